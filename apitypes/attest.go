@@ -6,8 +6,10 @@ package apitypes
 import (
 	"bytes"
 	"encoding/asn1"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/edgelesssys/contrast/internal/history"
 )
@@ -92,12 +94,23 @@ type CoordinatorState struct {
 	RootCA []byte `json:"root_ca"`
 	// PEM-encoded certificate of the deployment's mesh CA.
 	MeshCA []byte `json:"mesh_ca"`
+	// Hash of the most recent transition that was pruned from the history. It's empty if the history is complete,
+	// otherwise the oldest manifest in Manifests is a successor of this transition.
+	PrunedTransitionHash []byte `json:"pruned_transition_hash,omitempty"`
+	// Number of transitions that were pruned from the history.
+	PrunedTransitions uint64 `json:"pruned_transitions,omitempty"`
+	// Signature of the pruned history checkpoint by the Coordinator.
+	CheckpointSignature []byte `json:"checkpoint_signature,omitempty"`
+	// Manifest of a scheduled update that's not active yet. It's empty if no update is pending.
+	PendingManifest []byte `json:"pending_manifest,omitempty"`
+	// Time at which the pending update becomes active.
+	PendingActivationTime time.Time `json:"pending_activation_time,omitzero"`
 }
 
 // ConstructReportData constructs an extended report data digest,
 // intended for use with application-level verification.
 func ConstructReportData(nonce []byte, transitionDigest []byte, state *CoordinatorState) [ReportDataSize]byte {
-	// reportdata = sha256(nonce || sha256(transition) || sha256(root-ca) || sha256(mesh-ca))
+	// reportdata = sha256(nonce || sha256(transition) || sha256(root-ca) || sha256(mesh-ca) [|| sha256(checkpoint)] [|| sha256(pending)])
 	rootCADigest := history.Digest(state.RootCA)
 	meshCADigest := history.Digest(state.MeshCA)
 
@@ -105,6 +118,17 @@ func ConstructReportData(nonce []byte, transitionDigest []byte, state *Coordinat
 	reportdata = append(reportdata, transitionDigest...)
 	reportdata = append(reportdata, rootCADigest[:]...)
 	reportdata = append(reportdata, meshCADigest[:]...)
+	if len(state.PrunedTransitionHash) > 0 {
+		// Like the pending digest, the checkpoint digest is only appended if the history was pruned.
+		checkpointDigest := checkpointDigest(state)
+		reportdata = append(reportdata, checkpointDigest[:]...)
+	}
+	if len(state.PendingManifest) > 0 {
+		// The pending digest is only appended if an update is pending, so that the report data of
+		// Coordinators without pending updates doesn't change.
+		pendingDigest := pendingDigest(transitionDigest, state)
+		reportdata = append(reportdata, pendingDigest[:]...)
+	}
 	hash32 := history.Digest(reportdata)
 
 	var hash64 [64]byte
@@ -112,3 +136,22 @@ func ConstructReportData(nonce []byte, transitionDigest []byte, state *Coordinat
 
 	return hash64
 }
+
+// checkpointDigest returns sha256(pruned transition hash || pruned transitions), where the number
+// of pruned transitions is encoded as big-endian integer.
+func checkpointDigest(state *CoordinatorState) [history.HashSize]byte {
+	data := binary.BigEndian.AppendUint64(append([]byte{}, state.PrunedTransitionHash...), state.PrunedTransitions)
+	return history.Digest(data)
+}
+
+// pendingDigest returns sha256(sha256(pending transition) || activation time), where the pending
+// transition follows the transition with the given digest and the activation time is encoded as
+// big-endian Unix seconds.
+func pendingDigest(transitionDigest []byte, state *CoordinatorState) [history.HashSize]byte {
+	pending := &history.Transition{ManifestHash: history.Digest(state.PendingManifest)}
+	copy(pending.PreviousTransitionHash[:], transitionDigest)
+	pendingTransitionDigest := pending.Digest()
+
+	data := binary.BigEndian.AppendUint64(pendingTransitionDigest[:], uint64(state.PendingActivationTime.Unix()))
+	return history.Digest(data)
+}
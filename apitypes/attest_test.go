@@ -6,6 +6,7 @@ package apitypes
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -23,6 +24,13 @@ const (
 	"root_ca": "Uk9PVFBFTQ==",
 	"mesh_ca": "TUVTSFBFTQ=="
 }
+`
+	prunedResponse = `
+{
+	"manifests": ["bWFuaWZlc3Qy"],
+	"pruned_transition_hash": "cHJ1bmVk",
+	"pruned_transitions": 1
+}
 `
 	badField = `
 {
@@ -63,6 +71,16 @@ func TestResponseDecoding(t *testing.T) {
 				},
 			},
 		},
+		"pruned history parses correctly": {
+			resp: prunedResponse,
+			want: &AttestationResponse{
+				CoordinatorState: CoordinatorState{
+					Manifests:            [][]byte{[]byte("manifest2")},
+					PrunedTransitionHash: []byte("pruned"),
+					PrunedTransitions:    1,
+				},
+			},
+		},
 		"parsing error contains version, if available": {
 			resp:           badFieldWithVersion,
 			wantErr:        true,
@@ -97,4 +115,34 @@ func TestResponseDecoding(t *testing.T) {
 	}
 }
 
+func TestConstructReportData(t *testing.T) {
+	assert := assert.New(t)
+
+	nonce := []byte("nonce")
+	transitionDigest := make([]byte, 32)
+	state := &CoordinatorState{RootCA: []byte("root"), MeshCA: []byte("mesh")}
+	withoutPending := ConstructReportData(nonce, transitionDigest, state)
+
+	// Pending update fields must not change the report data if no manifest is pending.
+	state.PendingActivationTime = time.Unix(1792206000, 0)
+	assert.Equal(withoutPending, ConstructReportData(nonce, transitionDigest, state))
+
+	state.PendingManifest = []byte("pending")
+	withPending := ConstructReportData(nonce, transitionDigest, state)
+	assert.NotEqual(withoutPending, withPending)
+
+	state.PendingActivationTime = state.PendingActivationTime.Add(time.Hour)
+	assert.NotEqual(withPending, ConstructReportData(nonce, transitionDigest, state))
+
+	// The checkpoint must be bound to the report data, including the number of pruned transitions.
+	state = &CoordinatorState{RootCA: []byte("root"), MeshCA: []byte("mesh")}
+	state.PrunedTransitionHash = make([]byte, 32)
+	state.PrunedTransitions = 3
+	withCheckpoint := ConstructReportData(nonce, transitionDigest, state)
+	assert.NotEqual(withoutPending, withCheckpoint)
+
+	state.PrunedTransitions = 4
+	assert.NotEqual(withCheckpoint, ConstructReportData(nonce, transitionDigest, state))
+}
+
 var _ = error(&unmarshalError{})
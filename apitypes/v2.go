@@ -0,0 +1,117 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package apitypes
+
+import (
+	"encoding/asn1"
+	"encoding/json"
+
+	"github.com/edgelesssys/contrast/internal/history"
+)
+
+// APIVersionV2 is the identifier of version 2 of the Contrast HTTP API.
+//
+// Version 2 adds read-only GET endpoints below /v2/. Each request carries a hex-encoded, 32 byte
+// nonce in the nonce query parameter, and each response is an AttestedResponse.
+const APIVersionV2 = "v2"
+
+// Paths of the endpoints of version 2 of the HTTP API.
+const (
+	// PathV2Manifest serves the active manifest as ManifestResponse.
+	PathV2Manifest = "/v2/manifest"
+	// PathV2Manifests serves a manifest of the history by its hex-encoded SHA-256 hash as ManifestResponse.
+	PathV2Manifests = "/v2/manifests/"
+	// PathV2Policies serves a policy referenced in the history by its hex-encoded SHA-256 hash as PolicyResponse.
+	PathV2Policies = "/v2/policies/"
+	// PathV2Transitions serves the transition chain as TransitionsResponse.
+	PathV2Transitions = "/v2/transitions"
+	// PathV2CA serves the CA certificates as CAResponse.
+	PathV2CA = "/v2/ca"
+	// PathV2SPIFFEBundle serves the SPIFFE trust bundle of the deployment as SPIFFEBundleResponse.
+	PathV2SPIFFEBundle = "/v2/spiffe-bundle"
+)
+
+// AttestedResponse is the response body of all endpoints of version 2 of the HTTP API.
+//
+// The attestation document binds the response data to the request nonce and path, see
+// ConstructReportDataV2. Clients must verify the attestation document before using Data.
+type AttestedResponse struct {
+	// Version is the Coordinator version.
+	Version string `json:"version"`
+	// RawAttestationDoc is a raw attestation report.
+	RawAttestationDoc []byte `json:"raw_attestation_doc"`
+	// AttestationType is the OID used to identify the type of attestation document.
+	AttestationType asn1.ObjectIdentifier `json:"attestation_type"`
+	// Data is the JSON-encoded, endpoint-specific response data.
+	Data json.RawMessage `json:"data"`
+}
+
+// ManifestResponse is the data of the manifest endpoints.
+type ManifestResponse struct {
+	// Manifest is the JSON-encoded manifest.
+	Manifest []byte `json:"manifest"`
+	// TransitionHash is the hash of the latest transition. It's only set for the active manifest.
+	TransitionHash []byte `json:"transition_hash,omitempty"`
+	// Generation is the generation of the latest transition. It's only set for the active manifest.
+	Generation uint64 `json:"generation,omitempty"`
+}
+
+// PolicyResponse is the data of the policy endpoint.
+type PolicyResponse struct {
+	// Policy is the policy document.
+	Policy []byte `json:"policy"`
+}
+
+// Transition is a single step of the manifest history.
+type Transition struct {
+	// TransitionHash is the hash of this transition.
+	TransitionHash []byte `json:"transition_hash"`
+	// PreviousTransitionHash is the hash of the preceding transition. It's all-zero for the
+	// initial manifest.
+	PreviousTransitionHash []byte `json:"previous_transition_hash"`
+	// ManifestHash is the SHA-256 hash of the manifest set by this transition.
+	ManifestHash []byte `json:"manifest_hash"`
+	// Generation counts the transitions, starting at 1 for the initial manifest.
+	Generation uint64 `json:"generation"`
+}
+
+// TransitionsResponse is the data of the transitions endpoint.
+type TransitionsResponse struct {
+	// Transitions is the transition chain, oldest first. The last transition is the latest one.
+	Transitions []Transition `json:"transitions"`
+	// Number of transitions that were pruned from the history. The oldest transition in
+	// Transitions follows the most recent pruned transition.
+	PrunedTransitions uint64 `json:"pruned_transitions,omitempty"`
+}
+
+// CAResponse is the data of the CA endpoint.
+type CAResponse struct {
+	// PEM-encoded certificate of the deployment's root CA.
+	RootCA []byte `json:"root_ca"`
+	// PEM-encoded certificate of the deployment's mesh CA.
+	MeshCA []byte `json:"mesh_ca"`
+}
+
+// SPIFFEBundleResponse is the data of the SPIFFE bundle endpoint.
+type SPIFFEBundleResponse struct {
+	// TrustDomain is the SPIFFE trust domain configured in the active manifest.
+	TrustDomain string `json:"trust_domain"`
+	// Bundle is the trust bundle of the trust domain in JWK set format, holding the mesh CA.
+	Bundle json.RawMessage `json:"bundle"`
+}
+
+// ConstructReportDataV2 constructs the report data that binds the data of a version 2 response
+// to the request nonce and the requested path.
+func ConstructReportDataV2(nonce []byte, path string, data []byte) [ReportDataSize]byte {
+	// reportdata = sha256(nonce || sha256("contrast-api-v2:" || path) || sha256(data))
+	pathDigest := history.Digest([]byte("contrast-api-v2:" + path))
+	dataDigest := history.Digest(data)
+	reportdata := append([]byte{}, nonce...)
+	reportdata = append(reportdata, pathDigest[:]...)
+	reportdata = append(reportdata, dataDigest[:]...)
+	hash32 := history.Digest(reportdata)
+	var hash64 [ReportDataSize]byte
+	copy(hash64[:], hash32[:])
+	return hash64
+}
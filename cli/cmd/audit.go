@@ -0,0 +1,208 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package cmd
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/edgelesssys/contrast/internal/atls"
+	"github.com/edgelesssys/contrast/internal/auditlog"
+	"github.com/edgelesssys/contrast/internal/grpc/dialer"
+	"github.com/edgelesssys/contrast/internal/history"
+	"github.com/edgelesssys/contrast/internal/manifest"
+	"github.com/edgelesssys/contrast/internal/userapi"
+	"github.com/spf13/cobra"
+)
+
+// NewAuditCmd creates the contrast audit subcommand.
+func NewAuditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit [flags]",
+		Short: "Export the audit log of the coordinator",
+		Long: `Export the audit log of the coordinator.
+
+This will connect to the given Coordinator using aTLS and fetch the events of
+its audit log. Any workload owner of the currently active manifest can export
+the audit log.
+
+The events are written to stdout as JSON lines, oldest first. Each event
+contains the hash of its predecessor, and the CLI verifies that the exported
+events form an unbroken chain. If older events were pruned from the audit log,
+the export starts at the oldest retained event.
+
+For incremental exports, pass the hash of the last event that was already
+exported with --after. The CLI then verifies that the new events continue the
+chain from that event.`,
+		Args: cobra.NoArgs,
+		RunE: withTelemetry(runAudit),
+	}
+
+	cmd.Flags().StringP("manifest", "m", manifestFilename, "path to the active manifest (.json) file")
+	cmd.Flags().StringP("coordinator", "c", "", "endpoint the coordinator can be reached at")
+	must(cobra.MarkFlagRequired(cmd.Flags(), "coordinator"))
+	cmd.Flags().String("workload-owner-key", workloadOwnerPEM, "path to workload owner key (.pem) file")
+	cmd.Flags().String("after", "", "hex-encoded hash of the last already exported event")
+	addCollateralProxyFlag(cmd)
+
+	return cmd
+}
+
+func runAudit(cmd *cobra.Command, _ []string) error {
+	flags, err := parseAuditFlags(cmd)
+	if err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+
+	log, err := newCLILogger(cmd)
+	if err != nil {
+		return err
+	}
+
+	var after []byte
+	if flags.after != "" {
+		after, err = hex.DecodeString(flags.after)
+		if err != nil {
+			return fmt.Errorf("decoding event hash: %w", err)
+		}
+		if len(after) != history.HashSize {
+			return fmt.Errorf("event hash has invalid length %d", len(after))
+		}
+	}
+
+	manifestBytes, err := os.ReadFile(flags.manifestPath)
+	if err != nil {
+		return fmt.Errorf("failed to read manifest file: %w", err)
+	}
+	var m manifest.Manifest
+	if err := json.Unmarshal(manifestBytes, &m); err != nil {
+		return fmt.Errorf("failed to unmarshal manifest: %w", err)
+	}
+	workloadOwnerKey, err := loadWorkloadOwnerKey(flags.workloadOwnerKeyPath, nil, log)
+	if err != nil {
+		return fmt.Errorf("loading workload owner key: %w", err)
+	}
+
+	kdsGetter, err := cachedHTTPSGetter(log, flags.collateralProxyURL)
+	if err != nil {
+		return fmt.Errorf("configuring KDS cache: %w", err)
+	}
+	validator, err := m.CoordinatorValidator(log, kdsGetter)
+	if err != nil {
+		return fmt.Errorf("getting validators: %w", err)
+	}
+
+	dialer := dialer.NewWithKey(atls.NoIssuer, validator, atls.NoMetrics, nil, workloadOwnerKey, log)
+	conn, err := dialer.Dial(cmd.Context(), flags.coordinator)
+	if err != nil {
+		return fmt.Errorf("dialing coordinator: %w", err)
+	}
+	defer conn.Close()
+
+	client := userapi.NewUserAPIClient(conn)
+	previousHash := after
+	var afterSequence *uint64
+	var exported int
+	for {
+		resp, err := client.GetAuditLog(cmd.Context(), &userapi.GetAuditLogRequest{After: previousHash, AfterSequence: afterSequence})
+		if err != nil {
+			return fmt.Errorf("getting audit log: %w", err)
+		}
+		events := resp.GetEvents()
+		if len(events) == 0 {
+			break
+		}
+		if exported == 0 && len(after) == 0 {
+			// The oldest events may have been pruned, so the export starts at the oldest
+			// retained event.
+			var first auditlog.Event
+			if err := json.Unmarshal(events[0], &first); err != nil {
+				return fmt.Errorf("unmarshaling audit event: %w", err)
+			}
+			if first.Sequence > 0 {
+				log.Warn("Older audit events were pruned", "firstEvent", first.Sequence)
+				if previousHash, err = hex.DecodeString(first.PreviousHash); err != nil {
+					return fmt.Errorf("decoding previous hash of audit event %d: %w", first.Sequence, err)
+				}
+			}
+		}
+		if err := auditlog.VerifyChain(previousHash, events); err != nil {
+			return fmt.Errorf("verifying audit log: %w", err)
+		}
+		for _, event := range events {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s\n", event)
+		}
+		exported += len(events)
+
+		var last auditlog.Event
+		if err := json.Unmarshal(events[len(events)-1], &last); err != nil {
+			return fmt.Errorf("unmarshaling audit event: %w", err)
+		}
+		lastHash := history.Digest(events[len(events)-1])
+		previousHash, afterSequence = lastHash[:], &last.Sequence
+		if !resp.GetMore() {
+			break
+		}
+	}
+	if exported > 0 {
+		log.Info("Exported audit log", "events", exported, "lastEvent", hex.EncodeToString(previousHash))
+	}
+	return nil
+}
+
+type auditFlags struct {
+	manifestPath         string
+	coordinator          string
+	workloadOwnerKeyPath string
+	after                string
+	collateralProxyURL   string
+}
+
+func parseAuditFlags(cmd *cobra.Command) (*auditFlags, error) {
+	manifestPath, err := cmd.Flags().GetString("manifest")
+	if err != nil {
+		return nil, err
+	}
+	coordinator, err := cmd.Flags().GetString("coordinator")
+	if err != nil {
+		return nil, err
+	}
+	workloadOwnerKeyPath, err := cmd.Flags().GetString("workload-owner-key")
+	if err != nil {
+		return nil, err
+	}
+	after, err := cmd.Flags().GetString("after")
+	if err != nil {
+		return nil, err
+	}
+	workspaceDir, err := cmd.Flags().GetString("workspace-dir")
+	if err != nil {
+		return nil, err
+	}
+	collateralProxyURL, err := cmd.Flags().GetString("collateral-proxy")
+	if err != nil {
+		return nil, err
+	}
+
+	if workspaceDir != "" {
+		// Prepend default paths with workspaceDir
+		if !cmd.Flags().Changed("manifest") {
+			manifestPath = filepath.Join(workspaceDir, manifestFilename)
+		}
+		if !cmd.Flags().Changed("workload-owner-key") {
+			workloadOwnerKeyPath = filepath.Join(workspaceDir, workloadOwnerKeyPath)
+		}
+	}
+
+	return &auditFlags{
+		manifestPath:         manifestPath,
+		coordinator:          coordinator,
+		workloadOwnerKeyPath: workloadOwnerKeyPath,
+		after:                after,
+		collateralProxyURL:   collateralProxyURL,
+	}, nil
+}
@@ -0,0 +1,192 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package cmd
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/edgelesssys/contrast/internal/atls"
+	"github.com/edgelesssys/contrast/internal/grpc/dialer"
+	"github.com/edgelesssys/contrast/internal/history"
+	"github.com/edgelesssys/contrast/internal/manifest"
+	"github.com/edgelesssys/contrast/internal/userapi"
+	"github.com/spf13/cobra"
+)
+
+// NewCancelCmd creates the contrast cancel subcommand.
+func NewCancelCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cancel [flags] [transition-hash]",
+		Short: "Cancel a scheduled manifest update",
+		Long: `Cancel a scheduled manifest update.
+
+This will connect to the given Coordinator using aTLS and cancel the manifest
+update that was scheduled with 'contrast set --activation-time'. The
+cancellation needs to be approved by the workload owners of the active
+manifest, like a manifest update. To sign the cancellation with external keys,
+run 'contrast sign --cancel' with the transition hash of the scheduled update.
+
+If a transition hash is given, the update is only cancelled if it's still the
+scheduled one. Otherwise, whichever update is scheduled is cancelled.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: withTelemetry(runCancel),
+	}
+	cmd.SetOut(commandOut())
+
+	cmd.Flags().StringP("manifest", "m", manifestFilename, "path to the active manifest (.json) file")
+	cmd.Flags().StringP("coordinator", "c", "", "endpoint the coordinator can be reached at")
+	must(cobra.MarkFlagRequired(cmd.Flags(), "coordinator"))
+	cmd.Flags().String("workload-owner-key", workloadOwnerPEM, "path to workload owner key (.pem) file")
+	cmd.Flags().StringArrayP("signature", "s", nil, "path to a detached cancellation signature (DER) or signature bundle file, can be repeated")
+	must(cmd.MarkFlagFilename("signature"))
+	addCollateralProxyFlag(cmd)
+
+	return cmd
+}
+
+func runCancel(cmd *cobra.Command, args []string) error {
+	flags, err := parseCancelFlags(cmd)
+	if err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+
+	log, err := newCLILogger(cmd)
+	if err != nil {
+		return err
+	}
+
+	var transitionHash []byte
+	if len(args) > 0 {
+		transitionHash, err = hex.DecodeString(args[0])
+		if err != nil {
+			return fmt.Errorf("decoding transition hash: %w", err)
+		}
+		if len(transitionHash) != history.HashSize {
+			return fmt.Errorf("transition hash has invalid length %d", len(transitionHash))
+		}
+	}
+
+	manifestBytes, err := os.ReadFile(flags.manifestPath)
+	if err != nil {
+		return fmt.Errorf("failed to read manifest file: %w", err)
+	}
+	var m manifest.Manifest
+	if err := json.Unmarshal(manifestBytes, &m); err != nil {
+		return fmt.Errorf("failed to unmarshal manifest: %w", err)
+	}
+	workloadOwnerKey, err := loadWorkloadOwnerKey(flags.workloadOwnerKeyPath, nil, log)
+	if errors.Is(err, os.ErrNotExist) {
+		workloadOwnerKey = nil
+	} else if err != nil {
+		return fmt.Errorf("loading workload owner key: %w", err)
+	}
+	var signatures [][]byte
+	for _, signaturePath := range flags.signaturePaths {
+		signatures, err = appendSignatureFile(signatures, signaturePath)
+		if err != nil {
+			return err
+		}
+	}
+
+	kdsGetter, err := cachedHTTPSGetter(log, flags.collateralProxyURL)
+	if err != nil {
+		return fmt.Errorf("configuring KDS cache: %w", err)
+	}
+	validator, err := m.CoordinatorValidator(log, kdsGetter)
+	if err != nil {
+		return fmt.Errorf("getting validators: %w", err)
+	}
+
+	var dialr *dialer.Dialer
+	if workloadOwnerKey == nil {
+		dialr = dialer.New(atls.NoIssuer, validator, atls.NoMetrics, nil, log)
+	} else {
+		dialr = dialer.NewWithKey(atls.NoIssuer, validator, atls.NoMetrics, nil, workloadOwnerKey, log)
+	}
+	conn, err := dialr.Dial(cmd.Context(), flags.coordinator)
+	if err != nil {
+		return fmt.Errorf("dialing coordinator: %w", err)
+	}
+	defer conn.Close()
+
+	client := userapi.NewUserAPIClient(conn)
+	if transitionHash == nil {
+		resp, err := client.GetManifests(cmd.Context(), &userapi.GetManifestsRequest{})
+		if err != nil {
+			return fmt.Errorf("getting manifests: %w", err)
+		}
+		if resp.GetPendingUpdate() == nil {
+			return errors.New("no manifest update is scheduled")
+		}
+		transitionHash = resp.GetPendingUpdate().GetTransitionHash()
+	}
+
+	if _, err := client.CancelPendingUpdate(cmd.Context(), &userapi.CancelPendingUpdateRequest{
+		TransitionHash: transitionHash,
+		Signatures:     signatures,
+	}); err != nil {
+		return fmt.Errorf("cancelling scheduled manifest update: %w", err)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "✔️ Cancelled scheduled manifest update %x\n", transitionHash)
+	return nil
+}
+
+type cancelFlags struct {
+	manifestPath         string
+	coordinator          string
+	workloadOwnerKeyPath string
+	signaturePaths       []string
+	collateralProxyURL   string
+}
+
+func parseCancelFlags(cmd *cobra.Command) (*cancelFlags, error) {
+	manifestPath, err := cmd.Flags().GetString("manifest")
+	if err != nil {
+		return nil, err
+	}
+	coordinator, err := cmd.Flags().GetString("coordinator")
+	if err != nil {
+		return nil, err
+	}
+	workloadOwnerKeyPath, err := cmd.Flags().GetString("workload-owner-key")
+	if err != nil {
+		return nil, err
+	}
+	signaturePaths, err := cmd.Flags().GetStringArray("signature")
+	if err != nil {
+		return nil, err
+	}
+	workspaceDir, err := cmd.Flags().GetString("workspace-dir")
+	if err != nil {
+		return nil, err
+	}
+	collateralProxyURL, err := cmd.Flags().GetString("collateral-proxy")
+	if err != nil {
+		return nil, err
+	}
+
+	if workspaceDir != "" {
+		// Prepend default paths with workspaceDir
+		if !cmd.Flags().Changed("manifest") {
+			manifestPath = filepath.Join(workspaceDir, manifestFilename)
+		}
+		if !cmd.Flags().Changed("workload-owner-key") {
+			workloadOwnerKeyPath = filepath.Join(workspaceDir, workloadOwnerKeyPath)
+		}
+	}
+
+	return &cancelFlags{
+		manifestPath:         manifestPath,
+		coordinator:          coordinator,
+		workloadOwnerKeyPath: workloadOwnerKeyPath,
+		signaturePaths:       signaturePaths,
+		collateralProxyURL:   collateralProxyURL,
+	}, nil
+}
@@ -28,6 +28,7 @@ import (
 const (
 	coordRootPEMFilename         = "coordinator-root-ca.pem"
 	meshCAPEMFilename            = "mesh-ca.pem"
+	spiffeBundleFilename         = "spiffe-bundle.json"
 	workloadOwnerPEM             = "workload-owner.pem"
 	seedshareOwnerPEM            = "seedshare-owner.pem"
 	manifestFilename             = "manifest.json"
@@ -37,6 +38,8 @@ const (
 	layersCacheFilename          = "layers-cache.json"
 	latestTransitionHashFilename = "latest-transition"
 	historyFilename              = "history.yml"
+	pendingManifestFilename      = "manifest.pending.json"
+	signingKeyFilename           = "coordinator-signing-key.pem"
 	verifyDir                    = "verify"
 )
 
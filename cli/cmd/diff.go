@@ -0,0 +1,112 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/edgelesssys/contrast/internal/manifest"
+	"github.com/edgelesssys/contrast/internal/userapi"
+)
+
+// diffFromProto converts the wire representation of a manifest diff to a manifest.Diff.
+func diffFromProto(x *userapi.ManifestDiff) (*manifest.Diff, error) {
+	d := &manifest.Diff{
+		AddedPolicies:                toHexStrings(x.GetAddedPolicies()),
+		RemovedPolicies:              toHexStrings(x.GetRemovedPolicies()),
+		ChangedPolicies:              toHexStrings(x.GetChangedPolicies()),
+		AddedWorkloadOwnerPubKeys:    toHexStrings(x.GetAddedWorkloadOwnerPubKeys()),
+		RemovedWorkloadOwnerPubKeys:  toHexStrings(x.GetRemovedWorkloadOwnerPubKeys()),
+		AddedSeedshareOwnerPubKeys:   toHexStrings(x.GetAddedSeedshareOwnerPubKeys()),
+		RemovedSeedshareOwnerPubKeys: toHexStrings(x.GetRemovedSeedshareOwnerPubKeys()),
+	}
+	if len(x.GetAddedReferenceValues()) > 0 {
+		if err := json.Unmarshal(x.GetAddedReferenceValues(), &d.AddedReferenceValues); err != nil {
+			return nil, fmt.Errorf("unmarshaling added reference values: %w", err)
+		}
+	}
+	if len(x.GetRemovedReferenceValues()) > 0 {
+		if err := json.Unmarshal(x.GetRemovedReferenceValues(), &d.RemovedReferenceValues); err != nil {
+			return nil, fmt.Errorf("unmarshaling removed reference values: %w", err)
+		}
+	}
+	return d, nil
+}
+
+func toHexStrings(in []string) []manifest.HexString {
+	var out []manifest.HexString
+	for _, s := range in {
+		out = append(out, manifest.HexString(s))
+	}
+	return out
+}
+
+// writeManifestDiff prints a human readable representation of a manifest diff.
+func writeManifestDiff(out io.Writer, d *manifest.Diff) error {
+	if d.Empty() {
+		fmt.Fprintln(out, "No changes to the manifest")
+		return nil
+	}
+
+	if len(d.AddedPolicies)+len(d.RemovedPolicies)+len(d.ChangedPolicies) > 0 {
+		fmt.Fprintln(out, "Policies:")
+		writeDiffLines(out, "+", d.AddedPolicies)
+		writeDiffLines(out, "-", d.RemovedPolicies)
+		writeDiffLines(out, "~", d.ChangedPolicies)
+	}
+
+	added, err := referenceValueLines(d.AddedReferenceValues)
+	if err != nil {
+		return fmt.Errorf("formatting added reference values: %w", err)
+	}
+	removed, err := referenceValueLines(d.RemovedReferenceValues)
+	if err != nil {
+		return fmt.Errorf("formatting removed reference values: %w", err)
+	}
+	if len(added)+len(removed) > 0 {
+		fmt.Fprintln(out, "Reference values:")
+		writeDiffLines(out, "+", added)
+		writeDiffLines(out, "-", removed)
+	}
+
+	if len(d.AddedWorkloadOwnerPubKeys)+len(d.RemovedWorkloadOwnerPubKeys) > 0 {
+		fmt.Fprintln(out, "Workload owner keys:")
+		writeDiffLines(out, "+", d.AddedWorkloadOwnerPubKeys)
+		writeDiffLines(out, "-", d.RemovedWorkloadOwnerPubKeys)
+	}
+
+	if len(d.AddedSeedshareOwnerPubKeys)+len(d.RemovedSeedshareOwnerPubKeys) > 0 {
+		fmt.Fprintln(out, "Seedshare owner keys:")
+		writeDiffLines(out, "+", d.AddedSeedshareOwnerPubKeys)
+		writeDiffLines(out, "-", d.RemovedSeedshareOwnerPubKeys)
+	}
+	return nil
+}
+
+func writeDiffLines[T ~string](out io.Writer, prefix string, lines []T) {
+	for _, line := range lines {
+		fmt.Fprintf(out, "  %s %s\n", prefix, line)
+	}
+}
+
+func referenceValueLines(rv manifest.ReferenceValues) ([]string, error) {
+	var lines []string
+	for _, v := range rv.SNP {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, "SNP "+string(b))
+	}
+	for _, v := range rv.TDX {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, "TDX "+string(b))
+	}
+	return lines, nil
+}
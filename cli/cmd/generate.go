@@ -23,6 +23,7 @@ import (
 	"github.com/edgelesssys/contrast/cli/genpolicy"
 	"github.com/edgelesssys/contrast/cli/verifier"
 	"github.com/edgelesssys/contrast/internal/constants"
+	"github.com/edgelesssys/contrast/internal/cryptohelpers"
 	"github.com/edgelesssys/contrast/internal/idblock"
 	"github.com/edgelesssys/contrast/internal/initdata"
 	"github.com/edgelesssys/contrast/internal/kuberesource"
@@ -233,7 +234,7 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("find kube resources with policy: %w", err)
 	}
-	policyMap, err := manifestPolicyMapFromPolicies(policies)
+	policyMap, err := manifestPolicyMapFromPolicies(policies, mnf.SPIFFETrustDomain != "")
 	if err != nil {
 		return fmt.Errorf("create policy map: %w", err)
 	}
@@ -578,7 +579,7 @@ func patchTargets(fileMap map[string][]*unstructured.Unstructured, imageReplacem
 			}
 		}
 		if !flags.skipInitializer {
-			if err := injectInitializer(res, coordinatorNamespace, flags.collateralProxyURL, memoryProfile); err != nil {
+			if err := injectInitializer(res, coordinatorNamespace, flags.collateralProxyURL, mnf.KeyAlgorithm, memoryProfile); err != nil {
 				return nil, fmt.Errorf("injecting Initializer: %w", err)
 			}
 		}
@@ -607,7 +608,7 @@ func patchTargets(fileMap map[string][]*unstructured.Unstructured, imageReplacem
 	})
 }
 
-func injectInitializer(resource any, coordinatorNamespace, collateralProxyURL string, memoryProfile kuberesource.MemoryProfile) error {
+func injectInitializer(resource any, coordinatorNamespace, collateralProxyURL string, keyAlgorithm cryptohelpers.KeyAlgorithm, memoryProfile kuberesource.MemoryProfile) error {
 	if isCoordinator(resource) {
 		return nil
 	}
@@ -619,6 +620,9 @@ func injectInitializer(resource any, coordinatorNamespace, collateralProxyURL st
 	if collateralProxyURL != "" {
 		initializer.WithEnv(kuberesource.NewEnvVar(constants.CollateralProxyEnvVar, collateralProxyURL))
 	}
+	if keyAlgorithm != "" {
+		initializer.WithEnv(kuberesource.NewEnvVar(constants.KeyAlgorithmEnvVar, string(keyAlgorithm)))
+	}
 	if _, err := kuberesource.AddInitializer(resource, initializer); err != nil {
 		return err
 	}
@@ -0,0 +1,390 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/edgelesssys/contrast/internal/history"
+	"github.com/edgelesssys/contrast/internal/manifest"
+	"github.com/edgelesssys/contrast/internal/userapi"
+	"github.com/spf13/cobra"
+)
+
+// NewHistoryCmd creates the contrast history subcommand.
+func NewHistoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Inspect the manifest history of the coordinator",
+		Long: `Inspect the manifest history of the coordinator.
+
+This will connect to the given Coordinator using aTLS, verify it against the
+reference values of the given manifest and fetch the manifest history.
+
+Transitions can be referenced by their generation, starting at 1 for the initial
+manifest, by a unique prefix of their transition hash, or by "latest". Purely
+numeric references are always interpreted as generations.
+
+A transition is reported as signed if the manifest update was authorized by
+detached signatures that are valid for workload owner keys of the previous
+manifest. Updates authorized with the workload owner key in the TLS handshake
+are reported as not signed.
+
+If the Coordinator pruned old transitions, generations continue to count from
+the initial manifest. The signature of the oldest remaining transition can't be
+checked, because the manifest that authorized it was pruned.`,
+	}
+
+	cmd.PersistentFlags().StringP("manifest", "m", manifestFilename, "path to manifest (.json) file")
+	cmd.PersistentFlags().StringP("coordinator", "c", "", "endpoint the coordinator can be reached at")
+	must(cobra.MarkFlagRequired(cmd.PersistentFlags(), "coordinator"))
+	cmd.PersistentFlags().String("collateral-proxy", "", "route attestation-collateral fetches through the caching proxy at this base URL")
+
+	cmd.AddCommand(
+		newHistoryListCmd(),
+		newHistoryShowCmd(),
+		newHistoryDiffCmd(),
+	)
+	return cmd
+}
+
+func newHistoryListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List all transitions of the manifest history",
+		Args:  cobra.NoArgs,
+		RunE:  withTelemetry(runHistoryList),
+	}
+	cmd.SetOut(commandOut())
+	return cmd
+}
+
+func newHistoryShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show <transition>",
+		Short: "Show the details of a transition",
+		Args:  cobra.ExactArgs(1),
+		RunE:  withTelemetry(runHistoryShow),
+	}
+	cmd.SetOut(commandOut())
+	return cmd
+}
+
+func newHistoryDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff <from> <to>",
+		Short: "Show the changes between the manifests of two transitions",
+		Args:  cobra.ExactArgs(2),
+		RunE:  withTelemetry(runHistoryDiff),
+	}
+	cmd.SetOut(commandOut())
+	return cmd
+}
+
+func runHistoryList(cmd *cobra.Command, _ []string) error {
+	entries, err := fetchHistory(cmd)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	if pruned := entries[0].generation - 1; pruned > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "The Coordinator pruned the %d oldest transitions.\n", pruned)
+	}
+	fmt.Fprintln(w, "GENERATION\tTRANSITION\tPREVIOUS\tMANIFEST\tPOLICIES\tSIGNED")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t+%d -%d\t%s\n",
+			e.generation,
+			shortHash(e.transitionHash),
+			shortHash(e.transition.PreviousTransitionHash),
+			shortHash(e.transition.ManifestHash),
+			len(e.diff.AddedPolicies), len(e.diff.RemovedPolicies),
+			e.signedStatus(),
+		)
+	}
+	return w.Flush()
+}
+
+func runHistoryShow(cmd *cobra.Command, args []string) error {
+	entries, err := fetchHistory(cmd)
+	if err != nil {
+		return err
+	}
+	entry, err := resolveHistoryEntry(entries, args[0])
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Generation:          %d\n", entry.generation)
+	fmt.Fprintf(out, "Transition hash:     %x\n", entry.transitionHash)
+	fmt.Fprintf(out, "Previous transition: %x\n", entry.transition.PreviousTransitionHash)
+	fmt.Fprintf(out, "Manifest hash:       %x\n", entry.transition.ManifestHash)
+	fmt.Fprintf(out, "Signed:              %s\n", entry.signedStatus())
+	for _, signer := range entry.signedBy {
+		fmt.Fprintf(out, "Signed by:           %s\n", signer)
+	}
+	fmt.Fprintln(out, "Changes to previous manifest:")
+	return writeManifestDiff(out, entry.diff)
+}
+
+func runHistoryDiff(cmd *cobra.Command, args []string) error {
+	entries, err := fetchHistory(cmd)
+	if err != nil {
+		return err
+	}
+	from, err := resolveHistoryEntry(entries, args[0])
+	if err != nil {
+		return err
+	}
+	to, err := resolveHistoryEntry(entries, args[1])
+	if err != nil {
+		return err
+	}
+
+	diff, err := manifest.NewDiff(from.manifest, to.manifest)
+	if err != nil {
+		return fmt.Errorf("comparing manifests: %w", err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Changes from generation %d to generation %d:\n", from.generation, to.generation)
+	return writeManifestDiff(cmd.OutOrStdout(), diff)
+}
+
+type historyFlags struct {
+	manifestPath       string
+	coordinator        string
+	collateralProxyURL string
+}
+
+func parseHistoryFlags(cmd *cobra.Command) (*historyFlags, error) {
+	manifestPath, err := cmd.Flags().GetString("manifest")
+	if err != nil {
+		return nil, err
+	}
+	coordinator, err := cmd.Flags().GetString("coordinator")
+	if err != nil {
+		return nil, err
+	}
+	workspaceDir, err := cmd.Flags().GetString("workspace-dir")
+	if err != nil {
+		return nil, err
+	}
+	collateralProxyURL, err := cmd.Flags().GetString("collateral-proxy")
+	if err != nil {
+		return nil, err
+	}
+
+	if workspaceDir != "" {
+		// Prepend default path with workspaceDir
+		if !cmd.Flags().Changed("manifest") {
+			manifestPath = filepath.Join(workspaceDir, manifestFilename)
+		}
+	}
+
+	return &historyFlags{
+		manifestPath:       manifestPath,
+		coordinator:        coordinator,
+		collateralProxyURL: collateralProxyURL,
+	}, nil
+}
+
+// fetchHistory gets the manifest history from the coordinator and assembles the history entries.
+func fetchHistory(cmd *cobra.Command) ([]historyEntry, error) {
+	flags, err := parseHistoryFlags(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("parsing flags: %w", err)
+	}
+
+	log, err := newCLILogger(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	manifestBytes, err := os.ReadFile(flags.manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest file: %w", err)
+	}
+
+	kdsDir, err := cachedir("kds")
+	if err != nil {
+		return nil, fmt.Errorf("getting cache dir: %w", err)
+	}
+	log.Debug("Using KDS cache dir", "dir", kdsDir)
+
+	resp, err := getManifests(cmd.Context(), kdsDir, manifestBytes, flags.coordinator, flags.collateralProxyURL, log)
+	if err != nil {
+		return nil, fmt.Errorf("getting manifests: %w", err)
+	}
+
+	entries, err := newHistoryEntries(resp.GetManifests(), resp.GetTransitionSignatures(), resp.GetCheckpoint())
+	if err != nil {
+		return nil, fmt.Errorf("processing manifest history: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("manifest history is empty")
+	}
+	latest := entries[len(entries)-1].transitionHash
+	if !bytes.Equal(latest[:], resp.GetLatestTransition().GetTransitionHash()) {
+		return nil, fmt.Errorf("manifest history does not lead to the latest transition %x", resp.GetLatestTransition().GetTransitionHash())
+	}
+	return entries, nil
+}
+
+// historyEntry describes a single transition of the manifest history.
+type historyEntry struct {
+	generation     int
+	transitionHash [history.HashSize]byte
+	transition     *history.Transition
+	manifest       *manifest.Manifest
+	// diff contains the changes compared to the manifest of the previous transition.
+	diff *manifest.Diff
+	// signedBy are the workload owner keys that signed the transition.
+	signedBy []manifest.HexString
+	// invalidSignature is set if the transition has a signature that doesn't match any
+	// authorized workload owner key.
+	invalidSignature bool
+	// unverifiableSignature is set if the transition has a signature, but the manifest holding
+	// the authorized keys was pruned.
+	unverifiableSignature bool
+}
+
+func (e *historyEntry) signedStatus() string {
+	switch {
+	case e.invalidSignature:
+		return "invalid"
+	case len(e.signedBy) > 0:
+		return "yes"
+	case e.unverifiableSignature:
+		return "unknown"
+	default:
+		return "no"
+	}
+}
+
+// newHistoryEntries builds the history entries from the manifests, ordered from oldest to newest,
+// the transition signatures and the checkpoint of the pruned transitions returned by the
+// coordinator. The checkpoint is nil if the history wasn't pruned.
+func newHistoryEntries(manifests [][]byte, signatures []*userapi.TransitionSignature, checkpoint *userapi.HistoryCheckpoint) ([]historyEntry, error) {
+	signaturesByHash := make(map[[history.HashSize]byte][]byte)
+	for _, sig := range signatures {
+		if len(sig.GetTransitionHash()) != history.HashSize {
+			return nil, fmt.Errorf("invalid transition hash length %d", len(sig.GetTransitionHash()))
+		}
+		signaturesByHash[[history.HashSize]byte(sig.GetTransitionHash())] = sig.GetSignature()
+	}
+
+	var prunedTransitionHash [history.HashSize]byte
+	var prunedTransitions int
+	if checkpoint != nil {
+		if len(checkpoint.GetTransitionHash()) != history.HashSize {
+			return nil, fmt.Errorf("invalid checkpoint transition hash length %d", len(checkpoint.GetTransitionHash()))
+		}
+		prunedTransitionHash = [history.HashSize]byte(checkpoint.GetTransitionHash())
+		prunedTransitions = int(checkpoint.GetGeneration())
+	}
+
+	var entries []historyEntry
+	var previous *manifest.Manifest
+	for i, transition := range history.BuildTransitionChainFrom(prunedTransitionHash, manifests) {
+		var m manifest.Manifest
+		if err := json.Unmarshal(manifests[i], &m); err != nil {
+			return nil, fmt.Errorf("unmarshaling manifest %d: %w", i, err)
+		}
+		diff, err := manifest.NewDiff(previous, &m)
+		if err != nil {
+			return nil, fmt.Errorf("comparing manifest %d to its predecessor: %w", i, err)
+		}
+		entry := historyEntry{
+			generation:     prunedTransitions + i + 1,
+			transitionHash: transition.Digest(),
+			transition:     transition,
+			manifest:       &m,
+			diff:           diff,
+		}
+
+		if signature, ok := signaturesByHash[entry.transitionHash]; ok && previous == nil && prunedTransitions > 0 {
+			entry.unverifiableSignature = true
+		} else if ok {
+			// The initial manifest is signed with one of its own keys, all later manifests with a
+			// key of their predecessor.
+			authorizedKeys := m.WorkloadOwnerPubKeys
+			if previous != nil {
+				authorizedKeys = previous.WorkloadOwnerPubKeys
+			}
+			entry.signedBy, entry.invalidSignature = transitionSigners(authorizedKeys, entry.transitionHash, signature)
+		}
+
+		entries = append(entries, entry)
+		previous = &m
+	}
+	return entries, nil
+}
+
+// transitionSigners returns the keys that produced the signatures of the bundle over the
+// transition hash. invalid is set if the bundle is malformed or contains a signature that
+// none of the keys produced.
+func transitionSigners(keys []manifest.HexString, transitionHash [history.HashSize]byte, bundle []byte) (signers []manifest.HexString, invalid bool) {
+	signatures, err := history.SplitSignatures(bundle)
+	if err != nil {
+		return nil, true
+	}
+	signingHash := history.TransitionSigningDigest(transitionHash)
+	for _, signature := range signatures {
+		signer := slices.IndexFunc(keys, func(key manifest.HexString) bool {
+			pubKey, err := manifest.ParseWorkloadOwnerPublicKey(key)
+			return err == nil && ecdsa.VerifyASN1(pubKey, signingHash[:], signature)
+		})
+		if signer < 0 {
+			invalid = true
+			continue
+		}
+		if !slices.Contains(signers, keys[signer]) {
+			signers = append(signers, keys[signer])
+		}
+	}
+	return signers, invalid
+}
+
+// resolveHistoryEntry finds the entry referenced by a generation, a transition hash prefix or "latest".
+func resolveHistoryEntry(entries []historyEntry, ref string) (*historyEntry, error) {
+	if ref == "latest" && len(entries) > 0 {
+		return &entries[len(entries)-1], nil
+	}
+	if generation, err := strconv.Atoi(ref); err == nil {
+		if len(entries) == 0 || generation < entries[0].generation || generation > entries[len(entries)-1].generation {
+			return nil, fmt.Errorf("generation %d does not exist, history has %d transitions", generation, len(entries))
+		}
+		return &entries[generation-entries[0].generation], nil
+	}
+
+	var found *historyEntry
+	for i := range entries {
+		if !strings.HasPrefix(hex.EncodeToString(entries[i].transitionHash[:]), strings.ToLower(ref)) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("transition hash prefix %q is ambiguous", ref)
+		}
+		found = &entries[i]
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no transition found for %q", ref)
+	}
+	return found, nil
+}
+
+func shortHash(hash [history.HashSize]byte) string {
+	return hex.EncodeToString(hash[:6])
+}
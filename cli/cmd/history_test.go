@@ -0,0 +1,171 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/edgelesssys/contrast/internal/history"
+	"github.com/edgelesssys/contrast/internal/manifest"
+	"github.com/edgelesssys/contrast/internal/testkeys"
+	"github.com/edgelesssys/contrast/internal/userapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHistoryEntries(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	ownerKey := testkeys.ECDSA(t)
+	otherKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[1])
+	ownerPubKey := manifest.MarshalWorkloadOwnerPubKey(&ownerKey.PublicKey)
+
+	const policyA = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	const policyB = "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+	var manifests [][]byte
+	for _, policies := range []map[manifest.HexString]manifest.PolicyEntry{
+		{policyA: {}},
+		{policyA: {}, policyB: {}},
+		{policyB: {}},
+	} {
+		m, err := json.Marshal(manifest.Manifest{Policies: policies, WorkloadOwnerPubKeys: []manifest.HexString{ownerPubKey}})
+		require.NoError(err)
+		manifests = append(manifests, m)
+	}
+	chain := history.BuildTransitionChain(manifests)
+
+	sign := func(key *ecdsa.PrivateKey, transition *history.Transition) *userapi.TransitionSignature {
+		transitionHash := transition.Digest()
+		signingHash := history.Digest(hex.AppendEncode(nil, transitionHash[:]))
+		sig, err := ecdsa.SignASN1(rand.Reader, key, signingHash[:])
+		require.NoError(err)
+		return &userapi.TransitionSignature{TransitionHash: transitionHash[:], Signature: sig}
+	}
+	signatures := []*userapi.TransitionSignature{
+		sign(ownerKey, chain[0]),
+		sign(otherKey, chain[2]),
+	}
+
+	entries, err := newHistoryEntries(manifests, signatures, nil)
+	require.NoError(err)
+	require.Len(entries, 3)
+
+	for i, entry := range entries {
+		assert.Equal(i+1, entry.generation)
+		assert.Equal(chain[i].Digest(), entry.transitionHash)
+		assert.Equal(chain[i], entry.transition)
+	}
+	assert.Equal([]manifest.HexString{policyA}, entries[0].diff.AddedPolicies)
+	assert.Equal([]manifest.HexString{policyB}, entries[1].diff.AddedPolicies)
+	assert.Equal([]manifest.HexString{policyA}, entries[2].diff.RemovedPolicies)
+
+	assert.Equal("yes", entries[0].signedStatus())
+	assert.Equal([]manifest.HexString{ownerPubKey}, entries[0].signedBy)
+	assert.Equal("no", entries[1].signedStatus())
+	assert.Equal("invalid", entries[2].signedStatus())
+
+	// After pruning the first transition, generations continue from the checkpoint and the
+	// signature of the oldest remaining transition can't be checked.
+	prunedHash := chain[0].Digest()
+	checkpoint := &userapi.HistoryCheckpoint{TransitionHash: prunedHash[:], Generation: 1}
+	signatures = []*userapi.TransitionSignature{
+		sign(ownerKey, chain[1]),
+		sign(ownerKey, chain[2]),
+	}
+	entries, err = newHistoryEntries(manifests[1:], signatures, checkpoint)
+	require.NoError(err)
+	require.Len(entries, 2)
+	for i, entry := range entries {
+		assert.Equal(i+2, entry.generation)
+		assert.Equal(chain[i+1].Digest(), entry.transitionHash)
+	}
+	assert.Equal("unknown", entries[0].signedStatus())
+	assert.Equal("yes", entries[1].signedStatus())
+}
+
+func TestResolveHistoryEntry(t *testing.T) {
+	entries := []historyEntry{
+		{generation: 1, transitionHash: [history.HashSize]byte{0xab, 0x01}},
+		{generation: 2, transitionHash: [history.HashSize]byte{0xab, 0x02}},
+		{generation: 3, transitionHash: [history.HashSize]byte{0xcd}},
+	}
+
+	testCases := map[string]struct {
+		ref            string
+		wantGeneration int
+		wantErr        bool
+	}{
+		"generation":          {ref: "2", wantGeneration: 2},
+		"latest":              {ref: "latest", wantGeneration: 3},
+		"unique prefix":       {ref: "ab01", wantGeneration: 1},
+		"upper case prefix":   {ref: "CD", wantGeneration: 3},
+		"ambiguous prefix":    {ref: "ab", wantErr: true},
+		"unknown prefix":      {ref: "ef", wantErr: true},
+		"generation too low":  {ref: "0", wantErr: true},
+		"generation too high": {ref: "4", wantErr: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			entry, err := resolveHistoryEntry(entries, tc.ref)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantGeneration, entry.generation)
+		})
+	}
+}
+
+func TestResolveHistoryEntry_Pruned(t *testing.T) {
+	require := require.New(t)
+
+	entries := []historyEntry{
+		{generation: 5, transitionHash: [history.HashSize]byte{0x01}},
+		{generation: 6, transitionHash: [history.HashSize]byte{0x02}},
+	}
+
+	entry, err := resolveHistoryEntry(entries, "6")
+	require.NoError(err)
+	require.Equal(6, entry.generation)
+	_, err = resolveHistoryEntry(entries, "4")
+	require.Error(err, "pruned generations must not be resolved")
+}
+
+func TestTransitionSigners(t *testing.T) {
+	require := require.New(t)
+
+	ownerKeys := []*ecdsa.PrivateKey{
+		testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[0]),
+		testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[1]),
+	}
+	keys := []manifest.HexString{
+		manifest.MarshalWorkloadOwnerPubKey(&ownerKeys[0].PublicKey),
+		manifest.MarshalWorkloadOwnerPubKey(&ownerKeys[1].PublicKey),
+	}
+	transitionHash := [history.HashSize]byte{1, 2, 3}
+	sign := func(key *ecdsa.PrivateKey) []byte {
+		signingHash := history.TransitionSigningDigest(transitionHash)
+		sig, err := ecdsa.SignASN1(rand.Reader, key, signingHash[:])
+		require.NoError(err)
+		return sig
+	}
+
+	signers, invalid := transitionSigners(keys, transitionHash, history.JoinSignatures(sign(ownerKeys[1]), sign(ownerKeys[0]), sign(ownerKeys[1])))
+	require.False(invalid)
+	require.Equal([]manifest.HexString{keys[1], keys[0]}, signers)
+
+	signers, invalid = transitionSigners(keys[:1], transitionHash, history.JoinSignatures(sign(ownerKeys[0]), sign(ownerKeys[1])))
+	require.True(invalid)
+	require.Equal(keys[:1], signers)
+
+	_, invalid = transitionSigners(keys, transitionHash, []byte("malformed"))
+	require.True(invalid)
+}
@@ -0,0 +1,192 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/edgelesssys/contrast/internal/atls"
+	"github.com/edgelesssys/contrast/internal/grpc/dialer"
+	"github.com/edgelesssys/contrast/internal/manifest"
+	"github.com/edgelesssys/contrast/internal/userapi"
+	"github.com/spf13/cobra"
+)
+
+// NewMeshCertsCmd creates the contrast mesh-certs subcommand.
+func NewMeshCertsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mesh-certs [flags]",
+		Short: "List the mesh certificates issued by the coordinator",
+		Long: `List the mesh certificates issued by the coordinator.
+
+This will connect to the given Coordinator using aTLS and fetch its inventory
+of unexpired mesh certificates. Any workload owner of the currently active
+manifest can list the certificates.
+
+The inventory is kept in memory by each Coordinator instance, so it only
+contains the certificates the connected instance issued since it started.
+Certificates that weren't issued by the mesh CA of the current manifest are
+marked with "currentMeshCA": false.
+
+The certificates are written to stdout as JSON lines, oldest first.`,
+		Args: cobra.NoArgs,
+		RunE: withTelemetry(runMeshCerts),
+	}
+
+	cmd.Flags().StringP("manifest", "m", manifestFilename, "path to the active manifest (.json) file")
+	cmd.Flags().StringP("coordinator", "c", "", "endpoint the coordinator can be reached at")
+	must(cobra.MarkFlagRequired(cmd.Flags(), "coordinator"))
+	cmd.Flags().String("workload-owner-key", workloadOwnerPEM, "path to workload owner key (.pem) file")
+	cmd.Flags().Bool("old-only", false, "only list certificates that weren't issued by the mesh CA of the current manifest")
+	addCollateralProxyFlag(cmd)
+
+	return cmd
+}
+
+// meshCertInfo is the output format of a mesh certificate.
+type meshCertInfo struct {
+	SerialNumber       string     `json:"serialNumber"`
+	PolicyHash         string     `json:"policyHash"`
+	SANs               []string   `json:"sans"`
+	PeerAddress        string     `json:"peerAddress"`
+	IssuedAt           time.Time  `json:"issuedAt"`
+	NotAfter           time.Time  `json:"notAfter"`
+	ManifestGeneration uint64     `json:"manifestGeneration"`
+	CurrentMeshCA      bool       `json:"currentMeshCA"`
+	RevokedAt          *time.Time `json:"revokedAt,omitempty"`
+}
+
+func runMeshCerts(cmd *cobra.Command, _ []string) error {
+	flags, err := parseMeshCertsFlags(cmd)
+	if err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+
+	log, err := newCLILogger(cmd)
+	if err != nil {
+		return err
+	}
+
+	manifestBytes, err := os.ReadFile(flags.manifestPath)
+	if err != nil {
+		return fmt.Errorf("failed to read manifest file: %w", err)
+	}
+	var m manifest.Manifest
+	if err := json.Unmarshal(manifestBytes, &m); err != nil {
+		return fmt.Errorf("failed to unmarshal manifest: %w", err)
+	}
+	workloadOwnerKey, err := loadWorkloadOwnerKey(flags.workloadOwnerKeyPath, nil, log)
+	if err != nil {
+		return fmt.Errorf("loading workload owner key: %w", err)
+	}
+
+	kdsGetter, err := cachedHTTPSGetter(log, flags.collateralProxyURL)
+	if err != nil {
+		return fmt.Errorf("configuring KDS cache: %w", err)
+	}
+	validator, err := m.CoordinatorValidator(log, kdsGetter)
+	if err != nil {
+		return fmt.Errorf("getting validators: %w", err)
+	}
+
+	dialer := dialer.NewWithKey(atls.NoIssuer, validator, atls.NoMetrics, nil, workloadOwnerKey, log)
+	conn, err := dialer.Dial(cmd.Context(), flags.coordinator)
+	if err != nil {
+		return fmt.Errorf("dialing coordinator: %w", err)
+	}
+	defer conn.Close()
+
+	client := userapi.NewUserAPIClient(conn)
+	resp, err := client.ListMeshCerts(cmd.Context(), &userapi.ListMeshCertsRequest{})
+	if err != nil {
+		return fmt.Errorf("listing mesh certificates: %w", err)
+	}
+
+	encoder := json.NewEncoder(cmd.OutOrStdout())
+	for _, cert := range resp.GetCertificates() {
+		if flags.oldOnly && cert.GetCurrentMeshCA() {
+			continue
+		}
+		if err := encoder.Encode(newMeshCertInfo(cert)); err != nil {
+			return fmt.Errorf("encoding mesh certificate: %w", err)
+		}
+	}
+	return nil
+}
+
+func newMeshCertInfo(cert *userapi.MeshCert) meshCertInfo {
+	info := meshCertInfo{
+		SerialNumber:       new(big.Int).SetBytes(cert.GetSerialNumber()).Text(16),
+		PolicyHash:         cert.GetPolicyHash(),
+		SANs:               cert.GetSANs(),
+		PeerAddress:        cert.GetPeerAddress(),
+		IssuedAt:           time.Unix(cert.GetIssuedAt(), 0).UTC(),
+		NotAfter:           time.Unix(cert.GetNotAfter(), 0).UTC(),
+		ManifestGeneration: cert.GetManifestGeneration(),
+		CurrentMeshCA:      cert.GetCurrentMeshCA(),
+	}
+	if cert.GetRevokedAt() != 0 {
+		revokedAt := time.Unix(cert.GetRevokedAt(), 0).UTC()
+		info.RevokedAt = &revokedAt
+	}
+	return info
+}
+
+type meshCertsFlags struct {
+	manifestPath         string
+	coordinator          string
+	workloadOwnerKeyPath string
+	oldOnly              bool
+	collateralProxyURL   string
+}
+
+func parseMeshCertsFlags(cmd *cobra.Command) (*meshCertsFlags, error) {
+	manifestPath, err := cmd.Flags().GetString("manifest")
+	if err != nil {
+		return nil, err
+	}
+	coordinator, err := cmd.Flags().GetString("coordinator")
+	if err != nil {
+		return nil, err
+	}
+	workloadOwnerKeyPath, err := cmd.Flags().GetString("workload-owner-key")
+	if err != nil {
+		return nil, err
+	}
+	oldOnly, err := cmd.Flags().GetBool("old-only")
+	if err != nil {
+		return nil, err
+	}
+	workspaceDir, err := cmd.Flags().GetString("workspace-dir")
+	if err != nil {
+		return nil, err
+	}
+	collateralProxyURL, err := cmd.Flags().GetString("collateral-proxy")
+	if err != nil {
+		return nil, err
+	}
+
+	if workspaceDir != "" {
+		// Prepend default paths with workspaceDir
+		if !cmd.Flags().Changed("manifest") {
+			manifestPath = filepath.Join(workspaceDir, manifestFilename)
+		}
+		if !cmd.Flags().Changed("workload-owner-key") {
+			workloadOwnerKeyPath = filepath.Join(workspaceDir, workloadOwnerKeyPath)
+		}
+	}
+
+	return &meshCertsFlags{
+		manifestPath:         manifestPath,
+		coordinator:          coordinator,
+		workloadOwnerKeyPath: workloadOwnerKeyPath,
+		oldOnly:              oldOnly,
+		collateralProxyURL:   collateralProxyURL,
+	}, nil
+}
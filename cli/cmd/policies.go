@@ -12,6 +12,7 @@ import (
 	"github.com/edgelesssys/contrast/internal/initdata"
 	"github.com/edgelesssys/contrast/internal/kuberesource"
 	"github.com/edgelesssys/contrast/internal/manifest"
+	"github.com/edgelesssys/contrast/internal/spiffe"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	applycorev1 "k8s.io/client-go/applyconfigurations/core/v1"
 	applymetav1 "k8s.io/client-go/applyconfigurations/meta/v1"
@@ -67,6 +68,8 @@ func policiesFromKubeResources(fileMap map[string][]*unstructured.Unstructured)
 
 		var annotation string
 		var workloadSecretID string
+		var meshCertLifetime string
+		var serviceAccount string
 		var role manifest.Role
 		kuberesource.MapPodSpecWithMeta(res, func(meta *applymetav1.ObjectMetaApplyConfiguration, spec *applycorev1.PodSpecApplyConfiguration) (*applymetav1.ObjectMetaApplyConfiguration, *applycorev1.PodSpecApplyConfiguration) {
 			if meta == nil {
@@ -75,6 +78,10 @@ func policiesFromKubeResources(fileMap map[string][]*unstructured.Unstructured)
 			annotation = meta.Annotations[kuberesource.InitdataAnnotationKey]
 			role = manifest.Role(meta.Labels[kuberesource.ContrastRoleLabelKey])
 			workloadSecretID = meta.Annotations[kuberesource.WorkloadSecretIDAnnotationKey]
+			meshCertLifetime = meta.Annotations[kuberesource.MeshCertLifetimeAnnotationKey]
+			if spec != nil && spec.ServiceAccountName != nil {
+				serviceAccount = *spec.ServiceAccountName
+			}
 			return meta, spec
 		})
 		if annotation == "" {
@@ -93,11 +100,17 @@ func policiesFromKubeResources(fileMap map[string][]*unstructured.Unstructured)
 		if err := role.Validate(); err != nil {
 			return nil, fmt.Errorf("invalid role %s for %s: %w", role, name, err)
 		}
+		if _, err := (manifest.PolicyEntry{MeshCertLifetime: meshCertLifetime}).CertLifetime(); err != nil {
+			return nil, fmt.Errorf("invalid mesh cert lifetime for %s: %w", name, err)
+		}
 		deployments = append(deployments, deployment{
 			name:             name,
 			initdata:         initdata,
 			role:             role,
 			workloadSecretID: workloadSecretID,
+			meshCertLifetime: meshCertLifetime,
+			namespace:        namespace,
+			serviceAccount:   orDefault(serviceAccount, "default"),
 		})
 		return res, nil
 	}); err != nil {
@@ -106,7 +119,9 @@ func policiesFromKubeResources(fileMap map[string][]*unstructured.Unstructured)
 	return deployments, nil
 }
 
-func manifestPolicyMapFromPolicies(policies []deployment) (map[manifest.HexString]manifest.PolicyEntry, error) {
+// manifestPolicyMapFromPolicies creates the policy entries of the manifest. If withSPIFFE is set,
+// the entries get the SPIFFE path of the deployment's service account.
+func manifestPolicyMapFromPolicies(policies []deployment, withSPIFFE bool) (map[manifest.HexString]manifest.PolicyEntry, error) {
 	policyHashes := make(map[manifest.HexString]manifest.PolicyEntry)
 	for _, depl := range policies {
 		hash, err := depl.initdata.Digest()
@@ -125,6 +140,10 @@ func manifestPolicyMapFromPolicies(policies []deployment) (map[manifest.HexStrin
 			SANs:             depl.DNSNames(),
 			WorkloadSecretID: depl.workloadSecretID,
 			Role:             depl.role,
+			MeshCertLifetime: depl.meshCertLifetime,
+		}
+		if withSPIFFE {
+			entry.SPIFFEPath = spiffe.KubernetesPath(depl.namespace, depl.serviceAccount)
 		}
 		policyHashes[manifest.NewHexString(hash)] = entry
 	}
@@ -154,6 +173,9 @@ type deployment struct {
 	initdata         initdata.Raw
 	role             manifest.Role
 	workloadSecretID string
+	meshCertLifetime string
+	namespace        string
+	serviceAccount   string
 }
 
 func (d deployment) DNSNames() []string {
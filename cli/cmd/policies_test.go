@@ -51,6 +51,8 @@ func TestPoliciesFromKubeResources(t *testing.T) {
 					initdata:         serialized,
 					role:             manifest.RoleCoordinator,
 					workloadSecretID: "apps/v1/Deployment/default/test",
+					namespace:        "default",
+					serviceAccount:   "default",
 				},
 			},
 		},
@@ -112,12 +114,16 @@ func TestPoliciesFromKubeResources(t *testing.T) {
 					initdata:         serialized,
 					role:             manifest.RoleCoordinator,
 					workloadSecretID: "apps/v1/Deployment/default/test",
+					namespace:        "default",
+					serviceAccount:   "default",
 				},
 				{
 					name:             "another-pod",
 					initdata:         serialized,
 					role:             manifest.RoleNone,
 					workloadSecretID: "core/v1/Pod/default/another-pod",
+					namespace:        "default",
+					serviceAccount:   "default",
 				},
 			},
 		},
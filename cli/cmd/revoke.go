@@ -0,0 +1,203 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package cmd
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/edgelesssys/contrast/internal/atls"
+	"github.com/edgelesssys/contrast/internal/grpc/dialer"
+	"github.com/edgelesssys/contrast/internal/manifest"
+	"github.com/edgelesssys/contrast/internal/userapi"
+	"github.com/spf13/cobra"
+)
+
+// meshCRLFilename is the file the CRL of the mesh CA is written to.
+const meshCRLFilename = "mesh-crl.pem"
+
+// NewRevokeCmd creates the contrast revoke subcommand.
+func NewRevokeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revoke [flags]",
+		Short: "Revoke mesh certificates",
+		Long: `Revoke mesh certificates issued by the coordinator.
+
+This will connect to the given Coordinator using aTLS and revoke the mesh
+certificates with the given serial numbers, or all mesh certificates issued to
+workloads with the given policy hash. Any workload owner of the currently
+active manifest can revoke certificates. Sub CA certificates can't be revoked.
+
+Revoked certificates are listed in the CRL of the mesh CA, which the
+Coordinator hands out to workloads along with their certificates, and serves
+at the /crl endpoint of its HTTP API. The updated CRL is written to the
+workspace.`,
+		Args: cobra.NoArgs,
+		RunE: withTelemetry(runRevoke),
+	}
+
+	cmd.Flags().StringP("manifest", "m", manifestFilename, "path to the active manifest (.json) file")
+	cmd.Flags().StringP("coordinator", "c", "", "endpoint the coordinator can be reached at")
+	must(cobra.MarkFlagRequired(cmd.Flags(), "coordinator"))
+	cmd.Flags().String("workload-owner-key", workloadOwnerPEM, "path to workload owner key (.pem) file")
+	cmd.Flags().StringSlice("serial", nil, "hex-encoded serial number of a certificate to revoke (can be repeated)")
+	cmd.Flags().String("policy", "", "hex-encoded policy hash of the workload whose certificates are revoked")
+	cmd.Flags().Int32("reason", 0, "CRL reason code of the revocation, as defined in RFC 5280")
+	addCollateralProxyFlag(cmd)
+
+	return cmd
+}
+
+func runRevoke(cmd *cobra.Command, _ []string) error {
+	flags, err := parseRevokeFlags(cmd)
+	if err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+
+	log, err := newCLILogger(cmd)
+	if err != nil {
+		return err
+	}
+
+	var serialNumbers [][]byte
+	for _, serial := range flags.serials {
+		serialNumber, ok := new(big.Int).SetString(strings.TrimPrefix(serial, "0x"), 16)
+		if !ok || serialNumber.Sign() <= 0 {
+			return fmt.Errorf("invalid serial number %q", serial)
+		}
+		serialNumbers = append(serialNumbers, serialNumber.Bytes())
+	}
+	if flags.policyHash != "" {
+		if _, err := hex.DecodeString(flags.policyHash); err != nil {
+			return fmt.Errorf("decoding policy hash: %w", err)
+		}
+	}
+	if len(serialNumbers) == 0 && flags.policyHash == "" {
+		return errors.New("either --serial or --policy must be given")
+	}
+
+	manifestBytes, err := os.ReadFile(flags.manifestPath)
+	if err != nil {
+		return fmt.Errorf("failed to read manifest file: %w", err)
+	}
+	var m manifest.Manifest
+	if err := json.Unmarshal(manifestBytes, &m); err != nil {
+		return fmt.Errorf("failed to unmarshal manifest: %w", err)
+	}
+	workloadOwnerKey, err := loadWorkloadOwnerKey(flags.workloadOwnerKeyPath, nil, log)
+	if err != nil {
+		return fmt.Errorf("loading workload owner key: %w", err)
+	}
+
+	kdsGetter, err := cachedHTTPSGetter(log, flags.collateralProxyURL)
+	if err != nil {
+		return fmt.Errorf("configuring KDS cache: %w", err)
+	}
+	validator, err := m.CoordinatorValidator(log, kdsGetter)
+	if err != nil {
+		return fmt.Errorf("getting validators: %w", err)
+	}
+
+	dialer := dialer.NewWithKey(atls.NoIssuer, validator, atls.NoMetrics, nil, workloadOwnerKey, log)
+	conn, err := dialer.Dial(cmd.Context(), flags.coordinator)
+	if err != nil {
+		return fmt.Errorf("dialing coordinator: %w", err)
+	}
+	defer conn.Close()
+
+	client := userapi.NewUserAPIClient(conn)
+	resp, err := client.RevokeMeshCerts(cmd.Context(), &userapi.RevokeMeshCertsRequest{
+		SerialNumbers: serialNumbers,
+		PolicyHash:    flags.policyHash,
+		ReasonCode:    flags.reason,
+	})
+	if err != nil {
+		return fmt.Errorf("revoking mesh certificates: %w", err)
+	}
+
+	if len(resp.GetSerialNumbers()) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "No certificates were newly revoked")
+	}
+	for _, serialNumber := range resp.GetSerialNumbers() {
+		fmt.Fprintf(cmd.OutOrStdout(), "✔️ Revoked certificate %s\n", new(big.Int).SetBytes(serialNumber).Text(16))
+	}
+
+	if err := writeFilelist(flags.workspaceDir, map[string][]byte{meshCRLFilename: resp.GetCRL()}); err != nil {
+		return fmt.Errorf("writing CRL: %w", err)
+	}
+	return nil
+}
+
+type revokeFlags struct {
+	manifestPath         string
+	coordinator          string
+	workloadOwnerKeyPath string
+	serials              []string
+	policyHash           string
+	reason               int32
+	workspaceDir         string
+	collateralProxyURL   string
+}
+
+func parseRevokeFlags(cmd *cobra.Command) (*revokeFlags, error) {
+	manifestPath, err := cmd.Flags().GetString("manifest")
+	if err != nil {
+		return nil, err
+	}
+	coordinator, err := cmd.Flags().GetString("coordinator")
+	if err != nil {
+		return nil, err
+	}
+	workloadOwnerKeyPath, err := cmd.Flags().GetString("workload-owner-key")
+	if err != nil {
+		return nil, err
+	}
+	serials, err := cmd.Flags().GetStringSlice("serial")
+	if err != nil {
+		return nil, err
+	}
+	policyHash, err := cmd.Flags().GetString("policy")
+	if err != nil {
+		return nil, err
+	}
+	reason, err := cmd.Flags().GetInt32("reason")
+	if err != nil {
+		return nil, err
+	}
+	workspaceDir, err := cmd.Flags().GetString("workspace-dir")
+	if err != nil {
+		return nil, err
+	}
+	collateralProxyURL, err := cmd.Flags().GetString("collateral-proxy")
+	if err != nil {
+		return nil, err
+	}
+
+	if workspaceDir != "" {
+		// Prepend default paths with workspaceDir
+		if !cmd.Flags().Changed("manifest") {
+			manifestPath = filepath.Join(workspaceDir, manifestFilename)
+		}
+		if !cmd.Flags().Changed("workload-owner-key") {
+			workloadOwnerKeyPath = filepath.Join(workspaceDir, workloadOwnerKeyPath)
+		}
+	}
+
+	return &revokeFlags{
+		manifestPath:         manifestPath,
+		coordinator:          coordinator,
+		workloadOwnerKeyPath: workloadOwnerKeyPath,
+		serials:              serials,
+		policyHash:           policyHash,
+		reason:               reason,
+		workspaceDir:         workspaceDir,
+		collateralProxyURL:   collateralProxyURL,
+	}, nil
+}
@@ -0,0 +1,197 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package cmd
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/edgelesssys/contrast/internal/atls"
+	"github.com/edgelesssys/contrast/internal/grpc/dialer"
+	"github.com/edgelesssys/contrast/internal/history"
+	"github.com/edgelesssys/contrast/internal/manifest"
+	"github.com/edgelesssys/contrast/internal/userapi"
+	"github.com/spf13/cobra"
+)
+
+// NewRollbackCmd creates the contrast rollback subcommand.
+func NewRollbackCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rollback [flags] transition-hash",
+		Short: "Re-activate the manifest of an earlier transition",
+		Long: `Re-activate the manifest of an earlier transition.
+
+This will connect to the given Coordinator using aTLS and roll back to the
+manifest of the given transition, which must be part of the Coordinator's
+manifest history. Use 'contrast history' to find the transition hash.
+
+The rollback creates a new transition and needs to be authorized by the
+workload owners of the active manifest, like any other manifest update.
+To sign the rollback with external keys, run 'contrast sign' on the manifest
+that's rolled back to, for example a manifest written by 'contrast verify'.
+
+The Coordinator refuses to roll back across a change of seedshare owners.`,
+		Args: cobra.ExactArgs(1),
+		RunE: withTelemetry(runRollback),
+	}
+	cmd.SetOut(commandOut())
+
+	cmd.Flags().StringP("manifest", "m", manifestFilename, "path to the active manifest (.json) file")
+	cmd.Flags().StringP("coordinator", "c", "", "endpoint the coordinator can be reached at")
+	must(cobra.MarkFlagRequired(cmd.Flags(), "coordinator"))
+	cmd.Flags().String("workload-owner-key", workloadOwnerPEM, "path to workload owner key (.pem) file")
+	cmd.Flags().StringArrayP("signature", "s", nil, "path to a detached transition signature (DER) or signature bundle file, can be repeated")
+	must(cmd.MarkFlagFilename("signature"))
+	addCollateralProxyFlag(cmd)
+
+	return cmd
+}
+
+func runRollback(cmd *cobra.Command, args []string) error {
+	flags, err := parseRollbackFlags(cmd)
+	if err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+
+	log, err := newCLILogger(cmd)
+	if err != nil {
+		return err
+	}
+
+	transitionHash, err := hex.DecodeString(args[0])
+	if err != nil {
+		return fmt.Errorf("decoding transition hash: %w", err)
+	}
+	if len(transitionHash) != history.HashSize {
+		return fmt.Errorf("transition hash has invalid length %d", len(transitionHash))
+	}
+
+	manifestBytes, err := os.ReadFile(flags.manifestPath)
+	if err != nil {
+		return fmt.Errorf("failed to read manifest file: %w", err)
+	}
+	var m manifest.Manifest
+	if err := json.Unmarshal(manifestBytes, &m); err != nil {
+		return fmt.Errorf("failed to unmarshal manifest: %w", err)
+	}
+
+	workloadOwnerKey, err := loadWorkloadOwnerKey(flags.workloadOwnerKeyPath, nil, log)
+	if errors.Is(err, os.ErrNotExist) {
+		workloadOwnerKey = nil
+	} else if err != nil {
+		return fmt.Errorf("loading workload owner key: %w", err)
+	}
+	var signatures [][]byte
+	for _, signaturePath := range flags.signaturePaths {
+		signatures, err = appendSignatureFile(signatures, signaturePath)
+		if err != nil {
+			return err
+		}
+	}
+
+	kdsGetter, err := cachedHTTPSGetter(log, flags.collateralProxyURL)
+	if err != nil {
+		return fmt.Errorf("configuring KDS cache: %w", err)
+	}
+	validator, err := m.CoordinatorValidator(log, kdsGetter)
+	if err != nil {
+		return fmt.Errorf("getting validators: %w", err)
+	}
+
+	var dialr *dialer.Dialer
+	if workloadOwnerKey == nil {
+		dialr = dialer.New(atls.NoIssuer, validator, atls.NoMetrics, nil, log)
+	} else {
+		dialr = dialer.NewWithKey(atls.NoIssuer, validator, atls.NoMetrics, nil, workloadOwnerKey, log)
+	}
+	conn, err := dialr.Dial(cmd.Context(), flags.coordinator)
+	if err != nil {
+		return fmt.Errorf("dialing coordinator: %w", err)
+	}
+	defer conn.Close()
+
+	client := userapi.NewUserAPIClient(conn)
+	req := &userapi.RollbackRequest{
+		TransitionHash: transitionHash,
+		Signatures:     signatures,
+	}
+	resp, err := coordinatorLoop(cmd.Context(), cmd.OutOrStdout(), func(ctx context.Context) (*userapi.RollbackResponse, error) {
+		return client.Rollback(ctx, req)
+	})
+	if err != nil {
+		return fmt.Errorf("rolling back to transition %x: %w", transitionHash, err)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "✔️ Rolled back to the manifest of transition %x\n", transitionHash)
+	fmt.Fprintf(cmd.OutOrStdout(), "New transition hash: %x\n", resp.GetTransitionHash())
+
+	filelist := map[string][]byte{
+		coordRootPEMFilename: resp.GetRootCA(),
+		meshCAPEMFilename:    resp.GetMeshCA(),
+	}
+	if err := writeFilelist(flags.workspaceDir, filelist); err != nil {
+		return fmt.Errorf("writing filelist: %w", err)
+	}
+	return nil
+}
+
+type rollbackFlags struct {
+	manifestPath         string
+	coordinator          string
+	workloadOwnerKeyPath string
+	signaturePaths       []string
+	workspaceDir         string
+	collateralProxyURL   string
+}
+
+func parseRollbackFlags(cmd *cobra.Command) (*rollbackFlags, error) {
+	manifestPath, err := cmd.Flags().GetString("manifest")
+	if err != nil {
+		return nil, err
+	}
+	coordinator, err := cmd.Flags().GetString("coordinator")
+	if err != nil {
+		return nil, err
+	}
+	workloadOwnerKeyPath, err := cmd.Flags().GetString("workload-owner-key")
+	if err != nil {
+		return nil, err
+	}
+	signaturePaths, err := cmd.Flags().GetStringArray("signature")
+	if err != nil {
+		return nil, err
+	}
+	workspaceDir, err := cmd.Flags().GetString("workspace-dir")
+	if err != nil {
+		return nil, err
+	}
+	collateralProxyURL, err := cmd.Flags().GetString("collateral-proxy")
+	if err != nil {
+		return nil, err
+	}
+
+	if workspaceDir != "" {
+		// Prepend default paths with workspaceDir
+		if !cmd.Flags().Changed("manifest") {
+			manifestPath = filepath.Join(workspaceDir, manifestFilename)
+		}
+		if !cmd.Flags().Changed("workload-owner-key") {
+			workloadOwnerKeyPath = filepath.Join(workspaceDir, workloadOwnerKeyPath)
+		}
+	}
+
+	return &rollbackFlags{
+		manifestPath:         manifestPath,
+		coordinator:          coordinator,
+		workloadOwnerKeyPath: workloadOwnerKeyPath,
+		signaturePaths:       signaturePaths,
+		workspaceDir:         workspaceDir,
+		collateralProxyURL:   collateralProxyURL,
+	}, nil
+}
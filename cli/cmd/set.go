@@ -46,7 +46,19 @@ reference values embedded into the CLI.
 
 After the connection is established, the manifest is set. The Coordinator
 will re-generate the mesh CA certificate and accept new workloads to
-issuer certificates.`,
+issuer certificates.
+
+With --dry-run, the Coordinator performs all checks of a manifest update
+without applying it, and the CLI prints the changes compared to the
+currently active manifest.
+
+If the current manifest requires the approval of multiple workload owners,
+pass a signature bundle created with 'contrast sign', or one signature file
+per workload owner by repeating --signature.
+
+With --activation-time, the Coordinator schedules the manifest update and
+applies it once the activation time is reached. The current manifest stays
+active until then, and the update can be cancelled with 'contrast cancel'.`,
 		RunE: withTelemetry(runSet),
 	}
 	cmd.SetOut(commandOut())
@@ -57,8 +69,10 @@ issuer certificates.`,
 	cmd.Flags().String("workload-owner-key", workloadOwnerPEM, "path to workload owner key (.pem) file")
 	cmd.Flags().Bool("atomic", false, "only set the manifest if the coordinator's state matches the latest transition hash")
 	cmd.Flags().String("latest-transition", "", "latest transition hash set at the coordinator (hex string)")
-	cmd.Flags().StringP("signature", "s", "", "path to a detached transition signature (DER) file")
+	cmd.Flags().StringArrayP("signature", "s", nil, "path to a detached transition signature (DER) or signature bundle file, can be repeated")
 	must(cmd.MarkFlagFilename("signature"))
+	cmd.Flags().Bool("dry-run", false, "check the manifest update at the coordinator and print the changes without applying them")
+	cmd.Flags().String("activation-time", "", "schedule the manifest update for the given time (RFC 3339) instead of applying it immediately")
 	addCollateralProxyFlag(cmd)
 
 	return cmd
@@ -93,11 +107,11 @@ func runSet(cmd *cobra.Command, args []string) error {
 	} else if err != nil {
 		return fmt.Errorf("loading workload owner key: %w", err)
 	}
-	var signatureBytes []byte
-	if flags.signaturePath != "" {
-		signatureBytes, err = os.ReadFile(flags.signaturePath)
+	var signatures [][]byte
+	for _, signaturePath := range flags.signaturePaths {
+		signatures, err = appendSignatureFile(signatures, signaturePath)
 		if err != nil {
-			return fmt.Errorf("reading signature file: %w", err)
+			return err
 		}
 	}
 
@@ -162,29 +176,39 @@ func runSet(cmd *cobra.Command, args []string) error {
 		Manifest:               manifestBytes,
 		Policies:               getInitdataDocuments(policies),
 		PreviousTransitionHash: previousTransitionHash,
-		Signature:              signatureBytes,
+		Signatures:             signatures,
 	}
-	resp, err := setLoop(cmd.Context(), client, cmd.OutOrStdout(), req)
-	if err != nil {
-		grpcSt, ok := status.FromError(err)
-		if ok {
-			if grpcSt.Code() == codes.PermissionDenied {
-				msg := "Permission denied."
-				if signatureBytes != nil {
-					msg += " Ensure the signature is valid and corresponds to the latest transition hash."
-				} else if workloadOwnerKey == nil {
-					msg += " Specify a workload owner key with --workload-owner-key."
-				} else {
-					msg += " Ensure you are using a trusted workload owner key."
-				}
-				fmt.Fprintln(cmd.OutOrStdout(), msg)
-			}
+	if !flags.activationTime.IsZero() {
+		req.ActivationTime = flags.activationTime.Unix()
+	}
+
+	if flags.dryRun {
+		resp, err := dryRunSetLoop(cmd.Context(), client, cmd.OutOrStdout(), req)
+		if err != nil {
+			return setError(cmd.OutOrStdout(), err, len(signatures) > 0, workloadOwnerKey != nil)
+		}
+		diff, err := diffFromProto(resp.GetDiff())
+		if err != nil {
+			return fmt.Errorf("parsing manifest diff: %w", err)
 		}
-		additionalHelp := ""
-		if strings.Contains(err.Error(), "quote field MR_CONFIG_ID") || strings.Contains(err.Error(), "report field HOST_DATA") {
-			additionalHelp = " (coordinator did not match the expectations, is the version correct and did you run `contrast generate`?)"
+		fmt.Fprintln(cmd.OutOrStdout(), "✔️ Manifest update would be accepted by the coordinator")
+		if err := writeManifestDiff(cmd.OutOrStdout(), diff); err != nil {
+			return fmt.Errorf("writing manifest diff: %w", err)
 		}
-		return fmt.Errorf("setting manifest%s: %w", additionalHelp, err)
+		fmt.Fprintf(cmd.OutOrStdout(), "Next transition hash: %x\n", resp.GetNextTransitionHash())
+		return nil
+	}
+
+	resp, err := setLoop(cmd.Context(), client, cmd.OutOrStdout(), req)
+	if err != nil {
+		return setError(cmd.OutOrStdout(), err, len(signatures) > 0, workloadOwnerKey != nil)
+	}
+
+	if pending := resp.GetPendingUpdate(); pending != nil {
+		activationTime := time.Unix(pending.GetActivationTime(), 0)
+		fmt.Fprintf(cmd.OutOrStdout(), "✔️ Manifest update scheduled for %s\n", activationTime.Format(time.RFC3339))
+		fmt.Fprintf(cmd.OutOrStdout(), "Pending transition hash: %x\n", pending.GetTransitionHash())
+		return nil
 	}
 
 	fmt.Fprintln(cmd.OutOrStdout(), "✔️ Manifest set successfully")
@@ -210,13 +234,38 @@ func runSet(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// setError adds hints for common causes of a failed manifest update.
+func setError(out io.Writer, err error, hasSignature, hasWorkloadOwnerKey bool) error {
+	grpcSt, ok := status.FromError(err)
+	if ok {
+		if grpcSt.Code() == codes.PermissionDenied {
+			msg := "Permission denied."
+			if hasSignature {
+				msg += " Ensure the signatures are valid, correspond to the latest transition hash and reach the workload owner threshold."
+			} else if !hasWorkloadOwnerKey {
+				msg += " Specify a workload owner key with --workload-owner-key."
+			} else {
+				msg += " Ensure you are using a trusted workload owner key."
+			}
+			fmt.Fprintln(out, msg)
+		}
+	}
+	additionalHelp := ""
+	if strings.Contains(err.Error(), "quote field MR_CONFIG_ID") || strings.Contains(err.Error(), "report field HOST_DATA") {
+		additionalHelp = " (coordinator did not match the expectations, is the version correct and did you run `contrast generate`?)"
+	}
+	return fmt.Errorf("setting manifest%s: %w", additionalHelp, err)
+}
+
 type setFlags struct {
 	manifestPath         string
 	coordinator          string
 	workloadOwnerKeyPath string
 	atomic               bool
 	latestTransition     string
-	signaturePath        string
+	signaturePaths       []string
+	dryRun               bool
+	activationTime       time.Time
 	workspaceDir         string
 	collateralProxyURL   string
 }
@@ -248,10 +297,27 @@ func parseSetFlags(cmd *cobra.Command) (*setFlags, error) {
 	if !flags.atomic && flags.latestTransition != "" {
 		return nil, fmt.Errorf("\"latest-transition\" flag cannot be set without \"atomic\" flag")
 	}
-	flags.signaturePath, err = cmd.Flags().GetString("signature")
+	flags.signaturePaths, err = cmd.Flags().GetStringArray("signature")
 	if err != nil {
 		return nil, fmt.Errorf("getting signature flag: %w", err)
 	}
+	flags.dryRun, err = cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return nil, fmt.Errorf("getting dry-run flag: %w", err)
+	}
+	activationTime, err := cmd.Flags().GetString("activation-time")
+	if err != nil {
+		return nil, fmt.Errorf("getting activation-time flag: %w", err)
+	}
+	if activationTime != "" {
+		if flags.dryRun {
+			return nil, fmt.Errorf("\"activation-time\" flag cannot be used with \"dry-run\" flag")
+		}
+		flags.activationTime, err = time.Parse(time.RFC3339, activationTime)
+		if err != nil {
+			return nil, fmt.Errorf("parsing activation time: %w", err)
+		}
+	}
 	flags.workspaceDir, err = cmd.Flags().GetString("workspace-dir")
 	if err != nil {
 		return nil, fmt.Errorf("getting workspace-dir flag: %w", err)
@@ -308,15 +374,15 @@ func loadWorkloadOwnerKey(path string, manifst *manifest.Manifest, log *slog.Log
 	return workloadOwnerKey, nil
 }
 
-type setDoer struct {
-	client userapi.UserAPIClient
-	req    *userapi.SetManifestRequest
+// coordinatorDoer calls a single RPC on the coordinator and stores the response.
+type coordinatorDoer[T any] struct {
+	do func(ctx context.Context) (T, error)
 
-	resp *userapi.SetManifestResponse
+	resp T
 }
 
-func (d *setDoer) Do(ctx context.Context) error {
-	resp, err := d.client.SetManifest(ctx, d.req)
+func (d *coordinatorDoer[T]) Do(ctx context.Context) error {
+	resp, err := d.do(ctx)
 	if err == nil {
 		d.resp = resp
 		return nil
@@ -326,7 +392,24 @@ func (d *setDoer) Do(ctx context.Context) error {
 
 func setLoop(
 	ctx context.Context, client userapi.UserAPIClient, out io.Writer, req *userapi.SetManifestRequest,
-) (resp *userapi.SetManifestResponse, retErr error) {
+) (*userapi.SetManifestResponse, error) {
+	return coordinatorLoop(ctx, out, func(ctx context.Context) (*userapi.SetManifestResponse, error) {
+		return client.SetManifest(ctx, req)
+	})
+}
+
+func dryRunSetLoop(
+	ctx context.Context, client userapi.UserAPIClient, out io.Writer, req *userapi.SetManifestRequest,
+) (*userapi.DryRunSetManifestResponse, error) {
+	return coordinatorLoop(ctx, out, func(ctx context.Context) (*userapi.DryRunSetManifestResponse, error) {
+		return client.DryRunSetManifest(ctx, req)
+	})
+}
+
+// coordinatorLoop retries the RPC while the coordinator is unavailable, showing a spinner.
+func coordinatorLoop[T any](
+	ctx context.Context, out io.Writer, do func(ctx context.Context) (T, error),
+) (resp T, retErr error) {
 	spinner := spinner.New("  Waiting for coordinator ", 500*time.Millisecond, out)
 	spinner.Start()
 	defer func() {
@@ -337,17 +420,14 @@ func setLoop(
 		}
 	}()
 
-	doer := &setDoer{
-		client: client,
-		req:    req,
-	}
+	doer := &coordinatorDoer[T]{do: do}
 
 	ctx, cancel := context.WithTimeout(ctx, 180*time.Second)
 	defer cancel()
 
 	retrier := retry.NewIntervalRetrier(doer, time.Second, grpcRetry.ServiceIsUnavailable)
 	if err := retrier.Do(ctx); err != nil {
-		return nil, err
+		return resp, err
 	}
 
 	return doer.resp, nil
@@ -15,6 +15,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/edgelesssys/contrast/internal/history"
 	"github.com/edgelesssys/contrast/internal/manifest"
@@ -34,7 +35,19 @@ signed manifest update at the Coordinator without providing the workload owner
 key to the CLI setting the manifest.
 
 Using the prepare flag, the CLI will compute the next transition hash and
-output it to a file so it can be signed using an external tool like an HSM.`,
+output it to a file so it can be signed using an external tool like an HSM.
+
+If the current manifest requires the approval of multiple workload owners,
+each owner signs the transition hash and the signatures are merged into a
+signature bundle with the merge flag. When merging, the CLI only adds a
+signature of its own if the workload owner key is given explicitly.
+
+Using the cancel flag, the CLI signs the cancellation of the scheduled update
+with the given transition hash instead. The manifest is then the active one.
+
+Using the sub-ca flag, the CLI signs the issuance of a sub CA for the given
+certificate signing request instead. The names and the lifetime must match the
+ones passed to 'contrast sub-ca', and the manifest is the active one.`,
 		RunE: withTelemetry(runSign),
 	}
 	cmd.SetOut(commandOut())
@@ -43,6 +56,11 @@ output it to a file so it can be signed using an external tool like an HSM.`,
 	cmd.Flags().String("workload-owner-key", workloadOwnerPEM, "path to workload owner key (.pem) file")
 	cmd.Flags().String("latest-transition", "", "latest transition hash set at the coordinator (hex string)")
 	cmd.Flags().Bool("prepare", false, "prepare the next transition hash for signing without signing it")
+	cmd.Flags().String("cancel", "", "transition hash of a scheduled update to sign the cancellation of (hex string)")
+	cmd.Flags().String("sub-ca", "", "path to the certificate signing request of a sub CA to sign the issuance of")
+	cmd.Flags().StringSlice("sub-ca-name", nil, "name the sub CA may issue certificates for (can be repeated)")
+	cmd.Flags().Duration("sub-ca-lifetime", 24*time.Hour, "lifetime of the sub CA certificate")
+	cmd.Flags().StringArray("merge", nil, "path to a signature or signature bundle file to merge into the output, can be repeated")
 	cmd.Flags().String("out", "", "output file for the signature (or next transition hash when using --prepare)")
 	must(cmd.MarkFlagRequired("out"))
 	must(cmd.MarkFlagFilename("manifest", "json"))
@@ -73,10 +91,105 @@ func runSign(cmd *cobra.Command, _ []string) error {
 		return fmt.Errorf("validating manifest: %w", err)
 	}
 
+	// what describes the signed message in the output.
+	var message []byte
+	what := "Transition hash"
+	switch {
+	case flags.cancel != "":
+		message, err = cancellationMessage(flags.cancel)
+		what = "Cancellation message"
+	case flags.subCACSRPath != "":
+		message, err = subCAMessage(flags)
+		what = "Sub CA request"
+	default:
+		message, err = transitionMessage(manifestBytes, flags)
+	}
+	if err != nil {
+		return err
+	}
+
+	if flags.prepare {
+		if err := os.WriteFile(flags.out, message, 0o644); err != nil {
+			return fmt.Errorf("writing message to file: %w", err)
+		}
+		if flags.cancel != "" || flags.subCACSRPath != "" {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s written to %s.\n", what, flags.out)
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "Next transition hash written to %s.\n", flags.out)
+		}
+		return nil
+	}
+
+	var signatures [][]byte
+	for _, mergePath := range flags.mergePaths {
+		signatures, err = appendSignatureFile(signatures, mergePath)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(flags.mergePaths) == 0 || cmd.Flags().Changed("workload-owner-key") {
+		workloadOwnerKey, err := loadWorkloadOwnerKey(flags.workloadOwnerKeyPath, &m, log)
+		if err != nil {
+			return fmt.Errorf("loading workload owner key: %w", err)
+		}
+
+		signingHash := sha256.Sum256(message)
+		sig, err := ecdsa.SignASN1(rand.Reader, workloadOwnerKey, signingHash[:])
+		if err != nil {
+			return fmt.Errorf("signing message: %w", err)
+		}
+		signatures = append(signatures, sig)
+	}
+
+	if err := os.WriteFile(flags.out, history.JoinSignatures(signatures...), 0o644); err != nil {
+		return fmt.Errorf("writing signature to file: %w", err)
+	}
+	if len(flags.mergePaths) == 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s signed and signature written to %s.\n", what, flags.out)
+	} else {
+		fmt.Fprintf(cmd.OutOrStdout(), "Signature bundle written to %s.\n", flags.out)
+	}
+
+	return nil
+}
+
+// transitionMessage returns the hex-encoded hash of the transition from the latest transition to
+// the manifest, which workload owners sign to authorize the transition.
+func transitionMessage(manifestBytes []byte, flags *signFlags) ([]byte, error) {
+	previousTransitionHash, err := latestTransitionHash(flags)
+	if err != nil {
+		return nil, err
+	}
+	tr := &history.Transition{
+		ManifestHash:           history.Digest(manifestBytes),
+		PreviousTransitionHash: previousTransitionHash,
+	}
+	transitionHash := tr.Digest()
+	return hex.AppendEncode(nil, transitionHash[:]), nil
+}
+
+// subCAMessage returns the text workload owners sign to authorize the issuance of a sub CA for
+// the certificate signing request, names and lifetime given in flags.
+func subCAMessage(flags *signFlags) ([]byte, error) {
+	csr, err := os.ReadFile(flags.subCACSRPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading CSR: %w", err)
+	}
+	latest, err := latestTransitionHash(flags)
+	if err != nil {
+		return nil, err
+	}
+	return history.SubCASigningText(latest, csr, flags.subCANames, int64(flags.subCALifetime.Seconds())), nil
+}
+
+// latestTransitionHash returns the latest transition hash set at the Coordinator, as given in
+// flags or stored in the workspace.
+func latestTransitionHash(flags *signFlags) ([history.HashSize]byte, error) {
 	if flags.latestTransition == "" {
 		data, err := os.ReadFile(filepath.Join(flags.workspaceDir, verifyDir, latestTransitionHashFilename))
 		if err != nil && !errors.Is(err, os.ErrNotExist) {
-			return fmt.Errorf("reading previous transition hash: %w", err)
+			return [history.HashSize]byte{}, fmt.Errorf("reading previous transition hash: %w", err)
 		} else if errors.Is(err, os.ErrNotExist) {
 			data = []byte(strings.Repeat("00", history.HashSize)) // Assume initial set manifest
 		}
@@ -84,44 +197,39 @@ func runSign(cmd *cobra.Command, _ []string) error {
 	}
 	previousTransitionHash, err := hex.DecodeString(flags.latestTransition)
 	if err != nil {
-		return fmt.Errorf("decoding latest transition hash: %w", err)
+		return [history.HashSize]byte{}, fmt.Errorf("decoding latest transition hash: %w", err)
 	}
 	if len(previousTransitionHash) != history.HashSize {
-		return fmt.Errorf("invalid latest transition hash byte length: got %d, want %d", len(previousTransitionHash), history.HashSize)
+		return [history.HashSize]byte{}, fmt.Errorf("invalid latest transition hash byte length: got %d, want %d", len(previousTransitionHash), history.HashSize)
 	}
+	return [history.HashSize]byte(previousTransitionHash), nil
+}
 
-	tr := &history.Transition{
-		ManifestHash:           history.Digest(manifestBytes),
-		PreviousTransitionHash: [history.HashSize]byte(previousTransitionHash),
+// cancellationMessage returns the text workload owners sign to authorize the cancellation of the
+// scheduled update with the given hex-encoded transition hash.
+func cancellationMessage(transitionHashHex string) ([]byte, error) {
+	transitionHash, err := hex.DecodeString(transitionHashHex)
+	if err != nil {
+		return nil, fmt.Errorf("decoding transition hash: %w", err)
 	}
-	transitionHash := tr.Digest()
-	transitionHashHex := hex.AppendEncode(nil, transitionHash[:])
-
-	if flags.prepare {
-		if err := os.WriteFile(flags.out, transitionHashHex, 0o644); err != nil {
-			return fmt.Errorf("writing next transition hash to file: %w", err)
-		}
-		fmt.Fprintf(cmd.OutOrStdout(), "Next transition hash written to %s.\n", flags.out)
-		return nil
+	if len(transitionHash) != history.HashSize {
+		return nil, fmt.Errorf("invalid transition hash byte length: got %d, want %d", len(transitionHash), history.HashSize)
 	}
+	return history.CancellationSigningText([history.HashSize]byte(transitionHash)), nil
+}
 
-	workloadOwnerKey, err := loadWorkloadOwnerKey(flags.workloadOwnerKeyPath, &m, log)
+// appendSignatureFile reads a signature or signature bundle file and appends the contained
+// signatures.
+func appendSignatureFile(signatures [][]byte, path string) ([][]byte, error) {
+	bundle, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("loading workload owner key: %w", err)
+		return nil, fmt.Errorf("reading signature file: %w", err)
 	}
-
-	signingHash := sha256.Sum256(transitionHashHex)
-	sig, err := ecdsa.SignASN1(rand.Reader, workloadOwnerKey, signingHash[:])
+	split, err := history.SplitSignatures(bundle)
 	if err != nil {
-		return fmt.Errorf("signing transition hash: %w", err)
-	}
-
-	if err := os.WriteFile(flags.out, sig, 0o644); err != nil {
-		return fmt.Errorf("writing signature to file: %w", err)
+		return nil, fmt.Errorf("parsing signature file %s: %w", path, err)
 	}
-	fmt.Fprintf(cmd.OutOrStdout(), "Transition hash signed and signature written to %s.\n", flags.out)
-
-	return nil
+	return append(signatures, split...), nil
 }
 
 type signFlags struct {
@@ -129,6 +237,11 @@ type signFlags struct {
 	workloadOwnerKeyPath string
 	latestTransition     string
 	prepare              bool
+	cancel               string
+	subCACSRPath         string
+	subCANames           []string
+	subCALifetime        time.Duration
+	mergePaths           []string
 	out                  string
 	workspaceDir         string
 }
@@ -153,6 +266,38 @@ func parseSignFlags(cmd *cobra.Command) (*signFlags, error) {
 	if err != nil {
 		return nil, fmt.Errorf("getting prepare flag: %w", err)
 	}
+	flags.cancel, err = cmd.Flags().GetString("cancel")
+	if err != nil {
+		return nil, fmt.Errorf("getting cancel flag: %w", err)
+	}
+	if flags.cancel != "" && flags.latestTransition != "" {
+		return nil, errors.New("\"latest-transition\" flag cannot be used with \"cancel\" flag")
+	}
+	flags.subCACSRPath, err = cmd.Flags().GetString("sub-ca")
+	if err != nil {
+		return nil, fmt.Errorf("getting sub-ca flag: %w", err)
+	}
+	if flags.subCACSRPath != "" && flags.cancel != "" {
+		return nil, errors.New("\"sub-ca\" flag cannot be used with \"cancel\" flag")
+	}
+	flags.subCANames, err = cmd.Flags().GetStringSlice("sub-ca-name")
+	if err != nil {
+		return nil, fmt.Errorf("getting sub-ca-name flag: %w", err)
+	}
+	if flags.subCACSRPath != "" && len(flags.subCANames) == 0 {
+		return nil, errors.New("\"sub-ca\" flag requires \"sub-ca-name\" flag")
+	}
+	flags.subCALifetime, err = cmd.Flags().GetDuration("sub-ca-lifetime")
+	if err != nil {
+		return nil, fmt.Errorf("getting sub-ca-lifetime flag: %w", err)
+	}
+	flags.mergePaths, err = cmd.Flags().GetStringArray("merge")
+	if err != nil {
+		return nil, fmt.Errorf("getting merge flag: %w", err)
+	}
+	if flags.prepare && len(flags.mergePaths) > 0 {
+		return nil, errors.New("\"merge\" flag cannot be used with \"prepare\" flag")
+	}
 	flags.out, err = cmd.Flags().GetString("out")
 	if err != nil {
 		return nil, fmt.Errorf("getting dry-run flag: %w", err)
@@ -0,0 +1,220 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/edgelesssys/contrast/internal/atls"
+	"github.com/edgelesssys/contrast/internal/grpc/dialer"
+	"github.com/edgelesssys/contrast/internal/manifest"
+	"github.com/edgelesssys/contrast/internal/userapi"
+	"github.com/spf13/cobra"
+)
+
+// subCAFilename is the file the sub CA certificate chain is written to.
+const subCAFilename = "sub-ca.pem"
+
+// NewSubCACmd creates the contrast sub-ca subcommand.
+func NewSubCACmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sub-ca [flags]",
+		Short: "Issue a name-constrained sub CA certificate",
+		Long: `Issue a name-constrained sub CA certificate from the mesh CA.
+
+This will connect to the given Coordinator using aTLS and request a CA
+certificate for the key of the given certificate signing request. The sub CA
+can only issue certificates for the given names, and its certificate has a
+short lifetime. This allows components outside of the Contrast deployment,
+for example an ingress controller in its own confidential VM, to issue
+certificates that are trusted by the mesh CA. The issuance needs to be
+approved by the workload owners of the active manifest, like a manifest
+update. To sign the issuance with external keys, run 'contrast sign --sub-ca'
+with the same CSR, names and lifetime.
+
+The sub CA certificate can't be revoked. Certificates issued by the sub CA
+are only meant for the TLS connections of the workloads it issues them to,
+with peers outside of the Contrast deployment. The service mesh proxies, the
+transit engine API and the KMIP server of the deployment reject them.
+
+The sub CA certificate, followed by the mesh CA certificate, is written to the
+workspace.`,
+		Args: cobra.NoArgs,
+		RunE: withTelemetry(runSubCA),
+	}
+
+	cmd.Flags().StringP("manifest", "m", manifestFilename, "path to the active manifest (.json) file")
+	cmd.Flags().StringP("coordinator", "c", "", "endpoint the coordinator can be reached at")
+	must(cobra.MarkFlagRequired(cmd.Flags(), "coordinator"))
+	cmd.Flags().String("workload-owner-key", workloadOwnerPEM, "path to workload owner key (.pem) file")
+	cmd.Flags().StringArrayP("signature", "s", nil, "path to a detached sub CA signature (DER) or signature bundle file, can be repeated")
+	must(cmd.MarkFlagFilename("signature"))
+	cmd.Flags().String("csr", "", "path to the PEM-encoded certificate signing request of the sub CA key")
+	must(cobra.MarkFlagRequired(cmd.Flags(), "csr"))
+	cmd.Flags().StringSlice("name", nil, "name the sub CA may issue certificates for (can be repeated)")
+	must(cobra.MarkFlagRequired(cmd.Flags(), "name"))
+	cmd.Flags().Duration("lifetime", 24*time.Hour, "lifetime of the sub CA certificate")
+	addCollateralProxyFlag(cmd)
+
+	return cmd
+}
+
+func runSubCA(cmd *cobra.Command, _ []string) error {
+	flags, err := parseSubCAFlags(cmd)
+	if err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+
+	log, err := newCLILogger(cmd)
+	if err != nil {
+		return err
+	}
+
+	if flags.lifetime < time.Second {
+		return errors.New("lifetime must be at least one second")
+	}
+	csr, err := os.ReadFile(flags.csrPath)
+	if err != nil {
+		return fmt.Errorf("reading CSR: %w", err)
+	}
+
+	manifestBytes, err := os.ReadFile(flags.manifestPath)
+	if err != nil {
+		return fmt.Errorf("failed to read manifest file: %w", err)
+	}
+	var m manifest.Manifest
+	if err := json.Unmarshal(manifestBytes, &m); err != nil {
+		return fmt.Errorf("failed to unmarshal manifest: %w", err)
+	}
+	workloadOwnerKey, err := loadWorkloadOwnerKey(flags.workloadOwnerKeyPath, nil, log)
+	if errors.Is(err, os.ErrNotExist) {
+		workloadOwnerKey = nil
+	} else if err != nil {
+		return fmt.Errorf("loading workload owner key: %w", err)
+	}
+	var signatures [][]byte
+	for _, signaturePath := range flags.signaturePaths {
+		signatures, err = appendSignatureFile(signatures, signaturePath)
+		if err != nil {
+			return err
+		}
+	}
+
+	kdsGetter, err := cachedHTTPSGetter(log, flags.collateralProxyURL)
+	if err != nil {
+		return fmt.Errorf("configuring KDS cache: %w", err)
+	}
+	validator, err := m.CoordinatorValidator(log, kdsGetter)
+	if err != nil {
+		return fmt.Errorf("getting validators: %w", err)
+	}
+
+	var dialr *dialer.Dialer
+	if workloadOwnerKey == nil {
+		dialr = dialer.New(atls.NoIssuer, validator, atls.NoMetrics, nil, log)
+	} else {
+		dialr = dialer.NewWithKey(atls.NoIssuer, validator, atls.NoMetrics, nil, workloadOwnerKey, log)
+	}
+	conn, err := dialr.Dial(cmd.Context(), flags.coordinator)
+	if err != nil {
+		return fmt.Errorf("dialing coordinator: %w", err)
+	}
+	defer conn.Close()
+
+	client := userapi.NewUserAPIClient(conn)
+	resp, err := client.IssueSubCA(cmd.Context(), &userapi.IssueSubCARequest{
+		CSR:             csr,
+		Names:           flags.names,
+		LifetimeSeconds: int64(flags.lifetime.Seconds()),
+		Signatures:      signatures,
+	})
+	if err != nil {
+		return fmt.Errorf("issuing sub CA: %w", err)
+	}
+
+	chain := append(resp.GetCertificate(), resp.GetMeshCACert()...)
+	if err := writeFilelist(flags.workspaceDir, map[string][]byte{subCAFilename: chain}); err != nil {
+		return fmt.Errorf("writing sub CA certificate: %w", err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "✔️ Wrote sub CA certificate to %s\n", filepath.Join(flags.workspaceDir, subCAFilename))
+	return nil
+}
+
+type subCAFlags struct {
+	manifestPath         string
+	coordinator          string
+	workloadOwnerKeyPath string
+	signaturePaths       []string
+	csrPath              string
+	names                []string
+	lifetime             time.Duration
+	workspaceDir         string
+	collateralProxyURL   string
+}
+
+func parseSubCAFlags(cmd *cobra.Command) (*subCAFlags, error) {
+	manifestPath, err := cmd.Flags().GetString("manifest")
+	if err != nil {
+		return nil, err
+	}
+	coordinator, err := cmd.Flags().GetString("coordinator")
+	if err != nil {
+		return nil, err
+	}
+	workloadOwnerKeyPath, err := cmd.Flags().GetString("workload-owner-key")
+	if err != nil {
+		return nil, err
+	}
+	signaturePaths, err := cmd.Flags().GetStringArray("signature")
+	if err != nil {
+		return nil, err
+	}
+	csrPath, err := cmd.Flags().GetString("csr")
+	if err != nil {
+		return nil, err
+	}
+	names, err := cmd.Flags().GetStringSlice("name")
+	if err != nil {
+		return nil, err
+	}
+	lifetime, err := cmd.Flags().GetDuration("lifetime")
+	if err != nil {
+		return nil, err
+	}
+	workspaceDir, err := cmd.Flags().GetString("workspace-dir")
+	if err != nil {
+		return nil, err
+	}
+	collateralProxyURL, err := cmd.Flags().GetString("collateral-proxy")
+	if err != nil {
+		return nil, err
+	}
+
+	if workspaceDir != "" {
+		// Prepend default paths with workspaceDir
+		if !cmd.Flags().Changed("manifest") {
+			manifestPath = filepath.Join(workspaceDir, manifestFilename)
+		}
+		if !cmd.Flags().Changed("workload-owner-key") {
+			workloadOwnerKeyPath = filepath.Join(workspaceDir, workloadOwnerKeyPath)
+		}
+	}
+
+	return &subCAFlags{
+		manifestPath:         manifestPath,
+		coordinator:          coordinator,
+		workloadOwnerKeyPath: workloadOwnerKeyPath,
+		signaturePaths:       signaturePaths,
+		csrPath:              csrPath,
+		names:                names,
+		lifetime:             lifetime,
+		workspaceDir:         workspaceDir,
+		collateralProxyURL:   collateralProxyURL,
+	}, nil
+}
@@ -12,15 +12,18 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/edgelesssys/contrast/internal/atls"
 	"github.com/edgelesssys/contrast/internal/attestation/certcache"
 	"github.com/edgelesssys/contrast/internal/fsstore"
 	"github.com/edgelesssys/contrast/internal/grpc/dialer"
+	"github.com/edgelesssys/contrast/internal/history"
 	"github.com/edgelesssys/contrast/internal/history/configmapstore"
 	"github.com/edgelesssys/contrast/internal/initdata"
 	"github.com/edgelesssys/contrast/internal/kuberesource"
 	"github.com/edgelesssys/contrast/internal/manifest"
+	"github.com/edgelesssys/contrast/internal/spiffe"
 	"github.com/edgelesssys/contrast/internal/userapi"
 	"github.com/edgelesssys/contrast/sdk"
 	"github.com/spf13/afero"
@@ -93,6 +96,12 @@ func runVerify(cmd *cobra.Command, _ []string) error {
 	for i, m := range resp.Manifests {
 		filelist[fmt.Sprintf("manifest.%d.json", i)] = m
 	}
+	if len(resp.PendingManifest) > 0 {
+		filelist[pendingManifestFilename] = resp.PendingManifest
+	}
+	if len(resp.SigningKey) > 0 {
+		filelist[signingKeyFilename] = resp.SigningKey
+	}
 	for _, p := range resp.Policies {
 		initdata := initdata.Raw(p)
 		digest, err := initdata.Digest()
@@ -101,11 +110,35 @@ func runVerify(cmd *cobra.Command, _ []string) error {
 		}
 		filelist[fmt.Sprintf("initdata.%x.toml", digest)] = initdata
 	}
-	history, err := configmapstore.RecoverConfigMaps(resp.Manifests, resp.Policies, resp.LatestTransitionHash, resp.LatestTransitionSignature)
+	if len(resp.Manifests) > 0 {
+		var latest manifest.Manifest
+		if err := json.Unmarshal(resp.Manifests[len(resp.Manifests)-1], &latest); err != nil {
+			return fmt.Errorf("unmarshaling active manifest: %w", err)
+		}
+		if latest.SPIFFETrustDomain != "" {
+			bundle, err := spiffe.NewBundle(resp.MeshCA)
+			if err != nil {
+				return fmt.Errorf("creating SPIFFE bundle: %w", err)
+			}
+			filelist[spiffeBundleFilename] = bundle
+		}
+	}
+	var checkpoint *history.Checkpoint
+	if len(resp.PrunedTransitionHash) > 0 {
+		if len(resp.PrunedTransitionHash) != history.HashSize {
+			return fmt.Errorf("pruned transition hash has invalid length %d", len(resp.PrunedTransitionHash))
+		}
+		checkpoint = &history.Checkpoint{
+			TransitionHash: [history.HashSize]byte(resp.PrunedTransitionHash),
+			Generation:     resp.PrunedTransitions,
+			Signature:      resp.CheckpointSignature,
+		}
+	}
+	historyConfigMaps, err := configmapstore.RecoverConfigMaps(resp.Manifests, resp.Policies, resp.LatestTransitionHash, resp.LatestTransitionSignature, checkpoint)
 	if err != nil {
 		return fmt.Errorf("getting Coordinator history: %w", err)
 	}
-	historyBytes, err := kuberesource.EncodeResources(history...)
+	historyBytes, err := kuberesource.EncodeResources(historyConfigMaps...)
 	if err != nil {
 		return fmt.Errorf("encoding Coordinator history: %w", err)
 	}
@@ -126,6 +159,12 @@ func runVerify(cmd *cobra.Command, _ []string) error {
 
 	fmt.Fprintln(cmd.OutOrStdout(), "✔️ Manifest active at Coordinator matches expected manifest")
 	fmt.Fprintln(cmd.OutOrStdout(), "  Please verify the manifest history and policies")
+	if checkpoint != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "  The Coordinator pruned the %d oldest transitions from the manifest history\n", checkpoint.Generation)
+	}
+	if len(resp.PendingManifest) > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "  A manifest update is scheduled for %s, see %s\n", resp.PendingActivationTime.Format(time.RFC3339), pendingManifestFilename)
+	}
 
 	return nil
 }
@@ -187,19 +226,51 @@ func writeFilelist(dir string, filelist map[string][]byte) error {
 
 // getCoordinatorState calls GetManifests on the coordinator's userapi via aTLS.
 func getCoordinatorState(ctx context.Context, kdsDir string, manifestBytes []byte, endpoint, collateralProxy string, log *slog.Logger) (sdk.CoordinatorState, error) {
+	resp, err := getManifests(ctx, kdsDir, manifestBytes, endpoint, collateralProxy, log)
+	if err != nil {
+		return sdk.CoordinatorState{}, err
+	}
+
+	return sdk.CoordinatorState{
+		Manifests:                 resp.Manifests,
+		Policies:                  resp.Policies,
+		RootCA:                    resp.RootCA,
+		MeshCA:                    resp.MeshCA,
+		LatestTransitionHash:      resp.LatestTransition.TransitionHash,
+		LatestTransitionSignature: resp.LatestTransition.Signature,
+		PrunedTransitionHash:      resp.GetCheckpoint().GetTransitionHash(),
+		PrunedTransitions:         resp.GetCheckpoint().GetGeneration(),
+		CheckpointSignature:       resp.GetCheckpoint().GetSignature(),
+		PendingManifest:           resp.GetPendingUpdate().GetManifest(),
+		PendingActivationTime:     pendingActivationTime(resp.GetPendingUpdate()),
+		SigningKey:                resp.GetSigningKey(),
+	}, nil
+}
+
+// pendingActivationTime returns the activation time of the pending update, or the zero time if
+// there is none.
+func pendingActivationTime(pending *userapi.PendingUpdate) time.Time {
+	if pending == nil {
+		return time.Time{}
+	}
+	return time.Unix(pending.GetActivationTime(), 0).UTC()
+}
+
+// getManifests dials the coordinator, verifying it against the given manifest, and calls GetManifests.
+func getManifests(ctx context.Context, kdsDir string, manifestBytes []byte, endpoint, collateralProxy string, log *slog.Logger) (*userapi.GetManifestsResponse, error) {
 	var m manifest.Manifest
 	if err := json.Unmarshal(manifestBytes, &m); err != nil {
-		return sdk.CoordinatorState{}, fmt.Errorf("unmarshalling manifest: %w", err)
+		return nil, fmt.Errorf("unmarshalling manifest: %w", err)
 	}
 	if err := m.Validate(); err != nil {
-		return sdk.CoordinatorState{}, fmt.Errorf("validating manifest: %w", err)
+		return nil, fmt.Errorf("validating manifest: %w", err)
 	}
 
 	kdsCache := fsstore.New(afero.NewBasePathFs(afero.NewOsFs(), kdsDir), log.WithGroup("kds-cache"))
 	kdsGetter := certcache.NewCachedHTTPSGetter(kdsCache, certcache.NeverGCTicker, log.WithGroup("kds-getter"), collateralProxy)
 	validator, err := m.CoordinatorValidator(log, kdsGetter)
 	if err != nil {
-		return sdk.CoordinatorState{}, fmt.Errorf("getting validators: %w", err)
+		return nil, fmt.Errorf("getting validators: %w", err)
 	}
 	dialer := dialer.New(atls.NoIssuer, validator, atls.NoMetrics, nil, log)
 
@@ -207,7 +278,7 @@ func getCoordinatorState(ctx context.Context, kdsDir string, manifestBytes []byt
 
 	conn, err := dialer.Dial(ctx, endpoint)
 	if err != nil {
-		return sdk.CoordinatorState{}, fmt.Errorf("dialing coordinator: %w", err)
+		return nil, fmt.Errorf("dialing coordinator: %w", err)
 	}
 	defer conn.Close()
 
@@ -216,15 +287,7 @@ func getCoordinatorState(ctx context.Context, kdsDir string, manifestBytes []byt
 	client := userapi.NewUserAPIClient(conn)
 	resp, err := client.GetManifests(ctx, &userapi.GetManifestsRequest{})
 	if err != nil {
-		return sdk.CoordinatorState{}, fmt.Errorf("getting manifests: %w", err)
+		return nil, fmt.Errorf("getting manifests: %w", err)
 	}
-
-	return sdk.CoordinatorState{
-		Manifests:                 resp.Manifests,
-		Policies:                  resp.Policies,
-		RootCA:                    resp.RootCA,
-		MeshCA:                    resp.MeshCA,
-		LatestTransitionHash:      resp.LatestTransition.TransitionHash,
-		LatestTransitionSignature: resp.LatestTransition.Signature,
-	}, nil
+	return resp, nil
 }
@@ -122,6 +122,13 @@ func newRootCmd() (*cobra.Command, error) {
 		cmd.NewVerifyCmd(),
 		cmd.NewRecoverCmd(),
 		cmd.NewSignCmd(),
+		cmd.NewHistoryCmd(),
+		cmd.NewCancelCmd(),
+		cmd.NewRollbackCmd(),
+		cmd.NewAuditCmd(),
+		cmd.NewRevokeCmd(),
+		cmd.NewSubCACmd(),
+		cmd.NewMeshCertsCmd(),
 	)
 
 	return root, nil
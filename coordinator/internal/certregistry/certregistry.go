@@ -0,0 +1,409 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+// Package certregistry tracks the mesh certificates issued by the Coordinator and their
+// revocation status.
+//
+// The registry stores signed records in a history.Store that's shared by all Coordinators of a
+// deployment, see history.Records. Issued certificates are sharded by mesh CA, expiry date and
+// serial number, so that no record grows without bound, concurrent issuances rarely update the same
+// record, and expired shards are removed as a whole. The revoked
+// certificates of a mesh CA are kept in a separate record along with the CRL number, which is
+// read whenever a CRL is created.
+package certregistry
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgelesssys/contrast/internal/ca"
+	"github.com/edgelesssys/contrast/internal/history"
+	"k8s.io/utils/clock"
+)
+
+const (
+	// recordPrefix is the store key prefix of the registry records.
+	recordPrefix = "meshcerts"
+	// issuedInfix separates the mesh CA from the expiry date in the name of an issued shard.
+	issuedInfix = "-issued-"
+	// revokedSuffix is appended to the mesh CA in the name of a revoked record.
+	revokedSuffix = "-revoked"
+	// shardDateLayout is the layout of the expiry date in the name of an issued shard.
+	shardDateLayout = "20060102"
+	// shardsPerDay is the number of shards the certificates expiring on the same day are spread
+	// across by serial number.
+	shardsPerDay = 16
+)
+
+var (
+	// ErrUnknownCertificate is returned when revoking a certificate the registry doesn't know about.
+	ErrUnknownCertificate = errors.New("certificate is unknown to the mesh CA")
+	// ErrSubCA is returned when revoking a sub CA certificate. The proxies only check the CRL for
+	// leaf certificates, so a revoked sub CA wouldn't be rejected.
+	ErrSubCA = errors.New("sub CA certificates can't be revoked")
+)
+
+// Certificate is a mesh certificate issued by the Coordinator.
+type Certificate struct {
+	// SerialNumber is the serial number of the certificate.
+	SerialNumber *big.Int `json:"serial_number"`
+	// PolicyHash is the hex-encoded policy hash of the workload the certificate was issued to.
+	PolicyHash string `json:"policy_hash"`
+	// IssuedAt is the time the certificate was issued at.
+	IssuedAt time.Time `json:"issued_at"`
+	// NotAfter is the expiry date of the certificate.
+	NotAfter time.Time `json:"not_after"`
+	// RevokedAt is the time the certificate was revoked at. It's zero if the certificate wasn't
+	// revoked.
+	RevokedAt time.Time `json:"revoked_at,omitzero"`
+	// ReasonCode is the CRL reason code given for the revocation, see RFC 5280, section 5.3.1.
+	ReasonCode int `json:"reason_code,omitempty"`
+	// SubCA is set for sub CA certificates, which aren't issued to a workload.
+	SubCA bool `json:"sub_ca,omitempty"`
+}
+
+// Revoked reports whether the certificate was revoked.
+func (c *Certificate) Revoked() bool {
+	return !c.RevokedAt.IsZero()
+}
+
+// issuedShard holds the certificates of one mesh CA that expire on the same day.
+type issuedShard struct {
+	Certificates []Certificate `json:"certificates"`
+}
+
+// revokedRecord holds the revoked certificates of one mesh CA.
+type revokedRecord struct {
+	// CRLNumber is increased with every revocation. It's stored in the signed record, so that
+	// a rollback of the revocations is detected like a rollback of any other record.
+	CRLNumber uint64 `json:"crl_number"`
+	// Certificates holds the revoked certificates that weren't expired when the record was last
+	// updated.
+	Certificates []Certificate `json:"certificates"`
+}
+
+// Registry stores issued mesh certificates. A nil Registry doesn't record certificates and
+// creates empty CRLs.
+//
+// All methods that access the store take the transaction signing key of the current state, which
+// signs the records.
+type Registry struct {
+	records *history.Records
+	clock   clock.Clock
+	logger  *slog.Logger
+
+	// inventoryMu protects inventory.
+	inventoryMu sync.Mutex
+	// inventory holds the leaf certificates issued by this Coordinator, see RecordLeaf.
+	inventory []Leaf
+}
+
+// New creates a Registry that stores records in the given store.
+func New(store history.Store, logger *slog.Logger) *Registry {
+	return &Registry{
+		records: history.NewRecords(store, recordPrefix, logger),
+		clock:   clock.RealClock{},
+		logger:  logger,
+	}
+}
+
+// Watch keeps the cached records up to date until the context is done, see history.Records.
+func (r *Registry) Watch(ctx context.Context) error {
+	return r.records.Watch(ctx)
+}
+
+// Migrate copies the registry records from src to dst. The migration is skipped if dst already
+// has registry records or src has none, and the returned bool reports whether a migration took
+// place. An interrupted migration can safely be retried.
+func Migrate(src, dst history.Store) (bool, error) {
+	migrated, err := history.MigrateRecords(src, dst, recordPrefix)
+	if err != nil {
+		return false, fmt.Errorf("migrating mesh certificate registry: %w", err)
+	}
+	return migrated, nil
+}
+
+// RecordIssued adds a certificate issued by the given CA to its shard. Expired shards are removed
+// whenever a new shard is started.
+//
+// A certificate that isn't recorded can't be revoked, so the caller must not hand out the
+// certificate if an error is returned.
+func (r *Registry) RecordIssued(signingKey *ecdsa.PrivateKey, ca *ca.CA, cert Certificate) error {
+	if r == nil {
+		return nil
+	}
+	var newShard bool
+	err := r.records.Update(signingKey, shardName(ca, cert), func(content []byte) ([]byte, error) {
+		var shard issuedShard
+		newShard = content == nil
+		if content != nil {
+			if err := json.Unmarshal(content, &shard); err != nil {
+				return nil, fmt.Errorf("unmarshaling issued mesh certificates: %w", err)
+			}
+		}
+		shard.Certificates = append(shard.Certificates, cert)
+		return json.Marshal(shard)
+	})
+	if err != nil {
+		return fmt.Errorf("recording issued mesh certificate %x: %w", cert.SerialNumber, err)
+	}
+	if !newShard {
+		return nil
+	}
+	if err := r.prune(signingKey, ca); err != nil {
+		r.logger.Warn("Removing expired mesh certificates failed", "err", err)
+	}
+	return nil
+}
+
+// Revoke revokes the certificates with the given serial numbers, and all certificates issued to
+// the workload with the given policy hash, if it's not empty. It returns the newly revoked
+// certificates.
+//
+// If any of the serial numbers is unknown, no certificate is revoked and an error wrapping
+// ErrUnknownCertificate is returned. If any of them belongs to a sub CA, an error wrapping ErrSubCA
+// is returned. Certificates that are issued to the workload while the revocation is in progress
+// aren't revoked.
+func (r *Registry) Revoke(signingKey *ecdsa.PrivateKey, ca *ca.CA, serialNumbers []*big.Int, policyHash string, reasonCode int) ([]Certificate, error) {
+	issued, err := r.issued(signingKey, ca)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []Certificate
+	for _, serialNumber := range serialNumbers {
+		idx := slices.IndexFunc(issued, func(c Certificate) bool {
+			return c.SerialNumber.Cmp(serialNumber) == 0
+		})
+		if idx < 0 {
+			return nil, fmt.Errorf("%w: serial number %x", ErrUnknownCertificate, serialNumber)
+		}
+		if issued[idx].SubCA {
+			return nil, fmt.Errorf("%w: serial number %x", ErrSubCA, serialNumber)
+		}
+		candidates = append(candidates, issued[idx])
+	}
+	for _, cert := range issued {
+		if policyHash != "" && cert.PolicyHash == policyHash {
+			candidates = append(candidates, cert)
+		}
+	}
+
+	var revoked []Certificate
+	err = r.records.Update(signingKey, caID(ca)+revokedSuffix, func(content []byte) ([]byte, error) {
+		rec, err := r.unmarshalRevoked(content)
+		if err != nil {
+			return nil, err
+		}
+		revoked = nil
+		now := r.clock.Now().UTC()
+		for _, cert := range candidates {
+			if revokedIndex(rec.Certificates, cert.SerialNumber) >= 0 {
+				continue
+			}
+			cert.RevokedAt = now
+			cert.ReasonCode = reasonCode
+			rec.Certificates = append(rec.Certificates, cert)
+			revoked = append(revoked, cert)
+		}
+		if len(revoked) > 0 {
+			rec.CRLNumber++
+		}
+		return json.Marshal(rec)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("updating revoked mesh certificates: %w", err)
+	}
+	r.markRevoked(ca, revoked)
+	return revoked, nil
+}
+
+// Certificates returns the unexpired certificates issued by the given CA and the current CRL
+// number.
+func (r *Registry) Certificates(signingKey *ecdsa.PrivateKey, ca *ca.CA) ([]Certificate, uint64, error) {
+	if r == nil {
+		return nil, 0, nil
+	}
+	issued, err := r.issued(signingKey, ca)
+	if err != nil {
+		return nil, 0, err
+	}
+	rec, err := r.revoked(signingKey, ca)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range issued {
+		if idx := revokedIndex(rec.Certificates, issued[i].SerialNumber); idx >= 0 {
+			issued[i] = rec.Certificates[idx]
+		}
+	}
+	return issued, rec.CRLNumber, nil
+}
+
+// IsRevoked reports whether the certificate with the given serial number, issued by the given CA,
+// was revoked.
+func (r *Registry) IsRevoked(signingKey *ecdsa.PrivateKey, ca *ca.CA, serialNumber *big.Int) (bool, error) {
+	if r == nil {
+		return false, nil
+	}
+	rec, err := r.revoked(signingKey, ca)
+	if err != nil {
+		return false, err
+	}
+	return revokedIndex(rec.Certificates, serialNumber) >= 0, nil
+}
+
+// CRL creates a PEM-encoded CRL for the given CA, listing all revoked, unexpired certificates.
+// The CRL is valid until nextUpdate.
+func (r *Registry) CRL(signingKey *ecdsa.PrivateKey, ca *ca.CA, nextUpdate time.Time) ([]byte, error) {
+	rec := &revokedRecord{}
+	if r != nil {
+		var err error
+		rec, err = r.revoked(signingKey, ca)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var entries []x509.RevocationListEntry
+	for _, cert := range rec.Certificates {
+		entries = append(entries, x509.RevocationListEntry{
+			SerialNumber:   cert.SerialNumber,
+			RevocationTime: cert.RevokedAt,
+			ReasonCode:     cert.ReasonCode,
+		})
+	}
+	return ca.CreateCRL(entries, new(big.Int).SetUint64(rec.CRLNumber), nextUpdate)
+}
+
+// issued returns the unexpired certificates issued by the given CA.
+func (r *Registry) issued(signingKey *ecdsa.PrivateKey, ca *ca.CA) ([]Certificate, error) {
+	names, err := r.records.Names(signingKey)
+	if err != nil {
+		return nil, fmt.Errorf("listing mesh certificate records: %w", err)
+	}
+	prefix := caID(ca) + issuedInfix
+	now := r.clock.Now()
+	var certs []Certificate
+	for _, name := range names {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		content, err := r.records.Get(signingKey, name)
+		if errors.Is(err, os.ErrNotExist) {
+			// The shard expired and was removed concurrently.
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("getting issued mesh certificates: %w", err)
+		}
+		var shard issuedShard
+		if err := json.Unmarshal(content, &shard); err != nil {
+			return nil, fmt.Errorf("unmarshaling issued mesh certificates: %w", err)
+		}
+		for _, cert := range shard.Certificates {
+			if !cert.NotAfter.Before(now) {
+				certs = append(certs, cert)
+			}
+		}
+	}
+	return certs, nil
+}
+
+// revoked returns the revoked, unexpired certificates of the given CA.
+func (r *Registry) revoked(signingKey *ecdsa.PrivateKey, ca *ca.CA) (*revokedRecord, error) {
+	content, err := r.records.Get(signingKey, caID(ca)+revokedSuffix)
+	if errors.Is(err, os.ErrNotExist) {
+		content = nil
+	} else if err != nil {
+		return nil, fmt.Errorf("getting revoked mesh certificates: %w", err)
+	}
+	return r.unmarshalRevoked(content)
+}
+
+// unmarshalRevoked unmarshals a revoked record and removes the expired certificates from it.
+func (r *Registry) unmarshalRevoked(content []byte) (*revokedRecord, error) {
+	rec := &revokedRecord{}
+	if content != nil {
+		if err := json.Unmarshal(content, rec); err != nil {
+			return nil, fmt.Errorf("unmarshaling revoked mesh certificates: %w", err)
+		}
+	}
+	now := r.clock.Now()
+	rec.Certificates = slices.DeleteFunc(rec.Certificates, func(c Certificate) bool {
+		return c.NotAfter.Before(now)
+	})
+	return rec, nil
+}
+
+// prune removes the issued shards in which all certificates expired, and the revoked records of
+// previous mesh CAs that have no issued shards left. The revoked record of the current CA is kept,
+// so that its CRL number never goes backwards.
+func (r *Registry) prune(signingKey *ecdsa.PrivateKey, current *ca.CA) error {
+	names, err := r.records.Names(signingKey)
+	if err != nil {
+		return err
+	}
+	now := r.clock.Now().UTC()
+	activeCAs := map[string]bool{caID(current): true}
+	var expired []string
+	for _, name := range names {
+		id, shard, ok := strings.Cut(name, issuedInfix)
+		if !ok {
+			continue
+		}
+		date, _, _ := strings.Cut(shard, "-")
+		day, err := time.Parse(shardDateLayout, date)
+		if err != nil {
+			return fmt.Errorf("parsing expiry date of %q: %w", name, err)
+		}
+		if day.AddDate(0, 0, 1).After(now) {
+			activeCAs[id] = true
+			continue
+		}
+		expired = append(expired, name)
+	}
+	for _, name := range names {
+		if id, ok := strings.CutSuffix(name, revokedSuffix); ok && !activeCAs[id] {
+			expired = append(expired, name)
+		}
+	}
+	for _, name := range expired {
+		if err := r.records.Delete(signingKey, name); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("deleting %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// shardName returns the name of the issued shard the given certificate of the given CA belongs to.
+func shardName(ca *ca.CA, cert Certificate) string {
+	bucket := new(big.Int).Mod(cert.SerialNumber, big.NewInt(shardsPerDay))
+	return fmt.Sprintf("%s%s%s-%x", caID(ca), issuedInfix, cert.NotAfter.UTC().Format(shardDateLayout), bucket)
+}
+
+// caID identifies the given CA in record names.
+func caID(ca *ca.CA) string {
+	pubKey, err := x509.MarshalPKIXPublicKey(ca.GetIntermCAPrivKey().Public())
+	if err != nil {
+		// The mesh CA key was already used to create the mesh CA certificate, so it can be marshaled.
+		panic(fmt.Sprintf("marshaling mesh CA public key: %v", err))
+	}
+	digest := history.Digest(pubKey)
+	return hex.EncodeToString(digest[:])
+}
+
+func revokedIndex(certs []Certificate, serialNumber *big.Int) int {
+	return slices.IndexFunc(certs, func(c Certificate) bool {
+		return c.SerialNumber.Cmp(serialNumber) == 0
+	})
+}
@@ -0,0 +1,390 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package certregistry
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/pem"
+	"log/slog"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edgelesssys/contrast/internal/ca"
+	"github.com/edgelesssys/contrast/internal/history"
+	"github.com/edgelesssys/contrast/internal/history/aferostore"
+	"github.com/edgelesssys/contrast/internal/testkeys"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+// CRL reason codes, see RFC 5280, section 5.3.1.
+const (
+	keyCompromise = 1
+	superseded    = 4
+)
+
+func TestRevoke(t *testing.T) {
+	testCases := map[string]struct {
+		serialNumbers []*big.Int
+		policyHash    string
+		wantRevoked   []int64
+		wantErr       error
+	}{
+		"by serial number": {
+			serialNumbers: []*big.Int{big.NewInt(2)},
+			wantRevoked:   []int64{2},
+		},
+		"by policy hash": {
+			policyHash:  "aa",
+			wantRevoked: []int64{1, 2},
+		},
+		"by serial number and policy hash": {
+			serialNumbers: []*big.Int{big.NewInt(3)},
+			policyHash:    "aa",
+			wantRevoked:   []int64{1, 2, 3},
+		},
+		"unknown serial number": {
+			serialNumbers: []*big.Int{big.NewInt(1), big.NewInt(42)},
+			wantErr:       ErrUnknownCertificate,
+		},
+		"expired certificate": {
+			serialNumbers: []*big.Int{big.NewInt(4)},
+			wantErr:       ErrUnknownCertificate,
+		},
+		"unknown policy hash": {
+			policyHash: "cc",
+		},
+		"sub CA": {
+			serialNumbers: []*big.Int{big.NewInt(2), big.NewInt(5)},
+			wantErr:       ErrSubCA,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+
+			registry, clock := newTestRegistry(t)
+			key := newSigningKey(t)
+			meshCA := newTestCA(t)
+			now := clock.Now()
+			require.NoError(registry.RecordIssued(key, meshCA, Certificate{SerialNumber: big.NewInt(1), PolicyHash: "aa", NotAfter: now.Add(time.Hour)}))
+			require.NoError(registry.RecordIssued(key, meshCA, Certificate{SerialNumber: big.NewInt(2), PolicyHash: "aa", NotAfter: now.Add(time.Hour)}))
+			require.NoError(registry.RecordIssued(key, meshCA, Certificate{SerialNumber: big.NewInt(3), PolicyHash: "bb", NotAfter: now.Add(time.Hour)}))
+			require.NoError(registry.RecordIssued(key, meshCA, Certificate{SerialNumber: big.NewInt(4), PolicyHash: "bb", NotAfter: now.Add(-time.Hour)}))
+			require.NoError(registry.RecordIssued(key, meshCA, Certificate{SerialNumber: big.NewInt(5), NotAfter: now.Add(time.Hour), SubCA: true}))
+
+			revoked, err := registry.Revoke(key, meshCA, tc.serialNumbers, tc.policyHash, keyCompromise)
+			if tc.wantErr != nil {
+				require.ErrorIs(err, tc.wantErr)
+				certs, crlNumber, err := registry.Certificates(key, meshCA)
+				require.NoError(err)
+				assert.Zero(crlNumber)
+				for _, cert := range certs {
+					assert.False(cert.Revoked())
+				}
+				return
+			}
+			require.NoError(err)
+
+			var revokedSerials []int64
+			for _, cert := range revoked {
+				revokedSerials = append(revokedSerials, cert.SerialNumber.Int64())
+				assert.Equal(keyCompromise, cert.ReasonCode)
+			}
+			assert.ElementsMatch(tc.wantRevoked, revokedSerials)
+
+			certs, crlNumber, err := registry.Certificates(key, meshCA)
+			require.NoError(err)
+			assert.Len(certs, 4, "expired certificate must be removed")
+			if len(tc.wantRevoked) > 0 {
+				assert.Equal(uint64(1), crlNumber)
+			} else {
+				assert.Zero(crlNumber)
+			}
+
+			// Revoking again doesn't revoke anything new.
+			revoked, err = registry.Revoke(key, meshCA, tc.serialNumbers, tc.policyHash, keyCompromise)
+			require.NoError(err)
+			assert.Empty(revoked)
+		})
+	}
+}
+
+func TestCRL(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	registry, clock := newTestRegistry(t)
+	key := newSigningKey(t)
+	meshCA := newTestCA(t)
+	notAfter := clock.Now().Add(time.Hour)
+	require.NoError(registry.RecordIssued(key, meshCA, Certificate{SerialNumber: big.NewInt(1), PolicyHash: "aa", NotAfter: notAfter}))
+	require.NoError(registry.RecordIssued(key, meshCA, Certificate{SerialNumber: big.NewInt(2), PolicyHash: "bb", NotAfter: notAfter}))
+	_, err := registry.Revoke(key, meshCA, []*big.Int{big.NewInt(2)}, "", superseded)
+	require.NoError(err)
+
+	crlPEM, err := registry.CRL(key, meshCA, notAfter)
+	require.NoError(err)
+	block, _ := pem.Decode(crlPEM)
+	require.NotNil(block)
+	crl, err := x509.ParseRevocationList(block.Bytes)
+	require.NoError(err)
+
+	assert.Equal(big.NewInt(1), crl.Number)
+	require.Len(crl.RevokedCertificateEntries, 1)
+	assert.Equal(big.NewInt(2), crl.RevokedCertificateEntries[0].SerialNumber)
+	assert.Equal(superseded, crl.RevokedCertificateEntries[0].ReasonCode)
+
+	// Revoked certificates are removed from the CRL once they expire.
+	clock.Step(2 * time.Hour)
+	crlPEM, err = registry.CRL(key, meshCA, clock.Now().Add(time.Hour))
+	require.NoError(err)
+	block, _ = pem.Decode(crlPEM)
+	require.NotNil(block)
+	crl, err = x509.ParseRevocationList(block.Bytes)
+	require.NoError(err)
+	assert.Empty(crl.RevokedCertificateEntries)
+}
+
+func TestRegistry_Tampering(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	store := aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()})
+	key := newSigningKey(t)
+	registry := New(store, slog.New(slog.DiscardHandler))
+	meshCA := newTestCA(t)
+	require.NoError(registry.RecordIssued(key, meshCA, Certificate{SerialNumber: big.NewInt(1), PolicyHash: "aa", NotAfter: time.Now().Add(time.Hour)}))
+	_, err := registry.Revoke(key, meshCA, nil, "cc", 0)
+	require.NoError(err)
+	revokedKey := recordPrefix + "/" + caID(meshCA) + revokedSuffix
+	unrevoked, err := store.Get(revokedKey)
+	require.NoError(err)
+
+	// Replaying the record from before a revocation is detected, also after a restart.
+	_, err = registry.Revoke(key, meshCA, []*big.Int{big.NewInt(1)}, "", 0)
+	require.NoError(err)
+	revokedRecord, err := store.Get(revokedKey)
+	require.NoError(err)
+	require.NoError(store.Set(revokedKey, unrevoked))
+	revoked, err := registry.IsRevoked(key, meshCA, big.NewInt(1))
+	require.NoError(err)
+	assert.True(revoked, "the verified record must be served from the cache")
+	_, err = New(store, slog.New(slog.DiscardHandler)).IsRevoked(key, meshCA, big.NewInt(1))
+	require.ErrorIs(err, history.ErrRecordRollback)
+
+	// Records of other CAs aren't accepted.
+	otherCA, err := ca.New(newKey(t, 0), newKey(t, 2))
+	require.NoError(err)
+	require.NoError(store.Set(recordPrefix+"/"+caID(otherCA)+revokedSuffix, revokedRecord))
+	revoked, err = New(store, slog.New(slog.DiscardHandler)).IsRevoked(key, otherCA, big.NewInt(1))
+	require.NoError(err)
+	assert.False(revoked)
+
+	// Deleting the record is detected.
+	require.NoError(store.Delete(revokedKey))
+	_, err = New(store, slog.New(slog.DiscardHandler)).CRL(key, meshCA, time.Now().Add(time.Hour))
+	require.ErrorIs(err, history.ErrRecordRollback)
+}
+
+func TestRegistry_Prune(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	registry, clock := newTestRegistry(t)
+	key := newSigningKey(t)
+	oldCA := newTestCA(t)
+	meshCA, err := ca.New(newKey(t, 0), newKey(t, 2))
+	require.NoError(err)
+	now := clock.Now()
+	require.NoError(registry.RecordIssued(key, oldCA, Certificate{SerialNumber: big.NewInt(1), NotAfter: now.Add(time.Hour)}))
+	require.NoError(registry.RecordIssued(key, oldCA, Certificate{SerialNumber: big.NewInt(1 + shardsPerDay), NotAfter: now.Add(2 * time.Hour)}))
+	_, err = registry.Revoke(key, oldCA, []*big.Int{big.NewInt(1)}, "", keyCompromise)
+	require.NoError(err)
+	require.NoError(registry.RecordIssued(key, meshCA, Certificate{SerialNumber: big.NewInt(3), NotAfter: now.Add(72 * time.Hour)}))
+	_, err = registry.Revoke(key, meshCA, []*big.Int{big.NewInt(3)}, "", keyCompromise)
+	require.NoError(err)
+	names, err := registry.records.Names(key)
+	require.NoError(err)
+	assert.Len(names, 4, "certificates expiring on the same day with the same serial number bucket share a shard")
+	require.NoError(registry.RecordIssued(key, meshCA, Certificate{SerialNumber: big.NewInt(6), NotAfter: now.Add(72 * time.Hour)}))
+	names, err = registry.records.Names(key)
+	require.NoError(err)
+	assert.Len(names, 5, "certificates in other serial number buckets use another shard")
+
+	// Shards are removed once all their certificates expired, and the revoked records of
+	// previous CAs once they have no shards left.
+	clock.Step(48 * time.Hour)
+	require.NoError(registry.RecordIssued(key, meshCA, Certificate{SerialNumber: big.NewInt(4), NotAfter: clock.Now().Add(time.Hour)}))
+	names, err = registry.records.Names(key)
+	require.NoError(err)
+	for _, name := range names {
+		assert.True(strings.HasPrefix(name, caID(meshCA)), name)
+	}
+	certs, crlNumber, err := registry.Certificates(key, meshCA)
+	require.NoError(err)
+	assert.Len(certs, 3)
+	assert.Equal(uint64(1), crlNumber)
+
+	// The revoked record of the current CA is kept, so that the CRL number doesn't go backwards.
+	clock.Step(72 * time.Hour)
+	require.NoError(registry.RecordIssued(key, meshCA, Certificate{SerialNumber: big.NewInt(5), NotAfter: clock.Now().Add(time.Hour)}))
+	_, crlNumber, err = registry.Certificates(key, meshCA)
+	require.NoError(err)
+	assert.Equal(uint64(1), crlNumber)
+}
+
+func TestMigrate(t *testing.T) {
+	require := require.New(t)
+
+	src := aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()})
+	dst := aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()})
+	key := newSigningKey(t)
+	meshCA := newTestCA(t)
+
+	migrated, err := Migrate(src, dst)
+	require.NoError(err)
+	require.False(migrated, "an empty registry must not be migrated")
+
+	registry := New(src, slog.New(slog.DiscardHandler))
+	require.NoError(registry.RecordIssued(key, meshCA, Certificate{SerialNumber: big.NewInt(1), PolicyHash: "aa", NotAfter: time.Now().Add(time.Hour)}))
+	_, err = registry.Revoke(key, meshCA, []*big.Int{big.NewInt(1)}, "", keyCompromise)
+	require.NoError(err)
+
+	migrated, err = Migrate(src, dst)
+	require.NoError(err)
+	require.True(migrated)
+
+	// Revocations survive the migration.
+	revoked, err := New(dst, slog.New(slog.DiscardHandler)).IsRevoked(key, meshCA, big.NewInt(1))
+	require.NoError(err)
+	require.True(revoked)
+
+	// The destination's registry is never overwritten.
+	migrated, err = Migrate(src, dst)
+	require.NoError(err)
+	require.False(migrated)
+}
+
+func TestRecordIssued_StoreFailure(t *testing.T) {
+	store := &failingStore{Store: aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()})}
+	registry := New(store, slog.New(slog.DiscardHandler))
+	key := newSigningKey(t)
+
+	err := registry.RecordIssued(key, newTestCA(t), Certificate{SerialNumber: big.NewInt(1), NotAfter: time.Now().Add(time.Hour)})
+	require.ErrorIs(t, err, assert.AnError)
+	err = registry.RecordLeaf(key, newTestCA(t), Leaf{Certificate: Certificate{SerialNumber: big.NewInt(1), NotAfter: time.Now().Add(time.Hour)}})
+	require.ErrorIs(t, err, assert.AnError)
+	assert.Empty(t, registry.Leaves(nil), "unrecorded certificates must not be added to the inventory")
+}
+
+func TestLeaves(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	registry, clock := newTestRegistry(t)
+	key := newSigningKey(t)
+	oldCA := newTestCA(t)
+	meshCA, err := ca.New(newKey(t, 0), newKey(t, 2))
+	require.NoError(err)
+	now := clock.Now()
+	require.NoError(registry.RecordLeaf(key, oldCA, Leaf{
+		Certificate:        Certificate{SerialNumber: big.NewInt(1), PolicyHash: "aa", IssuedAt: now, NotAfter: now.Add(time.Hour)},
+		SANs:               []string{"old"},
+		PeerAddress:        "192.0.2.1:1234",
+		ManifestGeneration: 1,
+	}))
+	require.NoError(registry.RecordLeaf(key, meshCA, Leaf{
+		Certificate:        Certificate{SerialNumber: big.NewInt(2), PolicyHash: "aa", IssuedAt: now, NotAfter: now.Add(3 * time.Hour)},
+		SANs:               []string{"new"},
+		PeerAddress:        "192.0.2.2:1234",
+		ManifestGeneration: 2,
+	}))
+
+	leaves := registry.Leaves(meshCA)
+	require.Len(leaves, 2)
+	assert.Equal(big.NewInt(1), leaves[0].SerialNumber)
+	assert.Equal([]string{"old"}, leaves[0].SANs)
+	assert.Equal("192.0.2.1:1234", leaves[0].PeerAddress)
+	assert.Equal(1, leaves[0].ManifestGeneration)
+	assert.False(leaves[0].CurrentMeshCA)
+	assert.True(leaves[1].CurrentMeshCA)
+
+	// Leaves are recorded in the registry, too.
+	certs, _, err := registry.Certificates(key, meshCA)
+	require.NoError(err)
+	require.Len(certs, 1)
+	assert.Equal(big.NewInt(2), certs[0].SerialNumber)
+
+	// Revocations are reflected in the inventory.
+	_, err = registry.Revoke(key, meshCA, []*big.Int{big.NewInt(2)}, "", keyCompromise)
+	require.NoError(err)
+	leaves = registry.Leaves(meshCA)
+	require.Len(leaves, 2)
+	assert.False(leaves[0].Revoked())
+	assert.True(leaves[1].Revoked())
+	assert.Equal(keyCompromise, leaves[1].ReasonCode)
+
+	// Expired certificates are removed from the inventory.
+	clock.Step(2 * time.Hour)
+	leaves = registry.Leaves(nil)
+	require.Len(leaves, 1)
+	assert.Equal(big.NewInt(2), leaves[0].SerialNumber)
+	assert.False(leaves[0].CurrentMeshCA)
+}
+
+func TestRecordIssued_NilRegistry(t *testing.T) {
+	var registry *Registry
+	key := newSigningKey(t)
+	require.NoError(t, registry.RecordIssued(key, newTestCA(t), Certificate{SerialNumber: big.NewInt(1)}))
+	require.NoError(t, registry.RecordLeaf(key, newTestCA(t), Leaf{Certificate: Certificate{SerialNumber: big.NewInt(1)}}))
+	assert.Empty(t, registry.Leaves(newTestCA(t)))
+	crl, err := registry.CRL(key, newTestCA(t), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.NotEmpty(t, crl)
+}
+
+func newTestRegistry(t *testing.T) (*Registry, *testingclock.FakeClock) {
+	t.Helper()
+	var store history.Store = aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()})
+	registry := New(store, slog.New(slog.DiscardHandler))
+	clock := testingclock.NewFakeClock(time.Now())
+	registry.clock = clock
+	return registry, clock
+}
+
+func newTestCA(t *testing.T) *ca.CA {
+	t.Helper()
+	meshCA, err := ca.New(newKey(t, 0), newKey(t, 1))
+	require.NoError(t, err)
+	return meshCA
+}
+
+func newSigningKey(t *testing.T) *ecdsa.PrivateKey {
+	return testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP256Keys[0])
+}
+
+func newKey(t *testing.T, i int) *ecdsa.PrivateKey {
+	return testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[i])
+}
+
+// failingStore is a history.Store whose writes fail.
+type failingStore struct {
+	history.Store
+}
+
+func (s *failingStore) Set(string, []byte) error {
+	return assert.AnError
+}
+
+func (s *failingStore) CompareAndSwap(string, []byte, []byte) error {
+	return assert.AnError
+}
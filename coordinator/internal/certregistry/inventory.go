@@ -0,0 +1,101 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package certregistry
+
+import (
+	"crypto/ecdsa"
+	"slices"
+
+	"github.com/edgelesssys/contrast/internal/ca"
+)
+
+// Leaf is a mesh certificate in the inventory of this Coordinator.
+type Leaf struct {
+	Certificate
+	// SANs are the subject alternative names of the certificate.
+	SANs []string
+	// PeerAddress is the address of the workload that requested the certificate.
+	PeerAddress string
+	// ManifestGeneration is the generation of the manifest the certificate was issued under.
+	ManifestGeneration int
+	// CurrentMeshCA is set by Leaves for certificates issued by the given mesh CA.
+	CurrentMeshCA bool
+
+	// meshCAID identifies the issuing mesh CA, see caID.
+	meshCAID string
+}
+
+// RecordLeaf records a mesh certificate issued to a workload like RecordIssued, and adds it to the
+// inventory of this Coordinator.
+//
+// The inventory is kept in memory, so it only contains certificates that were issued by this
+// Coordinator since it started.
+func (r *Registry) RecordLeaf(signingKey *ecdsa.PrivateKey, ca *ca.CA, leaf Leaf) error {
+	if r == nil {
+		return nil
+	}
+	if err := r.RecordIssued(signingKey, ca, leaf.Certificate); err != nil {
+		return err
+	}
+
+	leaf.SANs = slices.Clone(leaf.SANs)
+	leaf.CurrentMeshCA = false
+	leaf.meshCAID = caID(ca)
+	r.inventoryMu.Lock()
+	defer r.inventoryMu.Unlock()
+	r.pruneInventory()
+	r.inventory = append(r.inventory, leaf)
+	return nil
+}
+
+// Leaves returns the unexpired certificates of the inventory, oldest first. CurrentMeshCA is set
+// for the certificates issued by meshCA, which may be nil.
+func (r *Registry) Leaves(meshCA *ca.CA) []Leaf {
+	if r == nil {
+		return nil
+	}
+	var currentID string
+	if meshCA != nil {
+		currentID = caID(meshCA)
+	}
+
+	r.inventoryMu.Lock()
+	defer r.inventoryMu.Unlock()
+	r.pruneInventory()
+	leaves := make([]Leaf, 0, len(r.inventory))
+	for _, leaf := range r.inventory {
+		leaf.SANs = slices.Clone(leaf.SANs)
+		leaf.CurrentMeshCA = leaf.meshCAID == currentID
+		leaves = append(leaves, leaf)
+	}
+	return leaves
+}
+
+// markRevoked updates the revocation status of the given certificates of the given CA in the
+// inventory.
+func (r *Registry) markRevoked(ca *ca.CA, revoked []Certificate) {
+	id := caID(ca)
+	r.inventoryMu.Lock()
+	defer r.inventoryMu.Unlock()
+	for i := range r.inventory {
+		if r.inventory[i].meshCAID != id {
+			continue
+		}
+		for _, cert := range revoked {
+			if r.inventory[i].SerialNumber.Cmp(cert.SerialNumber) == 0 {
+				r.inventory[i].RevokedAt = cert.RevokedAt
+				r.inventory[i].ReasonCode = cert.ReasonCode
+			}
+		}
+	}
+}
+
+// pruneInventory removes expired certificates from the inventory. The caller must hold
+// r.inventoryMu.
+func (r *Registry) pruneInventory() {
+	now := r.clock.Now()
+	r.inventory = slices.DeleteFunc(r.inventory, func(leaf Leaf) bool {
+		return leaf.NotAfter.Before(now)
+	})
+}
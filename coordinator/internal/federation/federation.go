@@ -0,0 +1,248 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+// Package federation keeps the CA certificates of federated Contrast deployments up to date.
+//
+// For every deployment listed in the FederatedDeployments of the current manifest, the Federator
+// periodically attests the deployment's Coordinator via aTLS, checks its root CA against the
+// fingerprint in the manifest and fetches its mesh CA certificate and CRL. The certificates are added to the trust bundle handed out to workloads by the mesh API.
+package federation
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/edgelesssys/contrast/coordinator/internal/stateguard"
+	"github.com/edgelesssys/contrast/internal/atls"
+	"github.com/edgelesssys/contrast/internal/atls/validators"
+	"github.com/edgelesssys/contrast/internal/attestation/certcache"
+	"github.com/edgelesssys/contrast/internal/grpc/dialer"
+	"github.com/edgelesssys/contrast/internal/manifest"
+	"github.com/edgelesssys/contrast/internal/userapi"
+	"k8s.io/utils/clock"
+)
+
+// refreshInterval is the interval in which the bundles of federated deployments are refreshed.
+const refreshInterval = time.Minute
+
+// Bundle holds the CA certificates of a federated deployment.
+type Bundle struct {
+	// MeshCACert is the PEM-encoded mesh CA certificate of the deployment.
+	MeshCACert []byte
+	// RootCACert is the PEM-encoded root CA certificate of the deployment.
+	RootCACert []byte
+	// CRL is the PEM-encoded CRL of the deployment's mesh CA.
+	CRL []byte
+	// SPIFFETrustDomain is the SPIFFE trust domain of the deployment's current manifest, if any.
+	SPIFFETrustDomain string
+	// UpdatedAt is the time the bundle was last fetched at.
+	UpdatedAt time.Time
+}
+
+// Federator fetches the bundles of federated deployments. A nil Federator has no bundles.
+type Federator struct {
+	guard       guard
+	httpsGetter *certcache.CachedHTTPSGetter
+	logger      *slog.Logger
+
+	clock  clock.WithTicker
+	dialer userAPIDialer
+
+	mu      sync.RWMutex
+	bundles map[string]federatedBundle
+}
+
+// federatedBundle is a bundle along with the configuration of the deployment it was fetched for.
+type federatedBundle struct {
+	deployment manifest.FederatedDeployment
+	bundle     Bundle
+}
+
+// guard is the public API of stateguard.Guard used by Federator.
+type guard interface {
+	// GetState returns the current state. If the error is nil, the state must be set.
+	GetState(context.Context) (*stateguard.State, error)
+}
+
+// New creates a new Federator.
+func New(guard guard, httpsGetter *certcache.CachedHTTPSGetter, logger *slog.Logger) *Federator {
+	return &Federator{
+		guard:       guard,
+		httpsGetter: httpsGetter,
+		logger:      logger,
+
+		clock:   clock.RealClock{},
+		dialer:  &defaultUserAPIDialer{},
+		bundles: make(map[string]federatedBundle),
+	}
+}
+
+// Run periodically refreshes the bundles of all federated deployments.
+//
+// The function returns only when the context expires, with the error returned from the context.
+func (f *Federator) Run(ctx context.Context) error {
+	t := f.clock.NewTicker(refreshInterval)
+	defer t.Stop()
+	for {
+		if err := f.RefreshOnce(ctx); err != nil {
+			f.logger.Warn("Could not refresh all federated deployments", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C():
+		}
+	}
+}
+
+// RefreshOnce fetches the bundles of all deployments that are federated by the current manifest.
+//
+// If a deployment can't be reached, its last bundle is kept as long as its configuration in the
+// manifest doesn't change. Bundles of deployments that were removed from the manifest are dropped.
+func (f *Federator) RefreshOnce(ctx context.Context) error {
+	state, err := f.guard.GetState(ctx)
+	if errors.Is(err, stateguard.ErrNoState) || errors.Is(err, stateguard.ErrStaleState) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("getting state: %w", err)
+	}
+	deployments := state.Manifest().FederatedDeployments
+
+	f.mu.RLock()
+	oldBundles := maps.Clone(f.bundles)
+	f.mu.RUnlock()
+
+	newBundles := make(map[string]federatedBundle, len(deployments))
+	var errs []error
+	for name, deployment := range deployments {
+		bundle, err := f.fetch(ctx, deployment)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("federated deployment %q: %w", name, err))
+			if old, ok := oldBundles[name]; ok && reflect.DeepEqual(old.deployment, deployment) {
+				newBundles[name] = old
+			}
+			continue
+		}
+		newBundles[name] = federatedBundle{deployment: deployment, bundle: bundle}
+	}
+
+	f.mu.Lock()
+	f.bundles = newBundles
+	f.mu.Unlock()
+	return errors.Join(errs...)
+}
+
+// Bundles returns the latest bundles of the federated deployments, keyed by deployment name.
+func (f *Federator) Bundles() map[string]Bundle {
+	if f == nil {
+		return nil
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	bundles := make(map[string]Bundle, len(f.bundles))
+	for name, b := range f.bundles {
+		bundles[name] = b.bundle
+	}
+	return bundles
+}
+
+// fetch attests the Coordinator of the deployment and returns its bundle.
+func (f *Federator) fetch(ctx context.Context, deployment manifest.FederatedDeployment) (Bundle, error) {
+	validator, err := deployment.CoordinatorValidator(f.logger, f.httpsGetter)
+	if err != nil {
+		return Bundle{}, fmt.Errorf("generating validators: %w", err)
+	}
+	client, closeConn, err := f.dialer.Dial(ctx, validator, f.logger, deployment.CoordinatorEndpoint)
+	if err != nil {
+		return Bundle{}, fmt.Errorf("dialing coordinator: %w", err)
+	}
+	defer func() {
+		if err := closeConn(); err != nil {
+			f.logger.Warn("Could not close connection", "err", err)
+		}
+	}()
+
+	resp, err := client.GetManifests(ctx, &userapi.GetManifestsRequest{})
+	if err != nil {
+		return Bundle{}, fmt.Errorf("calling GetManifests: %w", err)
+	}
+	if err := checkPEM(resp.GetMeshCA(), "CERTIFICATE"); err != nil {
+		return Bundle{}, fmt.Errorf("mesh CA certificate: %w", err)
+	}
+	if err := checkPEM(resp.GetRootCA(), "CERTIFICATE"); err != nil {
+		return Bundle{}, fmt.Errorf("root CA certificate: %w", err)
+	}
+	rootCABlock, _ := pem.Decode(resp.GetRootCA())
+	rootCA, err := x509.ParseCertificate(rootCABlock.Bytes)
+	if err != nil {
+		return Bundle{}, fmt.Errorf("parsing root CA certificate: %w", err)
+	}
+	if err := deployment.CheckRootCA(rootCA); err != nil {
+		return Bundle{}, err
+	}
+	if err := checkPEM(resp.GetCRL(), "X509 CRL"); err != nil {
+		return Bundle{}, fmt.Errorf("CRL: %w", err)
+	}
+	if len(resp.GetManifests()) == 0 {
+		return Bundle{}, errors.New("coordinator returned no manifest")
+	}
+	var latest manifest.Manifest
+	if err := json.Unmarshal(resp.GetManifests()[len(resp.GetManifests())-1], &latest); err != nil {
+		return Bundle{}, fmt.Errorf("unmarshaling latest manifest: %w", err)
+	}
+
+	return Bundle{
+		MeshCACert:        resp.GetMeshCA(),
+		RootCACert:        resp.GetRootCA(),
+		CRL:               resp.GetCRL(),
+		SPIFFETrustDomain: latest.SPIFFETrustDomain,
+		UpdatedAt:         f.clock.Now().UTC(),
+	}, nil
+}
+
+// checkPEM checks that data consists of a single PEM block of the given type with valid content.
+func checkPEM(data []byte, blockType string) error {
+	block, rest := pem.Decode(data)
+	if block == nil || block.Type != blockType {
+		return fmt.Errorf("no PEM block of type %s", blockType)
+	}
+	if len(rest) > 0 {
+		return errors.New("unexpected data after PEM block")
+	}
+	var err error
+	switch blockType {
+	case "CERTIFICATE":
+		_, err = x509.ParseCertificate(block.Bytes)
+	case "X509 CRL":
+		_, err = x509.ParseRevocationList(block.Bytes)
+	}
+	return err
+}
+
+type userAPIDialer interface {
+	Dial(context.Context, validators.Validator, *slog.Logger, string) (userapi.UserAPIClient, func() error, error)
+}
+
+type defaultUserAPIDialer struct{}
+
+func (defaultUserAPIDialer) Dial(ctx context.Context, validator validators.Validator, logger *slog.Logger, addr string) (userapi.UserAPIClient, func() error, error) {
+	// The user API doesn't authenticate clients for GetManifests, so the Coordinator doesn't need
+	// to attest itself.
+	dial := dialer.New(atls.NoIssuer, validator, atls.NoMetrics, nil, logger)
+	conn, err := dial.Dial(ctx, addr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("dialing coordinator: %w", err)
+	}
+	return userapi.NewUserAPIClient(conn), conn.Close, nil
+}
+
+var _ = userAPIDialer(&defaultUserAPIDialer{})
@@ -0,0 +1,217 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package federation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"encoding/pem"
+	"log/slog"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/edgelesssys/contrast/coordinator/internal/stateguard"
+	"github.com/edgelesssys/contrast/internal/atls/validators"
+	"github.com/edgelesssys/contrast/internal/ca"
+	"github.com/edgelesssys/contrast/internal/manifest"
+	"github.com/edgelesssys/contrast/internal/testkeys"
+	"github.com/edgelesssys/contrast/internal/userapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc"
+	clock "k8s.io/utils/clock/testing"
+)
+
+func TestRefreshOnce(t *testing.T) {
+	peerCA, err := ca.New(testkeys.ECDSA(t), testkeys.ECDSA(t))
+	require.NoError(t, err)
+	crl, err := peerCA.CreateCRL(nil, big.NewInt(1), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	peerManifest, err := json.Marshal(manifest.Manifest{SPIFFETrustDomain: "peer.example.org"})
+	require.NoError(t, err)
+	validResp := &userapi.GetManifestsResponse{
+		Manifests: [][]byte{peerManifest},
+		RootCA:    peerCA.GetRootCACert(),
+		MeshCA:    peerCA.GetMeshCACert(),
+		CRL:       crl,
+	}
+	rootCABlock, _ := pem.Decode(peerCA.GetRootCACert())
+	rootCAFingerprint := sha256.Sum256(rootCABlock.Bytes)
+	newDeployment := func(endpoint string) manifest.FederatedDeployment {
+		return newTestDeployment(endpoint, manifest.NewHexString(rootCAFingerprint[:]))
+	}
+	otherCA, err := ca.New(testkeys.ECDSA(t), testkeys.ECDSA(t))
+	require.NoError(t, err)
+	oldBundle := Bundle{MeshCACert: []byte("old mesh CA")}
+
+	testCases := map[string]struct {
+		deployments map[string]manifest.FederatedDeployment
+		oldBundles  map[string]federatedBundle
+		stateErr    error
+		resp        *userapi.GetManifestsResponse
+		dialErr     error
+		wantBundles map[string]Bundle
+		wantErr     bool
+	}{
+		"fetches bundle": {
+			deployments: map[string]manifest.FederatedDeployment{"peer": newDeployment("peer:1313")},
+			resp:        validResp,
+			wantBundles: map[string]Bundle{"peer": {
+				MeshCACert:        peerCA.GetMeshCACert(),
+				RootCACert:        peerCA.GetRootCACert(),
+				CRL:               crl,
+				SPIFFETrustDomain: "peer.example.org",
+			}},
+		},
+		"unreachable deployment keeps bundle": {
+			deployments: map[string]manifest.FederatedDeployment{"peer": newDeployment("peer:1313")},
+			oldBundles:  map[string]federatedBundle{"peer": {deployment: newDeployment("peer:1313"), bundle: oldBundle}},
+			dialErr:     assert.AnError,
+			wantBundles: map[string]Bundle{"peer": oldBundle},
+			wantErr:     true,
+		},
+		"unreachable deployment with changed config": {
+			deployments: map[string]manifest.FederatedDeployment{"peer": newDeployment("other-peer:1313")},
+			oldBundles:  map[string]federatedBundle{"peer": {deployment: newDeployment("peer:1313"), bundle: oldBundle}},
+			dialErr:     assert.AnError,
+			wantBundles: map[string]Bundle{},
+			wantErr:     true,
+		},
+		"removed deployment": {
+			oldBundles:  map[string]federatedBundle{"peer": {deployment: newDeployment("peer:1313"), bundle: oldBundle}},
+			wantBundles: map[string]Bundle{},
+		},
+		"invalid mesh CA": {
+			deployments: map[string]manifest.FederatedDeployment{"peer": newDeployment("peer:1313")},
+			resp: &userapi.GetManifestsResponse{
+				Manifests: [][]byte{peerManifest},
+				RootCA:    peerCA.GetRootCACert(),
+				MeshCA:    crl,
+				CRL:       crl,
+			},
+			wantBundles: map[string]Bundle{},
+			wantErr:     true,
+		},
+		"wrong root CA": {
+			deployments: map[string]manifest.FederatedDeployment{"peer": newDeployment("peer:1313")},
+			resp: &userapi.GetManifestsResponse{
+				Manifests: [][]byte{peerManifest},
+				RootCA:    otherCA.GetRootCACert(),
+				MeshCA:    otherCA.GetMeshCACert(),
+				CRL:       crl,
+			},
+			wantBundles: map[string]Bundle{},
+			wantErr:     true,
+		},
+		"no manifest": {
+			deployments: map[string]manifest.FederatedDeployment{"peer": newDeployment("peer:1313")},
+			resp: &userapi.GetManifestsResponse{
+				RootCA: peerCA.GetRootCACert(),
+				MeshCA: peerCA.GetMeshCACert(),
+				CRL:    crl,
+			},
+			wantBundles: map[string]Bundle{},
+			wantErr:     true,
+		},
+		"stale state": {
+			oldBundles:  map[string]federatedBundle{"peer": {deployment: newDeployment("peer:1313"), bundle: oldBundle}},
+			stateErr:    stateguard.ErrStaleState,
+			wantBundles: map[string]Bundle{"peer": oldBundle},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+
+			now := time.Now()
+			dialer := &stubDialer{client: &stubClient{resp: tc.resp}, err: tc.dialErr}
+			f := &Federator{
+				guard: &stubGuard{
+					state: stateguard.NewStateForTest(nil, &manifest.Manifest{FederatedDeployments: tc.deployments}, nil, nil),
+					err:   tc.stateErr,
+				},
+				logger:  slog.Default(),
+				clock:   clock.NewFakeClock(now),
+				dialer:  dialer,
+				bundles: tc.oldBundles,
+			}
+			for name, b := range tc.wantBundles {
+				if b.UpdatedAt.IsZero() && tc.resp != nil {
+					b.UpdatedAt = now.UTC()
+					tc.wantBundles[name] = b
+				}
+			}
+
+			err := f.RefreshOnce(t.Context())
+			if tc.wantErr {
+				require.Error(err)
+			} else {
+				require.NoError(err)
+			}
+			assert.Equal(tc.wantBundles, f.Bundles())
+			if tc.resp != nil {
+				assert.Equal("peer:1313", dialer.addr)
+				assert.NotNil(dialer.validator)
+			}
+		})
+	}
+}
+
+func TestNilFederator(t *testing.T) {
+	var f *Federator
+	assert.Empty(t, f.Bundles())
+}
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func newTestDeployment(endpoint string, rootCAFingerprint manifest.HexString) manifest.FederatedDeployment {
+	return manifest.FederatedDeployment{
+		CoordinatorEndpoint:   endpoint,
+		CoordinatorPolicyHash: manifest.HexString("dddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddd"),
+		RootCAFingerprint:     rootCAFingerprint,
+		ReferenceValues: manifest.ReferenceValues{
+			SNP: []manifest.SNPReferenceValues{{Platform: "Metal-QEMU-Insecure"}},
+		},
+	}
+}
+
+type stubGuard struct {
+	state *stateguard.State
+	err   error
+}
+
+func (g *stubGuard) GetState(context.Context) (*stateguard.State, error) {
+	return g.state, g.err
+}
+
+type stubDialer struct {
+	client    *stubClient
+	err       error
+	addr      string
+	validator validators.Validator
+}
+
+func (d *stubDialer) Dial(_ context.Context, validator validators.Validator, _ *slog.Logger, addr string) (userapi.UserAPIClient, func() error, error) {
+	d.addr, d.validator = addr, validator
+	if d.err != nil {
+		return nil, nil, d.err
+	}
+	return d.client, func() error { return nil }, nil
+}
+
+type stubClient struct {
+	userapi.UserAPIClient
+	resp *userapi.GetManifestsResponse
+}
+
+func (c *stubClient) GetManifests(context.Context, *userapi.GetManifestsRequest, ...grpc.CallOption) (*userapi.GetManifestsResponse, error) {
+	return c.resp, nil
+}
@@ -18,7 +18,6 @@ import (
 	"github.com/edgelesssys/contrast/coordinator/internal/userapi"
 	"github.com/edgelesssys/contrast/internal/atls"
 	"github.com/edgelesssys/contrast/internal/constants"
-	"github.com/edgelesssys/contrast/internal/manifest"
 )
 
 var (
@@ -32,7 +31,8 @@ var (
 // StateGuard is a stateguard.Guard at runtime, but can be stubbed in tests.
 type StateGuard interface {
 	GetState(context.Context) (*stateguard.State, error)
-	GetHistory(ctx context.Context) ([][]byte, map[manifest.HexString][]byte, error)
+	GetStateHistory(state *stateguard.State) (*stateguard.StateHistory, error)
+	GetPendingUpdate(ctx context.Context) (*stateguard.PendingUpdate, error)
 }
 
 // AttestationHandler handles POST requests to /attest.
@@ -53,18 +53,33 @@ func (h *AttestationHandler) getResponse(ctx context.Context, nonce []byte) (*ap
 		return nil, http.StatusInternalServerError, fmt.Errorf("%w: %w", errGettingState, err)
 	}
 
-	manifests, policies, err := h.StateGuard.GetHistory(ctx)
+	stateHistory, err := h.StateGuard.GetStateHistory(state)
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("%w: %w", errGettingHistory, err)
+	}
+	checkpoint := stateHistory.Checkpoint
+
+	pending, err := h.StateGuard.GetPendingUpdate(ctx)
 	if err != nil {
 		return nil, http.StatusInternalServerError, fmt.Errorf("%w: %w", errGettingHistory, err)
 	}
 
 	ca := state.CA()
 	coordinatorState := &apitypes.CoordinatorState{
-		Manifests: manifests,
+		Manifests: stateHistory.Manifests,
 		RootCA:    ca.GetRootCACert(),
 		MeshCA:    ca.GetMeshCACert(),
 	}
-	for _, policy := range policies {
+	if checkpoint != nil {
+		coordinatorState.PrunedTransitionHash = checkpoint.TransitionHash[:]
+		coordinatorState.PrunedTransitions = checkpoint.Generation
+		coordinatorState.CheckpointSignature = checkpoint.Signature
+	}
+	if pending != nil {
+		coordinatorState.PendingManifest = pending.ManifestBytes
+		coordinatorState.PendingActivationTime = pending.ActivationTime
+	}
+	for _, policy := range stateHistory.Policies {
 		coordinatorState.Policies = append(coordinatorState.Policies, policy)
 	}
 
@@ -20,7 +20,9 @@ import (
 	"github.com/edgelesssys/contrast/internal/atls"
 	"github.com/edgelesssys/contrast/internal/ca"
 	"github.com/edgelesssys/contrast/internal/constants"
+	"github.com/edgelesssys/contrast/internal/history"
 	"github.com/edgelesssys/contrast/internal/manifest"
+	"github.com/edgelesssys/contrast/internal/seedengine"
 	"github.com/edgelesssys/contrast/internal/testkeys"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -181,8 +183,9 @@ func TestAttestationHandler(t *testing.T) {
 }
 
 type stubIssuer struct {
-	oid      asn1.ObjectIdentifier
-	issueErr error
+	oid        asn1.ObjectIdentifier
+	issueErr   error
+	reportData [64]byte
 	atls.Issuer
 }
 
@@ -190,15 +193,23 @@ func (s *stubIssuer) OID() asn1.ObjectIdentifier {
 	return s.oid
 }
 
-func (s *stubIssuer) Issue(_ context.Context, _ [64]byte) (quote []byte, err error) {
+func (s *stubIssuer) Issue(_ context.Context, reportData [64]byte) (quote []byte, err error) {
 	if s.issueErr != nil {
 		return nil, s.issueErr
 	}
+	s.reportData = reportData
 	return []byte("fake-attestation"), nil
 }
 
 type stubGuard struct {
+	seedEngine    *seedengine.SeedEngine
 	ca            *ca.CA
+	manifests     [][]byte
+	policies      map[manifest.HexString][]byte
+	checkpoint    *history.Checkpoint
+	latest        *history.LatestTransition
+	generation    int
+	trustDomain   string
 	getStateErr   error
 	getHistoryErr error
 	stateguard.Guard
@@ -208,7 +219,7 @@ func (s *stubGuard) GetState(context.Context) (*stateguard.State, error) {
 	if s.getStateErr != nil {
 		return nil, s.getStateErr
 	}
-	m := &manifest.Manifest{}
+	m := &manifest.Manifest{SPIFFETrustDomain: s.trustDomain}
 	policyHash := sha256.Sum256(nil)
 	policyHashHex := manifest.NewHexString(policyHash[:])
 	m.Policies = map[manifest.HexString]manifest.PolicyEntry{
@@ -218,12 +229,28 @@ func (s *stubGuard) GetState(context.Context) (*stateguard.State, error) {
 		},
 	}
 
-	return stateguard.NewStateForTest(nil, m, nil, s.ca), nil
+	var manifestBytes []byte
+	if len(s.manifests) > 0 {
+		manifestBytes = s.manifests[len(s.manifests)-1]
+	}
+	latest := s.latest
+	if latest == nil {
+		latest = &history.LatestTransition{}
+	}
+	return stateguard.NewStateForTestWithLatest(s.seedEngine, m, manifestBytes, s.ca, latest, s.generation), nil
 }
 
-func (s *stubGuard) GetHistory(context.Context) ([][]byte, map[manifest.HexString][]byte, error) {
+func (s *stubGuard) GetStateHistory(*stateguard.State) (*stateguard.StateHistory, error) {
 	if s.getHistoryErr != nil {
-		return nil, nil, s.getHistoryErr
+		return nil, s.getHistoryErr
 	}
-	return nil, nil, nil
+	return &stateguard.StateHistory{
+		Manifests:  s.manifests,
+		Policies:   s.policies,
+		Checkpoint: s.checkpoint,
+	}, nil
+}
+
+func (s *stubGuard) GetPendingUpdate(context.Context) (*stateguard.PendingUpdate, error) {
+	return nil, nil
 }
@@ -15,7 +15,7 @@ import (
 //
 // Clients compare this against the versions they know and pick the newest shared one,
 // or fall back to the gRPC API on error or no matching supported versions.
-var supportedAPIVersions = []string{apitypes.APIVersionV1}
+var supportedAPIVersions = []string{apitypes.APIVersionV1, apitypes.APIVersionV2}
 
 // CapabilitiesHandler handles GET requests to /capabilities.
 // It advertises which versions of the Contrast HTTP API the Coordinator supports.
@@ -48,6 +48,7 @@ func TestCapabilitiesHandler(t *testing.T) {
 				var resp apitypes.CapabilitiesResponse
 				require.NoError(json.NewDecoder(res.Body).Decode(&resp))
 				require.Contains(resp.APIVersions, apitypes.APIVersionV1)
+				require.Contains(resp.APIVersions, apitypes.APIVersionV2)
 			}
 		})
 	}
@@ -0,0 +1,66 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package httpapi
+
+import (
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/edgelesssys/contrast/coordinator/internal/certregistry"
+	"github.com/edgelesssys/contrast/coordinator/internal/stateguard"
+	"github.com/edgelesssys/contrast/coordinator/internal/userapi"
+)
+
+// crlValidity is the validity period of CRLs served by the CRLHandler.
+const crlValidity = 24 * time.Hour
+
+// CRLHandler handles GET requests to /crl.
+//
+// It serves the DER-encoded CRL of the current mesh CA. The CRL is signed by the mesh CA, so it
+// doesn't need to be bound to an attestation document.
+type CRLHandler struct {
+	StateGuard StateGuard
+	Registry   *certregistry.Registry
+}
+
+// ServeHTTP implements [http.Handler].
+func (h *CRLHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	state, err := h.StateGuard.GetState(r.Context())
+	switch {
+	case errors.Is(err, stateguard.ErrNoState):
+		writeJSONError(w, http.StatusPreconditionFailed, userapi.ErrNoManifest)
+		return
+	case errors.Is(err, stateguard.ErrStaleState):
+		writeJSONError(w, http.StatusPreconditionFailed, userapi.ErrNeedsRecovery)
+		return
+	case err != nil:
+		writeJSONError(w, http.StatusInternalServerError, fmt.Errorf("%w: %w", errGettingState, err))
+		return
+	}
+
+	crlPEM, err := h.Registry.CRL(state.SeedEngine().TransactionSigningKey(), state.CA(), time.Now().Add(crlValidity))
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Errorf("creating CRL: %w", err))
+		return
+	}
+	block, _ := pem.Decode(crlPEM)
+	if block == nil {
+		writeJSONError(w, http.StatusInternalServerError, errors.New("decoding CRL"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pkix-crl")
+	if _, err := w.Write(block.Bytes); err != nil {
+		log.Printf("writing CRL response: %v", err)
+	}
+}
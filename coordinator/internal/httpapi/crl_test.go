@@ -0,0 +1,101 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package httpapi
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/edgelesssys/contrast/coordinator/internal/certregistry"
+	"github.com/edgelesssys/contrast/coordinator/internal/stateguard"
+	"github.com/edgelesssys/contrast/internal/ca"
+	"github.com/edgelesssys/contrast/internal/constants"
+	"github.com/edgelesssys/contrast/internal/history/aferostore"
+	"github.com/edgelesssys/contrast/internal/seedengine"
+	"github.com/edgelesssys/contrast/internal/testkeys"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCRLHandler(t *testing.T) {
+	testCases := map[string]struct {
+		method    string
+		guard     *stubGuard
+		expStatus int
+	}{
+		"success": {
+			expStatus: http.StatusOK,
+		},
+		"wrong HTTP method": {
+			method:    http.MethodPost,
+			expStatus: http.StatusMethodNotAllowed,
+		},
+		"no state": {
+			guard:     &stubGuard{getStateErr: stateguard.ErrNoState},
+			expStatus: http.StatusPreconditionFailed,
+		},
+		"stale state": {
+			guard:     &stubGuard{getStateErr: stateguard.ErrStaleState},
+			expStatus: http.StatusPreconditionFailed,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+
+			meshKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[1])
+			rootKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[2])
+			meshCA, err := ca.New(rootKey, meshKey)
+			require.NoError(err)
+			if tc.guard == nil {
+				tc.guard = &stubGuard{}
+			}
+			seedEngine, err := seedengine.New(make([]byte, constants.SecretSeedSize), make([]byte, constants.SecretSeedSaltSize))
+			require.NoError(err)
+			tc.guard.ca = meshCA
+			tc.guard.seedEngine = seedEngine
+
+			store := aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()})
+			registry := certregistry.New(store, slog.New(slog.DiscardHandler))
+			signingKey := seedEngine.TransactionSigningKey()
+			require.NoError(registry.RecordIssued(signingKey, meshCA, certregistry.Certificate{SerialNumber: big.NewInt(5), NotAfter: time.Now().Add(time.Hour)}))
+			_, err = registry.Revoke(signingKey, meshCA, []*big.Int{big.NewInt(5)}, "", 0)
+			require.NoError(err)
+
+			handler := &CRLHandler{StateGuard: tc.guard, Registry: registry}
+			method := http.MethodGet
+			if tc.method != "" {
+				method = tc.method
+			}
+			req := httptest.NewRequestWithContext(t.Context(), method, "/crl", nil)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+			res := rec.Result()
+			defer res.Body.Close()
+
+			require.Equal(tc.expStatus, res.StatusCode)
+			if res.StatusCode != http.StatusOK {
+				return
+			}
+			assert.Equal("application/pkix-crl", res.Header.Get("Content-Type"))
+			body, err := io.ReadAll(res.Body)
+			require.NoError(err)
+			crl, err := x509.ParseRevocationList(body)
+			require.NoError(err)
+			require.Len(crl.RevokedCertificateEntries, 1)
+			assert.Equal(big.NewInt(5), crl.RevokedCertificateEntries[0].SerialNumber)
+		})
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package httpapi
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/edgelesssys/contrast/apitypes"
+	"github.com/edgelesssys/contrast/coordinator/internal/stateguard"
+	"github.com/edgelesssys/contrast/coordinator/internal/userapi"
+	"github.com/edgelesssys/contrast/internal/atls"
+	"github.com/edgelesssys/contrast/internal/constants"
+	"github.com/edgelesssys/contrast/internal/history"
+	"github.com/edgelesssys/contrast/internal/manifest"
+	"github.com/edgelesssys/contrast/internal/spiffe"
+)
+
+var (
+	errNonceEncoding = errors.New("invalid nonce encoding")
+	errHashEncoding  = errors.New("invalid hash")
+	errNotFound      = errors.New("not found")
+)
+
+// V2Handler handles GET requests to the read-only endpoints of version 2 of the HTTP API.
+//
+// Every response is bound to an attestation document, see apitypes.ConstructReportDataV2.
+type V2Handler struct {
+	Issuer     atls.Issuer
+	StateGuard StateGuard
+}
+
+// v2Endpoint computes the response data of an endpoint. The path argument holds the part of the
+// request path following the endpoint's path prefix.
+type v2Endpoint func(ctx context.Context, state *stateguard.State, path string) (any, int, error)
+
+// ServeHTTP implements [http.Handler].
+func (h *V2Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var endpoint v2Endpoint
+	var subpath string
+	switch path := r.URL.Path; {
+	case path == apitypes.PathV2Manifest:
+		endpoint = h.getManifest
+	case strings.HasPrefix(path, apitypes.PathV2Manifests):
+		endpoint, subpath = h.getManifestByHash, strings.TrimPrefix(path, apitypes.PathV2Manifests)
+	case strings.HasPrefix(path, apitypes.PathV2Policies):
+		endpoint, subpath = h.getPolicyByHash, strings.TrimPrefix(path, apitypes.PathV2Policies)
+	case path == apitypes.PathV2Transitions:
+		endpoint = h.getTransitions
+	case path == apitypes.PathV2CA:
+		endpoint = h.getCA
+	case path == apitypes.PathV2SPIFFEBundle:
+		endpoint = h.getSPIFFEBundle
+	default:
+		writeJSONError(w, http.StatusNotFound, fmt.Errorf("%w: %s", errNotFound, path))
+		return
+	}
+
+	nonce, err := hex.DecodeString(r.URL.Query().Get("nonce"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Errorf("%w: %w", errNonceEncoding, err))
+		return
+	}
+	if len(nonce) != 32 {
+		writeJSONError(w, http.StatusBadRequest, fmt.Errorf("%w: got %d, expected 32", errNonceLength, len(nonce)))
+		return
+	}
+
+	ctx := r.Context()
+	state, err := h.StateGuard.GetState(ctx)
+	switch {
+	case errors.Is(err, stateguard.ErrNoState):
+		writeJSONError(w, http.StatusPreconditionFailed, userapi.ErrNoManifest)
+		return
+	case errors.Is(err, stateguard.ErrStaleState):
+		writeJSONError(w, http.StatusPreconditionFailed, userapi.ErrNeedsRecovery)
+		return
+	case err != nil:
+		writeJSONError(w, http.StatusInternalServerError, fmt.Errorf("%w: %w", errGettingState, err))
+		return
+	}
+
+	data, errCode, err := endpoint(ctx, state, subpath)
+	if err != nil {
+		writeJSONError(w, errCode, err)
+		return
+	}
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	reportData := apitypes.ConstructReportDataV2(nonce, r.URL.Path, dataBytes)
+	attestation, err := h.Issuer.Issue(ctx, reportData)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Errorf("%w: %w", errGettingAttestation, err))
+		return
+	}
+
+	resp := &apitypes.AttestedResponse{
+		Version:           constants.Version,
+		AttestationType:   h.Issuer.OID(),
+		RawAttestationDoc: attestation,
+		Data:              dataBytes,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(resp); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+	}
+}
+
+func (h *V2Handler) getManifest(_ context.Context, state *stateguard.State, _ string) (any, int, error) {
+	latest := state.LatestTransition()
+	return &apitypes.ManifestResponse{
+		Manifest:       state.ManifestBytes(),
+		TransitionHash: latest.TransitionHash[:],
+		Generation:     uint64(state.Generation()),
+	}, http.StatusOK, nil
+}
+
+func (h *V2Handler) getManifestByHash(_ context.Context, state *stateguard.State, hashHex string) (any, int, error) {
+	hash, err := parseHash(hashHex)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	stateHistory, err := h.StateGuard.GetStateHistory(state)
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("%w: %w", errGettingHistory, err)
+	}
+	for _, m := range stateHistory.Manifests {
+		if history.Digest(m) == hash {
+			return &apitypes.ManifestResponse{Manifest: m}, http.StatusOK, nil
+		}
+	}
+	return nil, http.StatusNotFound, fmt.Errorf("%w: manifest %x", errNotFound, hash)
+}
+
+func (h *V2Handler) getPolicyByHash(_ context.Context, state *stateguard.State, hashHex string) (any, int, error) {
+	hash, err := parseHash(hashHex)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	stateHistory, err := h.StateGuard.GetStateHistory(state)
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("%w: %w", errGettingHistory, err)
+	}
+	policy, ok := stateHistory.Policies[manifest.NewHexString(hash[:])]
+	if !ok {
+		return nil, http.StatusNotFound, fmt.Errorf("%w: policy %x", errNotFound, hash)
+	}
+	return &apitypes.PolicyResponse{Policy: policy}, http.StatusOK, nil
+}
+
+func (h *V2Handler) getTransitions(_ context.Context, state *stateguard.State, _ string) (any, int, error) {
+	transitions, err := h.getTransitionChain(state)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return transitions, http.StatusOK, nil
+}
+
+func (h *V2Handler) getCA(_ context.Context, state *stateguard.State, _ string) (any, int, error) {
+	ca := state.CA()
+	return &apitypes.CAResponse{
+		RootCA: ca.GetRootCACert(),
+		MeshCA: ca.GetMeshCACert(),
+	}, http.StatusOK, nil
+}
+
+func (h *V2Handler) getSPIFFEBundle(_ context.Context, state *stateguard.State, _ string) (any, int, error) {
+	trustDomain := state.Manifest().SPIFFETrustDomain
+	if trustDomain == "" {
+		return nil, http.StatusNotFound, fmt.Errorf("%w: manifest has no SPIFFE trust domain", errNotFound)
+	}
+	bundle, err := spiffe.NewBundle(state.CA().GetMeshCACert())
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("creating SPIFFE bundle: %w", err)
+	}
+	return &apitypes.SPIFFEBundleResponse{
+		TrustDomain: trustDomain,
+		Bundle:      bundle,
+	}, http.StatusOK, nil
+}
+
+// getTransitionChain returns the transition chain leading to the given state, rebuilt from its
+// history.
+func (h *V2Handler) getTransitionChain(state *stateguard.State) (*apitypes.TransitionsResponse, error) {
+	stateHistory, err := h.StateGuard.GetStateHistory(state)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errGettingHistory, err)
+	}
+	if len(stateHistory.Manifests) == 0 {
+		return nil, fmt.Errorf("%w: history is empty", errGettingHistory)
+	}
+
+	resp := &apitypes.TransitionsResponse{}
+	var previousTransitionHash [history.HashSize]byte
+	if stateHistory.Checkpoint != nil {
+		previousTransitionHash = stateHistory.Checkpoint.TransitionHash
+		resp.PrunedTransitions = stateHistory.Checkpoint.Generation
+	}
+	var latestTransitionHash [history.HashSize]byte
+	for i, t := range history.BuildTransitionChainFrom(previousTransitionHash, stateHistory.Manifests) {
+		transitionHash := t.Digest()
+		latestTransitionHash = transitionHash
+		resp.Transitions = append(resp.Transitions, apitypes.Transition{
+			TransitionHash:         transitionHash[:],
+			PreviousTransitionHash: t.PreviousTransitionHash[:],
+			ManifestHash:           t.ManifestHash[:],
+			Generation:             resp.PrunedTransitions + uint64(i) + 1,
+		})
+	}
+	if latestTransitionHash != state.LatestTransition().TransitionHash {
+		return nil, fmt.Errorf("%w: transition chain doesn't end at the latest transition", errGettingHistory)
+	}
+	return resp, nil
+}
+
+func parseHash(hashHex string) ([history.HashSize]byte, error) {
+	var hash [history.HashSize]byte
+	if len(hashHex) != 2*history.HashSize {
+		return hash, fmt.Errorf("%w: expected %d hex-encoded bytes", errHashEncoding, history.HashSize)
+	}
+	if _, err := hex.Decode(hash[:], []byte(hashHex)); err != nil {
+		return hash, fmt.Errorf("%w: %w", errHashEncoding, err)
+	}
+	return hash, nil
+}
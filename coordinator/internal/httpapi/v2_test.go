@@ -0,0 +1,267 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package httpapi
+
+import (
+	"crypto/ecdsa"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/edgelesssys/contrast/apitypes"
+	"github.com/edgelesssys/contrast/coordinator/internal/stateguard"
+	"github.com/edgelesssys/contrast/coordinator/internal/userapi"
+	"github.com/edgelesssys/contrast/internal/ca"
+	"github.com/edgelesssys/contrast/internal/constants"
+	"github.com/edgelesssys/contrast/internal/history"
+	"github.com/edgelesssys/contrast/internal/manifest"
+	"github.com/edgelesssys/contrast/internal/testkeys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestV2Handler(t *testing.T) {
+	manifests := [][]byte{[]byte(`{"Policies":{}}`), []byte(`{"Policies":{"a":{}}}`)}
+	policy := []byte("policy")
+	policyHash := history.Digest(policy)
+	manifestHash := history.Digest(manifests[0])
+	checkpoint := &history.Checkpoint{TransitionHash: [history.HashSize]byte{1}, Generation: 3}
+	transitions := history.BuildTransitionChainFrom(checkpoint.TransitionHash, manifests)
+	latestTransitionHash := transitions[1].Digest()
+	hexNonce := hex.EncodeToString(nonce)
+
+	testCases := map[string]struct {
+		path   string
+		method string
+		guard  *stubGuard
+		issuer *stubIssuer
+
+		expStatus int
+		expErr    error
+		expData   any
+	}{
+		"active manifest": {
+			path:      apitypes.PathV2Manifest,
+			expStatus: http.StatusOK,
+			expData: &apitypes.ManifestResponse{
+				Manifest:       manifests[1],
+				TransitionHash: latestTransitionHash[:],
+				Generation:     5,
+			},
+		},
+		"manifest by hash": {
+			path:      apitypes.PathV2Manifests + hex.EncodeToString(manifestHash[:]),
+			expStatus: http.StatusOK,
+			expData:   &apitypes.ManifestResponse{Manifest: manifests[0]},
+		},
+		"unknown manifest": {
+			path:      apitypes.PathV2Manifests + strings.Repeat("00", history.HashSize),
+			expStatus: http.StatusNotFound,
+			expErr:    errNotFound,
+		},
+		"invalid manifest hash": {
+			path:      apitypes.PathV2Manifests + "abc",
+			expStatus: http.StatusBadRequest,
+			expErr:    errHashEncoding,
+		},
+		"policy by hash": {
+			path:      apitypes.PathV2Policies + hex.EncodeToString(policyHash[:]),
+			expStatus: http.StatusOK,
+			expData:   &apitypes.PolicyResponse{Policy: policy},
+		},
+		"unknown policy": {
+			path:      apitypes.PathV2Policies + strings.Repeat("00", history.HashSize),
+			expStatus: http.StatusNotFound,
+			expErr:    errNotFound,
+		},
+		"transitions": {
+			path:      apitypes.PathV2Transitions,
+			expStatus: http.StatusOK,
+			expData: &apitypes.TransitionsResponse{
+				Transitions: []apitypes.Transition{
+					{
+						TransitionHash:         digestSlice(transitions[0].Digest()),
+						PreviousTransitionHash: checkpoint.TransitionHash[:],
+						ManifestHash:           digestSlice(history.Digest(manifests[0])),
+						Generation:             4,
+					},
+					{
+						TransitionHash:         latestTransitionHash[:],
+						PreviousTransitionHash: digestSlice(transitions[0].Digest()),
+						ManifestHash:           digestSlice(history.Digest(manifests[1])),
+						Generation:             5,
+					},
+				},
+				PrunedTransitions: 3,
+			},
+		},
+		"ca": {
+			path:      apitypes.PathV2CA,
+			expStatus: http.StatusOK,
+		},
+		"spiffe bundle": {
+			path:      apitypes.PathV2SPIFFEBundle,
+			guard:     &stubGuard{trustDomain: "example.org"},
+			expStatus: http.StatusOK,
+		},
+		"no SPIFFE trust domain": {
+			path:      apitypes.PathV2SPIFFEBundle,
+			expStatus: http.StatusNotFound,
+			expErr:    errNotFound,
+		},
+		"unknown path": {
+			path:      "/v2/unknown",
+			expStatus: http.StatusNotFound,
+			expErr:    errNotFound,
+		},
+		"wrong HTTP method": {
+			path:      apitypes.PathV2CA,
+			method:    http.MethodPost,
+			expStatus: http.StatusMethodNotAllowed,
+		},
+		"no state": {
+			path:      apitypes.PathV2Manifest,
+			guard:     &stubGuard{getStateErr: stateguard.ErrNoState},
+			expStatus: http.StatusPreconditionFailed,
+			expErr:    userapi.ErrNoManifest,
+		},
+		"stale state": {
+			path:      apitypes.PathV2Manifest,
+			guard:     &stubGuard{getStateErr: stateguard.ErrStaleState},
+			expStatus: http.StatusPreconditionFailed,
+			expErr:    userapi.ErrNeedsRecovery,
+		},
+		"unknown error during GetHistory": {
+			path:      apitypes.PathV2Transitions,
+			guard:     &stubGuard{getHistoryErr: assert.AnError},
+			expStatus: http.StatusInternalServerError,
+			expErr:    errGettingHistory,
+		},
+		"history doesn't lead to the state": {
+			path: apitypes.PathV2Transitions,
+			guard: &stubGuard{
+				manifests:  manifests[:1],
+				checkpoint: checkpoint,
+				latest:     &history.LatestTransition{TransitionHash: latestTransitionHash},
+				generation: 5,
+			},
+			expStatus: http.StatusInternalServerError,
+			expErr:    errGettingHistory,
+		},
+		"unable to get attestation": {
+			path:      apitypes.PathV2CA,
+			issuer:    &stubIssuer{issueErr: assert.AnError},
+			expStatus: http.StatusInternalServerError,
+			expErr:    errGettingAttestation,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			if tc.guard == nil {
+				tc.guard = &stubGuard{
+					manifests:  manifests,
+					policies:   map[manifest.HexString][]byte{manifest.NewHexString(policyHash[:]): policy},
+					checkpoint: checkpoint,
+					latest:     &history.LatestTransition{TransitionHash: latestTransitionHash},
+					generation: 5,
+				}
+			}
+			meshKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[1])
+			rootKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[2])
+			ca, err := ca.New(rootKey, meshKey)
+			require.NoError(err)
+			tc.guard.ca = ca
+
+			expectedOID := asn1.ObjectIdentifier{1, 2, 3}
+			if tc.issuer == nil {
+				tc.issuer = &stubIssuer{oid: expectedOID}
+			}
+
+			handler := &V2Handler{
+				StateGuard: tc.guard,
+				Issuer:     tc.issuer,
+			}
+
+			method := http.MethodGet
+			if tc.method != "" {
+				method = tc.method
+			}
+			req := httptest.NewRequestWithContext(t.Context(), method, tc.path+"?nonce="+hexNonce, nil)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+			res := rec.Result()
+			defer res.Body.Close()
+
+			require.Equal(tc.expStatus, res.StatusCode)
+
+			if tc.expErr != nil {
+				var apiErr apitypes.AttestationError
+				require.NoError(json.NewDecoder(res.Body).Decode(&apiErr))
+				require.Contains(apiErr.Err, tc.expErr.Error())
+			} else if res.StatusCode == http.StatusOK {
+				var resp apitypes.AttestedResponse
+				require.NoError(json.NewDecoder(res.Body).Decode(&resp))
+				require.Equal(constants.Version, resp.Version)
+				require.Equal(expectedOID, resp.AttestationType)
+				require.NotEmpty(resp.RawAttestationDoc)
+				require.Equal(apitypes.ConstructReportDataV2(nonce, tc.path, resp.Data), tc.issuer.reportData)
+				if tc.expData != nil {
+					expData, err := json.Marshal(tc.expData)
+					require.NoError(err)
+					require.JSONEq(string(expData), string(resp.Data))
+				}
+			}
+		})
+	}
+}
+
+func TestV2Handler_Nonce(t *testing.T) {
+	testCases := map[string]struct {
+		query  string
+		expErr error
+	}{
+		"missing nonce": {
+			expErr: errNonceLength,
+		},
+		"short nonce": {
+			query:  "?nonce=0102",
+			expErr: errNonceLength,
+		},
+		"invalid encoding": {
+			query:  "?nonce=xyz",
+			expErr: errNonceEncoding,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			handler := &V2Handler{StateGuard: &stubGuard{}, Issuer: &stubIssuer{}}
+			req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, apitypes.PathV2CA+tc.query, nil)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+			res := rec.Result()
+			defer res.Body.Close()
+
+			require.Equal(http.StatusBadRequest, res.StatusCode)
+			var apiErr apitypes.AttestationError
+			require.NoError(json.NewDecoder(res.Body).Decode(&apiErr))
+			require.Contains(apiErr.Err, tc.expErr.Error())
+		})
+	}
+}
+
+func digestSlice(digest [history.HashSize]byte) []byte {
+	return digest[:]
+}
@@ -0,0 +1,139 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package meshapi
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// minRSAKeySize is the smallest RSA key size accepted in a CSR.
+const minRSAKeySize = 2048
+
+var (
+	errInvalidCSR    = errors.New("invalid certificate signing request")
+	errSANNotAllowed = errors.New("SAN is not allowed by the manifest")
+)
+
+// csrNames are the SANs requested by a CSR, in the form accepted by ca.NewAttestedMeshCert.
+type csrNames struct {
+	names          []string
+	emailAddresses []string
+}
+
+// parseCSR parses a PEM-encoded CSR and verifies its signature and public key.
+func parseCSR(csrPEM []byte) (*x509.CertificateRequest, error) {
+	block, _ := pem.Decode(csrPEM)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, fmt.Errorf("%w: no PEM block of type CERTIFICATE REQUEST", errInvalidCSR)
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidCSR, err)
+	}
+	// The signature proves that the workload holds the private key.
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidCSR, err)
+	}
+	switch pub := csr.PublicKey.(type) {
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() && pub.Curve != elliptic.P384() && pub.Curve != elliptic.P521() {
+			return nil, fmt.Errorf("%w: unsupported curve %s", errInvalidCSR, pub.Curve.Params().Name)
+		}
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSAKeySize {
+			return nil, fmt.Errorf("%w: RSA key size %d is smaller than %d", errInvalidCSR, pub.N.BitLen(), minRSAKeySize)
+		}
+	case ed25519.PublicKey:
+	default:
+		return nil, fmt.Errorf("%w: unsupported public key type %T", errInvalidCSR, csr.PublicKey)
+	}
+	return csr, nil
+}
+
+// allowedCSRNames checks that all SANs of the CSR are allowed by the given names and email
+// addresses, and returns them. DNS names may be matched by wildcard names. If the CSR has no SANs,
+// the allowed names and email addresses are returned.
+func allowedCSRNames(csr *x509.CertificateRequest, allowedNames, allowedEmails []string) (csrNames, error) {
+	if len(csr.DNSNames) == 0 && len(csr.IPAddresses) == 0 && len(csr.URIs) == 0 && len(csr.EmailAddresses) == 0 {
+		return csrNames{names: allowedNames, emailAddresses: allowedEmails}, nil
+	}
+
+	var allowedDNSNames []string
+	var allowedIPs []net.IP
+	var allowedURIs []string
+	for _, name := range allowedNames {
+		if ip := net.ParseIP(name); ip != nil {
+			allowedIPs = append(allowedIPs, ip)
+		} else if uri, err := url.Parse(name); err == nil && uri.Scheme != "" {
+			allowedURIs = append(allowedURIs, uri.String())
+		} else {
+			allowedDNSNames = append(allowedDNSNames, name)
+		}
+	}
+
+	var names csrNames
+	var errs []error
+	for _, name := range csr.DNSNames {
+		if !slices.ContainsFunc(allowedDNSNames, func(pattern string) bool { return matchDNSName(pattern, name) }) {
+			errs = append(errs, fmt.Errorf("%w: DNS name %q", errSANNotAllowed, name))
+		}
+		names.names = append(names.names, name)
+	}
+	for _, ip := range csr.IPAddresses {
+		if !slices.ContainsFunc(allowedIPs, ip.Equal) {
+			errs = append(errs, fmt.Errorf("%w: IP address %s", errSANNotAllowed, ip))
+		}
+		names.names = append(names.names, ip.String())
+	}
+	for _, uri := range csr.URIs {
+		if !slices.Contains(allowedURIs, uri.String()) {
+			errs = append(errs, fmt.Errorf("%w: URI %q", errSANNotAllowed, uri))
+		}
+		names.names = append(names.names, uri.String())
+	}
+	for _, email := range csr.EmailAddresses {
+		if !slices.Contains(allowedEmails, email) {
+			errs = append(errs, fmt.Errorf("%w: email address %q", errSANNotAllowed, email))
+		}
+		names.emailAddresses = append(names.emailAddresses, email)
+	}
+	if len(csr.DNSNames) == 0 {
+		// The CA uses the first DNS name as common name.
+		errs = append(errs, fmt.Errorf("%w: CSR must contain a DNS name", errInvalidCSR))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return csrNames{}, err
+	}
+	return names, nil
+}
+
+// matchDNSName reports whether name is matched by pattern. A pattern "*.<domain>" matches names
+// with a single label in front of the domain. All other patterns, including a bare "*", only match
+// themselves. Wildcard names are only matched by the same wildcard, so that a workload can't
+// request a wildcard certificate the manifest doesn't list.
+func matchDNSName(pattern, name string) bool {
+	pattern, name = strings.ToLower(pattern), strings.ToLower(name)
+	switch {
+	case pattern == name:
+		return true
+	case strings.Contains(name, "*"):
+		return false
+	case strings.HasPrefix(pattern, "*.") && pattern != "*.":
+		label, domain, ok := strings.Cut(name, ".")
+		return ok && label != "" && domain == pattern[2:]
+	default:
+		return false
+	}
+}
@@ -5,16 +5,30 @@ package meshapi
 
 import (
 	"context"
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/edgelesssys/contrast/coordinator/internal/certregistry"
+	"github.com/edgelesssys/contrast/coordinator/internal/federation"
 	"github.com/edgelesssys/contrast/coordinator/internal/stateguard"
+	"github.com/edgelesssys/contrast/internal/auditlog"
+	"github.com/edgelesssys/contrast/internal/ca"
 	"github.com/edgelesssys/contrast/internal/manifest"
 	"github.com/edgelesssys/contrast/internal/meshapi"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
@@ -23,17 +37,61 @@ import (
 	"github.com/edgelesssys/contrast/internal/oid"
 )
 
+// Reasons of failed mesh certificate issuances, used as label of the failure metric.
+const (
+	reasonInvalidPeer     = "invalid_peer"
+	reasonUnknownPolicy   = "unknown_policy"
+	reasonInvalidManifest = "invalid_manifest"
+	reasonInvalidCSR      = "invalid_csr"
+	reasonForbiddenNames  = "forbidden_names"
+	reasonSigning         = "signing"
+	reasonRecording       = "recording"
+	reasonCRL             = "crl"
+	reasonWorkloadSecret  = "workload_secret"
+	reasonInternal        = "internal"
+)
+
 // Server implements the meshapi service.
 type Server struct {
-	logger *slog.Logger
+	logger    *slog.Logger
+	audit     *auditlog.Log
+	registry  *certregistry.Registry
+	federator *federation.Federator
+	metrics   metrics
 
 	meshapi.UnimplementedMeshAPIServer
 }
 
-// New returns a meshapi server using a sub-logger of log.
-func New(log *slog.Logger) *Server {
+type metrics struct {
+	certsIssued      *prometheus.CounterVec
+	issuanceFailures *prometheus.CounterVec
+}
+
+// New returns a meshapi server using a sub-logger of log, which registers its metrics with reg.
+// Issued certificates and secrets are recorded to audit, issued certificates are tracked in
+// registry, and the trust bundle contains the mesh CAs of the federated deployments known to
+// federator. All four may be nil.
+func New(log *slog.Logger, reg *prometheus.Registry, audit *auditlog.Log, registry *certregistry.Registry, federator *federation.Federator) *Server {
+	certsIssued := promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+		Subsystem: "contrast_meshapi",
+		Name:      "mesh_certificates_issued_total",
+		Help:      "Number of mesh certificates issued to workloads.",
+	}, []string{"policy_hash", "manifest_generation"})
+	issuanceFailures := promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+		Subsystem: "contrast_meshapi",
+		Name:      "mesh_certificate_issuance_failures_total",
+		Help:      "Number of failed mesh certificate requests of attested workloads.",
+	}, []string{"reason"})
+
 	return &Server{
-		logger: log.WithGroup("meshapi"),
+		logger:    log.WithGroup("meshapi"),
+		audit:     audit,
+		registry:  registry,
+		federator: federator,
+		metrics: metrics{
+			certsIssued:      certsIssued,
+			issuanceFailures: issuanceFailures,
+		},
 	}
 }
 
@@ -41,54 +99,102 @@ func New(log *slog.Logger) *Server {
 //
 // When this handler is called, the transport credentials already ensured that
 // the peer is authorized according to the manifest, so it can start issuing
-// right away.
-func (i *Server) NewMeshCert(ctx context.Context, _ *meshapi.NewMeshCertRequest) (*meshapi.NewMeshCertResponse, error) {
+// right away. If the request contains a CSR, the certificate is issued for the
+// key of the CSR, restricted to the SANs the manifest allows for the peer.
+func (i *Server) NewMeshCert(ctx context.Context, req *meshapi.NewMeshCertRequest) (*meshapi.NewMeshCertResponse, error) {
 	i.logger.Info("NewMeshCert called")
 
+	resp, err := i.newMeshCert(ctx, req)
+	if err != nil {
+		reason := reasonInternal
+		var issuanceErr *issuanceError
+		if errors.As(err, &issuanceErr) {
+			reason, err = issuanceErr.reason, issuanceErr.err
+		}
+		i.metrics.issuanceFailures.WithLabelValues(reason).Inc()
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (i *Server) newMeshCert(ctx context.Context, req *meshapi.NewMeshCertRequest) (*meshapi.NewMeshCertResponse, error) {
 	p, ok := peer.FromContext(ctx)
 	if !ok {
-		return nil, fmt.Errorf("failed to get peer from context")
+		return nil, newIssuanceError(reasonInvalidPeer, fmt.Errorf("failed to get peer from context"))
 	}
 
 	authInfo, ok := p.AuthInfo.(stateguard.AuthInfo)
 	if !ok {
-		return nil, fmt.Errorf("unexpected AuthInfo type: %T", p.AuthInfo)
+		return nil, newIssuanceError(reasonInvalidPeer, fmt.Errorf("unexpected AuthInfo type: %T", p.AuthInfo))
 	}
 	state := authInfo.State
 	report := authInfo.Report
 	tlsInfo := authInfo.TLSInfo
 
 	if len(tlsInfo.State.PeerCertificates) == 0 {
-		return nil, fmt.Errorf("no peer certificates found")
+		return nil, newIssuanceError(reasonInvalidPeer, fmt.Errorf("no peer certificates found"))
 	}
 
 	peerCert := tlsInfo.State.PeerCertificates[0]
 	peerPubKeyBytes, err := x509.MarshalPKIXPublicKey(peerCert.PublicKey)
 	if err != nil {
-		return nil, fmt.Errorf("could not marshal public key: %w", err)
+		return nil, newIssuanceError(reasonInvalidPeer, fmt.Errorf("could not marshal public key: %w", err))
 	}
 
 	hostData := manifest.NewHexString(report.HostData())
 	entry, ok := state.Manifest().Policies[hostData]
 	if !ok {
-		return nil, status.Errorf(codes.PermissionDenied, "policy hash %s not found in manifest", hostData)
+		return nil, newIssuanceError(reasonUnknownPolicy, status.Errorf(codes.PermissionDenied, "policy hash %s not found in manifest", hostData))
+	}
+	profile := entry.MeshCertProfile
+	if profile == nil {
+		profile = &manifest.MeshCertProfile{}
+	}
+	dnsNames := slices.Clone(entry.SANs)
+	// The SPIFFE ID was validated with the manifest. It's added as URI SAN by the CA.
+	spiffeID, err := state.Manifest().SPIFFEID(entry)
+	if err != nil {
+		return nil, newIssuanceError(reasonInvalidManifest, fmt.Errorf("invalid SPIFFE ID: %w", err))
+	}
+	if spiffeID != "" {
+		dnsNames = append(dnsNames, spiffeID)
 	}
-	dnsNames := entry.SANs
 
 	if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
-		dnsNames = append(dnsNames, host)
+		if ip, err := netip.ParseAddr(host); err != nil || profile.AllowsIP(ip) {
+			dnsNames = append(dnsNames, host)
+		}
 	}
 
 	peerPubKey, err := x509.ParsePKIXPublicKey(peerPubKeyBytes)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse peer public key: %w", err)
+		return nil, newIssuanceError(reasonInvalidPeer, fmt.Errorf("failed to parse peer public key: %w", err))
+	}
+
+	emailAddresses := profile.EmailAddresses
+	if len(req.GetCSR()) > 0 {
+		csr, err := parseCSR(req.GetCSR())
+		if err != nil {
+			return nil, newIssuanceError(reasonInvalidCSR, status.Error(codes.InvalidArgument, err.Error()))
+		}
+		names, err := allowedCSRNames(csr, dnsNames, emailAddresses)
+		if err != nil {
+			return nil, newIssuanceError(reasonForbiddenNames, status.Error(codes.PermissionDenied, err.Error()))
+		}
+		dnsNames, emailAddresses = names.names, names.emailAddresses
+		peerPubKey = csr.PublicKey
 	}
 
 	extensions, err := report.ClaimsToCertExtension()
 	if err != nil {
 		return nil, fmt.Errorf("failed to construct extensions: %w", err)
 	}
-	ca := state.CA()
+	policyHashExtension, err := extension.ConvertExtension(extension.NewBytesExtension(oid.PolicyHashOID, report.HostData()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct policy hash extension: %w", err)
+	}
+	extensions = append(extensions, policyHashExtension)
+	meshCA := state.CA()
 	if entry.WorkloadSecretID != "" {
 		workloadSecretExtension, err := extension.ConvertExtension(extension.NewBytesExtension(oid.WorkloadSecretOID,
 			[]byte(entry.WorkloadSecretID)))
@@ -97,25 +203,161 @@ func (i *Server) NewMeshCert(ctx context.Context, _ *meshapi.NewMeshCertRequest)
 		}
 		extensions = append(extensions, workloadSecretExtension)
 	}
-	cert, err := ca.NewAttestedMeshCert(dnsNames, extensions, peerPubKey)
+	// The lifetime and the profile were validated with the manifest.
+	lifetime, err := entry.CertLifetime()
+	if err != nil {
+		return nil, newIssuanceError(reasonInvalidManifest, fmt.Errorf("invalid mesh cert lifetime: %w", err))
+	}
+	profileExtensions, err := profile.PKIXExtensions()
+	if err != nil {
+		return nil, newIssuanceError(reasonInvalidManifest, fmt.Errorf("invalid mesh cert extensions: %w", err))
+	}
+	extensions = append(extensions, profileExtensions...)
+	extKeyUsage, err := profile.X509ExtKeyUsages()
+	if err != nil {
+		return nil, newIssuanceError(reasonInvalidManifest, fmt.Errorf("invalid mesh cert key usages: %w", err))
+	}
+	cert, err := meshCA.NewAttestedMeshCert(dnsNames, extensions, peerPubKey, ca.MeshCertOptions{
+		Lifetime:       lifetime,
+		Subject:        profile.PKIXSubject(),
+		EmailAddresses: emailAddresses,
+		ExtKeyUsage:    extKeyUsage,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to issue new attested mesh cert: %w", err)
+		return nil, newIssuanceError(reasonSigning, fmt.Errorf("failed to issue new attested mesh cert: %w", err))
+	}
+	certBlock, _ := pem.Decode(cert)
+	if certBlock == nil {
+		return nil, newIssuanceError(reasonSigning, fmt.Errorf("failed to decode issued mesh cert"))
+	}
+	parsedCert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, newIssuanceError(reasonSigning, fmt.Errorf("failed to parse issued mesh cert: %w", err))
+	}
+	signingKey := state.SeedEngine().TransactionSigningKey()
+	err = i.registry.RecordLeaf(signingKey, meshCA, certregistry.Leaf{
+		Certificate: certregistry.Certificate{
+			SerialNumber: parsedCert.SerialNumber,
+			PolicyHash:   hostData.String(),
+			IssuedAt:     time.Now().UTC(),
+			NotAfter:     parsedCert.NotAfter,
+		},
+		SANs:               slices.Concat(dnsNames, emailAddresses),
+		PeerAddress:        peerAddress(p),
+		ManifestGeneration: state.Generation(),
+	})
+	if err != nil {
+		return nil, newIssuanceError(reasonRecording, fmt.Errorf("failed to record issued mesh cert: %w", err))
+	}
+
+	// The CRL is valid as long as the certificate it's delivered with, because proxies might load
+	// it only once and must not reject peers because of an outdated CRL.
+	crl, err := i.registry.CRL(signingKey, meshCA, parsedCert.NotAfter)
+	if err != nil {
+		return nil, newIssuanceError(reasonCRL, fmt.Errorf("failed to create CRL: %w", err))
 	}
 
 	resp := &meshapi.NewMeshCertResponse{
-		MeshCACert: ca.GetMeshCACert(),
-		CertChain:  append(cert, ca.GetIntermCACert()...),
-		RootCACert: ca.GetRootCACert(),
+		MeshCACert: meshCA.GetMeshCACert(),
+		CertChain:  append(cert, meshCA.GetIntermCACert()...),
+		RootCACert: meshCA.GetRootCACert(),
 	}
+	resp.TrustBundle, resp.CRL, resp.FederatedSPIFFEBundles = i.federatedBundles(resp.MeshCACert, crl, state.Manifest())
 
 	if entry.WorkloadSecretID != "" {
 		workloadSecret, err := state.SeedEngine().DeriveWorkloadSecret(entry.WorkloadSecretID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to derive workload secret: %w", err)
+			return nil, newIssuanceError(reasonWorkloadSecret, fmt.Errorf("failed to derive workload secret: %w", err))
 		}
 		resp.WorkloadSecret = workloadSecret
 	}
 
+	i.audit.Record(auditlog.Event{
+		Type:        auditlog.EventMeshCertIssued,
+		Actor:       hostData.String(),
+		PeerAddress: peerAddress(p),
+		Details: map[string]string{
+			"sans":               strings.Join(dnsNames, ","),
+			"workload_secret_id": entry.WorkloadSecretID,
+			"serial_number":      parsedCert.SerialNumber.Text(16),
+			"csr":                fmt.Sprint(len(req.GetCSR()) > 0),
+		},
+	})
+	i.metrics.certsIssued.WithLabelValues(hostData.String(), strconv.Itoa(state.Generation())).Inc()
+	return resp, nil
+}
+
+// federatedBundles returns the trust bundle and the CRLs for the given mesh CA cert and its CRL,
+// including the mesh CAs and CRLs of the deployments federated by mnfst, and the mesh CAs of the
+// federated SPIFFE trust domains.
+func (i *Server) federatedBundles(meshCACert, crl []byte, mnfst *manifest.Manifest) (trustBundle, crls []byte, spiffeBundles map[string][]byte) {
+	trustBundle = slices.Clone(meshCACert)
+	crls = slices.Clone(crl)
+	bundles := i.federator.Bundles()
+	// Bundles of deployments that were removed from the manifest are only dropped by the next
+	// refresh of the federator, so the manifest is authoritative.
+	for _, name := range slices.Sorted(maps.Keys(mnfst.FederatedDeployments)) {
+		bundle, ok := bundles[name]
+		if !ok {
+			i.logger.Warn("Bundle of federated deployment isn't available yet", "deployment", name)
+			continue
+		}
+		trustBundle = append(trustBundle, bundle.MeshCACert...)
+		crls = append(crls, bundle.CRL...)
+		if bundle.SPIFFETrustDomain == "" || bundle.SPIFFETrustDomain == mnfst.SPIFFETrustDomain {
+			continue
+		}
+		if spiffeBundles == nil {
+			spiffeBundles = make(map[string][]byte)
+		}
+		spiffeBundles[bundle.SPIFFETrustDomain] = append(spiffeBundles[bundle.SPIFFETrustDomain], bundle.MeshCACert...)
+	}
+	return trustBundle, crls, spiffeBundles
+}
+
+// GetTrustBundle returns the current trust bundle and CRLs to authenticated workloads, so that
+// workloads can pick up revocations and changes to the federated deployments without requesting
+// a new mesh certificate.
+func (i *Server) GetTrustBundle(ctx context.Context, _ *meshapi.GetTrustBundleRequest) (*meshapi.GetTrustBundleResponse, error) {
+	i.logger.Debug("GetTrustBundle called")
+
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("failed to get peer from context")
+	}
+
+	authInfo, ok := p.AuthInfo.(stateguard.AuthInfo)
+	if !ok {
+		return nil, fmt.Errorf("unexpected AuthInfo type: %T", p.AuthInfo)
+	}
+	state := authInfo.State
+
+	hostData := manifest.NewHexString(authInfo.Report.HostData())
+	entry, ok := state.Manifest().Policies[hostData]
+	if !ok {
+		return nil, status.Errorf(codes.PermissionDenied, "policy hash %s not found in manifest", hostData)
+	}
+	lifetime, err := entry.CertLifetime()
+	if err != nil {
+		return nil, fmt.Errorf("invalid mesh cert lifetime: %w", err)
+	}
+
+	// All mesh certificates the workload holds expire within their lifetime, so the CRL is valid
+	// for as long as the CRL delivered with a new certificate.
+	nextUpdate := time.Now().AddDate(1, 0, 0)
+	if lifetime > 0 {
+		nextUpdate = time.Now().Add(lifetime)
+	}
+	meshCA := state.CA()
+	crl, err := i.registry.CRL(state.SeedEngine().TransactionSigningKey(), meshCA, nextUpdate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CRL: %w", err)
+	}
+
+	resp := &meshapi.GetTrustBundleResponse{
+		MeshCACert: meshCA.GetMeshCACert(),
+	}
+	resp.TrustBundle, resp.CRL, resp.FederatedSPIFFEBundles = i.federatedBundles(resp.MeshCACert, crl, state.Manifest())
 	return resp, nil
 }
 
@@ -159,16 +401,58 @@ func (i *Server) Recover(ctx context.Context, _ *meshapi.RecoverRequest) (*mesha
 		LatestManifest: state.ManifestBytes(),
 	}
 
+	i.audit.Record(auditlog.Event{
+		Type:        auditlog.EventPeerRecover,
+		Actor:       hostData.String(),
+		PeerAddress: peerAddress(p),
+	})
 	return resp, nil
 }
 
-func encodeKey(key *ecdsa.PrivateKey) ([]byte, error) {
-	der, err := x509.MarshalECPrivateKey(key)
+// issuanceError is an error of NewMeshCert along with the reason reported in the failure metric.
+type issuanceError struct {
+	reason string
+	err    error
+}
+
+func newIssuanceError(reason string, err error) error {
+	return &issuanceError{reason: reason, err: err}
+}
+
+func (e *issuanceError) Error() string {
+	return e.err.Error()
+}
+
+func (e *issuanceError) Unwrap() error {
+	return e.err
+}
+
+func peerAddress(p *peer.Peer) string {
+	if p.Addr == nil {
+		return ""
+	}
+	return p.Addr.String()
+}
+
+// encodeKey encodes the mesh CA key as PEM. ECDSA keys are encoded in SEC 1 format, which older
+// Coordinators expect, other keys in PKCS #8 format.
+func encodeKey(key crypto.Signer) ([]byte, error) {
+	if ecKey, ok := key.(*ecdsa.PrivateKey); ok {
+		der, err := x509.MarshalECPrivateKey(ecKey)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling private key: %w", err)
+		}
+		return pem.EncodeToMemory(&pem.Block{
+			Type:  "EC PRIVATE KEY",
+			Bytes: der,
+		}), nil
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
 	if err != nil {
 		return nil, fmt.Errorf("marshaling private key: %w", err)
 	}
 	return pem.EncodeToMemory(&pem.Block{
-		Type:  "EC PRIVATE KEY",
+		Type:  "PRIVATE KEY",
 		Bytes: der,
 	}), nil
 }
@@ -5,39 +5,63 @@ package meshapi
 
 import (
 	"bytes"
+	"context"
+	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
 	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/asn1"
 	"encoding/json"
 	"encoding/pem"
 	"log/slog"
+	"math/big"
 	"net"
+	"net/url"
+	"slices"
 	"testing"
+	"time"
 
+	"github.com/edgelesssys/contrast/coordinator/internal/certregistry"
 	"github.com/edgelesssys/contrast/coordinator/internal/stateguard"
 	"github.com/edgelesssys/contrast/internal/ca"
+	"github.com/edgelesssys/contrast/internal/history/aferostore"
 	"github.com/edgelesssys/contrast/internal/manifest"
+	meshapiproto "github.com/edgelesssys/contrast/internal/meshapi"
+	"github.com/edgelesssys/contrast/internal/oid"
 	"github.com/edgelesssys/contrast/internal/seedengine"
 	"github.com/edgelesssys/contrast/internal/testkeys"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 func TestNewMeshCert(t *testing.T) {
 	require := require.New(t)
 	assert := assert.New(t)
 
-	m := &manifest.Manifest{}
+	m := &manifest.Manifest{SPIFFETrustDomain: "example.org"}
 	policyHash := sha256.Sum256(nil)
 	policyHashHex := manifest.NewHexString(policyHash[:])
 	m.Policies = map[manifest.HexString]manifest.PolicyEntry{
 		policyHashHex: {
 			SANs:             []string{"test"},
 			WorkloadSecretID: "test",
+			SPIFFEPath:       "/ns/default/sa/test",
+			MeshCertProfile: &manifest.MeshCertProfile{
+				EmailAddresses: []string{"test@example.com"},
+				IPRanges:       []string{"1.2.3.0/24"},
+				Subject:        &manifest.CertSubject{Organization: []string{"Example"}},
+			},
 		},
 	}
 	key := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[0])
@@ -67,7 +91,7 @@ func TestNewMeshCert(t *testing.T) {
 		AuthInfo: info,
 	})
 
-	meshapi := New(slog.Default())
+	meshapi := New(slog.Default(), prometheus.NewRegistry(), nil, nil, nil)
 
 	resp, err := meshapi.NewMeshCert(ctx, nil)
 	require.NoError(err)
@@ -79,6 +103,17 @@ func TestNewMeshCert(t *testing.T) {
 	cert, intermediateCert := certChain[0], certChain[1]
 	require.Contains(cert.DNSNames, "test")
 	require.Contains(cert.IPAddresses, net.IP{1, 2, 3, 4})
+	assert.Equal([]string{"test@example.com"}, cert.EmailAddresses)
+	assert.Equal("test", cert.Subject.CommonName)
+	assert.Equal([]string{"Example"}, cert.Subject.Organization)
+	require.Len(cert.URIs, 1)
+	assert.Equal("spiffe://example.org/ns/default/sa/test", cert.URIs[0].String())
+	policyHashExt := slices.IndexFunc(cert.Extensions, func(ext pkix.Extension) bool { return ext.Id.Equal(oid.PolicyHashOID) })
+	require.NotEqual(-1, policyHashExt)
+	var gotPolicyHash []byte
+	_, err = asn1.Unmarshal(cert.Extensions[policyHashExt].Value, &gotPolicyHash)
+	require.NoError(err)
+	assert.Equal(policyHash[:], gotPolicyHash)
 	assert.False(cert.IsCA)
 	assert.True(intermediateCert.IsCA)
 	assert.Equal(cert.AuthorityKeyId, intermediateCert.SubjectKeyId)
@@ -94,6 +129,205 @@ func TestNewMeshCert(t *testing.T) {
 	assert.True(rootCerts[0].IsCA)
 	assert.Empty(rootCerts[0].AuthorityKeyId)
 	assert.Equal(intermediateCert.AuthorityKeyId, rootCerts[0].SubjectKeyId)
+
+	// A certificate that can't be recorded couldn't be revoked, so it isn't handed out.
+	readOnlyStore := aferostore.New(&afero.Afero{Fs: afero.NewReadOnlyFs(afero.NewMemMapFs())})
+	registry := certregistry.New(readOnlyStore, slog.Default())
+	_, err = New(slog.Default(), prometheus.NewRegistry(), nil, registry, nil).NewMeshCert(ctx, nil)
+	require.Error(err)
+}
+
+func TestNewMeshCertCSR(t *testing.T) {
+	ecdsaKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP256Keys[0])
+	rsaKey := testkeys.RSA(t)
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		key            crypto.Signer
+		template       x509.CertificateRequest
+		allowAny       bool
+		corrupt        bool
+		wantCode       codes.Code
+		wantReason     string
+		wantDNSNames   []string
+		wantIPs        []net.IP
+		wantURIs       int
+		wantEmails     []string
+		wantCommonName string
+	}{
+		"no SANs": {
+			key:            ecdsaKey,
+			wantDNSNames:   []string{"test", "*.test.svc"},
+			wantIPs:        []net.IP{{1, 2, 3, 4}},
+			wantURIs:       1,
+			wantEmails:     []string{"test@example.com"},
+			wantCommonName: "test",
+		},
+		"allowed SANs": {
+			key: ecdsaKey,
+			template: x509.CertificateRequest{
+				DNSNames:       []string{"legacy.test.svc"},
+				EmailAddresses: []string{"test@example.com"},
+			},
+			wantDNSNames:   []string{"legacy.test.svc"},
+			wantEmails:     []string{"test@example.com"},
+			wantCommonName: "legacy.test.svc",
+		},
+		"peer IP and SPIFFE ID": {
+			key: ed25519Key,
+			template: x509.CertificateRequest{
+				DNSNames:    []string{"test"},
+				IPAddresses: []net.IP{{1, 2, 3, 4}},
+				URIs:        []*url.URL{{Scheme: "spiffe", Host: "example.org", Path: "/ns/default/sa/test"}},
+			},
+			wantDNSNames:   []string{"test"},
+			wantIPs:        []net.IP{{1, 2, 3, 4}},
+			wantURIs:       1,
+			wantCommonName: "test",
+		},
+		"RSA key": {
+			key:            rsaKey,
+			template:       x509.CertificateRequest{DNSNames: []string{"test"}},
+			wantDNSNames:   []string{"test"},
+			wantCommonName: "test",
+		},
+		"disallowed DNS name": {
+			key:        ecdsaKey,
+			template:   x509.CertificateRequest{DNSNames: []string{"other"}},
+			wantCode:   codes.PermissionDenied,
+			wantReason: reasonForbiddenNames,
+		},
+		"wildcard matches only one label": {
+			key:        ecdsaKey,
+			template:   x509.CertificateRequest{DNSNames: []string{"a.b.test.svc"}},
+			wantCode:   codes.PermissionDenied,
+			wantReason: reasonForbiddenNames,
+		},
+		"wildcard DNS name": {
+			key:            ecdsaKey,
+			template:       x509.CertificateRequest{DNSNames: []string{"*.test.svc"}},
+			wantDNSNames:   []string{"*.test.svc"},
+			wantCommonName: "*.test.svc",
+		},
+		"wildcard DNS name not in manifest": {
+			key:        ecdsaKey,
+			template:   x509.CertificateRequest{DNSNames: []string{"*.other.test.svc"}},
+			wantCode:   codes.PermissionDenied,
+			wantReason: reasonForbiddenNames,
+		},
+		"bare wildcard matches only itself": {
+			key:        ecdsaKey,
+			template:   x509.CertificateRequest{DNSNames: []string{"test", "other.example.com"}},
+			allowAny:   true,
+			wantCode:   codes.PermissionDenied,
+			wantReason: reasonForbiddenNames,
+		},
+		"bare wildcard": {
+			key:            ecdsaKey,
+			template:       x509.CertificateRequest{DNSNames: []string{"test", "*"}},
+			allowAny:       true,
+			wantDNSNames:   []string{"test", "*"},
+			wantCommonName: "test",
+		},
+		"disallowed IP address": {
+			key:        ecdsaKey,
+			template:   x509.CertificateRequest{DNSNames: []string{"test"}, IPAddresses: []net.IP{{5, 6, 7, 8}}},
+			wantCode:   codes.PermissionDenied,
+			wantReason: reasonForbiddenNames,
+		},
+		"disallowed URI": {
+			key: ecdsaKey,
+			template: x509.CertificateRequest{
+				DNSNames: []string{"test"},
+				URIs:     []*url.URL{{Scheme: "spiffe", Host: "example.org", Path: "/ns/default/sa/admin"}},
+			},
+			wantCode:   codes.PermissionDenied,
+			wantReason: reasonForbiddenNames,
+		},
+		"disallowed email address": {
+			key:        ecdsaKey,
+			template:   x509.CertificateRequest{DNSNames: []string{"test"}, EmailAddresses: []string{"admin@example.com"}},
+			wantCode:   codes.PermissionDenied,
+			wantReason: reasonForbiddenNames,
+		},
+		"invalid signature": {
+			key:        ecdsaKey,
+			template:   x509.CertificateRequest{DNSNames: []string{"test"}},
+			corrupt:    true,
+			wantCode:   codes.InvalidArgument,
+			wantReason: reasonInvalidCSR,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+
+			sans := []string{"test", "*.test.svc"}
+			if tc.allowAny {
+				// contrast generate adds a bare wildcard to the SANs of every workload.
+				sans = append(sans, "*")
+			}
+			m := &manifest.Manifest{SPIFFETrustDomain: "example.org"}
+			policyHash := sha256.Sum256(nil)
+			m.Policies = map[manifest.HexString]manifest.PolicyEntry{
+				manifest.NewHexString(policyHash[:]): {
+					SANs:            sans,
+					SPIFFEPath:      "/ns/default/sa/test",
+					MeshCertProfile: &manifest.MeshCertProfile{EmailAddresses: []string{"test@example.com"}},
+				},
+			}
+			se, err := seedengine.New(make([]byte, 32), make([]byte, 32))
+			require.NoError(err)
+			ca, err := ca.New(se.RootCAKey(), testkeys.ECDSA(t))
+			require.NoError(err)
+
+			info := stateguard.AuthInfo{
+				TLSInfo: credentials.TLSInfo{
+					State: tls.ConnectionState{
+						PeerCertificates: []*x509.Certificate{{PublicKey: testkeys.ECDSA(t).Public(), PublicKeyAlgorithm: x509.ECDSA}},
+					},
+				},
+				Report: &fakeReport{hostData: policyHash[:]},
+				State:  stateguard.NewStateForTest(se, m, nil, ca),
+			}
+			ctx := peer.NewContext(t.Context(), &peer.Peer{
+				Addr:     &net.TCPAddr{IP: net.IP{1, 2, 3, 4}},
+				AuthInfo: info,
+			})
+
+			csrDER, err := x509.CreateCertificateRequest(rand.Reader, &tc.template, tc.key)
+			require.NoError(err)
+			if tc.corrupt {
+				csrDER[len(csrDER)-1] ^= 0xff
+			}
+			csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})
+
+			meshapi := New(slog.Default(), prometheus.NewRegistry(), nil, nil, nil)
+			resp, err := meshapi.NewMeshCert(ctx, &meshapiproto.NewMeshCertRequest{CSR: csrPEM})
+			if tc.wantCode != codes.OK {
+				require.Error(err)
+				assert.Equal(tc.wantCode, status.Code(err))
+				assert.Equal(1.0, testutil.ToFloat64(meshapi.metrics.issuanceFailures.WithLabelValues(tc.wantReason)))
+				return
+			}
+			require.NoError(err)
+			assert.Equal(1.0, testutil.ToFloat64(meshapi.metrics.certsIssued.WithLabelValues(manifest.NewHexString(policyHash[:]).String(), "0")))
+
+			certChain := certFromPEM(t, resp.CertChain)
+			require.Len(certChain, 2)
+			cert := certChain[0]
+			assert.Equal(tc.key.Public(), cert.PublicKey)
+			assert.Equal(tc.wantDNSNames, cert.DNSNames)
+			assert.Equal(tc.wantIPs, cert.IPAddresses)
+			assert.Len(cert.URIs, tc.wantURIs)
+			assert.Equal(tc.wantEmails, cert.EmailAddresses)
+			assert.Equal(tc.wantCommonName, cert.Subject.CommonName)
+			require.NoError(cert.CheckSignatureFrom(certChain[1]))
+		})
+	}
 }
 
 func TestRecover(t *testing.T) {
@@ -165,7 +399,7 @@ func TestRecover(t *testing.T) {
 				AuthInfo: info,
 			})
 
-			meshapi := New(slog.Default())
+			meshapi := New(slog.Default(), prometheus.NewRegistry(), nil, nil, nil)
 
 			resp, err := meshapi.Recover(ctx, nil)
 			if tc.wantErr {
@@ -182,6 +416,51 @@ func TestRecover(t *testing.T) {
 	}
 }
 
+func TestGetTrustBundle(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	mnfst := &manifest.Manifest{
+		Policies: map[manifest.HexString]manifest.PolicyEntry{
+			"0000000000000000000000000000000000000000000000000000000000000000": {},
+		},
+	}
+	se, err := seedengine.New(make([]byte, 32), make([]byte, 32))
+	require.NoError(err)
+	meshCA, err := ca.New(se.RootCAKey(), testkeys.ECDSA(t))
+	require.NoError(err)
+	signingKey := se.TransactionSigningKey()
+
+	registry := certregistry.New(aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()}), slog.Default())
+	require.NoError(registry.RecordIssued(signingKey, meshCA, certregistry.Certificate{SerialNumber: big.NewInt(1), NotAfter: time.Now().Add(time.Hour)}))
+	_, err = registry.Revoke(signingKey, meshCA, []*big.Int{big.NewInt(1)}, "", 0)
+	require.NoError(err)
+
+	server := New(slog.Default(), prometheus.NewRegistry(), nil, registry, nil)
+	newContext := func(hostData []byte) context.Context {
+		return peer.NewContext(t.Context(), &peer.Peer{
+			AuthInfo: stateguard.AuthInfo{
+				Report: &fakeReport{hostData: hostData},
+				State:  stateguard.NewStateForTest(se, mnfst, nil, meshCA),
+			},
+		})
+	}
+
+	resp, err := server.GetTrustBundle(newContext(bytes.Repeat([]byte{0}, 32)), nil)
+	require.NoError(err)
+	assert.Equal(meshCA.GetMeshCACert(), resp.MeshCACert)
+	assert.Equal(meshCA.GetMeshCACert(), resp.TrustBundle)
+	block, _ := pem.Decode(resp.CRL)
+	require.NotNil(block)
+	crl, err := x509.ParseRevocationList(block.Bytes)
+	require.NoError(err)
+	require.Len(crl.RevokedCertificateEntries, 1)
+	assert.Equal(big.NewInt(1), crl.RevokedCertificateEntries[0].SerialNumber)
+
+	_, err = server.GetTrustBundle(newContext(bytes.Repeat([]byte{1}, 32)), nil)
+	require.Equal(codes.PermissionDenied, status.Code(err))
+}
+
 type fakeReport struct {
 	extensions []pkix.Extension
 	hostData   []byte
@@ -0,0 +1,224 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+// Package notifier delivers signed notifications about Coordinator state transitions to webhook
+// endpoints.
+//
+// Notifications are JSON-encoded Events, sent with HTTP POST. They're signed with the transaction
+// signing key derived from the secret seed, and the base64-encoded ASN.1 ECDSA signature is sent in
+// the SignatureHeader. Receivers verify notifications with Verify.
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/edgelesssys/contrast/internal/history"
+	"k8s.io/utils/clock"
+)
+
+const (
+	// SignatureHeader is the HTTP header carrying the base64-encoded signature of a notification.
+	SignatureHeader = "Contrast-Signature"
+
+	// signatureDomain is prepended to the notification body before signing. The transaction
+	// signing key also signs the history, and a notification signature must never be valid for it.
+	signatureDomain = "contrast-notification"
+
+	// queueSize is the number of notifications that can wait for delivery.
+	queueSize = 64
+	// maxAttempts is the number of delivery attempts per notification and endpoint.
+	maxAttempts = 3
+	// retryInterval is the time between two delivery attempts.
+	retryInterval = 5 * time.Second
+	// requestTimeout limits a single delivery attempt.
+	requestTimeout = 10 * time.Second
+)
+
+// EventType identifies the kind of state transition a notification reports.
+type EventType string
+
+const (
+	// EventManifestChanged is sent by the Coordinator that advanced the state to a new manifest.
+	EventManifestChanged EventType = "manifest.changed"
+	// EventStateStale is sent by a Coordinator that noticed a manifest change made by another
+	// Coordinator, and needs to be recovered.
+	EventStateStale EventType = "state.stale"
+	// EventRecovered is sent when a Coordinator completed recovery.
+	EventRecovered EventType = "recovery.completed"
+)
+
+// Event is the body of a notification.
+type Event struct {
+	// Type identifies the kind of state transition.
+	Type EventType `json:"type"`
+	// Time is the time the notification was created at.
+	Time time.Time `json:"time"`
+	// Coordinator is the host name of the Coordinator instance that sent the notification.
+	Coordinator string `json:"coordinator"`
+	// TransitionHash is the hex-encoded hash of the latest transition after the state transition.
+	TransitionHash string `json:"transition_hash"`
+	// PreviousTransitionHash is the hex-encoded hash of the latest transition before the state
+	// transition. It's empty if there was none.
+	PreviousTransitionHash string `json:"previous_transition_hash,omitempty"`
+	// ManifestHash is the hex-encoded hash of the manifest of the latest transition, if known.
+	ManifestHash string `json:"manifest_hash,omitempty"`
+	// Generation is the generation of the latest transition, if known.
+	Generation int `json:"generation,omitempty"`
+}
+
+// Notifier sends notifications to a fixed set of webhook endpoints. A nil Notifier discards all
+// notifications.
+type Notifier struct {
+	endpoints []string
+	hostname  string
+	client    *http.Client
+	clock     clock.Clock
+	logger    *slog.Logger
+
+	queue chan notification
+}
+
+type notification struct {
+	eventType EventType
+	body      []byte
+	signature []byte
+}
+
+// New creates a Notifier for the given endpoints, which must be absolute HTTP or HTTPS URLs.
+func New(endpoints []string, logger *slog.Logger) (*Notifier, error) {
+	for _, endpoint := range endpoints {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("parsing webhook endpoint: %w", err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("webhook endpoint %q is not an absolute HTTP(S) URL", endpoint)
+		}
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("getting host name: %w", err)
+	}
+	return &Notifier{
+		endpoints: endpoints,
+		hostname:  hostname,
+		client:    &http.Client{Timeout: requestTimeout},
+		clock:     clock.RealClock{},
+		logger:    logger,
+		queue:     make(chan notification, queueSize),
+	}, nil
+}
+
+// Notify signs the event with key and queues it for delivery to all endpoints.
+//
+// Time and Coordinator of the event are set by the Notifier. Notify doesn't block: if the queue
+// is full, the notification is dropped and an error is logged.
+func (n *Notifier) Notify(event Event, key *ecdsa.PrivateKey) {
+	if n == nil {
+		return
+	}
+	event.Time = n.clock.Now().UTC()
+	event.Coordinator = n.hostname
+	body, err := json.Marshal(event)
+	if err != nil {
+		n.logger.Error("Marshaling notification failed", "type", event.Type, "err", err)
+		return
+	}
+	digest := digest(body)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		n.logger.Error("Signing notification failed", "type", event.Type, "err", err)
+		return
+	}
+	select {
+	case n.queue <- notification{eventType: event.Type, body: body, signature: signature}:
+	default:
+		n.logger.Error("Notification queue is full, dropping notification", "type", event.Type)
+	}
+}
+
+// Run delivers queued notifications until the context expires.
+//
+// Each notification is delivered to the endpoints one after another, retrying failed deliveries.
+// Notifications that can't be delivered are dropped.
+func (n *Notifier) Run(ctx context.Context) error {
+	for {
+		select {
+		case notification := <-n.queue:
+			for _, endpoint := range n.endpoints {
+				n.deliver(ctx, endpoint, notification)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (n *Notifier) deliver(ctx context.Context, endpoint string, notification notification) {
+	logger := n.logger.With("type", notification.eventType, "endpoint", endpoint)
+	for attempt := 1; ; attempt++ {
+		err := n.post(ctx, endpoint, notification)
+		if err == nil {
+			logger.Debug("Delivered notification")
+			return
+		}
+		if attempt == maxAttempts {
+			logger.Error("Delivering notification failed, dropping it", "attempts", attempt, "err", err)
+			return
+		}
+		logger.Warn("Delivering notification failed, retrying", "attempt", attempt, "err", err)
+		select {
+		case <-n.clock.After(retryInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (n *Notifier) post(ctx context.Context, endpoint string, notification notification) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(notification.body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, base64.StdEncoding.EncodeToString(notification.signature))
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("endpoint responded with status %s", resp.Status)
+	}
+	return nil
+}
+
+// Verify checks the base64-encoded signature of a notification body, as sent in the
+// SignatureHeader, against the Coordinator's transaction signing public key.
+func Verify(body []byte, signature string, key *ecdsa.PublicKey) error {
+	rawSignature, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("decoding notification signature: %w", err)
+	}
+	digest := digest(body)
+	if !ecdsa.VerifyASN1(key, digest[:], rawSignature) {
+		return errors.New("notification signature is invalid")
+	}
+	return nil
+}
+
+func digest(body []byte) [history.HashSize]byte {
+	return history.Digest(append([]byte(signatureDomain), body...))
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package notifier
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/edgelesssys/contrast/internal/testkeys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func TestNew(t *testing.T) {
+	testCases := map[string]struct {
+		endpoints []string
+		wantErr   bool
+	}{
+		"http and https": {
+			endpoints: []string{"http://example.com/hook", "https://example.com:8443/hook"},
+		},
+		"no endpoints": {},
+		"relative url": {
+			endpoints: []string{"/hook"},
+			wantErr:   true,
+		},
+		"unsupported scheme": {
+			endpoints: []string{"ftp://example.com/hook"},
+			wantErr:   true,
+		},
+		"invalid url": {
+			endpoints: []string{"http://example.com/%zz"},
+			wantErr:   true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := New(tc.endpoints, slog.Default())
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNotifier(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	key := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[0])
+	otherKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[1])
+
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	var failures atomic.Int32
+	failures.Store(1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failures.Add(-1) >= 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(err)
+		received <- r
+		bodies <- body
+	}))
+	defer server.Close()
+
+	n, err := New([]string{server.URL}, slog.Default())
+	require.NoError(err)
+	clock := testingclock.NewFakeClock(time.Now())
+	n.clock = clock
+	n.hostname = "coordinator-0"
+
+	go func() { _ = n.Run(t.Context()) }()
+
+	n.Notify(Event{Type: EventManifestChanged, TransitionHash: "abcd", Generation: 2}, key)
+
+	// The first delivery fails, so the notifier waits before retrying.
+	require.Eventually(clock.HasWaiters, time.Second, time.Millisecond)
+	clock.Step(retryInterval)
+
+	var r *http.Request
+	select {
+	case r = <-received:
+	case <-time.After(5 * time.Second):
+		require.FailNow("notification wasn't delivered")
+	}
+	body := <-bodies
+	assert.Equal(http.MethodPost, r.Method)
+	assert.Equal("application/json", r.Header.Get("Content-Type"))
+
+	var event Event
+	require.NoError(json.Unmarshal(body, &event))
+	assert.Equal(EventManifestChanged, event.Type)
+	assert.Equal("abcd", event.TransitionHash)
+	assert.Equal(2, event.Generation)
+	assert.Equal("coordinator-0", event.Coordinator)
+	assert.False(event.Time.IsZero())
+
+	signature := r.Header.Get(SignatureHeader)
+	require.NoError(Verify(body, signature, &key.PublicKey))
+	require.Error(Verify(body, signature, &otherKey.PublicKey))
+	require.Error(Verify(append(body, ' '), signature, &key.PublicKey))
+	require.Error(Verify(body, "not base64", &key.PublicKey))
+}
+
+func TestNotify_QueueFull(t *testing.T) {
+	key := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[0])
+	n, err := New([]string{"http://example.com/hook"}, slog.Default())
+	require.NoError(t, err)
+
+	// Notify must not block, even if nobody delivers the notifications.
+	for range queueSize + 1 {
+		n.Notify(Event{Type: EventStateStale}, key)
+	}
+	assert.Len(t, n.queue, queueSize)
+
+	// A nil notifier discards notifications.
+	var nilNotifier *Notifier
+	nilNotifier.Notify(Event{Type: EventStateStale}, key)
+}
@@ -5,7 +5,7 @@ package peerrecovery
 
 import (
 	"context"
-	"crypto/ecdsa"
+	"crypto"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
@@ -134,7 +134,7 @@ type authorizer struct {
 
 // AuthorizeByManifest calls meshapi.Recover on a peer coordinator given as context value and
 // verifies that the peer is an authorized Coordinator according to the manifest.
-func (a *authorizer) AuthorizeByManifest(ctx context.Context, mnfst *manifest.Manifest) (*seedengine.SeedEngine, *ecdsa.PrivateKey, error) {
+func (a *authorizer) AuthorizeByManifest(ctx context.Context, mnfst *manifest.Manifest) (*seedengine.SeedEngine, crypto.Signer, error) {
 	validator, err := mnfst.CoordinatorValidator(a.logger, a.httpsGetter)
 	if err != nil {
 		return nil, nil, fmt.Errorf("generating validators: %w", err)
@@ -160,8 +160,7 @@ func (a *authorizer) AuthorizeByManifest(ctx context.Context, mnfst *manifest.Ma
 		return nil, nil, fmt.Errorf("creating seed engine: %w", err)
 	}
 
-	block, _ := pem.Decode(resp.MeshCAKey)
-	meshCAKey, err := x509.ParseECPrivateKey(block.Bytes)
+	meshCAKey, err := parseMeshCAKey(resp.MeshCAKey)
 	if err != nil {
 		return nil, nil, fmt.Errorf("parsing mesh CA key: %w", err)
 	}
@@ -169,6 +168,30 @@ func (a *authorizer) AuthorizeByManifest(ctx context.Context, mnfst *manifest.Ma
 	return se, meshCAKey, nil
 }
 
+// parseMeshCAKey parses a PEM-encoded mesh CA key in SEC 1 or PKCS #8 format.
+func parseMeshCAKey(keyPEM []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported key type %T", key)
+		}
+		return signer, nil
+	default:
+		return nil, fmt.Errorf("unexpected PEM block type %q", block.Type)
+	}
+}
+
 func periodically(ctx context.Context, clock clock.WithTicker, interval time.Duration, f func(context.Context)) error {
 	t := clock.NewTicker(interval)
 	defer t.Stop()
@@ -5,6 +5,7 @@ package peerrecovery
 
 import (
 	"context"
+	"crypto"
 	"crypto/sha256"
 	"crypto/x509"
 	"encoding/json"
@@ -282,6 +283,50 @@ func newManifest(t *testing.T) (*manifest.Manifest, []byte) {
 	return mnfst, mnfstBytes
 }
 
+func TestParseMeshCAKey(t *testing.T) {
+	ecdsaKey := testkeys.ECDSA(t)
+	ecDER, err := x509.MarshalECPrivateKey(ecdsaKey)
+	require.NoError(t, err)
+	rsaKey := testkeys.RSA(t)
+	pkcs8DER, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		pem     []byte
+		wantKey crypto.Signer
+		wantErr bool
+	}{
+		"SEC 1": {
+			pem:     pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER}),
+			wantKey: ecdsaKey,
+		},
+		"PKCS #8": {
+			pem:     pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8DER}),
+			wantKey: rsaKey,
+		},
+		"no PEM": {
+			pem:     []byte("key"),
+			wantErr: true,
+		},
+		"unexpected type": {
+			pem:     pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkcs8DER}),
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			key, err := parseMeshCAKey(tc.pem)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tc.wantKey.Public().(interface{ Equal(crypto.PublicKey) bool }).Equal(key.Public()))
+		})
+	}
+}
+
 func TestMain(m *testing.M) {
 	goleak.VerifyTestMain(m)
 }
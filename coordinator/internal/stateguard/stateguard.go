@@ -23,16 +23,21 @@ package stateguard
 
 import (
 	"context"
-	"crypto/ecdsa"
+	"crypto"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"log/slog"
+	"os"
 	"slices"
 	"strings"
 	"sync/atomic"
 	"time"
 
+	"github.com/edgelesssys/contrast/coordinator/internal/notifier"
+	"github.com/edgelesssys/contrast/internal/auditlog"
 	"github.com/edgelesssys/contrast/internal/ca"
 	"github.com/edgelesssys/contrast/internal/history"
 	"github.com/edgelesssys/contrast/internal/manifest"
@@ -52,8 +57,22 @@ var (
 	// ErrConcurrentUpdate is returned by state-modifying operations if the input oldState is not
 	// the current state. This usually happens when a concurrent operation succeeded.
 	ErrConcurrentUpdate = errors.New("coordinator state was updated concurrently")
+
+	// ErrNoPendingUpdate is returned by CancelPendingUpdate if there is no matching pending update.
+	ErrNoPendingUpdate = errors.New("no matching manifest update is pending")
+
+	// ErrTransitionNotFound is returned by GetRollbackTarget if the transition is not part of the
+	// (retained) history of the current state.
+	ErrTransitionNotFound = errors.New("transition is not part of the manifest history")
+
+	// ErrSeedshareOwnersChanged is returned by GetRollbackTarget if the seedshare owners changed
+	// after the transition.
+	ErrSeedshareOwnersChanged = errors.New("seedshare owners changed after the transition")
 )
 
+// pendingCheckInterval is the interval at which ActivatePendingUpdates checks for due updates.
+const pendingCheckInterval = 10 * time.Second
+
 // Guard manages the manifest state of Contrast.
 type Guard struct {
 	// state holds all required configuration to serve requests from userapi.
@@ -65,6 +84,15 @@ type Guard struct {
 	logger  *slog.Logger
 	metrics metrics
 
+	// historyRetention is the number of transitions kept when pruning the history. Pruning is
+	// disabled if it's zero.
+	historyRetention int
+
+	// audit records state changes the Guard makes on its own. It may be nil.
+	audit *auditlog.Log
+	// notifier sends notifications about state transitions. It may be nil.
+	notifier *notifier.Notifier
+
 	clock clock.Clock
 }
 
@@ -81,7 +109,7 @@ func New(hist *history.History, reg *prometheus.Registry, log *slog.Logger) *Gua
 	})
 	manifestGeneration.Set(0)
 
-	return &Guard{
+	g := &Guard{
 		hist:   hist,
 		logger: log.WithGroup("stateguard"),
 		metrics: metrics{
@@ -89,6 +117,43 @@ func New(hist *history.History, reg *prometheus.Registry, log *slog.Logger) *Gua
 		},
 		clock: clock.RealClock{},
 	}
+	reg.MustRegister(&intermCAExpiryCollector{
+		guard: g,
+		desc: prometheus.NewDesc(
+			"contrast_coordinator_intermediate_ca_expiry_seconds",
+			"Seconds until the intermediate CA certificate of the current manifest expires.",
+			nil, nil,
+		),
+	})
+	return g
+}
+
+// Clock returns the clock the Guard activates pending updates with.
+func (g *Guard) Clock() clock.PassiveClock {
+	return g.clock
+}
+
+// SetHistoryRetention enables pruning of the history after each manifest update, keeping the given
+// number of most recent transitions and everything they reference.
+//
+// This function must be called before the Guard is used.
+func (g *Guard) SetHistoryRetention(transitions int) {
+	g.historyRetention = transitions
+}
+
+// SetAuditLog sets the audit log that records the activation of scheduled manifest updates.
+//
+// This function must be called before the Guard is used.
+func (g *Guard) SetAuditLog(audit *auditlog.Log) {
+	g.audit = audit
+}
+
+// SetNotifier sets the notifier that's informed about manifest changes, stale states and completed
+// recoveries.
+//
+// This function must be called before the Guard is used.
+func (g *Guard) SetNotifier(n *notifier.Notifier) {
+	g.notifier = n
 }
 
 // WatchHistory monitors the history for manifest updates and sets the state stale if necessary.
@@ -125,7 +190,7 @@ func (g *Guard) WatchHistory(ctx context.Context) error {
 					continue
 				}
 				stateInAncestors := false
-				walkErr := g.hist.WalkTransitions(state.latest.TransitionHash, func(h [32]byte, _ *history.Transition) error {
+				walkErr := g.hist.WalkTransitions(&state.seedEngine.TransactionSigningKey().PublicKey, state.latest.TransitionHash, func(h [32]byte, _ *history.Transition) error {
 					if h == t.TransitionHash {
 						stateInAncestors = true
 					}
@@ -142,6 +207,11 @@ func (g *Guard) WatchHistory(ctx context.Context) error {
 					"from-transition", manifest.NewHexString(state.latest.TransitionHash[:]),
 					"to-transition", manifest.NewHexString(t.TransitionHash[:]))
 				state.stale.Store(true)
+				g.notifier.Notify(notifier.Event{
+					Type:                   notifier.EventStateStale,
+					TransitionHash:         manifest.NewHexString(t.TransitionHash[:]).String(),
+					PreviousTransitionHash: manifest.NewHexString(state.latest.TransitionHash[:]).String(),
+				}, state.seedEngine.TransactionSigningKey())
 			case <-ctx.Done():
 				return ctx.Err()
 			}
@@ -203,18 +273,28 @@ func (g *Guard) ResetState(ctx context.Context, oldState *State, authorizer Secr
 		return nil, fmt.Errorf("%w: transition changed from %x to %x", ErrConcurrentUpdate, insecureLatest.TransitionHash, latest.TransitionHash)
 	}
 
-	ca, err := ca.New(se.RootCAKey(), meshCAKey)
+	rootCAKey, err := se.DeriveRootCAKey(mnfst.KeyAlgorithm)
+	if err != nil {
+		return nil, fmt.Errorf("deriving root CA key: %w", err)
+	}
+	ca, err := ca.New(rootCAKey, meshCAKey)
 	if err != nil {
 		return nil, fmt.Errorf("creating CA: %w", err)
 	}
 	var generation int
-	err = g.hist.WalkTransitions(latest.TransitionHash, func(_ [history.HashSize]byte, _ *history.Transition) error {
+	err = g.hist.WalkTransitions(&se.TransactionSigningKey().PublicKey, latest.TransitionHash, func(_ [history.HashSize]byte, _ *history.Transition) error {
 		generation++
 		return nil
 	})
 	if err != nil {
 		return nil, fmt.Errorf("walking transitions: %w", err)
 	}
+	checkpoint, err := g.hist.GetCheckpoint(&se.TransactionSigningKey().PublicKey)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("getting history checkpoint: %w", err)
+	} else if err == nil {
+		generation += int(checkpoint.Generation)
+	}
 	nextState := &State{
 		seedEngine:    se,
 		latest:        latest,
@@ -227,6 +307,7 @@ func (g *Guard) ResetState(ctx context.Context, oldState *State, authorizer Secr
 		return nil, ErrConcurrentUpdate
 	}
 	g.metrics.manifestGeneration.Set(float64(generation))
+	g.notifyTransition(notifier.EventRecovered, nil, nextState)
 	return nextState, nil
 }
 
@@ -235,7 +316,7 @@ type SecretSourceAuthorizer interface {
 	// AuthorizeByManifest obtains a SeedEngine and a mesh CA key and verifies their source
 	// according to the Manifest. Secrets must only be held by other Coordinators (identified by
 	// their Role) and seed share owners.
-	AuthorizeByManifest(context.Context, *manifest.Manifest) (*seedengine.SeedEngine, *ecdsa.PrivateKey, error)
+	AuthorizeByManifest(context.Context, *manifest.Manifest) (*seedengine.SeedEngine, crypto.Signer, error)
 }
 
 // GetState returns the current state.
@@ -266,16 +347,208 @@ func (g *Guard) GetState(context.Context) (*State, error) {
 //
 // The oldState argument needs to be a state obtained from GetState. If the Coordinator state
 // changes between the calls to GetState and UpdateState, an ErrConcurrentUpdate is returned.
-func (g *Guard) UpdateState(_ context.Context, oldState *State, se *seedengine.SeedEngine, manifestBytes []byte, policies [][]byte) (*State, error) {
+// If the update was authorized by a detached workload owner signature, it's persisted alongside
+// the transition so that clients can audit the history.
+func (g *Guard) UpdateState(_ context.Context, oldState *State, se *seedengine.SeedEngine, manifestBytes []byte, policies [][]byte, signature []byte) (*State, error) {
+	transitionHash, err := g.storeTransition(oldState, manifestBytes, policies, signature)
+	if err != nil {
+		return nil, err
+	}
+	return g.advanceState(oldState, se, transitionHash, manifestBytes)
+}
+
+// ScheduleState persists a transition to the given manifest that becomes active at
+// activationTime, replacing any pending update.
+//
+// The transition is applied to oldState, which must not be nil. If the Coordinator state changes
+// before the activation time, the pending update is discarded. Pending updates are activated by
+// ActivatePendingUpdates.
+func (g *Guard) ScheduleState(_ context.Context, oldState *State, manifestBytes []byte, policies [][]byte, signature []byte, activationTime time.Time) (*PendingUpdate, error) {
+	if oldState == nil {
+		return nil, errors.New("the initial manifest can't be scheduled")
+	}
+	signingKey := oldState.seedEngine.TransactionSigningKey()
+	oldPending, err := g.hist.GetPending(&signingKey.PublicKey)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("getting pending transition: %w", err)
+	}
+
+	transitionHash, err := g.storeTransition(oldState, manifestBytes, policies, signature)
+	if err != nil {
+		return nil, err
+	}
+	if g.state.Load() != oldState {
+		return nil, ErrConcurrentUpdate
+	}
+	pending := &history.PendingTransition{
+		TransitionHash: transitionHash,
+		ActivationTime: activationTime.Truncate(time.Second),
+	}
+	if err := g.hist.SetPending(oldPending, pending, signingKey); err != nil {
+		if strings.Contains(err.Error(), "has changed since last read") {
+			return nil, fmt.Errorf("%w: %w", ErrConcurrentUpdate, err)
+		}
+		return nil, fmt.Errorf("setting pending transition: %w", err)
+	}
+	if oldPending != nil {
+		g.logger.Info("Replaced pending manifest update", "transition", manifest.NewHexString(oldPending.TransitionHash[:]))
+	}
+	g.logger.Info("Scheduled manifest update", "transition", manifest.NewHexString(transitionHash[:]), "activationTime", pending.ActivationTime)
+	return &PendingUpdate{
+		TransitionHash: transitionHash,
+		ActivationTime: pending.ActivationTime,
+		ManifestBytes:  manifestBytes,
+	}, nil
+}
+
+// GetPendingUpdate returns the manifest update that's scheduled for the current state, or nil if
+// there is none.
+func (g *Guard) GetPendingUpdate(ctx context.Context) (*PendingUpdate, error) {
+	state, err := g.GetState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pending, transition, err := g.getPending(state)
+	if err != nil || pending == nil {
+		return nil, err
+	}
+	if transition.PreviousTransitionHash != state.latest.TransitionHash {
+		// The pending update was superseded and will be discarded.
+		return nil, nil
+	}
+	manifestBytes, err := g.hist.GetManifest(transition.ManifestHash)
+	if err != nil {
+		return nil, fmt.Errorf("getting pending manifest: %w", err)
+	}
+	return &PendingUpdate{
+		TransitionHash: pending.TransitionHash,
+		ActivationTime: pending.ActivationTime,
+		ManifestBytes:  manifestBytes,
+	}, nil
+}
+
+// CancelPendingUpdate cancels the pending manifest update with the given transition hash.
+//
+// If no update with this transition hash is pending, ErrNoPendingUpdate is returned.
+func (g *Guard) CancelPendingUpdate(ctx context.Context, transitionHash [history.HashSize]byte) error {
+	state, err := g.GetState(ctx)
+	if err != nil {
+		return err
+	}
+	pending, _, err := g.getPending(state)
+	if err != nil {
+		return err
+	}
+	if pending == nil || pending.TransitionHash != transitionHash {
+		return ErrNoPendingUpdate
+	}
+	if err := g.hist.SetPending(pending, nil, state.seedEngine.TransactionSigningKey()); err != nil {
+		if strings.Contains(err.Error(), "has changed since last read") {
+			return fmt.Errorf("%w: %w", ErrConcurrentUpdate, err)
+		}
+		return fmt.Errorf("removing pending transition: %w", err)
+	}
+	g.logger.Info("Cancelled pending manifest update", "transition", manifest.NewHexString(transitionHash[:]))
+	return nil
+}
+
+// ActivatePendingUpdates periodically checks whether a pending manifest update is due and
+// activates it.
+//
+// This function blocks and keeps checking until the context expires.
+func (g *Guard) ActivatePendingUpdates(ctx context.Context) error {
+	for {
+		select {
+		case <-g.clock.After(pendingCheckInterval):
+			if err := g.activatePendingUpdate(); err != nil {
+				g.logger.Warn("Activating pending manifest update failed", "error", err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// activatePendingUpdate activates the pending update if it's due. Pending updates that were
+// superseded by another update are discarded.
+func (g *Guard) activatePendingUpdate() error {
+	state := g.state.Load()
+	if state == nil || state.stale.Load() {
+		return nil
+	}
+	pending, transition, err := g.getPending(state)
+	if err != nil || pending == nil {
+		return err
+	}
+	if g.clock.Now().Before(pending.ActivationTime) {
+		return nil
+	}
+
+	// Remove the pending transition before activating it, so that it's activated at most once and
+	// never after a concurrent cancellation.
+	signingKey := state.seedEngine.TransactionSigningKey()
+	if err := g.hist.SetPending(pending, nil, signingKey); err != nil {
+		return fmt.Errorf("removing pending transition: %w", err)
+	}
+	if transition.PreviousTransitionHash != state.latest.TransitionHash {
+		g.logger.Warn("Discarded pending manifest update, because the manifest was updated in the meantime",
+			"transition", manifest.NewHexString(pending.TransitionHash[:]))
+		return nil
+	}
+
+	manifestBytes, err := g.hist.GetManifest(transition.ManifestHash)
+	if err == nil {
+		_, err = g.advanceState(state, state.seedEngine, pending.TransitionHash, manifestBytes)
+	}
+	if err != nil {
+		// Restore the pending transition, so that the activation is retried. If the state changed in
+		// the meantime, the restored transition is discarded by the next check. Restoring fails if
+		// another update was scheduled in the meantime, which then takes precedence.
+		if restoreErr := g.hist.SetPending(nil, pending, signingKey); restoreErr != nil {
+			g.logger.Warn("Restoring pending manifest update failed", "transition", manifest.NewHexString(pending.TransitionHash[:]), "error", restoreErr)
+		}
+		return fmt.Errorf("activating transition %x: %w", pending.TransitionHash, err)
+	}
+	g.logger.Info("Activated pending manifest update", "transition", manifest.NewHexString(pending.TransitionHash[:]))
+	manifestHash := history.Digest(manifestBytes)
+	g.audit.Record(auditlog.Event{
+		Type:  auditlog.EventManifestActivated,
+		Actor: "coordinator",
+		Details: map[string]string{
+			"transition": manifest.NewHexString(pending.TransitionHash[:]).String(),
+			"manifest":   manifest.NewHexString(manifestHash[:]).String(),
+		},
+	})
+	return nil
+}
+
+// getPending returns the verified pending transition and the transition it refers to, or nil if
+// no transition is pending.
+func (g *Guard) getPending(state *State) (*history.PendingTransition, *history.Transition, error) {
+	pending, err := g.hist.GetPending(&state.seedEngine.TransactionSigningKey().PublicKey)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("getting pending transition: %w", err)
+	}
+	transition, err := g.hist.GetTransition(pending.TransitionHash)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting pending transition %x: %w", pending.TransitionHash, err)
+	}
+	return pending, transition, nil
+}
+
+// storeTransition persists the manifest, its policies and a transition from oldState to it.
+func (g *Guard) storeTransition(oldState *State, manifestBytes []byte, policies [][]byte, signature []byte) ([history.HashSize]byte, error) {
 	var mnfst manifest.Manifest
 	if err := json.Unmarshal(manifestBytes, &mnfst); err != nil {
-		return nil, fmt.Errorf("unmarshaling manifest: %w", err)
+		return [history.HashSize]byte{}, fmt.Errorf("unmarshaling manifest: %w", err)
 	}
 	policyMap := make(map[[history.HashSize]byte][]byte)
 	for _, policy := range policies {
 		policyHash, err := g.hist.SetPolicy(policy)
 		if err != nil {
-			return nil, fmt.Errorf("setting policy: %w", err)
+			return [history.HashSize]byte{}, fmt.Errorf("setting policy: %w", err)
 		}
 		policyMap[policyHash] = policy
 	}
@@ -284,30 +557,46 @@ func (g *Guard) UpdateState(_ context.Context, oldState *State, se *seedengine.S
 		var ref [history.HashSize]byte
 		refSlice, err := hexRef.Bytes()
 		if err != nil {
-			return nil, fmt.Errorf("invalid policy hash: %w", err)
+			return [history.HashSize]byte{}, fmt.Errorf("invalid policy hash: %w", err)
 		}
 		copy(ref[:], refSlice)
 		if _, ok := policyMap[ref]; !ok {
-			return nil, fmt.Errorf("no policy provided for hash %q", hexRef)
+			return [history.HashSize]byte{}, fmt.Errorf("no policy provided for hash %q", hexRef)
 		}
 	}
 	manifestHash, err := g.hist.SetManifest(manifestBytes)
 	if err != nil {
-		return nil, fmt.Errorf("storing manifest: %w", err)
+		return [history.HashSize]byte{}, fmt.Errorf("storing manifest: %w", err)
 	}
 	transition := &history.Transition{
 		ManifestHash: manifestHash,
 	}
-	var oldLatest *history.LatestTransition
-	var oldGeneration int
 	if oldState != nil {
 		transition.PreviousTransitionHash = oldState.latest.TransitionHash
-		oldLatest = oldState.latest
-		oldGeneration = oldState.generation
 	}
 	transitionHash, err := g.hist.SetTransition(transition)
 	if err != nil {
-		return nil, fmt.Errorf("storing transition: %w", err)
+		return [history.HashSize]byte{}, fmt.Errorf("storing transition: %w", err)
+	}
+	if len(signature) > 0 {
+		if err := g.hist.SetTransitionSignature(transitionHash, signature); err != nil {
+			return [history.HashSize]byte{}, fmt.Errorf("storing transition signature: %w", err)
+		}
+	}
+	return transitionHash, nil
+}
+
+// advanceState makes the stored transition from oldState the latest transition.
+func (g *Guard) advanceState(oldState *State, se *seedengine.SeedEngine, transitionHash [history.HashSize]byte, manifestBytes []byte) (*State, error) {
+	var mnfst manifest.Manifest
+	if err := json.Unmarshal(manifestBytes, &mnfst); err != nil {
+		return nil, fmt.Errorf("unmarshaling manifest: %w", err)
+	}
+	var oldLatest *history.LatestTransition
+	var oldGeneration int
+	if oldState != nil {
+		oldLatest = oldState.latest
+		oldGeneration = oldState.generation
 	}
 	latest := &history.LatestTransition{
 		TransitionHash: transitionHash,
@@ -320,12 +609,16 @@ func (g *Guard) UpdateState(_ context.Context, oldState *State, se *seedengine.S
 		return nil, fmt.Errorf("updating latest transition: %w", err)
 	}
 
-	meshCAKey, err := se.GenerateMeshCAKey()
+	meshCAKey, err := se.GenerateMeshCAKey(mnfst.KeyAlgorithm)
 	if err != nil {
 		return nil, fmt.Errorf("generating mesh CA key: %w", err)
 	}
+	rootCAKey, err := se.DeriveRootCAKey(mnfst.KeyAlgorithm)
+	if err != nil {
+		return nil, fmt.Errorf("deriving root CA key: %w", err)
+	}
 
-	ca, err := ca.New(se.RootCAKey(), meshCAKey)
+	ca, err := ca.New(rootCAKey, meshCAKey)
 	if err != nil {
 		return nil, fmt.Errorf("creating CA: %w", err)
 	}
@@ -348,9 +641,33 @@ func (g *Guard) UpdateState(_ context.Context, oldState *State, se *seedengine.S
 		return nextState, nil
 	}
 	g.metrics.manifestGeneration.Set(float64(nextState.generation))
+	g.notifyTransition(notifier.EventManifestChanged, oldLatest, nextState)
+
+	if g.historyRetention > 0 {
+		// The update already succeeded, so failing to prune the history is not fatal. An
+		// interrupted pruning is completed with the next update.
+		if _, err := g.hist.Prune(transitionHash, g.historyRetention, se.TransactionSigningKey()); err != nil {
+			g.logger.Warn("Pruning history failed", "error", err)
+		}
+	}
 	return nextState, nil
 }
 
+// notifyTransition sends a notification about the transition from previous to state.
+func (g *Guard) notifyTransition(eventType notifier.EventType, previous *history.LatestTransition, state *State) {
+	manifestHash := history.Digest(state.manifestBytes)
+	event := notifier.Event{
+		Type:           eventType,
+		TransitionHash: manifest.NewHexString(state.latest.TransitionHash[:]).String(),
+		ManifestHash:   manifest.NewHexString(manifestHash[:]).String(),
+		Generation:     state.generation,
+	}
+	if previous != nil {
+		event.PreviousTransitionHash = manifest.NewHexString(previous.TransitionHash[:]).String()
+	}
+	g.notifier.Notify(event, state.seedEngine.TransactionSigningKey())
+}
+
 // GetHistory returns a list of manifests, the current manifest being last, and the policies
 // referenced in at least one of the manifests.
 func (g *Guard) GetHistory(ctx context.Context) ([][]byte, map[manifest.HexString][]byte, error) {
@@ -358,14 +675,37 @@ func (g *Guard) GetHistory(ctx context.Context) ([][]byte, map[manifest.HexStrin
 	if err != nil {
 		return nil, nil, err
 	}
-	var manifests [][]byte
-	policies := make(map[manifest.HexString][]byte)
-	err = g.hist.WalkTransitions(state.latest.TransitionHash, func(_ [history.HashSize]byte, t *history.Transition) error {
+	stateHistory, err := g.GetStateHistory(state)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stateHistory.Manifests, stateHistory.Policies, nil
+}
+
+// GetStateHistory returns the history leading to the given state.
+//
+// Unlike GetHistory, it doesn't read the current state, so that the result is consistent with a
+// state snapshot even if the manifest is updated concurrently.
+func (g *Guard) GetStateHistory(state *State) (*StateHistory, error) {
+	checkpoint, err := g.hist.GetCheckpoint(&state.seedEngine.TransactionSigningKey().PublicKey)
+	if errors.Is(err, os.ErrNotExist) {
+		checkpoint = nil
+	} else if err != nil {
+		return nil, fmt.Errorf("getting checkpoint: %w", err)
+	}
+
+	stateHistory := &StateHistory{
+		Policies:   make(map[manifest.HexString][]byte),
+		Checkpoint: checkpoint,
+	}
+	var oldestPrevious [history.HashSize]byte
+	err = g.hist.WalkTransitions(&state.seedEngine.TransactionSigningKey().PublicKey, state.latest.TransitionHash, func(_ [history.HashSize]byte, t *history.Transition) error {
+		oldestPrevious = t.PreviousTransitionHash
 		manifestBytes, err := g.hist.GetManifest(t.ManifestHash)
 		if err != nil {
 			return err
 		}
-		manifests = append(manifests, manifestBytes)
+		stateHistory.Manifests = append(stateHistory.Manifests, manifestBytes)
 
 		var mnfst manifest.Manifest
 		if err := json.Unmarshal(manifestBytes, &mnfst); err != nil {
@@ -373,7 +713,7 @@ func (g *Guard) GetHistory(ctx context.Context) ([][]byte, map[manifest.HexStrin
 		}
 
 		for policyHashHex := range mnfst.Policies {
-			if _, ok := policies[policyHashHex]; ok {
+			if _, ok := stateHistory.Policies[policyHashHex]; ok {
 				continue
 			}
 			policyHash, err := policyHashHex.Bytes()
@@ -386,17 +726,121 @@ func (g *Guard) GetHistory(ctx context.Context) ([][]byte, map[manifest.HexStrin
 			if err != nil {
 				return fmt.Errorf("getting policy: %w", err)
 			}
-			policies[policyHashHex] = policyBytes
+			stateHistory.Policies[policyHashHex] = policyBytes
 		}
 		return nil
 	})
 	if err != nil {
-		return nil, nil, fmt.Errorf("fetching manifests from history: %w", err)
+		return nil, fmt.Errorf("fetching manifests from history: %w", err)
+	}
+	// The history may have been pruned between reading the checkpoint and walking the transitions.
+	var boundary [history.HashSize]byte
+	if checkpoint != nil {
+		boundary = checkpoint.TransitionHash
+	}
+	if oldestPrevious != boundary {
+		return nil, errors.New("history was pruned concurrently")
 	}
 	// Traversing the history yields manifests in the wrong order, so reverse the slice.
-	slices.Reverse(manifests)
+	slices.Reverse(stateHistory.Manifests)
 
-	return manifests, policies, nil
+	return stateHistory, nil
+}
+
+// GetRollbackTarget returns the manifest and policies of the given transition in the history of
+// state, so that they can be re-activated.
+//
+// The transition must precede the latest transition, and all manifests since the transition
+// must have the same seedshare owners as the current manifest.
+func (g *Guard) GetRollbackTarget(_ context.Context, state *State, transitionHash [history.HashSize]byte) ([]byte, [][]byte, error) {
+	if transitionHash == state.latest.TransitionHash {
+		return nil, nil, fmt.Errorf("transition %x is the latest transition", transitionHash)
+	}
+	errFound := errors.New("found transition")
+	var manifestBytes []byte
+	var mnfst manifest.Manifest
+	err := g.hist.WalkTransitions(&state.seedEngine.TransactionSigningKey().PublicKey, state.latest.TransitionHash, func(h [history.HashSize]byte, t *history.Transition) error {
+		var err error
+		manifestBytes, err = g.hist.GetManifest(t.ManifestHash)
+		if err != nil {
+			return err
+		}
+		mnfst = manifest.Manifest{}
+		if err := json.Unmarshal(manifestBytes, &mnfst); err != nil {
+			return fmt.Errorf("unmarshaling manifest: %w", err)
+		}
+		if !slices.Equal(mnfst.SeedshareOwnerPubKeys, state.manifest.SeedshareOwnerPubKeys) {
+			return ErrSeedshareOwnersChanged
+		}
+		if h == transitionHash {
+			return errFound
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, errFound):
+	case err != nil:
+		return nil, nil, fmt.Errorf("searching transition %x: %w", transitionHash, err)
+	default:
+		return nil, nil, fmt.Errorf("searching transition %x: %w", transitionHash, ErrTransitionNotFound)
+	}
+
+	var policies [][]byte
+	for policyHashHex := range mnfst.Policies {
+		policyHash, err := policyHashHex.Bytes()
+		if err != nil {
+			return nil, nil, fmt.Errorf("converting hex to bytes: %w", err)
+		}
+		var policyHashFixed [history.HashSize]byte
+		copy(policyHashFixed[:], policyHash)
+		policy, err := g.hist.GetPolicy(policyHashFixed)
+		if err != nil {
+			return nil, nil, fmt.Errorf("getting policy: %w", err)
+		}
+		policies = append(policies, policy)
+	}
+	return manifestBytes, policies, nil
+}
+
+// GetTransitionSignatures returns the workload owner signatures for all transitions leading to
+// the current state, keyed by transition hash. Transitions without a detached signature are
+// omitted.
+func (g *Guard) GetTransitionSignatures(ctx context.Context) (map[[history.HashSize]byte][]byte, error) {
+	state, err := g.GetState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	signatures := make(map[[history.HashSize]byte][]byte)
+	err = g.hist.WalkTransitions(&state.seedEngine.TransactionSigningKey().PublicKey, state.latest.TransitionHash, func(transitionHash [history.HashSize]byte, _ *history.Transition) error {
+		signature, err := g.hist.GetTransitionSignature(transitionHash)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("getting signature: %w", err)
+		}
+		signatures[transitionHash] = signature
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fetching signatures from history: %w", err)
+	}
+	return signatures, nil
+}
+
+// GetHistoryCheckpoint returns the verified checkpoint of the history, which summarizes the
+// transitions that were removed by pruning. If the history was never pruned, nil is returned.
+func (g *Guard) GetHistoryCheckpoint(ctx context.Context) (*history.Checkpoint, error) {
+	state, err := g.GetState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	checkpoint, err := g.hist.GetCheckpoint(&state.seedEngine.TransactionSigningKey().PublicKey)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return checkpoint, nil
 }
 
 // State is a snapshot of the Coordinator's manifest history.
@@ -430,6 +874,15 @@ func NewStateForTest(seedEngine *seedengine.SeedEngine, manifest *manifest.Manif
 	}
 }
 
+// NewStateForTestWithLatest constructs a new State object with the given latest transition and
+// generation, see NewStateForTest.
+func NewStateForTestWithLatest(seedEngine *seedengine.SeedEngine, manifest *manifest.Manifest, manifestBytes []byte, ca *ca.CA, latest *history.LatestTransition, generation int) *State {
+	state := NewStateForTest(seedEngine, manifest, manifestBytes, ca)
+	state.latest = latest
+	state.generation = generation
+	return state
+}
+
 // SeedEngine returns the SeedEngine for this state.
 func (s *State) SeedEngine() *seedengine.SeedEngine {
 	return s.seedEngine
@@ -446,6 +899,11 @@ func (s *State) ManifestBytes() []byte {
 	return s.manifestBytes
 }
 
+// Generation returns the manifest generation of the state.
+func (s *State) Generation() int {
+	return s.generation
+}
+
 // CA returns the CA for this state.
 func (s *State) CA() *ca.CA {
 	return s.ca
@@ -455,3 +913,54 @@ func (s *State) CA() *ca.CA {
 func (s *State) LatestTransition() *history.LatestTransition {
 	return s.latest
 }
+
+// StateHistory is the part of the manifest history that leads to a state.
+type StateHistory struct {
+	// Manifests holds the manifests of the retained transitions, the manifest of the state being
+	// last.
+	Manifests [][]byte
+	// Policies holds the policies referenced in at least one of the manifests.
+	Policies map[manifest.HexString][]byte
+	// Checkpoint summarizes the transitions that were removed by pruning. It's nil if the history
+	// was never pruned.
+	Checkpoint *history.Checkpoint
+}
+
+// PendingUpdate is a manifest update that's scheduled for later activation.
+type PendingUpdate struct {
+	// TransitionHash is the hash of the transition that becomes the latest transition.
+	TransitionHash [history.HashSize]byte
+	// ActivationTime is the time at which the update is applied.
+	ActivationTime time.Time
+	// ManifestBytes is the raw manifest that becomes active.
+	ManifestBytes []byte
+}
+
+// intermCAExpiryCollector reports the time until the intermediate CA certificate of the current
+// state expires. It doesn't report anything while the Coordinator has no state.
+type intermCAExpiryCollector struct {
+	guard *Guard
+	desc  *prometheus.Desc
+}
+
+// Describe implements prometheus.Collector.
+func (c *intermCAExpiryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements prometheus.Collector.
+func (c *intermCAExpiryCollector) Collect(ch chan<- prometheus.Metric) {
+	state := c.guard.state.Load()
+	if state == nil || state.ca == nil {
+		return
+	}
+	block, _ := pem.Decode(state.ca.GetIntermCACert())
+	if block == nil {
+		return
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, cert.NotAfter.Sub(c.guard.clock.Now()).Seconds())
+}
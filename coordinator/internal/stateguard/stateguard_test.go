@@ -5,7 +5,7 @@ package stateguard
 
 import (
 	"context"
-	"crypto/ecdsa"
+	"crypto"
 	"crypto/sha256"
 	"crypto/x509"
 	"encoding/json"
@@ -55,7 +55,7 @@ func TestUpdateState(t *testing.T) {
 	mnfst, manifestBytes, policies := newManifest(t)
 
 	se := newSeedEngine(t)
-	updateState, err := g.UpdateState(ctx, emptyState, se, manifestBytes, policies)
+	updateState, err := g.UpdateState(ctx, emptyState, se, manifestBytes, policies, nil)
 	require.NoError(err)
 	require.NotNil(updateState)
 
@@ -86,6 +86,37 @@ func TestUpdateState(t *testing.T) {
 	}
 }
 
+func TestIntermCAExpiryMetric(t *testing.T) {
+	ctx := t.Context()
+	assert := assert.New(t)
+	require := require.New(t)
+	g, reg := newTestGuard(t)
+	clock := testingclock.NewFakeClock(time.Now())
+	g.clock = clock
+	const metricName = "contrast_coordinator_intermediate_ca_expiry_seconds"
+
+	// Nothing is reported without a state.
+	count, err := testutil.GatherAndCount(reg, metricName)
+	require.NoError(err)
+	assert.Zero(count)
+
+	_, manifestBytes, policies := newManifest(t)
+	state, err := g.UpdateState(ctx, nil, newSeedEngine(t), manifestBytes, policies, nil)
+	require.NoError(err)
+	block, _ := pem.Decode(state.CA().GetIntermCACert())
+	require.NotNil(block)
+	intermCert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(err)
+
+	clock.Step(time.Hour)
+	expected := fmt.Sprintf(`
+# HELP %[1]s Seconds until the intermediate CA certificate of the current manifest expires.
+# TYPE %[1]s gauge
+%[1]s %[2]v
+`, metricName, intermCert.NotAfter.Sub(clock.Now()).Seconds())
+	require.NoError(testutil.GatherAndCompare(reg, strings.NewReader(expected), metricName))
+}
+
 // TestTestConcurrentStateUpdate tests that parallel changes to the internal state pointer don't affect the
 // outcome of UpdateState calls.
 func TestTestConcurrentStateUpdate(t *testing.T) {
@@ -106,7 +137,7 @@ func TestTestConcurrentStateUpdate(t *testing.T) {
 	concurrentlyUpdatedState := &State{}
 	g.state.Store(concurrentlyUpdatedState)
 
-	updateState, err := g.UpdateState(ctx, emptyState, se, manifestBytes, policies)
+	updateState, err := g.UpdateState(ctx, emptyState, se, manifestBytes, policies, nil)
 	require.NoError(err)
 	require.NotNil(updateState)
 	assert.NotSame(concurrentlyUpdatedState, updateState, "UpdateState must return the state corresponding to its inputs")
@@ -135,7 +166,7 @@ func TestGetHistory(t *testing.T) {
 		policies = append(policies, nextPolicy)
 		manifestBytes, err := json.Marshal(mnfst)
 		require.NoError(err)
-		nextState, err := g.UpdateState(ctx, state, se, manifestBytes, policies)
+		nextState, err := g.UpdateState(ctx, state, se, manifestBytes, policies, nil)
 		require.NoError(err)
 		state = nextState
 	}
@@ -155,6 +186,263 @@ func TestGetHistory(t *testing.T) {
 	}
 }
 
+func TestHistoryRetention(t *testing.T) {
+	ctx := t.Context()
+	assert := assert.New(t)
+	require := require.New(t)
+	g, reg := newTestGuard(t)
+	g.SetHistoryRetention(2)
+
+	mnfst, _, policies := newManifest(t)
+	se := newSeedEngine(t)
+
+	checkpoint, err := g.GetHistoryCheckpoint(ctx)
+	require.ErrorIs(err, ErrNoState)
+	require.Nil(checkpoint)
+
+	numManifests := 5
+	var state *State
+	var manifests [][]byte
+	for i := range numManifests {
+		nextPolicy := []byte{byte(i)}
+		nextPolicyHash := sha256.Sum256(nextPolicy)
+		mnfst.Policies[manifest.NewHexString(nextPolicyHash[:])] = manifest.PolicyEntry{}
+		policies = append(policies, nextPolicy)
+		manifestBytes, err := json.Marshal(mnfst)
+		require.NoError(err)
+		manifests = append(manifests, manifestBytes)
+		state, err = g.UpdateState(ctx, state, se, manifestBytes, policies, nil)
+		require.NoError(err)
+
+		checkpoint, err := g.GetHistoryCheckpoint(ctx)
+		require.NoError(err)
+		if i < 2 {
+			assert.Nil(checkpoint, "iteration %d", i)
+		} else {
+			require.NotNil(checkpoint, "iteration %d", i)
+			assert.Equal(uint64(i-1), checkpoint.Generation, "iteration %d", i)
+		}
+	}
+	requireGauge(t, reg, numManifests)
+
+	// Only the retained manifests are returned, and they chain up to the latest transition.
+	gotManifests, _, err := g.GetHistory(ctx)
+	require.NoError(err)
+	assert.Equal(manifests[numManifests-2:], gotManifests)
+	checkpoint, err = g.GetHistoryCheckpoint(ctx)
+	require.NoError(err)
+	chain := history.BuildTransitionChainFrom(checkpoint.TransitionHash, gotManifests)
+	assert.Equal(state.LatestTransition().TransitionHash, chain[len(chain)-1].Digest())
+
+	// A restarted Guard recovers the full generation count.
+	b, reg := newTestGuard(t)
+	b.hist = g.hist
+	_, err = b.ResetState(ctx, nil, &stubAuthorizer{se: se, pk: testkeys.ECDSA(t)})
+	require.NoError(err)
+	requireGauge(t, reg, numManifests)
+}
+
+func TestScheduleState(t *testing.T) {
+	ctx := t.Context()
+	assert := assert.New(t)
+	require := require.New(t)
+	g, reg := newTestGuard(t)
+	clock := testingclock.NewFakeClock(time.Now())
+	g.clock = clock
+
+	mnfst, manifestBytes, policies := newManifest(t)
+	se := newSeedEngine(t)
+
+	_, err := g.ScheduleState(ctx, nil, manifestBytes, policies, nil, clock.Now())
+	require.Error(err, "the initial manifest must not be scheduled")
+
+	oldState, err := g.UpdateState(ctx, nil, se, manifestBytes, policies, nil)
+	require.NoError(err)
+	pending, err := g.GetPendingUpdate(ctx)
+	require.NoError(err)
+	require.Nil(pending)
+
+	mnfst.WorkloadOwnerPubKeys = []manifest.HexString{"cafe"}
+	nextManifestBytes, err := json.Marshal(mnfst)
+	require.NoError(err)
+	scheduled, err := g.ScheduleState(ctx, oldState, nextManifestBytes, policies, nil, clock.Now().Add(time.Hour))
+	require.NoError(err)
+
+	pending, err = g.GetPendingUpdate(ctx)
+	require.NoError(err)
+	require.NotNil(pending)
+	assert.Equal(scheduled.TransitionHash, pending.TransitionHash)
+	assert.True(scheduled.ActivationTime.Equal(pending.ActivationTime))
+	assert.Equal(nextManifestBytes, pending.ManifestBytes)
+
+	// The update isn't applied before the activation time.
+	require.NoError(g.activatePendingUpdate())
+	state, err := g.GetState(ctx)
+	require.NoError(err)
+	assert.Same(oldState, state)
+
+	clock.Step(time.Hour)
+	require.NoError(g.activatePendingUpdate())
+	state, err = g.GetState(ctx)
+	require.NoError(err)
+	assert.Equal(scheduled.TransitionHash, state.LatestTransition().TransitionHash)
+	assert.Equal(nextManifestBytes, state.ManifestBytes())
+	requireGauge(t, reg, 2)
+
+	pending, err = g.GetPendingUpdate(ctx)
+	require.NoError(err)
+	assert.Nil(pending)
+}
+
+func TestCancelPendingUpdate(t *testing.T) {
+	ctx := t.Context()
+	require := require.New(t)
+	g, _ := newTestGuard(t)
+	clock := testingclock.NewFakeClock(time.Now())
+	g.clock = clock
+
+	_, manifestBytes, policies := newManifest(t)
+	se := newSeedEngine(t)
+
+	oldState, err := g.UpdateState(ctx, nil, se, manifestBytes, policies, nil)
+	require.NoError(err)
+	scheduled, err := g.ScheduleState(ctx, oldState, manifestBytes, policies, nil, clock.Now().Add(time.Hour))
+	require.NoError(err)
+
+	require.ErrorIs(g.CancelPendingUpdate(ctx, [history.HashSize]byte{}), ErrNoPendingUpdate)
+	require.NoError(g.CancelPendingUpdate(ctx, scheduled.TransitionHash))
+	require.ErrorIs(g.CancelPendingUpdate(ctx, scheduled.TransitionHash), ErrNoPendingUpdate)
+
+	clock.Step(2 * time.Hour)
+	require.NoError(g.activatePendingUpdate())
+	state, err := g.GetState(ctx)
+	require.NoError(err)
+	require.Same(oldState, state)
+}
+
+func TestPendingUpdateSuperseded(t *testing.T) {
+	ctx := t.Context()
+	require := require.New(t)
+	g, _ := newTestGuard(t)
+	clock := testingclock.NewFakeClock(time.Now())
+	g.clock = clock
+
+	mnfst, manifestBytes, policies := newManifest(t)
+	se := newSeedEngine(t)
+
+	oldState, err := g.UpdateState(ctx, nil, se, manifestBytes, policies, nil)
+	require.NoError(err)
+	_, err = g.ScheduleState(ctx, oldState, manifestBytes, policies, nil, clock.Now().Add(time.Hour))
+	require.NoError(err)
+
+	// An immediate update supersedes the pending update.
+	mnfst.WorkloadOwnerPubKeys = []manifest.HexString{"cafe"}
+	nextManifestBytes, err := json.Marshal(mnfst)
+	require.NoError(err)
+	nextState, err := g.UpdateState(ctx, oldState, se, nextManifestBytes, policies, nil)
+	require.NoError(err)
+
+	pending, err := g.GetPendingUpdate(ctx)
+	require.NoError(err)
+	require.Nil(pending)
+
+	clock.Step(time.Hour)
+	require.NoError(g.activatePendingUpdate())
+	state, err := g.GetState(ctx)
+	require.NoError(err)
+	require.Same(nextState, state)
+}
+
+func TestPendingUpdateRestoredOnFailure(t *testing.T) {
+	ctx := t.Context()
+	require := require.New(t)
+	g, _ := newTestGuard(t)
+	clock := testingclock.NewFakeClock(time.Now())
+	g.clock = clock
+
+	_, manifestBytes, policies := newManifest(t)
+	se := newSeedEngine(t)
+
+	oldState, err := g.UpdateState(ctx, nil, se, manifestBytes, policies, nil)
+	require.NoError(err)
+	scheduled, err := g.ScheduleState(ctx, oldState, manifestBytes, policies, nil, clock.Now().Add(time.Hour))
+	require.NoError(err)
+
+	// The latest transition was changed behind the Guard's back, so the activation fails.
+	require.NoError(g.hist.SetLatest(oldState.latest, &history.LatestTransition{TransitionHash: [history.HashSize]byte{1}}, se.TransactionSigningKey()))
+	clock.Step(time.Hour)
+	require.Error(g.activatePendingUpdate())
+	state, err := g.GetState(ctx)
+	require.NoError(err)
+	require.Same(oldState, state)
+
+	pending, err := g.GetPendingUpdate(ctx)
+	require.NoError(err)
+	require.NotNil(pending)
+	require.Equal(scheduled.TransitionHash, pending.TransitionHash)
+}
+
+func TestGetRollbackTarget(t *testing.T) {
+	ctx := t.Context()
+	require := require.New(t)
+	g, _ := newTestGuard(t)
+
+	mnfst, manifestBytes, policies := newManifest(t)
+	se := newSeedEngine(t)
+
+	initialState, err := g.UpdateState(ctx, nil, se, manifestBytes, policies, nil)
+	require.NoError(err)
+	mnfst.WorkloadOwnerPubKeys = []manifest.HexString{"cafe"}
+	secondManifestBytes, err := json.Marshal(mnfst)
+	require.NoError(err)
+	secondState, err := g.UpdateState(ctx, initialState, se, secondManifestBytes, policies, nil)
+	require.NoError(err)
+
+	gotManifest, gotPolicies, err := g.GetRollbackTarget(ctx, secondState, initialState.latest.TransitionHash)
+	require.NoError(err)
+	require.Equal(manifestBytes, gotManifest)
+	require.ElementsMatch(policies, gotPolicies)
+
+	_, _, err = g.GetRollbackTarget(ctx, secondState, secondState.latest.TransitionHash)
+	require.Error(err, "the latest transition can't be a rollback target")
+	_, _, err = g.GetRollbackTarget(ctx, secondState, [history.HashSize]byte{1})
+	require.ErrorIs(err, ErrTransitionNotFound)
+
+	mnfst.SeedshareOwnerPubKeys = []manifest.HexString{"beef"}
+	thirdManifestBytes, err := json.Marshal(mnfst)
+	require.NoError(err)
+	thirdState, err := g.UpdateState(ctx, secondState, se, thirdManifestBytes, policies, nil)
+	require.NoError(err)
+	_, _, err = g.GetRollbackTarget(ctx, thirdState, initialState.latest.TransitionHash)
+	require.ErrorIs(err, ErrSeedshareOwnersChanged)
+}
+
+func TestGetTransitionSignatures(t *testing.T) {
+	ctx := t.Context()
+	require := require.New(t)
+	g, _ := newTestGuard(t)
+
+	_, manifestBytes, policies := newManifest(t)
+	se := newSeedEngine(t)
+
+	_, err := g.GetTransitionSignatures(ctx)
+	require.ErrorIs(err, ErrNoState)
+
+	// The initial transition is authorized without signature, the second one with signature.
+	state, err := g.UpdateState(ctx, nil, se, manifestBytes, policies, nil)
+	require.NoError(err)
+	firstTransition := state.LatestTransition().TransitionHash
+
+	state, err = g.UpdateState(ctx, state, se, manifestBytes, policies, []byte("signature"))
+	require.NoError(err)
+	secondTransition := state.LatestTransition().TransitionHash
+
+	signatures, err := g.GetTransitionSignatures(ctx)
+	require.NoError(err)
+	require.Equal(map[[history.HashSize]byte][]byte{secondTransition: []byte("signature")}, signatures)
+	require.NotContains(signatures, firstTransition)
+}
+
 func TestResetState(t *testing.T) {
 	ctx := t.Context()
 	require := require.New(t)
@@ -173,7 +461,7 @@ func TestResetState(t *testing.T) {
 	require.Nil(state)
 
 	// Initialize the state.
-	state, err = g.UpdateState(ctx, nil, se, manifestBytes, policies)
+	state, err = g.UpdateState(ctx, nil, se, manifestBytes, policies, nil)
 	require.NoError(err)
 	require.NotNil(state)
 
@@ -214,7 +502,7 @@ func TestConcurrentUpdateState(t *testing.T) {
 	for i := range numWorkers {
 		go func() {
 			defer wg.Done()
-			_, err := guard.UpdateState(ctx, nil, se, mnfst, policies)
+			_, err := guard.UpdateState(ctx, nil, se, mnfst, policies, nil)
 			if err != nil {
 				errCount.Add(1)
 				assert.ErrorIs(err, ErrConcurrentUpdate, "iteration %d", i)
@@ -251,7 +539,7 @@ func TestMetrics(t *testing.T) {
 	require.ErrorIs(err, ErrNoState)
 	for i := range numGenerations {
 		requireGauge(t, reg, i, "iteration %d", i)
-		s, err = a.UpdateState(t.Context(), s, se, manifestBytes, policies)
+		s, err = a.UpdateState(t.Context(), s, se, manifestBytes, policies, nil)
 		require.NoError(err, "iteration %d", i)
 	}
 	requireGauge(t, reg, numGenerations)
@@ -271,11 +559,11 @@ func TestMetrics(t *testing.T) {
 
 type stubAuthorizer struct {
 	se  *seedengine.SeedEngine
-	pk  *ecdsa.PrivateKey
+	pk  crypto.Signer
 	err error
 }
 
-func (fa *stubAuthorizer) AuthorizeByManifest(context.Context, *manifest.Manifest) (*seedengine.SeedEngine, *ecdsa.PrivateKey, error) {
+func (fa *stubAuthorizer) AuthorizeByManifest(context.Context, *manifest.Manifest) (*seedengine.SeedEngine, crypto.Signer, error) {
 	return fa.se, fa.pk, fa.err
 }
 
@@ -308,7 +596,7 @@ func TestWatchHistory(t *testing.T) {
 			_, manifestBytes, policies := newManifest(t)
 
 			se := newSeedEngine(t)
-			state, err := g.UpdateState(ctx, nil, se, manifestBytes, policies)
+			state, err := g.UpdateState(ctx, nil, se, manifestBytes, policies, nil)
 			require.NoError(err)
 			require.NotNil(state)
 
@@ -368,7 +656,7 @@ func TestWatchHistoryLateNotifications(t *testing.T) {
 	var notifications [][]byte
 	var state *State
 	for range 2 {
-		nextState, err := g.UpdateState(ctx, state, se, manifestBytes, policies)
+		nextState, err := g.UpdateState(ctx, state, se, manifestBytes, policies, nil)
 		require.NoError(err)
 		state = nextState
 		latest, err := store.Get("transitions/latest")
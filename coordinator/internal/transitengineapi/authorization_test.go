@@ -0,0 +1,149 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package transitengine
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/edgelesssys/contrast/coordinator/internal/stateguard"
+	"github.com/edgelesssys/contrast/internal/constants"
+	"github.com/edgelesssys/contrast/internal/manifest"
+	"github.com/edgelesssys/contrast/internal/oid"
+	"github.com/edgelesssys/contrast/internal/seedengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorizationMiddleware(t *testing.T) {
+	policyHash := make([]byte, 32)
+	policyHash[0] = 0xbb
+	m := &manifest.Manifest{
+		TransitKeys: map[string]manifest.TransitKey{
+			"shared": {Grants: []manifest.TransitKeyGrant{
+				{
+					WorkloadSecretIDs: []string{"producer"},
+					Operations:        []manifest.TransitOperation{manifest.TransitOperationEncrypt},
+				},
+				{
+					PolicyHashes: []manifest.HexString{manifest.HexString(hex.EncodeToString(policyHash))},
+					Operations:   []manifest.TransitOperation{manifest.TransitOperationDecrypt},
+				},
+			}},
+		},
+	}
+	otherPolicyHash := make([]byte, 32)
+	otherPolicyHash[0] = 0xcc
+
+	testCases := map[string]struct {
+		noClientCert     bool
+		workloadSecretID string
+		policyHash       []byte
+		op               manifest.TransitOperation
+		key              string
+		expStatus        int
+	}{
+		"unrestricted key with matching ID": {
+			workloadSecretID: "foo",
+			policyHash:       otherPolicyHash,
+			op:               manifest.TransitOperationEncrypt,
+			key:              "foo",
+			expStatus:        http.StatusOK,
+		},
+		"unrestricted key with other ID": {
+			workloadSecretID: "bar",
+			policyHash:       otherPolicyHash,
+			op:               manifest.TransitOperationEncrypt,
+			key:              "foo",
+			expStatus:        http.StatusForbidden,
+		},
+		"shared key granted by ID": {
+			workloadSecretID: "producer",
+			policyHash:       otherPolicyHash,
+			op:               manifest.TransitOperationEncrypt,
+			key:              "shared",
+			expStatus:        http.StatusOK,
+		},
+		"shared key granted by policy hash": {
+			policyHash: policyHash,
+			op:         manifest.TransitOperationDecrypt,
+			key:        "shared",
+			expStatus:  http.StatusOK,
+		},
+		"shared key operation not granted": {
+			workloadSecretID: "producer",
+			policyHash:       otherPolicyHash,
+			op:               manifest.TransitOperationDecrypt,
+			key:              "shared",
+			expStatus:        http.StatusForbidden,
+		},
+		"shared key with matching ID": {
+			workloadSecretID: "shared",
+			policyHash:       otherPolicyHash,
+			op:               manifest.TransitOperationEncrypt,
+			key:              "shared",
+			expStatus:        http.StatusForbidden,
+		},
+		"key name with slash": {
+			workloadSecretID: "foo/bar",
+			policyHash:       otherPolicyHash,
+			op:               manifest.TransitOperationEncrypt,
+			key:              "foo%2Fbar",
+			expStatus:        http.StatusForbidden,
+		},
+		"no client cert": {
+			noClientCert: true,
+			op:           manifest.TransitOperationEncrypt,
+			key:          "foo",
+			expStatus:    http.StatusForbidden,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+
+			seedEngine, err := seedengine.New(make([]byte, constants.SecretSeedSize), make([]byte, constants.SecretSeedSaltSize))
+			require.NoError(err)
+			guard := &fakeStateGuard{state: stateguard.NewStateForTest(seedEngine, m, nil, nil)}
+
+			var called bool
+			next := func(http.ResponseWriter, *http.Request) { called = true }
+			mux := http.NewServeMux()
+			mux.Handle("/v1/transit/test/{name}", authorizationMiddleware(next, guard, tc.op, slog.New(slog.DiscardHandler)))
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/transit/test/"+tc.key, nil)
+			if !tc.noClientCert {
+				cert := &x509.Certificate{}
+				if tc.workloadSecretID != "" {
+					cert.Extensions = append(cert.Extensions, newBytesExtension(t, oid.WorkloadSecretOID, []byte(tc.workloadSecretID)))
+				}
+				if tc.policyHash != nil {
+					cert.Extensions = append(cert.Extensions, newBytesExtension(t, oid.PolicyHashOID, tc.policyHash))
+				}
+				req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+			}
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			assert.Equal(tc.expStatus, rec.Code)
+			assert.Equal(tc.expStatus == http.StatusOK, called)
+		})
+	}
+}
+
+func newBytesExtension(t *testing.T, id asn1.ObjectIdentifier, value []byte) pkix.Extension {
+	t.Helper()
+	data, err := asn1.Marshal(value)
+	require.NoError(t, err)
+	return pkix.Extension{Id: id, Value: data}
+}
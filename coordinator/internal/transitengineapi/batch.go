@@ -0,0 +1,118 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package transitengine
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// maxBatchItems limits the number of items in the batch_input of a single request.
+const maxBatchItems = 1000
+
+type (
+	// encryptionBatchItem is an item of the batch_input of an encryption request.
+	encryptionBatchItem struct {
+		Plaintext      []byte `json:"plaintext"`
+		AssociatedData []byte `json:"associated_data,omitempty"`
+	}
+	// decryptionBatchItem is an item of the batch_input of a decryption request.
+	decryptionBatchItem struct {
+		CiphertextContainer *ciphertextContainer `json:"ciphertext"`
+		AssociatedData      []byte               `json:"associated_data,omitempty"`
+	}
+	// batchResult is an item of the batch_results of a batch response. Error is set if the item
+	// failed, in which case the other fields are empty.
+	batchResult struct {
+		Ciphertext *ciphertextContainer `json:"ciphertext,omitempty"`
+		Plaintext  []byte               `json:"plaintext,omitempty"`
+		KeyVersion uint32               `json:"key_version,omitempty"`
+		Error      string               `json:"error,omitempty"`
+	}
+	// batchResponse holds the results of a batch request, in the order of the batch_input.
+	batchResponse struct {
+		BatchResults []batchResult `json:"batch_results"`
+	}
+)
+
+// encryptBatch encrypts all items with the given key.
+func encryptBatch(key []byte, keyVersion uint32, items []encryptionBatchItem) []batchResult {
+	results := make([]batchResult, len(items))
+	for i, item := range items {
+		ciphertextContainer, err := symmetricEncryptRaw(key, item.Plaintext, item.AssociatedData)
+		if err != nil {
+			results[i].Error = fmt.Sprintf("encrypting: %v", err)
+			continue
+		}
+		ciphertextContainer.keyVersion = keyVersion
+		results[i] = batchResult{Ciphertext: &ciphertextContainer, KeyVersion: keyVersion}
+	}
+	return results
+}
+
+// decryptBatch decrypts all items with the keys of their versions. The key of each version is
+// derived only once per batch.
+func decryptBatch(ctx context.Context, guard stateGuard, metadata *keyMetadata, name string, items []decryptionBatchItem) []batchResult {
+	keys := make(map[uint32][]byte)
+	results := make([]batchResult, len(items))
+	for i, item := range items {
+		if item.CiphertextContainer == nil {
+			results[i].Error = "missing mandatory field: ciphertext"
+			continue
+		}
+		keyVersion := item.CiphertextContainer.keyVersion
+		if err := metadata.checkDecryptionVersion(keyVersion); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		key, ok := keys[keyVersion]
+		if !ok {
+			var err error
+			key, err = deriveEncryptionKey(ctx, guard, keyVersion, name)
+			if err != nil {
+				results[i].Error = fmt.Sprintf("key derivation: %v", err)
+				continue
+			}
+			keys[keyVersion] = key
+		}
+		plaintext, err := symmetricDecryptRaw(key, *item.CiphertextContainer, item.AssociatedData)
+		if err != nil {
+			results[i].Error = fmt.Sprintf("decrypting: %v", err)
+			continue
+		}
+		results[i] = batchResult{Plaintext: plaintext, KeyVersion: keyVersion}
+	}
+	return results
+}
+
+// batchStatusCode returns the HTTP status code of a batch response. Like OpenBao, it's 400 if any
+// item failed, unless partialFailureCode is set and not all items failed.
+func batchStatusCode(results []batchResult, partialFailureCode int) int {
+	var failed int
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+		}
+	}
+	switch {
+	case failed == 0:
+		return http.StatusOK
+	case failed < len(results) && partialFailureCode != 0:
+		return partialFailureCode
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// checkBatchInput checks the size of a batch_input and the partial_failure_response_code.
+func checkBatchInput(size, partialFailureCode int) error {
+	if size > maxBatchItems {
+		return fmt.Errorf("batch_input has %d items, at most %d are allowed", size, maxBatchItems)
+	}
+	if partialFailureCode != 0 && (partialFailureCode < 200 || partialFailureCode > 599) {
+		return fmt.Errorf("invalid partial_failure_response_code %d", partialFailureCode)
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package transitengine
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type batchResponseBody struct {
+	Data struct {
+		BatchResults []struct {
+			Ciphertext string `json:"ciphertext"`
+			Plaintext  string `json:"plaintext"`
+			Error      string `json:"error"`
+		} `json:"batch_results"`
+	} `json:"data"`
+}
+
+func TestBatchCyclic(t *testing.T) {
+	plaintexts := []string{"AAAA", "c2VjcmV0", "AQID"}
+
+	testCases := map[string]struct {
+		// associatedData is the associated data used for decryption of each item.
+		associatedData     []string
+		partialFailureCode int
+		wantCode           int
+		wantFailed         []bool
+	}{
+		"all items succeed": {
+			associatedData: []string{"AAAA", "AAAA", "AAAA"},
+			wantCode:       http.StatusOK,
+			wantFailed:     []bool{false, false, false},
+		},
+		"one item fails": {
+			associatedData: []string{"AAAA", "AAAA", "AQID"},
+			wantCode:       http.StatusBadRequest,
+			wantFailed:     []bool{false, false, true},
+		},
+		"one item fails with partial failure code": {
+			associatedData:     []string{"AAAA", "AQID", "AAAA"},
+			partialFailureCode: http.StatusMultiStatus,
+			wantCode:           http.StatusMultiStatus,
+			wantFailed:         []bool{false, true, false},
+		},
+		"all items fail with partial failure code": {
+			associatedData:     []string{"AQID", "AQID", "AQID"},
+			partialFailureCode: http.StatusMultiStatus,
+			wantCode:           http.StatusBadRequest,
+			wantFailed:         []bool{true, true, true},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+			guard, err := newTestGuard()
+			require.NoError(err)
+			mux := newMockTransitEngineMux(guard)
+
+			var encItems []string
+			for _, plaintext := range plaintexts {
+				encItems = append(encItems, fmt.Sprintf(`{"plaintext":%q,"associated_data":"AAAA"}`, plaintext))
+			}
+			res, body := doRequest(t, mux, http.MethodPut, "/v1/transit/encrypt/foo", `{"batch_input":[`+strings.Join(encItems, ",")+`]}`)
+			require.Equal(http.StatusOK, res.StatusCode, body)
+			var encResp batchResponseBody
+			require.NoError(json.Unmarshal([]byte(body), &encResp))
+			require.Len(encResp.Data.BatchResults, len(plaintexts))
+
+			var decItems []string
+			for i, result := range encResp.Data.BatchResults {
+				require.Empty(result.Error)
+				decItems = append(decItems, fmt.Sprintf(`{"ciphertext":%q,"associated_data":%q}`, result.Ciphertext, tc.associatedData[i]))
+			}
+			decReq := fmt.Sprintf(`{"batch_input":[%s],"partial_failure_response_code":%d}`, strings.Join(decItems, ","), tc.partialFailureCode)
+			res, body = doRequest(t, mux, http.MethodPut, "/v1/transit/decrypt/foo", decReq)
+			require.Equal(tc.wantCode, res.StatusCode, body)
+			var decResp batchResponseBody
+			require.NoError(json.Unmarshal([]byte(body), &decResp))
+			require.Len(decResp.Data.BatchResults, len(plaintexts))
+			for i, result := range decResp.Data.BatchResults {
+				if tc.wantFailed[i] {
+					assert.NotEmpty(result.Error, "item %d", i)
+					assert.Empty(result.Plaintext, "item %d", i)
+					continue
+				}
+				assert.Empty(result.Error, "item %d", i)
+				assert.Equal(plaintexts[i], result.Plaintext, "item %d", i)
+			}
+		})
+	}
+}
+
+func TestBatchInvalidRequests(t *testing.T) {
+	testCases := map[string]struct {
+		path     string
+		body     string
+		wantCode int
+	}{
+		"too many items": {
+			path:     "/v1/transit/encrypt/foo",
+			body:     `{"batch_input":[` + strings.Repeat(`{"plaintext":"AAAA"},`, maxBatchItems) + `{"plaintext":"AAAA"}]}`,
+			wantCode: http.StatusBadRequest,
+		},
+		"invalid partial failure code": {
+			path:     "/v1/transit/encrypt/foo",
+			body:     `{"batch_input":[{"plaintext":"AAAA"}],"partial_failure_response_code":1000}`,
+			wantCode: http.StatusBadRequest,
+		},
+		"invalid key version": {
+			path:     "/v1/transit/encrypt/foo",
+			body:     `{"batch_input":[{"plaintext":"AAAA"}],"key_version":-1}`,
+			wantCode: http.StatusBadRequest,
+		},
+		"missing ciphertext": {
+			path:     "/v1/transit/decrypt/foo",
+			body:     `{"batch_input":[{"associated_data":"AAAA"}]}`,
+			wantCode: http.StatusBadRequest,
+		},
+		"invalid ciphertext": {
+			path:     "/v1/transit/decrypt/foo",
+			body:     `{"batch_input":[{"ciphertext":"vault:v0:AAAA"}]}`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			guard, err := newTestGuard()
+			require.NoError(err)
+			mux := newMockTransitEngineMux(guard)
+
+			res, body := doRequest(t, mux, http.MethodPut, tc.path, tc.body)
+			require.Equal(tc.wantCode, res.StatusCode, body)
+		})
+	}
+}
+
+func TestDatakey(t *testing.T) {
+	testCases := map[string]struct {
+		datakeyType   string
+		body          string
+		wantCode      int
+		wantPlaintext bool
+		wantBits      int
+	}{
+		"plaintext": {
+			datakeyType:   "plaintext",
+			body:          `{}`,
+			wantCode:      http.StatusOK,
+			wantPlaintext: true,
+			wantBits:      256,
+		},
+		"wrapped": {
+			datakeyType: "wrapped",
+			body:        `{"bits":512}`,
+			wantCode:    http.StatusOK,
+			wantBits:    512,
+		},
+		"empty body": {
+			datakeyType:   "plaintext",
+			wantCode:      http.StatusOK,
+			wantPlaintext: true,
+			wantBits:      256,
+		},
+		"128 bits with associated data": {
+			datakeyType:   "plaintext",
+			body:          `{"bits":128,"associated_data":"AAAA"}`,
+			wantCode:      http.StatusOK,
+			wantPlaintext: true,
+			wantBits:      128,
+		},
+		"invalid bits": {
+			datakeyType: "plaintext",
+			body:        `{"bits":100}`,
+			wantCode:    http.StatusBadRequest,
+		},
+		"invalid type": {
+			datakeyType: "exported",
+			body:        `{}`,
+			wantCode:    http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+			guard, err := newTestGuard()
+			require.NoError(err)
+			mux := newMockTransitEngineMux(guard)
+
+			res, body := doRequest(t, mux, http.MethodPost, "/v1/transit/datakey/"+tc.datakeyType+"/foo", tc.body)
+			require.Equal(tc.wantCode, res.StatusCode, body)
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+			var datakeyResp struct {
+				Data struct {
+					Ciphertext string `json:"ciphertext"`
+					Plaintext  []byte `json:"plaintext"`
+				} `json:"data"`
+			}
+			require.NoError(json.Unmarshal([]byte(body), &datakeyResp))
+			if !tc.wantPlaintext {
+				assert.Empty(datakeyResp.Data.Plaintext)
+			} else {
+				assert.Len(datakeyResp.Data.Plaintext, tc.wantBits/8)
+			}
+
+			// The wrapped data key can be unwrapped with the decrypt endpoint.
+			var datakeyReq struct {
+				AssociatedData []byte `json:"associated_data"`
+			}
+			if tc.body != "" {
+				require.NoError(json.Unmarshal([]byte(tc.body), &datakeyReq))
+			}
+			decReq, err := json.Marshal(map[string]any{"ciphertext": datakeyResp.Data.Ciphertext, "associated_data": datakeyReq.AssociatedData})
+			require.NoError(err)
+			res, body = doRequest(t, mux, http.MethodPut, "/v1/transit/decrypt/foo", string(decReq))
+			require.Equal(http.StatusOK, res.StatusCode, body)
+			var decResp struct {
+				Data struct {
+					Plaintext []byte `json:"plaintext"`
+				} `json:"data"`
+			}
+			require.NoError(json.Unmarshal([]byte(body), &decResp))
+			assert.Len(decResp.Data.Plaintext, tc.wantBits/8)
+			if tc.wantPlaintext {
+				assert.Equal(datakeyResp.Data.Plaintext, decResp.Data.Plaintext)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package transitengine
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/edgelesssys/contrast/internal/auditlog"
+	"github.com/edgelesssys/contrast/internal/cryptohelpers"
+)
+
+// defaultDatakeyBits is the size of a data key if the request doesn't specify one.
+const defaultDatakeyBits = 256
+
+type (
+	// datakeyRequest holds the currently supported, optional parameters of a data key request.
+	datakeyRequest struct {
+		Bits           int    `json:"bits"`
+		KeyVersion     uint32 `json:"key_version"`
+		AssociatedData []byte `json:"associated_data,omitempty"`
+	}
+	// datakeyResponse holds the wrapped data key and, for the plaintext type, the data key itself.
+	datakeyResponse struct {
+		Ciphertext ciphertextContainer `json:"ciphertext"`
+		Plaintext  []byte              `json:"plaintext,omitempty"`
+		KeyVersion uint32              `json:"key_version"`
+	}
+)
+
+// getDatakeyHandler returns a handler that generates a random data key for envelope encryption.
+// The data key is returned encrypted with the named key, like the ciphertext of an encryption
+// request. For the type "plaintext", the data key is also returned in plaintext, for the type
+// "wrapped" it isn't.
+func getDatakeyHandler(guard stateGuard, keys *Keyring, logger *slog.Logger, audit *auditlog.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		workloadSecretID := r.PathValue("name")
+		datakeyType := r.PathValue("type")
+		if workloadSecretID == "" || (datakeyType != "plaintext" && datakeyType != "wrapped") {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{"Invalid URL format"},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		var datakeyReq datakeyRequest
+		// All parameters are optional, so an empty body is allowed.
+		if err := parseRequest(r, &datakeyReq); err != nil && !errors.Is(err, io.EOF) {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{fmt.Sprintf("parsing datakey request: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		if datakeyReq.Bits == 0 {
+			datakeyReq.Bits = defaultDatakeyBits
+		}
+		if datakeyReq.Bits != 128 && datakeyReq.Bits != 256 && datakeyReq.Bits != 512 {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{fmt.Sprintf("invalid bits %d, must be 128, 256 or 512", datakeyReq.Bits)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		metadata, err := getKeyMetadata(r.Context(), guard, keys, workloadSecretID)
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("reading key metadata: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		keyVersion, err := metadata.encryptionVersion(datakeyReq.KeyVersion)
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{err.Error()},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		key, err := deriveEncryptionKey(r.Context(), guard, keyVersion, workloadSecretID)
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("key derivation: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		datakey, err := cryptohelpers.GenerateRandomBytes(datakeyReq.Bits / 8)
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("generating data key: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		ciphertextContainer, err := symmetricEncryptRaw(key, datakey, datakeyReq.AssociatedData)
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("encrypting: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		ciphertextContainer.keyVersion = keyVersion
+		recordTransitEvent(audit, auditlog.EventTransitDatakey, r, workloadSecretID, keyVersion)
+		datakeyResp := datakeyResponse{Ciphertext: ciphertextContainer, KeyVersion: keyVersion}
+		if datakeyType == "plaintext" {
+			datakeyResp.Plaintext = datakey
+		}
+		if err = writeJSONResponse(w, datakeyResp); err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("writing response: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+	}
+}
@@ -0,0 +1,259 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package transitengine
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/edgelesssys/contrast/internal/history"
+	"k8s.io/utils/clock"
+)
+
+// keyRecordPrefix is the store key prefix of the transit engine key metadata records.
+const keyRecordPrefix = "transitkeys"
+
+// errInvalidKeyVersion is returned when a key version isn't allowed by the key metadata.
+var errInvalidKeyVersion = errors.New("invalid key version")
+
+// keyMetadata holds the versions of a transit engine key.
+//
+// Keys without metadata are unmanaged: they accept any key version and the default version is 0,
+// like before key versions were tracked. The first rotation or configuration change creates the
+// metadata.
+type keyMetadata struct {
+	// Name is the name of the key, which binds the metadata to the key.
+	Name string `json:"name"`
+	// LatestVersion is the version used for encryption by default.
+	LatestVersion uint32 `json:"latest_version"`
+	// MinDecryptionVersion is the minimum version of ciphertexts that can be decrypted.
+	MinDecryptionVersion uint32 `json:"min_decryption_version"`
+	// MinEncryptionVersion is the minimum version that can be used for encryption. If it's zero,
+	// any version down to MinDecryptionVersion can be used.
+	MinEncryptionVersion uint32 `json:"min_encryption_version"`
+	// Versions maps the versions created by rotations to their creation time.
+	Versions map[uint32]time.Time `json:"versions,omitempty"`
+
+	// stored is set if the metadata was read from or written to the keyring.
+	stored bool
+}
+
+// managed reports whether the key has metadata.
+func (m *keyMetadata) managed() bool {
+	return m.stored
+}
+
+// encryptionVersion returns the key version to encrypt with for the requested version, where 0
+// selects the latest version.
+func (m *keyMetadata) encryptionVersion(requested uint32) (uint32, error) {
+	if !m.managed() {
+		return requested, nil
+	}
+	version := requested
+	if version == 0 {
+		version = m.LatestVersion
+	}
+	if version > m.LatestVersion {
+		return 0, fmt.Errorf("%w: requested version %d is greater than the latest version %d", errInvalidKeyVersion, version, m.LatestVersion)
+	}
+	if version < m.MinEncryptionVersion || version < m.MinDecryptionVersion {
+		return 0, fmt.Errorf("%w: requested version %d is lower than the minimum encryption version", errInvalidKeyVersion, version)
+	}
+	return version, nil
+}
+
+// checkDecryptionVersion checks that ciphertexts of the given version can be decrypted.
+func (m *keyMetadata) checkDecryptionVersion(version uint32) error {
+	if !m.managed() {
+		return nil
+	}
+	if version > m.LatestVersion {
+		return fmt.Errorf("%w: ciphertext version %d is greater than the latest version %d", errInvalidKeyVersion, version, m.LatestVersion)
+	}
+	if version < m.MinDecryptionVersion {
+		return fmt.Errorf("%w: ciphertext version %d is lower than the minimum decryption version %d", errInvalidKeyVersion, version, m.MinDecryptionVersion)
+	}
+	return nil
+}
+
+// keyConfig holds the configurable fields of a transit engine key. Fields that are nil aren't
+// changed.
+type keyConfig struct {
+	MinDecryptionVersion *uint32 `json:"min_decryption_version,omitempty"`
+	MinEncryptionVersion *uint32 `json:"min_encryption_version,omitempty"`
+}
+
+// apply changes the metadata according to the config.
+func (c keyConfig) apply(m *keyMetadata) error {
+	minDecryptionVersion, minEncryptionVersion := m.MinDecryptionVersion, m.MinEncryptionVersion
+	if c.MinDecryptionVersion != nil {
+		minDecryptionVersion = *c.MinDecryptionVersion
+	}
+	if c.MinEncryptionVersion != nil {
+		minEncryptionVersion = *c.MinEncryptionVersion
+	}
+	if minDecryptionVersion > m.LatestVersion {
+		return fmt.Errorf("%w: min_decryption_version %d is greater than the latest version %d", errInvalidKeyVersion, minDecryptionVersion, m.LatestVersion)
+	}
+	if minEncryptionVersion > m.LatestVersion {
+		return fmt.Errorf("%w: min_encryption_version %d is greater than the latest version %d", errInvalidKeyVersion, minEncryptionVersion, m.LatestVersion)
+	}
+	if minEncryptionVersion != 0 && minEncryptionVersion < minDecryptionVersion {
+		return fmt.Errorf("%w: min_encryption_version %d is lower than min_decryption_version %d", errInvalidKeyVersion, minEncryptionVersion, minDecryptionVersion)
+	}
+	m.MinDecryptionVersion, m.MinEncryptionVersion = minDecryptionVersion, minEncryptionVersion
+	return nil
+}
+
+// keyInfo is the response of the key read, rotate and config endpoints, in the format of the
+// OpenBao transit secrets engine.
+type keyInfo struct {
+	Name                 string           `json:"name"`
+	Type                 string           `json:"type"`
+	LatestVersion        uint32           `json:"latest_version"`
+	MinDecryptionVersion uint32           `json:"min_decryption_version"`
+	MinEncryptionVersion uint32           `json:"min_encryption_version"`
+	Keys                 map[string]int64 `json:"keys"`
+	SupportsEncryption   bool             `json:"supports_encryption"`
+	SupportsDecryption   bool             `json:"supports_decryption"`
+	DeletionAllowed      bool             `json:"deletion_allowed"`
+	Exportable           bool             `json:"exportable"`
+}
+
+// info returns the metadata in the response format.
+func (m *keyMetadata) info() keyInfo {
+	keys := make(map[string]int64, len(m.Versions))
+	for version, created := range m.Versions {
+		keys[strconv.FormatUint(uint64(version), 10)] = created.Unix()
+	}
+	return keyInfo{
+		Name:                 m.Name,
+		Type:                 "aes256-gcm96",
+		LatestVersion:        m.LatestVersion,
+		MinDecryptionVersion: m.MinDecryptionVersion,
+		MinEncryptionVersion: m.MinEncryptionVersion,
+		Keys:                 keys,
+		SupportsEncryption:   true,
+		SupportsDecryption:   true,
+	}
+}
+
+// Keyring persists the metadata of transit engine keys as signed records in the history store.
+//
+// The store is shared by all Coordinators of a deployment, but isn't trusted. The records are
+// signed with the transaction signing key, which is derived from the secret seed and therefore
+// stable across manifest updates, and protected against rollback and deletion by the index of the
+// record set, see history.Records. A rollback would silently re-enable retired key versions.
+type Keyring struct {
+	records *history.Records
+	clock   clock.Clock
+}
+
+// NewKeyring creates a Keyring that stores the key metadata in the given store.
+func NewKeyring(store history.Store, logger *slog.Logger) *Keyring {
+	return &Keyring{
+		records: history.NewRecords(store, keyRecordPrefix, logger),
+		clock:   clock.RealClock{},
+	}
+}
+
+// MigrateKeyring copies the key metadata from src to dst. The migration is skipped if dst already
+// has key metadata or src has none, and the returned bool reports whether a migration took place.
+// An interrupted migration can safely be retried.
+func MigrateKeyring(src, dst history.Store) (bool, error) {
+	migrated, err := history.MigrateRecords(src, dst, keyRecordPrefix)
+	if err != nil {
+		return false, fmt.Errorf("migrating transit engine keys: %w", err)
+	}
+	return migrated, nil
+}
+
+// Watch keeps the cached key metadata up to date until the context is done, see history.Records.
+func (k *Keyring) Watch(ctx context.Context) error {
+	return k.records.Watch(ctx)
+}
+
+// metadata returns the metadata of the named key.
+func (k *Keyring) metadata(signingKey *ecdsa.PrivateKey, name string) (*keyMetadata, error) {
+	data, err := k.records.Get(signingKey, keyRecordName(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return &keyMetadata{Name: name}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("getting transit key metadata: %w", err)
+	}
+	return unmarshalKeyMetadata(data, name)
+}
+
+// rotate adds a new version to the named key and makes it the latest version.
+func (k *Keyring) rotate(signingKey *ecdsa.PrivateKey, name string) (*keyMetadata, error) {
+	return k.update(signingKey, name, func(m *keyMetadata) error {
+		if m.LatestVersion == ^uint32(0) {
+			return fmt.Errorf("%w: key can't be rotated beyond version %d", errInvalidKeyVersion, m.LatestVersion)
+		}
+		m.LatestVersion++
+		if m.Versions == nil {
+			m.Versions = make(map[uint32]time.Time)
+		}
+		m.Versions[m.LatestVersion] = k.clock.Now().UTC()
+		return nil
+	})
+}
+
+// configure applies the config to the named key.
+func (k *Keyring) configure(signingKey *ecdsa.PrivateKey, name string, config keyConfig) (*keyMetadata, error) {
+	return k.update(signingKey, name, config.apply)
+}
+
+// update applies fn to the metadata of the named key and stores the result.
+func (k *Keyring) update(signingKey *ecdsa.PrivateKey, name string, fn func(*keyMetadata) error) (*keyMetadata, error) {
+	var updated *keyMetadata
+	err := k.records.Update(signingKey, keyRecordName(name), func(data []byte) ([]byte, error) {
+		m := &keyMetadata{Name: name}
+		if data != nil {
+			var err error
+			if m, err = unmarshalKeyMetadata(data, name); err != nil {
+				return nil, err
+			}
+		}
+		if err := fn(m); err != nil {
+			return nil, err
+		}
+		m.stored = true
+		updated = m
+		data, err := json.Marshal(m)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling transit key metadata: %w", err)
+		}
+		return data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// keyRecordName returns the record name of the metadata of the named key. Key names may contain
+// characters that aren't allowed in record names, so the record is named after their hash.
+func keyRecordName(name string) string {
+	return fmt.Sprintf("%x", history.Digest([]byte(name)))
+}
+
+func unmarshalKeyMetadata(data []byte, name string) (*keyMetadata, error) {
+	var m keyMetadata
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("unmarshaling transit key metadata: %w", err)
+	}
+	if m.Name != name {
+		return nil, fmt.Errorf("transit key metadata belongs to key %q", m.Name)
+	}
+	m.stored = true
+	return &m, nil
+}
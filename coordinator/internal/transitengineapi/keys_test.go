@@ -0,0 +1,137 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package transitengine
+
+import (
+	"crypto/ecdsa"
+	"log/slog"
+	"testing"
+
+	"github.com/edgelesssys/contrast/internal/history"
+	"github.com/edgelesssys/contrast/internal/history/aferostore"
+	"github.com/edgelesssys/contrast/internal/testkeys"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyring(t *testing.T) {
+	signingKey := testkeys.ECDSA(t)
+
+	testCases := map[string]struct {
+		// tamper modifies the stored metadata of key "foo" after two rotations. firstRotation is the
+		// stored record after the first rotation.
+		tamper     func(t *testing.T, store *aferostore.AferoStore, k *Keyring, firstRotation []byte)
+		signingKey *ecdsa.PrivateKey
+		wantErr    bool
+		// wantErrIs is the error that's expected to be wrapped, if any.
+		wantErrIs error
+	}{
+		"untouched": {
+			tamper: func(*testing.T, *aferostore.AferoStore, *Keyring, []byte) {},
+		},
+		"deleted": {
+			tamper: func(t *testing.T, store *aferostore.AferoStore, _ *Keyring, _ []byte) {
+				require.NoError(t, store.Delete(keyRecordKey("foo")))
+			},
+			wantErr:   true,
+			wantErrIs: history.ErrRecordRollback,
+		},
+		"rolled back": {
+			tamper: func(t *testing.T, store *aferostore.AferoStore, _ *Keyring, firstRotation []byte) {
+				require.NoError(t, store.Set(keyRecordKey("foo"), firstRotation))
+			},
+			wantErr:   true,
+			wantErrIs: history.ErrRecordRollback,
+		},
+		"wrong signing key": {
+			tamper:     func(*testing.T, *aferostore.AferoStore, *Keyring, []byte) {},
+			signingKey: testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[1]),
+			wantErr:    true,
+		},
+		"metadata of other key": {
+			tamper: func(t *testing.T, store *aferostore.AferoStore, k *Keyring, _ []byte) {
+				_, err := k.rotate(signingKey, "bar")
+				require.NoError(t, err)
+				require.NoError(t, store.Set(keyRecordKey("foo"), mustGet(t, store, "bar")))
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+			store := aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()})
+			k := NewKeyring(store, slog.New(slog.DiscardHandler))
+
+			m, err := k.metadata(signingKey, "foo")
+			require.NoError(err)
+			assert.False(m.managed())
+			_, err = k.rotate(signingKey, "foo")
+			require.NoError(err)
+			firstRotation := mustGet(t, store, "foo")
+			_, err = k.rotate(signingKey, "foo")
+			require.NoError(err)
+
+			tc.tamper(t, store, k, firstRotation)
+
+			// A new keyring, like a restarted Coordinator, doesn't rely on a cache.
+			readKey := signingKey
+			if tc.signingKey != nil {
+				readKey = tc.signingKey
+			}
+			m, err = NewKeyring(store, slog.New(slog.DiscardHandler)).metadata(readKey, "foo")
+			if tc.wantErr {
+				require.Error(err)
+				if tc.wantErrIs != nil {
+					require.ErrorIs(err, tc.wantErrIs)
+				}
+				return
+			}
+			require.NoError(err)
+			assert.True(m.managed())
+			assert.EqualValues(2, m.LatestVersion)
+			assert.Len(m.Versions, 2)
+		})
+	}
+}
+
+func TestMigrateKeyring(t *testing.T) {
+	require := require.New(t)
+	signingKey := testkeys.ECDSA(t)
+	src := aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()})
+	dst := aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()})
+
+	migrated, err := MigrateKeyring(src, dst)
+	require.NoError(err)
+	require.False(migrated, "an empty keyring must not be migrated")
+
+	_, err = NewKeyring(src, slog.New(slog.DiscardHandler)).rotate(signingKey, "foo")
+	require.NoError(err)
+	migrated, err = MigrateKeyring(src, dst)
+	require.NoError(err)
+	require.True(migrated)
+
+	m, err := NewKeyring(dst, slog.New(slog.DiscardHandler)).metadata(signingKey, "foo")
+	require.NoError(err)
+	require.EqualValues(1, m.LatestVersion)
+
+	// The destination's keyring is never overwritten.
+	migrated, err = MigrateKeyring(src, dst)
+	require.NoError(err)
+	require.False(migrated)
+}
+
+func keyRecordKey(name string) string {
+	return keyRecordPrefix + "/" + keyRecordName(name)
+}
+
+func mustGet(t *testing.T, store *aferostore.AferoStore, name string) []byte {
+	t.Helper()
+	data, err := store.Get(keyRecordKey(name))
+	require.NoError(t, err)
+	return data
+}
@@ -0,0 +1,923 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package transitengine
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/edgelesssys/contrast/coordinator/internal/certregistry"
+	"github.com/edgelesssys/contrast/internal/auditlog"
+	"github.com/edgelesssys/contrast/internal/history"
+	"github.com/edgelesssys/contrast/internal/kmip"
+	"github.com/edgelesssys/contrast/internal/manifest"
+)
+
+const (
+	// maxKMIPMessageSize limits the size of a single KMIP request.
+	maxKMIPMessageSize = 1 << 20
+	// kmipHandshakeTimeout limits the duration of the TLS handshake of a KMIP connection.
+	kmipHandshakeTimeout = 10 * time.Second
+	// kmipIdleTimeout closes KMIP connections that don't send a request for this long.
+	kmipIdleTimeout = 5 * time.Minute
+	// gcmTagSize is the size of the authentication tag of AES-GCM.
+	gcmTagSize = 16
+	// defaultKMIPKeyLength is the length of a created key in bits if the request doesn't specify one.
+	defaultKMIPKeyLength = 256
+)
+
+// kmipVersion is a KMIP protocol version.
+type kmipVersion struct {
+	major, minor int32
+}
+
+// supportedKMIPVersions are the protocol versions the KMIP server speaks, in order of preference.
+// The served operations are encoded the same way in all of them, except for the attributes of
+// Create and Locate requests.
+var supportedKMIPVersions = []kmipVersion{{2, 0}, {1, 4}, {1, 3}, {1, 2}, {1, 1}, {1, 0}}
+
+// kmipOperations maps the served KMIP operations to their names in logs and to the transit engine
+// operation that must be granted to the client. Create and Get hand out key material like a
+// plaintext data key, and Destroy changes the state of a key like its configuration.
+var kmipOperations = map[kmip.Operation]struct {
+	name    string
+	transit manifest.TransitOperation
+}{
+	kmip.OperationCreate:  {"create", manifest.TransitOperationDatakey},
+	kmip.OperationGet:     {"get", manifest.TransitOperationDatakey},
+	kmip.OperationEncrypt: {"encrypt", manifest.TransitOperationEncrypt},
+	kmip.OperationDecrypt: {"decrypt", manifest.TransitOperationDecrypt},
+	kmip.OperationLocate:  {"locate", manifest.TransitOperationRead},
+	kmip.OperationDestroy: {"destroy", manifest.TransitOperationConfig},
+}
+
+// kmipError is an error with the KMIP result reason to report to the client.
+type kmipError struct {
+	reason kmip.ResultReason
+	err    error
+}
+
+func (e *kmipError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kmipError) Unwrap() error {
+	return e.err
+}
+
+func newKMIPError(reason kmip.ResultReason, format string, args ...any) error {
+	return &kmipError{reason: reason, err: fmt.Errorf(format, args...)}
+}
+
+// kmipClient is the client of a KMIP connection.
+type kmipClient struct {
+	peerIdentity
+	remoteAddr string
+}
+
+// KMIPServer serves symmetric keys over KMIP 1.0 to 1.4 and 2.0.
+//
+// Keys are KMIP objects with a random unique identifier. They are derived from the secret seed
+// like the keys of the transit engine API, and the object metadata is persisted in the history
+// store. Each object belongs to a transit engine key name, which authorizes the access to it.
+type KMIPServer struct {
+	guard     stateGuard
+	objects   *kmipObjects
+	tlsConfig *tls.Config
+	logger    *slog.Logger
+	audit     *auditlog.Log
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+}
+
+// NewKMIPServer sets up the KMIP server with a provided stateGuard. Object metadata is persisted in
+// store. Client certificates revoked in registry are rejected. Successful requests are recorded to
+// audit, which may be nil.
+func NewKMIPServer(guard stateGuard, store history.Store, registry *certregistry.Registry, logger *slog.Logger, audit *auditlog.Log) (*KMIPServer, error) {
+	tlsConfig, err := newTLSConfig(guard, registry, logger)
+	if err != nil {
+		return nil, err
+	}
+	return &KMIPServer{
+		guard:     guard,
+		objects:   newKMIPObjects(store, logger),
+		tlsConfig: tlsConfig,
+		logger:    logger,
+		audit:     audit,
+		conns:     make(map[net.Conn]struct{}),
+	}, nil
+}
+
+// Serve accepts KMIP connections on lis until the server is closed. It returns nil after Close.
+func (s *KMIPServer) Serve(lis net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return lis.Close()
+	}
+	s.listener = lis
+	s.mu.Unlock()
+
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return fmt.Errorf("accepting KMIP connection: %w", err)
+		}
+		go s.serveConn(tls.Server(conn, s.tlsConfig))
+	}
+}
+
+// Watch keeps the cached object records up to date until the context is done, see
+// history.Records.
+func (s *KMIPServer) Watch(ctx context.Context) error {
+	return s.objects.watch(ctx)
+}
+
+// Close stops accepting connections and closes all open connections.
+func (s *KMIPServer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	var errs []error
+	if s.listener != nil {
+		errs = append(errs, s.listener.Close())
+	}
+	for conn := range s.conns {
+		errs = append(errs, conn.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// serveConn handles the requests of a single KMIP connection.
+func (s *KMIPServer) serveConn(conn *tls.Conn) {
+	defer conn.Close()
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.conns[conn] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+	}()
+
+	ctx := context.Background()
+	handshakeCtx, cancel := context.WithTimeout(ctx, kmipHandshakeTimeout)
+	defer cancel()
+	if err := conn.HandshakeContext(handshakeCtx); err != nil {
+		s.logger.Debug("KMIP handshake failed", "remoteAddr", conn.RemoteAddr(), "err", err)
+		return
+	}
+	connState := conn.ConnectionState()
+	peer, err := newPeerIdentity(&connState)
+	if err != nil {
+		s.logger.Warn("KMIP client identification failed", "remoteAddr", conn.RemoteAddr(), "err", err)
+		return
+	}
+	client := kmipClient{peerIdentity: peer, remoteAddr: conn.RemoteAddr().String()}
+
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(kmipIdleTimeout)); err != nil {
+			return
+		}
+		data, err := kmip.ReadMessage(conn, maxKMIPMessageSize)
+		if errors.Is(err, kmip.ErrMessageTooLarge) {
+			// The rest of the message isn't read, so the connection can't be used anymore.
+			s.writeResponse(conn, client, kmipMessageErrorResponse(kmipVersion{1, 0}, newKMIPError(kmip.ResultReasonInvalidMessage, "%w", err)))
+			return
+		} else if err != nil {
+			if !errors.Is(err, io.EOF) {
+				s.logger.Debug("Reading KMIP request", "remoteAddr", client.remoteAddr, "err", err)
+			}
+			return
+		}
+		var resp kmip.Item
+		if req, err := kmip.Unmarshal(data); err != nil {
+			s.logger.Warn("Decoding KMIP request", "remoteAddr", client.remoteAddr, "err", err)
+			resp = kmipMessageErrorResponse(kmipVersion{1, 0}, newKMIPError(kmip.ResultReasonInvalidMessage, "decoding request: %w", err))
+		} else {
+			resp = s.handleMessage(ctx, client, req)
+		}
+		if !s.writeResponse(conn, client, resp) {
+			return
+		}
+	}
+}
+
+// writeResponse writes a response message to the connection. It reports whether the write
+// succeeded.
+func (s *KMIPServer) writeResponse(conn net.Conn, client kmipClient, resp kmip.Item) bool {
+	out, err := kmip.Marshal(resp)
+	if err != nil {
+		s.logger.Error("Encoding KMIP response", "remoteAddr", client.remoteAddr, "err", err)
+		return false
+	}
+	if _, err := conn.Write(out); err != nil {
+		s.logger.Debug("Writing KMIP response", "remoteAddr", client.remoteAddr, "err", err)
+		return false
+	}
+	return true
+}
+
+// handleMessage processes the batch items of a request message and returns the response message.
+func (s *KMIPServer) handleMessage(ctx context.Context, client kmipClient, req kmip.Item) kmip.Item {
+	if req.Tag != kmip.TagRequestMessage || req.Type != kmip.TypeStructure {
+		return kmipMessageErrorResponse(kmipVersion{1, 0}, newKMIPError(kmip.ResultReasonInvalidMessage, "expected a request message"))
+	}
+	header, ok := req.Child(kmip.TagRequestHeader)
+	if !ok {
+		return kmipMessageErrorResponse(kmipVersion{1, 0}, newKMIPError(kmip.ResultReasonInvalidMessage, "missing request header"))
+	}
+	version, err := negotiateKMIPVersion(header)
+	if err != nil {
+		return kmipMessageErrorResponse(kmipVersion{1, 0}, err)
+	}
+	continueOnError := false
+	if optionItem, ok := header.Child(kmip.TagBatchErrorContinuationOption); ok {
+		option, err := optionItem.Enumeration()
+		if err != nil {
+			return kmipMessageErrorResponse(version, newKMIPError(kmip.ResultReasonInvalidMessage, "batch error continuation option: %w", err))
+		}
+		switch kmip.BatchErrorContinuationOption(option) {
+		case kmip.BatchErrorContinuationOptionContinue:
+			continueOnError = true
+		case kmip.BatchErrorContinuationOptionStop:
+		default:
+			return kmipMessageErrorResponse(version, newKMIPError(kmip.ResultReasonFeatureNotSupported, "batch error continuation option %d isn't supported", option))
+		}
+	}
+
+	var results []kmip.Item
+	// idPlaceholder holds the unique identifier returned by the previous item, which is used by
+	// items that don't specify one.
+	var idPlaceholder string
+	for _, batchItem := range req.ChildrenWithTag(kmip.TagBatchItem) {
+		result, err := s.handleBatchItem(ctx, client, version, batchItem, &idPlaceholder)
+		results = append(results, result)
+		if err != nil && !continueOnError {
+			break
+		}
+	}
+	return kmipResponse(version, results)
+}
+
+// handleBatchItem processes a single batch item and returns its response. The error is also
+// contained in the response.
+func (s *KMIPServer) handleBatchItem(ctx context.Context, client kmipClient, version kmipVersion, batchItem kmip.Item, idPlaceholder *string) (kmip.Item, error) {
+	var result []kmip.Item
+	var op kmip.Operation
+	if opItem, ok := batchItem.Child(kmip.TagOperation); ok {
+		result = append(result, opItem)
+		if v, err := opItem.Enumeration(); err == nil {
+			op = kmip.Operation(v)
+		}
+	}
+	if id, ok := batchItem.Child(kmip.TagUniqueBatchItemID); ok {
+		result = append(result, id)
+	}
+
+	payload, err := s.handleOperation(ctx, client, version, op, batchItem, idPlaceholder)
+	if err != nil {
+		reason := kmip.ResultReasonGeneralFailure
+		var kErr *kmipError
+		if errors.As(err, &kErr) {
+			reason = kErr.reason
+		}
+		result = append(result,
+			kmip.NewEnumeration(kmip.TagResultStatus, kmip.ResultStatusOperationFailed),
+			kmip.NewEnumeration(kmip.TagResultReason, reason),
+			kmip.NewTextString(kmip.TagResultMessage, err.Error()),
+		)
+		return kmip.NewStructure(kmip.TagBatchItem, result...), err
+	}
+	result = append(result,
+		kmip.NewEnumeration(kmip.TagResultStatus, kmip.ResultStatusSuccess),
+		kmip.NewStructure(kmip.TagResponsePayload, payload...),
+	)
+	return kmip.NewStructure(kmip.TagBatchItem, result...), nil
+}
+
+// handleOperation dispatches a batch item to the handler of its operation and returns the items
+// of the response payload.
+func (s *KMIPServer) handleOperation(ctx context.Context, client kmipClient, version kmipVersion, op kmip.Operation, batchItem kmip.Item, idPlaceholder *string) ([]kmip.Item, error) {
+	if op == 0 {
+		return nil, newKMIPError(kmip.ResultReasonInvalidMessage, "missing or invalid operation")
+	}
+	payload, ok := batchItem.Child(kmip.TagRequestPayload)
+	if !ok {
+		return nil, newKMIPError(kmip.ResultReasonInvalidMessage, "missing request payload")
+	}
+	if op == kmip.OperationDiscoverVersions {
+		return discoverKMIPVersions(payload)
+	}
+	if _, ok := kmipOperations[op]; !ok {
+		return nil, newKMIPError(kmip.ResultReasonOperationNotSupported, "operation %#x isn't supported", uint32(op))
+	}
+	switch op {
+	case kmip.OperationCreate:
+		return s.create(ctx, client, op, payload, idPlaceholder)
+	case kmip.OperationLocate:
+		return s.locate(ctx, client, op, payload, idPlaceholder)
+	}
+
+	uid, err := optionalText(payload, kmip.TagUniqueIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	if uid == "" {
+		uid = *idPlaceholder
+	}
+	if uid == "" {
+		return nil, newKMIPError(kmip.ResultReasonMissingData, "missing unique identifier")
+	}
+	*idPlaceholder = uid
+	switch op {
+	case kmip.OperationGet:
+		return s.get(ctx, client, op, uid, payload)
+	case kmip.OperationEncrypt:
+		return s.encrypt(ctx, client, op, uid, payload)
+	case kmip.OperationDecrypt:
+		return s.decrypt(ctx, client, op, uid, payload)
+	default: // kmip.OperationDestroy
+		return s.destroy(ctx, client, op, uid)
+	}
+}
+
+// create creates a symmetric key. The key belongs to the name given in the request, or to the
+// client's WorkloadSecretID if there is none.
+func (s *KMIPServer) create(ctx context.Context, client kmipClient, op kmip.Operation, payload kmip.Item, idPlaceholder *string) ([]kmip.Item, error) {
+	objectType, err := requiredEnumeration(payload, kmip.TagObjectType)
+	if err != nil {
+		return nil, err
+	}
+	if kmip.ObjectType(objectType) != kmip.ObjectTypeSymmetricKey {
+		return nil, newKMIPError(kmip.ResultReasonFeatureNotSupported, "object type %d isn't supported, only symmetric keys are", objectType)
+	}
+	attrs, err := parseKMIPAttributes(payload)
+	if err != nil {
+		return nil, err
+	}
+	if attrs.algorithm != 0 && attrs.algorithm != kmip.CryptographicAlgorithmAES {
+		return nil, newKMIPError(kmip.ResultReasonFeatureNotSupported, "cryptographic algorithm %d isn't supported, only AES is", attrs.algorithm)
+	}
+	length := attrs.length
+	if length == 0 {
+		length = defaultKMIPKeyLength
+	}
+	if length != 128 && length != 192 && length != 256 {
+		return nil, newKMIPError(kmip.ResultReasonInvalidField, "invalid cryptographic length %d, must be 128, 192 or 256", length)
+	}
+	name := attrs.name
+	if name == "" {
+		name = client.workloadSecretID
+	}
+	if name == "" {
+		return nil, newKMIPError(kmip.ResultReasonMissingData, "missing Name attribute")
+	}
+	if err := s.authorize(ctx, client, op, name); err != nil {
+		return nil, err
+	}
+	state, err := s.guard.GetState(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting state: %w", err)
+	}
+	obj, err := s.objects.create(state.SeedEngine().TransactionSigningKey(), name, length)
+	if err != nil {
+		return nil, fmt.Errorf("creating object: %w", err)
+	}
+	*idPlaceholder = obj.UID
+	s.recordEvent(auditlog.EventKMIPCreate, client, obj)
+	return []kmip.Item{
+		kmip.NewEnumeration(kmip.TagObjectType, kmip.ObjectTypeSymmetricKey),
+		kmip.NewTextString(kmip.TagUniqueIdentifier, obj.UID),
+	}, nil
+}
+
+// locate returns the unique identifiers of the keys with the name given in the request, or with
+// the client's WorkloadSecretID if there is none. Destroyed keys aren't returned.
+func (s *KMIPServer) locate(ctx context.Context, client kmipClient, op kmip.Operation, payload kmip.Item, idPlaceholder *string) ([]kmip.Item, error) {
+	attrs, err := parseKMIPAttributes(payload)
+	if err != nil {
+		return nil, err
+	}
+	var maxItems int32
+	if item, ok := payload.Child(kmip.TagMaximumItems); ok {
+		if maxItems, err = item.Integer(); err != nil {
+			return nil, newKMIPError(kmip.ResultReasonInvalidField, "maximum items: %w", err)
+		}
+	}
+	name := attrs.name
+	if name == "" {
+		name = client.workloadSecretID
+	}
+	if name == "" {
+		return nil, newKMIPError(kmip.ResultReasonMissingData, "missing Name attribute")
+	}
+	if err := s.authorize(ctx, client, op, name); err != nil {
+		return nil, err
+	}
+	// Only symmetric AES keys exist, so other filters match all or nothing.
+	if (attrs.objectType != 0 && attrs.objectType != kmip.ObjectTypeSymmetricKey) ||
+		(attrs.algorithm != 0 && attrs.algorithm != kmip.CryptographicAlgorithmAES) {
+		return []kmip.Item{}, nil
+	}
+	state, err := s.guard.GetState(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting state: %w", err)
+	}
+	objs, err := s.objects.locate(state.SeedEngine().TransactionSigningKey(), name)
+	if err != nil {
+		return nil, fmt.Errorf("locating objects: %w", err)
+	}
+	items := []kmip.Item{}
+	for _, obj := range objs {
+		if attrs.length != 0 && attrs.length != obj.Length {
+			continue
+		}
+		if maxItems > 0 && len(items) == int(maxItems) {
+			break
+		}
+		items = append(items, kmip.NewTextString(kmip.TagUniqueIdentifier, obj.UID))
+		*idPlaceholder = obj.UID
+	}
+	return items, nil
+}
+
+// get returns the material of a key.
+func (s *KMIPServer) get(ctx context.Context, client kmipClient, op kmip.Operation, uid string, payload kmip.Item) ([]kmip.Item, error) {
+	if item, ok := payload.Child(kmip.TagKeyFormatType); ok {
+		format, err := item.Enumeration()
+		if err != nil {
+			return nil, newKMIPError(kmip.ResultReasonInvalidField, "key format type: %w", err)
+		}
+		if kmip.KeyFormatType(format) != kmip.KeyFormatTypeRaw {
+			return nil, newKMIPError(kmip.ResultReasonKeyFormatTypeNotSupported, "key format type %d isn't supported, only raw is", format)
+		}
+	}
+	obj, key, err := s.objectKey(ctx, client, op, uid)
+	if err != nil {
+		return nil, err
+	}
+	s.recordEvent(auditlog.EventKMIPGet, client, obj)
+	return []kmip.Item{
+		kmip.NewEnumeration(kmip.TagObjectType, kmip.ObjectTypeSymmetricKey),
+		kmip.NewTextString(kmip.TagUniqueIdentifier, obj.UID),
+		kmip.NewStructure(kmip.TagSymmetricKey,
+			kmip.NewStructure(kmip.TagKeyBlock,
+				kmip.NewEnumeration(kmip.TagKeyFormatType, kmip.KeyFormatTypeRaw),
+				kmip.NewStructure(kmip.TagKeyValue,
+					kmip.NewByteString(kmip.TagKeyMaterial, key),
+				),
+				kmip.NewEnumeration(kmip.TagCryptographicAlgorithm, kmip.CryptographicAlgorithmAES),
+				kmip.NewInteger(kmip.TagCryptographicLength, obj.Length),
+			),
+		),
+	}, nil
+}
+
+// encrypt encrypts data with a key, using AES-GCM with a random IV.
+func (s *KMIPServer) encrypt(ctx context.Context, client kmipClient, op kmip.Operation, uid string, payload kmip.Item) ([]kmip.Item, error) {
+	if err := checkKMIPCryptographicParameters(payload); err != nil {
+		return nil, err
+	}
+	if _, ok := payload.Child(kmip.TagIVCounterNonce); ok {
+		return nil, newKMIPError(kmip.ResultReasonFeatureNotSupported, "the IV is generated by the server and can't be set")
+	}
+	data, err := requiredBytes(payload, kmip.TagData)
+	if err != nil {
+		return nil, err
+	}
+	associatedData, err := optionalBytes(payload, kmip.TagAuthenticatedEncryptionAdditionalData)
+	if err != nil {
+		return nil, err
+	}
+	obj, key, err := s.objectKey(ctx, client, op, uid)
+	if err != nil {
+		return nil, err
+	}
+	ciphertextContainer, err := symmetricEncryptRaw(key, data, associatedData)
+	if err != nil {
+		return nil, newKMIPError(kmip.ResultReasonCryptographicFailure, "encrypting: %w", err)
+	}
+	ciphertext, tag := ciphertextContainer.ciphertext[:len(ciphertextContainer.ciphertext)-gcmTagSize], ciphertextContainer.ciphertext[len(ciphertextContainer.ciphertext)-gcmTagSize:]
+	s.recordEvent(auditlog.EventKMIPEncrypt, client, obj)
+	return []kmip.Item{
+		kmip.NewTextString(kmip.TagUniqueIdentifier, obj.UID),
+		kmip.NewByteString(kmip.TagData, ciphertext),
+		kmip.NewByteString(kmip.TagIVCounterNonce, ciphertextContainer.nonce),
+		kmip.NewByteString(kmip.TagAuthenticatedEncryptionTag, tag),
+	}, nil
+}
+
+// decrypt decrypts data encrypted with a key by encrypt.
+func (s *KMIPServer) decrypt(ctx context.Context, client kmipClient, op kmip.Operation, uid string, payload kmip.Item) ([]kmip.Item, error) {
+	if err := checkKMIPCryptographicParameters(payload); err != nil {
+		return nil, err
+	}
+	data, err := requiredBytes(payload, kmip.TagData)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := requiredBytes(payload, kmip.TagIVCounterNonce)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aesGCMNonceSize {
+		return nil, newKMIPError(kmip.ResultReasonInvalidField, "IV must be %d bytes, got %d", aesGCMNonceSize, len(nonce))
+	}
+	tag, err := requiredBytes(payload, kmip.TagAuthenticatedEncryptionTag)
+	if err != nil {
+		return nil, err
+	}
+	if len(tag) != gcmTagSize {
+		return nil, newKMIPError(kmip.ResultReasonInvalidField, "authenticated encryption tag must be %d bytes, got %d", gcmTagSize, len(tag))
+	}
+	associatedData, err := optionalBytes(payload, kmip.TagAuthenticatedEncryptionAdditionalData)
+	if err != nil {
+		return nil, err
+	}
+	obj, key, err := s.objectKey(ctx, client, op, uid)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := symmetricDecryptRaw(key, ciphertextContainer{nonce: nonce, ciphertext: append(data[:len(data):len(data)], tag...)}, associatedData)
+	if err != nil {
+		return nil, newKMIPError(kmip.ResultReasonCryptographicFailure, "decrypting: %w", err)
+	}
+	s.recordEvent(auditlog.EventKMIPDecrypt, client, obj)
+	return []kmip.Item{
+		kmip.NewTextString(kmip.TagUniqueIdentifier, obj.UID),
+		kmip.NewByteString(kmip.TagData, plaintext),
+	}, nil
+}
+
+// destroy marks a key as destroyed. The object is kept as a tombstone, so that the key can't be
+// used anymore, even though it could still be derived from the secret seed.
+func (s *KMIPServer) destroy(ctx context.Context, client kmipClient, op kmip.Operation, uid string) ([]kmip.Item, error) {
+	state, err := s.guard.GetState(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting state: %w", err)
+	}
+	signingKey := state.SeedEngine().TransactionSigningKey()
+	obj, err := s.objects.get(signingKey, uid)
+	if err != nil {
+		return nil, kmipObjectError(err)
+	}
+	if err := s.authorize(ctx, client, op, obj.Name); err != nil {
+		return nil, err
+	}
+	if obj.Destroyed != nil {
+		return nil, newKMIPError(kmip.ResultReasonItemNotFound, "object %s was destroyed", uid)
+	}
+	if obj, err = s.objects.destroy(signingKey, uid); err != nil {
+		return nil, kmipObjectError(err)
+	}
+	s.recordEvent(auditlog.EventKMIPDestroy, client, obj)
+	return []kmip.Item{kmip.NewTextString(kmip.TagUniqueIdentifier, obj.UID)}, nil
+}
+
+// objectKey authorizes the client to perform op with the object and derives its key. Destroyed
+// objects are treated as not found.
+func (s *KMIPServer) objectKey(ctx context.Context, client kmipClient, op kmip.Operation, uid string) (*kmipObject, []byte, error) {
+	state, err := s.guard.GetState(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting state: %w", err)
+	}
+	obj, err := s.objects.get(state.SeedEngine().TransactionSigningKey(), uid)
+	if err != nil {
+		return nil, nil, kmipObjectError(err)
+	}
+	if err := s.authorize(ctx, client, op, obj.Name); err != nil {
+		return nil, nil, err
+	}
+	if obj.Destroyed != nil {
+		return nil, nil, newKMIPError(kmip.ResultReasonItemNotFound, "object %s was destroyed", uid)
+	}
+	key, err := state.SeedEngine().DeriveTransitEngineKey(0, kmipKeyPrefix+obj.UID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("key derivation: %w", err)
+	}
+	if len(key)*8 < int(obj.Length) {
+		return nil, nil, fmt.Errorf("derived key too small, expected key length: %d bits", obj.Length)
+	}
+	return obj, key[:obj.Length/8], nil
+}
+
+// authorize authorizes the client to perform op with the keys of the given name. The decision is
+// logged for every request.
+func (s *KMIPServer) authorize(ctx context.Context, client kmipClient, op kmip.Operation, name string) error {
+	operation := kmipOperations[op]
+	rule, err := client.authorize(ctx, s.guard, name, operation.transit)
+	if err != nil {
+		s.logger.Warn("KMIP request denied", "key", name, "operation", operation.name, "principal", client.subject, "remoteAddr", client.remoteAddr, "err", err)
+		return newKMIPError(kmip.ResultReasonPermissionDenied, "unauthorized: %w", err)
+	}
+	s.logger.Info("KMIP request authorized", "key", name, "operation", operation.name, "principal", client.subject, "remoteAddr", client.remoteAddr, "rule", rule)
+	return nil
+}
+
+// recordEvent records a KMIP request to the audit log.
+func (s *KMIPServer) recordEvent(eventType auditlog.EventType, client kmipClient, obj *kmipObject) {
+	s.audit.Record(auditlog.Event{
+		Type:        eventType,
+		Actor:       client.subject,
+		PeerAddress: client.remoteAddr,
+		Details: map[string]string{
+			"name":              obj.Name,
+			"unique_identifier": obj.UID,
+		},
+	})
+}
+
+// kmipObjectError converts errors of kmipObjects to KMIP errors.
+func kmipObjectError(err error) error {
+	if errors.Is(err, errKMIPObjectNotFound) {
+		return newKMIPError(kmip.ResultReasonItemNotFound, "%w", err)
+	}
+	return err
+}
+
+// negotiateKMIPVersion returns the highest supported protocol version that isn't newer than the
+// version of the request.
+func negotiateKMIPVersion(header kmip.Item) (kmipVersion, error) {
+	versionItem, ok := header.Child(kmip.TagProtocolVersion)
+	if !ok {
+		return kmipVersion{}, newKMIPError(kmip.ResultReasonInvalidMessage, "missing protocol version")
+	}
+	requested, err := parseKMIPVersion(versionItem)
+	if err != nil {
+		return kmipVersion{}, err
+	}
+	for _, version := range supportedKMIPVersions {
+		if version.major < requested.major || (version.major == requested.major && version.minor <= requested.minor) {
+			return version, nil
+		}
+	}
+	return kmipVersion{}, newKMIPError(kmip.ResultReasonInvalidMessage, "protocol version %d.%d isn't supported", requested.major, requested.minor)
+}
+
+// discoverKMIPVersions returns the supported protocol versions that are also supported by the
+// client, or all supported versions if the client doesn't list any.
+func discoverKMIPVersions(payload kmip.Item) ([]kmip.Item, error) {
+	var clientVersions []kmipVersion
+	for _, item := range payload.ChildrenWithTag(kmip.TagProtocolVersion) {
+		version, err := parseKMIPVersion(item)
+		if err != nil {
+			return nil, err
+		}
+		clientVersions = append(clientVersions, version)
+	}
+	items := []kmip.Item{}
+	for _, version := range supportedKMIPVersions {
+		if len(clientVersions) > 0 && !slices.Contains(clientVersions, version) {
+			continue
+		}
+		items = append(items, version.item())
+	}
+	return items, nil
+}
+
+func parseKMIPVersion(item kmip.Item) (kmipVersion, error) {
+	major, err := requiredInteger(item, kmip.TagProtocolVersionMajor)
+	if err != nil {
+		return kmipVersion{}, err
+	}
+	minor, err := requiredInteger(item, kmip.TagProtocolVersionMinor)
+	if err != nil {
+		return kmipVersion{}, err
+	}
+	return kmipVersion{major: major, minor: minor}, nil
+}
+
+func (v kmipVersion) item() kmip.Item {
+	return kmip.NewStructure(kmip.TagProtocolVersion,
+		kmip.NewInteger(kmip.TagProtocolVersionMajor, v.major),
+		kmip.NewInteger(kmip.TagProtocolVersionMinor, v.minor),
+	)
+}
+
+// kmipResponse returns a response message with the given batch items.
+func kmipResponse(version kmipVersion, batchItems []kmip.Item) kmip.Item {
+	return kmip.NewStructure(kmip.TagResponseMessage, append([]kmip.Item{
+		kmip.NewStructure(kmip.TagResponseHeader,
+			version.item(),
+			kmip.NewDateTime(kmip.TagTimeStamp, time.Now()),
+			kmip.NewInteger(kmip.TagBatchCount, int32(len(batchItems))),
+		),
+	}, batchItems...)...)
+}
+
+// kmipMessageErrorResponse returns a response message for a request that couldn't be processed
+// at all, with a single failed batch item.
+func kmipMessageErrorResponse(version kmipVersion, err error) kmip.Item {
+	reason := kmip.ResultReasonGeneralFailure
+	var kErr *kmipError
+	if errors.As(err, &kErr) {
+		reason = kErr.reason
+	}
+	return kmipResponse(version, []kmip.Item{
+		kmip.NewStructure(kmip.TagBatchItem,
+			kmip.NewEnumeration(kmip.TagResultStatus, kmip.ResultStatusOperationFailed),
+			kmip.NewEnumeration(kmip.TagResultReason, reason),
+			kmip.NewTextString(kmip.TagResultMessage, err.Error()),
+		),
+	})
+}
+
+// kmipAttributes holds the attributes of a Create or Locate request that the server supports.
+// Fields of attributes that aren't set are zero.
+type kmipAttributes struct {
+	name       string
+	objectType kmip.ObjectType
+	algorithm  kmip.CryptographicAlgorithm
+	length     int32
+}
+
+// parseKMIPAttributes reads the attributes of a request payload. It supports the Attribute
+// structures of KMIP 1.x, directly in the payload or in a TemplateAttribute, and the Attributes
+// structure of KMIP 2.x. Unknown attributes are ignored.
+func parseKMIPAttributes(payload kmip.Item) (kmipAttributes, error) {
+	var attrs kmipAttributes
+	set := func(tag kmip.Tag, value kmip.Item) error {
+		var err error
+		switch tag {
+		case kmip.TagName:
+			if value.Type == kmip.TypeTextString {
+				// Some clients send the name value without the Name structure.
+				attrs.name, err = value.TextString()
+			} else {
+				attrs.name, err = requiredText(value, kmip.TagNameValue)
+			}
+		case kmip.TagObjectType:
+			var v uint32
+			v, err = value.Enumeration()
+			attrs.objectType = kmip.ObjectType(v)
+		case kmip.TagCryptographicAlgorithm:
+			var v uint32
+			v, err = value.Enumeration()
+			attrs.algorithm = kmip.CryptographicAlgorithm(v)
+		case kmip.TagCryptographicLength:
+			attrs.length, err = value.Integer()
+		}
+		if err != nil {
+			return newKMIPError(kmip.ResultReasonInvalidField, "attribute %#06x: %w", uint32(tag), err)
+		}
+		return nil
+	}
+
+	attributes := payload.ChildrenWithTag(kmip.TagAttribute)
+	if template, ok := payload.Child(kmip.TagTemplateAttribute); ok {
+		attributes = append(attributes, template.ChildrenWithTag(kmip.TagAttribute)...)
+	}
+	for _, attribute := range attributes {
+		attrName, err := requiredText(attribute, kmip.TagAttributeName)
+		if err != nil {
+			return kmipAttributes{}, err
+		}
+		value, ok := attribute.Child(kmip.TagAttributeValue)
+		if !ok {
+			return kmipAttributes{}, newKMIPError(kmip.ResultReasonMissingData, "attribute %q has no value", attrName)
+		}
+		tag, ok := kmipAttributeTags[attrName]
+		if !ok {
+			continue
+		}
+		if err := set(tag, value); err != nil {
+			return kmipAttributes{}, err
+		}
+	}
+	if v2Attributes, ok := payload.Child(kmip.TagAttributes); ok {
+		for _, value := range v2Attributes.Children() {
+			if err := set(value.Tag, value); err != nil {
+				return kmipAttributes{}, err
+			}
+		}
+	}
+	// KMIP 2.x Locate requests may also set the object type directly in the payload.
+	if item, ok := payload.Child(kmip.TagObjectType); ok && attrs.objectType == 0 {
+		if err := set(kmip.TagObjectType, item); err != nil {
+			return kmipAttributes{}, err
+		}
+	}
+	return attrs, nil
+}
+
+// kmipAttributeTags maps the KMIP 1.x attribute names the server supports to the tags used for
+// them in KMIP 2.x.
+var kmipAttributeTags = map[string]kmip.Tag{
+	"Name":                    kmip.TagName,
+	"Object Type":             kmip.TagObjectType,
+	"Cryptographic Algorithm": kmip.TagCryptographicAlgorithm,
+	"Cryptographic Length":    kmip.TagCryptographicLength,
+}
+
+// checkKMIPCryptographicParameters checks that the cryptographic parameters of an Encrypt or
+// Decrypt request, if any, select AES-GCM.
+func checkKMIPCryptographicParameters(payload kmip.Item) error {
+	params, ok := payload.Child(kmip.TagCryptographicParameters)
+	if !ok {
+		return nil
+	}
+	if item, ok := params.Child(kmip.TagBlockCipherMode); ok {
+		mode, err := item.Enumeration()
+		if err != nil {
+			return newKMIPError(kmip.ResultReasonInvalidField, "block cipher mode: %w", err)
+		}
+		if kmip.BlockCipherMode(mode) != kmip.BlockCipherModeGCM {
+			return newKMIPError(kmip.ResultReasonFeatureNotSupported, "block cipher mode %d isn't supported, only GCM is", mode)
+		}
+	}
+	if item, ok := params.Child(kmip.TagCryptographicAlgorithm); ok {
+		alg, err := item.Enumeration()
+		if err != nil {
+			return newKMIPError(kmip.ResultReasonInvalidField, "cryptographic algorithm: %w", err)
+		}
+		if kmip.CryptographicAlgorithm(alg) != kmip.CryptographicAlgorithmAES {
+			return newKMIPError(kmip.ResultReasonFeatureNotSupported, "cryptographic algorithm %d isn't supported, only AES is", alg)
+		}
+	}
+	return nil
+}
+
+func requiredText(item kmip.Item, tag kmip.Tag) (string, error) {
+	child, ok := item.Child(tag)
+	if !ok {
+		return "", newKMIPError(kmip.ResultReasonMissingData, "missing field %#06x", uint32(tag))
+	}
+	v, err := child.TextString()
+	if err != nil {
+		return "", newKMIPError(kmip.ResultReasonInvalidField, "%w", err)
+	}
+	return v, nil
+}
+
+func optionalText(item kmip.Item, tag kmip.Tag) (string, error) {
+	if _, ok := item.Child(tag); !ok {
+		return "", nil
+	}
+	return requiredText(item, tag)
+}
+
+func requiredBytes(item kmip.Item, tag kmip.Tag) ([]byte, error) {
+	child, ok := item.Child(tag)
+	if !ok {
+		return nil, newKMIPError(kmip.ResultReasonMissingData, "missing field %#06x", uint32(tag))
+	}
+	v, err := child.ByteString()
+	if err != nil {
+		return nil, newKMIPError(kmip.ResultReasonInvalidField, "%w", err)
+	}
+	return v, nil
+}
+
+func optionalBytes(item kmip.Item, tag kmip.Tag) ([]byte, error) {
+	if _, ok := item.Child(tag); !ok {
+		return nil, nil
+	}
+	return requiredBytes(item, tag)
+}
+
+func requiredInteger(item kmip.Item, tag kmip.Tag) (int32, error) {
+	child, ok := item.Child(tag)
+	if !ok {
+		return 0, newKMIPError(kmip.ResultReasonMissingData, "missing field %#06x", uint32(tag))
+	}
+	v, err := child.Integer()
+	if err != nil {
+		return 0, newKMIPError(kmip.ResultReasonInvalidField, "%w", err)
+	}
+	return v, nil
+}
+
+func requiredEnumeration(item kmip.Item, tag kmip.Tag) (uint32, error) {
+	child, ok := item.Child(tag)
+	if !ok {
+		return 0, newKMIPError(kmip.ResultReasonMissingData, "missing field %#06x", uint32(tag))
+	}
+	v, err := child.Enumeration()
+	if err != nil {
+		return 0, newKMIPError(kmip.ResultReasonInvalidField, "%w", err)
+	}
+	return v, nil
+}
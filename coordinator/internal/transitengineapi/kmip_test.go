@@ -0,0 +1,549 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package transitengine
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/edgelesssys/contrast/coordinator/internal/stateguard"
+	"github.com/edgelesssys/contrast/internal/constants"
+	"github.com/edgelesssys/contrast/internal/history/aferostore"
+	"github.com/edgelesssys/contrast/internal/kmip"
+	"github.com/edgelesssys/contrast/internal/manifest"
+	"github.com/edgelesssys/contrast/internal/seedengine"
+	"github.com/edgelesssys/contrast/internal/testkeys"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKMIPLifecycle(t *testing.T) {
+	assert := assert.New(t)
+	s := newTestKMIPServer(t, &manifest.Manifest{})
+	client := kmipClient{peerIdentity: peerIdentity{workloadSecretID: "db"}}
+
+	// Create a key with the KMIP 1.x attribute encoding.
+	resp := doKMIP(t, s, client, kmipVersion{1, 4}, kmip.OperationCreate,
+		kmip.NewEnumeration(kmip.TagObjectType, kmip.ObjectTypeSymmetricKey),
+		kmip.NewStructure(kmip.TagTemplateAttribute,
+			kmipAttribute("Cryptographic Algorithm", kmip.NewEnumeration(kmip.TagAttributeValue, kmip.CryptographicAlgorithmAES)),
+			kmipAttribute("Cryptographic Length", kmip.NewInteger(kmip.TagAttributeValue, 256)),
+			kmipAttribute("Name", kmip.NewStructure(kmip.TagAttributeValue,
+				kmip.NewTextString(kmip.TagNameValue, "db"),
+				kmip.NewEnumeration(kmip.TagNameType, kmip.NameTypeUninterpretedTextString),
+			)),
+		),
+	)
+	requireKMIPSuccess(t, resp)
+	uid := kmipPayloadText(t, resp, kmip.TagUniqueIdentifier)
+
+	resp = doKMIP(t, s, client, kmipVersion{1, 4}, kmip.OperationGet, kmip.NewTextString(kmip.TagUniqueIdentifier, uid))
+	requireKMIPSuccess(t, resp)
+	key := kmipKeyMaterial(t, resp)
+	assert.Len(key, 32)
+
+	// Get returns the same key again.
+	resp = doKMIP(t, s, client, kmipVersion{1, 4}, kmip.OperationGet, kmip.NewTextString(kmip.TagUniqueIdentifier, uid))
+	requireKMIPSuccess(t, resp)
+	assert.Equal(key, kmipKeyMaterial(t, resp))
+
+	resp = doKMIP(t, s, client, kmipVersion{1, 4}, kmip.OperationEncrypt,
+		kmip.NewTextString(kmip.TagUniqueIdentifier, uid),
+		kmip.NewStructure(kmip.TagCryptographicParameters, kmip.NewEnumeration(kmip.TagBlockCipherMode, kmip.BlockCipherModeGCM)),
+		kmip.NewByteString(kmip.TagData, []byte("secret")),
+		kmip.NewByteString(kmip.TagAuthenticatedEncryptionAdditionalData, []byte("context")),
+	)
+	requireKMIPSuccess(t, resp)
+	ciphertext := kmipPayloadBytes(t, resp, kmip.TagData)
+	nonce := kmipPayloadBytes(t, resp, kmip.TagIVCounterNonce)
+	tag := kmipPayloadBytes(t, resp, kmip.TagAuthenticatedEncryptionTag)
+	assert.NotEqual([]byte("secret"), ciphertext)
+
+	decrypt := func(associatedData string) kmip.Item {
+		return doKMIP(t, s, client, kmipVersion{1, 4}, kmip.OperationDecrypt,
+			kmip.NewTextString(kmip.TagUniqueIdentifier, uid),
+			kmip.NewByteString(kmip.TagData, ciphertext),
+			kmip.NewByteString(kmip.TagIVCounterNonce, nonce),
+			kmip.NewByteString(kmip.TagAuthenticatedEncryptionTag, tag),
+			kmip.NewByteString(kmip.TagAuthenticatedEncryptionAdditionalData, []byte(associatedData)),
+		)
+	}
+	resp = decrypt("context")
+	requireKMIPSuccess(t, resp)
+	assert.Equal([]byte("secret"), kmipPayloadBytes(t, resp, kmip.TagData))
+	assertKMIPFailure(t, decrypt("other context"), kmip.ResultReasonCryptographicFailure)
+
+	// Locate without a name finds the keys of the client's WorkloadSecretID.
+	resp = doKMIP(t, s, client, kmipVersion{1, 4}, kmip.OperationLocate)
+	requireKMIPSuccess(t, resp)
+	assert.Equal(uid, kmipPayloadText(t, resp, kmip.TagUniqueIdentifier))
+
+	resp = doKMIP(t, s, client, kmipVersion{1, 4}, kmip.OperationDestroy, kmip.NewTextString(kmip.TagUniqueIdentifier, uid))
+	requireKMIPSuccess(t, resp)
+
+	// The destroyed key can't be used anymore, and isn't located.
+	assertKMIPFailure(t, doKMIP(t, s, client, kmipVersion{1, 4}, kmip.OperationGet, kmip.NewTextString(kmip.TagUniqueIdentifier, uid)), kmip.ResultReasonItemNotFound)
+	assertKMIPFailure(t, decrypt("context"), kmip.ResultReasonItemNotFound)
+	assertKMIPFailure(t, doKMIP(t, s, client, kmipVersion{1, 4}, kmip.OperationDestroy, kmip.NewTextString(kmip.TagUniqueIdentifier, uid)), kmip.ResultReasonItemNotFound)
+	resp = doKMIP(t, s, client, kmipVersion{1, 4}, kmip.OperationLocate)
+	requireKMIPSuccess(t, resp)
+	_, ok := kmipPayload(t, resp).Child(kmip.TagUniqueIdentifier)
+	assert.False(ok)
+
+	// A new key with the same name is independent of the destroyed one.
+	resp = doKMIP(t, s, client, kmipVersion{2, 0}, kmip.OperationCreate,
+		kmip.NewEnumeration(kmip.TagObjectType, kmip.ObjectTypeSymmetricKey),
+		kmip.NewStructure(kmip.TagAttributes,
+			kmip.NewEnumeration(kmip.TagCryptographicAlgorithm, kmip.CryptographicAlgorithmAES),
+			kmip.NewInteger(kmip.TagCryptographicLength, 128),
+		),
+	)
+	requireKMIPSuccess(t, resp)
+	newUID := kmipPayloadText(t, resp, kmip.TagUniqueIdentifier)
+	assert.NotEqual(uid, newUID)
+	resp = doKMIP(t, s, client, kmipVersion{2, 0}, kmip.OperationGet, kmip.NewTextString(kmip.TagUniqueIdentifier, newUID))
+	requireKMIPSuccess(t, resp)
+	assert.Len(kmipKeyMaterial(t, resp), 16)
+	assert.NotEqual(key[:16], kmipKeyMaterial(t, resp))
+}
+
+func TestKMIPAuthorization(t *testing.T) {
+	m := &manifest.Manifest{
+		TransitKeys: map[string]manifest.TransitKey{
+			"shared": {Grants: []manifest.TransitKeyGrant{
+				{
+					WorkloadSecretIDs: []string{"producer"},
+					Operations:        []manifest.TransitOperation{manifest.TransitOperationDatakey, manifest.TransitOperationEncrypt},
+				},
+				{
+					WorkloadSecretIDs: []string{"consumer"},
+					Operations:        []manifest.TransitOperation{manifest.TransitOperationDecrypt, manifest.TransitOperationRead},
+				},
+			}},
+		},
+	}
+	s := newTestKMIPServer(t, m)
+	producer := kmipClient{peerIdentity: peerIdentity{workloadSecretID: "producer"}}
+	resp := doKMIP(t, s, producer, kmipVersion{1, 4}, kmip.OperationCreate,
+		kmip.NewEnumeration(kmip.TagObjectType, kmip.ObjectTypeSymmetricKey),
+		kmipAttribute("Name", kmip.NewStructure(kmip.TagAttributeValue, kmip.NewTextString(kmip.TagNameValue, "shared"))),
+	)
+	requireKMIPSuccess(t, resp)
+	uid := kmipPayloadText(t, resp, kmip.TagUniqueIdentifier)
+
+	testCases := map[string]struct {
+		workloadSecretID string
+		op               kmip.Operation
+		payload          []kmip.Item
+		wantReason       kmip.ResultReason
+	}{
+		"producer gets key": {
+			workloadSecretID: "producer",
+			op:               kmip.OperationGet,
+		},
+		"consumer can't get key": {
+			workloadSecretID: "consumer",
+			op:               kmip.OperationGet,
+			wantReason:       kmip.ResultReasonPermissionDenied,
+		},
+		"producer encrypts": {
+			workloadSecretID: "producer",
+			op:               kmip.OperationEncrypt,
+			payload:          []kmip.Item{kmip.NewByteString(kmip.TagData, []byte("secret"))},
+		},
+		"consumer can't encrypt": {
+			workloadSecretID: "consumer",
+			op:               kmip.OperationEncrypt,
+			payload:          []kmip.Item{kmip.NewByteString(kmip.TagData, []byte("secret"))},
+			wantReason:       kmip.ResultReasonPermissionDenied,
+		},
+		"consumer locates": {
+			workloadSecretID: "consumer",
+			op:               kmip.OperationLocate,
+			payload:          []kmip.Item{kmipAttribute("Name", kmip.NewStructure(kmip.TagAttributeValue, kmip.NewTextString(kmip.TagNameValue, "shared")))},
+		},
+		"producer can't destroy": {
+			workloadSecretID: "producer",
+			op:               kmip.OperationDestroy,
+			wantReason:       kmip.ResultReasonPermissionDenied,
+		},
+		"other workload can't get key": {
+			workloadSecretID: "shared-other",
+			op:               kmip.OperationGet,
+			wantReason:       kmip.ResultReasonPermissionDenied,
+		},
+		"other workload can't create key": {
+			workloadSecretID: "consumer",
+			op:               kmip.OperationCreate,
+			payload: []kmip.Item{
+				kmip.NewEnumeration(kmip.TagObjectType, kmip.ObjectTypeSymmetricKey),
+				kmipAttribute("Name", kmip.NewStructure(kmip.TagAttributeValue, kmip.NewTextString(kmip.TagNameValue, "shared"))),
+			},
+			wantReason: kmip.ResultReasonPermissionDenied,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			payload := tc.payload
+			if tc.op != kmip.OperationCreate && tc.op != kmip.OperationLocate {
+				payload = append([]kmip.Item{kmip.NewTextString(kmip.TagUniqueIdentifier, uid)}, payload...)
+			}
+			client := kmipClient{peerIdentity: peerIdentity{workloadSecretID: tc.workloadSecretID}}
+			resp := doKMIP(t, s, client, kmipVersion{1, 4}, tc.op, payload...)
+			if tc.wantReason != 0 {
+				assertKMIPFailure(t, resp, tc.wantReason)
+				return
+			}
+			requireKMIPSuccess(t, resp)
+		})
+	}
+}
+
+func TestKMIPBatch(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	s := newTestKMIPServer(t, &manifest.Manifest{})
+	client := kmipClient{peerIdentity: peerIdentity{workloadSecretID: "db"}}
+
+	create := kmipBatchItem(kmip.OperationCreate, kmip.NewEnumeration(kmip.TagObjectType, kmip.ObjectTypeSymmetricKey))
+	// The Get item doesn't specify a unique identifier, so it uses the one of the created key.
+	get := kmipBatchItem(kmip.OperationGet)
+	unsupported := kmipBatchItem(kmip.Operation(0x03))
+
+	testCases := map[string]struct {
+		option      kmip.BatchErrorContinuationOption
+		items       []kmip.Item
+		wantReasons []kmip.ResultReason
+	}{
+		"create and get": {
+			items:       []kmip.Item{create, get},
+			wantReasons: []kmip.ResultReason{0, 0},
+		},
+		"stop on error": {
+			items:       []kmip.Item{create, unsupported, get},
+			wantReasons: []kmip.ResultReason{0, kmip.ResultReasonOperationNotSupported},
+		},
+		"continue on error": {
+			option:      kmip.BatchErrorContinuationOptionContinue,
+			items:       []kmip.Item{create, unsupported, get},
+			wantReasons: []kmip.ResultReason{0, kmip.ResultReasonOperationNotSupported, 0},
+		},
+		"get without unique identifier": {
+			items:       []kmip.Item{get},
+			wantReasons: []kmip.ResultReason{kmip.ResultReasonMissingData},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			header := []kmip.Item{kmipVersion{1, 4}.item(), kmip.NewInteger(kmip.TagBatchCount, int32(len(tc.items)))}
+			if tc.option != 0 {
+				header = append(header, kmip.NewEnumeration(kmip.TagBatchErrorContinuationOption, tc.option))
+			}
+			req := kmip.NewStructure(kmip.TagRequestMessage, append([]kmip.Item{kmip.NewStructure(kmip.TagRequestHeader, header...)}, tc.items...)...)
+
+			resp := roundTripKMIP(t, s, client, req)
+			results := resp.ChildrenWithTag(kmip.TagBatchItem)
+			require.Len(results, len(tc.wantReasons))
+			for i, wantReason := range tc.wantReasons {
+				if wantReason == 0 {
+					requireKMIPSuccess(t, results[i])
+				} else {
+					assertKMIPFailure(t, results[i], wantReason)
+				}
+			}
+			if len(results) == 2 && tc.wantReasons[1] == 0 {
+				assert.Len(kmipKeyMaterial(t, results[1]), 32)
+			}
+		})
+	}
+}
+
+func TestKMIPVersions(t *testing.T) {
+	s := newTestKMIPServer(t, &manifest.Manifest{})
+
+	testCases := map[string]struct {
+		requestVersion kmipVersion
+		clientVersions []kmipVersion
+		wantVersion    kmipVersion
+		wantVersions   []kmipVersion
+		wantErr        bool
+	}{
+		"all versions": {
+			requestVersion: kmipVersion{1, 0},
+			wantVersion:    kmipVersion{1, 0},
+			wantVersions:   supportedKMIPVersions,
+		},
+		"common versions": {
+			requestVersion: kmipVersion{1, 2},
+			clientVersions: []kmipVersion{{2, 1}, {1, 4}, {1, 2}},
+			wantVersion:    kmipVersion{1, 2},
+			wantVersions:   []kmipVersion{{1, 4}, {1, 2}},
+		},
+		"newer minor version": {
+			requestVersion: kmipVersion{2, 1},
+			wantVersion:    kmipVersion{2, 0},
+			wantVersions:   supportedKMIPVersions,
+		},
+		"newer major version": {
+			requestVersion: kmipVersion{3, 0},
+			wantVersion:    kmipVersion{2, 0},
+			wantVersions:   supportedKMIPVersions,
+		},
+		"no common version": {
+			requestVersion: kmipVersion{1, 4},
+			clientVersions: []kmipVersion{{2, 1}},
+			wantVersion:    kmipVersion{1, 4},
+		},
+		"unsupported version": {
+			requestVersion: kmipVersion{0, 9},
+			wantErr:        true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+
+			var payload []kmip.Item
+			for _, version := range tc.clientVersions {
+				payload = append(payload, version.item())
+			}
+			req := kmipRequest(tc.requestVersion, kmipBatchItem(kmip.OperationDiscoverVersions, payload...))
+			resp := roundTripKMIP(t, s, kmipClient{}, req)
+			results := resp.ChildrenWithTag(kmip.TagBatchItem)
+			require.Len(results, 1)
+			if tc.wantErr {
+				assertKMIPFailure(t, results[0], kmip.ResultReasonInvalidMessage)
+				return
+			}
+			requireKMIPSuccess(t, results[0])
+
+			header, ok := resp.Child(kmip.TagResponseHeader)
+			require.True(ok)
+			versionItem, ok := header.Child(kmip.TagProtocolVersion)
+			require.True(ok)
+			version, err := parseKMIPVersion(versionItem)
+			require.NoError(err)
+			assert.Equal(tc.wantVersion, version)
+
+			var versions []kmipVersion
+			for _, item := range kmipPayload(t, results[0]).ChildrenWithTag(kmip.TagProtocolVersion) {
+				version, err := parseKMIPVersion(item)
+				require.NoError(err)
+				versions = append(versions, version)
+			}
+			assert.Equal(tc.wantVersions, versions)
+		})
+	}
+}
+
+func TestKMIPObjects(t *testing.T) {
+	signingKey := testkeys.ECDSA(t)
+
+	testCases := map[string]struct {
+		// tamper modifies the stored record of the object after it was destroyed.
+		tamper  func(t *testing.T, store *aferostore.AferoStore, oldData []byte, uid string)
+		wantErr bool
+	}{
+		"untouched": {
+			tamper: func(*testing.T, *aferostore.AferoStore, []byte, string) {},
+		},
+		"deleted": {
+			tamper: func(t *testing.T, store *aferostore.AferoStore, _ []byte, uid string) {
+				require.NoError(t, store.Delete(kmipObjectKey(uid)))
+			},
+			wantErr: true,
+		},
+		"revived": {
+			tamper: func(t *testing.T, store *aferostore.AferoStore, oldData []byte, uid string) {
+				require.NoError(t, store.Set(kmipObjectKey(uid), oldData))
+			},
+			wantErr: true,
+		},
+		"record of other object": {
+			tamper: func(t *testing.T, store *aferostore.AferoStore, _ []byte, uid string) {
+				other, err := newKMIPObjects(store, slog.New(slog.DiscardHandler)).create(signingKey, "db", 256)
+				require.NoError(t, err)
+				otherData, err := store.Get(kmipObjectKey(other.UID))
+				require.NoError(t, err)
+				require.NoError(t, store.Set(kmipObjectKey(uid), otherData))
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+			store := aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()})
+			o := newKMIPObjects(store, slog.New(slog.DiscardHandler))
+
+			obj, err := o.create(signingKey, "db", 256)
+			require.NoError(err)
+			oldData, err := store.Get(kmipObjectKey(obj.UID))
+			require.NoError(err)
+			_, err = o.destroy(signingKey, obj.UID)
+			require.NoError(err)
+
+			tc.tamper(t, store, oldData, obj.UID)
+
+			// A new instance, like a restarted Coordinator, doesn't rely on a cache.
+			obj, err = newKMIPObjects(store, slog.New(slog.DiscardHandler)).get(signingKey, obj.UID)
+			if tc.wantErr {
+				require.Error(err)
+				return
+			}
+			require.NoError(err)
+			assert.NotNil(obj.Destroyed)
+			objs, err := newKMIPObjects(store, slog.New(slog.DiscardHandler)).locate(signingKey, "db")
+			require.NoError(err)
+			assert.Empty(objs)
+		})
+	}
+}
+
+func TestMigrateKMIPObjects(t *testing.T) {
+	require := require.New(t)
+	signingKey := testkeys.ECDSA(t)
+	src := aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()})
+	dst := aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()})
+
+	migrated, err := MigrateKMIPObjects(src, dst)
+	require.NoError(err)
+	require.False(migrated, "empty record sets must not be migrated")
+
+	obj, err := newKMIPObjects(src, slog.New(slog.DiscardHandler)).create(signingKey, "db", 256)
+	require.NoError(err)
+	migrated, err = MigrateKMIPObjects(src, dst)
+	require.NoError(err)
+	require.True(migrated)
+
+	objs, err := newKMIPObjects(dst, slog.New(slog.DiscardHandler)).locate(signingKey, "db")
+	require.NoError(err)
+	require.Len(objs, 1)
+	require.Equal(obj.UID, objs[0].UID)
+
+	// The destination's records are never overwritten.
+	migrated, err = MigrateKMIPObjects(src, dst)
+	require.NoError(err)
+	require.False(migrated)
+}
+
+func newTestKMIPServer(t *testing.T, m *manifest.Manifest) *KMIPServer {
+	t.Helper()
+	seedEngine, err := seedengine.New(make([]byte, constants.SecretSeedSize), make([]byte, constants.SecretSeedSaltSize))
+	require.NoError(t, err)
+	return &KMIPServer{
+		guard:   &fakeStateGuard{state: stateguard.NewStateForTest(seedEngine, m, nil, nil)},
+		objects: newKMIPObjects(aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()}), slog.New(slog.DiscardHandler)),
+		logger:  slog.New(slog.DiscardHandler),
+	}
+}
+
+func kmipAttribute(name string, value kmip.Item) kmip.Item {
+	return kmip.NewStructure(kmip.TagAttribute, kmip.NewTextString(kmip.TagAttributeName, name), value)
+}
+
+func kmipBatchItem(op kmip.Operation, payload ...kmip.Item) kmip.Item {
+	return kmip.NewStructure(kmip.TagBatchItem,
+		kmip.NewEnumeration(kmip.TagOperation, op),
+		kmip.NewStructure(kmip.TagRequestPayload, payload...),
+	)
+}
+
+func kmipRequest(version kmipVersion, batchItems ...kmip.Item) kmip.Item {
+	return kmip.NewStructure(kmip.TagRequestMessage, append([]kmip.Item{
+		kmip.NewStructure(kmip.TagRequestHeader,
+			version.item(),
+			kmip.NewInteger(kmip.TagBatchCount, int32(len(batchItems))),
+		),
+	}, batchItems...)...)
+}
+
+// roundTripKMIP encodes the request, lets the server handle it and decodes the response, like a
+// client would.
+func roundTripKMIP(t *testing.T, s *KMIPServer, client kmipClient, req kmip.Item) kmip.Item {
+	t.Helper()
+	reqData, err := kmip.Marshal(req)
+	require.NoError(t, err)
+	req, err = kmip.Unmarshal(reqData)
+	require.NoError(t, err)
+	respData, err := kmip.Marshal(s.handleMessage(context.Background(), client, req))
+	require.NoError(t, err)
+	resp, err := kmip.Unmarshal(respData)
+	require.NoError(t, err)
+	require.Equal(t, kmip.TagResponseMessage, resp.Tag)
+	return resp
+}
+
+// doKMIP sends a request with a single operation and returns the response batch item.
+func doKMIP(t *testing.T, s *KMIPServer, client kmipClient, version kmipVersion, op kmip.Operation, payload ...kmip.Item) kmip.Item {
+	t.Helper()
+	resp := roundTripKMIP(t, s, client, kmipRequest(version, kmipBatchItem(op, payload...)))
+	results := resp.ChildrenWithTag(kmip.TagBatchItem)
+	require.Len(t, results, 1)
+	return results[0]
+}
+
+func requireKMIPSuccess(t *testing.T, result kmip.Item) {
+	t.Helper()
+	status, err := requiredEnumeration(result, kmip.TagResultStatus)
+	require.NoError(t, err)
+	message, _ := optionalText(result, kmip.TagResultMessage)
+	require.Equal(t, kmip.ResultStatusSuccess, kmip.ResultStatus(status), message)
+}
+
+func assertKMIPFailure(t *testing.T, result kmip.Item, wantReason kmip.ResultReason) {
+	t.Helper()
+	status, err := requiredEnumeration(result, kmip.TagResultStatus)
+	require.NoError(t, err)
+	assert.Equal(t, kmip.ResultStatusOperationFailed, kmip.ResultStatus(status))
+	reason, err := requiredEnumeration(result, kmip.TagResultReason)
+	require.NoError(t, err)
+	assert.Equal(t, wantReason, kmip.ResultReason(reason))
+}
+
+func kmipPayload(t *testing.T, result kmip.Item) kmip.Item {
+	t.Helper()
+	payload, ok := result.Child(kmip.TagResponsePayload)
+	require.True(t, ok)
+	return payload
+}
+
+func kmipPayloadText(t *testing.T, result kmip.Item, tag kmip.Tag) string {
+	t.Helper()
+	v, err := requiredText(kmipPayload(t, result), tag)
+	require.NoError(t, err)
+	return v
+}
+
+func kmipPayloadBytes(t *testing.T, result kmip.Item, tag kmip.Tag) []byte {
+	t.Helper()
+	v, err := requiredBytes(kmipPayload(t, result), tag)
+	require.NoError(t, err)
+	return v
+}
+
+func kmipKeyMaterial(t *testing.T, result kmip.Item) []byte {
+	t.Helper()
+	item := kmipPayload(t, result)
+	for _, tag := range []kmip.Tag{kmip.TagSymmetricKey, kmip.TagKeyBlock, kmip.TagKeyValue} {
+		var ok bool
+		item, ok = item.Child(tag)
+		require.True(t, ok)
+	}
+	v, err := requiredBytes(item, kmip.TagKeyMaterial)
+	require.NoError(t, err)
+	return v
+}
+
+func kmipObjectKey(uid string) string {
+	return kmipObjectRecordPrefix + "/" + uid
+}
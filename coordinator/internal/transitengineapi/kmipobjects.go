@@ -0,0 +1,252 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package transitengine
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/edgelesssys/contrast/internal/cryptohelpers"
+	"github.com/edgelesssys/contrast/internal/history"
+	"golang.org/x/sync/errgroup"
+	"k8s.io/utils/clock"
+)
+
+const (
+	// kmipObjectRecordPrefix is the store key prefix of the KMIP object records, which are named
+	// after the unique identifier of the object.
+	kmipObjectRecordPrefix = "kmipobjects"
+	// kmipNameRecordPrefix is the store key prefix of the records that hold the unique identifiers
+	// of the KMIP objects with the same name. They are named after the hash of the name.
+	kmipNameRecordPrefix = "kmipnames"
+	// kmipKeyPrefix is prepended to the unique identifier of a KMIP object to get the name its key
+	// is derived from. Transit engine key names can't contain a slash, so the keys are independent.
+	kmipKeyPrefix = "kmip/"
+	// kmipUIDSize is the number of random bytes of a unique identifier.
+	kmipUIDSize = 16
+)
+
+// errKMIPObjectNotFound is returned for unique identifiers that don't refer to a KMIP object.
+var errKMIPObjectNotFound = errors.New("KMIP object not found")
+
+// kmipObject holds the metadata of a symmetric key served over KMIP. The key itself is derived
+// from the secret seed and the unique identifier, so it's never stored.
+type kmipObject struct {
+	// UID is the unique identifier of the object, which binds the record to the object.
+	UID string `json:"uid"`
+	// Name is the name of the transit engine key the object belongs to. Access to the object is
+	// authorized like access to the named key.
+	Name string `json:"name"`
+	// Length is the length of the key in bits.
+	Length int32 `json:"length"`
+	// Created is the time the object was created.
+	Created time.Time `json:"created"`
+	// Destroyed is the time the object was destroyed. Destroyed objects are kept as tombstones,
+	// because the key could be derived again.
+	Destroyed *time.Time `json:"destroyed,omitempty"`
+}
+
+// kmipName holds the unique identifiers of the KMIP objects with the same name, in the order of
+// their creation.
+type kmipName struct {
+	Name string   `json:"name"`
+	UIDs []string `json:"uids"`
+}
+
+// kmipObjects persists the records of KMIP objects as signed records in the history store.
+//
+// The records are signed with the transaction signing key, because the store isn't trusted, and
+// protected against rollback and deletion by the index of their record set, see history.Records.
+// Replaying an older object record would revive a destroyed key.
+type kmipObjects struct {
+	objects *history.Records
+	names   *history.Records
+	clock   clock.Clock
+}
+
+func newKMIPObjects(store history.Store, logger *slog.Logger) *kmipObjects {
+	return &kmipObjects{
+		objects: history.NewRecords(store, kmipObjectRecordPrefix, logger),
+		names:   history.NewRecords(store, kmipNameRecordPrefix, logger),
+		clock:   clock.RealClock{},
+	}
+}
+
+// MigrateKMIPObjects copies the KMIP object records and their name records from src to dst. Each
+// record set is skipped if dst already has it or src has none, and the returned bool reports
+// whether a migration took place. An interrupted migration can safely be retried.
+func MigrateKMIPObjects(src, dst history.Store) (bool, error) {
+	var migrated bool
+	for _, prefix := range []string{kmipObjectRecordPrefix, kmipNameRecordPrefix} {
+		ok, err := history.MigrateRecords(src, dst, prefix)
+		if err != nil {
+			return false, fmt.Errorf("migrating KMIP %s: %w", prefix, err)
+		}
+		migrated = migrated || ok
+	}
+	return migrated, nil
+}
+
+// watch keeps the cached records up to date until the context is done, see history.Records.
+func (o *kmipObjects) watch(ctx context.Context) error {
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.Go(func() error { return o.objects.Watch(ctx) })
+	eg.Go(func() error { return o.names.Watch(ctx) })
+	return eg.Wait()
+}
+
+// create creates a new object with the given name and key length.
+func (o *kmipObjects) create(signingKey *ecdsa.PrivateKey, name string, length int32) (*kmipObject, error) {
+	uid, err := cryptohelpers.GenerateRandomBytes(kmipUIDSize)
+	if err != nil {
+		return nil, fmt.Errorf("generating unique identifier: %w", err)
+	}
+	obj := &kmipObject{
+		UID:     hex.EncodeToString(uid),
+		Name:    name,
+		Length:  length,
+		Created: o.clock.Now().UTC(),
+	}
+
+	// The object is stored before it's added to the name, so that Locate never returns an
+	// identifier without an object.
+	if err := o.objects.Update(signingKey, obj.UID, func(content []byte) ([]byte, error) {
+		if content != nil {
+			return nil, fmt.Errorf("KMIP object %s already exists", obj.UID)
+		}
+		return marshalKMIPRecord(obj)
+	}); err != nil {
+		return nil, fmt.Errorf("storing KMIP object: %w", err)
+	}
+	if err := o.names.Update(signingKey, kmipNameRecordName(name), func(content []byte) ([]byte, error) {
+		n := &kmipName{Name: name}
+		if content != nil {
+			var err error
+			if n, err = unmarshalKMIPName(content, name); err != nil {
+				return nil, err
+			}
+		}
+		n.UIDs = append(n.UIDs, obj.UID)
+		return marshalKMIPRecord(n)
+	}); err != nil {
+		return nil, fmt.Errorf("storing KMIP name: %w", err)
+	}
+	return obj, nil
+}
+
+// get returns the object with the given unique identifier, including destroyed objects.
+func (o *kmipObjects) get(signingKey *ecdsa.PrivateKey, uid string) (*kmipObject, error) {
+	if _, err := hex.DecodeString(uid); err != nil || len(uid) != 2*kmipUIDSize {
+		return nil, fmt.Errorf("%w: %s", errKMIPObjectNotFound, uid)
+	}
+	content, err := o.objects.Get(signingKey, uid)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", errKMIPObjectNotFound, uid)
+	} else if err != nil {
+		return nil, fmt.Errorf("getting KMIP object: %w", err)
+	}
+	return unmarshalKMIPObject(content, uid)
+}
+
+// locate returns the objects with the given name that aren't destroyed, oldest first.
+func (o *kmipObjects) locate(signingKey *ecdsa.PrivateKey, name string) ([]*kmipObject, error) {
+	content, err := o.names.Get(signingKey, kmipNameRecordName(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("getting KMIP name: %w", err)
+	}
+	n, err := unmarshalKMIPName(content, name)
+	if err != nil {
+		return nil, err
+	}
+	var objs []*kmipObject
+	for _, uid := range n.UIDs {
+		obj, err := o.get(signingKey, uid)
+		if err != nil {
+			return nil, err
+		}
+		if obj.Name != name {
+			return nil, fmt.Errorf("KMIP object %s belongs to name %q", uid, obj.Name)
+		}
+		if obj.Destroyed == nil {
+			objs = append(objs, obj)
+		}
+	}
+	return objs, nil
+}
+
+// destroy marks the object with the given unique identifier as destroyed. Destroying an object
+// again doesn't change it.
+func (o *kmipObjects) destroy(signingKey *ecdsa.PrivateKey, uid string) (*kmipObject, error) {
+	// Objects are never deleted, so an object that exists now still exists during the update.
+	obj, err := o.get(signingKey, uid)
+	if err != nil {
+		return nil, err
+	}
+	if obj.Destroyed != nil {
+		return obj, nil
+	}
+	err = o.objects.Update(signingKey, uid, func(content []byte) ([]byte, error) {
+		var err error
+		if obj, err = unmarshalKMIPObject(content, uid); err != nil {
+			return nil, err
+		}
+		if obj.Destroyed != nil {
+			// Another Coordinator destroyed the object in the meantime.
+			return content, nil
+		}
+		destroyed := o.clock.Now().UTC()
+		obj.Destroyed = &destroyed
+		return marshalKMIPRecord(obj)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("storing KMIP object: %w", err)
+	}
+	return obj, nil
+}
+
+// kmipNameRecordName returns the record name of the unique identifiers of the named objects.
+// Names may contain characters that aren't allowed in record names, so the record is named after
+// their hash.
+func kmipNameRecordName(name string) string {
+	return fmt.Sprintf("%x", history.Digest([]byte(name)))
+}
+
+func marshalKMIPRecord(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling KMIP record: %w", err)
+	}
+	return data, nil
+}
+
+func unmarshalKMIPObject(content []byte, uid string) (*kmipObject, error) {
+	var obj kmipObject
+	if err := json.Unmarshal(content, &obj); err != nil {
+		return nil, fmt.Errorf("unmarshaling KMIP object: %w", err)
+	}
+	if obj.UID != uid {
+		return nil, fmt.Errorf("KMIP object record belongs to object %s", obj.UID)
+	}
+	return &obj, nil
+}
+
+func unmarshalKMIPName(content []byte, name string) (*kmipName, error) {
+	var n kmipName
+	if err := json.Unmarshal(content, &n); err != nil {
+		return nil, fmt.Errorf("unmarshaling KMIP name: %w", err)
+	}
+	if n.Name != name {
+		return nil, fmt.Errorf("KMIP name record belongs to name %q", n.Name)
+	}
+	return &n, nil
+}
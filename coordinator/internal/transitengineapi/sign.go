@@ -0,0 +1,470 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package transitengine
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/edgelesssys/contrast/internal/auditlog"
+	"github.com/edgelesssys/contrast/internal/cryptohelpers"
+)
+
+// defaultSigningKeyType is the key type of signing keys if the request doesn't specify one.
+const defaultSigningKeyType = "ecdsa-p256"
+
+// signingKeyTypes maps the OpenBao names of the supported signing key types to their algorithms.
+var signingKeyTypes = map[string]cryptohelpers.KeyAlgorithm{
+	"ecdsa-p256": cryptohelpers.KeyAlgorithmECDSAP256,
+	"ecdsa-p384": cryptohelpers.KeyAlgorithmECDSAP384,
+	"ecdsa-p521": cryptohelpers.KeyAlgorithmECDSAP521,
+	"ed25519":    cryptohelpers.KeyAlgorithmEd25519,
+}
+
+type (
+	// hmacRequest holds the input to compute the HMAC of and the optional key version and algorithm.
+	hmacRequest struct {
+		Input      []byte `json:"input"`
+		KeyVersion uint32 `json:"key_version"`
+		Algorithm  string `json:"algorithm,omitempty"`
+	}
+	// hmacResponse holds the versioned HMAC.
+	hmacResponse struct {
+		HMAC       string `json:"hmac"`
+		KeyVersion uint32 `json:"key_version"`
+	}
+	// signRequest holds the input to sign and the optional key version, key type and hash options.
+	signRequest struct {
+		Input         []byte `json:"input"`
+		KeyVersion    uint32 `json:"key_version"`
+		KeyType       string `json:"key_type,omitempty"`
+		HashAlgorithm string `json:"hash_algorithm,omitempty"`
+		Prehashed     bool   `json:"prehashed,omitempty"`
+	}
+	// signResponse holds the versioned signature.
+	signResponse struct {
+		Signature  string `json:"signature"`
+		KeyVersion uint32 `json:"key_version"`
+	}
+	// verifyRequest holds the input and either the signature or the HMAC to verify.
+	verifyRequest struct {
+		Input         []byte `json:"input"`
+		Signature     string `json:"signature,omitempty"`
+		HMAC          string `json:"hmac,omitempty"`
+		KeyType       string `json:"key_type,omitempty"`
+		HashAlgorithm string `json:"hash_algorithm,omitempty"`
+		Algorithm     string `json:"algorithm,omitempty"`
+		Prehashed     bool   `json:"prehashed,omitempty"`
+	}
+	// verifyResponse holds the result of a verification.
+	verifyResponse struct {
+		Valid bool `json:"valid"`
+	}
+)
+
+func getHMACHandler(guard stateGuard, keys *Keyring, logger *slog.Logger, audit *auditlog.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		workloadSecretID := r.PathValue("name")
+		if workloadSecretID == "" {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{"Invalid URL format"},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		var hmacReq hmacRequest
+		if err := parseRequest(r, &hmacReq); err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{fmt.Sprintf("parsing hmac request: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		if err := checkHashAlgorithm(hmacReq.Algorithm); err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{err.Error()},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		metadata, err := getKeyMetadata(r.Context(), guard, keys, workloadSecretID)
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("reading key metadata: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		keyVersion, err := metadata.encryptionVersion(hmacReq.KeyVersion)
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{err.Error()},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		mac, err := computeHMAC(r, guard, keyVersion, workloadSecretID, hmacReq.Input)
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("computing hmac: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		recordTransitEvent(audit, auditlog.EventTransitHMAC, r, workloadSecretID, keyVersion)
+		hmacResp := hmacResponse{HMAC: formatVersioned(keyVersion, mac), KeyVersion: keyVersion}
+		if err = writeJSONResponse(w, hmacResp); err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("writing response: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+	}
+}
+
+func getSignHandler(guard stateGuard, keys *Keyring, logger *slog.Logger, audit *auditlog.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		workloadSecretID := r.PathValue("name")
+		if workloadSecretID == "" {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{"Invalid URL format"},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		var signReq signRequest
+		if err := parseRequest(r, &signReq); err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{fmt.Sprintf("parsing sign request: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		alg, err := signingAlgorithm(signReq.KeyType, signReq.HashAlgorithm, signReq.Prehashed, signReq.Input)
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{err.Error()},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		metadata, err := getKeyMetadata(r.Context(), guard, keys, workloadSecretID)
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("reading key metadata: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		keyVersion, err := metadata.encryptionVersion(signReq.KeyVersion)
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{err.Error()},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		key, err := deriveSigningKey(r, guard, alg, keyVersion, workloadSecretID)
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("key derivation: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		signature, err := signInput(key, signReq.Input, signReq.Prehashed)
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("signing: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		recordTransitEvent(audit, auditlog.EventTransitSign, r, workloadSecretID, keyVersion)
+		signResp := signResponse{Signature: formatVersioned(keyVersion, signature), KeyVersion: keyVersion}
+		if err = writeJSONResponse(w, signResp); err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("writing response: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+	}
+}
+
+func getVerifyHandler(guard stateGuard, keys *Keyring, logger *slog.Logger, audit *auditlog.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		workloadSecretID := r.PathValue("name")
+		if workloadSecretID == "" {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{"Invalid URL format"},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		var verifyReq verifyRequest
+		if err := parseRequest(r, &verifyReq); err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{fmt.Sprintf("parsing verify request: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		if (verifyReq.Signature == "") == (verifyReq.HMAC == "") {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{"exactly one of signature and hmac must be set"},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		var alg cryptohelpers.KeyAlgorithm
+		var err error
+		if verifyReq.HMAC != "" {
+			err = checkHashAlgorithm(verifyReq.Algorithm)
+		} else {
+			alg, err = signingAlgorithm(verifyReq.KeyType, verifyReq.HashAlgorithm, verifyReq.Prehashed, verifyReq.Input)
+		}
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{err.Error()},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		keyVersion, expected, err := parseVersioned(verifyReq.Signature + verifyReq.HMAC)
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{err.Error()},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		metadata, err := getKeyMetadata(r.Context(), guard, keys, workloadSecretID)
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("reading key metadata: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		if err := metadata.checkDecryptionVersion(keyVersion); err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{err.Error()},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		var valid bool
+		if verifyReq.HMAC != "" {
+			var mac []byte
+			mac, err = computeHMAC(r, guard, keyVersion, workloadSecretID, verifyReq.Input)
+			valid = err == nil && hmac.Equal(mac, expected)
+		} else {
+			var key crypto.Signer
+			key, err = deriveSigningKey(r, guard, alg, keyVersion, workloadSecretID)
+			valid = err == nil && verifyInput(key.Public(), verifyReq.Input, expected, verifyReq.Prehashed) == nil
+		}
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("key derivation: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		recordTransitEvent(audit, auditlog.EventTransitVerify, r, workloadSecretID, keyVersion)
+		if err = writeJSONResponse(w, verifyResponse{Valid: valid}); err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("writing response: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+	}
+}
+
+// computeHMAC computes the HMAC-SHA256 of input with the transit engine HMAC key of the current
+// state's seed engine.
+func computeHMAC(r *http.Request, guard stateGuard, keyVersion uint32, name string, input []byte) ([]byte, error) {
+	state, err := guard.GetState(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	key, err := state.SeedEngine().DeriveTransitHMACKey(keyVersion, name)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(input)
+	return mac.Sum(nil), nil
+}
+
+// deriveSigningKey derives the transit engine signing key from the current state's seed engine.
+func deriveSigningKey(r *http.Request, guard stateGuard, alg cryptohelpers.KeyAlgorithm, keyVersion uint32, name string) (crypto.Signer, error) {
+	state, err := guard.GetState(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return state.SeedEngine().DeriveTransitSigningKey(alg, keyVersion, name)
+}
+
+// signingAlgorithm returns the algorithm of the given key type, and checks that the hash options
+// are supported for it.
+func signingAlgorithm(keyType, hashAlgorithm string, prehashed bool, input []byte) (cryptohelpers.KeyAlgorithm, error) {
+	if keyType == "" {
+		keyType = defaultSigningKeyType
+	}
+	alg, ok := signingKeyTypes[keyType]
+	if !ok {
+		return "", fmt.Errorf("unsupported key_type %q", keyType)
+	}
+	if err := checkHashAlgorithm(hashAlgorithm); err != nil {
+		return "", err
+	}
+	if prehashed {
+		if alg == cryptohelpers.KeyAlgorithmEd25519 {
+			return "", errors.New("prehashed input isn't supported for ed25519 keys")
+		}
+		if len(input) != sha256.Size {
+			return "", fmt.Errorf("prehashed input must be %d bytes long, got %d", sha256.Size, len(input))
+		}
+	}
+	return alg, nil
+}
+
+// checkHashAlgorithm checks that the hash algorithm is SHA-256, the only one supported.
+func checkHashAlgorithm(hashAlgorithm string) error {
+	if hashAlgorithm != "" && hashAlgorithm != "sha2-256" {
+		return fmt.Errorf("unsupported hash algorithm %q, only sha2-256 is supported", hashAlgorithm)
+	}
+	return nil
+}
+
+// signInput signs input with key. Ed25519 keys sign the input itself, other keys sign its SHA-256
+// digest, or the input if it's prehashed.
+func signInput(key crypto.Signer, input []byte, prehashed bool) ([]byte, error) {
+	if _, ok := key.(ed25519.PrivateKey); ok {
+		return key.Sign(rand.Reader, input, crypto.Hash(0))
+	}
+	return cryptohelpers.SignDigest(key, signedDigest(input, prehashed))
+}
+
+// verifyInput verifies a signature created with signInput.
+func verifyInput(pub crypto.PublicKey, input, signature []byte, prehashed bool) error {
+	if _, ok := pub.(ed25519.PublicKey); ok {
+		return cryptohelpers.VerifyDigest(pub, input, signature)
+	}
+	return cryptohelpers.VerifyDigest(pub, signedDigest(input, prehashed), signature)
+}
+
+func signedDigest(input []byte, prehashed bool) []byte {
+	if prehashed {
+		return input
+	}
+	digest := sha256.Sum256(input)
+	return digest[:]
+}
+
+// formatVersioned encodes data in the "vault:vX:base64" format.
+func formatVersioned(keyVersion uint32, data []byte) string {
+	return fmt.Sprintf("vault:v%d:%s", keyVersion, base64.StdEncoding.EncodeToString(data))
+}
+
+// parseVersioned decodes data in the "vault:vX:base64" format.
+func parseVersioned(versioned string) (uint32, []byte, error) {
+	parts := strings.SplitN(versioned, ":", 3)
+	if len(parts) < 3 || parts[0] != "vault" {
+		return 0, nil, errors.New("invalid signature format")
+	}
+	version, err := extractVersion(parts[1])
+	if err != nil {
+		return 0, nil, fmt.Errorf("signature version: %w", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return 0, nil, fmt.Errorf("decoding signature: %w", err)
+	}
+	return version, data, nil
+}
@@ -0,0 +1,216 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package transitengine
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignVerify(t *testing.T) {
+	input := base64.StdEncoding.EncodeToString([]byte("artifact"))
+	digest := sha256.Sum256([]byte("artifact"))
+	prehashedInput := base64.StdEncoding.EncodeToString(digest[:])
+
+	testCases := map[string]struct {
+		signOptions   string
+		signInput     string
+		verifyOptions string
+		verifyInput   string
+		wantSignCode  int
+		wantValid     bool
+	}{
+		"default key type": {
+			wantSignCode: http.StatusOK,
+			wantValid:    true,
+		},
+		"ecdsa-p384": {
+			signOptions:   `"key_type":"ecdsa-p384",`,
+			verifyOptions: `"key_type":"ecdsa-p384",`,
+			wantSignCode:  http.StatusOK,
+			wantValid:     true,
+		},
+		"ecdsa-p521": {
+			signOptions:   `"key_type":"ecdsa-p521","hash_algorithm":"sha2-256",`,
+			verifyOptions: `"key_type":"ecdsa-p521",`,
+			wantSignCode:  http.StatusOK,
+			wantValid:     true,
+		},
+		"ed25519": {
+			signOptions:   `"key_type":"ed25519",`,
+			verifyOptions: `"key_type":"ed25519",`,
+			wantSignCode:  http.StatusOK,
+			wantValid:     true,
+		},
+		"prehashed": {
+			signOptions:   `"prehashed":true,`,
+			signInput:     prehashedInput,
+			verifyOptions: `"prehashed":true,`,
+			verifyInput:   prehashedInput,
+			wantSignCode:  http.StatusOK,
+			wantValid:     true,
+		},
+		"prehashed signature verifies unhashed input": {
+			signOptions:  `"prehashed":true,`,
+			signInput:    prehashedInput,
+			wantSignCode: http.StatusOK,
+			wantValid:    true,
+		},
+		"other input": {
+			verifyInput:  base64.StdEncoding.EncodeToString([]byte("other artifact")),
+			wantSignCode: http.StatusOK,
+		},
+		"other key type": {
+			signOptions:   `"key_type":"ecdsa-p256",`,
+			verifyOptions: `"key_type":"ecdsa-p384",`,
+			wantSignCode:  http.StatusOK,
+		},
+		"unsupported key type": {
+			signOptions:  `"key_type":"rsa-2048",`,
+			wantSignCode: http.StatusBadRequest,
+		},
+		"unsupported hash algorithm": {
+			signOptions:  `"hash_algorithm":"sha2-512",`,
+			wantSignCode: http.StatusBadRequest,
+		},
+		"prehashed ed25519": {
+			signOptions:  `"key_type":"ed25519","prehashed":true,`,
+			wantSignCode: http.StatusBadRequest,
+		},
+		"prehashed input of wrong size": {
+			signOptions:  `"prehashed":true,`,
+			wantSignCode: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+			guard, err := newTestGuard()
+			require.NoError(err)
+			mux := newMockTransitEngineMux(guard)
+
+			signInput, verifyInput := input, input
+			if tc.signInput != "" {
+				signInput = tc.signInput
+			}
+			if tc.verifyInput != "" {
+				verifyInput = tc.verifyInput
+			}
+
+			res, body := doRequest(t, mux, http.MethodPost, "/v1/transit/sign/foo", fmt.Sprintf(`{%s"input":%q}`, tc.signOptions, signInput))
+			require.Equal(tc.wantSignCode, res.StatusCode, body)
+			if tc.wantSignCode != http.StatusOK {
+				return
+			}
+			var signResp struct {
+				Data struct {
+					Signature string `json:"signature"`
+				} `json:"data"`
+			}
+			require.NoError(json.Unmarshal([]byte(body), &signResp))
+			assert.Regexp(`^vault:v0:`, signResp.Data.Signature)
+
+			res, body = doRequest(t, mux, http.MethodPost, "/v1/transit/verify/foo", fmt.Sprintf(`{%s"input":%q,"signature":%q}`, tc.verifyOptions, verifyInput, signResp.Data.Signature))
+			require.Equal(http.StatusOK, res.StatusCode, body)
+			assert.Contains(body, fmt.Sprintf(`"valid":%t`, tc.wantValid))
+		})
+	}
+}
+
+func TestHMAC(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	guard, err := newTestGuard()
+	require.NoError(err)
+	mux := newMockTransitEngineMux(guard)
+
+	res, body := doRequest(t, mux, http.MethodPost, "/v1/transit/hmac/foo", `{"input":"c2VjcmV0"}`)
+	require.Equal(http.StatusOK, res.StatusCode, body)
+	var hmacResp struct {
+		Data struct {
+			HMAC string `json:"hmac"`
+		} `json:"data"`
+	}
+	require.NoError(json.Unmarshal([]byte(body), &hmacResp))
+	assert.Regexp(`^vault:v0:`, hmacResp.Data.HMAC)
+
+	// The HMAC is deterministic.
+	_, body = doRequest(t, mux, http.MethodPost, "/v1/transit/hmac/foo", `{"input":"c2VjcmV0"}`)
+	assert.Contains(body, hmacResp.Data.HMAC)
+
+	// The HMAC is specific to the key.
+	_, body = doRequest(t, mux, http.MethodPost, "/v1/transit/hmac/bar", `{"input":"c2VjcmV0"}`)
+	assert.NotContains(body, hmacResp.Data.HMAC)
+
+	res, body = doRequest(t, mux, http.MethodPost, "/v1/transit/verify/foo", fmt.Sprintf(`{"input":"c2VjcmV0","hmac":%q}`, hmacResp.Data.HMAC))
+	require.Equal(http.StatusOK, res.StatusCode, body)
+	assert.Contains(body, `"valid":true`)
+
+	res, body = doRequest(t, mux, http.MethodPost, "/v1/transit/verify/foo", fmt.Sprintf(`{"input":"AAAA","hmac":%q}`, hmacResp.Data.HMAC))
+	require.Equal(http.StatusOK, res.StatusCode, body)
+	assert.Contains(body, `"valid":false`)
+
+	res, body = doRequest(t, mux, http.MethodPost, "/v1/transit/hmac/foo", `{"input":"c2VjcmV0","algorithm":"sha2-512"}`)
+	assert.Equal(http.StatusBadRequest, res.StatusCode, body)
+}
+
+func TestSignVerifyKeyVersions(t *testing.T) {
+	testCases := map[string]struct {
+		verifyBody string
+		wantCode   int
+	}{
+		"both signature and hmac": {
+			verifyBody: `{"input":"AAAA","signature":"vault:v0:AAAA","hmac":"vault:v0:AAAA"}`,
+			wantCode:   http.StatusBadRequest,
+		},
+		"neither signature nor hmac": {
+			verifyBody: `{"input":"AAAA"}`,
+			wantCode:   http.StatusBadRequest,
+		},
+		"invalid signature format": {
+			verifyBody: `{"input":"AAAA","signature":"AAAA"}`,
+			wantCode:   http.StatusBadRequest,
+		},
+		"retired version": {
+			verifyBody: `{"input":"AAAA","signature":"vault:v1:AAAA"}`,
+			wantCode:   http.StatusBadRequest,
+		},
+		"future version": {
+			verifyBody: `{"input":"AAAA","signature":"vault:v3:AAAA"}`,
+			wantCode:   http.StatusBadRequest,
+		},
+		"current version": {
+			verifyBody: `{"input":"AAAA","signature":"vault:v2:AAAA"}`,
+			wantCode:   http.StatusOK,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			guard, err := newTestGuard()
+			require.NoError(err)
+			mux := newMockTransitEngineMux(guard)
+
+			for range 2 {
+				res, body := doRequest(t, mux, http.MethodPost, "/v1/transit/keys/foo/rotate", "")
+				require.Equal(http.StatusOK, res.StatusCode, body)
+			}
+			res, body := doRequest(t, mux, http.MethodPost, "/v1/transit/keys/foo/config", `{"min_decryption_version":2}`)
+			require.Equal(http.StatusOK, res.StatusCode, body)
+
+			res, body = doRequest(t, mux, http.MethodPost, "/v1/transit/verify/foo", tc.verifyBody)
+			require.Equal(tc.wantCode, res.StatusCode, body)
+		})
+	}
+}
@@ -1,9 +1,10 @@
 // Copyright 2024 Edgeless Systems GmbH
 // SPDX-License-Identifier: BUSL-1.1
 
-// Package transitengine provides all functionality related to the transit engine API endpoints: decrypt and encrypt.
-// It is organized in a layered approach, keeping http request processing separated from the underlying crypto
-// business logic(crypto.go).
+// Package transitengine provides all functionality related to the transit engine API endpoints: encrypt, decrypt,
+// rewrap, datakey, hmac, sign, verify and key management. It is organized in a layered approach, keeping http
+// request processing separated from the underlying crypto business logic (crypto.go, sign.go) and the key metadata
+// (keys.go).
 package transitengine
 
 import (
@@ -16,14 +17,19 @@ import (
 	"encoding/asn1"
 	"encoding/json"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
+	"github.com/edgelesssys/contrast/coordinator/internal/certregistry"
 	"github.com/edgelesssys/contrast/coordinator/internal/stateguard"
+	"github.com/edgelesssys/contrast/internal/auditlog"
+	"github.com/edgelesssys/contrast/internal/ca"
 	"github.com/edgelesssys/contrast/internal/manifest"
 	"github.com/edgelesssys/contrast/internal/oid"
 )
@@ -37,15 +43,21 @@ const (
 
 type (
 	// encryptionRequest holds the request-specific plaintext and currently supported, optional query parameters: associatedData and keyVersion.
+	// If batchInput is set, its items are encrypted instead of the plaintext.
 	encryptionRequest struct {
-		Plaintext      []byte `json:"plaintext"`
-		KeyVersion     uint32 `json:"key_version"`
-		AssociatedData []byte `json:"associated_data,omitempty"`
+		Plaintext                  []byte                `json:"plaintext"`
+		KeyVersion                 uint32                `json:"key_version"`
+		AssociatedData             []byte                `json:"associated_data,omitempty"`
+		BatchInput                 []encryptionBatchItem `json:"batch_input,omitempty"`
+		PartialFailureResponseCode int                   `json:"partial_failure_response_code,omitempty"`
 	}
 	// decryptionRequest holds the request-specific ciphertextContainer and currently supported, optional query parameters: associatedData.
+	// If batchInput is set, its items are decrypted instead of the ciphertext.
 	decryptionRequest struct {
-		CiphertextContainer *ciphertextContainer `json:"ciphertext"`
-		AssociatedData      []byte               `json:"associated_data,omitempty"`
+		CiphertextContainer        *ciphertextContainer  `json:"ciphertext"`
+		AssociatedData             []byte                `json:"associated_data,omitempty"`
+		BatchInput                 []decryptionBatchItem `json:"batch_input,omitempty"`
+		PartialFailureResponseCode int                   `json:"partial_failure_response_code,omitempty"`
 	}
 	// encryptionResponse holds the response-specific ciphertextContainer.
 	encryptionResponse struct {
@@ -55,6 +67,17 @@ type (
 	decryptionResponse struct {
 		Plaintext []byte `json:"plaintext"`
 	}
+	// rewrapRequest holds the ciphertextContainer to re-encrypt and the optional key version to re-encrypt with.
+	rewrapRequest struct {
+		CiphertextContainer *ciphertextContainer `json:"ciphertext"`
+		KeyVersion          uint32               `json:"key_version"`
+		AssociatedData      []byte               `json:"associated_data,omitempty"`
+	}
+	// rewrapResponse holds the re-encrypted ciphertextContainer and the key version used.
+	rewrapResponse struct {
+		Ciphertext ciphertextContainer `json:"ciphertext"`
+		KeyVersion uint32              `json:"key_version"`
+	}
 )
 
 // httpError is a json struct holding http error related fields, used for sending json error response bodies and logging.
@@ -70,50 +93,98 @@ type stateGuard interface {
 	GetState(context.Context) (*stateguard.State, error)
 }
 
-// NewTransitEngineAPI sets up the transit engine API with a provided stateGuard.
-func NewTransitEngineAPI(guard stateGuard, logger *slog.Logger) (*http.Server, error) {
+// NewTransitEngineAPI sets up the transit engine API with a provided stateGuard. Key metadata is
+// persisted in keys. Client certificates revoked in registry are rejected. Successful requests
+// are recorded to audit, which may be nil.
+func NewTransitEngineAPI(guard stateGuard, keys *Keyring, registry *certregistry.Registry, logger *slog.Logger, audit *auditlog.Log) (*http.Server, error) {
+	tlsConfig, err := newTLSConfig(guard, registry, logger)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Server{
+		TLSConfig: tlsConfig,
+		Handler:   newTransitEngineMux(guard, keys, logger, audit),
+	}, nil
+}
+
+// newTLSConfig returns the TLS config of the transit engine API and the KMIP server. Clients must
+// authenticate with a mesh cert issued by the mesh CA of the current state that isn't revoked in
+// registry, and the server presents a mesh cert issued for the Coordinator's SANs.
+func newTLSConfig(guard stateGuard, registry *certregistry.Registry, logger *slog.Logger) (*tls.Config, error) {
 	privKeyAPI, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	if err != nil {
 		return nil, fmt.Errorf("failed creating transit engine API private key")
 	}
-	return &http.Server{
-		TLSConfig: &tls.Config{
-			ClientAuth: tls.RequireAndVerifyClientCert,
-			GetConfigForClient: func(chi *tls.ClientHelloInfo) (*tls.Config, error) {
-				logger.Debug("call getConfigForClient")
-				state, err := guard.GetState(chi.Context())
-				if err != nil {
-					return nil, fmt.Errorf("getting state: %w", err)
-				}
-				return &tls.Config{
-					ClientCAs:  state.CA().GetMeshCACertPool(),
-					ClientAuth: tls.RequireAndVerifyClientCert,
-					MinVersion: tls.VersionTLS12,
-					GetCertificate: func(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
-						return getCertificate(privKeyAPI, guard)
-					},
-				}, nil
-			},
+	return &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		GetConfigForClient: func(chi *tls.ClientHelloInfo) (*tls.Config, error) {
+			logger.Debug("call getConfigForClient")
+			state, err := guard.GetState(chi.Context())
+			if err != nil {
+				return nil, fmt.Errorf("getting state: %w", err)
+			}
+			return &tls.Config{
+				ClientCAs:  state.CA().GetMeshCACertPool(),
+				ClientAuth: tls.RequireAndVerifyClientCert,
+				MinVersion: tls.VersionTLS12,
+				GetCertificate: func(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+					return getCertificate(privKeyAPI, guard)
+				},
+				VerifyPeerCertificate: func(_ [][]byte, verifiedChains [][]*x509.Certificate) error {
+					return verifyClientChain(registry, state, verifiedChains)
+				},
+			}, nil
 		},
-		Handler: newTransitEngineMux(guard, logger),
 	}, nil
 }
 
+// verifyClientChain returns an error unless one of the verified chains consists of a client
+// certificate issued directly by the mesh CA that wasn't revoked.
+//
+// Certificates issued by sub CAs aren't accepted: they chain up to the mesh CA, but their
+// extensions, which authorize the access to keys, aren't restricted by the sub CA.
+func verifyClientChain(registry *certregistry.Registry, state *stateguard.State, verifiedChains [][]*x509.Certificate) error {
+	idx := slices.IndexFunc(verifiedChains, func(chain []*x509.Certificate) bool { return len(chain) == 2 })
+	if idx < 0 {
+		return errors.New("client certificate must be issued by the mesh CA")
+	}
+	if registry == nil {
+		return nil
+	}
+	leaf := verifiedChains[idx][0]
+	revoked, err := registry.IsRevoked(state.SeedEngine().TransactionSigningKey(), state.CA(), leaf.SerialNumber)
+	if err != nil {
+		return fmt.Errorf("checking revocation status: %w", err)
+	}
+	if revoked {
+		return fmt.Errorf("certificate with serial number %s was revoked", leaf.SerialNumber)
+	}
+	return nil
+}
+
 // newTransitEngineMux creates the http multiplexer for the required transit engine API path,
 // adding the corresponding middlewares for logging and authorization.
-func newTransitEngineMux(guard stateGuard, logger *slog.Logger) *http.ServeMux {
+func newTransitEngineMux(guard stateGuard, keys *Keyring, logger *slog.Logger, audit *auditlog.Log) *http.ServeMux {
 	mux := http.NewServeMux()
 
 	// 'name' wildcard is kept to reflect existing transit engine API specifications:
 	// https://openbao.org/api-docs/secret/transit/#encrypt-data
 	// name <=> workloadSecretID, which should be used for the key derivation.
-	mux.Handle("/v1/transit/encrypt/{name}", authorizationMiddleware(getEncryptHandler(guard, logger), logger))
-	mux.Handle("/v1/transit/decrypt/{name}", authorizationMiddleware(getDecryptHandler(guard, logger), logger))
+	mux.Handle("/v1/transit/encrypt/{name}", authorizationMiddleware(getEncryptHandler(guard, keys, logger, audit), guard, manifest.TransitOperationEncrypt, logger))
+	mux.Handle("/v1/transit/decrypt/{name}", authorizationMiddleware(getDecryptHandler(guard, keys, logger, audit), guard, manifest.TransitOperationDecrypt, logger))
+	mux.Handle("/v1/transit/rewrap/{name}", authorizationMiddleware(getRewrapHandler(guard, keys, logger, audit), guard, manifest.TransitOperationRewrap, logger))
+	mux.Handle("/v1/transit/datakey/{type}/{name}", authorizationMiddleware(getDatakeyHandler(guard, keys, logger, audit), guard, manifest.TransitOperationDatakey, logger))
+	mux.Handle("/v1/transit/hmac/{name}", authorizationMiddleware(getHMACHandler(guard, keys, logger, audit), guard, manifest.TransitOperationHMAC, logger))
+	mux.Handle("/v1/transit/sign/{name}", authorizationMiddleware(getSignHandler(guard, keys, logger, audit), guard, manifest.TransitOperationSign, logger))
+	mux.Handle("/v1/transit/verify/{name}", authorizationMiddleware(getVerifyHandler(guard, keys, logger, audit), guard, manifest.TransitOperationVerify, logger))
+	mux.Handle("GET /v1/transit/keys/{name}", authorizationMiddleware(getReadKeyHandler(guard, keys, logger), guard, manifest.TransitOperationRead, logger))
+	mux.Handle("POST /v1/transit/keys/{name}/rotate", authorizationMiddleware(getRotateHandler(guard, keys, logger, audit), guard, manifest.TransitOperationRotate, logger))
+	mux.Handle("POST /v1/transit/keys/{name}/config", authorizationMiddleware(getConfigHandler(guard, keys, logger, audit), guard, manifest.TransitOperationConfig, logger))
 
 	return mux
 }
 
-func getEncryptHandler(guard stateGuard, logger *slog.Logger) http.HandlerFunc {
+func getEncryptHandler(guard stateGuard, keys *Keyring, logger *slog.Logger, audit *auditlog.Log) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		workloadSecretID := r.PathValue("name")
 		if workloadSecretID == "" {
@@ -137,7 +208,39 @@ func getEncryptHandler(guard stateGuard, logger *slog.Logger) http.HandlerFunc {
 			}, logger)
 			return
 		}
-		key, err := deriveEncryptionKey(r.Context(), guard, encReq.KeyVersion, workloadSecretID)
+		if err := checkBatchInput(len(encReq.BatchInput), encReq.PartialFailureResponseCode); err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{err.Error()},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		metadata, err := getKeyMetadata(r.Context(), guard, keys, workloadSecretID)
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("reading key metadata: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		keyVersion, err := metadata.encryptionVersion(encReq.KeyVersion)
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{err.Error()},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		key, err := deriveEncryptionKey(r.Context(), guard, keyVersion, workloadSecretID)
 		if err != nil {
 			writeHTTPError(w, httpError{
 				code:          http.StatusInternalServerError,
@@ -148,6 +251,12 @@ func getEncryptHandler(guard stateGuard, logger *slog.Logger) http.HandlerFunc {
 			}, logger)
 			return
 		}
+		if len(encReq.BatchInput) > 0 {
+			results := encryptBatch(key, keyVersion, encReq.BatchInput)
+			recordTransitBatchEvent(audit, auditlog.EventTransitEncrypt, r, workloadSecretID, len(results))
+			writeBatchResponse(w, r, results, encReq.PartialFailureResponseCode, logger)
+			return
+		}
 		ciphertextContainer, err := symmetricEncryptRaw(key, encReq.Plaintext, encReq.AssociatedData)
 		if err != nil {
 			writeHTTPError(w, httpError{
@@ -159,7 +268,8 @@ func getEncryptHandler(guard stateGuard, logger *slog.Logger) http.HandlerFunc {
 			}, logger)
 			return
 		}
-		ciphertextContainer.keyVersion = encReq.KeyVersion
+		ciphertextContainer.keyVersion = keyVersion
+		recordTransitEvent(audit, auditlog.EventTransitEncrypt, r, workloadSecretID, keyVersion)
 		var encResp encryptionResponse
 		encResp.Ciphertext = ciphertextContainer
 		if err = writeJSONResponse(w, encResp); err != nil {
@@ -175,7 +285,7 @@ func getEncryptHandler(guard stateGuard, logger *slog.Logger) http.HandlerFunc {
 	}
 }
 
-func getDecryptHandler(guard stateGuard, logger *slog.Logger) http.HandlerFunc {
+func getDecryptHandler(guard stateGuard, keys *Keyring, logger *slog.Logger, audit *auditlog.Log) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		workloadSecretID := r.PathValue("name")
 		if workloadSecretID == "" {
@@ -199,7 +309,17 @@ func getDecryptHandler(guard stateGuard, logger *slog.Logger) http.HandlerFunc {
 			}, logger)
 			return
 		}
-		if decReq.CiphertextContainer == nil {
+		if err := checkBatchInput(len(decReq.BatchInput), decReq.PartialFailureResponseCode); err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{err.Error()},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		if decReq.CiphertextContainer == nil && len(decReq.BatchInput) == 0 {
 			writeHTTPError(w, httpError{
 				code:          http.StatusBadRequest,
 				Errors:        []string{"missing mandatory field: ciphertext"},
@@ -209,6 +329,33 @@ func getDecryptHandler(guard stateGuard, logger *slog.Logger) http.HandlerFunc {
 			}, logger)
 			return
 		}
+		metadata, err := getKeyMetadata(r.Context(), guard, keys, workloadSecretID)
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("reading key metadata: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		if len(decReq.BatchInput) > 0 {
+			results := decryptBatch(r.Context(), guard, metadata, workloadSecretID, decReq.BatchInput)
+			recordTransitBatchEvent(audit, auditlog.EventTransitDecrypt, r, workloadSecretID, len(results))
+			writeBatchResponse(w, r, results, decReq.PartialFailureResponseCode, logger)
+			return
+		}
+		if err := metadata.checkDecryptionVersion(decReq.CiphertextContainer.keyVersion); err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{err.Error()},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
 		key, err := deriveEncryptionKey(r.Context(), guard, decReq.CiphertextContainer.keyVersion, workloadSecretID)
 		if err != nil {
 			writeHTTPError(w, httpError{
@@ -231,6 +378,7 @@ func getDecryptHandler(guard stateGuard, logger *slog.Logger) http.HandlerFunc {
 			}, logger)
 			return
 		}
+		recordTransitEvent(audit, auditlog.EventTransitDecrypt, r, workloadSecretID, decReq.CiphertextContainer.keyVersion)
 		var decResp decryptionResponse
 		decResp.Plaintext = plaintext
 		if err = writeJSONResponse(w, decResp); err != nil {
@@ -246,21 +394,310 @@ func getDecryptHandler(guard stateGuard, logger *slog.Logger) http.HandlerFunc {
 	}
 }
 
-// auhorizeWorkloadSecret authorizes the client request by extracting the workloadSecretID
-// sent as the mesh cert extension and ensures equality to the workloadSecretID handed in.
-func authorizeWorkloadSecret(workloadSecretID string, r *http.Request, logger *slog.Logger) error {
-	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
-		return fmt.Errorf("no client certs provided")
+func getRewrapHandler(guard stateGuard, keys *Keyring, logger *slog.Logger, audit *auditlog.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		workloadSecretID := r.PathValue("name")
+		if workloadSecretID == "" {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{"Invalid URL format"},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		var rewrapReq rewrapRequest
+		if err := parseRequest(r, &rewrapReq); err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{fmt.Sprintf("parsing rewrap request: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		if rewrapReq.CiphertextContainer == nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{"missing mandatory field: ciphertext"},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		metadata, err := getKeyMetadata(r.Context(), guard, keys, workloadSecretID)
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("reading key metadata: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		oldVersion := rewrapReq.CiphertextContainer.keyVersion
+		if err := metadata.checkDecryptionVersion(oldVersion); err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{err.Error()},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		newVersion, err := metadata.encryptionVersion(rewrapReq.KeyVersion)
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{err.Error()},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		oldKey, err := deriveEncryptionKey(r.Context(), guard, oldVersion, workloadSecretID)
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("key derivation: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		plaintext, err := symmetricDecryptRaw(oldKey, *rewrapReq.CiphertextContainer, rewrapReq.AssociatedData)
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{fmt.Sprintf("decrypting: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		newKey, err := deriveEncryptionKey(r.Context(), guard, newVersion, workloadSecretID)
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("key derivation: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		ciphertextContainer, err := symmetricEncryptRaw(newKey, plaintext, rewrapReq.AssociatedData)
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("encrypting: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		ciphertextContainer.keyVersion = newVersion
+		recordTransitEvent(audit, auditlog.EventTransitRewrap, r, workloadSecretID, newVersion)
+		rewrapResp := rewrapResponse{Ciphertext: ciphertextContainer, KeyVersion: newVersion}
+		if err = writeJSONResponse(w, rewrapResp); err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("writing response: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+	}
+}
+
+func getReadKeyHandler(guard stateGuard, keys *Keyring, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		workloadSecretID := r.PathValue("name")
+		metadata, err := getKeyMetadata(r.Context(), guard, keys, workloadSecretID)
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("reading key metadata: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		if err = writeJSONResponse(w, metadata.info()); err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("writing response: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
 	}
-	extensionWSID, err := extractWorkloadSecretExtension(r.TLS.PeerCertificates[0])
+}
+
+func getRotateHandler(guard stateGuard, keys *Keyring, logger *slog.Logger, audit *auditlog.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		workloadSecretID := r.PathValue("name")
+		state, err := guard.GetState(r.Context())
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("getting state: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		metadata, err := keys.rotate(state.SeedEngine().TransactionSigningKey(), workloadSecretID)
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          keyUpdateErrorCode(err),
+				Errors:        []string{fmt.Sprintf("rotating key: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		recordTransitEvent(audit, auditlog.EventTransitRotate, r, workloadSecretID, metadata.LatestVersion)
+		if err = writeJSONResponse(w, metadata.info()); err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("writing response: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+	}
+}
+
+func getConfigHandler(guard stateGuard, keys *Keyring, logger *slog.Logger, audit *auditlog.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		workloadSecretID := r.PathValue("name")
+		var config keyConfig
+		if err := parseRequest(r, &config); err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusBadRequest,
+				Errors:        []string{fmt.Sprintf("parsing config request: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		state, err := guard.GetState(r.Context())
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("getting state: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		metadata, err := keys.configure(state.SeedEngine().TransactionSigningKey(), workloadSecretID, config)
+		if err != nil {
+			writeHTTPError(w, httpError{
+				code:          keyUpdateErrorCode(err),
+				Errors:        []string{fmt.Sprintf("configuring key: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+		audit.Record(auditlog.Event{
+			Type:        auditlog.EventTransitConfig,
+			Actor:       transitActor(r),
+			PeerAddress: r.RemoteAddr,
+			Details: map[string]string{
+				"name":                   workloadSecretID,
+				"min_decryption_version": strconv.FormatUint(uint64(metadata.MinDecryptionVersion), 10),
+				"min_encryption_version": strconv.FormatUint(uint64(metadata.MinEncryptionVersion), 10),
+			},
+		})
+		if err = writeJSONResponse(w, metadata.info()); err != nil {
+			writeHTTPError(w, httpError{
+				code:          http.StatusInternalServerError,
+				Errors:        []string{fmt.Sprintf("writing response: %v", err)},
+				reqMethod:     r.Method,
+				reqURI:        r.RequestURI,
+				reqRemoteAddr: r.RemoteAddr,
+			}, logger)
+			return
+		}
+	}
+}
+
+// authorizeRequest authorizes the client request to perform op with the named key, according to
+// the TransitKeys of the current manifest. It returns the rule that allowed the request.
+func authorizeRequest(r *http.Request, guard stateGuard, name string, op manifest.TransitOperation) (string, error) {
+	// Names with a slash can't be requested in a URL path without escaping and are reserved for
+	// the derivation of KMIP object keys.
+	if strings.Contains(name, "/") {
+		return "", fmt.Errorf("key name %q must not contain '/'", name)
+	}
+	peer, err := newPeerIdentity(r.TLS)
 	if err != nil {
-		return fmt.Errorf("missing required workloadSecretID cert extension:%w", err)
+		return "", err
 	}
-	if workloadSecretID == extensionWSID {
-		logger.Debug("access granted", "workload-secret", workloadSecretID, "principal", r.TLS.PeerCertificates[0].Subject, "path", r.URL)
-		return nil
+	return peer.authorize(r.Context(), guard, name, op)
+}
+
+// peerIdentity identifies a client by the workloadSecretID and policy hash extensions of its mesh
+// cert. Fields of missing extensions are empty.
+type peerIdentity struct {
+	subject          string
+	workloadSecretID string
+	policyHash       manifest.HexString
+}
+
+// newPeerIdentity reads the identity of the client from its mesh cert.
+func newPeerIdentity(connState *tls.ConnectionState) (peerIdentity, error) {
+	if connState == nil || len(connState.PeerCertificates) == 0 {
+		return peerIdentity{}, fmt.Errorf("no client certs provided")
+	}
+	cert := connState.PeerCertificates[0]
+	workloadSecretID, err := extractBytesExtension(cert, oid.WorkloadSecretOID)
+	if err != nil && !errors.Is(err, errExtensionNotFound) {
+		return peerIdentity{}, fmt.Errorf("workloadSecretID cert extension: %w", err)
+	}
+	policyHash, err := extractBytesExtension(cert, oid.PolicyHashOID)
+	if err != nil && !errors.Is(err, errExtensionNotFound) {
+		return peerIdentity{}, fmt.Errorf("policy hash cert extension: %w", err)
+	}
+	return peerIdentity{
+		subject:          cert.Subject.String(),
+		workloadSecretID: string(workloadSecretID),
+		policyHash:       manifest.NewHexString(policyHash),
+	}, nil
+}
+
+// authorize authorizes the client to perform op with the named key, according to the TransitKeys
+// of the current manifest. It returns the rule that allowed the operation.
+func (p peerIdentity) authorize(ctx context.Context, guard stateGuard, name string, op manifest.TransitOperation) (string, error) {
+	state, err := guard.GetState(ctx)
+	if err != nil {
+		return "", fmt.Errorf("getting state: %w", err)
 	}
-	return fmt.Errorf("mismatching workloadSecretIDs: name:%s, extension:%s", workloadSecretID, extensionWSID)
+	return state.Manifest().TransitAccess(name, p.workloadSecretID, p.policyHash, op)
 }
 
 // deriveEncryptionKey derives the transit engine encryption key from the current state's seed engine.
@@ -280,16 +717,81 @@ func deriveEncryptionKey(ctx context.Context, guard stateGuard, keyVersion uint3
 	return key[:aesGCMKeySize], nil
 }
 
+// getKeyMetadata reads the metadata of the named key with the signing key of the current state.
+func getKeyMetadata(ctx context.Context, guard stateGuard, keys *Keyring, name string) (*keyMetadata, error) {
+	state, err := guard.GetState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return keys.metadata(state.SeedEngine().TransactionSigningKey(), name)
+}
+
+// keyUpdateErrorCode returns the HTTP status code for an error of a key metadata update.
+func keyUpdateErrorCode(err error) int {
+	if errors.Is(err, errInvalidKeyVersion) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// recordTransitEvent records a transit engine request to the audit log.
+func recordTransitEvent(audit *auditlog.Log, eventType auditlog.EventType, r *http.Request, workloadSecretID string, keyVersion uint32) {
+	audit.Record(auditlog.Event{
+		Type:        eventType,
+		Actor:       transitActor(r),
+		PeerAddress: r.RemoteAddr,
+		Details: map[string]string{
+			"name":        workloadSecretID,
+			"key_version": strconv.FormatUint(uint64(keyVersion), 10),
+		},
+	})
+}
+
+// recordTransitBatchEvent records a transit engine batch request to the audit log.
+func recordTransitBatchEvent(audit *auditlog.Log, eventType auditlog.EventType, r *http.Request, workloadSecretID string, batchSize int) {
+	audit.Record(auditlog.Event{
+		Type:        eventType,
+		Actor:       transitActor(r),
+		PeerAddress: r.RemoteAddr,
+		Details: map[string]string{
+			"name":       workloadSecretID,
+			"batch_size": strconv.Itoa(batchSize),
+		},
+	})
+}
+
+// transitActor identifies the client of a transit engine request by the subject of its mesh
+// certificate.
+func transitActor(r *http.Request) string {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return ""
+	}
+	return r.TLS.PeerCertificates[0].Subject.String()
+}
+
 // writeJSONResponse wraps any payload inside a "data" object and sends it as an HTTP response.
 func writeJSONResponse(w http.ResponseWriter, payload any) error {
+	return writeJSONResponseWithCode(w, http.StatusOK, payload)
+}
+
+// writeJSONResponseWithCode is like writeJSONResponse, but with the given status code.
+func writeJSONResponseWithCode(w http.ResponseWriter, code int, payload any) error {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(code)
 	response := map[string]any{
 		"data": payload,
 	}
 	return json.NewEncoder(w).Encode(response)
 }
 
+// writeBatchResponse sends the results of a batch request. The status code is determined by
+// batchStatusCode.
+func writeBatchResponse(w http.ResponseWriter, r *http.Request, results []batchResult, partialFailureCode int, logger *slog.Logger) {
+	if err := writeJSONResponseWithCode(w, batchStatusCode(results, partialFailureCode), batchResponse{BatchResults: results}); err != nil {
+		logger.Warn("Writing batch response", "err", err, "method", r.Method, "uri", r.RequestURI, "remoteAddr", r.RemoteAddr)
+	}
+}
+
 // writeHTTPError sends the httpError handed in as json body error response and logs prior.
 func writeHTTPError(w http.ResponseWriter, httpErr httpError, logger *slog.Logger) {
 	w.Header().Set("Content-Type", "application/json")
@@ -337,31 +839,39 @@ func extractVersion(versionStr string) (uint32, error) {
 	return uint32(version), nil
 }
 
-// extractWorkloadSecretExtension is a helper function which checks if the extension OID exists in the certificate and returns the string representation
-// of the contained bytes.
-func extractWorkloadSecretExtension(cert *x509.Certificate) (string, error) {
+// errExtensionNotFound is returned by extractBytesExtension if the certificate doesn't have the
+// extension.
+var errExtensionNotFound = errors.New("extension not found")
+
+// extractBytesExtension is a helper function which checks if the extension OID exists in the certificate and returns
+// the contained bytes.
+func extractBytesExtension(cert *x509.Certificate, id asn1.ObjectIdentifier) ([]byte, error) {
 	for _, ext := range cert.Extensions {
-		if ext.Id.Equal(oid.WorkloadSecretOID) {
+		if ext.Id.Equal(id) {
 			var value []byte
 			_, err := asn1.Unmarshal(ext.Value, &value)
 			if err != nil {
-				return "", fmt.Errorf("failed to parse extension: %w", err)
+				return nil, fmt.Errorf("failed to parse extension: %w", err)
 			}
-			return string(value), nil
+			return value, nil
 		}
 	}
-	return "", fmt.Errorf("extension not found")
+	return nil, errExtensionNotFound
 }
 
-// authorizationMiddleware reads out the workloadSecretID stored in name URL parameter and ensures
-// the client request to be authorized.
-func authorizationMiddleware(next http.HandlerFunc, logger *slog.Logger) http.HandlerFunc {
+// authorizationMiddleware reads out the key name stored in the name URL parameter and ensures
+// the client request to be authorized to perform op with the key. The decision is logged for
+// every request.
+func authorizationMiddleware(next http.HandlerFunc, guard stateGuard, op manifest.TransitOperation, logger *slog.Logger) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		workloadSecretID := r.PathValue("name")
-		if err := authorizeWorkloadSecret(workloadSecretID, r, logger); err != nil {
+		name := r.PathValue("name")
+		rule, err := authorizeRequest(r, guard, name, op)
+		if err != nil {
+			logger.Warn("Transit request denied", "key", name, "operation", op, "principal", transitActor(r), "remoteAddr", r.RemoteAddr, "err", err)
 			http.Error(w, fmt.Sprintf("Unauthorized: %v", err), http.StatusForbidden)
 			return
 		}
+		logger.Info("Transit request authorized", "key", name, "operation", op, "principal", transitActor(r), "remoteAddr", r.RemoteAddr, "rule", rule)
 		next.ServeHTTP(w, r)
 	})
 }
@@ -381,7 +891,7 @@ func getCertificate(privKeyAPI *ecdsa.PrivateKey, guard stateGuard) (*tls.Certif
 			dnsNames = append(dnsNames, policyEntry.SANs...)
 		}
 	}
-	meshCertPEM, err := state.CA().NewAttestedMeshCert(dnsNames, nil, &privKeyAPI.PublicKey)
+	meshCertPEM, err := state.CA().NewAttestedMeshCert(dnsNames, nil, &privKeyAPI.PublicKey, ca.MeshCertOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create mesh cert: %w", err)
 	}
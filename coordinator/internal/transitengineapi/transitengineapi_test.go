@@ -6,18 +6,28 @@ package transitengine
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
 
+	"github.com/edgelesssys/contrast/coordinator/internal/certregistry"
 	"github.com/edgelesssys/contrast/coordinator/internal/stateguard"
+	"github.com/edgelesssys/contrast/internal/ca"
 	"github.com/edgelesssys/contrast/internal/constants"
+	"github.com/edgelesssys/contrast/internal/history/aferostore"
 	"github.com/edgelesssys/contrast/internal/seedengine"
+	"github.com/edgelesssys/contrast/internal/testkeys"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -253,6 +263,197 @@ func TestRejectLegacyCiphertext(t *testing.T) {
 	require.Equal(http.StatusBadRequest, res.StatusCode, string(body))
 }
 
+func TestKeyVersions(t *testing.T) {
+	type step struct {
+		method   string
+		path     string
+		body     string
+		wantCode int
+	}
+	testCases := map[string]struct {
+		steps []step
+	}{
+		"unmanaged key accepts any version": {
+			steps: []step{
+				{method: http.MethodPut, path: "/v1/transit/encrypt/foo", body: `{"plaintext":"AAAA","key_version":7}`, wantCode: http.StatusOK},
+				{method: http.MethodGet, path: "/v1/transit/keys/foo", wantCode: http.StatusOK},
+			},
+		},
+		"rotate enables the next version": {
+			steps: []step{
+				{method: http.MethodPut, path: "/v1/transit/encrypt/foo", body: `{"plaintext":"AAAA","key_version":2}`, wantCode: http.StatusOK},
+				{method: http.MethodPost, path: "/v1/transit/keys/foo/rotate", wantCode: http.StatusOK},
+				{method: http.MethodPut, path: "/v1/transit/encrypt/foo", body: `{"plaintext":"AAAA","key_version":1}`, wantCode: http.StatusOK},
+				{method: http.MethodPut, path: "/v1/transit/encrypt/foo", body: `{"plaintext":"AAAA","key_version":2}`, wantCode: http.StatusBadRequest},
+			},
+		},
+		"rotation is per key": {
+			steps: []step{
+				{method: http.MethodPost, path: "/v1/transit/keys/foo/rotate", wantCode: http.StatusOK},
+				{method: http.MethodPut, path: "/v1/transit/encrypt/bar", body: `{"plaintext":"AAAA","key_version":2}`, wantCode: http.StatusOK},
+			},
+		},
+		"min decryption version retires old versions": {
+			steps: []step{
+				{method: http.MethodPost, path: "/v1/transit/keys/foo/rotate", wantCode: http.StatusOK},
+				{method: http.MethodPost, path: "/v1/transit/keys/foo/rotate", wantCode: http.StatusOK},
+				{method: http.MethodPost, path: "/v1/transit/keys/foo/config", body: `{"min_decryption_version":2}`, wantCode: http.StatusOK},
+				{method: http.MethodPut, path: "/v1/transit/encrypt/foo", body: `{"plaintext":"AAAA","key_version":1}`, wantCode: http.StatusBadRequest},
+				{method: http.MethodPut, path: "/v1/transit/encrypt/foo", body: `{"plaintext":"AAAA","key_version":2}`, wantCode: http.StatusOK},
+			},
+		},
+		"min decryption version beyond latest": {
+			steps: []step{
+				{method: http.MethodPost, path: "/v1/transit/keys/foo/rotate", wantCode: http.StatusOK},
+				{method: http.MethodPost, path: "/v1/transit/keys/foo/config", body: `{"min_decryption_version":2}`, wantCode: http.StatusBadRequest},
+			},
+		},
+		"min encryption version below min decryption version": {
+			steps: []step{
+				{method: http.MethodPost, path: "/v1/transit/keys/foo/rotate", wantCode: http.StatusOK},
+				{method: http.MethodPost, path: "/v1/transit/keys/foo/rotate", wantCode: http.StatusOK},
+				{method: http.MethodPost, path: "/v1/transit/keys/foo/config", body: `{"min_decryption_version":2,"min_encryption_version":1}`, wantCode: http.StatusBadRequest},
+			},
+		},
+		"min encryption version": {
+			steps: []step{
+				{method: http.MethodPost, path: "/v1/transit/keys/foo/rotate", wantCode: http.StatusOK},
+				{method: http.MethodPost, path: "/v1/transit/keys/foo/rotate", wantCode: http.StatusOK},
+				{method: http.MethodPost, path: "/v1/transit/keys/foo/config", body: `{"min_encryption_version":2}`, wantCode: http.StatusOK},
+				{method: http.MethodPut, path: "/v1/transit/encrypt/foo", body: `{"plaintext":"AAAA","key_version":1}`, wantCode: http.StatusBadRequest},
+				{method: http.MethodPut, path: "/v1/transit/encrypt/foo", body: `{"plaintext":"AAAA"}`, wantCode: http.StatusOK},
+			},
+		},
+		"read key with wrong method": {
+			steps: []step{
+				{method: http.MethodDelete, path: "/v1/transit/keys/foo", wantCode: http.StatusMethodNotAllowed},
+			},
+		},
+		"rotate and config with wrong method": {
+			steps: []step{
+				{method: http.MethodGet, path: "/v1/transit/keys/foo/rotate", wantCode: http.StatusMethodNotAllowed},
+				{method: http.MethodPut, path: "/v1/transit/keys/foo/config", body: `{"min_decryption_version":1}`, wantCode: http.StatusMethodNotAllowed},
+				{method: http.MethodGet, path: "/v1/transit/keys/foo", wantCode: http.StatusOK},
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			guard, err := newTestGuard()
+			require.NoError(err)
+			mux := newMockTransitEngineMux(guard)
+
+			for i, step := range tc.steps {
+				res, body := doRequest(t, mux, step.method, step.path, step.body)
+				require.Equal(step.wantCode, res.StatusCode, "step %d: %s", i, body)
+			}
+		})
+	}
+}
+
+func TestRotateAndRewrap(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	guard, err := newTestGuard()
+	require.NoError(err)
+	mux := newMockTransitEngineMux(guard)
+
+	res, body := doRequest(t, mux, http.MethodPut, "/v1/transit/encrypt/foo", `{"plaintext":"c2VjcmV0","associated_data":"AAAA"}`)
+	require.Equal(http.StatusOK, res.StatusCode, body)
+	var encResp struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	require.NoError(json.Unmarshal([]byte(body), &encResp))
+	assert.Regexp(`^vault:v0:`, encResp.Data.Ciphertext)
+
+	res, body = doRequest(t, mux, http.MethodPost, "/v1/transit/keys/foo/rotate", "")
+	require.Equal(http.StatusOK, res.StatusCode, body)
+	var keyResp struct {
+		Data keyInfo `json:"data"`
+	}
+	require.NoError(json.Unmarshal([]byte(body), &keyResp))
+	assert.Equal("foo", keyResp.Data.Name)
+	assert.EqualValues(1, keyResp.Data.LatestVersion)
+	assert.EqualValues(0, keyResp.Data.MinDecryptionVersion)
+	assert.Contains(keyResp.Data.Keys, "1")
+
+	rewrapReq := fmt.Sprintf(`{"ciphertext":%q,"associated_data":"AAAA"}`, encResp.Data.Ciphertext)
+	res, body = doRequest(t, mux, http.MethodPost, "/v1/transit/rewrap/foo", rewrapReq)
+	require.Equal(http.StatusOK, res.StatusCode, body)
+	var rewrapResp struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+			KeyVersion uint32 `json:"key_version"`
+		} `json:"data"`
+	}
+	require.NoError(json.Unmarshal([]byte(body), &rewrapResp))
+	assert.Regexp(`^vault:v1:`, rewrapResp.Data.Ciphertext)
+	assert.EqualValues(1, rewrapResp.Data.KeyVersion)
+
+	res, body = doRequest(t, mux, http.MethodPost, "/v1/transit/keys/foo/config", `{"min_decryption_version":1}`)
+	require.Equal(http.StatusOK, res.StatusCode, body)
+
+	decryptOld := fmt.Sprintf(`{"ciphertext":%q,"associated_data":"AAAA"}`, encResp.Data.Ciphertext)
+	res, body = doRequest(t, mux, http.MethodPut, "/v1/transit/decrypt/foo", decryptOld)
+	assert.Equal(http.StatusBadRequest, res.StatusCode, body)
+
+	decryptNew := fmt.Sprintf(`{"ciphertext":%q,"associated_data":"AAAA"}`, rewrapResp.Data.Ciphertext)
+	res, body = doRequest(t, mux, http.MethodPut, "/v1/transit/decrypt/foo", decryptNew)
+	require.Equal(http.StatusOK, res.StatusCode, body)
+	assert.Contains(body, `"plaintext":"c2VjcmV0"`)
+
+	res, body = doRequest(t, mux, http.MethodPost, "/v1/transit/rewrap/foo", decryptOld)
+	assert.Equal(http.StatusBadRequest, res.StatusCode, body)
+}
+
+func doRequest(t *testing.T, mux *http.ServeMux, method, path, body string) (*http.Response, string) {
+	t.Helper()
+	req := httptest.NewRequestWithContext(t.Context(), method, path, bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	res := rec.Result()
+	t.Cleanup(func() { _ = res.Body.Close() })
+	resBody, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return res, string(resBody)
+}
+
+func TestVerifyClientChain(t *testing.T) {
+	require := require.New(t)
+
+	seedEngine, err := seedengine.New(make([]byte, constants.SecretSeedSize), make([]byte, constants.SecretSeedSaltSize))
+	require.NoError(err)
+	meshCA, err := ca.New(testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[0]), testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[1]))
+	require.NoError(err)
+	state := stateguard.NewStateForTest(seedEngine, nil, nil, meshCA)
+	signingKey := seedEngine.TransactionSigningKey()
+
+	registry := certregistry.New(aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()}), slog.New(slog.DiscardHandler))
+	for _, serial := range []int64{1, 2, 3} {
+		require.NoError(registry.RecordIssued(signingKey, meshCA, certregistry.Certificate{SerialNumber: big.NewInt(serial), NotAfter: time.Now().Add(time.Hour)}))
+	}
+	_, err = registry.Revoke(signingKey, meshCA, []*big.Int{big.NewInt(2)}, "", 0)
+	require.NoError(err)
+
+	chain := func(serials ...int64) [][]*x509.Certificate {
+		var chain []*x509.Certificate
+		for _, serial := range serials {
+			chain = append(chain, &x509.Certificate{SerialNumber: big.NewInt(serial)})
+		}
+		return [][]*x509.Certificate{chain}
+	}
+	require.NoError(verifyClientChain(registry, state, chain(1, 3)))
+	require.Error(verifyClientChain(registry, state, chain(2, 3)), "revoked leaf must be rejected")
+	require.NoError(verifyClientChain(nil, state, chain(2, 3)))
+	require.Error(verifyClientChain(registry, state, chain(1, 3, 4)), "sub CA chain must be rejected")
+	require.Error(verifyClientChain(nil, state, chain(1, 3, 4)), "sub CA chain must be rejected")
+}
+
 type fakeStateGuard struct {
 	state *stateguard.State
 }
@@ -279,7 +480,16 @@ func (f *fakeStateGuard) GetState(context.Context) (*stateguard.State, error) {
 func newMockTransitEngineMux(guard stateGuard) *http.ServeMux {
 	mux := http.NewServeMux()
 	logger := slog.New(slog.DiscardHandler)
-	mux.Handle("/v1/transit/encrypt/{name}", getEncryptHandler(guard, logger))
-	mux.Handle("/v1/transit/decrypt/{name}", getDecryptHandler(guard, logger))
+	keys := NewKeyring(aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()}), logger)
+	mux.Handle("/v1/transit/encrypt/{name}", getEncryptHandler(guard, keys, logger, nil))
+	mux.Handle("/v1/transit/decrypt/{name}", getDecryptHandler(guard, keys, logger, nil))
+	mux.Handle("/v1/transit/rewrap/{name}", getRewrapHandler(guard, keys, logger, nil))
+	mux.Handle("/v1/transit/datakey/{type}/{name}", getDatakeyHandler(guard, keys, logger, nil))
+	mux.Handle("/v1/transit/hmac/{name}", getHMACHandler(guard, keys, logger, nil))
+	mux.Handle("/v1/transit/sign/{name}", getSignHandler(guard, keys, logger, nil))
+	mux.Handle("/v1/transit/verify/{name}", getVerifyHandler(guard, keys, logger, nil))
+	mux.Handle("GET /v1/transit/keys/{name}", getReadKeyHandler(guard, keys, logger))
+	mux.Handle("POST /v1/transit/keys/{name}/rotate", getRotateHandler(guard, keys, logger, nil))
+	mux.Handle("POST /v1/transit/keys/{name}/config", getConfigHandler(guard, keys, logger, nil))
 	return mux
 }
@@ -15,17 +15,24 @@ package userapi
 import (
 	"bytes"
 	"context"
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/rsa"
 	"crypto/x509"
-	"encoding/hex"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"log/slog"
+	"math/big"
 	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/edgelesssys/contrast/coordinator/internal/certregistry"
 	"github.com/edgelesssys/contrast/coordinator/internal/stateguard"
+	"github.com/edgelesssys/contrast/internal/auditlog"
 	"github.com/edgelesssys/contrast/internal/constants"
 	"github.com/edgelesssys/contrast/internal/cryptohelpers"
 	"github.com/edgelesssys/contrast/internal/history"
@@ -36,6 +43,7 @@ import (
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+	"k8s.io/utils/clock"
 )
 
 // guard is the public API of stateguard.Guard.
@@ -44,8 +52,22 @@ type guard interface {
 	GetState(context.Context) (*stateguard.State, error)
 	// GetHistory returns a slice of manifests and a map of policies referenced in the manifests.
 	GetHistory(context.Context) (manifests [][]byte, policies map[manifest.HexString][]byte, err error)
+	// GetTransitionSignatures returns the workload owner signatures of the transitions in the history.
+	GetTransitionSignatures(context.Context) (map[[history.HashSize]byte][]byte, error)
+	// GetHistoryCheckpoint returns the checkpoint of the pruned history, or nil if the history is complete.
+	GetHistoryCheckpoint(context.Context) (*history.Checkpoint, error)
 	// UpdateState advances the state to the given manifest and policies.
-	UpdateState(ctx context.Context, oldState *stateguard.State, se *seedengine.SeedEngine, manifest []byte, policies [][]byte) (newState *stateguard.State, err error)
+	UpdateState(ctx context.Context, oldState *stateguard.State, se *seedengine.SeedEngine, manifest []byte, policies [][]byte, signature []byte) (newState *stateguard.State, err error)
+	// ScheduleState persists an update to the given manifest and policies that's applied at activationTime.
+	ScheduleState(ctx context.Context, oldState *stateguard.State, manifest []byte, policies [][]byte, signature []byte, activationTime time.Time) (*stateguard.PendingUpdate, error)
+	// GetPendingUpdate returns the scheduled manifest update, or nil if there is none.
+	GetPendingUpdate(context.Context) (*stateguard.PendingUpdate, error)
+	// CancelPendingUpdate cancels the scheduled manifest update with the given transition hash.
+	CancelPendingUpdate(ctx context.Context, transitionHash [history.HashSize]byte) error
+	// Clock returns the clock pending updates are activated with.
+	Clock() clock.PassiveClock
+	// GetRollbackTarget returns the manifest and policies of an earlier transition in the history of state.
+	GetRollbackTarget(ctx context.Context, state *stateguard.State, transitionHash [history.HashSize]byte) (manifest []byte, policies [][]byte, err error)
 	// ResetState recovers to the latest persisted state, authorizing the recovery seed with the passed func.
 	ResetState(ctx context.Context, oldState *stateguard.State, a stateguard.SecretSourceAuthorizer) (newState *stateguard.State, err error)
 }
@@ -59,6 +81,8 @@ type Server struct {
 	logger    *slog.Logger
 	guard     guard
 	discovery discovery
+	audit     *auditlog.Log
+	registry  *certregistry.Registry
 
 	// allowInsecure selects the manifest security level accepted by the Coordinator. It defaults to
 	// secure manifests and can be switched to insecure manifests via MakeInsecure.
@@ -67,12 +91,15 @@ type Server struct {
 	userapi.UnimplementedUserAPIServer
 }
 
-// New constructs a new Server instance.
-func New(logger *slog.Logger, guard guard, discovery discovery) *Server {
+// New constructs a new Server instance. Successful state changes are recorded to audit, and mesh
+// certificates are revoked in registry. Both may be nil.
+func New(logger *slog.Logger, guard guard, discovery discovery, audit *auditlog.Log, registry *certregistry.Registry) *Server {
 	return &Server{
 		logger:    logger,
 		guard:     guard,
 		discovery: discovery,
+		audit:     audit,
+		registry:  registry,
 	}
 }
 
@@ -85,58 +112,48 @@ func (s *Server) MakeInsecure() {
 }
 
 // SetManifest registers a new manifest at the Coordinator.
+//
+// If the request has an activation time in the future, the manifest is scheduled instead and
+// becomes active once the activation time is reached.
 func (s *Server) SetManifest(ctx context.Context, req *userapi.SetManifestRequest) (*userapi.SetManifestResponse, error) {
 	s.logger.Info("SetManifest called")
 
-	oldState, err := s.guard.GetState(ctx)
-	switch {
-	case errors.Is(err, stateguard.ErrStaleState):
-		return nil, status.Error(codes.FailedPrecondition, ErrNeedsRecovery.Error())
-	case errors.Is(err, stateguard.ErrNoState):
-		// This is fine, we are going to set the initial manifest.
-	case err != nil:
-		return nil, status.Errorf(codes.Internal, "getting state: %v", err)
+	oldState, m, approvers, err := s.checkManifestUpdate(ctx, req)
+	if err != nil {
+		return nil, err
 	}
-
-	var m *manifest.Manifest
-	if err := json.Unmarshal(req.Manifest, &m); err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "unmarshaling manifest: %v", err)
+	signatures, err := requestSignatures(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
 	var resp userapi.SetManifestResponse
 
-	var se *seedengine.SeedEngine
-	if oldState != nil {
-		oldManifest := oldState.Manifest()
-		// Subsequent SetManifest call, check permissions of caller.
-		if err := validateSignature(oldManifest.WorkloadOwnerPubKeys, oldState.LatestTransition().TransitionHash, req); err != nil && !errors.Is(err, errNoSignature) {
-			s.logger.Warn("SetManifest signature validation failed", "err", err)
-			return nil, status.Errorf(codes.PermissionDenied, "validating manifest signature: %v", err)
-		} else if errors.Is(err, errNoSignature) {
-			if err := validatePeer(ctx, oldManifest.WorkloadOwnerPubKeys); err != nil {
-				s.logger.Warn("SetManifest peer validation failed", "err", err)
-				return nil, status.Errorf(codes.PermissionDenied, "validating peer: %v", err)
+	if activationTime := time.Unix(req.GetActivationTime(), 0); req.GetActivationTime() != 0 && activationTime.After(s.guard.Clock().Now()) {
+		if oldState == nil {
+			return nil, status.Error(codes.InvalidArgument, "the initial manifest can't be scheduled")
+		}
+		pending, err := s.guard.ScheduleState(ctx, oldState, req.GetManifest(), req.GetPolicies(), history.JoinSignatures(signatures...), activationTime)
+		if err != nil {
+			code := codes.Internal
+			if errors.Is(err, stateguard.ErrConcurrentUpdate) {
+				code = codes.FailedPrecondition
 			}
+			return nil, status.Errorf(code, "scheduling manifest update: %v", err)
 		}
+		resp.PendingUpdate = pendingUpdateToProto(pending)
+		s.recordManifestEvent(ctx, auditlog.EventManifestScheduled, pending.TransitionHash, req.GetManifest(), approvers, map[string]string{
+			"activation_time": pending.ActivationTime.Format(time.RFC3339),
+		})
+		s.logger.Info("SetManifest scheduled the update", "activationTime", pending.ActivationTime)
+		return &resp, nil
+	}
+
+	var se *seedengine.SeedEngine
+	if oldState != nil {
 		se = oldState.SeedEngine()
-		if slices.Compare(oldManifest.SeedshareOwnerPubKeys, m.SeedshareOwnerPubKeys) != 0 {
-			s.logger.Warn("SetManifest detected attempted seedshare owners change", "from", oldManifest.SeedshareOwnerPubKeys, "to", m.SeedshareOwnerPubKeys)
-			return nil, status.Errorf(codes.PermissionDenied, "changes to seedshare owners are not allowed")
-		}
-		if req.GetPreviousTransitionHash() != nil && !bytes.Equal(oldState.LatestTransition().TransitionHash[:], req.GetPreviousTransitionHash()) {
-			return nil, status.Errorf(codes.FailedPrecondition, "previous transition hash '%x' does not match latest state '%x'", req.GetPreviousTransitionHash(), oldState.LatestTransition().TransitionHash)
-		}
 	} else {
 		// First SetManifest call, initialize seed engine.
-		if req.Signature != nil {
-			if err := validateSignature(m.WorkloadOwnerPubKeys, [history.HashSize]byte{}, req); err != nil {
-				s.logger.Warn("SetManifest signature validation failed for initial manifest", "err", err)
-				return nil, status.Errorf(codes.PermissionDenied, "validating manifest signature: %v", err)
-			}
-		}
-		if req.GetPreviousTransitionHash() != nil && !bytes.Equal(req.GetPreviousTransitionHash(), make([]byte, history.HashSize)) {
-			return nil, status.Errorf(codes.FailedPrecondition, "previous transition hash '%x' requested but manifest history is empty", req.GetPreviousTransitionHash())
-		}
 		seed, err := cryptohelpers.GenerateRandomBytes(constants.SecretSeedSize)
 		if err != nil {
 			return nil, status.Errorf(codes.Internal, "generating random bytes for seed: %v", err)
@@ -160,12 +177,7 @@ func (s *Server) SetManifest(ctx context.Context, req *userapi.SetManifestReques
 		}
 	}
 
-	if err := s.checkManifestSecurity(m); err != nil {
-		s.logger.Warn("SetManifest rejected the manifest", "err", err)
-		return nil, status.Error(codes.InvalidArgument, err.Error())
-	}
-
-	state, err := s.guard.UpdateState(ctx, oldState, se, req.GetManifest(), req.GetPolicies())
+	state, err := s.guard.UpdateState(ctx, oldState, se, req.GetManifest(), req.GetPolicies(), history.JoinSignatures(signatures...))
 	if err != nil {
 		code := codes.Internal
 		if errors.Is(err, stateguard.ErrConcurrentUpdate) {
@@ -175,11 +187,122 @@ func (s *Server) SetManifest(ctx context.Context, req *userapi.SetManifestReques
 	}
 	resp.MeshCA = state.CA().GetMeshCACert()
 	resp.RootCA = state.CA().GetRootCACert()
+	s.recordManifestEvent(ctx, auditlog.EventManifestSet, state.LatestTransition().TransitionHash, req.GetManifest(), approvers, nil)
 
 	s.logger.Info("SetManifest succeeded")
 	return &resp, nil
 }
 
+// DryRunSetManifest checks whether SetManifest would accept the request and returns the changes
+// the update would apply, without modifying the Coordinator state.
+func (s *Server) DryRunSetManifest(ctx context.Context, req *userapi.SetManifestRequest) (*userapi.DryRunSetManifestResponse, error) {
+	s.logger.Info("DryRunSetManifest called")
+
+	oldState, m, _, err := s.checkManifestUpdate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var oldManifest *manifest.Manifest
+	var latestTransitionHash [history.HashSize]byte
+	if oldState != nil {
+		oldManifest = oldState.Manifest()
+		latestTransitionHash = oldState.LatestTransition().TransitionHash
+	}
+	diff, err := manifest.NewDiff(oldManifest, m)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "comparing manifests: %v", err)
+	}
+	wireDiff, err := diffToProto(diff)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "converting manifest diff: %v", err)
+	}
+	nextTransition := &history.Transition{
+		ManifestHash:           history.Digest(req.GetManifest()),
+		PreviousTransitionHash: latestTransitionHash,
+	}
+	nextTransitionHash := nextTransition.Digest()
+
+	s.logger.Info("DryRunSetManifest succeeded")
+	return &userapi.DryRunSetManifestResponse{
+		Diff:               wireDiff,
+		NextTransitionHash: nextTransitionHash[:],
+	}, nil
+}
+
+// checkManifestUpdate runs the checks a manifest update needs to pass before the state is modified.
+// The manifest must be valid and the request must contain a policy for every policy hash in the
+// manifest.
+//
+// It returns the current state, which is nil if no manifest was set yet, the parsed manifest and
+// the workload owner keys that approved the update. Returned errors are gRPC status errors.
+func (s *Server) checkManifestUpdate(ctx context.Context, req *userapi.SetManifestRequest) (*stateguard.State, *manifest.Manifest, []manifest.HexString, error) {
+	oldState, err := s.guard.GetState(ctx)
+	switch {
+	case errors.Is(err, stateguard.ErrStaleState):
+		return nil, nil, nil, status.Error(codes.FailedPrecondition, ErrNeedsRecovery.Error())
+	case errors.Is(err, stateguard.ErrNoState):
+		// This is fine, we are going to set the initial manifest.
+	case err != nil:
+		return nil, nil, nil, status.Errorf(codes.Internal, "getting state: %v", err)
+	}
+
+	var m *manifest.Manifest
+	if err := json.Unmarshal(req.Manifest, &m); err != nil {
+		return nil, nil, nil, status.Errorf(codes.InvalidArgument, "unmarshaling manifest: %v", err)
+	}
+	signatures, err := requestSignatures(req)
+	if err != nil {
+		return nil, nil, nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var approvers []manifest.HexString
+	if oldState != nil {
+		oldManifest := oldState.Manifest()
+		// Subsequent SetManifest call, check permissions of caller.
+		signingDigest := transitionSigningDigest(oldState.LatestTransition().TransitionHash, req.GetManifest())
+		approvers, err = checkApprovals(ctx, oldManifest, signingDigest, signatures)
+		if err != nil {
+			s.logger.Warn("SetManifest approval check failed", "err", err)
+			return nil, nil, nil, status.Errorf(codes.PermissionDenied, "manifest update: %v", err)
+		}
+		if slices.Compare(oldManifest.SeedshareOwnerPubKeys, m.SeedshareOwnerPubKeys) != 0 {
+			s.logger.Warn("SetManifest detected attempted seedshare owners change", "from", oldManifest.SeedshareOwnerPubKeys, "to", m.SeedshareOwnerPubKeys)
+			return nil, nil, nil, status.Errorf(codes.PermissionDenied, "changes to seedshare owners are not allowed")
+		}
+		if oldManifest.KeyAlgorithm != m.KeyAlgorithm {
+			s.logger.Warn("SetManifest detected attempted key algorithm change", "from", oldManifest.KeyAlgorithm, "to", m.KeyAlgorithm)
+			return nil, nil, nil, status.Errorf(codes.PermissionDenied, "changes to the key algorithm are not allowed")
+		}
+		if req.GetPreviousTransitionHash() != nil && !bytes.Equal(oldState.LatestTransition().TransitionHash[:], req.GetPreviousTransitionHash()) {
+			return nil, nil, nil, status.Errorf(codes.FailedPrecondition, "previous transition hash '%x' does not match latest state '%x'", req.GetPreviousTransitionHash(), oldState.LatestTransition().TransitionHash)
+		}
+	} else {
+		if len(signatures) > 0 {
+			signingDigest := transitionSigningDigest([history.HashSize]byte{}, req.GetManifest())
+			if approvers, err = validateSignatures(m.WorkloadOwnerPubKeys, signingDigest, signatures); err != nil {
+				s.logger.Warn("SetManifest signature validation failed for initial manifest", "err", err)
+				return nil, nil, nil, status.Errorf(codes.PermissionDenied, "validating manifest signature: %v", err)
+			}
+		}
+		if req.GetPreviousTransitionHash() != nil && !bytes.Equal(req.GetPreviousTransitionHash(), make([]byte, history.HashSize)) {
+			return nil, nil, nil, status.Errorf(codes.FailedPrecondition, "previous transition hash '%x' requested but manifest history is empty", req.GetPreviousTransitionHash())
+		}
+	}
+
+	if err := s.checkManifestSecurity(m); err != nil {
+		s.logger.Warn("SetManifest rejected the manifest", "err", err)
+		return nil, nil, nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := m.Validate(); err != nil {
+		return nil, nil, nil, status.Errorf(codes.InvalidArgument, "validating manifest: %v", err)
+	}
+	if err := checkPoliciesProvided(m, req.GetPolicies()); err != nil {
+		return nil, nil, nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return oldState, m, approvers, nil
+}
+
 // GetManifests retrieves the current CA certificates, the manifest history and all policies.
 func (s *Server) GetManifests(ctx context.Context, _ *userapi.GetManifestsRequest) (*userapi.GetManifestsResponse, error) {
 	s.logger.Info("GetManifest called")
@@ -197,8 +320,29 @@ func (s *Server) GetManifests(ctx context.Context, _ *userapi.GetManifestsReques
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "getting history: %v", err)
 	}
+	signatures, err := s.guard.GetTransitionSignatures(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "getting transition signatures: %v", err)
+	}
+	checkpoint, err := s.guard.GetHistoryCheckpoint(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "getting history checkpoint: %v", err)
+	}
+	pending, err := s.guard.GetPendingUpdate(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "getting pending update: %v", err)
+	}
+
+	signingKey, err := x509.MarshalPKIXPublicKey(&state.SeedEngine().TransactionSigningKey().PublicKey)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "marshaling signing key: %v", err)
+	}
 
 	ca := state.CA()
+	crl, err := s.meshCACRL(state)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "creating CRL: %v", err)
+	}
 	resp := &userapi.GetManifestsResponse{
 		Manifests: manifests,
 		RootCA:    ca.GetRootCACert(),
@@ -207,15 +351,420 @@ func (s *Server) GetManifests(ctx context.Context, _ *userapi.GetManifestsReques
 			TransitionHash: state.LatestTransition().TransitionHash[:],
 			Signature:      state.LatestTransition().Signature,
 		},
+		SigningKey: pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: signingKey}),
+		CRL:        crl,
 	}
 	for _, policy := range policies {
 		resp.Policies = append(resp.Policies, policy)
 	}
+	for transitionHash, signature := range signatures {
+		resp.TransitionSignatures = append(resp.TransitionSignatures, &userapi.TransitionSignature{
+			TransitionHash: transitionHash[:],
+			Signature:      signature,
+		})
+	}
+	if checkpoint != nil {
+		resp.Checkpoint = &userapi.HistoryCheckpoint{
+			TransitionHash: checkpoint.TransitionHash[:],
+			Generation:     checkpoint.Generation,
+			Signature:      checkpoint.Signature,
+		}
+	}
+	if pending != nil {
+		resp.PendingUpdate = pendingUpdateToProto(pending)
+	}
 
 	s.logger.Info("GetManifest succeeded")
 	return resp, nil
 }
 
+// meshCACRL creates a CRL of the mesh CA that's valid as long as the mesh CA certificate.
+func (s *Server) meshCACRL(state *stateguard.State) ([]byte, error) {
+	meshCA := state.CA()
+	block, _ := pem.Decode(meshCA.GetMeshCACert())
+	if block == nil {
+		return nil, errors.New("decoding mesh CA certificate")
+	}
+	meshCACert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing mesh CA certificate: %w", err)
+	}
+	return s.registry.CRL(state.SeedEngine().TransactionSigningKey(), meshCA, meshCACert.NotAfter)
+}
+
+// CancelPendingUpdate cancels a scheduled manifest update.
+//
+// The cancellation needs to be approved by the workload owners of the current manifest like a
+// manifest update.
+func (s *Server) CancelPendingUpdate(ctx context.Context, req *userapi.CancelPendingUpdateRequest) (*userapi.CancelPendingUpdateResponse, error) {
+	s.logger.Info("CancelPendingUpdate called")
+
+	state, err := s.guard.GetState(ctx)
+	switch {
+	case errors.Is(err, stateguard.ErrNoState):
+		return nil, status.Error(codes.FailedPrecondition, ErrNoManifest.Error())
+	case errors.Is(err, stateguard.ErrStaleState):
+		return nil, status.Error(codes.FailedPrecondition, ErrNeedsRecovery.Error())
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "getting state: %v", err)
+	}
+
+	var transitionHash [history.HashSize]byte
+	if len(req.GetTransitionHash()) != history.HashSize {
+		return nil, status.Errorf(codes.InvalidArgument, "transition hash must be %d bytes long", history.HashSize)
+	}
+	copy(transitionHash[:], req.GetTransitionHash())
+	var signatures [][]byte
+	for _, bundle := range req.GetSignatures() {
+		split, err := history.SplitSignatures(bundle)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "parsing cancellation signatures: %v", err)
+		}
+		signatures = append(signatures, split...)
+	}
+	approvers, err := checkApprovals(ctx, state.Manifest(), history.CancellationSigningDigest(transitionHash), signatures)
+	if err != nil {
+		s.logger.Warn("CancelPendingUpdate approval check failed", "err", err)
+		return nil, status.Errorf(codes.PermissionDenied, "cancellation: %v", err)
+	}
+	if err := s.guard.CancelPendingUpdate(ctx, transitionHash); err != nil {
+		code := codes.Internal
+		switch {
+		case errors.Is(err, stateguard.ErrNoPendingUpdate):
+			code = codes.NotFound
+		case errors.Is(err, stateguard.ErrConcurrentUpdate):
+			code = codes.FailedPrecondition
+		}
+		return nil, status.Errorf(code, "cancelling pending update: %v", err)
+	}
+	var approverStrings []string
+	for _, approver := range approvers {
+		approverStrings = append(approverStrings, approver.String())
+	}
+	actor, peerAddress := peerIdentity(ctx)
+	s.audit.Record(auditlog.Event{
+		Type:        auditlog.EventManifestCancelled,
+		Actor:       actor,
+		PeerAddress: peerAddress,
+		Details: map[string]string{
+			"transition": manifest.NewHexString(transitionHash[:]).String(),
+			"approvers":  strings.Join(approverStrings, ","),
+		},
+	})
+
+	s.logger.Info("CancelPendingUpdate succeeded")
+	return &userapi.CancelPendingUpdateResponse{}, nil
+}
+
+// Rollback re-activates the manifest of an earlier transition.
+//
+// The rollback creates a new transition to the earlier manifest and its policies, which needs to be
+// authorized by the workload owners of the current manifest like any other manifest update.
+func (s *Server) Rollback(ctx context.Context, req *userapi.RollbackRequest) (*userapi.RollbackResponse, error) {
+	s.logger.Info("Rollback called")
+
+	state, err := s.guard.GetState(ctx)
+	switch {
+	case errors.Is(err, stateguard.ErrNoState):
+		return nil, status.Error(codes.FailedPrecondition, ErrNoManifest.Error())
+	case errors.Is(err, stateguard.ErrStaleState):
+		return nil, status.Error(codes.FailedPrecondition, ErrNeedsRecovery.Error())
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "getting state: %v", err)
+	}
+
+	if len(req.GetTransitionHash()) != history.HashSize {
+		return nil, status.Errorf(codes.InvalidArgument, "transition hash must be %d bytes long", history.HashSize)
+	}
+	manifestBytes, policies, err := s.guard.GetRollbackTarget(ctx, state, [history.HashSize]byte(req.GetTransitionHash()))
+	switch {
+	case errors.Is(err, stateguard.ErrTransitionNotFound):
+		return nil, status.Errorf(codes.NotFound, "getting rollback target: %v", err)
+	case errors.Is(err, stateguard.ErrSeedshareOwnersChanged):
+		return nil, status.Errorf(codes.FailedPrecondition, "getting rollback target: %v", err)
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "getting rollback target: %v", err)
+	}
+
+	setReq := &userapi.SetManifestRequest{
+		Manifest:               manifestBytes,
+		Policies:               policies,
+		PreviousTransitionHash: req.GetPreviousTransitionHash(),
+		Signatures:             req.GetSignatures(),
+	}
+	oldState, _, approvers, err := s.checkManifestUpdate(ctx, setReq)
+	if err != nil {
+		return nil, err
+	}
+	if oldState != state {
+		// The rollback target was looked up in another state's history.
+		return nil, status.Errorf(codes.FailedPrecondition, "updating Coordinator state: %v", stateguard.ErrConcurrentUpdate)
+	}
+	signatures, err := requestSignatures(setReq)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	newState, err := s.guard.UpdateState(ctx, oldState, oldState.SeedEngine(), manifestBytes, policies, history.JoinSignatures(signatures...))
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, stateguard.ErrConcurrentUpdate) {
+			code = codes.FailedPrecondition
+		}
+		return nil, status.Errorf(code, "updating Coordinator state: %v", err)
+	}
+
+	s.recordManifestEvent(ctx, auditlog.EventManifestRollback, newState.LatestTransition().TransitionHash, manifestBytes, approvers, map[string]string{
+		"target": manifest.NewHexString(req.GetTransitionHash()).String(),
+	})
+	s.logger.Info("Rollback succeeded", "target", manifest.NewHexString(req.GetTransitionHash()))
+	return &userapi.RollbackResponse{
+		RootCA:         newState.CA().GetRootCACert(),
+		MeshCA:         newState.CA().GetMeshCACert(),
+		TransitionHash: newState.LatestTransition().TransitionHash[:],
+	}, nil
+}
+
+// GetAuditLog returns the events of the audit log.
+//
+// Any workload owner of the current manifest can read the audit log.
+func (s *Server) GetAuditLog(ctx context.Context, req *userapi.GetAuditLogRequest) (*userapi.GetAuditLogResponse, error) {
+	s.logger.Info("GetAuditLog called")
+	if s.audit == nil {
+		return nil, status.Error(codes.Unimplemented, "audit log is not enabled")
+	}
+
+	state, err := s.guard.GetState(ctx)
+	switch {
+	case errors.Is(err, stateguard.ErrNoState):
+		return nil, status.Error(codes.FailedPrecondition, ErrNoManifest.Error())
+	case errors.Is(err, stateguard.ErrStaleState):
+		return nil, status.Error(codes.FailedPrecondition, ErrNeedsRecovery.Error())
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "getting state: %v", err)
+	}
+	if _, err := validatePeer(ctx, state.Manifest().WorkloadOwnerPubKeys); err != nil {
+		s.logger.Warn("GetAuditLog peer validation failed", "err", err)
+		return nil, status.Errorf(codes.PermissionDenied, "validating peer: %v", err)
+	}
+
+	limit := int(req.GetLimit())
+	if limit == 0 || limit > maxAuditLogEvents {
+		limit = maxAuditLogEvents
+	}
+	signingKey := state.SeedEngine().TransactionSigningKey()
+	events, more, err := s.audit.Events(signingKey, req.GetAfter(), req.AfterSequence, limit)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "reading audit log: %v", err)
+	}
+
+	s.logger.Info("GetAuditLog succeeded", "events", len(events), "more", more)
+	return &userapi.GetAuditLogResponse{Events: events, More: more}, nil
+}
+
+// RevokeMeshCerts revokes mesh certificates issued by the mesh CA of the current manifest.
+//
+// Any workload owner of the current manifest can revoke certificates.
+func (s *Server) RevokeMeshCerts(ctx context.Context, req *userapi.RevokeMeshCertsRequest) (*userapi.RevokeMeshCertsResponse, error) {
+	s.logger.Info("RevokeMeshCerts called")
+	if s.registry == nil {
+		return nil, status.Error(codes.Unimplemented, "certificate revocation is not enabled")
+	}
+	if len(req.GetSerialNumbers()) == 0 && req.GetPolicyHash() == "" {
+		return nil, status.Error(codes.InvalidArgument, "neither serial numbers nor policy hash given")
+	}
+	if req.GetReasonCode() < 0 || req.GetReasonCode() > maxCRLReasonCode {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid CRL reason code %d", req.GetReasonCode())
+	}
+	var serialNumbers []*big.Int
+	for _, serialNumber := range req.GetSerialNumbers() {
+		serialNumbers = append(serialNumbers, new(big.Int).SetBytes(serialNumber))
+	}
+
+	state, err := s.guard.GetState(ctx)
+	switch {
+	case errors.Is(err, stateguard.ErrNoState):
+		return nil, status.Error(codes.FailedPrecondition, ErrNoManifest.Error())
+	case errors.Is(err, stateguard.ErrStaleState):
+		return nil, status.Error(codes.FailedPrecondition, ErrNeedsRecovery.Error())
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "getting state: %v", err)
+	}
+	if _, err := validatePeer(ctx, state.Manifest().WorkloadOwnerPubKeys); err != nil {
+		s.logger.Warn("RevokeMeshCerts peer validation failed", "err", err)
+		return nil, status.Errorf(codes.PermissionDenied, "validating peer: %v", err)
+	}
+
+	signingKey := state.SeedEngine().TransactionSigningKey()
+	revoked, err := s.registry.Revoke(signingKey, state.CA(), serialNumbers, req.GetPolicyHash(), int(req.GetReasonCode()))
+	if errors.Is(err, certregistry.ErrUnknownCertificate) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	} else if errors.Is(err, certregistry.ErrSubCA) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "revoking certificates: %v", err)
+	}
+	crl, err := s.registry.CRL(signingKey, state.CA(), time.Now().Add(crlValidity))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "creating CRL: %v", err)
+	}
+
+	resp := &userapi.RevokeMeshCertsResponse{CRL: crl}
+	actor, peerAddress := peerIdentity(ctx)
+	for _, cert := range revoked {
+		resp.SerialNumbers = append(resp.SerialNumbers, cert.SerialNumber.Bytes())
+		s.audit.Record(auditlog.Event{
+			Type:        auditlog.EventMeshCertRevoked,
+			Actor:       actor,
+			PeerAddress: peerAddress,
+			Details: map[string]string{
+				"serial_number": cert.SerialNumber.Text(16),
+				"policy_hash":   cert.PolicyHash,
+				"reason_code":   strconv.Itoa(cert.ReasonCode),
+			},
+		})
+	}
+
+	s.logger.Info("RevokeMeshCerts succeeded", "revoked", len(revoked))
+	return resp, nil
+}
+
+// IssueSubCA issues a CA certificate from the mesh CA of the current manifest, which can issue
+// certificates only for the requested names.
+//
+// The issuance needs to be approved by the workload owners of the current manifest like a manifest
+// update, because the sub CA can issue certificates that are trusted by all workloads.
+func (s *Server) IssueSubCA(ctx context.Context, req *userapi.IssueSubCARequest) (*userapi.IssueSubCAResponse, error) {
+	s.logger.Info("IssueSubCA called")
+	if len(req.GetNames()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no names given")
+	}
+	lifetime := defaultSubCALifetime
+	if req.GetLifetimeSeconds() != 0 {
+		lifetime = time.Duration(req.GetLifetimeSeconds()) * time.Second
+	}
+	if lifetime <= 0 || lifetime > maxSubCALifetime {
+		return nil, status.Errorf(codes.InvalidArgument, "sub CA lifetime must be positive and at most %s", maxSubCALifetime)
+	}
+	csr, err := parseSubCACSR(req.GetCSR())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "parsing CSR: %v", err)
+	}
+
+	state, err := s.guard.GetState(ctx)
+	switch {
+	case errors.Is(err, stateguard.ErrNoState):
+		return nil, status.Error(codes.FailedPrecondition, ErrNoManifest.Error())
+	case errors.Is(err, stateguard.ErrStaleState):
+		return nil, status.Error(codes.FailedPrecondition, ErrNeedsRecovery.Error())
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "getting state: %v", err)
+	}
+	var signatures [][]byte
+	for _, bundle := range req.GetSignatures() {
+		split, err := history.SplitSignatures(bundle)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "parsing sub CA signatures: %v", err)
+		}
+		signatures = append(signatures, split...)
+	}
+	signingDigest := history.SubCASigningDigest(state.LatestTransition().TransitionHash, req.GetCSR(), req.GetNames(), req.GetLifetimeSeconds())
+	approvers, err := checkApprovals(ctx, state.Manifest(), signingDigest, signatures)
+	if err != nil {
+		s.logger.Warn("IssueSubCA approval check failed", "err", err)
+		return nil, status.Errorf(codes.PermissionDenied, "sub CA issuance: %v", err)
+	}
+
+	meshCA := state.CA()
+	certPEM, err := meshCA.NewSubCACert(req.GetNames(), csr.PublicKey, csr.Subject, lifetime)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "issuing sub CA certificate: %v", err)
+	}
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return nil, status.Error(codes.Internal, "decoding issued sub CA certificate")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "parsing issued sub CA certificate: %v", err)
+	}
+	err = s.registry.RecordIssued(state.SeedEngine().TransactionSigningKey(), meshCA, certregistry.Certificate{
+		SerialNumber: cert.SerialNumber,
+		IssuedAt:     time.Now().UTC(),
+		NotAfter:     cert.NotAfter,
+		SubCA:        true,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "recording sub CA certificate: %v", err)
+	}
+
+	actor, peerAddress := peerIdentity(ctx)
+	s.audit.Record(auditlog.Event{
+		Type:        auditlog.EventSubCAIssued,
+		Actor:       actor,
+		PeerAddress: peerAddress,
+		Details: map[string]string{
+			"serial_number": cert.SerialNumber.Text(16),
+			"names":         strings.Join(req.GetNames(), ","),
+			"not_after":     cert.NotAfter.UTC().Format(time.RFC3339),
+			"approvers":     strings.Join(hexStringsToStrings(approvers), ","),
+		},
+	})
+
+	s.logger.Info("IssueSubCA succeeded", "serialNumber", cert.SerialNumber.Text(16), "names", req.GetNames())
+	return &userapi.IssueSubCAResponse{
+		Certificate:  certPEM,
+		MeshCACert:   meshCA.GetMeshCACert(),
+		IntermCACert: meshCA.GetIntermCACert(),
+		RootCACert:   meshCA.GetRootCACert(),
+	}, nil
+}
+
+// ListMeshCerts returns the inventory of unexpired mesh certificates this Coordinator issued.
+//
+// Any workload owner of the current manifest can list the certificates.
+func (s *Server) ListMeshCerts(ctx context.Context, _ *userapi.ListMeshCertsRequest) (*userapi.ListMeshCertsResponse, error) {
+	s.logger.Info("ListMeshCerts called")
+	if s.registry == nil {
+		return nil, status.Error(codes.Unimplemented, "certificate inventory is not enabled")
+	}
+
+	state, err := s.guard.GetState(ctx)
+	switch {
+	case errors.Is(err, stateguard.ErrNoState):
+		return nil, status.Error(codes.FailedPrecondition, ErrNoManifest.Error())
+	case errors.Is(err, stateguard.ErrStaleState):
+		return nil, status.Error(codes.FailedPrecondition, ErrNeedsRecovery.Error())
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "getting state: %v", err)
+	}
+	if _, err := validatePeer(ctx, state.Manifest().WorkloadOwnerPubKeys); err != nil {
+		s.logger.Warn("ListMeshCerts peer validation failed", "err", err)
+		return nil, status.Errorf(codes.PermissionDenied, "validating peer: %v", err)
+	}
+
+	resp := &userapi.ListMeshCertsResponse{}
+	for _, leaf := range s.registry.Leaves(state.CA()) {
+		cert := &userapi.MeshCert{
+			SerialNumber:       leaf.SerialNumber.Bytes(),
+			PolicyHash:         leaf.PolicyHash,
+			SANs:               leaf.SANs,
+			PeerAddress:        leaf.PeerAddress,
+			IssuedAt:           leaf.IssuedAt.Unix(),
+			NotAfter:           leaf.NotAfter.Unix(),
+			ManifestGeneration: uint64(leaf.ManifestGeneration),
+			CurrentMeshCA:      leaf.CurrentMeshCA,
+		}
+		if leaf.Revoked() {
+			cert.RevokedAt = leaf.RevokedAt.Unix()
+		}
+		resp.Certificates = append(resp.Certificates, cert)
+	}
+
+	s.logger.Info("ListMeshCerts succeeded", "certificates", len(resp.Certificates))
+	return resp, nil
+}
+
 // Recover recovers the Coordinator from a seed and salt.
 func (s *Server) Recover(ctx context.Context, req *userapi.RecoverRequest) (*userapi.RecoverResponse, error) {
 	s.logger.Info("Recover called")
@@ -246,10 +795,20 @@ func (s *Server) Recover(ctx context.Context, req *userapi.RecoverRequest) (*use
 		s.logger.Info("Skipping sanity checks because user recovery was forced")
 	}
 
-	_, err = s.guard.ResetState(ctx, oldState, &seedAuthorizer{req: req, checkManifestSecurity: s.checkManifestSecurity})
+	newState, err := s.guard.ResetState(ctx, oldState, &seedAuthorizer{req: req, checkManifestSecurity: s.checkManifestSecurity})
 	if err != nil {
 		return nil, fmt.Errorf("resetting state: %w", err)
 	}
+	actor, peerAddress := peerIdentity(ctx)
+	s.audit.Record(auditlog.Event{
+		Type:        auditlog.EventRecover,
+		Actor:       actor,
+		PeerAddress: peerAddress,
+		Details: map[string]string{
+			"transition": manifest.NewHexString(newState.LatestTransition().TransitionHash[:]).String(),
+			"force":      strconv.FormatBool(req.GetForce()),
+		},
+	})
 	return &userapi.RecoverResponse{}, nil
 }
 
@@ -271,16 +830,37 @@ func (s *Server) checkManifestSecurity(mnfst *manifest.Manifest) error {
 	return nil
 }
 
+// checkPoliciesProvided verifies that policies contains a policy for every policy hash referenced
+// in the manifest, like the Guard does on UpdateState.
+func checkPoliciesProvided(mnfst *manifest.Manifest, policies [][]byte) error {
+	provided := make(map[[history.HashSize]byte]struct{})
+	for _, policy := range policies {
+		provided[history.Digest(policy)] = struct{}{}
+	}
+	for hexRef := range mnfst.Policies {
+		refSlice, err := hexRef.Bytes()
+		if err != nil {
+			return fmt.Errorf("invalid policy hash: %w", err)
+		}
+		var ref [history.HashSize]byte
+		copy(ref[:], refSlice)
+		if _, ok := provided[ref]; !ok {
+			return fmt.Errorf("no policy provided for hash %q", hexRef)
+		}
+	}
+	return nil
+}
+
 type seedAuthorizer struct {
 	req                   *userapi.RecoverRequest
 	checkManifestSecurity func(*manifest.Manifest) error
 }
 
-func (a *seedAuthorizer) AuthorizeByManifest(ctx context.Context, mnfst *manifest.Manifest) (*seedengine.SeedEngine, *ecdsa.PrivateKey, error) {
+func (a *seedAuthorizer) AuthorizeByManifest(ctx context.Context, mnfst *manifest.Manifest) (*seedengine.SeedEngine, crypto.Signer, error) {
 	if err := a.checkManifestSecurity(mnfst); err != nil {
 		return nil, nil, status.Error(codes.FailedPrecondition, err.Error())
 	}
-	if err := validatePeer(ctx, mnfst.SeedshareOwnerPubKeys); err != nil {
+	if _, err := validatePeer(ctx, mnfst.SeedshareOwnerPubKeys); err != nil {
 		return nil, nil, status.Errorf(codes.PermissionDenied, "peer not authorized to recover existing state: %v", err)
 	}
 
@@ -290,60 +870,191 @@ func (a *seedAuthorizer) AuthorizeByManifest(ctx context.Context, mnfst *manifes
 		return nil, nil, status.Errorf(codes.InvalidArgument, "initializing seed engine: %v", err)
 	}
 
-	meshKey, err := se.GenerateMeshCAKey()
+	meshKey, err := se.GenerateMeshCAKey(mnfst.KeyAlgorithm)
 	if err != nil {
 		return nil, nil, status.Errorf(codes.Internal, "deriving mesh CA key: %v", err)
 	}
 	return se, meshKey, nil
 }
 
-func validateSignature(keys []manifest.HexString, latestTransitionHash [history.HashSize]byte, req *userapi.SetManifestRequest) error {
-	if len(keys) == 0 {
-		return errors.New("setting manifest is disabled (no workload owner keys in manifest)")
+// recordManifestEvent records a manifest change to the audit log.
+func (s *Server) recordManifestEvent(ctx context.Context, eventType auditlog.EventType, transitionHash [history.HashSize]byte, manifestBytes []byte, approvers []manifest.HexString, details map[string]string) {
+	if details == nil {
+		details = make(map[string]string)
+	}
+	manifestHash := history.Digest(manifestBytes)
+	details["transition"] = manifest.NewHexString(transitionHash[:]).String()
+	details["manifest"] = manifest.NewHexString(manifestHash[:]).String()
+	if len(approvers) > 0 {
+		var approverStrings []string
+		for _, approver := range approvers {
+			approverStrings = append(approverStrings, approver.String())
+		}
+		details["approvers"] = strings.Join(approverStrings, ",")
+	}
+	actor, peerAddress := peerIdentity(ctx)
+	s.audit.Record(auditlog.Event{
+		Type:        eventType,
+		Actor:       actor,
+		PeerAddress: peerAddress,
+		Details:     details,
+	})
+}
+
+// peerIdentity returns the hex-encoded public key and the address of the peer, if available.
+func peerIdentity(ctx context.Context) (string, string) {
+	var address string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		address = p.Addr.String()
 	}
-	if len(req.Signature) == 0 {
-		return errNoSignature
+	peerPubKey, err := getPeerPublicKey(ctx)
+	if err != nil {
+		return "", address
+	}
+	return manifest.NewHexString(peerPubKey).String(), address
+}
+
+// diffToProto converts a manifest diff to its wire representation.
+func diffToProto(d *manifest.Diff) (*userapi.ManifestDiff, error) {
+	added, err := json.Marshal(d.AddedReferenceValues)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling added reference values: %w", err)
+	}
+	removed, err := json.Marshal(d.RemovedReferenceValues)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling removed reference values: %w", err)
+	}
+	return &userapi.ManifestDiff{
+		AddedPolicies:                hexStringsToStrings(d.AddedPolicies),
+		RemovedPolicies:              hexStringsToStrings(d.RemovedPolicies),
+		ChangedPolicies:              hexStringsToStrings(d.ChangedPolicies),
+		AddedReferenceValues:         added,
+		RemovedReferenceValues:       removed,
+		AddedWorkloadOwnerPubKeys:    hexStringsToStrings(d.AddedWorkloadOwnerPubKeys),
+		RemovedWorkloadOwnerPubKeys:  hexStringsToStrings(d.RemovedWorkloadOwnerPubKeys),
+		AddedSeedshareOwnerPubKeys:   hexStringsToStrings(d.AddedSeedshareOwnerPubKeys),
+		RemovedSeedshareOwnerPubKeys: hexStringsToStrings(d.RemovedSeedshareOwnerPubKeys),
+	}, nil
+}
+
+func hexStringsToStrings(in []manifest.HexString) []string {
+	var out []string
+	for _, s := range in {
+		out = append(out, s.String())
+	}
+	return out
+}
+
+func pendingUpdateToProto(pending *stateguard.PendingUpdate) *userapi.PendingUpdate {
+	return &userapi.PendingUpdate{
+		TransitionHash: pending.TransitionHash[:],
+		ActivationTime: pending.ActivationTime.Unix(),
+		Manifest:       pending.ManifestBytes,
+	}
+}
+
+// requestSignatures returns all workload owner signatures of the request. Both signature fields
+// may contain signature bundles.
+func requestSignatures(req *userapi.SetManifestRequest) ([][]byte, error) {
+	var signatures [][]byte
+	for _, bundle := range append([][]byte{req.GetSignature()}, req.GetSignatures()...) {
+		split, err := history.SplitSignatures(bundle)
+		if err != nil {
+			return nil, fmt.Errorf("parsing manifest signatures: %w", err)
+		}
+		signatures = append(signatures, split...)
+	}
+	return signatures, nil
+}
+
+// checkApprovals returns the workload owners of m that approved an action, either with a signature
+// over the signing digest or by authenticating the connection. It fails if fewer workload owners
+// approved than m requires.
+func checkApprovals(ctx context.Context, m *manifest.Manifest, signingDigest [history.HashSize]byte, signatures [][]byte) ([]manifest.HexString, error) {
+	approvers, err := validateSignatures(m.WorkloadOwnerPubKeys, signingDigest, signatures)
+	if err != nil && !errors.Is(err, errNoSignature) {
+		return nil, fmt.Errorf("validating signatures: %w", err)
+	}
+	required := m.RequiredWorkloadOwnerApprovals()
+	if len(approvers) < required {
+		// The workload owner key used in the TLS handshake counts as one approval.
+		peerKey, err := validatePeer(ctx, m.WorkloadOwnerPubKeys)
+		if err != nil && len(signatures) == 0 {
+			return nil, fmt.Errorf("validating peer: %w", err)
+		} else if err == nil && !slices.Contains(approvers, peerKey) {
+			approvers = append(approvers, peerKey)
+		}
 	}
+	if len(approvers) < required {
+		return nil, fmt.Errorf("approved by %d workload owners, but %d are required", len(approvers), required)
+	}
+	return approvers, nil
+}
 
+// transitionSigningDigest returns the digest workload owners sign to authorize the transition
+// from the latest transition to the given manifest.
+func transitionSigningDigest(latestTransitionHash [history.HashSize]byte, manifestBytes []byte) [history.HashSize]byte {
 	tr := &history.Transition{
-		ManifestHash:           history.Digest(req.Manifest),
+		ManifestHash:           history.Digest(manifestBytes),
 		PreviousTransitionHash: latestTransitionHash,
 	}
-	hash := tr.Digest()
-	// Hash again because we do hash+sign on the blob that contains the hex-encoded next transition hash.
-	hash = history.Digest(hex.AppendEncode(nil, hash[:]))
+	return history.TransitionSigningDigest(tr.Digest())
+}
 
+// validateSignatures checks that each signature is a signature over the signing digest by one of
+// the keys. It returns the distinct keys that produced the signatures.
+func validateSignatures(keys []manifest.HexString, signingDigest [history.HashSize]byte, signatures [][]byte) ([]manifest.HexString, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("setting manifest is disabled (no workload owner keys in manifest)")
+	}
+	if len(signatures) == 0 {
+		return nil, errNoSignature
+	}
+
+	trustedWorkloadOwnerKeys := make([]*ecdsa.PublicKey, 0, len(keys))
 	for _, key := range keys {
 		trustedWorkloadOwnerKey, err := manifest.ParseWorkloadOwnerPublicKey(key)
 		if err != nil {
-			return fmt.Errorf("parsing key: %w", err)
+			return nil, fmt.Errorf("parsing key: %w", err)
 		}
-		if ecdsa.VerifyASN1(trustedWorkloadOwnerKey, hash[:], req.Signature) {
-			return nil
+		trustedWorkloadOwnerKeys = append(trustedWorkloadOwnerKeys, trustedWorkloadOwnerKey)
+	}
+
+	var signers []manifest.HexString
+	for _, signature := range signatures {
+		idx := slices.IndexFunc(trustedWorkloadOwnerKeys, func(key *ecdsa.PublicKey) bool {
+			return ecdsa.VerifyASN1(key, signingDigest[:], signature)
+		})
+		if idx < 0 {
+			return nil, errors.New("invalid signature")
+		}
+		if !slices.Contains(signers, keys[idx]) {
+			signers = append(signers, keys[idx])
 		}
 	}
-	return errors.New("invalid manifest signature")
+	return signers, nil
 }
 
-func validatePeer(ctx context.Context, keys []manifest.HexString) error {
+// validatePeer checks that the peer authenticated with one of the keys and returns the key.
+func validatePeer(ctx context.Context, keys []manifest.HexString) (manifest.HexString, error) {
 	if len(keys) == 0 {
-		return errors.New("setting manifest is disabled")
+		return "", errors.New("setting manifest is disabled")
 	}
 
 	peerPubKey, err := getPeerPublicKey(ctx)
 	if err != nil {
-		return err
+		return "", err
 	}
 	for _, key := range keys {
 		trustedWorkloadOwnerKey, err := key.Bytes()
 		if err != nil {
-			return fmt.Errorf("parsing key: %w", err)
+			return "", fmt.Errorf("parsing key: %w", err)
 		}
 		if bytes.Equal(peerPubKey, trustedWorkloadOwnerKey) {
-			return nil
+			return key, nil
 		}
 	}
-	return errors.New("peer not authorized")
+	return "", errors.New("peer not authorized")
 }
 
 func getPeerPublicKey(ctx context.Context) ([]byte, error) {
@@ -369,6 +1080,38 @@ func getPeerPublicKey(ctx context.Context) ([]byte, error) {
 	}
 }
 
+// parseSubCACSR parses a PEM-encoded CSR and verifies its signature, which proves that the
+// requester holds the private key of the sub CA.
+func parseSubCACSR(csrPEM []byte) (*x509.CertificateRequest, error) {
+	block, _ := pem.Decode(csrPEM)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, errors.New("no PEM block of type CERTIFICATE REQUEST")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("checking signature: %w", err)
+	}
+	return csr, nil
+}
+
+const (
+	// maxCRLReasonCode is the highest CRL reason code defined in RFC 5280, section 5.3.1.
+	maxCRLReasonCode = 10
+	// crlValidity is the validity period of the CRL returned by RevokeMeshCerts.
+	crlValidity = 24 * time.Hour
+	// defaultSubCALifetime is the lifetime of sub CA certificates if the request doesn't set one.
+	defaultSubCALifetime = 24 * time.Hour
+	// maxAuditLogEvents is the maximum number of events returned by GetAuditLog, which keeps the
+	// response well below the gRPC message size limit.
+	maxAuditLogEvents = 1000
+	// maxSubCALifetime is the longest lifetime of sub CA certificates. Sub CAs can't be revoked,
+	// so their lifetime is kept short.
+	maxSubCALifetime = 7 * 24 * time.Hour
+)
+
 var (
 	// ErrNoManifest is returned when a manifest is needed but not present.
 	ErrNoManifest = errors.New("no manifest configured")
@@ -19,14 +19,21 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"math/big"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/edgelesssys/contrast/coordinator/internal/certregistry"
 	"github.com/edgelesssys/contrast/coordinator/internal/stateguard"
+	"github.com/edgelesssys/contrast/internal/auditlog"
+	"github.com/edgelesssys/contrast/internal/constants"
+	"github.com/edgelesssys/contrast/internal/cryptohelpers"
 	"github.com/edgelesssys/contrast/internal/history"
 	"github.com/edgelesssys/contrast/internal/history/aferostore"
 	"github.com/edgelesssys/contrast/internal/manifest"
+	"github.com/edgelesssys/contrast/internal/seedengine"
 	"github.com/edgelesssys/contrast/internal/testkeys"
 	"github.com/edgelesssys/contrast/internal/userapi"
 	"github.com/google/go-sev-guest/abi"
@@ -42,11 +49,8 @@ import (
 )
 
 func TestSetManifest(t *testing.T) {
-	newBaseManifest := func() *manifest.Manifest {
-		return &manifest.Manifest{}
-	}
 	newManifestBytes := func(f func(*manifest.Manifest)) []byte {
-		m := newBaseManifest()
+		m := newTestManifest()
 		if f != nil {
 			f(m)
 		}
@@ -74,7 +78,16 @@ func TestSetManifest(t *testing.T) {
 					m.Policies = nil
 				}),
 			},
-			wantErr: false,
+			wantErr: true,
+		},
+		"invalid manifest": {
+			req: &userapi.SetManifestRequest{
+				Manifest: newManifestBytes(func(m *manifest.Manifest) {
+					m.ReferenceValues = manifest.ReferenceValues{}
+				}),
+				Policies: testPolicies(),
+			},
+			wantErr: true,
 		},
 		"request without policies": {
 			req: &userapi.SetManifestRequest{
@@ -90,15 +103,10 @@ func TestSetManifest(t *testing.T) {
 		"policy not in manifest": {
 			req: &userapi.SetManifestRequest{
 				Manifest: newManifestBytes(func(m *manifest.Manifest) {
-					m.Policies = map[manifest.HexString]manifest.PolicyEntry{
-						manifest.HexString("ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"): {SANs: []string{"a1", "a2"}, WorkloadSecretID: "a3"},
-						manifest.HexString("3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d"): {SANs: []string{"b1", "b2"}, WorkloadSecretID: "b3"},
-					}
+					m.Policies[manifest.HexString("ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb")] = manifest.PolicyEntry{SANs: []string{"a1", "a2"}, WorkloadSecretID: "a3"}
+					m.Policies[manifest.HexString("3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d")] = manifest.PolicyEntry{SANs: []string{"b1", "b2"}, WorkloadSecretID: "b3"}
 				}),
-				Policies: [][]byte{
-					[]byte("a"),
-					[]byte("c"),
-				},
+				Policies: testPolicies([]byte("a"), []byte("c")),
 			},
 			wantErr: true,
 		},
@@ -106,37 +114,29 @@ func TestSetManifest(t *testing.T) {
 		"valid manifest": {
 			req: &userapi.SetManifestRequest{
 				Manifest: newManifestBytes(func(m *manifest.Manifest) {
-					m.Policies = map[manifest.HexString]manifest.PolicyEntry{
-						manifest.HexString("ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"): {SANs: []string{"a1", "a2"}, WorkloadSecretID: "a3"},
-						manifest.HexString("3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d"): {SANs: []string{"b1", "b2"}, WorkloadSecretID: "b3"},
-					}
+					m.Policies[manifest.HexString("ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb")] = manifest.PolicyEntry{SANs: []string{"a1", "a2"}, WorkloadSecretID: "a3"}
+					m.Policies[manifest.HexString("3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d")] = manifest.PolicyEntry{SANs: []string{"b1", "b2"}, WorkloadSecretID: "b3"}
 				}),
-				Policies: [][]byte{
-					[]byte("a"),
-					[]byte("b"),
-				},
+				Policies: testPolicies([]byte("a"), []byte("b")),
 			},
 		},
 		"nil transition hash": {
 			req: &userapi.SetManifestRequest{
-				Manifest: newManifestBytes(func(m *manifest.Manifest) {
-					m.Policies = nil
-				}),
+				Manifest: newManifestBytes(nil),
+				Policies: testPolicies(),
 			},
 		},
 		"empty transition hash": {
 			req: &userapi.SetManifestRequest{
-				Manifest: newManifestBytes(func(m *manifest.Manifest) {
-					m.Policies = nil
-				}),
+				Manifest:               newManifestBytes(nil),
+				Policies:               testPolicies(),
 				PreviousTransitionHash: bytes.Repeat([]byte{0x0}, history.HashSize),
 			},
 		},
 		"invalid transition hash": {
 			req: &userapi.SetManifestRequest{
-				Manifest: newManifestBytes(func(m *manifest.Manifest) {
-					m.Policies = nil
-				}),
+				Manifest:               newManifestBytes(nil),
+				Policies:               testPolicies(),
 				PreviousTransitionHash: bytes.Repeat([]byte{0xf}, history.HashSize),
 			},
 			wantErr: true,
@@ -187,20 +187,15 @@ func TestSetManifest(t *testing.T) {
 			ctx := rpcContext(t.Context(), tc.workloadOwnerKey)
 			m, err := json.Marshal(manifestWithTrustedKey)
 			require.NoError(err)
-			_, err = coordinator.SetManifest(ctx, &userapi.SetManifestRequest{Manifest: m})
+			_, err = coordinator.SetManifest(ctx, &userapi.SetManifestRequest{Manifest: m, Policies: testPolicies()})
 			require.NoError(err)
 
 			req := &userapi.SetManifestRequest{
 				Manifest: newManifestBytes(func(m *manifest.Manifest) {
-					m.Policies = map[manifest.HexString]manifest.PolicyEntry{
-						manifest.HexString("ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"): {SANs: []string{"a1", "a2"}, WorkloadSecretID: "a3"},
-						manifest.HexString("3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d"): {SANs: []string{"b1", "b2"}, WorkloadSecretID: "b3"},
-					}
+					m.Policies[manifest.HexString("ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb")] = manifest.PolicyEntry{SANs: []string{"a1", "a2"}, WorkloadSecretID: "a3"}
+					m.Policies[manifest.HexString("3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d")] = manifest.PolicyEntry{SANs: []string{"b1", "b2"}, WorkloadSecretID: "b3"}
 				}),
-				Policies: [][]byte{
-					[]byte("a"),
-					[]byte("b"),
-				},
+				Policies: testPolicies([]byte("a"), []byte("b")),
 			}
 			_, err = coordinator.SetManifest(ctx, req)
 			require.Equal(tc.wantCode, status.Code(err))
@@ -214,7 +209,7 @@ func TestSetManifest(t *testing.T) {
 		ctx := rpcContext(t.Context(), trustedKey)
 		m, err := json.Marshal(manifestWithoutTrustedKey)
 		require.NoError(err)
-		req := &userapi.SetManifestRequest{Manifest: m}
+		req := &userapi.SetManifestRequest{Manifest: m, Policies: testPolicies()}
 		_, err = coordinator.SetManifest(ctx, req)
 		require.NoError(err)
 		_, err = coordinator.SetManifest(ctx, req)
@@ -282,7 +277,7 @@ func TestSetManifest(t *testing.T) {
 
 				manifestBytes, err := json.Marshal(tc.manifest(t))
 				require.NoError(err)
-				resp, err := coordinator.SetManifest(t.Context(), &userapi.SetManifestRequest{Manifest: manifestBytes})
+				resp, err := coordinator.SetManifest(t.Context(), &userapi.SetManifestRequest{Manifest: manifestBytes, Policies: testPolicies()})
 				if tc.wantErr == nil {
 					require.NoError(err)
 					require.NotNil(resp)
@@ -303,17 +298,17 @@ func TestSetManifest(t *testing.T) {
 		ctx := rpcContext(t.Context(), trustedKey)
 		m, err := json.Marshal(manifestWithTrustedKey)
 		require.NoError(err)
-		req := &userapi.SetManifestRequest{Manifest: m}
+		req := &userapi.SetManifestRequest{Manifest: m, Policies: testPolicies()}
 		_, err = coordinator.SetManifest(ctx, req)
 		require.NoError(err)
 		tr := history.Transition{
 			ManifestHash: history.Digest(m),
 		}
 		prevTransitionHash := tr.Digest()
-		req = &userapi.SetManifestRequest{Manifest: m, PreviousTransitionHash: prevTransitionHash[:]}
+		req = &userapi.SetManifestRequest{Manifest: m, Policies: testPolicies(), PreviousTransitionHash: prevTransitionHash[:]}
 		_, err = coordinator.SetManifest(ctx, req)
 		require.NoError(err)
-		req = &userapi.SetManifestRequest{Manifest: m, PreviousTransitionHash: prevTransitionHash[:]}
+		req = &userapi.SetManifestRequest{Manifest: m, Policies: testPolicies(), PreviousTransitionHash: prevTransitionHash[:]}
 		_, err = coordinator.SetManifest(ctx, req)
 		require.Error(err)
 	})
@@ -334,6 +329,7 @@ func TestSetManifest(t *testing.T) {
 		require.NoError(err)
 		req := &userapi.SetManifestRequest{
 			Manifest:  m,
+			Policies:  testPolicies(),
 			Signature: sig,
 		}
 		_, err = coordinator.SetManifest(ctx, req)
@@ -348,10 +344,281 @@ func TestSetManifest(t *testing.T) {
 		require.NoError(err)
 		req = &userapi.SetManifestRequest{
 			Manifest:  m,
+			Policies:  testPolicies(),
 			Signature: sig,
 		}
 		_, err = coordinator.SetManifest(ctx, req)
 		require.NoError(err)
+
+		// The signatures are persisted with the history.
+		resp, err := coordinator.GetManifests(ctx, &userapi.GetManifestsRequest{})
+		require.NoError(err)
+		require.Len(resp.TransitionSignatures, 2)
+		require.Contains(resp.TransitionSignatures, &userapi.TransitionSignature{
+			TransitionHash: nextTransitionHash[:],
+			Signature:      sig,
+		})
+	})
+
+	t.Run("threshold manifest update", func(t *testing.T) {
+		ownerKeys := []*ecdsa.PrivateKey{
+			testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[0]),
+			testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[1]),
+			testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP256Keys[0]),
+		}
+		thresholdManifest := newTestManifest()
+		thresholdManifest.WorkloadOwnerThreshold = 2
+		for _, key := range ownerKeys[:2] {
+			thresholdManifest.WorkloadOwnerPubKeys = append(thresholdManifest.WorkloadOwnerPubKeys, manifest.MarshalWorkloadOwnerPubKey(&key.PublicKey))
+		}
+		m, err := json.Marshal(thresholdManifest)
+		require.NoError(t, err)
+		initialTransition := history.Transition{ManifestHash: history.Digest(m)}
+		nextTransition := history.Transition{ManifestHash: history.Digest(m), PreviousTransitionHash: initialTransition.Digest()}
+		sign := func(key *ecdsa.PrivateKey) []byte {
+			signingHash := history.TransitionSigningDigest(nextTransition.Digest())
+			sig, err := ecdsa.SignASN1(rand.Reader, key, signingHash[:])
+			require.NoError(t, err)
+			return sig
+		}
+
+		testCases := map[string]struct {
+			peerKey        *ecdsa.PrivateKey
+			signature      []byte
+			signatures     [][]byte
+			wantCode       codes.Code
+			wantSignatures int
+		}{
+			"single signature": {
+				signature: sign(ownerKeys[0]),
+				wantCode:  codes.PermissionDenied,
+			},
+			"peer only": {
+				peerKey:  ownerKeys[0],
+				wantCode: codes.PermissionDenied,
+			},
+			"two signatures": {
+				signatures:     [][]byte{sign(ownerKeys[0]), sign(ownerKeys[1])},
+				wantSignatures: 2,
+			},
+			"signature bundle": {
+				signature:      history.JoinSignatures(sign(ownerKeys[0]), sign(ownerKeys[1])),
+				wantSignatures: 2,
+			},
+			"signature and peer": {
+				peerKey:        ownerKeys[1],
+				signature:      sign(ownerKeys[0]),
+				wantSignatures: 1,
+			},
+			"same key twice": {
+				signatures: [][]byte{sign(ownerKeys[0]), sign(ownerKeys[0])},
+				wantCode:   codes.PermissionDenied,
+			},
+			"signature and peer with same key": {
+				peerKey:   ownerKeys[0],
+				signature: sign(ownerKeys[0]),
+				wantCode:  codes.PermissionDenied,
+			},
+			"unauthorized signature": {
+				signatures: [][]byte{sign(ownerKeys[0]), sign(ownerKeys[1]), sign(ownerKeys[2])},
+				wantCode:   codes.PermissionDenied,
+			},
+			"malformed signature bundle": {
+				signature: []byte("not a signature"),
+				wantCode:  codes.InvalidArgument,
+			},
+		}
+
+		for name, tc := range testCases {
+			t.Run(name, func(t *testing.T) {
+				require := require.New(t)
+
+				coordinator := newCoordinator()
+				_, err := coordinator.SetManifest(rpcContext(t.Context(), nil), &userapi.SetManifestRequest{Manifest: m, Policies: testPolicies()})
+				require.NoError(err)
+
+				req := &userapi.SetManifestRequest{
+					Manifest:   m,
+					Policies:   testPolicies(),
+					Signature:  tc.signature,
+					Signatures: tc.signatures,
+				}
+				_, err = coordinator.SetManifest(rpcContext(t.Context(), tc.peerKey), req)
+				require.Equal(tc.wantCode, status.Code(err), "unexpected error: %v", err)
+				if tc.wantCode != codes.OK {
+					return
+				}
+
+				// All signatures are persisted with the history.
+				resp, err := coordinator.GetManifests(t.Context(), &userapi.GetManifestsRequest{})
+				require.NoError(err)
+				require.Len(resp.TransitionSignatures, 1)
+				signatures, err := history.SplitSignatures(resp.TransitionSignatures[0].Signature)
+				require.NoError(err)
+				require.Len(signatures, tc.wantSignatures)
+			})
+		}
+	})
+
+	t.Run("unreachable threshold", func(t *testing.T) {
+		require := require.New(t)
+
+		ownerKey := testkeys.ECDSA(t)
+		mnfst := manifestWithWorkloadOwnerKey(ownerKey)
+		mnfst.WorkloadOwnerThreshold = 2
+		m, err := json.Marshal(mnfst)
+		require.NoError(err)
+
+		coordinator := newCoordinator()
+		_, err = coordinator.SetManifest(rpcContext(t.Context(), ownerKey), &userapi.SetManifestRequest{Manifest: m, Policies: testPolicies()})
+		require.Equal(codes.InvalidArgument, status.Code(err), "a threshold above the number of workload owner keys must be rejected")
+	})
+}
+
+func TestDryRunSetManifest(t *testing.T) {
+	trustedKey := testkeys.ECDSA(t)
+	untrustedKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[1])
+
+	t.Run("initial manifest", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		coordinator := newCoordinator()
+		mnfstBytes, policies := newManifestWithSeedshareOwner(t)
+		var mnfst manifest.Manifest
+		require.NoError(json.Unmarshal(mnfstBytes, &mnfst))
+
+		resp, err := coordinator.DryRunSetManifest(t.Context(), &userapi.SetManifestRequest{
+			Manifest: mnfstBytes,
+			Policies: policies,
+		})
+		require.NoError(err)
+
+		policyHash := sha256.Sum256(policies[0])
+		assert.Equal([]string{manifest.NewHexString(policyHash[:]).String()}, resp.Diff.AddedPolicies)
+		var addedReferenceValues manifest.ReferenceValues
+		require.NoError(json.Unmarshal(resp.Diff.AddedReferenceValues, &addedReferenceValues))
+		assert.Equal(mnfst.ReferenceValues, addedReferenceValues)
+		assert.Equal(hexStringsToStrings(mnfst.WorkloadOwnerPubKeys), resp.Diff.AddedWorkloadOwnerPubKeys)
+		assert.Equal(hexStringsToStrings(mnfst.SeedshareOwnerPubKeys), resp.Diff.AddedSeedshareOwnerPubKeys)
+		tr := history.Transition{ManifestHash: history.Digest(mnfstBytes)}
+		nextTransitionHash := tr.Digest()
+		assert.Equal(nextTransitionHash[:], resp.NextTransitionHash)
+
+		// The dry run must not have modified the state.
+		_, err = coordinator.GetManifests(t.Context(), &userapi.GetManifestsRequest{})
+		require.Equal(codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("invalid manifest", func(t *testing.T) {
+		require := require.New(t)
+
+		coordinator := newCoordinator()
+		mnfst := manifestWithWorkloadOwnerKey(trustedKey)
+		mnfst.ReferenceValues = manifest.ReferenceValues{}
+		m, err := json.Marshal(mnfst)
+		require.NoError(err)
+		_, err = coordinator.DryRunSetManifest(t.Context(), &userapi.SetManifestRequest{Manifest: m, Policies: testPolicies()})
+		require.Equal(codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("missing policy", func(t *testing.T) {
+		require := require.New(t)
+
+		coordinator := newCoordinator()
+		mnfstBytes, _ := newManifestWithSeedshareOwner(t)
+		_, err := coordinator.DryRunSetManifest(t.Context(), &userapi.SetManifestRequest{Manifest: mnfstBytes})
+		require.Equal(codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("subsequent manifest", func(t *testing.T) {
+		mnfstBytes, policies := newManifestWithSeedshareOwner(t)
+		var mnfst manifest.Manifest
+		require.NoError(t, json.Unmarshal(mnfstBytes, &mnfst))
+
+		newPolicy := []byte("=== SOME OTHER REGO HERE ===")
+		newPolicyHash := sha256.Sum256(newPolicy)
+		updated := mnfst
+		updated.Policies = maps.Clone(mnfst.Policies)
+		updated.Policies[manifest.NewHexString(newPolicyHash[:])] = manifest.PolicyEntry{SANs: []string{"other"}}
+		updatedBytes, err := json.Marshal(updated)
+		require.NoError(t, err)
+
+		changedSeedshareOwners := updated
+		changedSeedshareOwners.SeedshareOwnerPubKeys = nil
+		changedSeedshareOwnersBytes, err := json.Marshal(changedSeedshareOwners)
+		require.NoError(t, err)
+
+		changedKeyAlgorithm := updated
+		changedKeyAlgorithm.KeyAlgorithm = cryptohelpers.KeyAlgorithmRSA3072
+		changedKeyAlgorithmBytes, err := json.Marshal(changedKeyAlgorithm)
+		require.NoError(t, err)
+
+		testCases := map[string]struct {
+			key      *ecdsa.PrivateKey
+			req      *userapi.SetManifestRequest
+			wantCode codes.Code
+		}{
+			"trusted key": {
+				key: trustedKey,
+				req: &userapi.SetManifestRequest{Manifest: updatedBytes, Policies: append(policies, newPolicy)},
+			},
+			"untrusted key": {
+				key:      untrustedKey,
+				req:      &userapi.SetManifestRequest{Manifest: updatedBytes, Policies: append(policies, newPolicy)},
+				wantCode: codes.PermissionDenied,
+			},
+			"seedshare owner change": {
+				key:      trustedKey,
+				req:      &userapi.SetManifestRequest{Manifest: changedSeedshareOwnersBytes, Policies: append(policies, newPolicy)},
+				wantCode: codes.PermissionDenied,
+			},
+			"key algorithm change": {
+				key:      trustedKey,
+				req:      &userapi.SetManifestRequest{Manifest: changedKeyAlgorithmBytes, Policies: append(policies, newPolicy)},
+				wantCode: codes.PermissionDenied,
+			},
+			"wrong previous transition": {
+				key: trustedKey,
+				req: &userapi.SetManifestRequest{
+					Manifest:               updatedBytes,
+					Policies:               append(policies, newPolicy),
+					PreviousTransitionHash: bytes.Repeat([]byte{0xf}, history.HashSize),
+				},
+				wantCode: codes.FailedPrecondition,
+			},
+		}
+
+		for name, tc := range testCases {
+			t.Run(name, func(t *testing.T) {
+				require := require.New(t)
+				assert := assert.New(t)
+
+				coordinator := newCoordinator()
+				ctx := rpcContext(t.Context(), tc.key)
+				_, err := coordinator.SetManifest(ctx, &userapi.SetManifestRequest{Manifest: mnfstBytes, Policies: policies})
+				require.NoError(err)
+				before, err := coordinator.GetManifests(ctx, &userapi.GetManifestsRequest{})
+				require.NoError(err)
+
+				resp, err := coordinator.DryRunSetManifest(ctx, tc.req)
+				require.Equal(tc.wantCode, status.Code(err))
+
+				after, err := coordinator.GetManifests(ctx, &userapi.GetManifestsRequest{})
+				require.NoError(err)
+				assert.Equal(before.LatestTransition.TransitionHash, after.LatestTransition.TransitionHash)
+
+				if tc.wantCode != codes.OK {
+					return
+				}
+				assert.Equal([]string{manifest.NewHexString(newPolicyHash[:]).String()}, resp.Diff.AddedPolicies)
+				assert.Empty(resp.Diff.RemovedPolicies)
+				assert.Empty(resp.Diff.AddedWorkloadOwnerPubKeys)
+				var addedReferenceValues manifest.ReferenceValues
+				require.NoError(json.Unmarshal(resp.Diff.AddedReferenceValues, &addedReferenceValues))
+				assert.Empty(addedReferenceValues.SNP)
+			})
+		}
 	})
 }
 
@@ -366,20 +633,15 @@ func TestGetManifests(t *testing.T) {
 	require.Equal(codes.FailedPrecondition, status.Code(err))
 	assert.Nil(resp)
 
-	m := &manifest.Manifest{}
-	m.Policies = map[manifest.HexString]manifest.PolicyEntry{
-		manifest.HexString("ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"): {SANs: []string{"a1", "a2"}, WorkloadSecretID: "a3"},
-		manifest.HexString("3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d"): {SANs: []string{"b1", "b2"}, WorkloadSecretID: "b3"},
-	}
+	m := newTestManifest()
+	m.Policies[manifest.HexString("ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb")] = manifest.PolicyEntry{SANs: []string{"a1", "a2"}, WorkloadSecretID: "a3"}
+	m.Policies[manifest.HexString("3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d")] = manifest.PolicyEntry{SANs: []string{"b1", "b2"}, WorkloadSecretID: "b3"}
 	manifestBytes, err := json.Marshal(m)
 	require.NoError(err)
 
 	req := &userapi.SetManifestRequest{
 		Manifest: manifestBytes,
-		Policies: [][]byte{
-			[]byte("a"),
-			[]byte("b"),
-		},
+		Policies: testPolicies([]byte("a"), []byte("b")),
 	}
 	setResp, err := coordinator.SetManifest(ctx, req)
 	require.NoError(err)
@@ -391,6 +653,524 @@ func TestGetManifests(t *testing.T) {
 	assert.Equal("system:coordinator:root", parsePEMCertificate(t, resp.RootCA).Subject.CommonName)
 	assert.Equal("system:coordinator:intermediate", parsePEMCertificate(t, resp.MeshCA).Subject.CommonName)
 	assert.Len(resp.Policies, len(m.Policies))
+
+	block, _ := pem.Decode(resp.SigningKey)
+	require.NotNil(block)
+	signingKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	require.NoError(err)
+	assert.IsType(&ecdsa.PublicKey{}, signingKey)
+}
+
+func TestPendingUpdate(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	ownerKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[0])
+	otherKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[1])
+	m, err := json.Marshal(manifestWithWorkloadOwnerKey(ownerKey))
+	require.NoError(err)
+	ctx := rpcContext(t.Context(), ownerKey)
+
+	coordinator := newCoordinator()
+	activationTime := time.Now().Add(time.Hour)
+	_, err = coordinator.SetManifest(ctx, &userapi.SetManifestRequest{Manifest: m, Policies: testPolicies(), ActivationTime: activationTime.Unix()})
+	require.Equal(codes.InvalidArgument, status.Code(err), "the initial manifest can't be scheduled")
+
+	setResp, err := coordinator.SetManifest(ctx, &userapi.SetManifestRequest{Manifest: m, Policies: testPolicies()})
+	require.NoError(err)
+	require.Nil(setResp.PendingUpdate)
+
+	updated, err := json.Marshal(manifestWithWorkloadOwnerKey(otherKey))
+	require.NoError(err)
+	setResp, err = coordinator.SetManifest(ctx, &userapi.SetManifestRequest{Manifest: updated, Policies: testPolicies(), ActivationTime: activationTime.Unix()})
+	require.NoError(err)
+	require.NotNil(setResp.PendingUpdate)
+	assert.Nil(setResp.MeshCA)
+	assert.Equal(activationTime.Unix(), setResp.PendingUpdate.ActivationTime)
+
+	resp, err := coordinator.GetManifests(ctx, &userapi.GetManifestsRequest{})
+	require.NoError(err)
+	require.Len(resp.Manifests, 1, "the scheduled manifest must not be active yet")
+	require.NotNil(resp.PendingUpdate)
+	assert.Equal(updated, resp.PendingUpdate.Manifest)
+	assert.Equal(setResp.PendingUpdate.TransitionHash, resp.PendingUpdate.TransitionHash)
+
+	cancelReq := &userapi.CancelPendingUpdateRequest{TransitionHash: resp.PendingUpdate.TransitionHash}
+	_, err = coordinator.CancelPendingUpdate(rpcContext(t.Context(), otherKey), cancelReq)
+	require.Equal(codes.PermissionDenied, status.Code(err), "only owners of the current manifest can cancel")
+	_, err = coordinator.CancelPendingUpdate(ctx, &userapi.CancelPendingUpdateRequest{TransitionHash: make([]byte, history.HashSize)})
+	require.Equal(codes.NotFound, status.Code(err))
+	_, err = coordinator.CancelPendingUpdate(ctx, cancelReq)
+	require.NoError(err)
+
+	resp, err = coordinator.GetManifests(ctx, &userapi.GetManifestsRequest{})
+	require.NoError(err)
+	assert.Nil(resp.PendingUpdate)
+
+	// An activation time in the past applies the update immediately.
+	setResp, err = coordinator.SetManifest(ctx, &userapi.SetManifestRequest{Manifest: updated, Policies: testPolicies(), ActivationTime: time.Now().Add(-time.Minute).Unix()})
+	require.NoError(err)
+	assert.Nil(setResp.PendingUpdate)
+	assert.NotNil(setResp.MeshCA)
+}
+
+func TestCancelPendingUpdateThreshold(t *testing.T) {
+	require := require.New(t)
+
+	ownerKeys := []*ecdsa.PrivateKey{
+		testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[0]),
+		testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[1]),
+	}
+	thresholdManifest := newTestManifest()
+	thresholdManifest.WorkloadOwnerThreshold = 2
+	for _, key := range ownerKeys {
+		thresholdManifest.WorkloadOwnerPubKeys = append(thresholdManifest.WorkloadOwnerPubKeys, manifest.MarshalWorkloadOwnerPubKey(&key.PublicKey))
+	}
+	m, err := json.Marshal(thresholdManifest)
+	require.NoError(err)
+	initialTransition := history.Transition{ManifestHash: history.Digest(m)}
+	nextTransition := history.Transition{ManifestHash: history.Digest(m), PreviousTransitionHash: initialTransition.Digest()}
+	nextTransitionHash := nextTransition.Digest()
+	sign := func(key *ecdsa.PrivateKey, digest [history.HashSize]byte) []byte {
+		sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+		require.NoError(err)
+		return sig
+	}
+	transitionDigest := history.TransitionSigningDigest(nextTransitionHash)
+	cancellationDigest := history.CancellationSigningDigest(nextTransitionHash)
+
+	coordinator := newCoordinator()
+	_, err = coordinator.SetManifest(rpcContext(t.Context(), nil), &userapi.SetManifestRequest{Manifest: m, Policies: testPolicies()})
+	require.NoError(err)
+	_, err = coordinator.SetManifest(rpcContext(t.Context(), nil), &userapi.SetManifestRequest{
+		Manifest:       m,
+		Policies:       testPolicies(),
+		Signatures:     [][]byte{sign(ownerKeys[0], transitionDigest), sign(ownerKeys[1], transitionDigest)},
+		ActivationTime: time.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(err)
+
+	_, err = coordinator.CancelPendingUpdate(rpcContext(t.Context(), ownerKeys[0]), &userapi.CancelPendingUpdateRequest{
+		TransitionHash: nextTransitionHash[:],
+	})
+	require.Equal(codes.PermissionDenied, status.Code(err), "a single workload owner must not cancel")
+	_, err = coordinator.CancelPendingUpdate(rpcContext(t.Context(), nil), &userapi.CancelPendingUpdateRequest{
+		TransitionHash: nextTransitionHash[:],
+		Signatures:     [][]byte{sign(ownerKeys[0], transitionDigest), sign(ownerKeys[1], transitionDigest)},
+	})
+	require.Equal(codes.PermissionDenied, status.Code(err), "transition signatures must not authorize a cancellation")
+	_, err = coordinator.CancelPendingUpdate(rpcContext(t.Context(), ownerKeys[1]), &userapi.CancelPendingUpdateRequest{
+		TransitionHash: nextTransitionHash[:],
+		Signatures:     [][]byte{sign(ownerKeys[0], cancellationDigest)},
+	})
+	require.NoError(err)
+
+	resp, err := coordinator.GetManifests(t.Context(), &userapi.GetManifestsRequest{})
+	require.NoError(err)
+	require.Nil(resp.PendingUpdate)
+}
+
+func TestRollback(t *testing.T) {
+	require := require.New(t)
+
+	ownerKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[0])
+	otherKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[1])
+	unauthorizedKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP256Keys[0])
+	initialManifest, err := json.Marshal(manifestWithWorkloadOwnerKey(ownerKey))
+	require.NoError(err)
+	secondManifest := manifestWithWorkloadOwnerKey(ownerKey)
+	secondManifest.WorkloadOwnerPubKeys = append(secondManifest.WorkloadOwnerPubKeys, manifest.MarshalWorkloadOwnerPubKey(&otherKey.PublicKey))
+	updatedManifest, err := json.Marshal(secondManifest)
+	require.NoError(err)
+
+	coordinator := newCoordinator()
+	_, err = coordinator.Rollback(rpcContext(t.Context(), ownerKey), &userapi.RollbackRequest{TransitionHash: make([]byte, history.HashSize)})
+	require.Equal(codes.FailedPrecondition, status.Code(err), "rollback needs a manifest history")
+
+	_, err = coordinator.SetManifest(rpcContext(t.Context(), ownerKey), &userapi.SetManifestRequest{Manifest: initialManifest, Policies: testPolicies()})
+	require.NoError(err)
+	_, err = coordinator.SetManifest(rpcContext(t.Context(), ownerKey), &userapi.SetManifestRequest{Manifest: updatedManifest, Policies: testPolicies()})
+	require.NoError(err)
+	initialTransition := &history.Transition{ManifestHash: history.Digest(initialManifest)}
+	initialTransitionHash := initialTransition.Digest()
+	updatedTransition := &history.Transition{ManifestHash: history.Digest(updatedManifest), PreviousTransitionHash: initialTransitionHash}
+	rollbackTransition := &history.Transition{ManifestHash: history.Digest(initialManifest), PreviousTransitionHash: updatedTransition.Digest()}
+	rollbackTransitionHash := rollbackTransition.Digest()
+
+	req := &userapi.RollbackRequest{TransitionHash: initialTransitionHash[:]}
+	_, err = coordinator.Rollback(rpcContext(t.Context(), unauthorizedKey), req)
+	require.Equal(codes.PermissionDenied, status.Code(err))
+	_, err = coordinator.Rollback(rpcContext(t.Context(), otherKey), &userapi.RollbackRequest{TransitionHash: make([]byte, history.HashSize)})
+	require.Equal(codes.NotFound, status.Code(err))
+	_, err = coordinator.Rollback(rpcContext(t.Context(), otherKey), &userapi.RollbackRequest{TransitionHash: []byte{1}})
+	require.Equal(codes.InvalidArgument, status.Code(err))
+
+	// Any workload owner of the current manifest can authorize the rollback, also by signature.
+	signingHash := history.TransitionSigningDigest(rollbackTransitionHash)
+	sig, err := ecdsa.SignASN1(rand.Reader, otherKey, signingHash[:])
+	require.NoError(err)
+	req.Signatures = [][]byte{sig}
+	resp, err := coordinator.Rollback(t.Context(), req)
+	require.NoError(err)
+	require.Equal(rollbackTransitionHash[:], resp.TransitionHash)
+	require.NotEmpty(resp.MeshCA)
+
+	manifests, err := coordinator.GetManifests(t.Context(), &userapi.GetManifestsRequest{})
+	require.NoError(err)
+	require.Equal([][]byte{initialManifest, updatedManifest, initialManifest}, manifests.Manifests)
+	require.Equal(rollbackTransitionHash[:], manifests.LatestTransition.TransitionHash)
+}
+
+func TestRollbackInvalidTarget(t *testing.T) {
+	require := require.New(t)
+
+	ownerKey := testkeys.ECDSA(t)
+	unreachable := manifestWithWorkloadOwnerKey(ownerKey)
+	unreachable.WorkloadOwnerThreshold = 2
+	unreachableManifest, err := json.Marshal(unreachable)
+	require.NoError(err)
+	currentManifest, err := json.Marshal(manifestWithWorkloadOwnerKey(ownerKey))
+	require.NoError(err)
+
+	// The rollback target was stored before manifests were validated on update.
+	coordinator := newCoordinator()
+	se, err := seedengine.New(make([]byte, constants.SecretSeedSize), make([]byte, constants.SecretSeedSaltSize))
+	require.NoError(err)
+	state, err := coordinator.guard.UpdateState(t.Context(), nil, se, unreachableManifest, testPolicies(), nil)
+	require.NoError(err)
+	_, err = coordinator.guard.UpdateState(t.Context(), state, se, currentManifest, testPolicies(), nil)
+	require.NoError(err)
+
+	target := history.Transition{ManifestHash: history.Digest(unreachableManifest)}
+	targetHash := target.Digest()
+	_, err = coordinator.Rollback(rpcContext(t.Context(), ownerKey), &userapi.RollbackRequest{TransitionHash: targetHash[:]})
+	require.Equal(codes.InvalidArgument, status.Code(err), "rollback targets must pass the manifest validation")
+}
+
+func TestGetAuditLog(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	ownerKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[0])
+	otherKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[1])
+	m, err := json.Marshal(manifestWithWorkloadOwnerKey(ownerKey))
+	require.NoError(err)
+
+	logger := slog.Default()
+	store := aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()})
+	hist := history.NewWithStore(logger, store)
+	guard := stateguard.New(hist, prometheus.NewRegistry(), logger)
+	audit := auditlog.New(store, logger)
+	coordinator := New(logger, guard, &stubDiscovery{}, audit, nil)
+
+	ctx := rpcContext(t.Context(), ownerKey)
+	_, err = coordinator.SetManifest(ctx, &userapi.SetManifestRequest{Manifest: m, Policies: testPolicies()})
+	require.NoError(err)
+	_, err = coordinator.SetManifest(ctx, &userapi.SetManifestRequest{Manifest: m, Policies: testPolicies()})
+	require.NoError(err)
+
+	go func() {
+		_ = audit.Run(t.Context(), func() (*ecdsa.PrivateKey, error) {
+			state, err := guard.GetState(t.Context())
+			if err != nil {
+				return nil, err
+			}
+			return state.SeedEngine().TransactionSigningKey(), nil
+		})
+	}()
+
+	_, err = coordinator.GetAuditLog(rpcContext(t.Context(), otherKey), &userapi.GetAuditLogRequest{})
+	require.Equal(codes.PermissionDenied, status.Code(err))
+
+	var resp *userapi.GetAuditLogResponse
+	require.Eventually(func() bool {
+		resp, err = coordinator.GetAuditLog(ctx, &userapi.GetAuditLogRequest{})
+		return err == nil && len(resp.Events) == 2
+	}, 5*time.Second, 100*time.Millisecond)
+	require.False(resp.More)
+	require.NoError(auditlog.VerifyChain(nil, resp.Events))
+
+	ownerKeyHex := manifest.MarshalWorkloadOwnerPubKey(&ownerKey.PublicKey).String()
+	var event auditlog.Event
+	require.NoError(json.Unmarshal(resp.Events[1], &event))
+	assert.Equal(auditlog.EventManifestSet, event.Type)
+	assert.Equal(ownerKeyHex, event.Actor)
+	assert.Equal(ownerKeyHex, event.Details["approvers"])
+	manifestHash := history.Digest(m)
+	assert.Equal(manifest.NewHexString(manifestHash[:]).String(), event.Details["manifest"])
+
+	firstHash := history.Digest(resp.Events[0])
+	resp, err = coordinator.GetAuditLog(ctx, &userapi.GetAuditLogRequest{After: firstHash[:]})
+	require.NoError(err)
+	require.Len(resp.Events, 1)
+
+	resp, err = coordinator.GetAuditLog(ctx, &userapi.GetAuditLogRequest{Limit: 1})
+	require.NoError(err)
+	require.Len(resp.Events, 1)
+	require.True(resp.More)
+}
+
+func TestRevokeMeshCerts(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	ownerKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[0])
+	otherKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[1])
+	m, err := json.Marshal(manifestWithWorkloadOwnerKey(ownerKey))
+	require.NoError(err)
+
+	logger := slog.Default()
+	store := aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()})
+	hist := history.NewWithStore(logger, store)
+	guard := stateguard.New(hist, prometheus.NewRegistry(), logger)
+	registry := certregistry.New(store, logger)
+	coordinator := New(logger, guard, &stubDiscovery{}, nil, registry)
+
+	ctx := rpcContext(t.Context(), ownerKey)
+	_, err = coordinator.SetManifest(ctx, &userapi.SetManifestRequest{Manifest: m, Policies: testPolicies()})
+	require.NoError(err)
+	state, err := guard.GetState(ctx)
+	require.NoError(err)
+	err = registry.RecordIssued(state.SeedEngine().TransactionSigningKey(), state.CA(), certregistry.Certificate{
+		SerialNumber: big.NewInt(7),
+		PolicyHash:   "aa",
+		NotAfter:     time.Now().Add(time.Hour),
+	})
+	require.NoError(err)
+	err = registry.RecordIssued(state.SeedEngine().TransactionSigningKey(), state.CA(), certregistry.Certificate{
+		SerialNumber: big.NewInt(9),
+		NotAfter:     time.Now().Add(time.Hour),
+		SubCA:        true,
+	})
+	require.NoError(err)
+
+	revokeReq := &userapi.RevokeMeshCertsRequest{SerialNumbers: [][]byte{{7}}, ReasonCode: 1}
+	_, err = coordinator.RevokeMeshCerts(rpcContext(t.Context(), otherKey), revokeReq)
+	require.Equal(codes.PermissionDenied, status.Code(err))
+
+	_, err = coordinator.RevokeMeshCerts(ctx, &userapi.RevokeMeshCertsRequest{})
+	require.Equal(codes.InvalidArgument, status.Code(err))
+
+	_, err = coordinator.RevokeMeshCerts(ctx, &userapi.RevokeMeshCertsRequest{SerialNumbers: [][]byte{{7}}, ReasonCode: 11})
+	require.Equal(codes.InvalidArgument, status.Code(err))
+
+	_, err = coordinator.RevokeMeshCerts(ctx, &userapi.RevokeMeshCertsRequest{SerialNumbers: [][]byte{{8}}})
+	require.Equal(codes.NotFound, status.Code(err))
+
+	_, err = coordinator.RevokeMeshCerts(ctx, &userapi.RevokeMeshCertsRequest{SerialNumbers: [][]byte{{9}}})
+	require.Equal(codes.InvalidArgument, status.Code(err), "sub CAs must not be revoked")
+
+	resp, err := coordinator.RevokeMeshCerts(ctx, revokeReq)
+	require.NoError(err)
+	assert.Equal([][]byte{{7}}, resp.SerialNumbers)
+
+	block, _ := pem.Decode(resp.CRL)
+	require.NotNil(block)
+	crl, err := x509.ParseRevocationList(block.Bytes)
+	require.NoError(err)
+	require.Len(crl.RevokedCertificateEntries, 1)
+	assert.Equal(big.NewInt(7), crl.RevokedCertificateEntries[0].SerialNumber)
+	assert.Equal(1, crl.RevokedCertificateEntries[0].ReasonCode)
+}
+
+func TestListMeshCerts(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	ownerKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[0])
+	otherKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[1])
+	m, err := json.Marshal(manifestWithWorkloadOwnerKey(ownerKey))
+	require.NoError(err)
+
+	logger := slog.Default()
+	store := aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()})
+	guard := stateguard.New(history.NewWithStore(logger, store), prometheus.NewRegistry(), logger)
+	registry := certregistry.New(store, logger)
+	coordinator := New(logger, guard, &stubDiscovery{}, nil, registry)
+
+	ctx := rpcContext(t.Context(), ownerKey)
+	_, err = coordinator.ListMeshCerts(ctx, &userapi.ListMeshCertsRequest{})
+	require.Equal(codes.FailedPrecondition, status.Code(err))
+
+	_, err = coordinator.SetManifest(ctx, &userapi.SetManifestRequest{Manifest: m, Policies: testPolicies()})
+	require.NoError(err)
+	state, err := guard.GetState(ctx)
+	require.NoError(err)
+	issuedAt := time.Unix(1700000000, 0)
+	err = registry.RecordLeaf(state.SeedEngine().TransactionSigningKey(), state.CA(), certregistry.Leaf{
+		Certificate: certregistry.Certificate{
+			SerialNumber: big.NewInt(7),
+			PolicyHash:   "aa",
+			IssuedAt:     issuedAt,
+			NotAfter:     time.Now().Add(time.Hour),
+		},
+		SANs:               []string{"test", "192.0.2.1"},
+		PeerAddress:        "192.0.2.1:1234",
+		ManifestGeneration: 1,
+	})
+	require.NoError(err)
+
+	_, err = coordinator.ListMeshCerts(rpcContext(t.Context(), otherKey), &userapi.ListMeshCertsRequest{})
+	require.Equal(codes.PermissionDenied, status.Code(err))
+
+	resp, err := coordinator.ListMeshCerts(ctx, &userapi.ListMeshCertsRequest{})
+	require.NoError(err)
+	require.Len(resp.GetCertificates(), 1)
+	cert := resp.GetCertificates()[0]
+	assert.Equal([]byte{7}, cert.GetSerialNumber())
+	assert.Equal("aa", cert.GetPolicyHash())
+	assert.Equal([]string{"test", "192.0.2.1"}, cert.GetSANs())
+	assert.Equal("192.0.2.1:1234", cert.GetPeerAddress())
+	assert.Equal(issuedAt.Unix(), cert.GetIssuedAt())
+	assert.Equal(uint64(1), cert.GetManifestGeneration())
+	assert.True(cert.GetCurrentMeshCA())
+	assert.Zero(cert.GetRevokedAt())
+}
+
+func TestIssueSubCA(t *testing.T) {
+	ownerKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[0])
+	otherKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[1])
+	subCAKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[2])
+	csrDER, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{}, subCAKey)
+	require.NoError(t, err)
+	csr := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})
+
+	testCases := map[string]struct {
+		req      *userapi.IssueSubCARequest
+		peerKey  *ecdsa.PrivateKey
+		wantCode codes.Code
+	}{
+		"valid": {
+			req:     &userapi.IssueSubCARequest{CSR: csr, Names: []string{"*.example.com"}, LifetimeSeconds: 3600},
+			peerKey: ownerKey,
+		},
+		"default lifetime": {
+			req:     &userapi.IssueSubCARequest{CSR: csr, Names: []string{"example.com"}},
+			peerKey: ownerKey,
+		},
+		"not a workload owner": {
+			req:      &userapi.IssueSubCARequest{CSR: csr, Names: []string{"example.com"}},
+			peerKey:  otherKey,
+			wantCode: codes.PermissionDenied,
+		},
+		"no names": {
+			req:      &userapi.IssueSubCARequest{CSR: csr},
+			peerKey:  ownerKey,
+			wantCode: codes.InvalidArgument,
+		},
+		"invalid name": {
+			req:      &userapi.IssueSubCARequest{CSR: csr, Names: []string{"*"}},
+			peerKey:  ownerKey,
+			wantCode: codes.InvalidArgument,
+		},
+		"lifetime too long": {
+			req:      &userapi.IssueSubCARequest{CSR: csr, Names: []string{"example.com"}, LifetimeSeconds: int64((30 * 24 * time.Hour).Seconds())},
+			peerKey:  ownerKey,
+			wantCode: codes.InvalidArgument,
+		},
+		"invalid CSR": {
+			req:      &userapi.IssueSubCARequest{CSR: []byte("csr"), Names: []string{"example.com"}},
+			peerKey:  ownerKey,
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+
+			m, err := json.Marshal(manifestWithWorkloadOwnerKey(ownerKey))
+			require.NoError(err)
+			logger := slog.Default()
+			store := aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()})
+			guard := stateguard.New(history.NewWithStore(logger, store), prometheus.NewRegistry(), logger)
+			registry := certregistry.New(store, logger)
+			coordinator := New(logger, guard, &stubDiscovery{}, nil, registry)
+			_, err = coordinator.SetManifest(rpcContext(t.Context(), ownerKey), &userapi.SetManifestRequest{Manifest: m, Policies: testPolicies()})
+			require.NoError(err)
+
+			resp, err := coordinator.IssueSubCA(rpcContext(t.Context(), tc.peerKey), tc.req)
+			if tc.wantCode != codes.OK {
+				require.Equal(tc.wantCode, status.Code(err))
+				return
+			}
+			require.NoError(err)
+
+			block, _ := pem.Decode(resp.GetCertificate())
+			require.NotNil(block)
+			cert, err := x509.ParseCertificate(block.Bytes)
+			require.NoError(err)
+			assert.True(cert.IsCA)
+			assert.True(subCAKey.PublicKey.Equal(cert.PublicKey))
+			roots := x509.NewCertPool()
+			require.True(roots.AppendCertsFromPEM(resp.GetMeshCACert()))
+			_, err = cert.Verify(x509.VerifyOptions{Roots: roots})
+			require.NoError(err)
+
+			// The sub CA must be revocable like mesh certificates.
+			state, err := guard.GetState(t.Context())
+			require.NoError(err)
+			certs, _, err := registry.Certificates(state.SeedEngine().TransactionSigningKey(), state.CA())
+			require.NoError(err)
+			require.Len(certs, 1)
+			assert.True(certs[0].SubCA)
+			assert.Equal(cert.SerialNumber, certs[0].SerialNumber)
+		})
+	}
+}
+
+func TestIssueSubCAThreshold(t *testing.T) {
+	require := require.New(t)
+
+	ownerKeys := []*ecdsa.PrivateKey{
+		testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[0]),
+		testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[1]),
+	}
+	thresholdManifest := newTestManifest()
+	thresholdManifest.WorkloadOwnerThreshold = 2
+	for _, key := range ownerKeys {
+		thresholdManifest.WorkloadOwnerPubKeys = append(thresholdManifest.WorkloadOwnerPubKeys, manifest.MarshalWorkloadOwnerPubKey(&key.PublicKey))
+	}
+	m, err := json.Marshal(thresholdManifest)
+	require.NoError(err)
+	initialTransition := history.Transition{ManifestHash: history.Digest(m)}
+	sign := func(key *ecdsa.PrivateKey, digest [history.HashSize]byte) []byte {
+		sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+		require.NoError(err)
+		return sig
+	}
+
+	subCAKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[2])
+	csrDER, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{}, subCAKey)
+	require.NoError(err)
+	csr := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})
+	names := []string{"example.com"}
+	digest := history.SubCASigningDigest(initialTransition.Digest(), csr, names, 3600)
+
+	coordinator := newCoordinator()
+	_, err = coordinator.SetManifest(rpcContext(t.Context(), nil), &userapi.SetManifestRequest{Manifest: m, Policies: testPolicies()})
+	require.NoError(err)
+
+	_, err = coordinator.IssueSubCA(rpcContext(t.Context(), ownerKeys[0]), &userapi.IssueSubCARequest{
+		CSR: csr, Names: names, LifetimeSeconds: 3600,
+	})
+	require.Equal(codes.PermissionDenied, status.Code(err), "a single workload owner must not issue a sub CA")
+	_, err = coordinator.IssueSubCA(rpcContext(t.Context(), ownerKeys[0]), &userapi.IssueSubCARequest{
+		CSR: csr, Names: []string{"other.example.com"}, LifetimeSeconds: 3600,
+		Signatures: [][]byte{sign(ownerKeys[1], digest)},
+	})
+	require.Equal(codes.PermissionDenied, status.Code(err), "signatures must be bound to the names")
+	_, err = coordinator.IssueSubCA(rpcContext(t.Context(), nil), &userapi.IssueSubCARequest{
+		CSR: csr, Names: names, LifetimeSeconds: 3600,
+		Signatures: [][]byte{sign(ownerKeys[0], history.TransitionSigningDigest(initialTransition.Digest())), sign(ownerKeys[1], digest)},
+	})
+	require.Equal(codes.PermissionDenied, status.Code(err), "transition signatures must not authorize a sub CA")
+	_, err = coordinator.IssueSubCA(rpcContext(t.Context(), ownerKeys[0]), &userapi.IssueSubCARequest{
+		CSR: csr, Names: names, LifetimeSeconds: 3600,
+		Signatures: [][]byte{sign(ownerKeys[1], digest)},
+	})
+	require.NoError(err)
 }
 
 func TestRecovery(t *testing.T) {
@@ -473,7 +1253,7 @@ func TestRecovery(t *testing.T) {
 				peers: tc.peers,
 				err:   tc.peersErr,
 			}
-			a := New(logger, auth, discovery)
+			a := New(logger, auth, discovery, nil, nil)
 
 			manifestBytes, policies := newManifestWithSeedshareOwner(t)
 
@@ -525,7 +1305,7 @@ func TestRecoveryFlow(t *testing.T) {
 	store := aferostore.New(&afero.Afero{Fs: fs})
 	hist := history.NewWithStore(slog.Default(), store)
 	auth := stateguard.New(hist, prometheus.NewRegistry(), logger)
-	a := New(logger, auth, &stubDiscovery{})
+	a := New(logger, auth, &stubDiscovery{}, nil, nil)
 
 	// 2. A manifest is set and the returned seed is recorded.
 	manifestBytes, policies := newManifestWithSeedshareOwner(t)
@@ -612,7 +1392,7 @@ func TestRecoveryManifestSecurityMustMatchCoordinator(t *testing.T) {
 			fs := afero.NewMemMapFs()
 			store := aferostore.New(&afero.Afero{Fs: fs})
 			hist := history.NewWithStore(slog.Default(), store)
-			coordinator := New(logger, stateguard.New(hist, prometheus.NewRegistry(), logger), &stubDiscovery{})
+			coordinator := New(logger, stateguard.New(hist, prometheus.NewRegistry(), logger), &stubDiscovery{}, nil, nil)
 			if tc.insecure {
 				coordinator.MakeInsecure()
 			}
@@ -634,14 +1414,14 @@ func TestRecoveryManifestSecurityMustMatchCoordinator(t *testing.T) {
 			}
 			ctx := rpcContext(t.Context(), seedShareOwnerKey)
 
-			mismatched := New(logger, stateguard.New(hist, prometheus.NewRegistry(), logger), &stubDiscovery{})
+			mismatched := New(logger, stateguard.New(hist, prometheus.NewRegistry(), logger), &stubDiscovery{}, nil, nil)
 			if !tc.insecure {
 				mismatched.MakeInsecure()
 			}
 			_, err = mismatched.Recover(ctx, recoverReq)
 			require.ErrorContains(err, tc.mismatchError.Error())
 
-			matching := New(logger, stateguard.New(hist, prometheus.NewRegistry(), logger), &stubDiscovery{})
+			matching := New(logger, stateguard.New(hist, prometheus.NewRegistry(), logger), &stubDiscovery{}, nil, nil)
 			if tc.insecure {
 				matching.MakeInsecure()
 			}
@@ -654,37 +1434,22 @@ func TestRecoveryManifestSecurityMustMatchCoordinator(t *testing.T) {
 // TestUserAPIConcurrent tests potential synchronization problems between the different
 // gRPCs of the server.
 func TestUserAPIConcurrent(t *testing.T) {
-	newBaseManifest := func() *manifest.Manifest {
-		return &manifest.Manifest{}
-	}
-	newManifestBytes := func(f func(*manifest.Manifest)) []byte {
-		m := newBaseManifest()
-		if f != nil {
-			f(m)
-		}
-		b, err := json.Marshal(m)
-		require.NoError(t, err)
-		return b
-	}
+	m := newTestManifest()
+	m.Policies[manifest.HexString("ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb")] = manifest.PolicyEntry{SANs: []string{"a1", "a2"}, WorkloadSecretID: "a3"}
+	m.Policies[manifest.HexString("3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d")] = manifest.PolicyEntry{SANs: []string{"b1", "b2"}, WorkloadSecretID: "b3"}
+	manifestBytes, err := json.Marshal(m)
+	require.NoError(t, err)
 
 	logger := slog.Default()
 	fs := afero.NewBasePathFs(afero.NewOsFs(), t.TempDir())
 	store := aferostore.New(&afero.Afero{Fs: fs})
 	hist := history.NewWithStore(slog.Default(), store)
 	auth := stateguard.New(hist, prometheus.NewRegistry(), logger)
-	coordinator := New(logger, auth, &stubDiscovery{})
+	coordinator := New(logger, auth, &stubDiscovery{}, nil, nil)
 
 	setReq := &userapi.SetManifestRequest{
-		Manifest: newManifestBytes(func(m *manifest.Manifest) {
-			m.Policies = map[manifest.HexString]manifest.PolicyEntry{
-				manifest.HexString("ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"): {SANs: []string{"a1", "a2"}, WorkloadSecretID: "a3"},
-				manifest.HexString("3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d"): {SANs: []string{"b1", "b2"}, WorkloadSecretID: "b3"},
-			}
-		}),
-		Policies: [][]byte{
-			[]byte("a"),
-			[]byte("b"),
-		},
+		Manifest: manifestBytes,
+		Policies: testPolicies([]byte("a"), []byte("b")),
 	}
 
 	ctx := t.Context()
@@ -800,13 +1565,13 @@ func TestStoreRaces(t *testing.T) {
 	seedshareOwnerKey := testkeys.RSA(t)
 	workloadOwnerKey := testkeys.ECDSA(t)
 
-	manifestBytes, err := json.Marshal(&manifest.Manifest{
-		WorkloadOwnerPubKeys:  []manifest.HexString{manifest.MarshalWorkloadOwnerPubKey(&workloadOwnerKey.PublicKey)},
-		SeedshareOwnerPubKeys: []manifest.HexString{manifest.MarshalSeedShareOwnerKey(&seedshareOwnerKey.PublicKey)},
-	})
+	mnfst := manifestWithWorkloadOwnerKey(workloadOwnerKey)
+	mnfst.SeedshareOwnerPubKeys = []manifest.HexString{manifest.MarshalSeedShareOwnerKey(&seedshareOwnerKey.PublicKey)}
+	manifestBytes, err := json.Marshal(mnfst)
 	require.NoError(err)
 	req := &userapi.SetManifestRequest{
 		Manifest: manifestBytes,
+		Policies: testPolicies(),
 	}
 
 	var seed, salt []byte
@@ -947,13 +1712,13 @@ func TestNotificationRaces(t *testing.T) {
 	seedshareOwnerKey := testkeys.RSA(t)
 	workloadOwnerKey := testkeys.ECDSA(t)
 
-	manifestBytes, err := json.Marshal(&manifest.Manifest{
-		WorkloadOwnerPubKeys:  []manifest.HexString{manifest.MarshalWorkloadOwnerPubKey(&workloadOwnerKey.PublicKey)},
-		SeedshareOwnerPubKeys: []manifest.HexString{manifest.MarshalSeedShareOwnerKey(&seedshareOwnerKey.PublicKey)},
-	})
+	mnfst := manifestWithWorkloadOwnerKey(workloadOwnerKey)
+	mnfst.SeedshareOwnerPubKeys = []manifest.HexString{manifest.MarshalSeedShareOwnerKey(&seedshareOwnerKey.PublicKey)}
+	manifestBytes, err := json.Marshal(mnfst)
 	require.NoError(err)
 	req := &userapi.SetManifestRequest{
 		Manifest: manifestBytes,
+		Policies: testPolicies(),
 	}
 	var transitions [][]byte
 	for i := range 2 {
@@ -986,12 +1751,12 @@ func newCoordinatorWithRegistry(reg *prometheus.Registry) *Server {
 	store := aferostore.New(&afero.Afero{Fs: fs})
 	hist := history.NewWithStore(slog.Default(), store)
 	auth := stateguard.New(hist, reg, logger)
-	return New(logger, auth, &stubDiscovery{})
+	return New(logger, auth, &stubDiscovery{}, nil, nil)
 }
 
 func newInsecureManifest(t *testing.T) *manifest.Manifest {
 	t.Helper()
-	mnfst := &manifest.Manifest{}
+	mnfst := newTestManifest()
 	mnfst.ReferenceValues.SNP = []manifest.SNPReferenceValues{
 		{Platform: "Metal-QEMU-Insecure"},
 	}
@@ -1000,28 +1765,17 @@ func newInsecureManifest(t *testing.T) *manifest.Manifest {
 
 func newInsecureManifestWithSeedshareOwner(t *testing.T) ([]byte, [][]byte) {
 	t.Helper()
-	policy := []byte("=== SOME REGO HERE ===")
-	policyHash := sha256.Sum256(policy)
-	policyHashHex := manifest.NewHexString(policyHash[:])
-
 	mnfst := newInsecureManifest(t)
-	mnfst.Policies = map[manifest.HexString]manifest.PolicyEntry{
-		policyHashHex: {
-			SANs:             []string{"test"},
-			WorkloadSecretID: "test2",
-			Role:             manifest.RoleCoordinator,
-		},
-	}
 	seedShareOwnerKey := testkeys.RSA(t)
 	mnfst.SeedshareOwnerPubKeys = []manifest.HexString{manifest.MarshalSeedShareOwnerKey(&seedShareOwnerKey.PublicKey)}
 	mnfstBytes, err := json.Marshal(mnfst)
 	require.NoError(t, err)
-	return mnfstBytes, [][]byte{policy}
+	return mnfstBytes, testPolicies()
 }
 
 func newMixedManifest(t *testing.T) *manifest.Manifest {
 	t.Helper()
-	mnfst := &manifest.Manifest{}
+	mnfst := newTestManifest()
 	mnfst.ReferenceValues.SNP = []manifest.SNPReferenceValues{
 		{Platform: "Metal-QEMU-Insecure"},
 		{Platform: "Metal-QEMU-SNP"},
@@ -1033,7 +1787,7 @@ func newCoordinatorWithWatcher(t *testing.T, hist *history.History) *Server {
 	t.Helper()
 	logger := slog.Default()
 	auth := stateguard.New(hist, prometheus.NewRegistry(), logger)
-	coordinator := New(logger, auth, &stubDiscovery{})
+	coordinator := New(logger, auth, &stubDiscovery{}, nil, nil)
 
 	ctx, cancel := context.WithCancel(t.Context())
 	doneCh := make(chan struct{})
@@ -1051,16 +1805,32 @@ func newCoordinatorWithWatcher(t *testing.T, hist *history.History) *Server {
 
 func newManifestWithSeedshareOwner(t *testing.T) ([]byte, [][]byte) {
 	t.Helper()
-	policy := []byte("=== SOME REGO HERE ===")
-	policyHash := sha256.Sum256(policy)
-	policyHashHex := manifest.NewHexString(policyHash[:])
-
-	mnfst := &manifest.Manifest{}
-	mnfst.Policies = map[manifest.HexString]manifest.PolicyEntry{
-		policyHashHex: {
-			SANs:             []string{"test"},
-			WorkloadSecretID: "test2",
-			Role:             manifest.RoleCoordinator,
+	mnfst := newTestManifest()
+	workloadOwnerKey := testkeys.ECDSA(t)
+	workloadOwnerKeyBytes := manifest.MarshalWorkloadOwnerPubKey(&workloadOwnerKey.PublicKey)
+	mnfst.WorkloadOwnerPubKeys = []manifest.HexString{workloadOwnerKeyBytes}
+	seedShareOwnerKey := testkeys.RSA(t)
+	seedShareOwnerKeyBytes := manifest.MarshalSeedShareOwnerKey(&seedShareOwnerKey.PublicKey)
+	mnfst.SeedshareOwnerPubKeys = []manifest.HexString{seedShareOwnerKeyBytes}
+	mnfstBytes, err := json.Marshal(mnfst)
+	require.NoError(t, err)
+	return mnfstBytes, testPolicies()
+}
+
+// testCoordinatorPolicy is the Coordinator policy of the manifests returned by newTestManifest.
+var testCoordinatorPolicy = []byte("=== SOME REGO HERE ===")
+
+// newTestManifest returns a valid manifest for a secure Coordinator. Requests that set it need to
+// contain the policies returned by testPolicies.
+func newTestManifest() *manifest.Manifest {
+	policyHash := sha256.Sum256(testCoordinatorPolicy)
+	mnfst := &manifest.Manifest{
+		Policies: map[manifest.HexString]manifest.PolicyEntry{
+			manifest.NewHexString(policyHash[:]): {
+				SANs:             []string{"test"},
+				WorkloadSecretID: "test2",
+				Role:             manifest.RoleCoordinator,
+			},
 		},
 	}
 	svn0 := manifest.SVN(0)
@@ -1079,15 +1849,13 @@ func newManifestWithSeedshareOwner(t *testing.T) ([]byte, [][]byte) {
 			SMT: true,
 		},
 	}}
-	workloadOwnerKey := testkeys.ECDSA(t)
-	workloadOwnerKeyBytes := manifest.MarshalWorkloadOwnerPubKey(&workloadOwnerKey.PublicKey)
-	mnfst.WorkloadOwnerPubKeys = []manifest.HexString{workloadOwnerKeyBytes}
-	seedShareOwnerKey := testkeys.RSA(t)
-	seedShareOwnerKeyBytes := manifest.MarshalSeedShareOwnerKey(&seedShareOwnerKey.PublicKey)
-	mnfst.SeedshareOwnerPubKeys = []manifest.HexString{seedShareOwnerKeyBytes}
-	mnfstBytes, err := json.Marshal(mnfst)
-	require.NoError(t, err)
-	return mnfstBytes, [][]byte{policy}
+	return mnfst
+}
+
+// testPolicies returns the policies of a manifest returned by newTestManifest, followed by the
+// given additional policies.
+func testPolicies(additional ...[]byte) [][]byte {
+	return append([][]byte{testCoordinatorPolicy}, additional...)
 }
 
 func rpcContext(ctx context.Context, cryptoKey crypto.PrivateKey) context.Context {
@@ -1114,7 +1882,7 @@ func rpcContext(ctx context.Context, cryptoKey crypto.PrivateKey) context.Contex
 }
 
 func manifestWithWorkloadOwnerKey(key *ecdsa.PrivateKey) *manifest.Manifest {
-	m := &manifest.Manifest{}
+	m := newTestManifest()
 	if key == nil {
 		return m
 	}
@@ -5,21 +5,27 @@ package main
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/edgelesssys/contrast/apitypes"
+	"github.com/edgelesssys/contrast/coordinator/internal/certregistry"
+	"github.com/edgelesssys/contrast/coordinator/internal/federation"
 	"github.com/edgelesssys/contrast/coordinator/internal/httpapi"
 	meshapiserver "github.com/edgelesssys/contrast/coordinator/internal/meshapi"
+	"github.com/edgelesssys/contrast/coordinator/internal/notifier"
 	"github.com/edgelesssys/contrast/coordinator/internal/peerdiscovery"
 	"github.com/edgelesssys/contrast/coordinator/internal/peerrecovery"
 	"github.com/edgelesssys/contrast/coordinator/internal/probes"
@@ -29,11 +35,14 @@ import (
 	"github.com/edgelesssys/contrast/internal/atls"
 	"github.com/edgelesssys/contrast/internal/atls/issuer"
 	"github.com/edgelesssys/contrast/internal/attestation/certcache"
+	"github.com/edgelesssys/contrast/internal/auditlog"
 	"github.com/edgelesssys/contrast/internal/constants"
 	"github.com/edgelesssys/contrast/internal/defaultdeny"
 	"github.com/edgelesssys/contrast/internal/grpc/atlscredentials"
 	"github.com/edgelesssys/contrast/internal/history"
 	"github.com/edgelesssys/contrast/internal/history/configmapstore"
+	"github.com/edgelesssys/contrast/internal/history/crdstore"
+	"github.com/edgelesssys/contrast/internal/kmip"
 	loggerpkg "github.com/edgelesssys/contrast/internal/logger"
 	"github.com/edgelesssys/contrast/internal/memstore"
 	"github.com/edgelesssys/contrast/internal/meshapi"
@@ -45,6 +54,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/keepalive"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/utils/clock"
@@ -53,9 +63,19 @@ import (
 const (
 	metricsEnvVar       = "CONTRAST_METRICS"
 	allowInsecureEnvVar = "CONTRAST_ALLOW_INSECURE"
+	// historyStoreEnvVar selects the history store backend, either "configmap" (default) or "crd".
+	historyStoreEnvVar = "CONTRAST_HISTORY_STORE"
+	// historyRetentionEnvVar enables pruning of the history, keeping the given number of transitions.
+	historyRetentionEnvVar = "CONTRAST_HISTORY_RETENTION"
+	// auditRetentionEnvVar sets the number of most recent events kept in the audit log.
+	auditRetentionEnvVar = "CONTRAST_AUDIT_RETENTION"
+	// webhookURLsEnvVar holds a comma-separated list of endpoints notified about state transitions.
+	webhookURLsEnvVar   = "CONTRAST_WEBHOOK_URLS"
 	probeAndMetricsPort = 9102
 	// transitEngineAPIPort specifies the default port to expose the transit engine API.
 	transitEngineAPIPort = "8200"
+	// kmipEnvVar enables the KMIP server on the KMIP port if set.
+	kmipEnvVar = "CONTRAST_KMIP"
 )
 
 func main() {
@@ -114,11 +134,42 @@ func run() (retErr error) {
 	promRegistry := prometheus.NewRegistry()
 	serverMetrics := newServerMetrics(promRegistry)
 
-	store := configmapstore.New(clientset, string(namespace), logger.WithGroup("history-store"))
+	store, err := newHistoryStore(config, clientset, string(namespace), logger)
+	if err != nil {
+		return fmt.Errorf("creating history store: %w", err)
+	}
 
 	hist := history.NewWithStore(logger.WithGroup("history"), store)
+	auditLog := auditlog.New(store, logger.WithGroup("auditlog"))
+	certRegistry := certregistry.New(store, logger.WithGroup("certregistry"))
 
 	meshAuth := stateguard.New(hist, promRegistry, logger)
+	meshAuth.SetAuditLog(auditLog)
+	if retention := os.Getenv(historyRetentionEnvVar); retention != "" {
+		transitions, err := strconv.Atoi(retention)
+		if err != nil || transitions < 1 {
+			return fmt.Errorf("invalid value for %s: must be a positive number of transitions, got %q", historyRetentionEnvVar, retention)
+		}
+		logger.Info("History pruning enabled", "retainedTransitions", transitions)
+		meshAuth.SetHistoryRetention(transitions)
+	}
+	if retention := os.Getenv(auditRetentionEnvVar); retention != "" {
+		events, err := strconv.Atoi(retention)
+		if err != nil || events < 1 {
+			return fmt.Errorf("invalid value for %s: must be a positive number of events, got %q", auditRetentionEnvVar, retention)
+		}
+		logger.Info("Audit log retention configured", "retainedEvents", events)
+		auditLog.SetRetention(events)
+	}
+	var stateNotifier *notifier.Notifier
+	if webhookURLs := os.Getenv(webhookURLsEnvVar); webhookURLs != "" {
+		stateNotifier, err = notifier.New(strings.Split(webhookURLs, ","), logger.WithGroup("notifier"))
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %w", webhookURLsEnvVar, err)
+		}
+		logger.Info("Webhook notifications enabled", "endpoints", webhookURLs)
+		meshAuth.SetNotifier(stateNotifier)
+	}
 
 	issuer, err := issuer.New(logger, collateralProxy)
 	if err != nil {
@@ -127,7 +178,7 @@ func run() (retErr error) {
 
 	userAPICredentials := atlscredentials.New(issuer, nil, atls.NoMetrics, loggerpkg.NewNamed(logger, "atlscredentials"))
 	userAPIServer := newGRPCServer(userAPICredentials, serverMetrics)
-	userapiService := userapiserver.New(logger, meshAuth, discovery)
+	userapiService := userapiserver.New(logger, meshAuth, discovery, auditLog, certRegistry)
 	if os.Getenv(allowInsecureEnvVar) != "" {
 		logger.Warn("Coordinator is configured to allow insecure manifests")
 		userapiService.MakeInsecure()
@@ -140,9 +191,11 @@ func run() (retErr error) {
 	defer ticker.Stop()
 	kdsGetter := certcache.NewCachedHTTPSGetter(memstore.New[string, []byte](), ticker, loggerpkg.NewNamed(logger, "kds-getter-validator"), collateralProxy)
 
+	federator := federation.New(meshAuth, kdsGetter, logger.WithGroup("federation"))
+
 	meshAPIcredentials := meshAuth.Credentials(promRegistry, issuer, kdsGetter)
 	meshAPIServer := newGRPCServer(meshAPIcredentials, serverMetrics)
-	meshapi.RegisterMeshAPIServer(meshAPIServer, meshapiserver.New(logger))
+	meshapi.RegisterMeshAPIServer(meshAPIServer, meshapiserver.New(logger, promRegistry, auditLog, certRegistry, federator))
 	serverMetrics.InitializeMetrics(meshAPIServer)
 
 	metricsServer := &http.Server{}
@@ -157,11 +210,20 @@ func run() (retErr error) {
 	}
 	readinessHandler := probes.ReadinessHandler{Guard: meshAuth}
 
-	transitAPIServer, err := transitengine.NewTransitEngineAPI(meshAuth, logger)
+	transitKeys := transitengine.NewKeyring(store, logger.WithGroup("transitkeys"))
+	transitAPIServer, err := transitengine.NewTransitEngineAPI(meshAuth, transitKeys, certRegistry, logger, auditLog)
 	if err != nil {
 		return fmt.Errorf("creating transit engine API server: %w", err)
 	}
 
+	var kmipServer *transitengine.KMIPServer
+	if _, enableKMIP := os.LookupEnv(kmipEnvVar); enableKMIP {
+		kmipServer, err = transitengine.NewKMIPServer(meshAuth, store, certRegistry, logger, auditLog)
+		if err != nil {
+			return fmt.Errorf("creating KMIP server: %w", err)
+		}
+	}
+
 	eg, ctx := errgroup.WithContext(ctxSignal)
 
 	eg.Go(func() error {
@@ -173,6 +235,14 @@ func run() (retErr error) {
 		mux := http.NewServeMux()
 		mux.Handle("/attest", &h)
 		mux.Handle("/capabilities", &httpapi.CapabilitiesHandler{})
+		mux.Handle("/crl", &httpapi.CRLHandler{
+			StateGuard: meshAuth,
+			Registry:   certRegistry,
+		})
+		mux.Handle("/v2/", &httpapi.V2Handler{
+			Issuer:     issuer,
+			StateGuard: meshAuth,
+		})
 
 		httpAPIServer.Addr = ":" + apitypes.Port
 		httpAPIServer.Handler = mux
@@ -243,6 +313,55 @@ func run() (retErr error) {
 		return nil
 	})
 
+	eg.Go(func() error {
+		logger.Info("Watching mesh certificate registry")
+		if err := certRegistry.Watch(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			logger.Error("Watching mesh certificate registry", "err", err)
+		}
+		return nil
+	})
+
+	eg.Go(func() error {
+		logger.Info("Watching transit engine keys")
+		if err := transitKeys.Watch(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			logger.Error("Watching transit engine keys", "err", err)
+		}
+		return nil
+	})
+
+	eg.Go(func() error {
+		logger.Info("Writing audit log")
+		err := auditLog.Run(ctx, func() (*ecdsa.PrivateKey, error) {
+			state, err := meshAuth.GetState(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return state.SeedEngine().TransactionSigningKey(), nil
+		})
+		if err != nil && !errors.Is(err, context.Canceled) {
+			logger.Error("Writing audit log", "err", err)
+		}
+		return nil
+	})
+
+	if stateNotifier != nil {
+		eg.Go(func() error {
+			logger.Info("Delivering webhook notifications")
+			if err := stateNotifier.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				logger.Error("Delivering webhook notifications", "err", err)
+			}
+			return nil
+		})
+	}
+
+	eg.Go(func() error {
+		logger.Info("Watching for scheduled manifest updates")
+		if err := meshAuth.ActivatePendingUpdates(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			logger.Error("Activating scheduled manifest updates", "err", err)
+		}
+		return nil
+	})
+
 	eg.Go(func() error {
 		logger.Info("Coordinator peer recovery started")
 		recoverer := peerrecovery.New(meshAuth, discovery, issuer, kdsGetter, logger)
@@ -261,6 +380,14 @@ func run() (retErr error) {
 		return nil
 	})
 
+	eg.Go(func() error {
+		logger.Info("Refreshing trust bundles of federated deployments")
+		if err := federator.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			logger.Error("Refreshing trust bundles of federated deployments", "err", err)
+		}
+		return nil
+	})
+
 	eg.Go(func() error {
 		logger.Info("Coordinator transit engine API listening")
 		lis, err := (&net.ListenConfig{}).Listen(ctx, "tcp", net.JoinHostPort("0.0.0.0", transitEngineAPIPort))
@@ -274,6 +401,28 @@ func run() (retErr error) {
 		return nil
 	})
 
+	if kmipServer != nil {
+		eg.Go(func() error {
+			logger.Info("Coordinator KMIP server listening")
+			lis, err := (&net.ListenConfig{}).Listen(ctx, "tcp", net.JoinHostPort("0.0.0.0", kmip.Port))
+			if err != nil {
+				return fmt.Errorf("failed to listen: %w", err)
+			}
+			if err := kmipServer.Serve(lis); err != nil {
+				logger.Error("Serving KMIP", "err", err)
+				return fmt.Errorf("serving KMIP: %w", err)
+			}
+			return nil
+		})
+		eg.Go(func() error {
+			logger.Info("Watching KMIP objects")
+			if err := kmipServer.Watch(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				logger.Error("Watching KMIP objects", "err", err)
+			}
+			return nil
+		})
+	}
+
 	eg.Go(func() error {
 		<-ctx.Done()
 		if ctxSignal.Err() != nil {
@@ -288,8 +437,13 @@ func run() (retErr error) {
 		gracefulStopGRPC(ctx, wg, userAPIServer)
 		gracefulStopGRPC(ctx, wg, meshAPIServer)
 		wg.Wait()
+		var kmipErr error
+		if kmipServer != nil {
+			kmipErr = kmipServer.Close()
+		}
 		return errors.Join(
 			transitAPIServer.Shutdown(ctx),
+			kmipErr,
 			httpAPIServer.Shutdown(ctx),
 			metricsServer.Shutdown(ctx),
 		)
@@ -339,3 +493,60 @@ func gracefulStopGRPC(ctx context.Context, wg *sync.WaitGroup, server *grpc.Serv
 		}
 	})
 }
+
+// newHistoryStore creates the history store selected by the historyStoreEnvVar.
+//
+// When the ContrastHistory store is selected, an existing ConfigMap history, audit log, mesh
+// certificate registry, transit engine keys and KMIP objects are migrated to it.
+func newHistoryStore(config *rest.Config, clientset kubernetes.Interface, namespace string, logger *slog.Logger) (history.Store, error) {
+	configMapStore := configmapstore.New(clientset, namespace, logger.WithGroup("history-store"))
+
+	switch backend := os.Getenv(historyStoreEnvVar); backend {
+	case "", "configmap":
+		return configMapStore, nil
+	case "crd":
+		dynamicClient, err := dynamic.NewForConfig(config)
+		if err != nil {
+			return nil, fmt.Errorf("creating Kubernetes dynamic client: %w", err)
+		}
+		store := crdstore.New(dynamicClient, namespace, logger.WithGroup("history-store"))
+		migrated, err := history.Migrate(configMapStore, store)
+		if err != nil {
+			return nil, fmt.Errorf("migrating history from ConfigMaps: %w", err)
+		}
+		if migrated {
+			logger.Info("Migrated history from ConfigMaps to ContrastHistory resources")
+		}
+		migrated, err = auditlog.Migrate(configMapStore, store)
+		if err != nil {
+			return nil, fmt.Errorf("migrating audit log from ConfigMaps: %w", err)
+		}
+		if migrated {
+			logger.Info("Migrated audit log from ConfigMaps to ContrastHistory resources")
+		}
+		migrated, err = certregistry.Migrate(configMapStore, store)
+		if err != nil {
+			return nil, fmt.Errorf("migrating mesh certificate registry from ConfigMaps: %w", err)
+		}
+		if migrated {
+			logger.Info("Migrated mesh certificate registry from ConfigMaps to ContrastHistory resources")
+		}
+		migrated, err = transitengine.MigrateKeyring(configMapStore, store)
+		if err != nil {
+			return nil, fmt.Errorf("migrating transit engine keys from ConfigMaps: %w", err)
+		}
+		if migrated {
+			logger.Info("Migrated transit engine keys from ConfigMaps to ContrastHistory resources")
+		}
+		migrated, err = transitengine.MigrateKMIPObjects(configMapStore, store)
+		if err != nil {
+			return nil, fmt.Errorf("migrating KMIP objects from ConfigMaps: %w", err)
+		}
+		if migrated {
+			logger.Info("Migrated KMIP objects from ConfigMaps to ContrastHistory resources")
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown history store %q", backend)
+	}
+}
@@ -4,15 +4,15 @@
 package main
 
 import (
+	"bytes"
 	"context"
-	"crypto/ecdsa"
-	"crypto/elliptic"
-	"crypto/rand"
+	"crypto"
 	"crypto/x509"
 	"encoding/hex"
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"maps"
 	"net"
 	"os"
 	"os/signal"
@@ -21,18 +21,26 @@ import (
 
 	"github.com/edgelesssys/contrast/internal/atls"
 	"github.com/edgelesssys/contrast/internal/atls/issuer"
+	"github.com/edgelesssys/contrast/internal/cryptohelpers"
 	"github.com/edgelesssys/contrast/internal/defaultdeny"
 	"github.com/edgelesssys/contrast/internal/grpc/dialer"
 	"github.com/edgelesssys/contrast/internal/logger"
 	"github.com/edgelesssys/contrast/internal/meshapi"
+	"github.com/edgelesssys/contrast/internal/spiffe/workloadapi"
 
 	"github.com/edgelesssys/contrast/internal/constants"
 	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
 )
 
 const (
 	// workloadSecretPath is fixed path to the Contrast workload secret.
 	workloadSecretPath = "/contrast/secrets/workload-secret-seed"
+	// defaultKeyAlgorithm is the algorithm of the workload key if the manifest doesn't specify one.
+	defaultKeyAlgorithm = cryptohelpers.KeyAlgorithmECDSAP256
+	// trustBundleRefreshInterval is the interval in which a running initializer refreshes the
+	// trust bundle and the CRLs.
+	trustBundleRefreshInterval = 5 * time.Minute
 )
 
 func main() {
@@ -97,21 +105,29 @@ func run(cmd *cobra.Command, _ []string) (retErr error) {
 		return errors.New("COORDINATOR_HOST not set")
 	}
 
+	keyAlgorithm := defaultKeyAlgorithm
+	if alg := os.Getenv(constants.KeyAlgorithmEnvVar); alg != "" {
+		keyAlgorithm = cryptohelpers.KeyAlgorithm(alg)
+	}
+	if err := keyAlgorithm.Validate(); err != nil {
+		return fmt.Errorf("invalid %s: %w", constants.KeyAlgorithmEnvVar, err)
+	}
+
 	ctx := cmd.Context()
 	ctx, cancel := signal.NotifyContext(ctx, syscall.SIGTERM, syscall.SIGINT)
 	defer cancel()
 
-	privKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
-	if err != nil {
-		return fmt.Errorf("generating key: %w", err)
-	}
-
 	issuer, err := issuer.New(log, collateralProxy)
 	if err != nil {
 		return fmt.Errorf("creating issuer: %w", err)
 	}
 
-	requestCert := func() (*meshapi.NewMeshCertResponse, error) {
+	var workloadAPI *workloadapi.Server
+	if os.Getenv(constants.SPIFFEWorkloadAPIEnvVar) != "" {
+		workloadAPI = workloadapi.NewServer()
+	}
+
+	dialCoordinator := func(privKey crypto.Signer) (*grpc.ClientConn, error) {
 		// Supply a nil validator, as the coordinator does not need to be
 		// validated by the initializer.
 		dial := dialer.NewWithKey(issuer, nil, atls.NoMetrics, nil, privKey, log)
@@ -119,6 +135,14 @@ func run(cmd *cobra.Command, _ []string) (retErr error) {
 		if err != nil {
 			return nil, fmt.Errorf("dialing: %w", err)
 		}
+		return conn, nil
+	}
+
+	requestCert := func(privKey crypto.Signer) (*meshapi.NewMeshCertResponse, error) {
+		conn, err := dialCoordinator(privKey)
+		if err != nil {
+			return nil, err
+		}
 		defer conn.Close()
 
 		client := meshapi.NewMeshAPIClient(conn)
@@ -130,57 +154,122 @@ func run(cmd *cobra.Command, _ []string) (retErr error) {
 		return resp, nil
 	}
 
-	ticker := time.NewTicker(5 * time.Second)
-	var resp *meshapi.NewMeshCertResponse
-	for {
-		resp, err = requestCert()
-		if err == nil {
-			log.Info("Successfully requested cert from Coordinator")
-			break
+	// The key and the files of the current TLS config, which are needed to update the trust
+	// bundle in place.
+	var currentKey crypto.Signer
+	var currentFiles map[string][]byte
+
+	// writeFiles atomically writes the TLS config and updates the SPIFFE Workload API.
+	writeFiles := func(files map[string][]byte, privKey crypto.Signer, spiffeBundles map[string][]byte) error {
+		if err := writeTLSConfig(tlsConfigPath, files, map[string]os.FileMode{"key.pem": 0o400}); err != nil {
+			return fmt.Errorf("writing tls-config: %w", err)
 		}
-		log.Warn("Requesting cert", "err", err)
-		log.Info("Waiting for retry")
-		select {
-		case <-ticker.C:
-		case <-ctx.Done():
-			return fmt.Errorf("waiting for retry to request cert: %w", ctx.Err())
+		if workloadAPI != nil {
+			if err := workloadAPI.Update(files["certChain.pem"], privKey, files["mesh-ca.pem"], files["crl.pem"], spiffeBundles); err != nil {
+				return fmt.Errorf("updating SPIFFE Workload API: %w", err)
+			}
 		}
+		currentKey, currentFiles = privKey, files
+		return nil
 	}
 
-	// convert privKey to PEM
-	privKeyBytes, err := x509.MarshalPKCS8PrivateKey(privKey)
-	if err != nil {
-		return fmt.Errorf("marshaling private key: %w", err)
-	}
-	pemEncodedPrivKey := pem.EncodeToMemory(&pem.Block{
-		Type:  "PRIVATE KEY",
-		Bytes: privKeyBytes,
-	})
+	// requestTLSConfig requests a mesh certificate for a fresh key, retrying until it succeeds,
+	// and atomically writes the TLS config.
+	requestTLSConfig := func() (*meshapi.NewMeshCertResponse, error) {
+		privKey, err := keyAlgorithm.GenerateKey()
+		if err != nil {
+			return nil, fmt.Errorf("generating key: %w", err)
+		}
 
-	// make sure directories exist
-	if err := os.MkdirAll("/contrast/tls-config", 0o755); err != nil {
-		return fmt.Errorf("creating tls-config directory: %w", err)
-	}
-	if err := os.MkdirAll("/contrast/secrets", 0o755); err != nil {
-		return fmt.Errorf("creating secrets directory: %w", err)
-	}
+		ticker := time.NewTicker(5 * time.Second)
+		defer ticker.Stop()
+		var resp *meshapi.NewMeshCertResponse
+		for {
+			resp, err = requestCert(privKey)
+			if err == nil {
+				log.Info("Successfully requested cert from Coordinator")
+				break
+			}
+			log.Warn("Requesting cert", "err", err)
+			log.Info("Waiting for retry")
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return nil, fmt.Errorf("waiting for retry to request cert: %w", ctx.Err())
+			}
+		}
 
-	// write files to disk
-	err = os.WriteFile("/contrast/tls-config/mesh-ca.pem", resp.MeshCACert, 0o444)
-	if err != nil {
-		return fmt.Errorf("writing mesh-ca.pem: %w", err)
+		// convert privKey to PEM
+		privKeyBytes, err := x509.MarshalPKCS8PrivateKey(privKey)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling private key: %w", err)
+		}
+		pemEncodedPrivKey := pem.EncodeToMemory(&pem.Block{
+			Type:  "PRIVATE KEY",
+			Bytes: privKeyBytes,
+		})
+
+		// Coordinators without federation support don't send a trust bundle.
+		trustBundle := resp.TrustBundle
+		if len(trustBundle) == 0 {
+			trustBundle = resp.MeshCACert
+		}
+		files := map[string][]byte{
+			"mesh-ca.pem":             resp.MeshCACert,
+			"trust-bundle.pem":        trustBundle,
+			"certChain.pem":           resp.CertChain,
+			"key.pem":                 pemEncodedPrivKey,
+			"coordinator-root-ca.pem": resp.RootCACert,
+			"crl.pem":                 resp.CRL,
+		}
+		if err := writeFiles(files, privKey, resp.FederatedSPIFFEBundles); err != nil {
+			return nil, err
+		}
+		return resp, nil
 	}
-	err = os.WriteFile("/contrast/tls-config/certChain.pem", resp.CertChain, 0o444)
-	if err != nil {
-		return fmt.Errorf("writing certChain.pem: %w", err)
+
+	// refreshTrustBundle replaces the trust bundle and the CRLs of the current TLS config with the
+	// ones the Coordinator currently serves, so that revocations and changes to the federated
+	// deployments take effect without a new certificate.
+	refreshTrustBundle := func() error {
+		conn, err := dialCoordinator(currentKey)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		resp, err := meshapi.NewMeshAPIClient(conn).GetTrustBundle(ctx, &meshapi.GetTrustBundleRequest{})
+		if err != nil {
+			return fmt.Errorf("calling GetTrustBundle: %w", err)
+		}
+		if !bytes.Equal(resp.MeshCACert, currentFiles["mesh-ca.pem"]) {
+			// The mesh CA changed with a manifest update. The trust bundle of the new mesh CA
+			// must not replace the one the current certificate was issued with, so the new
+			// trust bundle is only used after the next renewal.
+			log.Info("Mesh CA changed, keeping trust bundle until the mesh certificate is renewed")
+			return nil
+		}
+		if bytes.Equal(resp.TrustBundle, currentFiles["trust-bundle.pem"]) && bytes.Equal(resp.CRL, currentFiles["crl.pem"]) {
+			return nil
+		}
+		files := maps.Clone(currentFiles)
+		files["trust-bundle.pem"] = resp.TrustBundle
+		files["crl.pem"] = resp.CRL
+		if err := writeFiles(files, currentKey, resp.FederatedSPIFFEBundles); err != nil {
+			return err
+		}
+		log.Info("Updated trust bundle and CRLs")
+		return nil
 	}
-	err = os.WriteFile("/contrast/tls-config/key.pem", pemEncodedPrivKey, 0o400)
+
+	resp, err := requestTLSConfig()
 	if err != nil {
-		return fmt.Errorf("writing key.pem: %w", err)
+		return err
 	}
-	err = os.WriteFile("/contrast/tls-config/coordinator-root-ca.pem", resp.RootCACert, 0o444)
-	if err != nil {
-		return fmt.Errorf("writing coordinator-root-ca.pem: %w", err)
+
+	// make sure directories exist
+	if err := os.MkdirAll("/contrast/secrets", 0o755); err != nil {
+		return fmt.Errorf("creating secrets directory: %w", err)
 	}
 
 	if len(resp.WorkloadSecret) > 0 {
@@ -203,11 +292,60 @@ func run(cmd *cobra.Command, _ []string) (retErr error) {
 		log.Info("Encrypted mount setup done")
 	}
 
+	serveErr := make(chan error, 1)
+	if workloadAPI != nil {
+		lis, err := workloadapi.Listen(constants.SPIFFEWorkloadAPISocketPath)
+		if err != nil {
+			return fmt.Errorf("listening for SPIFFE Workload API: %w", err)
+		}
+		log.Info("Serving SPIFFE Workload API", "socket", constants.SPIFFEWorkloadAPISocketPath)
+		go func() {
+			if err := workloadAPI.Serve(ctx, lis); err != nil {
+				serveErr <- err
+			}
+		}()
+	}
+
 	if err := os.WriteFile("/done", []byte(""), 0o644); err != nil {
 		return fmt.Errorf("creating startup probe done directory:%w", err)
 	}
 	log.Info("Initializer done")
 
+	if os.Getenv(constants.MeshCertRenewalEnvVar) != "" || workloadAPI != nil {
+		// Renewal keeps the initializer running, which also keeps propagated cryptsetup
+		// device mounts alive and the SPIFFE Workload API available.
+		log.Info("Renewing mesh certificate before it expires")
+		for {
+			notAfter, err := certNotAfter(resp.CertChain)
+			if err != nil {
+				return fmt.Errorf("getting mesh certificate expiry: %w", err)
+			}
+			renewAt := renewalTime(time.Now(), notAfter)
+			log.Info("Scheduled mesh certificate renewal", "notAfter", notAfter, "renewAt", renewAt)
+			for time.Now().Before(renewAt) {
+				select {
+				case <-time.After(min(time.Until(renewAt), trustBundleRefreshInterval)):
+				case err := <-serveErr:
+					return fmt.Errorf("serving SPIFFE Workload API: %w", err)
+				case <-ctx.Done():
+					return nil
+				}
+				if time.Now().Before(renewAt) {
+					if err := refreshTrustBundle(); err != nil {
+						log.Warn("Refreshing trust bundle", "err", err)
+					}
+				}
+			}
+			resp, err = requestTLSConfig()
+			if errors.Is(err, context.Canceled) {
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("renewing mesh certificate: %w", err)
+			}
+			log.Info("Renewed mesh certificate")
+		}
+	}
+
 	if cryptsetupDevicePath != "" {
 		// The device mount is created by the initializer and shared with the application
 		// container through a common emptyDir. We want to avoid mounting on a sub-path of
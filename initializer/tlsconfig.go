@@ -0,0 +1,126 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package main
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	// tlsConfigPath is the path the mesh certificates are written to.
+	tlsConfigPath = "/contrast/tls-config"
+
+	// tlsConfigDataLink is the name of the symlink in tlsConfigPath that points to the current
+	// versioned directory.
+	tlsConfigDataLink = "..data"
+
+	// tlsConfigDirPrefix is the name prefix of the versioned directories in tlsConfigPath.
+	tlsConfigDirPrefix = "..tls-config-"
+)
+
+// writeTLSConfig atomically replaces the TLS config at path with the given files.
+//
+// The layout is the one Kubernetes uses for projected volumes: the files are written to a new,
+// versioned directory inside path, and the symlink path/..data is swapped to the new directory
+// with a rename. Every file in path is a symlink into ..data. Readers thus either see the
+// complete old or the complete new set of files, never a mix of both, and watchers of path, like
+// Envoy, are notified by the rename. Previous versioned directories are removed afterwards.
+func writeTLSConfig(path string, files map[string][]byte, modes map[string]os.FileMode) error {
+	info, err := os.Lstat(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("checking TLS config directory: %w", err)
+	case !info.IsDir():
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("removing previous TLS config: %w", err)
+		}
+	}
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return fmt.Errorf("creating TLS config directory: %w", err)
+	}
+
+	dirName := tlsConfigDirPrefix + strconv.FormatInt(time.Now().UnixNano(), 10)
+	if err := os.Mkdir(filepath.Join(path, dirName), 0o755); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+	for name, content := range files {
+		mode, ok := modes[name]
+		if !ok {
+			mode = 0o444
+		}
+		if err := os.WriteFile(filepath.Join(path, dirName, name), content, mode); err != nil {
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+	}
+
+	// The link targets are relative, so that the links resolve in all containers, independent of
+	// where the shared volume is mounted.
+	if err := replaceWithSymlink(filepath.Join(path, tlsConfigDataLink), dirName); err != nil {
+		return fmt.Errorf("replacing TLS config: %w", err)
+	}
+	for name := range files {
+		target := filepath.Join(tlsConfigDataLink, name)
+		if current, err := os.Readlink(filepath.Join(path, name)); err == nil && current == target {
+			continue
+		}
+		if err := replaceWithSymlink(filepath.Join(path, name), target); err != nil {
+			return fmt.Errorf("linking %s: %w", name, err)
+		}
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return fmt.Errorf("listing TLS config directory: %w", err)
+	}
+	for _, entry := range entries {
+		if _, ok := files[entry.Name()]; ok || entry.Name() == tlsConfigDataLink || entry.Name() == dirName {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(path, entry.Name())); err != nil {
+			return fmt.Errorf("removing previous TLS config: %w", err)
+		}
+	}
+	return nil
+}
+
+// replaceWithSymlink atomically replaces the file at path with a symlink to target.
+func replaceWithSymlink(path, target string) error {
+	tmpLink := path + ".tmp"
+	if err := os.RemoveAll(tmpLink); err != nil {
+		return err
+	}
+	if err := os.Symlink(target, tmpLink); err != nil {
+		return err
+	}
+	return os.Rename(tmpLink, path)
+}
+
+// certNotAfter returns the expiry date of the first certificate in a PEM-encoded chain.
+func certNotAfter(certChain []byte) (time.Time, error) {
+	block, _ := pem.Decode(certChain)
+	if block == nil {
+		return time.Time{}, errors.New("no PEM block found in certificate chain")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing certificate: %w", err)
+	}
+	return cert.NotAfter, nil
+}
+
+// renewalTime returns the time a certificate that expires at notAfter should be renewed at.
+//
+// Certificates are renewed after two thirds of their remaining lifetime, which leaves enough
+// time for retries if the Coordinator is unavailable.
+func renewalTime(now, notAfter time.Time) time.Time {
+	return now.Add(notAfter.Sub(now) * 2 / 3)
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteTLSConfig(t *testing.T) {
+	testCases := map[string]struct {
+		setup func(t *testing.T, path string)
+	}{
+		"no previous config": {},
+		"previous config is a directory": {
+			setup: func(t *testing.T, path string) {
+				require.NoError(t, os.MkdirAll(path, 0o755))
+				require.NoError(t, os.WriteFile(filepath.Join(path, "stale.pem"), []byte("stale"), 0o444))
+			},
+		},
+		"previous config is a symlink": {
+			setup: func(t *testing.T, path string) {
+				require.NoError(t, os.Symlink(t.TempDir(), path))
+			},
+		},
+		"previous config": {
+			setup: func(t *testing.T, path string) {
+				require.NoError(t, writeTLSConfig(path, map[string][]byte{"stale.pem": []byte("stale"), "cert.pem": []byte("old")}, nil))
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+
+			baseDir := t.TempDir()
+			path := filepath.Join(baseDir, "tls-config")
+			if tc.setup != nil {
+				tc.setup(t, path)
+			}
+
+			files := map[string][]byte{"cert.pem": []byte("cert"), "key.pem": []byte("key")}
+			require.NoError(writeTLSConfig(path, files, map[string]os.FileMode{"key.pem": 0o400}))
+
+			for name, content := range files {
+				got, err := os.ReadFile(filepath.Join(path, name))
+				require.NoError(err)
+				assert.Equal(content, got)
+			}
+			assert.NoFileExists(filepath.Join(path, "stale.pem"))
+			info, err := os.Stat(filepath.Join(path, "key.pem"))
+			require.NoError(err)
+			assert.Equal(os.FileMode(0o400), info.Mode().Perm())
+
+			target, err := os.Readlink(filepath.Join(path, tlsConfigDataLink))
+			require.NoError(err)
+			assert.False(filepath.IsAbs(target))
+			for name := range files {
+				target, err := os.Readlink(filepath.Join(path, name))
+				require.NoError(err)
+				assert.Equal(filepath.Join(tlsConfigDataLink, name), target)
+			}
+			entries, err := os.ReadDir(path)
+			require.NoError(err)
+			assert.Len(entries, 4, "only the links and the current directory should remain")
+			entries, err = os.ReadDir(baseDir)
+			require.NoError(err)
+			assert.Len(entries, 1)
+		})
+	}
+}
+
+func TestRenewalTime(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, now.Add(2*time.Hour), renewalTime(now, now.Add(3*time.Hour)))
+	assert.Equal(t, now.Add(-2*time.Hour), renewalTime(now, now.Add(-3*time.Hour)))
+}
@@ -168,6 +168,24 @@ func TestPublicKey(t *testing.T) {
 	}
 }
 
+// TestGetCertificateKeyAlgorithms ensures that aTLS certificates can be created and verified for
+// all key algorithms a manifest can configure.
+func TestGetCertificateKeyAlgorithms(t *testing.T) {
+	for _, alg := range cryptohelpers.KeyAlgorithms() {
+		t.Run(string(alg), func(t *testing.T) {
+			require := require.New(t)
+
+			key, err := alg.GenerateKey()
+			require.NoError(err)
+			tlsCert, err := getCertificate(t.Context(), nil, key, publicKey(key), nil)
+			require.NoError(err)
+			cert, _, err := processCertificate(tlsCert.Certificate, nil)
+			require.NoError(err)
+			assert.Equal(t, key.Public(), cert.PublicKey)
+		})
+	}
+}
+
 // contextValidator fakes a validator that takes a long time to validate.
 // If the inputC channel is not fed with a result, it will wait for the context to expire.
 type contextValidator struct {
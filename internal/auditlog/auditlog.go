@@ -0,0 +1,566 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+// Package auditlog implements an append-only log of security-relevant Coordinator events.
+//
+// Each event references the hash of its predecessor, so the events form a hash chain. The events
+// are stored in segments of up to segmentEvents events, which are signed records of a
+// history.Records set. Their signatures and the rollback protection of the record set prevent a
+// rewrite of the log by anyone without the transaction signing key.
+//
+// Recording an event only queues it. Run writes the queued events in batches, so that the audited
+// operations don't wait for the store.
+package auditlog
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/edgelesssys/contrast/internal/history"
+	"k8s.io/utils/clock"
+)
+
+const (
+	// recordPrefix is the store key prefix of the audit log records.
+	recordPrefix = "audit"
+	// segmentNamePrefix is the prefix of the record name of a segment, which is followed by the
+	// zero-padded hex-encoded segment number, so that the names sort by number.
+	segmentNamePrefix = "segment-"
+	// segmentEvents is the number of events in a full segment. Segment n holds the events with the
+	// sequence numbers from n*segmentEvents to (n+1)*segmentEvents-1.
+	segmentEvents = 128
+	// flushInterval is the maximum time a recorded event waits in the queue.
+	flushInterval = time.Second
+	// maxBatchEvents is the number of queued events that triggers a write before flushInterval.
+	maxBatchEvents = 256
+	// queueSize is the number of events that can wait for Run to pick them up.
+	queueSize = 1024
+	// maxPendingEvents is the number of events that are kept for another attempt if writing them
+	// fails. Further events are dropped.
+	maxPendingEvents = 4096
+	// DefaultRetention is the default number of most recent events kept in the log.
+	DefaultRetention = 10000
+)
+
+// errSegmentFull is returned by a segment update if the segment can't hold more events.
+var errSegmentFull = errors.New("audit log segment is full")
+
+// EventType identifies the kind of an audit event.
+type EventType string
+
+const (
+	// EventManifestSet is recorded when a new manifest becomes active through SetManifest.
+	EventManifestSet EventType = "manifest.set"
+	// EventManifestScheduled is recorded when a manifest update is scheduled for later activation.
+	EventManifestScheduled EventType = "manifest.scheduled"
+	// EventManifestActivated is recorded when the Coordinator activates a scheduled manifest update.
+	EventManifestActivated EventType = "manifest.activated"
+	// EventManifestCancelled is recorded when a scheduled manifest update is cancelled.
+	EventManifestCancelled EventType = "manifest.cancelled"
+	// EventManifestRollback is recorded when an earlier manifest is re-activated.
+	EventManifestRollback EventType = "manifest.rollback"
+	// EventRecover is recorded when a seedshare owner recovers the Coordinator.
+	EventRecover EventType = "coordinator.recover"
+	// EventPeerRecover is recorded when the Coordinator hands its secrets to a recovering peer.
+	EventPeerRecover EventType = "coordinator.peer-recover"
+	// EventMeshCertIssued is recorded when the Coordinator issues a mesh certificate to a workload.
+	EventMeshCertIssued EventType = "meshcert.issue"
+	// EventMeshCertRevoked is recorded when a workload owner revokes a mesh certificate.
+	EventMeshCertRevoked EventType = "meshcert.revoke"
+	// EventSubCAIssued is recorded when a workload owner gets a sub CA certificate issued.
+	EventSubCAIssued EventType = "subca.issue"
+	// EventTransitEncrypt is recorded for encryption requests to the transit engine API.
+	EventTransitEncrypt EventType = "transit.encrypt"
+	// EventTransitDecrypt is recorded for decryption requests to the transit engine API.
+	EventTransitDecrypt EventType = "transit.decrypt"
+	// EventTransitDatakey is recorded when a workload generates a data key with the transit engine
+	// API.
+	EventTransitDatakey EventType = "transit.datakey"
+	// EventTransitHMAC is recorded when a workload computes an HMAC with the transit engine API.
+	EventTransitHMAC EventType = "transit.hmac"
+	// EventTransitSign is recorded when a workload signs data with the transit engine API.
+	EventTransitSign EventType = "transit.sign"
+	// EventTransitVerify is recorded when a workload verifies a signature or HMAC with the transit
+	// engine API.
+	EventTransitVerify EventType = "transit.verify"
+	// EventTransitRewrap is recorded for requests to re-encrypt data with the transit engine API.
+	EventTransitRewrap EventType = "transit.rewrap"
+	// EventTransitRotate is recorded when a workload rotates its transit engine key.
+	EventTransitRotate EventType = "transit.rotate"
+	// EventTransitConfig is recorded when a workload changes the configuration of its transit
+	// engine key.
+	EventTransitConfig EventType = "transit.config"
+	// EventKMIPCreate is recorded when a workload creates a symmetric key over KMIP.
+	EventKMIPCreate EventType = "kmip.create"
+	// EventKMIPGet is recorded when a workload gets the material of a symmetric key over KMIP.
+	EventKMIPGet EventType = "kmip.get"
+	// EventKMIPEncrypt is recorded for KMIP encryption requests.
+	EventKMIPEncrypt EventType = "kmip.encrypt"
+	// EventKMIPDecrypt is recorded for KMIP decryption requests.
+	EventKMIPDecrypt EventType = "kmip.decrypt"
+	// EventKMIPDestroy is recorded when a workload destroys a symmetric key over KMIP.
+	EventKMIPDestroy EventType = "kmip.destroy"
+	// EventDropped is recorded when events were dropped because they couldn't be written in time.
+	EventDropped EventType = "audit.dropped"
+)
+
+// Event is a single entry of the audit log.
+type Event struct {
+	// Sequence is the position of the event in the log, starting at 0.
+	Sequence uint64 `json:"sequence"`
+	// Time is the time the event was recorded at.
+	Time time.Time `json:"time"`
+	// Type identifies the kind of event.
+	Type EventType `json:"type"`
+	// Actor identifies who triggered the event, for example by public key or policy hash.
+	Actor string `json:"actor,omitempty"`
+	// PeerAddress is the network address the event was triggered from, if known.
+	PeerAddress string `json:"peer_address,omitempty"`
+	// Details holds event-specific attributes.
+	Details map[string]string `json:"details,omitempty"`
+	// PreviousHash is the hex-encoded hash of the previous event. It's empty for the first event.
+	PreviousHash string `json:"previous_hash,omitempty"`
+}
+
+// Log records events to a store. A nil Log discards all events.
+type Log struct {
+	records *history.Records
+	clock   clock.WithTicker
+	logger  *slog.Logger
+
+	// retention is the minimum number of most recent events kept in the log.
+	retention uint64
+	// queue holds the recorded events until Run writes them.
+	queue chan Event
+	// dropped counts the events that were dropped since the last write.
+	dropped atomic.Uint64
+}
+
+// New creates a Log that stores events in the given store.
+func New(store history.Store, logger *slog.Logger) *Log {
+	return &Log{
+		records:   history.NewRecords(store, recordPrefix, logger),
+		clock:     clock.RealClock{},
+		logger:    logger,
+		retention: DefaultRetention,
+		queue:     make(chan Event, queueSize),
+	}
+}
+
+// SetRetention sets the number of most recent events kept in the log. Older events are deleted
+// segment by segment, so somewhat more events may be kept.
+func (l *Log) SetRetention(events int) {
+	l.retention = uint64(events)
+}
+
+// Record queues an event for appending to the log.
+//
+// Time is set when the event is recorded, Sequence and PreviousHash when it's written. If the
+// queue is full, the event is dropped, and the number of dropped events is recorded later. Events
+// are never returned as errors, so that a failing audit store doesn't interrupt the audited
+// operation.
+func (l *Log) Record(event Event) {
+	if l == nil {
+		return
+	}
+	event.Time = l.clock.Now().UTC()
+	select {
+	case l.queue <- event:
+	default:
+		l.dropped.Add(1)
+	}
+}
+
+// Run writes the recorded events to the store until the context is done.
+//
+// signingKey returns the transaction signing key of the Coordinator. Events are kept until it's
+// available, up to maxPendingEvents.
+func (l *Log) Run(ctx context.Context, signingKey func() (*ecdsa.PrivateKey, error)) error {
+	go func() {
+		_ = l.records.Watch(ctx)
+	}()
+
+	ticker := l.clock.NewTicker(flushInterval)
+	defer ticker.Stop()
+	var pending []Event
+	for {
+		select {
+		case <-ctx.Done():
+			l.flush(signingKey, l.drain(pending))
+			return ctx.Err()
+		case event := <-l.queue:
+			pending = append(pending, event)
+			if len(pending) < maxBatchEvents {
+				continue
+			}
+		case <-ticker.C():
+		}
+		pending = l.flush(signingKey, l.drain(pending))
+	}
+}
+
+// drain appends the queued events to pending.
+func (l *Log) drain(pending []Event) []Event {
+	for {
+		select {
+		case event := <-l.queue:
+			pending = append(pending, event)
+		default:
+			return pending
+		}
+	}
+}
+
+// flush writes the pending events and returns the events that need another attempt.
+func (l *Log) flush(signingKey func() (*ecdsa.PrivateKey, error), pending []Event) []Event {
+	if dropped := l.dropped.Swap(0); dropped > 0 {
+		pending = append(pending, Event{
+			Time:    l.clock.Now().UTC(),
+			Type:    EventDropped,
+			Details: map[string]string{"count": strconv.FormatUint(dropped, 10)},
+		})
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	key, err := signingKey()
+	if err == nil {
+		err = l.append(key, pending)
+	}
+	if err == nil {
+		if err := l.prune(key); err != nil {
+			l.logger.Warn("Pruning audit log failed", "err", err)
+		}
+		return nil
+	}
+	if len(pending) >= maxPendingEvents {
+		l.logger.Error("Writing audit events failed, dropping them", "events", len(pending), "err", err)
+		l.dropped.Add(uint64(len(pending)))
+		return nil
+	}
+	l.logger.Warn("Writing audit events failed, retrying", "events", len(pending), "err", err)
+	return pending
+}
+
+// append writes the events to the newest segments of the log.
+func (l *Log) append(signingKey *ecdsa.PrivateKey, events []Event) error {
+	number, ok, err := l.lastSegment(signingKey)
+	if err != nil {
+		return err
+	}
+	var previousHash string
+	if ok && number > 0 {
+		// The newest segment may not be written yet, so the hash of the last event of its
+		// predecessor is needed to continue the chain.
+		previous, err := l.segment(signingKey, number-1)
+		if err != nil {
+			return err
+		}
+		previousHash = previous.lastHash()
+	}
+
+	for len(events) > 0 {
+		var written int
+		var lastHash string
+		err := l.records.Update(signingKey, segmentName(number), func(content []byte) ([]byte, error) {
+			seg := segment{PreviousHash: previousHash}
+			if content != nil {
+				seg = segment{}
+				if err := json.Unmarshal(content, &seg); err != nil {
+					return nil, fmt.Errorf("unmarshaling audit log segment %d: %w", number, err)
+				}
+			}
+			written, lastHash = 0, seg.lastHash()
+			if len(seg.Events) >= segmentEvents {
+				return nil, errSegmentFull
+			}
+			written = min(segmentEvents-len(seg.Events), len(events))
+			for _, event := range events[:written] {
+				event.Sequence = number*segmentEvents + uint64(len(seg.Events))
+				event.PreviousHash = seg.lastHash()
+				data, err := json.Marshal(event)
+				if err != nil {
+					return nil, fmt.Errorf("marshaling audit event: %w", err)
+				}
+				seg.Events = append(seg.Events, data)
+			}
+			lastHash = seg.lastHash()
+			return json.Marshal(seg)
+		})
+		if err != nil && !errors.Is(err, errSegmentFull) {
+			return fmt.Errorf("writing audit log segment %d: %w", number, err)
+		}
+		events = events[written:]
+		previousHash = lastHash
+		if errors.Is(err, errSegmentFull) || len(events) > 0 {
+			number++
+		}
+	}
+	return nil
+}
+
+// prune deletes the segments that only hold events beyond the retention.
+func (l *Log) prune(signingKey *ecdsa.PrivateKey) error {
+	if l.retention == 0 {
+		return nil
+	}
+	names, err := l.records.Names(signingKey)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	last, err := parseSegmentName(names[len(names)-1])
+	if err != nil {
+		return err
+	}
+	// The newest segment may hold a single event, so one more segment than needed for the
+	// retained events is kept.
+	keep := (l.retention+segmentEvents-1)/segmentEvents + 1
+	for _, name := range names {
+		number, err := parseSegmentName(name)
+		if err != nil {
+			return err
+		}
+		if number+keep > last {
+			break
+		}
+		if err := l.records.Delete(signingKey, name); err != nil {
+			return fmt.Errorf("deleting audit log segment %d: %w", number, err)
+		}
+	}
+	return nil
+}
+
+// Events returns up to limit JSON-encoded events recorded after the event with the given hash,
+// oldest first, and whether there are more events. If after is empty, the events are returned
+// from the oldest retained event.
+//
+// If afterSequence is set, it must be the sequence number of the event referenced by after.
+// Otherwise, the log is searched for the event, starting at the newest event. The returned events
+// are checked to continue the chain at after.
+func (l *Log) Events(signingKey *ecdsa.PrivateKey, after []byte, afterSequence *uint64, limit int) ([][]byte, bool, error) {
+	first, last, ok, err := l.segmentRange(signingKey)
+	if err != nil {
+		return nil, false, err
+	}
+	if !ok {
+		if len(after) > 0 {
+			return nil, false, fmt.Errorf("event %x is not part of the audit log", after)
+		}
+		return nil, false, nil
+	}
+
+	var start uint64
+	previousHash := after
+	switch {
+	case len(after) == 0:
+		oldest, err := l.segment(signingKey, first)
+		if err != nil {
+			return nil, false, err
+		}
+		start = first * segmentEvents
+		if previousHash, err = hex.DecodeString(oldest.PreviousHash); err != nil {
+			return nil, false, fmt.Errorf("audit log segment %d has an invalid previous hash", first)
+		}
+	case afterSequence != nil:
+		start = *afterSequence + 1
+	default:
+		if start, err = l.find(signingKey, after, first, last); err != nil {
+			return nil, false, err
+		}
+	}
+	if start < first*segmentEvents {
+		return nil, false, fmt.Errorf("event %x was pruned from the audit log", after)
+	}
+
+	var events [][]byte
+	for number := start / segmentEvents; number <= last && len(events) <= limit; number++ {
+		seg, err := l.segment(signingKey, number)
+		if err != nil {
+			return nil, false, err
+		}
+		offset := 0
+		if number == start/segmentEvents {
+			offset = min(int(start%segmentEvents), len(seg.Events))
+		}
+		for _, data := range seg.Events[offset:] {
+			events = append(events, data)
+		}
+	}
+	if len(events) == 0 {
+		if len(after) == 0 {
+			return nil, false, nil
+		}
+		// No events were recorded after the given one, so it must be the newest event.
+		newest, err := l.segment(signingKey, last)
+		if err != nil {
+			return nil, false, err
+		}
+		newestHash := newest.lastHash()
+		if len(newest.Events) == 0 && last > first {
+			// The newest segment was reserved, but not written yet.
+			previous, err := l.segment(signingKey, last-1)
+			if err != nil {
+				return nil, false, err
+			}
+			newestHash = previous.lastHash()
+		}
+		if newestHash != hex.EncodeToString(after) || start != last*segmentEvents+uint64(len(newest.Events)) {
+			return nil, false, fmt.Errorf("event %x is not part of the audit log", after)
+		}
+		return nil, false, nil
+	}
+
+	more := len(events) > limit
+	events = events[:min(len(events), limit)]
+	if err := VerifyChain(previousHash, events); err != nil {
+		return nil, false, err
+	}
+	return events, more, nil
+}
+
+// find returns the sequence number of the event after the event with the given hash.
+func (l *Log) find(signingKey *ecdsa.PrivateKey, hash []byte, first, last uint64) (uint64, error) {
+	hexHash := hex.EncodeToString(hash)
+	for number := last; number >= first && number <= last; number-- {
+		seg, err := l.segment(signingKey, number)
+		if err != nil {
+			return 0, err
+		}
+		for i := len(seg.Events) - 1; i >= 0; i-- {
+			if seg.hash(i) == hexHash {
+				return number*segmentEvents + uint64(i) + 1, nil
+			}
+		}
+		if seg.PreviousHash == hexHash {
+			return number * segmentEvents, nil
+		}
+	}
+	return 0, fmt.Errorf("event %x is not part of the audit log", hash)
+}
+
+// segment reads the segment with the given number. A segment that isn't written yet is empty.
+func (l *Log) segment(signingKey *ecdsa.PrivateKey, number uint64) (*segment, error) {
+	content, err := l.records.Get(signingKey, segmentName(number))
+	if err != nil {
+		return nil, fmt.Errorf("getting audit log segment %d: %w", number, err)
+	}
+	var seg segment
+	if len(content) > 0 {
+		if err := json.Unmarshal(content, &seg); err != nil {
+			return nil, fmt.Errorf("unmarshaling audit log segment %d: %w", number, err)
+		}
+	}
+	return &seg, nil
+}
+
+// lastSegment returns the number of the newest segment, and whether there is any.
+func (l *Log) lastSegment(signingKey *ecdsa.PrivateKey) (uint64, bool, error) {
+	_, last, ok, err := l.segmentRange(signingKey)
+	return last, ok, err
+}
+
+// segmentRange returns the numbers of the oldest and the newest segment, and whether there is any.
+func (l *Log) segmentRange(signingKey *ecdsa.PrivateKey) (uint64, uint64, bool, error) {
+	names, err := l.records.Names(signingKey)
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("listing audit log segments: %w", err)
+	}
+	if len(names) == 0 {
+		return 0, 0, false, nil
+	}
+	first, err := parseSegmentName(names[0])
+	if err != nil {
+		return 0, 0, false, err
+	}
+	last, err := parseSegmentName(names[len(names)-1])
+	if err != nil {
+		return 0, 0, false, err
+	}
+	return first, last, true, nil
+}
+
+// VerifyChain checks that the JSON-encoded events form a hash chain, oldest first, that
+// continues the event with the given hash. If previousHash is empty, the first event must be the
+// first event of the log.
+func VerifyChain(previousHash []byte, events [][]byte) error {
+	var sequence uint64
+	for i, data := range events {
+		var event Event
+		if err := json.Unmarshal(data, &event); err != nil {
+			return fmt.Errorf("unmarshaling audit event: %w", err)
+		}
+		if event.PreviousHash != hex.EncodeToString(previousHash) {
+			return fmt.Errorf("audit event %d doesn't continue the chain at %x", event.Sequence, previousHash)
+		}
+		if i > 0 && event.Sequence != sequence+1 {
+			return fmt.Errorf("audit event %d follows event %d", event.Sequence, sequence)
+		} else if i == 0 && len(previousHash) == 0 && event.Sequence != 0 {
+			return fmt.Errorf("first audit event has sequence number %d", event.Sequence)
+		}
+		sequence = event.Sequence
+		hash := history.Digest(data)
+		previousHash = hash[:]
+	}
+	return nil
+}
+
+// Migrate copies the audit log from src to dst.
+//
+// The migration is skipped if dst already has an audit log or src has none, and the returned bool
+// reports whether a migration took place. An interrupted migration can safely be retried.
+func Migrate(src, dst history.Store) (bool, error) {
+	migrated, err := history.MigrateRecords(src, dst, recordPrefix)
+	if err != nil {
+		return false, fmt.Errorf("migrating audit log: %w", err)
+	}
+	return migrated, nil
+}
+
+// segment is the content of a segment record.
+type segment struct {
+	// PreviousHash is the hex-encoded hash of the event before the first event of the segment. It's
+	// empty for the first segment.
+	PreviousHash string `json:"previous_hash,omitempty"`
+	// Events are the JSON-encoded events of the segment, oldest first.
+	Events []json.RawMessage `json:"events"`
+}
+
+// hash returns the hex-encoded hash of the event at index i.
+func (s *segment) hash(i int) string {
+	hash := history.Digest(s.Events[i])
+	return hex.EncodeToString(hash[:])
+}
+
+// lastHash returns the hex-encoded hash of the last event of the segment, or the previous hash of
+// an empty segment.
+func (s *segment) lastHash() string {
+	if len(s.Events) == 0 {
+		return s.PreviousHash
+	}
+	return s.hash(len(s.Events) - 1)
+}
+
+func segmentName(number uint64) string {
+	return fmt.Sprintf("%s%016x", segmentNamePrefix, number)
+}
+
+func parseSegmentName(name string) (uint64, error) {
+	number, err := strconv.ParseUint(strings.TrimPrefix(name, segmentNamePrefix), 16, 64)
+	if err != nil || !strings.HasPrefix(name, segmentNamePrefix) {
+		return 0, fmt.Errorf("invalid audit log segment name %q", name)
+	}
+	return number, nil
+}
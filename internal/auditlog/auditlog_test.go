@@ -0,0 +1,319 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package auditlog
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/edgelesssys/contrast/internal/history"
+	"github.com/edgelesssys/contrast/internal/history/aferostore"
+	"github.com/edgelesssys/contrast/internal/testkeys"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func TestLog(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	store := aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()})
+	key := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP256Keys[0])
+	log := newTestLog(store)
+
+	events, more, err := log.Events(key, nil, nil, 100)
+	require.NoError(err)
+	require.Empty(events)
+	require.False(more)
+
+	log.Record(Event{Type: EventManifestSet, Actor: "owner", Details: map[string]string{"transition": "abc"}})
+	log.Record(Event{Type: EventMeshCertIssued, Actor: "policy", PeerAddress: "192.0.2.1:1234"})
+	log.Record(Event{Type: EventTransitEncrypt, Actor: "workload"})
+	flush(t, log, key)
+
+	events, more, err = log.Events(key, nil, nil, 100)
+	require.NoError(err)
+	require.Len(events, 3)
+	require.False(more)
+	require.NoError(VerifyChain(nil, events))
+
+	var first Event
+	require.NoError(json.Unmarshal(events[0], &first))
+	assert.Equal(uint64(0), first.Sequence)
+	assert.Equal(EventManifestSet, first.Type)
+	assert.Equal("owner", first.Actor)
+	assert.Equal("abc", first.Details["transition"])
+	assert.Empty(first.PreviousHash)
+	assert.False(first.Time.IsZero())
+
+	// Exporting after an event only returns its successors, with or without its sequence number.
+	firstHash := history.Digest(events[0])
+	newer, _, err := log.Events(key, firstHash[:], nil, 100)
+	require.NoError(err)
+	require.Equal(events[1:], newer)
+	require.NoError(VerifyChain(firstHash[:], newer))
+	require.Error(VerifyChain(nil, newer), "chain must start at the first event")
+	sequence := uint64(0)
+	newer, _, err = log.Events(key, firstHash[:], &sequence, 100)
+	require.NoError(err)
+	require.Equal(events[1:], newer)
+	sequence = 1
+	_, _, err = log.Events(key, firstHash[:], &sequence, 100)
+	require.Error(err, "a wrong sequence number must be detected")
+
+	latestHash := history.Digest(events[2])
+	newer, _, err = log.Events(key, latestHash[:], nil, 100)
+	require.NoError(err)
+	require.Empty(newer)
+
+	_, _, err = log.Events(key, make([]byte, history.HashSize), nil, 100)
+	require.Error(err)
+
+	// A nil log discards events.
+	var nilLog *Log
+	nilLog.Record(Event{Type: EventRecover})
+}
+
+func TestLog_Pages(t *testing.T) {
+	require := require.New(t)
+
+	store := aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()})
+	key := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP256Keys[0])
+	log := newTestLog(store)
+	log.SetRetention(0)
+
+	// The events span multiple segments and are written in multiple batches.
+	for range 3 {
+		for range 100 {
+			log.Record(Event{Type: EventTransitDecrypt})
+		}
+		flush(t, log, key)
+	}
+
+	all, more, err := log.Events(key, nil, nil, 1000)
+	require.NoError(err)
+	require.False(more)
+	require.Len(all, 300)
+	require.NoError(VerifyChain(nil, all))
+
+	var exported [][]byte
+	var after []byte
+	var afterSequence *uint64
+	for {
+		page, more, err := log.Events(key, after, afterSequence, 70)
+		require.NoError(err)
+		require.LessOrEqual(len(page), 70)
+		exported = append(exported, page...)
+		if !more {
+			break
+		}
+		var last Event
+		require.NoError(json.Unmarshal(page[len(page)-1], &last))
+		hash := history.Digest(page[len(page)-1])
+		after, afterSequence = hash[:], &last.Sequence
+	}
+	require.Equal(all, exported)
+}
+
+func TestLog_Retention(t *testing.T) {
+	require := require.New(t)
+
+	store := aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()})
+	key := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP256Keys[0])
+	log := newTestLog(store)
+	log.SetRetention(10)
+
+	for range 300 {
+		log.Record(Event{Type: EventTransitDecrypt})
+	}
+	flush(t, log, key)
+	events, _, err := log.Events(key, nil, nil, 1000)
+	require.NoError(err)
+	require.Len(events, 300-segmentEvents, "only the oldest segment must be pruned")
+
+	var oldest Event
+	require.NoError(json.Unmarshal(events[0], &oldest))
+	require.Equal(uint64(segmentEvents), oldest.Sequence)
+	previousHash, err := hex.DecodeString(oldest.PreviousHash)
+	require.NoError(err)
+	require.NoError(VerifyChain(previousHash, events))
+
+	// Pruned events can't be used as the start of an export.
+	sequence := uint64(5)
+	_, _, err = log.Events(key, make([]byte, history.HashSize), &sequence, 1000)
+	require.Error(err)
+}
+
+func TestLog_Tampering(t *testing.T) {
+	require := require.New(t)
+
+	store := aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()})
+	key := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP256Keys[0])
+	log := newTestLog(store)
+	log.Record(Event{Type: EventRecover, Actor: "seedshare-owner"})
+	flush(t, log, key)
+	oldSegment, err := store.Get("audit/" + segmentName(0))
+	require.NoError(err)
+	log.Record(Event{Type: EventManifestSet, Actor: "owner"})
+	flush(t, log, key)
+
+	events, _, err := log.Events(key, nil, nil, 100)
+	require.NoError(err)
+	require.Len(events, 2)
+
+	// Replacing an event breaks the chain.
+	var event Event
+	require.NoError(json.Unmarshal(events[0], &event))
+	event.Actor = "attacker"
+	tampered, err := json.Marshal(event)
+	require.NoError(err)
+	require.Error(VerifyChain(nil, [][]byte{tampered, events[1]}))
+
+	// Omitting an event breaks the chain.
+	require.Error(VerifyChain(nil, events[1:]))
+
+	// Rolling back the stored log is detected.
+	require.NoError(store.Set("audit/"+segmentName(0), oldSegment))
+	_, _, err = newTestLog(store).Events(key, nil, nil, 100)
+	require.ErrorIs(err, history.ErrRecordRollback)
+
+	// The log can't be read with another key.
+	otherKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[0])
+	_, _, err = newTestLog(store).Events(otherKey, nil, nil, 100)
+	require.Error(err)
+}
+
+func TestLog_Concurrent(t *testing.T) {
+	require := require.New(t)
+
+	store := aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()})
+	key := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP256Keys[0])
+	// Two logs share the store, like two Coordinators.
+	logs := []*Log{newTestLog(store), newTestLog(store)}
+
+	done := make(chan struct{})
+	for _, log := range logs {
+		go func() {
+			for range 10 {
+				log.Record(Event{Type: EventTransitDecrypt})
+				flush(t, log, key)
+			}
+			done <- struct{}{}
+		}()
+	}
+	<-done
+	<-done
+
+	events, _, err := logs[0].Events(key, nil, nil, 100)
+	require.NoError(err)
+	require.Len(events, 20)
+	require.NoError(VerifyChain(nil, events))
+}
+
+func TestLog_Dropped(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	store := aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()})
+	key := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP256Keys[0])
+	log := newTestLog(store)
+
+	for range queueSize + 3 {
+		log.Record(Event{Type: EventTransitDecrypt})
+	}
+	flush(t, log, key)
+
+	events, _, err := log.Events(key, nil, nil, 2*queueSize)
+	require.NoError(err)
+	require.Len(events, queueSize+1)
+	var dropped Event
+	require.NoError(json.Unmarshal(events[queueSize], &dropped))
+	assert.Equal(EventDropped, dropped.Type)
+	assert.Equal("3", dropped.Details["count"])
+}
+
+func TestLog_Run(t *testing.T) {
+	require := require.New(t)
+
+	store := aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()})
+	key := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP256Keys[0])
+	clock := testingclock.NewFakeClock(time.Now())
+	log := New(store, slog.New(slog.DiscardHandler))
+	log.clock = clock
+
+	ctx, cancel := context.WithCancel(t.Context())
+	runDone := make(chan error)
+	go func() {
+		runDone <- log.Run(ctx, func() (*ecdsa.PrivateKey, error) { return key, nil })
+	}()
+
+	log.Record(Event{Type: EventManifestSet})
+	require.Eventually(func() bool {
+		clock.Step(flushInterval)
+		events, _, err := log.Events(key, nil, nil, 100)
+		return err == nil && len(events) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Queued events are written when Run stops.
+	log.Record(Event{Type: EventRecover})
+	cancel()
+	require.ErrorIs(<-runDone, context.Canceled)
+	events, _, err := log.Events(key, nil, nil, 100)
+	require.NoError(err)
+	require.Len(events, 2)
+}
+
+func TestMigrate(t *testing.T) {
+	require := require.New(t)
+
+	src := aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()})
+	dst := aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()})
+	key := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP256Keys[0])
+
+	migrated, err := Migrate(src, dst)
+	require.NoError(err)
+	require.False(migrated, "empty audit log must not be migrated")
+
+	log := newTestLog(src)
+	log.Record(Event{Type: EventManifestSet})
+	log.Record(Event{Type: EventMeshCertIssued})
+	flush(t, log, key)
+	events, _, err := log.Events(key, nil, nil, 100)
+	require.NoError(err)
+
+	migrated, err = Migrate(src, dst)
+	require.NoError(err)
+	require.True(migrated)
+
+	migratedEvents, _, err := newTestLog(dst).Events(key, nil, nil, 100)
+	require.NoError(err)
+	require.Equal(events, migratedEvents)
+
+	// The destination's audit log is never overwritten.
+	log.Record(Event{Type: EventRecover})
+	flush(t, log, key)
+	migrated, err = Migrate(src, dst)
+	require.NoError(err)
+	require.False(migrated)
+}
+
+func newTestLog(store history.Store) *Log {
+	log := New(store, slog.New(slog.DiscardHandler))
+	log.clock = testingclock.NewFakeClock(time.Now())
+	return log
+}
+
+// flush writes the queued events of the log.
+func flush(t *testing.T, log *Log, key *ecdsa.PrivateKey) {
+	t.Helper()
+	pending := log.flush(func() (*ecdsa.PrivateKey, error) { return key, nil }, log.drain(nil))
+	require.Empty(t, pending)
+}
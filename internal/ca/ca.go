@@ -4,15 +4,17 @@
 package ca
 
 import (
-	"crypto/ecdsa"
+	"crypto"
 	"crypto/rand"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"math/big"
 	"net"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/edgelesssys/contrast/internal/cryptohelpers"
@@ -28,10 +30,10 @@ import (
 // cross-signing workload certs was adapted from MarbleRun, see:
 // https://docs.edgeless.systems/marblerun/architecture/security#public-key-infrastructure-and-certificate-authority
 type CA struct {
-	rootCAPrivKey *ecdsa.PrivateKey
+	rootCAPrivKey crypto.Signer
 	rootCAPEM     []byte
 
-	intermPrivKey *ecdsa.PrivateKey
+	intermPrivKey crypto.Signer
 
 	intermCAPEM []byte
 
@@ -41,8 +43,8 @@ type CA struct {
 	meshCACertPool *x509.CertPool
 }
 
-// New creates a new CA.
-func New(rootPrivKey, intermPrivKey *ecdsa.PrivateKey) (*CA, error) {
+// New creates a new CA. The keys may be of any algorithm supported by crypto/x509.
+func New(rootPrivKey, intermPrivKey crypto.Signer) (*CA, error) {
 	now := time.Now()
 	notBefore := now.Add(-time.Hour)
 	notAfter := now.AddDate(10, 0, 0)
@@ -55,7 +57,7 @@ func New(rootPrivKey, intermPrivKey *ecdsa.PrivateKey) (*CA, error) {
 		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
 		BasicConstraintsValid: true,
 	}
-	rootCert, rootPEM, err := createCert(rootTemplate, rootTemplate, &rootPrivKey.PublicKey, rootPrivKey)
+	rootCert, rootPEM, err := createCert(rootTemplate, rootTemplate, rootPrivKey.Public(), rootPrivKey)
 	if err != nil {
 		return nil, fmt.Errorf("creating root certificate: %w", err)
 	}
@@ -66,10 +68,10 @@ func New(rootPrivKey, intermPrivKey *ecdsa.PrivateKey) (*CA, error) {
 		NotBefore:             notBefore,
 		NotAfter:              notAfter,
 		IsCA:                  true,
-		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
 		BasicConstraintsValid: true,
 	}
-	_, intermCAPEM, err := createCert(intermCACertTemplate, rootCert, &intermPrivKey.PublicKey, rootPrivKey)
+	_, intermCAPEM, err := createCert(intermCACertTemplate, rootCert, intermPrivKey.Public(), rootPrivKey)
 	if err != nil {
 		return nil, fmt.Errorf("creating intermediate certificate: %w", err)
 	}
@@ -79,10 +81,10 @@ func New(rootPrivKey, intermPrivKey *ecdsa.PrivateKey) (*CA, error) {
 		NotBefore:             notBefore,
 		NotAfter:              notAfter,
 		IsCA:                  true,
-		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
 		BasicConstraintsValid: true,
 	}
-	meshCACert, meshCAPEM, err := createCert(meshCACertTemplate, meshCACertTemplate, &intermPrivKey.PublicKey, intermPrivKey)
+	meshCACert, meshCAPEM, err := createCert(meshCACertTemplate, meshCACertTemplate, intermPrivKey.Public(), intermPrivKey)
 	if err != nil {
 		return nil, fmt.Errorf("creating mesh certificate: %w", err)
 	}
@@ -103,8 +105,23 @@ func New(rootPrivKey, intermPrivKey *ecdsa.PrivateKey) (*CA, error) {
 	return &ca, nil
 }
 
+// MeshCertOptions customizes a mesh certificate. The zero value creates a certificate for client
+// and server authentication that's valid for one year.
+type MeshCertOptions struct {
+	// Lifetime is the validity period of the certificate. If zero, the certificate is valid for one
+	// year.
+	Lifetime time.Duration
+	// Subject is the subject of the certificate. If its common name is empty, the first DNS name is
+	// used as common name.
+	Subject pkix.Name
+	// EmailAddresses are added to the certificate as email SANs.
+	EmailAddresses []string
+	// ExtKeyUsage replaces the default extended key usages, client and server authentication.
+	ExtKeyUsage []x509.ExtKeyUsage
+}
+
 // NewAttestedMeshCert creates a new attested mesh certificate.
-func (c *CA) NewAttestedMeshCert(names []string, extensions []pkix.Extension, subjectPublicKey any) ([]byte, error) {
+func (c *CA) NewAttestedMeshCert(names []string, extensions []pkix.Extension, subjectPublicKey any, opts MeshCertOptions) ([]byte, error) {
 	var dnsNames []string
 	var ips []net.IP
 	var uris []*url.URL
@@ -123,15 +140,28 @@ func (c *CA) NewAttestedMeshCert(names []string, extensions []pkix.Extension, su
 	}
 
 	now := time.Now()
+	notAfter := now.AddDate(1, 0, 0)
+	if opts.Lifetime > 0 {
+		notAfter = now.Add(opts.Lifetime)
+	}
+	subject := opts.Subject
+	if subject.CommonName == "" {
+		subject.CommonName = dnsNames[0]
+	}
+	extKeyUsage := []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth}
+	if len(opts.ExtKeyUsage) > 0 {
+		extKeyUsage = opts.ExtKeyUsage
+	}
 	certTemplate := &x509.Certificate{
-		Subject:               pkix.Name{CommonName: dnsNames[0]},
+		Subject:               subject,
 		NotBefore:             now.Add(-time.Hour),
-		NotAfter:              now.AddDate(1, 0, 0),
-		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		NotAfter:              notAfter,
+		ExtKeyUsage:           extKeyUsage,
 		KeyUsage:              x509.KeyUsageDigitalSignature,
 		BasicConstraintsValid: true,
 		ExtraExtensions:       extensions,
 		DNSNames:              dnsNames,
+		EmailAddresses:        opts.EmailAddresses,
 		IPAddresses:           ips,
 		URIs:                  uris,
 	}
@@ -144,13 +174,115 @@ func (c *CA) NewAttestedMeshCert(names []string, extensions []pkix.Extension, su
 	return certPEM, nil
 }
 
+// NewSubCACert creates a CA certificate for subjectPublicKey, issued by the mesh CA.
+//
+// The sub CA can only issue leaf certificates for client and server authentication, and only for
+// the given names, which are encoded as name constraints:
+//   - A DNS name permits the name and all of its subdomains, a name "*.<domain>" only the
+//     subdomains of the domain.
+//   - An IP address permits the address, a CIDR range all addresses in the range.
+//   - A URI permits all URIs with the same host.
+//   - An email address permits the address.
+//
+// Name types that aren't given are excluded entirely. If the common name of subject is empty, a
+// default name is used.
+func (c *CA) NewSubCACert(names []string, subjectPublicKey any, subject pkix.Name, lifetime time.Duration) ([]byte, error) {
+	if len(names) == 0 {
+		return nil, errors.New("sub CA needs at least one permitted name")
+	}
+	if lifetime <= 0 {
+		return nil, errors.New("sub CA lifetime must be positive")
+	}
+	if subject.CommonName == "" {
+		subject.CommonName = "system:coordinator:sub-ca"
+	}
+
+	now := time.Now()
+	certTemplate := &x509.Certificate{
+		Subject:                     subject,
+		NotBefore:                   now.Add(-time.Hour),
+		NotAfter:                    now.Add(lifetime),
+		IsCA:                        true,
+		MaxPathLenZero:              true,
+		KeyUsage:                    x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		ExtKeyUsage:                 []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid:       true,
+		PermittedDNSDomainsCritical: true,
+	}
+	for _, name := range names {
+		if err := addNameConstraint(certTemplate, name); err != nil {
+			return nil, err
+		}
+	}
+	// An empty constraint matches all names, see crypto/x509. Excluding it for name types without
+	// permitted subtrees prevents the sub CA from issuing certificates for arbitrary names.
+	if len(certTemplate.PermittedDNSDomains) == 0 {
+		certTemplate.ExcludedDNSDomains = []string{""}
+	}
+	if len(certTemplate.PermittedIPRanges) == 0 {
+		certTemplate.ExcludedIPRanges = []*net.IPNet{
+			{IP: net.IPv4zero.To4(), Mask: net.CIDRMask(0, 32)},
+			{IP: net.IPv6zero, Mask: net.CIDRMask(0, 128)},
+		}
+	}
+	if len(certTemplate.PermittedURIDomains) == 0 {
+		certTemplate.ExcludedURIDomains = []string{""}
+	}
+	if len(certTemplate.PermittedEmailAddresses) == 0 {
+		certTemplate.ExcludedEmailAddresses = []string{""}
+	}
+
+	_, certPEM, err := createCert(certTemplate, c.meshCACert, subjectPublicKey, c.intermPrivKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+	return certPEM, nil
+}
+
+// addNameConstraint adds a permitted subtree for name to template.
+func addNameConstraint(template *x509.Certificate, name string) error {
+	if ip := net.ParseIP(name); ip != nil {
+		bits := 8 * net.IPv6len
+		if ip4 := ip.To4(); ip4 != nil {
+			ip, bits = ip4, 8*net.IPv4len
+		}
+		template.PermittedIPRanges = append(template.PermittedIPRanges, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		return nil
+	}
+	if _, ipNet, err := net.ParseCIDR(name); err == nil {
+		template.PermittedIPRanges = append(template.PermittedIPRanges, ipNet)
+		return nil
+	}
+	if uri, err := url.Parse(name); err == nil && uri.Scheme != "" {
+		if uri.Hostname() == "" {
+			return fmt.Errorf("URI %q has no host", name)
+		}
+		template.PermittedURIDomains = append(template.PermittedURIDomains, uri.Hostname())
+		return nil
+	}
+	if strings.Contains(name, "@") {
+		template.PermittedEmailAddresses = append(template.PermittedEmailAddresses, name)
+		return nil
+	}
+	domain, wildcard := strings.CutPrefix(name, "*.")
+	if domain == "" || strings.Contains(domain, "*") {
+		return fmt.Errorf("invalid DNS name constraint %q", name)
+	}
+	if wildcard {
+		// A leading period restricts the constraint to subdomains.
+		domain = "." + domain
+	}
+	template.PermittedDNSDomains = append(template.PermittedDNSDomains, domain)
+	return nil
+}
+
 // GetRootCACert returns the root certificate of the CA in PEM format.
 func (c *CA) GetRootCACert() []byte {
 	return c.rootCAPEM
 }
 
 // GetIntermCAPrivKey returns the intermediate private key of the CA.
-func (c *CA) GetIntermCAPrivKey() *ecdsa.PrivateKey {
+func (c *CA) GetIntermCAPrivKey() crypto.Signer {
 	return c.intermPrivKey
 }
 
@@ -169,6 +301,28 @@ func (c *CA) GetMeshCACertPool() *x509.CertPool {
 	return c.meshCACertPool
 }
 
+// CreateCRL creates a PEM-encoded certificate revocation list for the mesh CA.
+//
+// The CRL is signed with the mesh CA key and names the mesh CA as issuer, so it applies to all
+// certificates issued with NewAttestedMeshCert, independent of whether they're verified against
+// the mesh CA or the intermediate CA certificate.
+func (c *CA) CreateCRL(revoked []x509.RevocationListEntry, number *big.Int, nextUpdate time.Time) ([]byte, error) {
+	template := &x509.RevocationList{
+		RevokedCertificateEntries: revoked,
+		Number:                    number,
+		ThisUpdate:                time.Now().Add(-time.Minute),
+		NextUpdate:                nextUpdate,
+	}
+	crlDER, err := x509.CreateRevocationList(rand.Reader, template, c.meshCACert, c.intermPrivKey)
+	if err != nil {
+		return nil, fmt.Errorf("creating revocation list: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "X509 CRL",
+		Bytes: crlDER,
+	}), nil
+}
+
 // createCert issues a new certificate for pub, based on template, signed by parent with priv.
 //
 // It returns the certificate both in PEM encoding and as an x509 struct.
@@ -11,7 +11,9 @@ import (
 	"math/big"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/edgelesssys/contrast/internal/cryptohelpers"
 	"github.com/edgelesssys/contrast/internal/testkeys"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -47,6 +49,7 @@ func TestAttestedMeshCert(t *testing.T) {
 		dnsNames   []string
 		extensions []pkix.Extension
 		subjectPub any
+		opts       MeshCertOptions
 		wantErr    bool
 		wantIPs    int
 		wantURIs   int
@@ -68,6 +71,22 @@ func TestAttestedMeshCert(t *testing.T) {
 			subjectPub: newKey(t, 0).Public(),
 			wantURIs:   1,
 		},
+		"lifetime": {
+			dnsNames:   []string{"foo"},
+			extensions: []pkix.Extension{},
+			subjectPub: newKey(t, 0).Public(),
+			opts:       MeshCertOptions{Lifetime: time.Hour},
+		},
+		"profile": {
+			dnsNames:   []string{"foo"},
+			extensions: []pkix.Extension{},
+			subjectPub: newKey(t, 0).Public(),
+			opts: MeshCertOptions{
+				Subject:        pkix.Name{CommonName: "legacy", Organization: []string{"Example"}},
+				EmailAddresses: []string{"foo@example.com"},
+				ExtKeyUsage:    []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+			},
+		},
 	}
 
 	for name, tc := range testCases {
@@ -80,7 +99,7 @@ func TestAttestedMeshCert(t *testing.T) {
 			ca, err := New(rootCAKey, meshCAKey)
 			require.NoError(err)
 
-			pem, err := ca.NewAttestedMeshCert(tc.dnsNames, tc.extensions, tc.subjectPub)
+			pem, err := ca.NewAttestedMeshCert(tc.dnsNames, tc.extensions, tc.subjectPub, tc.opts)
 			if tc.wantErr {
 				assert.Error(err)
 				return
@@ -91,6 +110,23 @@ func TestAttestedMeshCert(t *testing.T) {
 			cert := parsePEMCertificate(t, pem)
 			assert.Len(cert.IPAddresses, tc.wantIPs)
 			assert.Len(cert.URIs, tc.wantURIs)
+			if tc.opts.Lifetime > 0 {
+				assert.WithinDuration(time.Now().Add(tc.opts.Lifetime), cert.NotAfter, time.Minute)
+			} else {
+				assert.WithinDuration(time.Now().AddDate(1, 0, 0), cert.NotAfter, time.Minute)
+			}
+			if tc.opts.Subject.CommonName != "" {
+				assert.Equal(tc.opts.Subject.CommonName, cert.Subject.CommonName)
+				assert.Equal(tc.opts.Subject.Organization, cert.Subject.Organization)
+			} else {
+				assert.Equal(tc.dnsNames[0], cert.Subject.CommonName)
+			}
+			assert.Equal(tc.opts.EmailAddresses, cert.EmailAddresses)
+			if len(tc.opts.ExtKeyUsage) > 0 {
+				assert.Equal(tc.opts.ExtKeyUsage, cert.ExtKeyUsage)
+			} else {
+				assert.ElementsMatch([]x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth}, cert.ExtKeyUsage)
+			}
 		})
 	}
 }
@@ -187,7 +223,7 @@ func TestCAConcurrent(t *testing.T) {
 	}
 	newMeshCert := func() {
 		defer wg.Done()
-		_, err := ca.NewAttestedMeshCert([]string{"foo", "bar"}, []pkix.Extension{}, newKey(t, 2).Public())
+		_, err := ca.NewAttestedMeshCert([]string{"foo", "bar"}, []pkix.Extension{}, newKey(t, 2).Public(), MeshCertOptions{})
 		assert.NoError(err)
 	}
 
@@ -227,7 +263,7 @@ func TestCertValidity(t *testing.T) {
 
 	ca, err := New(rootCAKey, meshCAKey)
 	require.NoError(err)
-	crt, err := ca.NewAttestedMeshCert([]string{"localhost"}, nil, key.Public())
+	crt, err := ca.NewAttestedMeshCert([]string{"localhost"}, nil, key.Public(), MeshCertOptions{})
 	require.NoError(err)
 
 	assertValidPEMCert(t, ca.GetRootCACert())
@@ -236,6 +272,149 @@ func TestCertValidity(t *testing.T) {
 	assertValidPEMCert(t, crt)
 }
 
+func TestCreateCRL(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	ca, err := New(newKey(t, 0), newKey(t, 1))
+	require.NoError(err)
+	crt, err := ca.NewAttestedMeshCert([]string{"localhost"}, nil, newKey(t, 2).Public(), MeshCertOptions{})
+	require.NoError(err)
+	leaf := parsePEMCertificate(t, crt)
+
+	nextUpdate := time.Now().Add(time.Hour)
+	revoked := []x509.RevocationListEntry{{SerialNumber: leaf.SerialNumber, RevocationTime: time.Now()}}
+	crlPEM, err := ca.CreateCRL(revoked, big.NewInt(3), nextUpdate)
+	require.NoError(err)
+
+	block, _ := pem.Decode(crlPEM)
+	require.NotNil(block)
+	assert.Equal("X509 CRL", block.Type)
+	crl, err := x509.ParseRevocationList(block.Bytes)
+	require.NoError(err)
+
+	assert.Equal(big.NewInt(3), crl.Number)
+	assert.WithinDuration(nextUpdate, crl.NextUpdate, time.Second)
+	require.Len(crl.RevokedCertificateEntries, 1)
+	assert.Equal(leaf.SerialNumber, crl.RevokedCertificateEntries[0].SerialNumber)
+
+	// The CRL must verify against both CA certificates that can issue the leaf.
+	assert.NoError(crl.CheckSignatureFrom(parsePEMCertificate(t, ca.GetMeshCACert())))
+	assert.NoError(crl.CheckSignatureFrom(parsePEMCertificate(t, ca.GetIntermCACert())))
+	assert.Equal(leaf.Issuer.String(), crl.Issuer.String())
+}
+
+func TestNewSubCACert(t *testing.T) {
+	testCases := map[string]struct {
+		names      []string
+		leafNames  []string
+		lifetime   time.Duration
+		wantErr    bool
+		wantLeafOK bool
+	}{
+		"dns name": {
+			names:      []string{"example.com"},
+			leafNames:  []string{"example.com", "backend.example.com"},
+			wantLeafOK: true,
+		},
+		"wildcard excludes domain": {
+			names:     []string{"*.example.com"},
+			leafNames: []string{"example.com"},
+		},
+		"wildcard permits subdomain": {
+			names:      []string{"*.example.com"},
+			leafNames:  []string{"backend.example.com"},
+			wantLeafOK: true,
+		},
+		"other dns name": {
+			names:     []string{"example.com"},
+			leafNames: []string{"example.org"},
+		},
+		"ip address": {
+			names:      []string{"example.com", "192.0.2.1"},
+			leafNames:  []string{"example.com", "192.0.2.1"},
+			wantLeafOK: true,
+		},
+		"ip range": {
+			names:      []string{"example.com", "192.0.2.0/24"},
+			leafNames:  []string{"example.com", "192.0.2.17"},
+			wantLeafOK: true,
+		},
+		"ip address not permitted": {
+			names:     []string{"example.com"},
+			leafNames: []string{"example.com", "192.0.2.1"},
+		},
+		"uri": {
+			names:      []string{"example.com", "spiffe://example.org"},
+			leafNames:  []string{"example.com", "spiffe://example.org/ns/default/sa/web"},
+			wantLeafOK: true,
+		},
+		"uri not permitted": {
+			names:     []string{"example.com"},
+			leafNames: []string{"example.com", "spiffe://example.org/ns/default/sa/web"},
+		},
+		"no names": {
+			wantErr: true,
+		},
+		"wildcard": {
+			names:   []string{"*"},
+			wantErr: true,
+		},
+		"negative lifetime": {
+			names:    []string{"example.com"},
+			lifetime: -time.Hour,
+			wantErr:  true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+
+			ca, err := New(newKey(t, 0), newKey(t, 1))
+			require.NoError(err)
+			subCAKey := newKey(t, 2)
+			lifetime := tc.lifetime
+			if lifetime == 0 {
+				lifetime = time.Hour
+			}
+
+			subCAPEM, err := ca.NewSubCACert(tc.names, subCAKey.Public(), pkix.Name{}, lifetime)
+			if tc.wantErr {
+				require.Error(err)
+				return
+			}
+			require.NoError(err)
+			assertValidPEMCert(t, subCAPEM)
+			subCACert := parsePEMCertificate(t, subCAPEM)
+			assert.True(subCACert.IsCA)
+			assert.True(subCACert.MaxPathLenZero)
+			assert.WithinDuration(time.Now().Add(lifetime), subCACert.NotAfter, time.Minute)
+
+			// The sub CA chains to both the mesh CA and the root CA.
+			_, err = subCACert.Verify(x509.VerifyOptions{Roots: pool(t, ca.GetMeshCACert())})
+			require.NoError(err)
+			_, err = subCACert.Verify(x509.VerifyOptions{Roots: pool(t, ca.GetRootCACert()), Intermediates: pool(t, ca.GetIntermCACert())})
+			require.NoError(err)
+
+			subCA := &CA{meshCACert: subCACert, intermPrivKey: subCAKey}
+			leafPEM, err := subCA.NewAttestedMeshCert(tc.leafNames, nil, newKey(t, 0).Public(), MeshCertOptions{})
+			require.NoError(err)
+			leaf := parsePEMCertificate(t, leafPEM)
+			_, err = leaf.Verify(x509.VerifyOptions{
+				Roots:         pool(t, ca.GetMeshCACert()),
+				Intermediates: pool(t, subCAPEM),
+			})
+			if tc.wantLeafOK {
+				assert.NoError(err)
+			} else {
+				assert.Error(err)
+			}
+		})
+	}
+}
+
 func assertValidPEMCert(t *testing.T, pem []byte) {
 	crt := parsePEMCertificate(t, pem)
 	if crt.IsCA {
@@ -260,9 +439,9 @@ func TestCARecovery(t *testing.T) {
 	require.NoError(err)
 
 	key := newKey(t, 2)
-	oldCert, err := oldCA.NewAttestedMeshCert([]string{"localhost"}, nil, key.Public())
+	oldCert, err := oldCA.NewAttestedMeshCert([]string{"localhost"}, nil, key.Public(), MeshCertOptions{})
 	require.NoError(err)
-	newCert, err := newCA.NewAttestedMeshCert([]string{"localhost"}, nil, key.Public())
+	newCert, err := newCA.NewAttestedMeshCert([]string{"localhost"}, nil, key.Public(), MeshCertOptions{})
 	require.NoError(err)
 
 	require.NotEqual(oldCA.GetRootCACert(), newCA.GetRootCACert())
@@ -296,6 +475,38 @@ func TestCARecovery(t *testing.T) {
 	}
 }
 
+func TestKeyAlgorithms(t *testing.T) {
+	for _, alg := range cryptohelpers.KeyAlgorithms() {
+		t.Run(string(alg), func(t *testing.T) {
+			require := require.New(t)
+
+			rootCAKey, err := alg.GenerateKey()
+			require.NoError(err)
+			meshCAKey, err := alg.GenerateKey()
+			require.NoError(err)
+
+			ca, err := New(rootCAKey, meshCAKey)
+			require.NoError(err)
+
+			certPEM, err := ca.NewAttestedMeshCert([]string{"foo"}, nil, newKey(t, 0).Public(), MeshCertOptions{})
+			require.NoError(err)
+			cert := parsePEMCertificate(t, certPEM)
+			_, err = cert.Verify(x509.VerifyOptions{Roots: pool(t, ca.GetMeshCACert())})
+			require.NoError(err)
+			_, err = cert.Verify(x509.VerifyOptions{Roots: pool(t, ca.GetRootCACert()), Intermediates: pool(t, ca.GetIntermCACert())})
+			require.NoError(err)
+
+			crlPEM, err := ca.CreateCRL(nil, big.NewInt(1), time.Now().Add(time.Hour))
+			require.NoError(err)
+			block, _ := pem.Decode(crlPEM)
+			require.NotNil(block)
+			crl, err := x509.ParseRevocationList(block.Bytes)
+			require.NoError(err)
+			require.NoError(crl.CheckSignatureFrom(parsePEMCertificate(t, ca.GetMeshCACert())))
+		})
+	}
+}
+
 func pool(t *testing.T, pem []byte) *x509.CertPool {
 	pool := x509.NewCertPool()
 	require.True(t, pool.AppendCertsFromPEM(pem))
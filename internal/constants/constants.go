@@ -70,4 +70,24 @@ const (
 	// AMD KDS and Intel PCS attestation-collateral fetches through that in-cluster caching
 	// proxy. It is read by the coordinator and the initializer. empty means fetch directly.
 	CollateralProxyEnvVar = "CONTRAST_COLLATERAL_PROXY"
+
+	// MeshCertRenewalEnvVar is the environment variable that signals to the initializer that it
+	// should keep running and renew the mesh certificate before it expires.
+	MeshCertRenewalEnvVar = "CONTRAST_MESH_CERT_RENEWAL"
+
+	// KeyAlgorithmEnvVar is the environment variable that sets the algorithm of the workload key
+	// the initializer creates. It's set by contrast generate from the manifest.
+	KeyAlgorithmEnvVar = "CONTRAST_KEY_ALGORITHM"
+
+	// SPIFFEWorkloadAPIEnvVar is the environment variable that signals to the initializer that it
+	// should keep running and serve the SPIFFE Workload API.
+	SPIFFEWorkloadAPIEnvVar = "CONTRAST_SPIFFE_WORKLOAD_API"
+
+	// SPIFFEEndpointSocketEnvVar is the environment variable SPIFFE clients read the address of
+	// the Workload API from.
+	SPIFFEEndpointSocketEnvVar = "SPIFFE_ENDPOINT_SOCKET"
+
+	// SPIFFEWorkloadAPISocketPath is the path of the Unix socket the initializer serves the SPIFFE
+	// Workload API on. It's on the volume that's shared by all containers of the pod.
+	SPIFFEWorkloadAPISocketPath = "/contrast/spiffe/agent.sock"
 )
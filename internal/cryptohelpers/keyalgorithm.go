@@ -0,0 +1,129 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package cryptohelpers
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// KeyAlgorithm is an asymmetric key algorithm for CA and workload keys.
+type KeyAlgorithm string
+
+const (
+	// KeyAlgorithmECDSAP256 is ECDSA on the NIST P-256 curve.
+	KeyAlgorithmECDSAP256 KeyAlgorithm = "ECDSA-P256"
+	// KeyAlgorithmECDSAP384 is ECDSA on the NIST P-384 curve.
+	KeyAlgorithmECDSAP384 KeyAlgorithm = "ECDSA-P384"
+	// KeyAlgorithmECDSAP521 is ECDSA on the NIST P-521 curve.
+	KeyAlgorithmECDSAP521 KeyAlgorithm = "ECDSA-P521"
+	// KeyAlgorithmRSA2048 is RSA with a 2048-bit modulus.
+	KeyAlgorithmRSA2048 KeyAlgorithm = "RSA-2048"
+	// KeyAlgorithmRSA3072 is RSA with a 3072-bit modulus.
+	KeyAlgorithmRSA3072 KeyAlgorithm = "RSA-3072"
+	// KeyAlgorithmRSA4096 is RSA with a 4096-bit modulus.
+	KeyAlgorithmRSA4096 KeyAlgorithm = "RSA-4096"
+	// KeyAlgorithmEd25519 is EdDSA on Curve25519.
+	KeyAlgorithmEd25519 KeyAlgorithm = "Ed25519"
+)
+
+// KeyAlgorithms returns all supported key algorithms.
+func KeyAlgorithms() []KeyAlgorithm {
+	return []KeyAlgorithm{
+		KeyAlgorithmECDSAP256,
+		KeyAlgorithmECDSAP384,
+		KeyAlgorithmECDSAP521,
+		KeyAlgorithmRSA2048,
+		KeyAlgorithmRSA3072,
+		KeyAlgorithmRSA4096,
+		KeyAlgorithmEd25519,
+	}
+}
+
+// Validate checks that the key algorithm is supported.
+func (a KeyAlgorithm) Validate() error {
+	if !slices.Contains(KeyAlgorithms(), a) {
+		return fmt.Errorf("unsupported key algorithm %q, supported are %v", a, KeyAlgorithms())
+	}
+	return nil
+}
+
+// Curve returns the elliptic curve of an ECDSA algorithm, or nil for other algorithms.
+func (a KeyAlgorithm) Curve() elliptic.Curve {
+	switch a {
+	case KeyAlgorithmECDSAP256:
+		return elliptic.P256()
+	case KeyAlgorithmECDSAP384:
+		return elliptic.P384()
+	case KeyAlgorithmECDSAP521:
+		return elliptic.P521()
+	default:
+		return nil
+	}
+}
+
+// RSABits returns the modulus size of an RSA algorithm, or zero for other algorithms.
+func (a KeyAlgorithm) RSABits() int {
+	switch a {
+	case KeyAlgorithmRSA2048:
+		return 2048
+	case KeyAlgorithmRSA3072:
+		return 3072
+	case KeyAlgorithmRSA4096:
+		return 4096
+	default:
+		return 0
+	}
+}
+
+// GenerateKey generates a random key of the algorithm.
+func (a KeyAlgorithm) GenerateKey() (crypto.Signer, error) {
+	if curve := a.Curve(); curve != nil {
+		return ecdsa.GenerateKey(curve, rand.Reader)
+	}
+	if bits := a.RSABits(); bits > 0 {
+		return rsa.GenerateKey(rand.Reader, bits)
+	}
+	if a == KeyAlgorithmEd25519 {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	}
+	return nil, a.Validate()
+}
+
+// SignDigest signs a SHA-256 digest with key. Ed25519 keys sign the digest as message.
+func SignDigest(key crypto.Signer, digest []byte) ([]byte, error) {
+	var opts crypto.SignerOpts = crypto.SHA256
+	if _, ok := key.Public().(ed25519.PublicKey); ok {
+		opts = crypto.Hash(0)
+	}
+	return key.Sign(rand.Reader, digest, opts)
+}
+
+// VerifyDigest verifies a signature created with SignDigest.
+func VerifyDigest(pub crypto.PublicKey, digest, signature []byte) error {
+	switch pub := pub.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(pub, digest, signature) {
+			return errors.New("invalid ECDSA signature")
+		}
+		return nil
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest, signature)
+	case ed25519.PublicKey:
+		if !ed25519.Verify(pub, digest, signature) {
+			return errors.New("invalid Ed25519 signature")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package cryptohelpers
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyAlgorithm(t *testing.T) {
+	for _, alg := range KeyAlgorithms() {
+		t.Run(string(alg), func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+
+			require.NoError(alg.Validate())
+			key, err := alg.GenerateKey()
+			require.NoError(err)
+
+			digest := sha256.Sum256([]byte("message"))
+			signature, err := SignDigest(key, digest[:])
+			require.NoError(err)
+			assert.NoError(VerifyDigest(key.Public(), digest[:], signature))
+
+			otherDigest := sha256.Sum256([]byte("other message"))
+			assert.Error(VerifyDigest(key.Public(), otherDigest[:], signature))
+		})
+	}
+}
+
+func TestKeyAlgorithmValidate(t *testing.T) {
+	testCases := map[string]struct {
+		alg     KeyAlgorithm
+		wantErr bool
+	}{
+		"ECDSA":          {alg: KeyAlgorithmECDSAP384},
+		"RSA":            {alg: KeyAlgorithmRSA3072},
+		"Ed25519":        {alg: KeyAlgorithmEd25519},
+		"empty":          {alg: "", wantErr: true},
+		"unknown":        {alg: "ML-DSA-65", wantErr: true},
+		"case sensitive": {alg: "ecdsa-p384", wantErr: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.alg.Validate()
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
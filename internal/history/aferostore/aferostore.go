@@ -86,6 +86,19 @@ func (s *AferoStore) CompareAndSwap(key string, oldVal, newVal []byte) error {
 	return s.fs.WriteFile(key, newVal, 0o644)
 }
 
+// Delete removes key from the store.
+func (s *AferoStore) Delete(key string) error {
+	if !keyRe.MatchString(key) {
+		return fmt.Errorf("invalid key %q", key)
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if err := s.fs.Remove(key); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
 // Watch watches for changes to the value of key.
 //
 // Not implemented for AferoStore.
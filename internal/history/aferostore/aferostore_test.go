@@ -100,4 +100,20 @@ func suite(t *testing.T, storeFactory func(t *testing.T) history.Store) {
 		require.NoError(err)
 		require.Equal(val2, z)
 	})
+
+	t.Run("Delete", func(t *testing.T) {
+		require := require.New(t)
+		s := storeFactory(t)
+
+		key := "foo/bar"
+
+		require.ErrorContains(s.Delete("invalid-key"), "invalid key")
+		require.NoError(s.Delete(key), "deleting a missing key must succeed")
+
+		require.NoError(s.Set(key, []byte("val")))
+		require.NoError(s.Delete(key))
+
+		_, err := s.Get(key)
+		require.ErrorIs(err, os.ErrNotExist)
+	})
 }
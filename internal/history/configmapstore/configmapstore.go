@@ -9,6 +9,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"log/slog"
+	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -59,7 +60,9 @@ func (s *ConfigMapStore) Get(key string) ([]byte, error) {
 	defer cancel()
 
 	cm, err := s.client.CoreV1().ConfigMaps(s.namespace).Get(ctx, cmName, metav1.GetOptions{})
-	if err != nil {
+	if errors.IsNotFound(err) {
+		return nil, fmt.Errorf("%w: %w", os.ErrNotExist, err)
+	} else if err != nil {
 		return nil, err
 	}
 	return cm.BinaryData[filepath.Base(key)], nil
@@ -135,6 +138,22 @@ func (s *ConfigMapStore) CompareAndSwap(key string, oldVal, newVal []byte) error
 	return err
 }
 
+// Delete removes key from the store.
+func (s *ConfigMapStore) Delete(key string) error {
+	cmName, err := objectName(key)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err = s.client.CoreV1().ConfigMaps(s.namespace).Delete(ctx, cmName, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
 // Watch watches for changes to the value of key.
 func (s *ConfigMapStore) Watch(key string) (<-chan []byte, func(), error) {
 	cmName, err := objectName(key)
@@ -187,7 +206,10 @@ func (s *ConfigMapStore) Watch(key string) (<-chan []byte, func(), error) {
 
 // RecoverConfigMaps reconstructs all config maps needed for recovering the Coordinator state
 // from the given manifests, policies, and latest transition information.
-func RecoverConfigMaps(manifests [][]byte, policies [][]byte, latestTransitionHash []byte, latestTransitionSignature []byte) ([]any, error) {
+//
+// If the history was pruned, checkpoint must be the checkpoint the oldest manifest follows, and nil
+// otherwise.
+func RecoverConfigMaps(manifests [][]byte, policies [][]byte, latestTransitionHash []byte, latestTransitionSignature []byte, checkpoint *history.Checkpoint) ([]any, error) {
 	var hist []any
 	appendCm := func(pathFmt string, hash [history.HashSize]byte, content []byte) error {
 		hashStr := hex.EncodeToString(hash[:])
@@ -209,7 +231,16 @@ func RecoverConfigMaps(manifests [][]byte, policies [][]byte, latestTransitionHa
 			return nil, fmt.Errorf("creating config map for policy: %w", err)
 		}
 	}
-	transitions := history.BuildTransitionChain(manifests)
+	var previousTransitionHash [history.HashSize]byte
+	if checkpoint != nil {
+		previousTransitionHash = checkpoint.TransitionHash
+		cmName, err := objectName("transitions/checkpoint")
+		if err != nil {
+			return nil, fmt.Errorf("creating config map for checkpoint: %w", err)
+		}
+		hist = append(hist, newEntry("", cmName, "checkpoint", checkpoint.MarshalBinary()))
+	}
+	transitions := history.BuildTransitionChainFrom(previousTransitionHash, manifests)
 	for _, t := range transitions {
 		if err := appendCm("transitions/%s", t.Digest(), t.MarshalBinary()); err != nil {
 			return nil, fmt.Errorf("creating config map for transition: %w", err)
@@ -5,9 +5,11 @@ package configmapstore
 
 import (
 	"log/slog"
+	"os"
 	"regexp"
 	"testing"
 
+	"github.com/edgelesssys/contrast/internal/history"
 	"github.com/edgelesssys/contrast/internal/kuberesource"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
@@ -29,6 +31,7 @@ func TestGetSet(t *testing.T) {
 
 	x, err := s.Get(key)
 	require.True(errors.IsNotFound(err))
+	require.ErrorIs(err, os.ErrNotExist)
 	require.Empty(x)
 
 	require.NoError(s.Set(key, val1))
@@ -61,6 +64,23 @@ func TestHas(t *testing.T) {
 	require.True(s.Has(key))
 }
 
+func TestDelete(t *testing.T) {
+	require := require.New(t)
+
+	s := New(fake.NewClientset(), "test", slog.Default())
+
+	key := "foo/bar"
+
+	require.ErrorContains(s.Delete("invalid-key"), "invalid key")
+	require.NoError(s.Delete(key), "deleting a missing key must succeed")
+
+	require.NoError(s.Set(key, []byte("val")))
+	require.NoError(s.Delete(key))
+
+	_, err := s.Get(key)
+	require.ErrorIs(err, os.ErrNotExist)
+}
+
 func TestCompareAndSwap(t *testing.T) {
 	require := require.New(t)
 
@@ -159,12 +179,26 @@ func TestRecoverConfigMaps(t *testing.T) {
 	manifests := [][]byte{{}}
 	policies := [][]byte{{}}
 
-	cms, err := RecoverConfigMaps(manifests, policies, make([]byte, 32), make([]byte, 64))
+	cms, err := RecoverConfigMaps(manifests, policies, make([]byte, 32), make([]byte, 64), nil)
 	require.NoError(err)
 	// A policy, a manifest, a transition and a latest transition.
 	require.Len(cms, 4)
 
-	configmapNamesRE := regexp.MustCompile("^contrast-store-((policies|manifests|transitions)-[0-9a-f]{64}|transitions-latest)$")
+	checkpoint := &history.Checkpoint{TransitionHash: [history.HashSize]byte{1}, Generation: 3, Signature: make([]byte, 64)}
+	prunedCMs, err := RecoverConfigMaps(manifests, policies, make([]byte, 32), make([]byte, 64), checkpoint)
+	require.NoError(err)
+	// The same objects as above, and a checkpoint.
+	require.Len(prunedCMs, 5)
+	transitionCM, ok := prunedCMs[3].(*corev1.ConfigMap)
+	require.True(ok)
+	for _, transitionBytes := range transitionCM.BinaryData {
+		var transition history.Transition
+		require.NoError(transition.UnmarshalBinary(transitionBytes))
+		require.Equal(checkpoint.TransitionHash, transition.PreviousTransitionHash)
+	}
+	cms = append(cms, prunedCMs[2])
+
+	configmapNamesRE := regexp.MustCompile("^contrast-store-((policies|manifests|transitions)-[0-9a-f]{64}|transitions-latest|transitions-checkpoint)$")
 
 	for _, a := range cms {
 		cm, ok := a.(*corev1.ConfigMap)
@@ -0,0 +1,449 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+// Package crdstore implements a history.Store backed by ContrastHistory custom resources.
+//
+// Every key is stored in its own ContrastHistory object. Values that exceed the chunk size are
+// split into chunk objects, which are referenced by the object of the key and named after the key
+// and their content. Chunks are immutable and written before the referencing object, so that
+// readers never observe partially written values. Chunks that are no longer referenced after a
+// value was replaced are deleted. Concurrent modifications are detected through the
+// resourceVersion of the key's object.
+package crdstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/edgelesssys/contrast/internal/kuberesource"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+)
+
+// timeout is the timeout for Kubernetes API calls.
+const timeout = 10 * time.Second
+
+// chunkSize is the maximum number of value bytes stored in a single object. Together with the
+// base64 encoding overhead, this stays well below the etcd request size limit of 1.5MiB.
+const chunkSize = 512 * 1024
+
+const (
+	appName      = "kvstore"
+	appComponent = "coordinator"
+)
+
+// ContrastHistory is the custom resource holding a single key or chunk of the history.
+type ContrastHistory struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ContrastHistorySpec `json:"spec"`
+}
+
+// ContrastHistorySpec is the spec of a ContrastHistory resource.
+type ContrastHistorySpec struct {
+	// Key is the history key stored in this object. It's empty for chunk objects.
+	Key string `json:"key,omitempty"`
+	// Data is the value, if it fits into a single object, or the content of a chunk.
+	Data []byte `json:"data,omitempty"`
+	// Chunks are the names of the chunk objects holding the value, in order.
+	Chunks []string `json:"chunks,omitempty"`
+}
+
+// CRDStore is a Store implementation backed by ContrastHistory custom resources.
+type CRDStore struct {
+	client    dynamic.NamespaceableResourceInterface
+	namespace string
+	logger    *slog.Logger
+}
+
+// New creates a new [CRDStore] instance with the given dynamic Kubernetes client.
+func New(client dynamic.Interface, namespace string, log *slog.Logger) *CRDStore {
+	return &CRDStore{
+		client:    client.Resource(kuberesource.ContrastHistoryGVR),
+		namespace: namespace,
+		logger:    log,
+	}
+}
+
+// Get the value for key.
+func (s *CRDStore) Get(key string) ([]byte, error) {
+	name, err := objectName(key)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	obj, err := s.get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return s.value(ctx, obj)
+}
+
+// Has returns true if the key exists.
+func (s *CRDStore) Has(key string) (bool, error) {
+	name, err := objectName(key)
+	if err != nil {
+		return false, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, err = s.get(ctx, name)
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Set the value for key.
+func (s *CRDStore) Set(key string, value []byte) error {
+	name, err := objectName(key)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	spec, _, err := s.writeChunks(ctx, key, value)
+	if err != nil {
+		return err
+	}
+	obj, err := s.get(ctx, name)
+	if errors.IsNotFound(err) {
+		return s.create(ctx, newEntry(s.namespace, name, spec))
+	} else if err != nil {
+		return err
+	}
+	oldChunks := obj.Spec.Chunks
+	obj.Spec = spec
+	if err := s.update(ctx, obj); err != nil {
+		return err
+	}
+	s.deleteStaleChunks(ctx, key, oldChunks, spec.Chunks)
+	return nil
+}
+
+// CompareAndSwap updates the key to newVal if its current value is oldVal.
+//
+// The update is conditional on the resourceVersion of the object read for the comparison, so
+// concurrent modifications between the comparison and the update are detected. Chunks written for
+// newVal are deleted again if the update fails.
+func (s *CRDStore) CompareAndSwap(key string, oldVal, newVal []byte) error {
+	name, err := objectName(key)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// Treat non-existing object as empty to allow initial set.
+	obj, err := s.get(ctx, name)
+	if err != nil && (!errors.IsNotFound(err) || len(oldVal) != 0) {
+		return err
+	}
+	if obj != nil {
+		current, err := s.value(ctx, obj)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(current, oldVal) {
+			return fmt.Errorf("object %q has changed since last read", key)
+		}
+	}
+
+	spec, created, err := s.writeChunks(ctx, key, newVal)
+	if err != nil {
+		return err
+	}
+	if obj == nil {
+		err = s.create(ctx, newEntry(s.namespace, name, spec))
+	} else {
+		oldChunks := obj.Spec.Chunks
+		obj.Spec = spec
+		if err = s.update(ctx, obj); err == nil {
+			s.deleteStaleChunks(ctx, key, oldChunks, spec.Chunks)
+		}
+	}
+	if err != nil {
+		s.deleteUnusedChunks(ctx, key, name, created)
+	}
+	if errors.IsAlreadyExists(err) || errors.IsConflict(err) {
+		return fmt.Errorf("object %q has changed since last read: %w", key, err)
+	}
+	return err
+}
+
+// Delete removes key and its chunks from the store.
+func (s *CRDStore) Delete(key string) error {
+	name, err := objectName(key)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	obj, err := s.get(ctx, name)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := s.delete(ctx, name); err != nil {
+		return err
+	}
+	for _, chunk := range obj.Spec.Chunks {
+		if err := s.delete(ctx, chunk); err != nil {
+			return fmt.Errorf("deleting chunk %q: %w", chunk, err)
+		}
+	}
+	return nil
+}
+
+// Watch watches for changes to the value of key.
+func (s *CRDStore) Watch(key string) (<-chan []byte, func(), error) {
+	name, err := objectName(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	watcher, err := s.client.Namespace(s.namespace).Watch(context.Background(), metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", name).String(),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan []byte)
+
+	go func() {
+		defer watcher.Stop()
+		defer close(result)
+
+		s.logger.Debug("watch started", "key", key)
+		for {
+			select {
+			case <-ctx.Done():
+				s.logger.Debug("watch canceled", "key", key)
+				return
+			case event, ok := <-watcher.ResultChan():
+				if !ok {
+					s.logger.Debug("watch channel closed", "key", key)
+					return
+				}
+				switch event.Type {
+				case watch.Error:
+					s.logger.Warn("watch error", "key", key, "error", event.Object)
+					return
+				case watch.Added, watch.Modified:
+					u, ok := event.Object.(*unstructured.Unstructured)
+					if !ok {
+						s.logger.Error("unexpected object type", "key", key, "object", event.Object)
+						continue
+					}
+					obj, err := fromUnstructured(u)
+					if err != nil {
+						s.logger.Error("decoding object", "key", key, "error", err)
+						continue
+					}
+					getCtx, getCancel := context.WithTimeout(ctx, timeout)
+					value, err := s.value(getCtx, obj)
+					getCancel()
+					if err != nil {
+						s.logger.Error("reading value", "key", key, "error", err)
+						continue
+					}
+					select {
+					case result <- value:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	return result, cancel, nil
+}
+
+// writeChunks returns the spec for storing value under key, creating chunk objects if necessary.
+//
+// Besides the spec, it returns the names of the chunks that didn't exist before. If writing a chunk
+// fails, the chunks created so far are deleted again.
+func (s *CRDStore) writeChunks(ctx context.Context, key string, value []byte) (ContrastHistorySpec, []string, error) {
+	if len(value) <= chunkSize {
+		return ContrastHistorySpec{Key: key, Data: value}, nil, nil
+	}
+	spec := ContrastHistorySpec{Key: key}
+	var created []string
+	for chunk := range slices.Chunk(value, chunkSize) {
+		name := chunkName(key, chunk)
+		err := s.create(ctx, newEntry(s.namespace, name, ContrastHistorySpec{Data: chunk}))
+		if err == nil {
+			created = append(created, name)
+		} else if !errors.IsAlreadyExists(err) {
+			s.deleteStaleChunks(ctx, key, created, nil)
+			return ContrastHistorySpec{}, nil, fmt.Errorf("creating chunk %q: %w", name, err)
+		}
+		spec.Chunks = append(spec.Chunks, name)
+	}
+	return spec, created, nil
+}
+
+// deleteStaleChunks deletes the chunks of a replaced value that aren't part of the new value.
+//
+// The new value is already stored, so failures are only logged: a stale chunk wastes space, but
+// isn't referenced anymore.
+func (s *CRDStore) deleteStaleChunks(ctx context.Context, key string, oldChunks, newChunks []string) {
+	for _, chunk := range oldChunks {
+		if slices.Contains(newChunks, chunk) {
+			continue
+		}
+		if err := s.delete(ctx, chunk); err != nil {
+			s.logger.Warn("deleting stale chunk", "key", key, "chunk", chunk, "error", err)
+		}
+	}
+}
+
+// deleteUnusedChunks deletes chunks written for a failed update of the object with the given name.
+//
+// Chunk names are derived from their content, so a concurrent update that stored the same data
+// might reference them. Chunks that the object references after the failed update are kept.
+func (s *CRDStore) deleteUnusedChunks(ctx context.Context, key, name string, chunks []string) {
+	if len(chunks) == 0 {
+		return
+	}
+	var inUse []string
+	obj, err := s.get(ctx, name)
+	if err == nil {
+		inUse = obj.Spec.Chunks
+	} else if !errors.IsNotFound(err) {
+		s.logger.Warn("getting object to delete unused chunks", "key", key, "error", err)
+		return
+	}
+	s.deleteStaleChunks(ctx, key, chunks, inUse)
+}
+
+// value reassembles the value stored in the given object.
+func (s *CRDStore) value(ctx context.Context, obj *ContrastHistory) ([]byte, error) {
+	if len(obj.Spec.Chunks) == 0 {
+		return obj.Spec.Data, nil
+	}
+	var value []byte
+	for _, name := range obj.Spec.Chunks {
+		chunk, err := s.get(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("getting chunk %q: %w", name, err)
+		}
+		if chunkName(obj.Spec.Key, chunk.Spec.Data) != name {
+			return nil, fmt.Errorf("chunk %q has been modified", name)
+		}
+		value = append(value, chunk.Spec.Data...)
+	}
+	return value, nil
+}
+
+func (s *CRDStore) get(ctx context.Context, name string) (*ContrastHistory, error) {
+	u, err := s.client.Namespace(s.namespace).Get(ctx, name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, fmt.Errorf("%w: %w", os.ErrNotExist, err)
+	} else if err != nil {
+		return nil, err
+	}
+	return fromUnstructured(u)
+}
+
+func (s *CRDStore) create(ctx context.Context, obj *ContrastHistory) error {
+	u, err := toUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.Namespace(s.namespace).Create(ctx, u, metav1.CreateOptions{})
+	return err
+}
+
+func (s *CRDStore) update(ctx context.Context, obj *ContrastHistory) error {
+	u, err := toUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.Namespace(s.namespace).Update(ctx, u, metav1.UpdateOptions{})
+	return err
+}
+
+func (s *CRDStore) delete(ctx context.Context, name string) error {
+	err := s.client.Namespace(s.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func newEntry(namespace, name string, spec ContrastHistorySpec) *ContrastHistory {
+	labels := kuberesource.ContrastLabels(appName, appComponent)
+	labels[kuberesource.KubernetesAppManagedByLabel] = "contrast.edgeless.systems"
+	return &ContrastHistory{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       kuberesource.ContrastHistoryKind,
+			APIVersion: kuberesource.ContrastHistoryGVR.GroupVersion().String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: spec,
+	}
+}
+
+func toUnstructured(obj *ContrastHistory) (*unstructured.Unstructured, error) {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, fmt.Errorf("converting to unstructured: %w", err)
+	}
+	return &unstructured.Unstructured{Object: u}, nil
+}
+
+func fromUnstructured(u *unstructured.Unstructured) (*ContrastHistory, error) {
+	var obj ContrastHistory
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+		return nil, fmt.Errorf("converting from unstructured: %w", err)
+	}
+	return &obj, nil
+}
+
+var keyRe = regexp.MustCompile(`^[a-zA-Z0-9-]+/[a-zA-Z0-9-]+$`)
+
+func objectName(key string) (string, error) {
+	if !keyRe.MatchString(key) {
+		return "", fmt.Errorf("invalid key %q", key)
+	}
+	return fmt.Sprintf("contrast-history-%s", strings.ReplaceAll(key, "/", "-")), nil
+}
+
+// chunkName returns the name of a chunk object, which is derived from the key the chunk belongs to
+// and its content. Chunks are thus never shared between keys and can be deleted with their key.
+// Chunk names contain a dot, so they can't collide with object names derived from keys.
+func chunkName(key string, chunk []byte) string {
+	h := sha256.New()
+	_, _ = h.Write([]byte(key))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write(chunk)
+	return "contrast-history-chunk." + hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,295 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package crdstore
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/edgelesssys/contrast/internal/kuberesource"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newFakeClient() *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		kuberesource.ContrastHistoryGVR: kuberesource.ContrastHistoryKind + "List",
+	})
+}
+
+func TestGetSet(t *testing.T) {
+	require := require.New(t)
+
+	s := New(newFakeClient(), "test", slog.Default())
+
+	key := "foo/bar"
+	val1 := []byte("val1")
+	val2 := []byte("val2")
+
+	_, err := s.Get("invalid-key")
+	require.ErrorContains(err, "invalid key")
+
+	x, err := s.Get(key)
+	require.True(errors.IsNotFound(err))
+	require.ErrorIs(err, os.ErrNotExist)
+	require.Empty(x)
+
+	require.NoError(s.Set(key, val1))
+
+	y, err := s.Get(key)
+	require.NoError(err)
+	require.Equal(val1, y)
+
+	require.ErrorContains(s.Set("invalid-key", nil), "invalid key")
+
+	require.NoError(s.Set(key, val2))
+
+	z, err := s.Get(key)
+	require.NoError(err)
+	require.Equal(val2, z)
+}
+
+func TestChunks(t *testing.T) {
+	require := require.New(t)
+
+	client := newFakeClient()
+	s := New(client, "test", slog.Default())
+
+	key := "foo/bar"
+	large := make([]byte, 3*chunkSize+16)
+	for i := range large {
+		// Use a period that is coprime to the chunk size, so that no two chunks are equal.
+		large[i] = byte(i % 251)
+	}
+
+	require.NoError(s.Set(key, large))
+
+	got, err := s.Get(key)
+	require.NoError(err)
+	require.Equal(large, got)
+
+	list, err := client.Resource(kuberesource.ContrastHistoryGVR).Namespace("test").List(t.Context(), metav1.ListOptions{})
+	require.NoError(err)
+	require.Len(list.Items, 5) // 4 chunks + 1 object for the key.
+
+	// Chunks are content-addressed, so storing the same value again doesn't create new objects.
+	require.NoError(s.Set(key, large))
+	list, err = client.Resource(kuberesource.ContrastHistoryGVR).Namespace("test").List(t.Context(), metav1.ListOptions{})
+	require.NoError(err)
+	require.Len(list.Items, 5)
+
+	// Chunks aren't shared between keys, so they can be deleted with the key.
+	require.NoError(s.Set("foo/baz", large))
+	require.NoError(s.Delete("foo/baz"))
+	list, err = client.Resource(kuberesource.ContrastHistoryGVR).Namespace("test").List(t.Context(), metav1.ListOptions{})
+	require.NoError(err)
+	require.Len(list.Items, 5)
+
+	// Replacing a chunked value only keeps the chunks that are part of the new value.
+	changed := append(large[:3*chunkSize:3*chunkSize], 1, 2, 3)
+	require.NoError(s.Set(key, changed))
+	got, err = s.Get(key)
+	require.NoError(err)
+	require.Equal(changed, got)
+	list, err = client.Resource(kuberesource.ContrastHistoryGVR).Namespace("test").List(t.Context(), metav1.ListOptions{})
+	require.NoError(err)
+	require.Len(list.Items, 5)
+
+	// Replacing a chunked value with a small one must not use the old chunks, and deletes them.
+	require.NoError(s.CompareAndSwap(key, changed, []byte("small")))
+	got, err = s.Get(key)
+	require.NoError(err)
+	require.Equal([]byte("small"), got)
+	list, err = client.Resource(kuberesource.ContrastHistoryGVR).Namespace("test").List(t.Context(), metav1.ListOptions{})
+	require.NoError(err)
+	require.Len(list.Items, 1)
+}
+
+func TestHas(t *testing.T) {
+	require := require.New(t)
+
+	s := New(newFakeClient(), "test", slog.Default())
+
+	key := "foo/bar"
+	val := []byte("val")
+
+	require.False(s.Has(key))
+
+	require.NoError(s.Set(key, val))
+
+	require.True(s.Has(key))
+}
+
+func TestDelete(t *testing.T) {
+	require := require.New(t)
+
+	s := New(newFakeClient(), "test", slog.Default())
+
+	key := "foo/bar"
+
+	require.ErrorContains(s.Delete("invalid-key"), "invalid key")
+	require.NoError(s.Delete(key), "deleting a missing key must succeed")
+
+	require.NoError(s.Set(key, []byte("val")))
+	require.NoError(s.Delete(key))
+
+	_, err := s.Get(key)
+	require.ErrorIs(err, os.ErrNotExist)
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	require := require.New(t)
+
+	s := New(newFakeClient(), "test", slog.Default())
+
+	key := "foo/bar"
+	val1 := []byte("val1")
+	val2 := []byte("val2")
+
+	x, err := s.Get(key)
+	require.True(errors.IsNotFound(err))
+	require.Empty(x)
+
+	require.ErrorContains(s.CompareAndSwap("invalid-key", nil, nil), "invalid key")
+	require.Error(s.CompareAndSwap(key, val1, val2))
+
+	require.NoError(s.CompareAndSwap(key, nil, val1))
+	require.ErrorContains(s.CompareAndSwap(key, nil, val1), "has changed since last read")
+
+	y, err := s.Get(key)
+	require.NoError(err)
+	require.Equal(val1, y)
+
+	require.ErrorContains(s.CompareAndSwap(key, val2, val1), "has changed since last read")
+	require.NoError(s.CompareAndSwap(key, val1, val2))
+
+	z, err := s.Get(key)
+	require.NoError(err)
+	require.Equal(val2, z)
+}
+
+func TestCompareAndSwapConflict(t *testing.T) {
+	require := require.New(t)
+
+	client := newFakeClient()
+	s := New(client, "test", slog.Default())
+
+	key := "foo/bar"
+	val1 := []byte("val1")
+	val2 := []byte("val2")
+	require.NoError(s.Set(key, val1))
+
+	// Simulate a concurrent update between read and write, which the API server detects via the
+	// resourceVersion.
+	client.PrependReactor("update", kuberesource.ContrastHistoryGVR.Resource, func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewConflict(kuberesource.ContrastHistoryGVR.GroupResource(), "contrast-history-foo-bar", nil)
+	})
+
+	require.ErrorContains(s.CompareAndSwap(key, val1, val2), "has changed since last read")
+}
+
+func TestCompareAndSwapChunkCleanup(t *testing.T) {
+	require := require.New(t)
+
+	client := newFakeClient()
+	s := New(client, "test", slog.Default())
+
+	key := "foo/bar"
+	val1 := []byte("val1")
+	large := make([]byte, 2*chunkSize+16)
+	for i := range large {
+		large[i] = byte(i % 251)
+	}
+	require.NoError(s.Set(key, val1))
+	countObjects := func() int {
+		list, err := client.Resource(kuberesource.ContrastHistoryGVR).Namespace("test").List(t.Context(), metav1.ListOptions{})
+		require.NoError(err)
+		return len(list.Items)
+	}
+
+	// A failed comparison doesn't write any chunks.
+	require.ErrorContains(s.CompareAndSwap(key, []byte("other"), large), "has changed since last read")
+	require.Equal(1, countObjects())
+
+	// The chunks of a conflicting update are deleted again.
+	client.PrependReactor("update", kuberesource.ContrastHistoryGVR.Resource, func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewConflict(kuberesource.ContrastHistoryGVR.GroupResource(), "contrast-history-foo-bar", nil)
+	})
+	require.ErrorContains(s.CompareAndSwap(key, val1, large), "has changed since last read")
+	require.Equal(1, countObjects())
+	got, err := s.Get(key)
+	require.NoError(err)
+	require.Equal(val1, got)
+}
+
+func TestWatch(t *testing.T) {
+	require := require.New(t)
+
+	s := New(newFakeClient(), "test", slog.Default())
+
+	key := "foo/bar"
+	val1 := []byte("val1")
+	val2 := []byte("val2")
+
+	_, _, err := s.Watch("invalid-key")
+	require.ErrorContains(err, "invalid key")
+
+	ch, cancel, err := s.Watch(key)
+	require.NoError(err)
+	defer cancel()
+
+	require.NoError(s.Set(key, val1))
+	require.Equal(val1, <-ch)
+	require.NoError(s.Set(key, val2))
+	require.Equal(val2, <-ch)
+}
+
+func TestObjectName(t *testing.T) {
+	testCases := map[string]struct {
+		key      string
+		wantName string
+		wantErr  bool
+	}{
+		"transition": {
+			key:      "transitions/latest",
+			wantName: "contrast-history-transitions-latest",
+		},
+		"manifest": {
+			key:      "manifests/2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+			wantName: "contrast-history-manifests-2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		},
+		"no separator": {
+			key:     "transitions",
+			wantErr: true,
+		},
+		"too many separators": {
+			key:     "a/b/c",
+			wantErr: true,
+		},
+		"dot": {
+			key:     "chunk/a.b",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			got, err := objectName(tc.key)
+			if tc.wantErr {
+				require.Error(err)
+				return
+			}
+			require.NoError(err)
+			require.Equal(tc.wantName, got)
+		})
+	}
+}
@@ -10,10 +10,13 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"hash"
 	"log/slog"
+	"os"
+	"strings"
 	"sync"
 )
 
@@ -85,6 +88,21 @@ func (h *History) SetTransition(transition *Transition) ([HashSize]byte, error)
 	return h.setContentaddressed("transitions/%s", transition.MarshalBinary())
 }
 
+// GetTransitionSignature returns the workload owner signature that authorized the given transition.
+//
+// If the transition was not authorized by a detached signature, an error wrapping os.ErrNotExist is
+// returned.
+func (h *History) GetTransitionSignature(transitionHash [HashSize]byte) ([]byte, error) {
+	return h.store.Get(fmt.Sprintf("signatures/%s", hex.EncodeToString(transitionHash[:])))
+}
+
+// SetTransitionSignature stores the workload owner signature that authorized the given transition.
+//
+// The signature is not verified and must be treated as untrusted by readers.
+func (h *History) SetTransitionSignature(transitionHash [HashSize]byte, signature []byte) error {
+	return h.store.Set(fmt.Sprintf("signatures/%s", hex.EncodeToString(transitionHash[:])), signature)
+}
+
 // GetLatest verifies the latest transition with the given public key and returns it.
 func (h *History) GetLatest(pubKey *ecdsa.PublicKey) (*LatestTransition, error) {
 	latestTransition, err := h.GetLatestInsecure()
@@ -170,8 +188,24 @@ func (h *History) WatchLatestTransitions(ctx context.Context) (<-chan LatestTran
 // WalkTransitions executes a function for the referenced transition and all its ancestors.
 //
 // The all-zero transition is the root node of all transition trees and is not passed to the closure.
-func (h *History) WalkTransitions(transitionHash [HashSize]byte, consume func([HashSize]byte, *Transition) error) error {
-	for transitionHash != [HashSize]byte{} {
+// If the history was pruned, the walk ends at the checkpoint, and the pruned transitions are not
+// passed to the closure either. The checkpoint needs to be signed by pubKey, so that a forged
+// checkpoint can't hide transitions from the walk.
+func (h *History) WalkTransitions(pubKey *ecdsa.PublicKey, transitionHash [HashSize]byte, consume func([HashSize]byte, *Transition) error) error {
+	var boundary [HashSize]byte
+	pruned, err := h.store.Has(checkpointKey)
+	if err != nil {
+		return fmt.Errorf("checking for checkpoint: %w", err)
+	}
+	if pruned {
+		checkpoint, err := h.GetCheckpoint(pubKey)
+		if err != nil {
+			return err
+		}
+		boundary = checkpoint.TransitionHash
+	}
+
+	for transitionHash != [HashSize]byte{} && transitionHash != boundary {
 		transition, err := h.GetTransition(transitionHash)
 		if err != nil {
 			return fmt.Errorf("getting transition %x: %w", transitionHash, err)
@@ -233,6 +267,12 @@ func (h *History) addToCache(hash [HashSize]byte, data []byte) {
 	h.cache[hash] = cloned
 }
 
+func (h *History) removeFromCache(hash [HashSize]byte) {
+	h.cacheMu.Lock()
+	defer h.cacheMu.Unlock()
+	delete(h.cache, hash)
+}
+
 // Digest calculates the Digest of a given slice in the same way as used for (Latest)Transitions..
 func Digest(in []byte) [HashSize]byte {
 	hf := hashFun()
@@ -357,14 +397,161 @@ type Store interface {
 	//
 	// If the value of key changes, the new value is sent on the channel.
 	Watch(key string) (ch <-chan []byte, cancel func(), err error)
+
+	// Delete removes key from the store.
+	//
+	// Deleting a key that does not exist is not an error.
+	Delete(key string) error
+}
+
+// Migrate copies the history reachable from the latest transition in src to dst.
+//
+// The migration is skipped if dst already has a latest transition or src has none, and the
+// returned bool reports whether a migration took place. The latest transition is copied last, so
+// an interrupted migration can safely be retried.
+func Migrate(src, dst Store) (bool, error) {
+	if has, err := dst.Has("transitions/latest"); err != nil {
+		return false, fmt.Errorf("checking destination for latest transition: %w", err)
+	} else if has {
+		return false, nil
+	}
+	if has, err := src.Has("transitions/latest"); err != nil {
+		return false, fmt.Errorf("checking source for latest transition: %w", err)
+	} else if !has {
+		return false, nil
+	}
+
+	latestBytes, err := src.Get("transitions/latest")
+	if err != nil {
+		return false, fmt.Errorf("getting latest transition: %w", err)
+	}
+	var latest LatestTransition
+	if err := latest.UnmarshalBinary(latestBytes); err != nil {
+		return false, fmt.Errorf("unmarshaling latest transition: %w", err)
+	}
+
+	copyKey := func(key string) ([]byte, error) {
+		value, err := src.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		if err := dst.Set(key, value); err != nil {
+			return nil, fmt.Errorf("setting %q: %w", key, err)
+		}
+		return value, nil
+	}
+
+	// Pruned transitions are not copied, the walk ends at the checkpoint.
+	var boundary [HashSize]byte
+	checkpointBytes, err := copyKey(checkpointKey)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, fmt.Errorf("copying checkpoint: %w", err)
+	} else if err == nil {
+		var checkpoint Checkpoint
+		if err := checkpoint.UnmarshalBinary(checkpointBytes); err != nil {
+			return false, fmt.Errorf("unmarshaling checkpoint: %w", err)
+		}
+		boundary = checkpoint.TransitionHash
+	}
+
+	// copyTransition copies a transition with its signature, manifest and policies.
+	copyTransition := func(transitionHash [HashSize]byte) (*Transition, error) {
+		hashStr := hex.EncodeToString(transitionHash[:])
+		transitionBytes, err := copyKey("transitions/" + hashStr)
+		if err != nil {
+			return nil, fmt.Errorf("copying transition %s: %w", hashStr, err)
+		}
+		var transition Transition
+		if err := transition.UnmarshalBinary(transitionBytes); err != nil {
+			return nil, fmt.Errorf("unmarshaling transition %s: %w", hashStr, err)
+		}
+		if _, err := copyKey("signatures/" + hashStr); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("copying signature of transition %s: %w", hashStr, err)
+		}
+
+		manifestHashStr := hex.EncodeToString(transition.ManifestHash[:])
+		manifestBytes, err := copyKey("manifests/" + manifestHashStr)
+		if err != nil {
+			return nil, fmt.Errorf("copying manifest %s: %w", manifestHashStr, err)
+		}
+		policyHashStrs, err := policyReferences(manifestBytes)
+		if err != nil {
+			return nil, fmt.Errorf("manifest %s: %w", manifestHashStr, err)
+		}
+		for _, policyHashStr := range policyHashStrs {
+			if _, err := copyKey("policies/" + policyHashStr); err != nil {
+				return nil, fmt.Errorf("copying policy %s: %w", policyHashStr, err)
+			}
+		}
+		return &transition, nil
+	}
+
+	transitionHash := latest.TransitionHash
+	for transitionHash != [HashSize]byte{} && transitionHash != boundary {
+		transition, err := copyTransition(transitionHash)
+		if err != nil {
+			return false, err
+		}
+		transitionHash = transition.PreviousTransitionHash
+	}
+
+	// The pending transition isn't reachable from the latest transition, so it's copied separately.
+	pendingBytes, err := src.Get(pendingKey)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, fmt.Errorf("getting pending transition: %w", err)
+	} else if len(pendingBytes) > 0 {
+		var pending PendingTransition
+		if err := pending.UnmarshalBinary(pendingBytes); err != nil {
+			return false, fmt.Errorf("unmarshaling pending transition: %w", err)
+		}
+		if _, err := copyTransition(pending.TransitionHash); err != nil {
+			return false, fmt.Errorf("pending transition: %w", err)
+		}
+		if err := dst.Set(pendingKey, pendingBytes); err != nil {
+			return false, fmt.Errorf("setting pending transition: %w", err)
+		}
+	}
+
+	if err := dst.CompareAndSwap("transitions/latest", nil, latestBytes); err != nil {
+		// Another Coordinator might have completed the migration concurrently.
+		if has, hasErr := dst.Has("transitions/latest"); hasErr == nil && has {
+			return false, nil
+		}
+		return false, fmt.Errorf("setting latest transition: %w", err)
+	}
+	return true, nil
+}
+
+// policyReferences returns the lower-case hex encoded hashes of the policies referenced by a
+// manifest.
+func policyReferences(manifestBytes []byte) ([]string, error) {
+	// Only the policy references are needed here, so we don't need to depend on the manifest package.
+	var mnfst struct {
+		Policies map[string]json.RawMessage
+	}
+	if err := json.Unmarshal(manifestBytes, &mnfst); err != nil {
+		return nil, fmt.Errorf("unmarshaling manifest: %w", err)
+	}
+	var refs []string
+	for policyHashStr := range mnfst.Policies {
+		refs = append(refs, strings.ToLower(policyHashStr))
+	}
+	return refs, nil
 }
 
 // BuildTransitionChain builds a chain of transitions from the given manifests,
 // where each transition corresponds to one manifest and includes the hash of the previous transition.
 // Manifests are expected to be ordered from oldest to newest. The returned slice is ordered from oldest to newest as well.
 func BuildTransitionChain(manifests [][]byte) []*Transition {
+	return BuildTransitionChainFrom([HashSize]byte{}, manifests)
+}
+
+// BuildTransitionChainFrom works like BuildTransitionChain, but the oldest transition refers to the
+// given previous transition hash instead of the all-zero root. This is needed to rebuild the chain
+// of a pruned history, where previousTransitionHash is the transition hash of the checkpoint.
+func BuildTransitionChainFrom(previousTransitionHash [HashSize]byte, manifests [][]byte) []*Transition {
 	transitions := make([]*Transition, 0, len(manifests))
-	lastTransitionHash := [HashSize]byte{}
+	lastTransitionHash := previousTransitionHash
 	for _, m := range manifests {
 		md := Digest(m)
 		t := &Transition{
@@ -605,6 +605,26 @@ func TestHistory_SetGet(t *testing.T) {
 	}
 }
 
+func TestHistory_TransitionSignature(t *testing.T) {
+	require := require.New(t)
+
+	fs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	h := NewWithStore(slog.New(slog.DiscardHandler), aferostore.New(fs))
+	transitionHash := strToHash(require, "7305db9b2abccd706c256db3d97e5ff48d677cfe4d3a5904afb7da0e3950e1e2")
+
+	_, err := h.GetTransitionSignature(transitionHash)
+	require.ErrorIs(err, os.ErrNotExist)
+
+	require.NoError(h.SetTransitionSignature(transitionHash, []byte("signature")))
+	content, err := fs.ReadFile("signatures/7305db9b2abccd706c256db3d97e5ff48d677cfe4d3a5904afb7da0e3950e1e2")
+	require.NoError(err)
+	require.Equal("signature", string(content))
+
+	signature, err := h.GetTransitionSignature(transitionHash)
+	require.NoError(err)
+	require.Equal([]byte("signature"), signature)
+}
+
 func TestHistory_WatchLatestTransitions(t *testing.T) {
 	require := require.New(t)
 	store := &fakeStore{
@@ -659,8 +679,8 @@ func TestHistory_WalkTransitions(t *testing.T) {
 			require.Fail("closure should not be called without any transitions")
 			return nil
 		}
-		require.NoError(h.WalkTransitions([HashSize]byte{0}, doNotCall), "all-zero transition should not fail")
-		require.Error(h.WalkTransitions([HashSize]byte{123}, doNotCall), "unknown transition should fail")
+		require.NoError(h.WalkTransitions(nil, [HashSize]byte{0}, doNotCall), "all-zero transition should not fail")
+		require.Error(h.WalkTransitions(nil, [HashSize]byte{123}, doNotCall), "unknown transition should fail")
 	})
 
 	t.Run("walk transitions", func(t *testing.T) {
@@ -696,7 +716,7 @@ func TestHistory_WalkTransitions(t *testing.T) {
 		}
 
 		closureCallCount := 0
-		require.NoError(h.WalkTransitions(latestTransition, func(_ [32]byte, _ *Transition) error {
+		require.NoError(h.WalkTransitions(nil, latestTransition, func(_ [32]byte, _ *Transition) error {
 			closureCallCount++
 			return nil
 		}))
@@ -718,13 +738,82 @@ func TestHistory_WalkTransitions(t *testing.T) {
 		latestTransition, err := h.SetTransition(transition)
 		require.NoError(err)
 
-		err = h.WalkTransitions(latestTransition, func([32]byte, *Transition) error {
+		err = h.WalkTransitions(nil, latestTransition, func([32]byte, *Transition) error {
 			return assert.AnError
 		})
 		require.ErrorIs(err, assert.AnError)
 	})
 }
 
+func TestMigrate(t *testing.T) {
+	require := require.New(t)
+
+	srcFS := &afero.Afero{Fs: afero.NewMemMapFs()}
+	src := NewWithStore(slog.New(slog.DiscardHandler), aferostore.New(srcFS))
+	signingKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP256Keys[0])
+
+	// Populate the source with two generations, one of them signed, and an unreachable policy.
+	policy1Hash, err := src.SetPolicy([]byte("policy1"))
+	require.NoError(err)
+	policy2Hash, err := src.SetPolicy([]byte("policy2"))
+	require.NoError(err)
+	_, err = src.SetPolicy([]byte("unreferenced"))
+	require.NoError(err)
+
+	var latest *LatestTransition
+	var transitionHash [HashSize]byte
+	for i, policyHash := range [][HashSize]byte{policy1Hash, policy2Hash} {
+		manifestHash, err := src.SetManifest(fmt.Appendf(nil, `{"Policies":{%q:{}}}`, hex.EncodeToString(policyHash[:])))
+		require.NoError(err)
+		transitionHash, err = src.SetTransition(&Transition{ManifestHash: manifestHash, PreviousTransitionHash: transitionHash})
+		require.NoError(err)
+		if i == 1 {
+			require.NoError(src.SetTransitionSignature(transitionHash, []byte("signature")))
+		}
+		next := &LatestTransition{TransitionHash: transitionHash}
+		require.NoError(src.SetLatest(latest, next, signingKey))
+		latest = next
+	}
+
+	dstFS := &afero.Afero{Fs: afero.NewMemMapFs()}
+	migrated, err := Migrate(aferostore.New(srcFS), aferostore.New(dstFS))
+	require.NoError(err)
+	require.True(migrated)
+
+	dst := NewWithStore(slog.New(slog.DiscardHandler), aferostore.New(dstFS))
+	gotLatest, err := dst.GetLatest(&signingKey.PublicKey)
+	require.NoError(err)
+	require.Equal(transitionHash, gotLatest.TransitionHash)
+	generations := 0
+	require.NoError(dst.WalkTransitions(&signingKey.PublicKey, gotLatest.TransitionHash, func(_ [HashSize]byte, t *Transition) error {
+		generations++
+		_, err := dst.GetManifest(t.ManifestHash)
+		return err
+	}))
+	require.Equal(2, generations)
+	for _, policyHash := range [][HashSize]byte{policy1Hash, policy2Hash} {
+		_, err := dst.GetPolicy(policyHash)
+		require.NoError(err)
+	}
+	signature, err := dst.GetTransitionSignature(transitionHash)
+	require.NoError(err)
+	require.Equal([]byte("signature"), signature)
+	policies, err := dstFS.ReadDir("policies")
+	require.NoError(err)
+	require.Len(policies, 2, "unreferenced policy must not be migrated")
+
+	// A second migration is a no-op.
+	migrated, err = Migrate(aferostore.New(srcFS), aferostore.New(dstFS))
+	require.NoError(err)
+	require.False(migrated)
+
+	// Migrating from an empty store is a no-op, too.
+	emptyFS := &afero.Afero{Fs: afero.NewMemMapFs()}
+	migrated, err = Migrate(aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()}), aferostore.New(emptyFS))
+	require.NoError(err)
+	require.False(migrated)
+}
+
 func TestHistoryCache(t *testing.T) {
 	require := require.New(t)
 
@@ -760,7 +849,7 @@ func TestHistoryCache(t *testing.T) {
 	require.Equal(0, store.getCount)
 
 	var visited [][HashSize]byte
-	require.NoError(h.WalkTransitions(transition2Hash, func(b [32]byte, _ *Transition) error {
+	require.NoError(h.WalkTransitions(nil, transition2Hash, func(b [32]byte, _ *Transition) error {
 		visited = append(visited, b)
 		return nil
 	}))
@@ -0,0 +1,122 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package history
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// pendingKey is the store key of the pending transition. There is at most one pending transition,
+// and an empty value means that no transition is pending.
+const pendingKey = "transitions/pending"
+
+// pendingSignatureDomain is prepended to the pending transition content before signing, so that
+// the signature can't be mistaken for a latest transition or checkpoint signature.
+const pendingSignatureDomain = "contrast-history-pending"
+
+// PendingTransition is a transition that was scheduled to become the latest transition at a
+// later time.
+//
+// The transition itself is stored with the other transitions, but it's only reachable from the
+// pending transition until it's activated.
+type PendingTransition struct {
+	TransitionHash [HashSize]byte
+	// ActivationTime is the time at which the transition becomes active, with a resolution of
+	// seconds.
+	ActivationTime time.Time
+	// Signature is the signature of the Coordinator over the pending transition.
+	Signature []byte
+}
+
+// UnmarshalBinary unmarshals the binary representation of the PendingTransition into the struct.
+func (p *PendingTransition) UnmarshalBinary(data []byte) error {
+	if len(data) <= HashSize+8 {
+		return errors.New("pending transition has invalid length")
+	}
+	copy(p.TransitionHash[:], data[:HashSize])
+	p.ActivationTime = time.Unix(int64(binary.BigEndian.Uint64(data[HashSize:HashSize+8])), 0).UTC()
+	p.Signature = make([]byte, len(data)-HashSize-8)
+	copy(p.Signature, data[HashSize+8:])
+	return nil
+}
+
+// MarshalBinary returns the binary representation of the PendingTransition.
+func (p *PendingTransition) MarshalBinary() []byte {
+	if p == nil {
+		return []byte{}
+	}
+	return append(p.content(), p.Signature...)
+}
+
+func (p *PendingTransition) content() []byte {
+	data := make([]byte, HashSize+8)
+	copy(data[:HashSize], p.TransitionHash[:])
+	binary.BigEndian.PutUint64(data[HashSize:], uint64(p.ActivationTime.Unix()))
+	return data
+}
+
+func (p *PendingTransition) digest() [HashSize]byte {
+	return Digest(append([]byte(pendingSignatureDomain), p.content()...))
+}
+
+func (p *PendingTransition) sign(key *ecdsa.PrivateKey) error {
+	digest := p.digest()
+	var err error
+	p.Signature, err = ecdsa.SignASN1(rand.Reader, key, digest[:])
+	return err
+}
+
+func (p *PendingTransition) verify(key *ecdsa.PublicKey) error {
+	digest := p.digest()
+	if !ecdsa.VerifyASN1(key, digest[:], p.Signature) {
+		return errors.New("pending transition signature is invalid")
+	}
+	return nil
+}
+
+// GetPending verifies the pending transition with the given public key and returns it.
+//
+// If no transition is pending, an error wrapping os.ErrNotExist is returned.
+func (h *History) GetPending(pubKey *ecdsa.PublicKey) (*PendingTransition, error) {
+	pendingBytes, err := h.store.Get(pendingKey)
+	if err != nil {
+		return nil, fmt.Errorf("getting pending transition: %w", err)
+	}
+	if len(pendingBytes) == 0 {
+		return nil, fmt.Errorf("getting pending transition: %w", os.ErrNotExist)
+	}
+	var pending PendingTransition
+	if err := pending.UnmarshalBinary(pendingBytes); err != nil {
+		return nil, fmt.Errorf("unmarshaling pending transition: %w", err)
+	}
+	if err := pending.verify(pubKey); err != nil {
+		return nil, fmt.Errorf("verifying pending transition: %w", err)
+	}
+	return &pending, nil
+}
+
+// SetPending replaces the pending transition oldP with newP, which is signed with signingKey.
+//
+// Both oldP and newP may be nil, which stands for no pending transition. Passing a nil newP thus
+// removes the pending transition. If the pending transition is not oldP anymore, an error is
+// returned.
+func (h *History) SetPending(oldP, newP *PendingTransition, signingKey *ecdsa.PrivateKey) error {
+	if newP != nil {
+		if err := newP.sign(signingKey); err != nil {
+			return fmt.Errorf("signing pending transition: %w", err)
+		}
+	}
+	// Stores treat missing keys like empty values, so this also works if there never was a
+	// pending transition.
+	if err := h.store.CompareAndSwap(pendingKey, oldP.MarshalBinary(), newP.MarshalBinary()); err != nil {
+		return fmt.Errorf("setting pending transition: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package history
+
+import (
+	"crypto/ecdsa"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/edgelesssys/contrast/internal/history/aferostore"
+	"github.com/edgelesssys/contrast/internal/testkeys"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPending(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	h := newPrunableHistory(t)
+	pubKey := &h.signingKey.PublicKey
+
+	_, err := h.GetPending(pubKey)
+	require.ErrorIs(err, os.ErrNotExist)
+
+	activationTime := time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC)
+	pending := &PendingTransition{TransitionHash: [HashSize]byte{1}, ActivationTime: activationTime}
+	require.NoError(h.SetPending(nil, pending, h.signingKey))
+	require.Error(h.SetPending(nil, pending, h.signingKey), "a pending transition must not be replaced unknowingly")
+
+	got, err := h.GetPending(pubKey)
+	require.NoError(err)
+	assert.Equal(pending.TransitionHash, got.TransitionHash)
+	assert.True(activationTime.Equal(got.ActivationTime))
+
+	replacement := &PendingTransition{TransitionHash: [HashSize]byte{2}, ActivationTime: activationTime.Add(time.Hour)}
+	require.NoError(h.SetPending(got, replacement, h.signingKey))
+	require.Error(h.SetPending(got, nil, h.signingKey), "a replaced pending transition must not be cancelled")
+
+	require.NoError(h.SetPending(replacement, nil, h.signingKey))
+	_, err = h.GetPending(pubKey)
+	require.ErrorIs(err, os.ErrNotExist)
+
+	// A pending transition that wasn't signed by the Coordinator must be rejected.
+	otherKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[0])
+	require.NoError(h.SetPending(nil, pending, otherKey))
+	_, err = h.GetPending(pubKey)
+	require.Error(err)
+	require.NotErrorIs(err, os.ErrNotExist)
+}
+
+func TestPendingTransition_Marshal(t *testing.T) {
+	require := require.New(t)
+	signingKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP256Keys[0])
+
+	pending := &PendingTransition{TransitionHash: [HashSize]byte{1, 2, 3}, ActivationTime: time.Unix(1792206000, 0).UTC()}
+	require.NoError(pending.sign(signingKey))
+	require.NoError(pending.verify(&signingKey.PublicKey))
+
+	var unmarshaled PendingTransition
+	require.NoError(unmarshaled.UnmarshalBinary(pending.MarshalBinary()))
+	require.Equal(*pending, unmarshaled)
+	require.Error(unmarshaled.UnmarshalBinary(make([]byte, HashSize+8)))
+
+	// A pending transition signature must not be usable as checkpoint signature.
+	checkpoint := &Checkpoint{TransitionHash: pending.TransitionHash, Generation: uint64(pending.ActivationTime.Unix()), Signature: pending.Signature}
+	require.Error(checkpoint.verify(&signingKey.PublicKey))
+}
+
+func TestPrune_Pending(t *testing.T) {
+	require := require.New(t)
+
+	h := newPrunableHistory(t)
+	h.appendManifest(require, "policy1")
+	h.appendManifest(require, "policy2")
+
+	// Schedule a return to the first manifest.
+	transitionHash, err := h.SetTransition(&Transition{ManifestHash: h.manifests[0], PreviousTransitionHash: h.transitions[1]})
+	require.NoError(err)
+	require.NoError(h.SetPending(nil, &PendingTransition{TransitionHash: transitionHash, ActivationTime: time.Now()}, h.signingKey))
+
+	pruned, err := h.Prune(h.latest.TransitionHash, 1, h.signingKey)
+	require.NoError(err)
+	require.Equal(1, pruned)
+	require.False(h.exists("transitions", h.transitions[0]))
+	require.True(h.exists("manifests", h.manifests[0]), "manifest is referenced by the pending transition")
+	require.True(h.exists("policies", Digest([]byte("policy1"))))
+}
+
+func TestMigrate_Pending(t *testing.T) {
+	require := require.New(t)
+
+	h := newPrunableHistory(t)
+	h.appendManifest(require, "policy1")
+	manifestHash, err := h.SetManifest([]byte(`{"Policies":{}}`))
+	require.NoError(err)
+	transitionHash, err := h.SetTransition(&Transition{ManifestHash: manifestHash, PreviousTransitionHash: h.transitions[0]})
+	require.NoError(err)
+	require.NoError(h.SetPending(nil, &PendingTransition{TransitionHash: transitionHash, ActivationTime: time.Now()}, h.signingKey))
+
+	dstFS := &afero.Afero{Fs: afero.NewMemMapFs()}
+	migrated, err := Migrate(h.store, aferostore.New(dstFS))
+	require.NoError(err)
+	require.True(migrated)
+
+	dst := NewWithStore(slog.New(slog.DiscardHandler), aferostore.New(dstFS))
+	pending, err := dst.GetPending(&h.signingKey.PublicKey)
+	require.NoError(err)
+	require.Equal(transitionHash, pending.TransitionHash)
+	transition, err := dst.GetTransition(transitionHash)
+	require.NoError(err)
+	_, err = dst.GetManifest(transition.ManifestHash)
+	require.NoError(err)
+}
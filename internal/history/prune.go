@@ -0,0 +1,292 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package history
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// checkpointKey is the store key of the checkpoint. There is at most one checkpoint, which is
+// replaced whenever the history is pruned further.
+const checkpointKey = "transitions/checkpoint"
+
+// checkpointSignatureDomain is prepended to the checkpoint content before signing. The key that
+// signs checkpoints also signs latest transitions, and a checkpoint signature must never be valid
+// for a latest transition.
+const checkpointSignatureDomain = "contrast-history-checkpoint"
+
+// Checkpoint summarizes the prefix of the transition chain that was removed by pruning.
+//
+// It takes the place of the most recent pruned transition: the oldest transition that's still in
+// the history refers to the checkpoint's transition hash as its predecessor.
+type Checkpoint struct {
+	// TransitionHash is the hash of the most recent pruned transition.
+	TransitionHash [HashSize]byte
+	// Generation is the generation of the most recent pruned transition, which is the total number
+	// of pruned transitions.
+	Generation uint64
+	// Signature is the signature of the Coordinator over the checkpoint.
+	Signature []byte
+}
+
+// UnmarshalBinary unmarshals the binary representation of the Checkpoint into the struct.
+func (c *Checkpoint) UnmarshalBinary(data []byte) error {
+	if len(data) <= HashSize+8 {
+		return errors.New("checkpoint has invalid length")
+	}
+	copy(c.TransitionHash[:], data[:HashSize])
+	c.Generation = binary.BigEndian.Uint64(data[HashSize : HashSize+8])
+	c.Signature = make([]byte, len(data)-HashSize-8)
+	copy(c.Signature, data[HashSize+8:])
+	return nil
+}
+
+// MarshalBinary returns the binary representation of the Checkpoint.
+func (c *Checkpoint) MarshalBinary() []byte {
+	if c == nil {
+		return []byte{}
+	}
+	return append(c.content(), c.Signature...)
+}
+
+func (c *Checkpoint) content() []byte {
+	data := make([]byte, HashSize+8)
+	copy(data[:HashSize], c.TransitionHash[:])
+	binary.BigEndian.PutUint64(data[HashSize:], c.Generation)
+	return data
+}
+
+func (c *Checkpoint) digest() [HashSize]byte {
+	return Digest(append([]byte(checkpointSignatureDomain), c.content()...))
+}
+
+func (c *Checkpoint) sign(key *ecdsa.PrivateKey) error {
+	digest := c.digest()
+	var err error
+	c.Signature, err = ecdsa.SignASN1(rand.Reader, key, digest[:])
+	return err
+}
+
+func (c *Checkpoint) verify(key *ecdsa.PublicKey) error {
+	digest := c.digest()
+	if !ecdsa.VerifyASN1(key, digest[:], c.Signature) {
+		return errors.New("checkpoint signature is invalid")
+	}
+	return nil
+}
+
+// GetCheckpoint verifies the checkpoint with the given public key and returns it.
+//
+// If the history was never pruned, an error wrapping os.ErrNotExist is returned.
+func (h *History) GetCheckpoint(pubKey *ecdsa.PublicKey) (*Checkpoint, error) {
+	checkpoint, err := h.GetCheckpointInsecure()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkpoint.verify(pubKey); err != nil {
+		return nil, fmt.Errorf("verifying checkpoint: %w", err)
+	}
+	return checkpoint, nil
+}
+
+// GetCheckpointInsecure returns the checkpoint without verifying it.
+func (h *History) GetCheckpointInsecure() (*Checkpoint, error) {
+	checkpointBytes, err := h.store.Get(checkpointKey)
+	if err != nil {
+		return nil, fmt.Errorf("getting checkpoint: %w", err)
+	}
+	var checkpoint Checkpoint
+	if err := checkpoint.UnmarshalBinary(checkpointBytes); err != nil {
+		return nil, fmt.Errorf("unmarshaling checkpoint: %w", err)
+	}
+	return &checkpoint, nil
+}
+
+// Prune removes all but the keep most recent transitions leading up to latestTransitionHash.
+//
+// Manifests, policies and signatures are removed with the transitions, unless they're referenced
+// by one of the remaining transitions. The removed transitions are summarized by a checkpoint
+// signed with signingKey, which must be the key that signs the latest transition.
+//
+// The checkpoint is written before anything is deleted, so concurrent readers never observe a
+// broken transition chain. If pruning is interrupted, the next call to Prune finishes the
+// cleanup. Prune returns the number of transitions that were newly pruned.
+func (h *History) Prune(latestTransitionHash [HashSize]byte, keep int, signingKey *ecdsa.PrivateKey) (int, error) {
+	if keep < 1 {
+		return 0, fmt.Errorf("at least one transition must be kept, got %d", keep)
+	}
+
+	oldCheckpoint, err := h.GetCheckpoint(&signingKey.PublicKey)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, err
+	}
+
+	type chainEntry struct {
+		hash       [HashSize]byte
+		transition *Transition
+	}
+	var chain []chainEntry
+	if err := h.WalkTransitions(&signingKey.PublicKey, latestTransitionHash, func(hash [HashSize]byte, t *Transition) error {
+		chain = append(chain, chainEntry{hash: hash, transition: t})
+		return nil
+	}); err != nil {
+		return 0, fmt.Errorf("walking transitions: %w", err)
+	}
+
+	retained := &references{
+		manifests: make(map[[HashSize]byte]struct{}),
+		policies:  make(map[string]struct{}),
+	}
+	retainedManifests := make([][HashSize]byte, 0, keep+1)
+	for _, entry := range chain[:min(keep, len(chain))] {
+		retainedManifests = append(retainedManifests, entry.transition.ManifestHash)
+	}
+	// The manifest of a pending transition might have been used before, and must be kept as well.
+	pending, err := h.GetPending(&signingKey.PublicKey)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, err
+	} else if err == nil {
+		pendingTransition, err := h.GetTransition(pending.TransitionHash)
+		if err != nil {
+			return 0, fmt.Errorf("getting pending transition %x: %w", pending.TransitionHash, err)
+		}
+		retainedManifests = append(retainedManifests, pendingTransition.ManifestHash)
+	}
+	for _, manifestHash := range retainedManifests {
+		manifestBytes, err := h.GetManifest(manifestHash)
+		if err != nil {
+			return 0, fmt.Errorf("getting manifest %x: %w", manifestHash, err)
+		}
+		policyHashStrs, err := policyReferences(manifestBytes)
+		if err != nil {
+			return 0, fmt.Errorf("manifest %x: %w", manifestHash, err)
+		}
+		retained.manifests[manifestHash] = struct{}{}
+		for _, policyHashStr := range policyHashStrs {
+			retained.policies[policyHashStr] = struct{}{}
+		}
+	}
+
+	// Finish an interrupted cleanup first, because the transitions behind the old checkpoint are
+	// not reachable from the new one.
+	if oldCheckpoint != nil {
+		if err := h.deletePrefix(oldCheckpoint.TransitionHash, retained); err != nil {
+			return 0, fmt.Errorf("cleaning up after previous checkpoint: %w", err)
+		}
+	}
+	if len(chain) <= keep {
+		return 0, nil
+	}
+
+	pruned := chain[keep:]
+	checkpoint := &Checkpoint{
+		TransitionHash: pruned[0].hash,
+		Generation:     uint64(len(pruned)),
+	}
+	if oldCheckpoint != nil {
+		checkpoint.Generation += oldCheckpoint.Generation
+	}
+	if err := checkpoint.sign(signingKey); err != nil {
+		return 0, fmt.Errorf("signing checkpoint: %w", err)
+	}
+	if err := h.store.CompareAndSwap(checkpointKey, oldCheckpoint.MarshalBinary(), checkpoint.MarshalBinary()); err != nil {
+		return 0, fmt.Errorf("setting checkpoint: %w", err)
+	}
+	h.log.Info("Pruned history", "transitions", len(pruned), "checkpoint", hex.EncodeToString(checkpoint.TransitionHash[:]))
+
+	if err := h.deletePrefix(checkpoint.TransitionHash, retained); err != nil {
+		return len(pruned), fmt.Errorf("cleaning up pruned transitions: %w", err)
+	}
+	return len(pruned), nil
+}
+
+// references is a set of manifests and policies that must not be deleted.
+type references struct {
+	manifests map[[HashSize]byte]struct{}
+	policies  map[string]struct{}
+}
+
+// deletePrefix deletes the referenced transition and all its ancestors that are still present.
+//
+// Transitions are deleted oldest first, so the remaining transitions are always reachable from
+// transitionHash if the deletion is interrupted.
+func (h *History) deletePrefix(transitionHash [HashSize]byte, retained *references) error {
+	var prefix [][HashSize]byte
+	for transitionHash != [HashSize]byte{} {
+		transition, err := h.GetTransition(transitionHash)
+		if errors.Is(err, os.ErrNotExist) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("getting transition %x: %w", transitionHash, err)
+		}
+		prefix = append(prefix, transitionHash)
+		transitionHash = transition.PreviousTransitionHash
+	}
+
+	for _, transitionHash := range slices.Backward(prefix) {
+		transition, err := h.GetTransition(transitionHash)
+		if err != nil {
+			return fmt.Errorf("getting transition %x: %w", transitionHash, err)
+		}
+		transitionHashStr := hex.EncodeToString(transitionHash[:])
+		if err := h.store.Delete("signatures/" + transitionHashStr); err != nil {
+			return fmt.Errorf("deleting signature %s: %w", transitionHashStr, err)
+		}
+		if _, ok := retained.manifests[transition.ManifestHash]; !ok {
+			if err := h.deleteManifest(transition.ManifestHash, retained); err != nil {
+				return err
+			}
+		}
+		if err := h.deleteContentaddressed("transitions/%s", transitionHash); err != nil {
+			return fmt.Errorf("deleting transition %s: %w", transitionHashStr, err)
+		}
+	}
+	return nil
+}
+
+// deleteManifest deletes a manifest and the policies it references, unless they're retained.
+//
+// Policies are deleted before the manifest, so that they can still be found if the deletion is
+// interrupted.
+func (h *History) deleteManifest(manifestHash [HashSize]byte, retained *references) error {
+	manifestBytes, err := h.GetManifest(manifestHash)
+	if errors.Is(err, os.ErrNotExist) {
+		// Already deleted, for example because an earlier transition referenced the same manifest.
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("getting manifest %x: %w", manifestHash, err)
+	}
+	policyHashStrs, err := policyReferences(manifestBytes)
+	if err != nil {
+		return fmt.Errorf("manifest %x: %w", manifestHash, err)
+	}
+	for _, policyHashStr := range policyHashStrs {
+		if _, ok := retained.policies[policyHashStr]; ok {
+			continue
+		}
+		policyHash, err := hex.DecodeString(policyHashStr)
+		if err != nil || len(policyHash) != HashSize {
+			return fmt.Errorf("manifest %x references invalid policy hash %q", manifestHash, policyHashStr)
+		}
+		if err := h.deleteContentaddressed("policies/%s", [HashSize]byte(policyHash)); err != nil {
+			return fmt.Errorf("deleting policy %s: %w", policyHashStr, err)
+		}
+	}
+	if err := h.deleteContentaddressed("manifests/%s", manifestHash); err != nil {
+		return fmt.Errorf("deleting manifest %x: %w", manifestHash, err)
+	}
+	return nil
+}
+
+func (h *History) deleteContentaddressed(pathFmt string, hash [HashSize]byte) error {
+	h.removeFromCache(hash)
+	return h.store.Delete(fmt.Sprintf(pathFmt, hex.EncodeToString(hash[:])))
+}
@@ -0,0 +1,281 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package history
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/edgelesssys/contrast/internal/history/aferostore"
+	"github.com/edgelesssys/contrast/internal/testkeys"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// prunableHistory is a history with a linear transition chain for pruning tests.
+type prunableHistory struct {
+	*History
+	fs          *afero.Afero
+	signingKey  *ecdsa.PrivateKey
+	latest      *LatestTransition
+	transitions [][HashSize]byte
+	manifests   [][HashSize]byte
+}
+
+func newPrunableHistory(t *testing.T) *prunableHistory {
+	fs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	return &prunableHistory{
+		History:    NewWithStore(slog.New(slog.DiscardHandler), aferostore.New(fs)),
+		fs:         fs,
+		signingKey: testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP256Keys[0]),
+		latest:     &LatestTransition{},
+	}
+}
+
+// appendManifest adds a manifest referencing the given policies as the next transition.
+func (h *prunableHistory) appendManifest(require *require.Assertions, policies ...string) {
+	var policyRefs []string
+	for _, policy := range policies {
+		policyHash, err := h.SetPolicy([]byte(policy))
+		require.NoError(err)
+		policyRefs = append(policyRefs, fmt.Sprintf("%q:{}", hex.EncodeToString(policyHash[:])))
+	}
+	manifest := fmt.Appendf(nil, `{"Policies":{%s}}`, strings.Join(policyRefs, ","))
+	manifestHash, err := h.SetManifest(manifest)
+	require.NoError(err)
+
+	var previous [HashSize]byte
+	if len(h.transitions) > 0 {
+		previous = h.transitions[len(h.transitions)-1]
+	}
+	transitionHash, err := h.SetTransition(&Transition{ManifestHash: manifestHash, PreviousTransitionHash: previous})
+	require.NoError(err)
+	require.NoError(h.SetTransitionSignature(transitionHash, []byte("signature")))
+
+	var oldLatest *LatestTransition
+	if len(h.transitions) > 0 {
+		oldLatest = h.latest
+	}
+	next := &LatestTransition{TransitionHash: transitionHash}
+	require.NoError(h.SetLatest(oldLatest, next, h.signingKey))
+	h.latest = next
+	h.transitions = append(h.transitions, transitionHash)
+	h.manifests = append(h.manifests, manifestHash)
+}
+
+func (h *prunableHistory) walk(require *require.Assertions) [][HashSize]byte {
+	var walked [][HashSize]byte
+	require.NoError(h.WalkTransitions(&h.signingKey.PublicKey, h.latest.TransitionHash, func(hash [HashSize]byte, _ *Transition) error {
+		walked = append([][HashSize]byte{hash}, walked...)
+		return nil
+	}))
+	return walked
+}
+
+func (h *prunableHistory) exists(key string, hash [HashSize]byte) bool {
+	ok, err := h.fs.Exists(fmt.Sprintf("%s/%x", key, hash))
+	return err == nil && ok
+}
+
+func TestPrune(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	h := newPrunableHistory(t)
+	h.appendManifest(require, "policy1")
+	h.appendManifest(require, "policy1", "policy2")
+	h.appendManifest(require, "policy2")
+	h.appendManifest(require, "policy3")
+	h.appendManifest(require, "policy2", "policy3")
+
+	_, err := h.Prune(h.latest.TransitionHash, 0, h.signingKey)
+	require.Error(err, "keeping no transitions must fail")
+
+	pruned, err := h.Prune(h.latest.TransitionHash, 2, h.signingKey)
+	require.NoError(err)
+	assert.Equal(3, pruned)
+	assert.Equal(h.transitions[3:], h.walk(require))
+
+	checkpoint, err := h.GetCheckpoint(&h.signingKey.PublicKey)
+	require.NoError(err)
+	assert.Equal(h.transitions[2], checkpoint.TransitionHash)
+	assert.Equal(uint64(3), checkpoint.Generation)
+
+	for i := range 3 {
+		assert.False(h.exists("transitions", h.transitions[i]))
+		assert.False(h.exists("signatures", h.transitions[i]))
+		assert.False(h.exists("manifests", h.manifests[i]))
+	}
+	for i := 3; i < 5; i++ {
+		assert.True(h.exists("transitions", h.transitions[i]))
+		assert.True(h.exists("signatures", h.transitions[i]))
+		assert.True(h.exists("manifests", h.manifests[i]))
+	}
+	assert.False(h.exists("policies", Digest([]byte("policy1"))))
+	assert.True(h.exists("policies", Digest([]byte("policy2"))), "policy2 is referenced by a retained manifest")
+	assert.True(h.exists("policies", Digest([]byte("policy3"))))
+
+	// Pruning again doesn't change anything.
+	pruned, err = h.Prune(h.latest.TransitionHash, 2, h.signingKey)
+	require.NoError(err)
+	assert.Equal(0, pruned)
+
+	// The checkpoint advances with further pruning.
+	h.appendManifest(require, "policy3")
+	pruned, err = h.Prune(h.latest.TransitionHash, 2, h.signingKey)
+	require.NoError(err)
+	assert.Equal(1, pruned)
+	assert.Equal(h.transitions[4:], h.walk(require))
+	checkpoint, err = h.GetCheckpoint(&h.signingKey.PublicKey)
+	require.NoError(err)
+	assert.Equal(h.transitions[3], checkpoint.TransitionHash)
+	assert.Equal(uint64(4), checkpoint.Generation)
+	assert.False(h.exists("transitions", h.transitions[3]))
+}
+
+func TestPrune_SharedManifest(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	h := newPrunableHistory(t)
+	h.appendManifest(require, "policy1")
+	h.appendManifest(require, "policy2")
+	h.appendManifest(require, "policy1")
+	require.Equal(h.manifests[0], h.manifests[2])
+
+	pruned, err := h.Prune(h.latest.TransitionHash, 1, h.signingKey)
+	require.NoError(err)
+	assert.Equal(2, pruned)
+
+	assert.True(h.exists("manifests", h.manifests[0]), "manifest is referenced by a retained transition")
+	assert.True(h.exists("policies", Digest([]byte("policy1"))))
+	assert.False(h.exists("manifests", h.manifests[1]))
+	assert.False(h.exists("policies", Digest([]byte("policy2"))))
+}
+
+func TestPrune_Interrupted(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	h := newPrunableHistory(t)
+	for i := range 5 {
+		h.appendManifest(require, fmt.Sprintf("policy%d", i))
+	}
+
+	// Fail after the first two transitions were deleted entirely.
+	store := &failingDeleteStore{Store: h.store, remaining: 2 * 4}
+	h.store = store
+
+	_, err := h.Prune(h.latest.TransitionHash, 1, h.signingKey)
+	require.ErrorIs(err, errDeleteFailed)
+	assert.Equal(h.transitions[4:], h.walk(require), "the checkpoint must be in effect after an interrupted pruning")
+	assert.False(h.exists("transitions", h.transitions[1]))
+	assert.True(h.exists("transitions", h.transitions[2]))
+
+	store.remaining = -1
+	pruned, err := h.Prune(h.latest.TransitionHash, 1, h.signingKey)
+	require.NoError(err)
+	assert.Equal(0, pruned)
+	for i := range 4 {
+		assert.False(h.exists("transitions", h.transitions[i]))
+		assert.False(h.exists("manifests", h.manifests[i]))
+		assert.False(h.exists("policies", Digest(fmt.Appendf(nil, "policy%d", i))))
+	}
+}
+
+func TestPrune_InvalidCheckpoint(t *testing.T) {
+	require := require.New(t)
+
+	h := newPrunableHistory(t)
+	h.appendManifest(require, "policy1")
+	h.appendManifest(require, "policy2")
+
+	otherKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[0])
+	checkpoint := &Checkpoint{TransitionHash: h.transitions[0], Generation: 1}
+	require.NoError(checkpoint.sign(otherKey))
+	require.NoError(h.store.Set(checkpointKey, checkpoint.MarshalBinary()))
+
+	_, err := h.GetCheckpoint(&h.signingKey.PublicKey)
+	require.Error(err)
+	_, err = h.Prune(h.latest.TransitionHash, 1, h.signingKey)
+	require.Error(err)
+	err = h.WalkTransitions(&h.signingKey.PublicKey, h.latest.TransitionHash, func([HashSize]byte, *Transition) error {
+		return nil
+	})
+	require.Error(err, "a checkpoint with an invalid signature must not end the walk")
+}
+
+func TestCheckpoint(t *testing.T) {
+	require := require.New(t)
+	signingKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP256Keys[0])
+
+	checkpoint := &Checkpoint{TransitionHash: [HashSize]byte{1, 2, 3}, Generation: 42}
+	require.NoError(checkpoint.sign(signingKey))
+	require.NoError(checkpoint.verify(&signingKey.PublicKey))
+
+	var unmarshaled Checkpoint
+	require.NoError(unmarshaled.UnmarshalBinary(checkpoint.MarshalBinary()))
+	require.Equal(*checkpoint, unmarshaled)
+	require.Error(unmarshaled.UnmarshalBinary(make([]byte, HashSize+8)))
+
+	// A checkpoint signature must not be usable as latest transition signature and vice versa.
+	latest := &LatestTransition{TransitionHash: checkpoint.TransitionHash, Signature: checkpoint.Signature}
+	require.Error(latest.verify(&signingKey.PublicKey))
+	require.NoError(latest.sign(signingKey))
+	checkpoint.Signature = latest.Signature
+	require.Error(checkpoint.verify(&signingKey.PublicKey))
+
+	var nilCheckpoint *Checkpoint
+	require.Empty(nilCheckpoint.MarshalBinary())
+}
+
+func TestMigrate_Pruned(t *testing.T) {
+	require := require.New(t)
+
+	h := newPrunableHistory(t)
+	for i := range 3 {
+		h.appendManifest(require, fmt.Sprintf("policy%d", i))
+	}
+	_, err := h.Prune(h.latest.TransitionHash, 1, h.signingKey)
+	require.NoError(err)
+
+	dstFS := &afero.Afero{Fs: afero.NewMemMapFs()}
+	migrated, err := Migrate(h.store, aferostore.New(dstFS))
+	require.NoError(err)
+	require.True(migrated)
+
+	dst := NewWithStore(slog.New(slog.DiscardHandler), aferostore.New(dstFS))
+	checkpoint, err := dst.GetCheckpoint(&h.signingKey.PublicKey)
+	require.NoError(err)
+	require.Equal(uint64(2), checkpoint.Generation)
+	var walked int
+	require.NoError(dst.WalkTransitions(&h.signingKey.PublicKey, h.latest.TransitionHash, func([HashSize]byte, *Transition) error {
+		walked++
+		return nil
+	}))
+	require.Equal(1, walked)
+}
+
+var errDeleteFailed = errors.New("delete failed")
+
+// failingDeleteStore fails all deletions after the given number of deletions succeeded. A
+// negative number disables failures.
+type failingDeleteStore struct {
+	Store
+	remaining int
+}
+
+func (s *failingDeleteStore) Delete(key string) error {
+	if s.remaining == 0 {
+		return errDeleteFailed
+	}
+	s.remaining--
+	return s.Store.Delete(key)
+}
@@ -0,0 +1,578 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package history
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// recordSignatureDomain is prepended to the content of a signed record before signing. The key
+	// that signs records also signs latest transitions and checkpoints, and a record signature must
+	// never be valid for those.
+	recordSignatureDomain = "contrast-signed-record"
+	// recordIndexName is the name of the index of a record set.
+	recordIndexName = "index"
+	// maxRecordUpdateAttempts limits the retries of an update that races with other Coordinators.
+	maxRecordUpdateAttempts = 10
+	// recordWatchRetryInterval is the time to wait before watching the index again after the
+	// watch failed.
+	recordWatchRetryInterval = 5 * time.Second
+)
+
+// ErrRecordRollback is returned if a signed record or the index of a record set was rolled back,
+// replaced or deleted.
+var ErrRecordRollback = errors.New("signed record was rolled back")
+
+var recordNameRe = regexp.MustCompile(`^[a-zA-Z0-9-]+$`)
+
+// Records is a set of signed records that share a key prefix in the store.
+//
+// The store is shared by all Coordinators of a deployment, but isn't trusted. Records are signed
+// with the transaction signing key, which binds them to their store key. A signature alone doesn't
+// prevent replaying an older record or deleting it, so each set has a signed index that holds the
+// revision and hash of every record. A record is only accepted if it matches its index entry.
+//
+// An update writes the record before the index, so after an interrupted update the record may be
+// one revision ahead of the index. Such a record is accepted and committed to the index on read.
+// New names are reserved in the index before their record is written, so a record whose name
+// isn't indexed is never accepted, and a deleted record can't be restored by replaying it.
+//
+// The index itself is protected by its revision: a Records instance rejects an index that's older
+// than the newest one it has seen. Like the latest transition of the history, the index can't be
+// protected against a rollback of the whole store while no Coordinator is running.
+//
+// Verified records are cached. While Watch runs, reads are served from the cache, which is
+// dropped whenever the index changes. Otherwise, each read fetches the index and uses the cache
+// only if the index is unchanged.
+type Records struct {
+	store  Store
+	prefix string
+	logger *slog.Logger
+
+	// mu serializes reads and updates of this Coordinator and protects the fields below. Updates of
+	// other Coordinators are detected by the compare-and-swap of the records and the index.
+	mu sync.Mutex
+	// highestRevision is the newest index revision seen.
+	highestRevision uint64
+	// index is the verified index, or nil if it must be fetched before the next read.
+	index *loadedIndex
+	// cache holds verified records that match the index, by name.
+	cache map[string]*loadedRecord
+	// publicKey is the key index and cache were verified with.
+	publicKey *ecdsa.PublicKey
+	// watching is set while Watch keeps the index up to date.
+	watching bool
+}
+
+// NewRecords creates a record set for the given key prefix of the store.
+func NewRecords(store Store, prefix string, logger *slog.Logger) *Records {
+	return &Records{
+		store:  store,
+		prefix: prefix,
+		logger: logger,
+	}
+}
+
+// Get returns the content of the named record.
+//
+// If the record doesn't exist, an error wrapping os.ErrNotExist is returned.
+func (r *Records) Get(signingKey *ecdsa.PrivateKey, name string) ([]byte, error) {
+	if err := checkRecordName(name); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, err := r.get(signingKey, name)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, fmt.Errorf("record %q: %w", r.key(name), os.ErrNotExist)
+	}
+	return bytes.Clone(record.record.Content), nil
+}
+
+// Names returns the sorted names of all records in the set.
+func (r *Records) Names(signingKey *ecdsa.PrivateKey) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	index, err := r.loadIndex(signingKey)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Sorted(maps.Keys(index.refs)), nil
+}
+
+// Update applies fn to the content of the named record and stores the result.
+//
+// fn receives nil if the record doesn't exist yet. It may be called more than once if the update
+// races with other Coordinators, and its error is returned unchanged.
+func (r *Records) Update(signingKey *ecdsa.PrivateKey, name string, fn func(content []byte) ([]byte, error)) error {
+	if err := checkRecordName(name); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var lastErr error
+	for range maxRecordUpdateAttempts {
+		current, err := r.get(signingKey, name)
+		if err != nil {
+			return err
+		}
+		var oldContent, oldData []byte
+		var revision uint64
+		if current != nil {
+			oldContent = bytes.Clone(current.record.Content)
+			oldData = current.data
+			revision = current.record.Revision
+		} else {
+			if err := r.commit(signingKey, name, &recordRef{}); err != nil {
+				return err
+			}
+			// A record that isn't indexed is a leftover of a deleted record and is overwritten,
+			// unless another Coordinator created it in the meantime.
+			oldData, err = r.store.Get(r.key(name))
+			if errors.Is(err, os.ErrNotExist) {
+				oldData = nil
+			} else if err != nil {
+				return fmt.Errorf("getting record %q: %w", r.key(name), err)
+			}
+			if len(oldData) > 0 {
+				leftover, err := unmarshalSignedRecord(oldData, r.key(name), &signingKey.PublicKey)
+				if err == nil && leftover.Generation == r.index.refs[name].Generation {
+					r.index, r.cache = nil, nil
+					continue
+				}
+			}
+		}
+		generation := r.index.refs[name].Generation
+
+		content, err := fn(oldContent)
+		if err != nil {
+			return err
+		}
+		record := &signedRecord{
+			Key:        r.key(name),
+			Generation: generation,
+			Revision:   revision + 1,
+			Content:    content,
+		}
+		data, err := record.marshal(signingKey)
+		if err != nil {
+			return err
+		}
+		if lastErr = r.store.CompareAndSwap(record.Key, oldData, data); lastErr != nil {
+			// Another Coordinator updated the record in the meantime, retry on top of it.
+			r.index, r.cache = nil, nil
+			continue
+		}
+		ref := newRecordRef(record, data)
+		if err := r.commit(signingKey, name, &ref); err != nil {
+			return err
+		}
+		if r.index.refs[name] == ref {
+			r.cacheRecord(name, &loadedRecord{record: record, data: data})
+		}
+		return nil
+	}
+	return fmt.Errorf("updating record %q after %d attempts: %w", r.key(name), maxRecordUpdateAttempts, lastErr)
+}
+
+// Delete removes the named record from the set.
+func (r *Records) Delete(signingKey *ecdsa.PrivateKey, name string) error {
+	if err := checkRecordName(name); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// The index entry is removed first, so that the record isn't accepted anymore even if
+	// deleting it fails.
+	if err := r.commit(signingKey, name, nil); err != nil {
+		return err
+	}
+	delete(r.cache, name)
+	if err := r.store.Delete(r.key(name)); err != nil {
+		return fmt.Errorf("deleting record %q: %w", r.key(name), err)
+	}
+	return nil
+}
+
+// Watch keeps the index of the record set up to date, so that reads don't need to fetch it from
+// the store. Watch blocks until the context is done. If the store can't be watched, reads keep
+// fetching the index.
+func (r *Records) Watch(ctx context.Context) error {
+	for {
+		err := r.watch(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		r.logger.Warn("Watching record index failed, retrying", "prefix", r.prefix, "err", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(recordWatchRetryInterval):
+		}
+	}
+}
+
+func (r *Records) watch(ctx context.Context) error {
+	ch, cancel, err := r.store.Watch(r.key(recordIndexName))
+	if err != nil {
+		return fmt.Errorf("watching record index: %w", err)
+	}
+	defer cancel()
+	if ch == nil {
+		// The store doesn't support watching. Reads must fetch the index, because other
+		// Coordinators might still write to the store.
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	r.mu.Lock()
+	r.watching = true
+	// Updates before the watch started aren't reported.
+	r.index = nil
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		r.watching = false
+		r.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case data, ok := <-ch:
+			if !ok {
+				return errors.New("store watcher closed unexpectedly")
+			}
+			r.mu.Lock()
+			if r.index != nil && !bytes.Equal(data, r.index.data) {
+				r.index = nil
+			}
+			r.mu.Unlock()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// get returns the verified named record, or nil if it doesn't exist. The caller must hold r.mu.
+func (r *Records) get(signingKey *ecdsa.PrivateKey, name string) (*loadedRecord, error) {
+	index, err := r.loadIndex(signingKey)
+	if err != nil {
+		return nil, err
+	}
+	ref, ok := index.refs[name]
+	if !ok {
+		return nil, nil
+	}
+	if cached, ok := r.cache[name]; ok {
+		return cached, nil
+	}
+
+	key := r.key(name)
+	data, err := r.store.Get(key)
+	if errors.Is(err, os.ErrNotExist) {
+		if ref.Revision == 0 {
+			// The name was reserved, but the record wasn't written.
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: record %q was deleted", ErrRecordRollback, key)
+	} else if err != nil {
+		return nil, fmt.Errorf("getting record %q: %w", key, err)
+	}
+	record, err := unmarshalSignedRecord(data, key, &signingKey.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if record.Generation != ref.Generation {
+		if ref.Revision == 0 {
+			// A leftover of a deleted record, which the next update overwrites.
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: record %q belongs to generation %d, expected %d", ErrRecordRollback, key, record.Generation, ref.Generation)
+	}
+	switch {
+	case record.Revision == ref.Revision:
+		if newRecordRef(record, data) != ref {
+			return nil, fmt.Errorf("%w: record %q doesn't match its index entry", ErrRecordRollback, key)
+		}
+	case record.Revision == ref.Revision+1:
+		// An update was interrupted before it was committed to the index.
+		newRef := newRecordRef(record, data)
+		if err := r.commit(signingKey, name, &newRef); err != nil {
+			return nil, err
+		}
+		if r.index.refs[name] != newRef {
+			return nil, fmt.Errorf("%w: record %q was replaced", ErrRecordRollback, key)
+		}
+	default:
+		return nil, fmt.Errorf("%w: record %q has revision %d, expected %d", ErrRecordRollback, key, record.Revision, ref.Revision)
+	}
+
+	loaded := &loadedRecord{record: record, data: data}
+	r.cacheRecord(name, loaded)
+	return loaded, nil
+}
+
+// loadIndex returns the verified index, fetching it from the store if necessary. The caller must
+// hold r.mu.
+func (r *Records) loadIndex(signingKey *ecdsa.PrivateKey) (*loadedIndex, error) {
+	publicKey := &signingKey.PublicKey
+	if r.publicKey == nil || !r.publicKey.Equal(publicKey) {
+		r.index, r.cache = nil, nil
+		r.publicKey = publicKey
+	}
+	if r.index != nil && r.watching {
+		return r.index, nil
+	}
+
+	key := r.key(recordIndexName)
+	data, err := r.store.Get(key)
+	if errors.Is(err, os.ErrNotExist) {
+		data = nil
+	} else if err != nil {
+		return nil, fmt.Errorf("getting record index %q: %w", key, err)
+	}
+	if r.index != nil && bytes.Equal(data, r.index.data) {
+		return r.index, nil
+	}
+
+	r.index, r.cache = nil, nil
+	index := &loadedIndex{
+		record: &signedRecord{Key: key},
+		data:   data,
+		refs:   make(map[string]recordRef),
+	}
+	if len(data) > 0 {
+		index.record, err = unmarshalSignedRecord(data, key, publicKey)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(index.record.Content, &index.refs); err != nil {
+			return nil, fmt.Errorf("unmarshaling record index %q: %w", key, err)
+		}
+	}
+	if index.record.Revision < r.highestRevision {
+		return nil, fmt.Errorf("%w: record index %q has revision %d, but revision %d was seen before", ErrRecordRollback, key, index.record.Revision, r.highestRevision)
+	}
+	r.highestRevision = index.record.Revision
+	r.index = index
+	return index, nil
+}
+
+// commit sets the index entry of the named record to ref, or removes it if ref is nil. An entry
+// with a revision of zero reserves the name for a new record with the next generation. The
+// caller must hold r.mu.
+func (r *Records) commit(signingKey *ecdsa.PrivateKey, name string, ref *recordRef) error {
+	key := r.key(recordIndexName)
+	var lastErr error
+	for range maxRecordUpdateAttempts {
+		index, err := r.loadIndex(signingKey)
+		if err != nil {
+			return err
+		}
+		current, ok := index.refs[name]
+		switch {
+		case ref == nil && !ok:
+			return nil
+		case ref != nil && ok && current.Revision >= ref.Revision:
+			// Another Coordinator committed the record already.
+			return nil
+		}
+
+		refs := maps.Clone(index.refs)
+		record := &signedRecord{Key: key, Revision: index.record.Revision + 1}
+		if ref == nil {
+			delete(refs, name)
+		} else if ref.Revision == 0 {
+			refs[name] = recordRef{Generation: record.Revision}
+		} else {
+			refs[name] = *ref
+		}
+		record.Content, err = json.Marshal(refs)
+		if err != nil {
+			return fmt.Errorf("marshaling record index %q: %w", key, err)
+		}
+		data, err := record.marshal(signingKey)
+		if err != nil {
+			return err
+		}
+		if lastErr = r.store.CompareAndSwap(key, index.data, data); lastErr == nil {
+			r.index = &loadedIndex{record: record, data: data, refs: refs}
+			r.highestRevision = record.Revision
+			return nil
+		}
+		// Another Coordinator updated the index in the meantime, retry on top of it.
+		r.index, r.cache = nil, nil
+	}
+	return fmt.Errorf("updating record index %q after %d attempts: %w", key, maxRecordUpdateAttempts, lastErr)
+}
+
+// MigrateRecords copies the record set with the given prefix from src to dst.
+//
+// The migration is skipped if dst already has an index for the prefix or src has none, and the
+// returned bool reports whether a migration took place. The index is copied last, so an
+// interrupted migration can safely be retried.
+func MigrateRecords(src, dst Store, prefix string) (bool, error) {
+	indexKey := prefix + "/" + recordIndexName
+	if has, err := dst.Has(indexKey); err != nil {
+		return false, fmt.Errorf("checking destination for record index %q: %w", indexKey, err)
+	} else if has {
+		return false, nil
+	}
+	data, err := src.Get(indexKey)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("getting record index %q: %w", indexKey, err)
+	}
+
+	var index signedRecord
+	if err := json.Unmarshal(data, &index); err != nil {
+		return false, fmt.Errorf("unmarshaling record index %q: %w", indexKey, err)
+	}
+	var refs map[string]recordRef
+	if err := json.Unmarshal(index.Content, &refs); err != nil {
+		return false, fmt.Errorf("unmarshaling record index %q: %w", indexKey, err)
+	}
+	for _, name := range slices.Sorted(maps.Keys(refs)) {
+		key := prefix + "/" + name
+		record, err := src.Get(key)
+		if errors.Is(err, os.ErrNotExist) && refs[name].Revision == 0 {
+			continue
+		} else if err != nil {
+			return false, fmt.Errorf("getting record %q: %w", key, err)
+		}
+		if err := dst.Set(key, record); err != nil {
+			return false, fmt.Errorf("copying record %q: %w", key, err)
+		}
+	}
+	if err := dst.Set(indexKey, data); err != nil {
+		return false, fmt.Errorf("copying record index %q: %w", indexKey, err)
+	}
+	return true, nil
+}
+
+func (r *Records) cacheRecord(name string, record *loadedRecord) {
+	if r.cache == nil {
+		r.cache = make(map[string]*loadedRecord)
+	}
+	r.cache[name] = record
+}
+
+func (r *Records) key(name string) string {
+	return r.prefix + "/" + name
+}
+
+func checkRecordName(name string) error {
+	if !recordNameRe.MatchString(name) || name == recordIndexName {
+		return fmt.Errorf("invalid record name %q", name)
+	}
+	return nil
+}
+
+// signedRecord is the stored form of a record and of the index.
+type signedRecord struct {
+	// Key is the store key of the record, which binds the signature to it.
+	Key string `json:"key"`
+	// Generation is the index revision that reserved the name of the record. It distinguishes a
+	// record from a deleted record of the same name.
+	Generation uint64 `json:"generation,omitempty"`
+	// Revision is increased with every update of the record.
+	Revision uint64 `json:"revision"`
+	// Content is the payload of the record.
+	Content []byte `json:"content"`
+	// Signature is the signature of the Coordinator over the other fields.
+	Signature []byte `json:"signature,omitempty"`
+}
+
+func (s *signedRecord) digest() ([HashSize]byte, error) {
+	unsigned := *s
+	unsigned.Signature = nil
+	data, err := json.Marshal(unsigned)
+	if err != nil {
+		return [HashSize]byte{}, fmt.Errorf("marshaling record %q: %w", s.Key, err)
+	}
+	return Digest(append([]byte(recordSignatureDomain), data...)), nil
+}
+
+func (s *signedRecord) marshal(signingKey *ecdsa.PrivateKey) ([]byte, error) {
+	digest, err := s.digest()
+	if err != nil {
+		return nil, err
+	}
+	s.Signature, err = ecdsa.SignASN1(rand.Reader, signingKey, digest[:])
+	if err != nil {
+		return nil, fmt.Errorf("signing record %q: %w", s.Key, err)
+	}
+	return json.Marshal(s)
+}
+
+func unmarshalSignedRecord(data []byte, key string, publicKey *ecdsa.PublicKey) (*signedRecord, error) {
+	var record signedRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("unmarshaling record %q: %w", key, err)
+	}
+	if record.Key != key {
+		return nil, fmt.Errorf("record %q is stored under key %q", record.Key, key)
+	}
+	digest, err := record.digest()
+	if err != nil {
+		return nil, err
+	}
+	if !ecdsa.VerifyASN1(publicKey, digest[:], record.Signature) {
+		return nil, fmt.Errorf("record %q has an invalid signature", key)
+	}
+	return &record, nil
+}
+
+// recordRef is the index entry of a record.
+type recordRef struct {
+	// Generation is the index revision that reserved the name.
+	Generation uint64 `json:"generation"`
+	// Revision is the revision of the record. It's zero while the record isn't written yet.
+	Revision uint64 `json:"revision"`
+	// Hash is the hex-encoded hash of the stored record.
+	Hash string `json:"hash,omitempty"`
+}
+
+func newRecordRef(record *signedRecord, data []byte) recordRef {
+	hash := Digest(data)
+	return recordRef{
+		Generation: record.Generation,
+		Revision:   record.Revision,
+		Hash:       hex.EncodeToString(hash[:]),
+	}
+}
+
+type loadedIndex struct {
+	record *signedRecord
+	data   []byte
+	refs   map[string]recordRef
+}
+
+type loadedRecord struct {
+	record *signedRecord
+	data   []byte
+}
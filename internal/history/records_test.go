@@ -0,0 +1,222 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package history
+
+import (
+	"crypto/ecdsa"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/edgelesssys/contrast/internal/history/aferostore"
+	"github.com/edgelesssys/contrast/internal/testkeys"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecords(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	store := newTestStore()
+	signingKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP256Keys[0])
+	records := NewRecords(store, "test", slog.New(slog.DiscardHandler))
+
+	_, err := records.Get(signingKey, "a")
+	require.ErrorIs(err, os.ErrNotExist)
+	names, err := records.Names(signingKey)
+	require.NoError(err)
+	assert.Empty(names)
+
+	require.NoError(records.Update(signingKey, "a", func(content []byte) ([]byte, error) {
+		assert.Nil(content)
+		return []byte("1"), nil
+	}))
+	require.NoError(records.Update(signingKey, "a", func(content []byte) ([]byte, error) {
+		assert.Equal([]byte("1"), content)
+		return []byte("2"), nil
+	}))
+	require.NoError(records.Update(signingKey, "b", func([]byte) ([]byte, error) {
+		return []byte("b"), nil
+	}))
+
+	content, err := records.Get(signingKey, "a")
+	require.NoError(err)
+	assert.Equal([]byte("2"), content)
+	names, err = records.Names(signingKey)
+	require.NoError(err)
+	assert.Equal([]string{"a", "b"}, names)
+
+	// A new instance, like a restarted Coordinator, reads the same records.
+	content, err = NewRecords(store, "test", slog.New(slog.DiscardHandler)).Get(signingKey, "a")
+	require.NoError(err)
+	assert.Equal([]byte("2"), content)
+
+	require.NoError(records.Delete(signingKey, "a"))
+	_, err = records.Get(signingKey, "a")
+	require.ErrorIs(err, os.ErrNotExist)
+
+	// Records signed with another key are rejected.
+	otherKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[0])
+	_, err = records.Get(otherKey, "b")
+	require.Error(err)
+
+	for _, name := range []string{"", "index", "a/b", "a.b"} {
+		_, err = records.Get(signingKey, name)
+		assert.Error(err, name)
+	}
+}
+
+func TestRecords_Rollback(t *testing.T) {
+	require := require.New(t)
+
+	store := newTestStore()
+	signingKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP256Keys[0])
+	records := NewRecords(store, "test", slog.New(slog.DiscardHandler))
+	set := func(value string) {
+		require.NoError(records.Update(signingKey, "a", func([]byte) ([]byte, error) {
+			return []byte(value), nil
+		}))
+	}
+
+	set("1")
+	oldRecord, err := store.Get("test/a")
+	require.NoError(err)
+	oldIndex, err := store.Get("test/index")
+	require.NoError(err)
+	set("2")
+
+	// Replaying an older record is detected with the index.
+	newRecord, err := store.Get("test/a")
+	require.NoError(err)
+	require.NoError(store.Set("test/a", oldRecord))
+	content, err := records.Get(signingKey, "a")
+	require.NoError(err)
+	require.Equal([]byte("2"), content, "the verified record must be served from the cache")
+	_, err = NewRecords(store, "test", slog.New(slog.DiscardHandler)).Get(signingKey, "a")
+	require.ErrorIs(err, ErrRecordRollback)
+
+	// Deleting a record is detected with the index.
+	require.NoError(store.Delete("test/a"))
+	_, err = NewRecords(store, "test", slog.New(slog.DiscardHandler)).Get(signingKey, "a")
+	require.ErrorIs(err, ErrRecordRollback)
+
+	// Replaying the index together with the record is detected by the instance that saw the
+	// newer index.
+	require.NoError(store.Set("test/a", oldRecord))
+	require.NoError(store.Set("test/index", oldIndex))
+	_, err = records.Get(signingKey, "a")
+	require.ErrorIs(err, ErrRecordRollback)
+
+	// A deleted record can't be revived, not even after its name was reused.
+	require.NoError(store.Set("test/a", newRecord))
+	records = NewRecords(store, "test", slog.New(slog.DiscardHandler))
+	set("3")
+	require.NoError(records.Delete(signingKey, "a"))
+	require.NoError(store.Set("test/a", newRecord))
+	_, err = records.Get(signingKey, "a")
+	require.ErrorIs(err, os.ErrNotExist)
+	set("4")
+	content, err = records.Get(signingKey, "a")
+	require.NoError(err)
+	require.Equal([]byte("4"), content)
+	require.NoError(store.Set("test/a", newRecord))
+	_, err = NewRecords(store, "test", slog.New(slog.DiscardHandler)).Get(signingKey, "a")
+	require.ErrorIs(err, ErrRecordRollback)
+}
+
+func TestRecords_InterruptedUpdate(t *testing.T) {
+	require := require.New(t)
+
+	store := newTestStore()
+	signingKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP256Keys[0])
+	records := NewRecords(store, "test", slog.New(slog.DiscardHandler))
+	require.NoError(records.Update(signingKey, "a", func([]byte) ([]byte, error) {
+		return []byte("1"), nil
+	}))
+	index, err := store.Get("test/index")
+	require.NoError(err)
+	require.NoError(records.Update(signingKey, "a", func([]byte) ([]byte, error) {
+		return []byte("2"), nil
+	}))
+
+	// The update was written, but not committed to the index.
+	require.NoError(store.Set("test/index", index))
+
+	restarted := NewRecords(store, "test", slog.New(slog.DiscardHandler))
+	content, err := restarted.Get(signingKey, "a")
+	require.NoError(err)
+	require.Equal([]byte("2"), content)
+	committed, err := store.Get("test/index")
+	require.NoError(err)
+	require.NotEqual(index, committed, "the update must be committed to the index")
+}
+
+func TestRecords_Concurrent(t *testing.T) {
+	require := require.New(t)
+
+	store := newTestStore()
+	signingKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP256Keys[0])
+	// Two record sets share the store, like two Coordinators.
+	instances := []*Records{
+		NewRecords(store, "test", slog.New(slog.DiscardHandler)),
+		NewRecords(store, "test", slog.New(slog.DiscardHandler)),
+	}
+
+	var wg sync.WaitGroup
+	for _, records := range instances {
+		wg.Go(func() {
+			for range 5 {
+				require.NoError(records.Update(signingKey, "counter", func(content []byte) ([]byte, error) {
+					counter, _ := strconv.Atoi(string(content))
+					return []byte(strconv.Itoa(counter + 1)), nil
+				}))
+			}
+		})
+	}
+	wg.Wait()
+
+	for _, records := range instances {
+		content, err := records.Get(signingKey, "counter")
+		require.NoError(err)
+		require.Equal("10", string(content))
+	}
+}
+
+func TestMigrateRecords(t *testing.T) {
+	require := require.New(t)
+
+	src := newTestStore()
+	dst := newTestStore()
+	signingKey := testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP256Keys[0])
+
+	migrated, err := MigrateRecords(src, dst, "test")
+	require.NoError(err)
+	require.False(migrated, "empty record set must not be migrated")
+
+	records := NewRecords(src, "test", slog.New(slog.DiscardHandler))
+	for _, name := range []string{"a", "b"} {
+		require.NoError(records.Update(signingKey, name, func([]byte) ([]byte, error) {
+			return []byte(name), nil
+		}))
+	}
+
+	migrated, err = MigrateRecords(src, dst, "test")
+	require.NoError(err)
+	require.True(migrated)
+	content, err := NewRecords(dst, "test", slog.New(slog.DiscardHandler)).Get(signingKey, "b")
+	require.NoError(err)
+	require.Equal([]byte("b"), content)
+
+	migrated, err = MigrateRecords(src, dst, "test")
+	require.NoError(err)
+	require.False(migrated, "existing record set must not be overwritten")
+}
+
+func newTestStore() Store {
+	return aferostore.New(&afero.Afero{Fs: afero.NewMemMapFs()})
+}
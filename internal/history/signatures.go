@@ -0,0 +1,103 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package history
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"slices"
+
+	"golang.org/x/crypto/cryptobyte"
+	"golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// TransitionSigningDigest returns the digest a workload owner signs to authorize a transition.
+//
+// The transition hash is hex-encoded before hashing, so that it can be signed as a text blob with
+// external tools.
+func TransitionSigningDigest(transitionHash [HashSize]byte) [HashSize]byte {
+	return Digest(hex.AppendEncode(nil, transitionHash[:]))
+}
+
+// CancellationSigningDigest returns the digest a workload owner signs to authorize the cancellation
+// of the scheduled update with the given transition hash.
+//
+// The digest is computed over CancellationSigningText, so that it can be signed with external
+// tools like the transition signing digest.
+func CancellationSigningDigest(transitionHash [HashSize]byte) [HashSize]byte {
+	return Digest(CancellationSigningText(transitionHash))
+}
+
+// CancellationSigningText returns the text a workload owner signs to authorize the cancellation of
+// the scheduled update with the given transition hash.
+//
+// The text is the hex-encoded transition hash with a prefix, so that a cancellation signature is
+// never valid for a transition.
+func CancellationSigningText(transitionHash [HashSize]byte) []byte {
+	return hex.AppendEncode([]byte("cancel:"), transitionHash[:])
+}
+
+// SubCASigningDigest returns the digest a workload owner signs to authorize the issuance of a sub
+// CA with the given request parameters while the transition with the given hash is the latest one.
+//
+// The digest is computed over SubCASigningText, so that it can be signed with external tools like
+// the transition signing digest.
+func SubCASigningDigest(latestTransitionHash [HashSize]byte, csr []byte, names []string, lifetimeSeconds int64) [HashSize]byte {
+	return Digest(SubCASigningText(latestTransitionHash, csr, names, lifetimeSeconds))
+}
+
+// SubCASigningText returns the text a workload owner signs to authorize the issuance of a sub CA
+// with the given request parameters while the transition with the given hash is the latest one.
+//
+// The text is the hex-encoded latest transition hash and the hex-encoded digest of the request
+// parameters with a prefix, so that a sub CA signature is never valid for a transition or a
+// cancellation. Binding the latest transition hash keeps a signature from being used again after
+// the manifest changed.
+func SubCASigningText(latestTransitionHash [HashSize]byte, csr []byte, names []string, lifetimeSeconds int64) []byte {
+	var b cryptobyte.Builder
+	b.AddUint32LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(csr) })
+	b.AddUint32LengthPrefixed(func(b *cryptobyte.Builder) {
+		for _, name := range names {
+			b.AddUint32LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes([]byte(name)) })
+		}
+	})
+	b.AddUint64(uint64(lifetimeSeconds))
+	requestDigest := Digest(b.BytesOrPanic())
+
+	text := hex.AppendEncode([]byte("sub-ca:"), latestTransitionHash[:])
+	text = append(text, ':')
+	return hex.AppendEncode(text, requestDigest[:])
+}
+
+// SplitSignatures splits a signature bundle into the contained signatures.
+//
+// A signature bundle is the concatenation of ASN.1 DER encoded ECDSA signatures. A single
+// signature is thus also a valid bundle.
+func SplitSignatures(bundle []byte) ([][]byte, error) {
+	s := cryptobyte.String(bundle)
+	var signatures [][]byte
+	for !s.Empty() {
+		var signature cryptobyte.String
+		if !s.ReadASN1Element(&signature, asn1.SEQUENCE) {
+			return nil, errors.New("malformed signature bundle")
+		}
+		signatures = append(signatures, signature)
+	}
+	return signatures, nil
+}
+
+// JoinSignatures combines the given signatures into a signature bundle, omitting duplicates.
+func JoinSignatures(signatures ...[]byte) []byte {
+	var bundle []byte
+	var seen [][]byte
+	for _, signature := range signatures {
+		if slices.ContainsFunc(seen, func(s []byte) bool { return bytes.Equal(s, signature) }) {
+			continue
+		}
+		seen = append(seen, signature)
+		bundle = append(bundle, signature...)
+	}
+	return bundle
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package history
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"testing"
+
+	"github.com/edgelesssys/contrast/internal/testkeys"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignatureBundle(t *testing.T) {
+	require := require.New(t)
+
+	digest := TransitionSigningDigest([HashSize]byte{1, 2, 3})
+	var signatures [][]byte
+	for _, encodedKey := range testkeys.ECDSAP384Keys[:2] {
+		key := testkeys.New[ecdsa.PrivateKey](t, encodedKey)
+		signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+		require.NoError(err)
+		signatures = append(signatures, signature)
+	}
+
+	bundle := JoinSignatures(signatures[0], signatures[1], signatures[0])
+	split, err := SplitSignatures(bundle)
+	require.NoError(err)
+	require.Equal(signatures, split, "duplicates must be removed")
+
+	// A single signature is a valid bundle.
+	split, err = SplitSignatures(signatures[0])
+	require.NoError(err)
+	require.Equal(signatures[:1], split)
+
+	split, err = SplitSignatures(nil)
+	require.NoError(err)
+	require.Empty(split)
+
+	_, err = SplitSignatures(bundle[:len(bundle)-1])
+	require.Error(err)
+	_, err = SplitSignatures([]byte("not a signature"))
+	require.Error(err)
+}
+
+func TestSubCASigningText(t *testing.T) {
+	require := require.New(t)
+
+	text := SubCASigningText([HashSize]byte{1}, []byte("csr"), []string{"a", "b"}, 3600)
+	require.Equal(text, SubCASigningText([HashSize]byte{1}, []byte("csr"), []string{"a", "b"}, 3600))
+
+	// Every request parameter is bound.
+	for _, other := range [][]byte{
+		SubCASigningText([HashSize]byte{2}, []byte("csr"), []string{"a", "b"}, 3600),
+		SubCASigningText([HashSize]byte{1}, []byte("other"), []string{"a", "b"}, 3600),
+		SubCASigningText([HashSize]byte{1}, []byte("csr"), []string{"ab"}, 3600),
+		SubCASigningText([HashSize]byte{1}, []byte("csr"), []string{"a", "b"}, 60),
+	} {
+		require.NotEqual(text, other)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+// Package kmip implements the TTLV encoding of the Key Management Interoperability Protocol (KMIP)
+// and defines the tags and enumerations of the subset of KMIP that Contrast serves.
+//
+// See https://docs.oasis-open.org/kmip/spec/v1.4/kmip-spec-v1.4.html and
+// https://docs.oasis-open.org/kmip/kmip-spec/v2.0/kmip-spec-v2.0.html for the specifications.
+package kmip
+
+// Port is the IANA-registered port of KMIP over TLS.
+const Port = "5696"
+
+// Tag identifies the meaning of a TTLV item.
+type Tag uint32
+
+// Tags of the KMIP 1.4 and 2.0 specifications.
+const (
+	TagAttribute                             Tag = 0x420008
+	TagAttributeName                         Tag = 0x42000A
+	TagAttributeValue                        Tag = 0x42000B
+	TagBatchCount                            Tag = 0x42000D
+	TagBatchErrorContinuationOption          Tag = 0x42000E
+	TagBatchItem                             Tag = 0x42000F
+	TagBlockCipherMode                       Tag = 0x420011
+	TagCryptographicAlgorithm                Tag = 0x420028
+	TagCryptographicLength                   Tag = 0x42002A
+	TagCryptographicParameters               Tag = 0x42002B
+	TagCryptographicUsageMask                Tag = 0x42002C
+	TagIVCounterNonce                        Tag = 0x42003D
+	TagKeyBlock                              Tag = 0x420040
+	TagKeyFormatType                         Tag = 0x420042
+	TagKeyMaterial                           Tag = 0x420043
+	TagKeyValue                              Tag = 0x420045
+	TagMaximumItems                          Tag = 0x42004F
+	TagMaximumResponseSize                   Tag = 0x420050
+	TagName                                  Tag = 0x420053
+	TagNameType                              Tag = 0x420054
+	TagNameValue                             Tag = 0x420055
+	TagObjectType                            Tag = 0x420057
+	TagOperation                             Tag = 0x42005C
+	TagProtocolVersion                       Tag = 0x420069
+	TagProtocolVersionMajor                  Tag = 0x42006A
+	TagProtocolVersionMinor                  Tag = 0x42006B
+	TagRequestHeader                         Tag = 0x420077
+	TagRequestMessage                        Tag = 0x420078
+	TagRequestPayload                        Tag = 0x420079
+	TagResponseHeader                        Tag = 0x42007A
+	TagResponseMessage                       Tag = 0x42007B
+	TagResponsePayload                       Tag = 0x42007C
+	TagResultMessage                         Tag = 0x42007D
+	TagResultReason                          Tag = 0x42007E
+	TagResultStatus                          Tag = 0x42007F
+	TagSymmetricKey                          Tag = 0x42008F
+	TagTemplateAttribute                     Tag = 0x420091
+	TagTimeStamp                             Tag = 0x420092
+	TagUniqueBatchItemID                     Tag = 0x420093
+	TagUniqueIdentifier                      Tag = 0x420094
+	TagData                                  Tag = 0x4200C2
+	TagLocatedItems                          Tag = 0x4200D5
+	TagAuthenticatedEncryptionAdditionalData Tag = 0x4200FE
+	TagAuthenticatedEncryptionTag            Tag = 0x4200FF
+	// TagAttributes replaces TagTemplateAttribute in KMIP 2.0.
+	TagAttributes Tag = 0x420125
+)
+
+// Type is the encoding of the value of a TTLV item.
+type Type uint8
+
+// Types of the TTLV encoding.
+const (
+	TypeStructure   Type = 0x01
+	TypeInteger     Type = 0x02
+	TypeLongInteger Type = 0x03
+	TypeBigInteger  Type = 0x04
+	TypeEnumeration Type = 0x05
+	TypeBoolean     Type = 0x06
+	TypeTextString  Type = 0x07
+	TypeByteString  Type = 0x08
+	TypeDateTime    Type = 0x09
+	TypeInterval    Type = 0x0A
+)
+
+// Operation is the value of a TagOperation item.
+type Operation uint32
+
+// Operations of the KMIP specification that Contrast serves.
+const (
+	OperationCreate           Operation = 0x01
+	OperationLocate           Operation = 0x08
+	OperationGet              Operation = 0x0A
+	OperationDestroy          Operation = 0x14
+	OperationDiscoverVersions Operation = 0x1E
+	OperationEncrypt          Operation = 0x1F
+	OperationDecrypt          Operation = 0x20
+)
+
+// ResultStatus is the value of a TagResultStatus item.
+type ResultStatus uint32
+
+// Result statuses of the KMIP specification.
+const (
+	ResultStatusSuccess         ResultStatus = 0x00
+	ResultStatusOperationFailed ResultStatus = 0x01
+)
+
+// ResultReason is the value of a TagResultReason item.
+type ResultReason uint32
+
+// Result reasons of the KMIP specification.
+const (
+	ResultReasonItemNotFound              ResultReason = 0x01
+	ResultReasonResponseTooLarge          ResultReason = 0x02
+	ResultReasonInvalidMessage            ResultReason = 0x04
+	ResultReasonOperationNotSupported     ResultReason = 0x05
+	ResultReasonMissingData               ResultReason = 0x06
+	ResultReasonInvalidField              ResultReason = 0x07
+	ResultReasonFeatureNotSupported       ResultReason = 0x08
+	ResultReasonCryptographicFailure      ResultReason = 0x0A
+	ResultReasonPermissionDenied          ResultReason = 0x0C
+	ResultReasonKeyFormatTypeNotSupported ResultReason = 0x10
+	ResultReasonGeneralFailure            ResultReason = 0x100
+)
+
+// BatchErrorContinuationOption is the value of a TagBatchErrorContinuationOption item.
+type BatchErrorContinuationOption uint32
+
+// Batch error continuation options of the KMIP specification.
+const (
+	BatchErrorContinuationOptionContinue BatchErrorContinuationOption = 0x01
+	BatchErrorContinuationOptionStop     BatchErrorContinuationOption = 0x02
+	BatchErrorContinuationOptionUndo     BatchErrorContinuationOption = 0x03
+)
+
+// ObjectType is the value of a TagObjectType item.
+type ObjectType uint32
+
+// ObjectTypeSymmetricKey is the only object type Contrast serves.
+const ObjectTypeSymmetricKey ObjectType = 0x02
+
+// CryptographicAlgorithm is the value of a TagCryptographicAlgorithm item.
+type CryptographicAlgorithm uint32
+
+// CryptographicAlgorithmAES is the only algorithm Contrast serves.
+const CryptographicAlgorithmAES CryptographicAlgorithm = 0x03
+
+// KeyFormatType is the value of a TagKeyFormatType item.
+type KeyFormatType uint32
+
+// KeyFormatTypeRaw is the only key format Contrast serves.
+const KeyFormatTypeRaw KeyFormatType = 0x01
+
+// BlockCipherMode is the value of a TagBlockCipherMode item.
+type BlockCipherMode uint32
+
+// BlockCipherModeGCM is the only block cipher mode Contrast serves.
+const BlockCipherModeGCM BlockCipherMode = 0x09
+
+// NameType is the value of a TagNameType item.
+type NameType uint32
+
+// NameTypeUninterpretedTextString is the name type of human-readable names.
+const NameTypeUninterpretedTextString NameType = 0x01
@@ -0,0 +1,343 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package kmip
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// headerSize is the size of the tag, type and length of a TTLV item.
+	headerSize = 8
+	// maxDepth limits the nesting of structures in a decoded message.
+	maxDepth = 32
+)
+
+// ErrMessageTooLarge is returned by ReadMessage if a message exceeds the maximum size.
+var ErrMessageTooLarge = errors.New("KMIP message too large")
+
+// Item is a TTLV item. The type of Value depends on Type:
+//
+//   - TypeStructure: []Item
+//   - TypeInteger: int32
+//   - TypeLongInteger: int64
+//   - TypeBigInteger: []byte, the big-endian two's complement representation
+//   - TypeEnumeration, TypeInterval: uint32
+//   - TypeBoolean: bool
+//   - TypeTextString: string
+//   - TypeByteString: []byte
+//   - TypeDateTime: time.Time
+type Item struct {
+	Tag   Tag
+	Type  Type
+	Value any
+}
+
+// NewStructure returns a structure item with the given children.
+func NewStructure(tag Tag, children ...Item) Item {
+	return Item{Tag: tag, Type: TypeStructure, Value: children}
+}
+
+// NewInteger returns an integer item.
+func NewInteger(tag Tag, value int32) Item {
+	return Item{Tag: tag, Type: TypeInteger, Value: value}
+}
+
+// NewEnumeration returns an enumeration item.
+func NewEnumeration[E ~uint32](tag Tag, value E) Item {
+	return Item{Tag: tag, Type: TypeEnumeration, Value: uint32(value)}
+}
+
+// NewTextString returns a text string item.
+func NewTextString(tag Tag, value string) Item {
+	return Item{Tag: tag, Type: TypeTextString, Value: value}
+}
+
+// NewByteString returns a byte string item.
+func NewByteString(tag Tag, value []byte) Item {
+	return Item{Tag: tag, Type: TypeByteString, Value: value}
+}
+
+// NewDateTime returns a date-time item. The time is truncated to seconds.
+func NewDateTime(tag Tag, value time.Time) Item {
+	return Item{Tag: tag, Type: TypeDateTime, Value: value.Truncate(time.Second)}
+}
+
+// Children returns the children of a structure item, or nil for other items.
+func (i Item) Children() []Item {
+	children, _ := i.Value.([]Item)
+	return children
+}
+
+// Child returns the first child of a structure item with the given tag.
+func (i Item) Child(tag Tag) (Item, bool) {
+	for _, child := range i.Children() {
+		if child.Tag == tag {
+			return child, true
+		}
+	}
+	return Item{}, false
+}
+
+// ChildrenWithTag returns all children of a structure item with the given tag.
+func (i Item) ChildrenWithTag(tag Tag) []Item {
+	var children []Item
+	for _, child := range i.Children() {
+		if child.Tag == tag {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// Integer returns the value of an integer item.
+func (i Item) Integer() (int32, error) {
+	v, ok := i.Value.(int32)
+	if i.Type != TypeInteger || !ok {
+		return 0, i.typeError(TypeInteger)
+	}
+	return v, nil
+}
+
+// Enumeration returns the value of an enumeration item.
+func (i Item) Enumeration() (uint32, error) {
+	v, ok := i.Value.(uint32)
+	if i.Type != TypeEnumeration || !ok {
+		return 0, i.typeError(TypeEnumeration)
+	}
+	return v, nil
+}
+
+// TextString returns the value of a text string item.
+func (i Item) TextString() (string, error) {
+	v, ok := i.Value.(string)
+	if i.Type != TypeTextString || !ok {
+		return "", i.typeError(TypeTextString)
+	}
+	return v, nil
+}
+
+// ByteString returns the value of a byte string item.
+func (i Item) ByteString() ([]byte, error) {
+	v, ok := i.Value.([]byte)
+	if i.Type != TypeByteString || !ok {
+		return nil, i.typeError(TypeByteString)
+	}
+	return v, nil
+}
+
+func (i Item) typeError(want Type) error {
+	return fmt.Errorf("item with tag %#06x has type %#02x, expected %#02x", uint32(i.Tag), uint8(i.Type), uint8(want))
+}
+
+// Marshal encodes an item in TTLV.
+func Marshal(item Item) ([]byte, error) {
+	return appendItem(nil, item)
+}
+
+func appendItem(b []byte, item Item) ([]byte, error) {
+	if item.Tag>>24 != 0 {
+		return nil, fmt.Errorf("tag %#x exceeds 3 bytes", uint32(item.Tag))
+	}
+	b = append(b, byte(item.Tag>>16), byte(item.Tag>>8), byte(item.Tag), byte(item.Type))
+	lengthOffset := len(b)
+	b = append(b, 0, 0, 0, 0)
+
+	var err error
+	switch v := item.Value.(type) {
+	case []Item:
+		if item.Type != TypeStructure {
+			return nil, item.typeError(TypeStructure)
+		}
+		for _, child := range v {
+			if b, err = appendItem(b, child); err != nil {
+				return nil, err
+			}
+		}
+	case int32:
+		if item.Type != TypeInteger {
+			return nil, item.typeError(TypeInteger)
+		}
+		b = binary.BigEndian.AppendUint32(b, uint32(v))
+	case int64:
+		if item.Type != TypeLongInteger {
+			return nil, item.typeError(TypeLongInteger)
+		}
+		b = binary.BigEndian.AppendUint64(b, uint64(v))
+	case uint32:
+		if item.Type != TypeEnumeration && item.Type != TypeInterval {
+			return nil, item.typeError(TypeEnumeration)
+		}
+		b = binary.BigEndian.AppendUint32(b, v)
+	case bool:
+		if item.Type != TypeBoolean {
+			return nil, item.typeError(TypeBoolean)
+		}
+		var n uint64
+		if v {
+			n = 1
+		}
+		b = binary.BigEndian.AppendUint64(b, n)
+	case string:
+		if item.Type != TypeTextString {
+			return nil, item.typeError(TypeTextString)
+		}
+		b = append(b, v...)
+	case []byte:
+		if item.Type != TypeByteString && item.Type != TypeBigInteger {
+			return nil, item.typeError(TypeByteString)
+		}
+		if item.Type == TypeBigInteger && len(v)%8 != 0 {
+			return nil, fmt.Errorf("big integer with tag %#06x has length %d, must be a multiple of 8", uint32(item.Tag), len(v))
+		}
+		b = append(b, v...)
+	case time.Time:
+		if item.Type != TypeDateTime {
+			return nil, item.typeError(TypeDateTime)
+		}
+		b = binary.BigEndian.AppendUint64(b, uint64(v.Unix()))
+	default:
+		return nil, fmt.Errorf("item with tag %#06x has unsupported value type %T", uint32(item.Tag), item.Value)
+	}
+
+	length := len(b) - lengthOffset - 4
+	if length > 1<<31 {
+		return nil, fmt.Errorf("item with tag %#06x is too large", uint32(item.Tag))
+	}
+	binary.BigEndian.PutUint32(b[lengthOffset:], uint32(length))
+	return append(b, make([]byte, padding(length))...), nil
+}
+
+// Unmarshal decodes a single TTLV item, which must span all of data.
+func Unmarshal(data []byte) (Item, error) {
+	item, rest, err := decodeItem(data, 0)
+	if err != nil {
+		return Item{}, err
+	}
+	if len(rest) != 0 {
+		return Item{}, fmt.Errorf("%d trailing bytes after item", len(rest))
+	}
+	return item, nil
+}
+
+func decodeItem(data []byte, depth int) (Item, []byte, error) {
+	if depth > maxDepth {
+		return Item{}, nil, fmt.Errorf("structures are nested deeper than %d levels", maxDepth)
+	}
+	if len(data) < headerSize {
+		return Item{}, nil, io.ErrUnexpectedEOF
+	}
+	item := Item{
+		Tag:  Tag(uint32(data[0])<<16 | uint32(data[1])<<8 | uint32(data[2])),
+		Type: Type(data[3]),
+	}
+	length := binary.BigEndian.Uint32(data[4:headerSize])
+	data = data[headerSize:]
+	if uint64(length)+uint64(padding(int(length))) > uint64(len(data)) {
+		return Item{}, nil, fmt.Errorf("item with tag %#06x: %w", uint32(item.Tag), io.ErrUnexpectedEOF)
+	}
+	value := data[:length]
+	rest := data[int(length)+padding(int(length)):]
+
+	checkLength := func(want uint32) error {
+		if length != want {
+			return fmt.Errorf("item with tag %#06x and type %#02x has length %d, expected %d", uint32(item.Tag), uint8(item.Type), length, want)
+		}
+		return nil
+	}
+	switch item.Type {
+	case TypeStructure:
+		children := []Item{}
+		for len(value) > 0 {
+			var child Item
+			var err error
+			child, value, err = decodeItem(value, depth+1)
+			if err != nil {
+				return Item{}, nil, err
+			}
+			children = append(children, child)
+		}
+		item.Value = children
+	case TypeInteger:
+		if err := checkLength(4); err != nil {
+			return Item{}, nil, err
+		}
+		item.Value = int32(binary.BigEndian.Uint32(value))
+	case TypeLongInteger:
+		if err := checkLength(8); err != nil {
+			return Item{}, nil, err
+		}
+		item.Value = int64(binary.BigEndian.Uint64(value))
+	case TypeEnumeration, TypeInterval:
+		if err := checkLength(4); err != nil {
+			return Item{}, nil, err
+		}
+		item.Value = binary.BigEndian.Uint32(value)
+	case TypeBoolean:
+		if err := checkLength(8); err != nil {
+			return Item{}, nil, err
+		}
+		switch binary.BigEndian.Uint64(value) {
+		case 0:
+			item.Value = false
+		case 1:
+			item.Value = true
+		default:
+			return Item{}, nil, fmt.Errorf("boolean with tag %#06x is neither 0 nor 1", uint32(item.Tag))
+		}
+	case TypeTextString:
+		if !utf8.Valid(value) {
+			return Item{}, nil, fmt.Errorf("text string with tag %#06x isn't valid UTF-8", uint32(item.Tag))
+		}
+		item.Value = string(value)
+	case TypeByteString:
+		item.Value = append([]byte{}, value...)
+	case TypeBigInteger:
+		if length%8 != 0 {
+			return Item{}, nil, fmt.Errorf("big integer with tag %#06x has length %d, must be a multiple of 8", uint32(item.Tag), length)
+		}
+		item.Value = append([]byte{}, value...)
+	case TypeDateTime:
+		if err := checkLength(8); err != nil {
+			return Item{}, nil, err
+		}
+		item.Value = time.Unix(int64(binary.BigEndian.Uint64(value)), 0).UTC()
+	default:
+		return Item{}, nil, fmt.Errorf("item with tag %#06x has unknown type %#02x", uint32(item.Tag), uint8(item.Type))
+	}
+	return item, rest, nil
+}
+
+// ReadMessage reads a single TTLV-encoded message from r. Messages larger than maxSize bytes
+// are rejected with ErrMessageTooLarge before their value is read.
+func ReadMessage(r io.Reader, maxSize int) ([]byte, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	length := int64(binary.BigEndian.Uint32(header[4:]))
+	length += int64(padding(int(length)))
+	if headerSize+length > int64(maxSize) {
+		return nil, ErrMessageTooLarge
+	}
+	msg := make([]byte, headerSize+length)
+	copy(msg, header)
+	if _, err := io.ReadFull(r, msg[headerSize:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return msg, nil
+}
+
+// padding returns the number of bytes needed to align a value of the given length to 8 bytes.
+func padding(length int) int {
+	return (8 - length%8) % 8
+}
@@ -0,0 +1,179 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package kmip
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The test vectors are the examples of section 9.1.2 of the KMIP 1.4 specification.
+func TestMarshalUnmarshal(t *testing.T) {
+	testCases := map[string]struct {
+		item Item
+		enc  string
+	}{
+		"integer": {
+			item: NewInteger(0x420020, 8),
+			enc:  "420020 02 00000004 0000000800000000",
+		},
+		"long integer": {
+			item: Item{Tag: 0x420020, Type: TypeLongInteger, Value: int64(123456789000000000)},
+			enc:  "420020 03 00000008 01B69B4BA5749200",
+		},
+		"big integer": {
+			item: Item{Tag: 0x420020, Type: TypeBigInteger, Value: []byte{0, 0, 0, 0, 0x03, 0xfd, 0x35, 0xeb, 0x6b, 0xc2, 0xdf, 0x46, 0x18, 0x08, 0x00, 0x00}},
+			enc:  "420020 04 00000010 0000000003FD35EB6BC2DF4618080000",
+		},
+		"enumeration": {
+			item: NewEnumeration(0x420020, uint32(255)),
+			enc:  "420020 05 00000004 000000FF00000000",
+		},
+		"boolean": {
+			item: Item{Tag: 0x420020, Type: TypeBoolean, Value: true},
+			enc:  "420020 06 00000008 0000000000000001",
+		},
+		"text string": {
+			item: NewTextString(0x420020, "Hello World"),
+			enc:  "420020 07 0000000B 48656C6C6F20576F726C640000000000",
+		},
+		"byte string": {
+			item: NewByteString(0x420020, []byte{1, 2, 3}),
+			enc:  "420020 08 00000003 0102030000000000",
+		},
+		"date-time": {
+			item: NewDateTime(0x420020, time.Date(2008, time.March, 14, 11, 56, 40, 0, time.UTC)),
+			enc:  "420020 09 00000008 0000000047DA67F8",
+		},
+		"interval": {
+			item: Item{Tag: 0x420020, Type: TypeInterval, Value: uint32(864000)},
+			enc:  "420020 0A 00000004 000D2F0000000000",
+		},
+		"structure": {
+			item: NewStructure(0x420020,
+				NewEnumeration(0x420004, uint32(254)),
+				NewInteger(0x420005, 255),
+			),
+			enc: "420020 01 00000020 420004 05 00000004 000000FE00000000 420005 02 00000004 000000FF00000000",
+		},
+		"empty structure": {
+			item: NewStructure(0x420020),
+			enc:  "420020 01 00000000",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+
+			enc, err := hex.DecodeString(strings.ReplaceAll(tc.enc, " ", ""))
+			require.NoError(err)
+
+			got, err := Marshal(tc.item)
+			require.NoError(err)
+			assert.Equal(enc, got)
+
+			item, err := Unmarshal(enc)
+			require.NoError(err)
+			if tc.item.Type == TypeStructure && len(tc.item.Children()) == 0 {
+				tc.item.Value = []Item{}
+			}
+			assert.Equal(tc.item, item)
+		})
+	}
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	testCases := map[string]string{
+		"empty":                  "",
+		"short header":           "420020 02 0000",
+		"short value":            "420020 02 00000004 00000008",
+		"missing padding":        "420020 07 00000001 41",
+		"wrong integer length":   "420020 02 00000008 0000000000000008",
+		"invalid boolean":        "420020 06 00000008 0000000000000002",
+		"invalid UTF-8":          "420020 07 00000001 FF00000000000000",
+		"unknown type":           "420020 0B 00000000",
+		"truncated child":        "420020 01 00000008 420004 05 00000004",
+		"trailing bytes":         "420020 01 00000000 420020 01 00000000",
+		"big integer not padded": "420020 04 00000004 00000001 00000000",
+	}
+
+	for name, enc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			data, err := hex.DecodeString(strings.ReplaceAll(enc, " ", ""))
+			require.NoError(t, err)
+
+			_, err = Unmarshal(data)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestUnmarshalNesting(t *testing.T) {
+	item := NewStructure(TagRequestMessage)
+	for range maxDepth + 1 {
+		item = NewStructure(TagRequestMessage, item)
+	}
+	data, err := Marshal(item)
+	require.NoError(t, err)
+
+	_, err = Unmarshal(data)
+	assert.Error(t, err)
+}
+
+func TestReadMessage(t *testing.T) {
+	msg, err := Marshal(NewStructure(TagRequestMessage, NewTextString(TagUniqueIdentifier, "abc")))
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		data    []byte
+		maxSize int
+		wantErr error
+	}{
+		"message": {
+			data:    msg,
+			maxSize: len(msg),
+		},
+		"message followed by next message": {
+			data:    append(append([]byte{}, msg...), msg...),
+			maxSize: len(msg),
+		},
+		"too large": {
+			data:    msg,
+			maxSize: len(msg) - 1,
+			wantErr: ErrMessageTooLarge,
+		},
+		"truncated": {
+			data:    msg[:len(msg)-1],
+			maxSize: len(msg),
+			wantErr: io.ErrUnexpectedEOF,
+		},
+		"empty": {
+			maxSize: len(msg),
+			wantErr: io.EOF,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			got, err := ReadMessage(bytes.NewReader(tc.data), tc.maxSize)
+			if tc.wantErr != nil {
+				assert.ErrorIs(err, tc.wantErr)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(msg, got)
+		})
+	}
+}
@@ -17,6 +17,11 @@ const (
 	// ExposeServiceAnnotationKey is the annotation key used to specify whether a Service should be exposed via a LoadBalancer.
 	ExposeServiceAnnotationKey = annotationPrefix + "expose-service"
 
+	// MeshCertLifetimeAnnotationKey is the annotation key used to specify the lifetime of mesh certificates for a pod.
+	//
+	// The value is a Go duration string, like "24h". The initializer renews the certificate before it expires.
+	MeshCertLifetimeAnnotationKey = annotationPrefix + "mesh-cert-lifetime"
+
 	// ImageStoreSizeAnnotationKey is the annotation key used to configure the size of the image store volume.
 	ImageStoreSizeAnnotationKey = annotationPrefix + "image-store-size"
 
@@ -26,6 +31,11 @@ const (
 	// SkipInitializerAnnotationKey is the annotation key used to specify whether a pod should skip the Contrast initializer injection.
 	SkipInitializerAnnotationKey = annotationPrefix + "skip-initializer"
 
+	// SPIFFEWorkloadAPIAnnotationKey is the annotation key used to enable the SPIFFE Workload API for a pod.
+	//
+	// If set to "true", the initializer keeps running and serves the mesh certificate as X.509-SVID on a Unix socket.
+	SPIFFEWorkloadAPIAnnotationKey = annotationPrefix + "spiffe-workload-api"
+
 	// SmAdminInterfaceAnnotationKey is the annotation key used to specify the port of the service mesh admin interface.
 	SmAdminInterfaceAnnotationKey = annotationPrefix + "servicemesh-admin-interface-port"
 
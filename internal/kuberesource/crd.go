@@ -0,0 +1,84 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package kuberesource
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// ContrastHistoryKind is the kind of the custom resource used by the Coordinator to persist its history.
+	ContrastHistoryKind   = "ContrastHistory"
+	contrastHistoryPlural = "contrasthistories"
+)
+
+// ContrastHistoryGVR identifies the ContrastHistory custom resource.
+var ContrastHistoryGVR = schema.GroupVersionResource{
+	Group:    "contrast.edgeless.systems",
+	Version:  "v1alpha1",
+	Resource: contrastHistoryPlural,
+}
+
+// ContrastHistoryCRD returns the CustomResourceDefinition for the ContrastHistory resource.
+//
+// The CRD only needs to be installed if the Coordinator is configured to
+// use the ContrastHistory history store.
+func ContrastHistoryCRD() *unstructured.Unstructured {
+	labels := map[string]any{}
+	for k, v := range ContrastLabels("kvstore", "coordinator") {
+		labels[k] = v
+	}
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata": map[string]any{
+			"name":   contrastHistoryPlural + "." + ContrastHistoryGVR.Group,
+			"labels": labels,
+		},
+		"spec": map[string]any{
+			"group": ContrastHistoryGVR.Group,
+			"scope": "Namespaced",
+			"names": map[string]any{
+				"kind":     ContrastHistoryKind,
+				"listKind": ContrastHistoryKind + "List",
+				"plural":   contrastHistoryPlural,
+				"singular": "contrasthistory",
+			},
+			"versions": []any{
+				map[string]any{
+					"name":    ContrastHistoryGVR.Version,
+					"served":  true,
+					"storage": true,
+					"schema": map[string]any{
+						"openAPIV3Schema": map[string]any{
+							"type":     "object",
+							"required": []any{"spec"},
+							"properties": map[string]any{
+								"spec": map[string]any{
+									"type": "object",
+									"properties": map[string]any{
+										"key": map[string]any{
+											"type": "string",
+										},
+										"data": map[string]any{
+											"type":   "string",
+											"format": "byte",
+										},
+										"chunks": map[string]any{
+											"type": "array",
+											"items": map[string]any{
+												"type": "string",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}}
+}
@@ -71,6 +71,13 @@ func AddInitializer(
 			}
 			initializer = addCryptsetupConfig(initializer, devName, mountName)
 		}
+		spiffeWorkloadAPI := meta != nil && meta.Annotations[SPIFFEWorkloadAPIAnnotationKey] == "true"
+		if meta != nil && meta.Annotations[MeshCertLifetimeAnnotationKey] != "" || spiffeWorkloadAPI {
+			initializer = addMeshCertRenewalConfig(initializer)
+		}
+		if spiffeWorkloadAPI {
+			initializer = initializer.WithEnv(NewEnvVar(constants.SPIFFEWorkloadAPIEnvVar, "true"))
+		}
 
 		if !needsServiceMesh(meta) {
 			initializer.Env = append(initializer.Env, *NewEnvVar(constants.DisableServiceMeshEnvVar, "true"))
@@ -100,6 +107,9 @@ func AddInitializer(
 
 		for i := range spec.Containers {
 			addOrReplaceVolumeMount(&spec.Containers[i], roVolumeMount)
+			if spiffeWorkloadAPI {
+				setEnv(&spec.Containers[i], constants.SPIFFEEndpointSocketEnvVar, "unix://"+constants.SPIFFEWorkloadAPISocketPath)
+			}
 		}
 
 		for i := range spec.InitContainers {
@@ -150,6 +160,24 @@ func addCryptsetupConfig(initializer *applycorev1.ContainerApplyConfiguration, d
 		)
 }
 
+// addMeshCertRenewalConfig turns the initializer into a sidecar that renews the mesh certificate.
+func addMeshCertRenewalConfig(initializer *applycorev1.ContainerApplyConfiguration) *applycorev1.ContainerApplyConfiguration {
+	return initializer.
+		WithEnv(NewEnvVar(constants.MeshCertRenewalEnvVar, "true")).
+		WithStartupProbe(
+			Probe().
+				WithFailureThreshold(20).
+				WithPeriodSeconds(5).
+				WithExec(
+					applycorev1.ExecAction().
+						WithCommand("/bin/test", "-f", "/done"),
+				),
+		).
+		WithRestartPolicy(
+			corev1.ContainerRestartPolicyAlways,
+		)
+}
+
 func addOrReplaceVolumeMount(container *applycorev1.ContainerApplyConfiguration, volumeMount applycorev1.VolumeMountApplyConfiguration) {
 	// Remove already existing volume mounts on the worker containers with unique volume mount name.
 	container.VolumeMounts = slices.DeleteFunc(container.VolumeMounts, func(v applycorev1.VolumeMountApplyConfiguration) bool {
@@ -6,6 +6,7 @@ package kuberesource
 import (
 	_ "embed"
 	"fmt"
+	"slices"
 	"strings"
 	"testing"
 
@@ -313,6 +314,32 @@ func TestAddInitializer(t *testing.T) {
 						))),
 			wantError: true,
 		},
+		{
+			name: "mesh cert renewal",
+			d: applyappsv1.Deployment("test", "default").
+				WithSpec(applyappsv1.DeploymentSpec().
+					WithTemplate(applycorev1.PodTemplateSpec().
+						WithAnnotations(map[string]string{MeshCertLifetimeAnnotationKey: "24h"}).
+						WithSpec(
+							applycorev1.PodSpec().
+								WithContainers(applycorev1.Container()).
+								WithRuntimeClassName("contrast-cc"),
+						))),
+			wantError: false,
+		},
+		{
+			name: "spiffe workload api",
+			d: applyappsv1.Deployment("test", "default").
+				WithSpec(applyappsv1.DeploymentSpec().
+					WithTemplate(applycorev1.PodTemplateSpec().
+						WithAnnotations(map[string]string{SPIFFEWorkloadAPIAnnotationKey: "true"}).
+						WithSpec(
+							applycorev1.PodSpec().
+								WithContainers(applycorev1.Container()).
+								WithRuntimeClassName("contrast-cc"),
+						))),
+			wantError: false,
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			require := require.New(t)
@@ -343,6 +370,30 @@ func TestAddInitializer(t *testing.T) {
 				require.Equal(mountName, *tc.d.Spec.Template.Spec.InitContainers[0].VolumeMounts[1].Name)
 			}
 
+			templateMeta := tc.d.Spec.Template.ObjectMetaApplyConfiguration
+			if templateMeta != nil && templateMeta.Annotations[MeshCertLifetimeAnnotationKey] != "" {
+				renewingInitializer := tc.d.Spec.Template.Spec.InitContainers[0]
+				require.NotNil(renewingInitializer.RestartPolicy)
+				assert.Equal(corev1.ContainerRestartPolicyAlways, *renewingInitializer.RestartPolicy)
+				assert.True(slices.ContainsFunc(renewingInitializer.Env, func(e applycorev1.EnvVarApplyConfiguration) bool {
+					return *e.Name == constants.MeshCertRenewalEnvVar
+				}))
+			}
+
+			if templateMeta != nil && templateMeta.Annotations[SPIFFEWorkloadAPIAnnotationKey] == "true" {
+				spiffeInitializer := tc.d.Spec.Template.Spec.InitContainers[0]
+				require.NotNil(spiffeInitializer.RestartPolicy)
+				assert.Equal(corev1.ContainerRestartPolicyAlways, *spiffeInitializer.RestartPolicy)
+				assert.True(slices.ContainsFunc(spiffeInitializer.Env, func(e applycorev1.EnvVarApplyConfiguration) bool {
+					return *e.Name == constants.SPIFFEWorkloadAPIEnvVar
+				}))
+				for _, c := range tc.d.Spec.Template.Spec.Containers {
+					assert.True(slices.ContainsFunc(c.Env, func(e applycorev1.EnvVarApplyConfiguration) bool {
+						return *e.Name == constants.SPIFFEEndpointSocketEnvVar
+					}))
+				}
+			}
+
 			initializerCount := 0
 			for _, c := range tc.d.Spec.Template.Spec.InitContainers {
 				if c.Name != nil && *c.Name == expectedInitializerContainerName {
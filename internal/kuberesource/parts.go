@@ -341,6 +341,9 @@ func Coordinator(namespace string) *CoordinatorConfig {
 											ContainerPort().
 												WithName("transitapi").
 												WithContainerPort(8200),
+											ContainerPort().
+												WithName("kmip").
+												WithContainerPort(5696),
 										).
 										WithStartupProbe(
 											Probe().
@@ -401,7 +404,11 @@ func Coordinator(namespace string) *CoordinatorConfig {
 			applyrbacv1.PolicyRule().
 				WithAPIGroups("").
 				WithResources("configmaps").
-				WithVerbs("get", "create", "update", "watch"),
+				WithVerbs("get", "create", "update", "delete", "watch"),
+			applyrbacv1.PolicyRule().
+				WithAPIGroups(ContrastHistoryGVR.Group).
+				WithResources(ContrastHistoryGVR.Resource).
+				WithVerbs("get", "create", "update", "delete", "watch"),
 			applyrbacv1.PolicyRule().
 				WithAPIGroups("").
 				WithResources("pods").
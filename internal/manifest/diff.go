@@ -0,0 +1,149 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// Diff describes the changes between two manifests.
+//
+// Reference values don't have a natural identity, so they are compared by their JSON encoding:
+// a reference value that changed in any field shows up as removed and added.
+type Diff struct {
+	// AddedPolicies are the policy hashes only present in the new manifest.
+	AddedPolicies []HexString
+	// RemovedPolicies are the policy hashes only present in the old manifest.
+	RemovedPolicies []HexString
+	// ChangedPolicies are the policy hashes present in both manifests with differing entries.
+	ChangedPolicies []HexString
+
+	// AddedReferenceValues are the reference values only present in the new manifest.
+	AddedReferenceValues ReferenceValues
+	// RemovedReferenceValues are the reference values only present in the old manifest.
+	RemovedReferenceValues ReferenceValues
+
+	// AddedWorkloadOwnerPubKeys are the workload owner keys only present in the new manifest.
+	AddedWorkloadOwnerPubKeys []HexString
+	// RemovedWorkloadOwnerPubKeys are the workload owner keys only present in the old manifest.
+	RemovedWorkloadOwnerPubKeys []HexString
+
+	// AddedSeedshareOwnerPubKeys are the seedshare owner keys only present in the new manifest.
+	AddedSeedshareOwnerPubKeys []HexString
+	// RemovedSeedshareOwnerPubKeys are the seedshare owner keys only present in the old manifest.
+	RemovedSeedshareOwnerPubKeys []HexString
+}
+
+// NewDiff computes the changes from oldManifest to newManifest.
+//
+// A nil oldManifest is treated like an empty manifest. All slices in the result are sorted.
+func NewDiff(oldManifest, newManifest *Manifest) (*Diff, error) {
+	if oldManifest == nil {
+		oldManifest = &Manifest{}
+	}
+	if newManifest == nil {
+		newManifest = &Manifest{}
+	}
+
+	var d Diff
+	for hash, entry := range newManifest.Policies {
+		oldEntry, ok := oldManifest.Policies[hash]
+		switch {
+		case !ok:
+			d.AddedPolicies = append(d.AddedPolicies, hash)
+		case !reflect.DeepEqual(oldEntry, entry):
+			d.ChangedPolicies = append(d.ChangedPolicies, hash)
+		}
+	}
+	for hash := range oldManifest.Policies {
+		if _, ok := newManifest.Policies[hash]; !ok {
+			d.RemovedPolicies = append(d.RemovedPolicies, hash)
+		}
+	}
+	slices.Sort(d.AddedPolicies)
+	slices.Sort(d.RemovedPolicies)
+	slices.Sort(d.ChangedPolicies)
+
+	var err error
+	d.AddedReferenceValues.SNP, d.RemovedReferenceValues.SNP, err = diffByJSON(oldManifest.ReferenceValues.SNP, newManifest.ReferenceValues.SNP)
+	if err != nil {
+		return nil, fmt.Errorf("comparing SNP reference values: %w", err)
+	}
+	d.AddedReferenceValues.TDX, d.RemovedReferenceValues.TDX, err = diffByJSON(oldManifest.ReferenceValues.TDX, newManifest.ReferenceValues.TDX)
+	if err != nil {
+		return nil, fmt.Errorf("comparing TDX reference values: %w", err)
+	}
+
+	d.AddedWorkloadOwnerPubKeys, d.RemovedWorkloadOwnerPubKeys = diffKeys(oldManifest.WorkloadOwnerPubKeys, newManifest.WorkloadOwnerPubKeys)
+	d.AddedSeedshareOwnerPubKeys, d.RemovedSeedshareOwnerPubKeys = diffKeys(oldManifest.SeedshareOwnerPubKeys, newManifest.SeedshareOwnerPubKeys)
+
+	return &d, nil
+}
+
+// Empty returns true if the diff contains no changes.
+func (d *Diff) Empty() bool {
+	return len(d.AddedPolicies) == 0 &&
+		len(d.RemovedPolicies) == 0 &&
+		len(d.ChangedPolicies) == 0 &&
+		len(d.AddedReferenceValues.SNP)+len(d.AddedReferenceValues.TDX) == 0 &&
+		len(d.RemovedReferenceValues.SNP)+len(d.RemovedReferenceValues.TDX) == 0 &&
+		len(d.AddedWorkloadOwnerPubKeys) == 0 &&
+		len(d.RemovedWorkloadOwnerPubKeys) == 0 &&
+		len(d.AddedSeedshareOwnerPubKeys) == 0 &&
+		len(d.RemovedSeedshareOwnerPubKeys) == 0
+}
+
+// diffByJSON returns the elements of newVals not in oldVals and the elements of oldVals not in
+// newVals, comparing by JSON encoding. Duplicates are counted.
+func diffByJSON[T any](oldVals, newVals []T) (added, removed []T, err error) {
+	count := make(map[string]int)
+	for _, v := range oldVals {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		count[string(b)]++
+	}
+	for _, v := range newVals {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		if count[string(b)] > 0 {
+			count[string(b)]--
+			continue
+		}
+		added = append(added, v)
+	}
+	for _, v := range oldVals {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		if count[string(b)] > 0 {
+			count[string(b)]--
+			removed = append(removed, v)
+		}
+	}
+	return added, removed, nil
+}
+
+func diffKeys(oldKeys, newKeys []HexString) (added, removed []HexString) {
+	for _, k := range newKeys {
+		if !slices.Contains(oldKeys, k) {
+			added = append(added, k)
+		}
+	}
+	for _, k := range oldKeys {
+		if !slices.Contains(newKeys, k) {
+			removed = append(removed, k)
+		}
+	}
+	slices.Sort(added)
+	slices.Sort(removed)
+	return added, removed
+}
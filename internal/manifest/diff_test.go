@@ -0,0 +1,83 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package manifest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDiff(t *testing.T) {
+	const (
+		policyA = HexString("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa")
+		policyB = HexString("bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb")
+		policyC = HexString("cccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccc")
+	)
+
+	testCases := map[string]struct {
+		oldManifest *Manifest
+		newManifest *Manifest
+		wantDiff    *Diff
+	}{
+		"identical": {
+			oldManifest: newTestManifestSNP(),
+			newManifest: newTestManifestSNP(),
+			wantDiff:    &Diff{},
+		},
+		"initial manifest": {
+			newManifest: newTestManifestSNP(),
+			wantDiff: &Diff{
+				AddedPolicies:              []HexString{policyB},
+				AddedReferenceValues:       newTestManifestSNP().ReferenceValues,
+				AddedWorkloadOwnerPubKeys:  newTestManifestSNP().WorkloadOwnerPubKeys,
+				AddedSeedshareOwnerPubKeys: newTestManifestSNP().SeedshareOwnerPubKeys,
+			},
+		},
+		"policies": {
+			oldManifest: &Manifest{Policies: map[HexString]PolicyEntry{
+				policyA: {SANs: []string{"a"}},
+				policyB: {SANs: []string{"b"}},
+			}},
+			newManifest: &Manifest{Policies: map[HexString]PolicyEntry{
+				policyB: {SANs: []string{"b", "b2"}},
+				policyC: {SANs: []string{"c"}},
+			}},
+			wantDiff: &Diff{
+				AddedPolicies:   []HexString{policyC},
+				RemovedPolicies: []HexString{policyA},
+				ChangedPolicies: []HexString{policyB},
+			},
+		},
+		"reference values": {
+			oldManifest: newTestManifestSNP(),
+			newManifest: newTestManifestTDX(),
+			wantDiff: &Diff{
+				AddedReferenceValues:   ReferenceValues{TDX: newTestManifestTDX().ReferenceValues.TDX},
+				RemovedReferenceValues: ReferenceValues{SNP: newTestManifestSNP().ReferenceValues.SNP},
+			},
+		},
+		"workload owner keys": {
+			oldManifest: &Manifest{WorkloadOwnerPubKeys: []HexString{"01", "02"}},
+			newManifest: &Manifest{WorkloadOwnerPubKeys: []HexString{"03", "02"}},
+			wantDiff: &Diff{
+				AddedWorkloadOwnerPubKeys:   []HexString{"03"},
+				RemovedWorkloadOwnerPubKeys: []HexString{"01"},
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+
+			diff, err := NewDiff(tc.oldManifest, tc.newManifest)
+			require.NoError(err)
+			assert.Equal(tc.wantDiff, diff)
+			assert.Equal(tc.wantDiff.Empty(), diff.Empty())
+		})
+	}
+}
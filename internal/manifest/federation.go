@@ -0,0 +1,91 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package manifest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+
+	"github.com/edgelesssys/contrast/internal/atls/validators"
+	"github.com/edgelesssys/contrast/internal/attestation/certcache"
+)
+
+// FederatedDeployment is another Contrast deployment whose workloads are trusted by the workloads
+// of this deployment.
+//
+// The Coordinator attests the Coordinator of the federated deployment, checks that it serves the
+// expected root CA and adds its mesh CA to the trust bundle it hands out to workloads.
+type FederatedDeployment struct {
+	// CoordinatorEndpoint is the address of the user API of the federated deployment's
+	// Coordinator, as host and port.
+	CoordinatorEndpoint string
+	// CoordinatorPolicyHash is the expected policy hash of the federated deployment's Coordinator.
+	CoordinatorPolicyHash HexString
+	// RootCAFingerprint is the SHA-256 digest of the DER-encoded root CA certificate of the
+	// federated deployment. The root CA stays the same across manifest updates, so it pins the
+	// deployment independently of the Coordinator's policy.
+	RootCAFingerprint HexString
+	// ReferenceValues specifies the allowed TEE configurations of the federated deployment's
+	// Coordinator.
+	ReferenceValues ReferenceValues
+}
+
+// Validate checks the validity of all fields of the federated deployment.
+func (d FederatedDeployment) Validate() error {
+	var errs []error
+	if _, _, err := net.SplitHostPort(d.CoordinatorEndpoint); err != nil {
+		errs = append(errs, newValidationError("CoordinatorEndpoint", err))
+	}
+	if err := (PolicyEntry{Role: RoleCoordinator}).Validate(d.CoordinatorPolicyHash); err != nil {
+		errs = append(errs, newValidationError("CoordinatorPolicyHash", err))
+	}
+	if err := validateHexString(d.RootCAFingerprint, sha256.Size); err != nil {
+		errs = append(errs, newValidationError("RootCAFingerprint", err))
+	}
+	if err := d.ReferenceValues.Validate(); err != nil {
+		errs = append(errs, newValidationError("ReferenceValues", err))
+	}
+	coordinatorManifest := d.coordinatorManifest()
+	if coordinatorManifest.HasInsecurePlatforms() && coordinatorManifest.HasSecurePlatforms() {
+		errs = append(errs, newValidationError("ReferenceValues", errors.New("federated deployment must not mix secure and insecure platforms")))
+	}
+	return errors.Join(errs...)
+}
+
+// CoordinatorValidator returns a validator that succeeds only for the Coordinator of the federated
+// deployment.
+func (d FederatedDeployment) CoordinatorValidator(log *slog.Logger, kdsGetter *certcache.CachedHTTPSGetter) (validators.Validator, error) {
+	if err := d.Validate(); err != nil {
+		return nil, fmt.Errorf("validating federated deployment: %w", err)
+	}
+	return d.coordinatorManifest().CoordinatorValidator(log, kdsGetter)
+}
+
+// CheckRootCA checks that the given root CA certificate matches the RootCAFingerprint of the
+// federated deployment.
+func (d FederatedDeployment) CheckRootCA(rootCA *x509.Certificate) error {
+	want, err := d.RootCAFingerprint.Bytes()
+	if err != nil {
+		return fmt.Errorf("decoding root CA fingerprint: %w", err)
+	}
+	got := sha256.Sum256(rootCA.Raw)
+	if !bytes.Equal(got[:], want) {
+		return fmt.Errorf("root CA fingerprint %x doesn't match expected fingerprint %x", got, want)
+	}
+	return nil
+}
+
+// coordinatorManifest returns a manifest that only contains the Coordinator of the federated
+// deployment.
+func (d FederatedDeployment) coordinatorManifest() *Manifest {
+	return &Manifest{
+		Policies:        map[HexString]PolicyEntry{d.CoordinatorPolicyHash: {Role: RoleCoordinator}},
+		ReferenceValues: d.ReferenceValues,
+	}
+}
@@ -11,12 +11,16 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/edgelesssys/contrast/internal/attestation/certcache"
+	"github.com/edgelesssys/contrast/internal/cryptohelpers"
 	"github.com/edgelesssys/contrast/internal/idblock"
 	"github.com/edgelesssys/contrast/internal/platforms"
 	snpmeasure "github.com/edgelesssys/contrast/internal/snp"
+	"github.com/edgelesssys/contrast/internal/spiffe"
 	"github.com/google/go-sev-guest/kds"
 	snpvalidate "github.com/google/go-sev-guest/validate"
 	snpverify "github.com/google/go-sev-guest/verify"
@@ -35,8 +39,24 @@ type Manifest struct {
 	ReferenceValues ReferenceValues
 	// WorkloadOwnerPubKeys is a list of ECDSA public keys in PKIX DER format, hex-encoded.
 	WorkloadOwnerPubKeys []HexString
+	// WorkloadOwnerThreshold is the number of distinct workload owner keys that need to approve a
+	// manifest update. Zero is treated as one.
+	WorkloadOwnerThreshold int `json:",omitempty"`
 	// SeedshareOwnerPubKeys is a list of RSA public keys in PKCS1 DER format, hex-encoded.
 	SeedshareOwnerPubKeys []HexString
+	// SPIFFETrustDomain is the SPIFFE trust domain of the deployment. If set, mesh certificates
+	// carry the SPIFFE ID of the workload as URI SAN, and the mesh CA is served as SPIFFE trust
+	// bundle of this trust domain.
+	SPIFFETrustDomain string `json:",omitempty"`
+	// KeyAlgorithm is the algorithm of the Coordinator's CA keys and of the workload keys created
+	// by the initializer. If empty, CA keys use ECDSA-P384 and workload keys use ECDSA-P256.
+	KeyAlgorithm cryptohelpers.KeyAlgorithm `json:",omitempty"`
+	// FederatedDeployments are other Contrast deployments, keyed by name, whose workloads are
+	// trusted by the workloads of this deployment.
+	FederatedDeployments map[string]FederatedDeployment `json:",omitempty"`
+	// TransitKeys restricts the access to keys of the transit engine API, keyed by key name. Keys
+	// without an entry can only be used by workloads whose WorkloadSecretID equals the key name.
+	TransitKeys map[string]TransitKey `json:",omitempty"`
 }
 
 // Default returns a default manifest with reference values for the given platform.
@@ -101,14 +121,96 @@ func (m *Manifest) Validate() error {
 		}
 	}
 
+	if m.WorkloadOwnerThreshold < 0 || (m.WorkloadOwnerThreshold > 1 && m.WorkloadOwnerThreshold > len(m.WorkloadOwnerPubKeys)) {
+		errs = append(errs, newValidationError("WorkloadOwnerThreshold",
+			fmt.Errorf("threshold %d can't be reached with %d workload owner keys", m.WorkloadOwnerThreshold, len(m.WorkloadOwnerPubKeys))))
+	}
+
 	for i, key := range m.SeedshareOwnerPubKeys {
 		if _, err := ParseSeedShareOwnerKey(key); err != nil {
 			errs = append(errs, newValidationError(fmt.Sprintf("SeedshareOwnerPubKeys[%d]", i), err))
 		}
 	}
+
+	if m.KeyAlgorithm != "" {
+		if err := m.KeyAlgorithm.Validate(); err != nil {
+			errs = append(errs, newValidationError("KeyAlgorithm", err))
+		}
+	}
+
+	for name, deployment := range m.FederatedDeployments {
+		if name == "" {
+			errs = append(errs, newValidationError("FederatedDeployments", errors.New("deployment name must not be empty")))
+		}
+		if err := deployment.Validate(); err != nil {
+			errs = append(errs, newValidationError(fmt.Sprintf("FederatedDeployments[%q]", name), err))
+		}
+		if m.HasSecurePlatforms() && deployment.coordinatorManifest().HasInsecurePlatforms() {
+			errs = append(errs, newValidationError(fmt.Sprintf("FederatedDeployments[%q]", name),
+				errors.New("secure deployment must not federate with insecure deployment")))
+		}
+	}
+
+	for name, key := range m.TransitKeys {
+		if name == "" {
+			errs = append(errs, newValidationError("TransitKeys", errors.New("key name must not be empty")))
+		}
+		if err := key.Validate(m.Policies); err != nil {
+			errs = append(errs, newValidationError(fmt.Sprintf("TransitKeys[%q]", name), err))
+		}
+	}
+
+	if m.SPIFFETrustDomain != "" {
+		if err := spiffe.ValidateTrustDomain(m.SPIFFETrustDomain); err != nil {
+			errs = append(errs, newValidationError("SPIFFETrustDomain", err))
+		}
+		for policyHash, policy := range m.Policies {
+			if err := m.validateSPIFFEID(policy); err != nil {
+				errs = append(errs, newValidationError(fmt.Sprintf("Policies[%q]", policyHash), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SPIFFEID returns the SPIFFE ID of the workload with the given policy entry. The path of the ID
+// is the entry's SPIFFEPath, or /policy/<name> with the first SAN of the entry as name. It returns
+// an empty string if the manifest has no SPIFFE trust domain.
+func (m *Manifest) SPIFFEID(entry PolicyEntry) (string, error) {
+	if m.SPIFFETrustDomain == "" {
+		return "", nil
+	}
+	path := entry.SPIFFEPath
+	if path == "" {
+		if len(entry.SANs) == 0 {
+			return "", errors.New("policy entry has neither a SPIFFE path nor SANs to derive one from")
+		}
+		path = "/policy/" + entry.SANs[0]
+	}
+	return spiffe.ID(m.SPIFFETrustDomain, path)
+}
+
+func (m *Manifest) validateSPIFFEID(entry PolicyEntry) error {
+	var errs []error
+	if _, err := m.SPIFFEID(entry); err != nil {
+		errs = append(errs, newValidationError("SPIFFEPath", err))
+	}
+	// An X.509-SVID must contain exactly one URI SAN, the SPIFFE ID.
+	for i, san := range entry.SANs {
+		if uri, err := url.Parse(san); err == nil && uri.Scheme != "" {
+			errs = append(errs, newValidationError(fmt.Sprintf("SANs[%d]", i),
+				fmt.Errorf("URI SAN %q is not allowed together with a SPIFFE trust domain", san)))
+		}
+	}
 	return errors.Join(errs...)
 }
 
+// RequiredWorkloadOwnerApprovals returns the number of distinct workload owner keys that need to
+// approve an update of the manifest.
+func (m *Manifest) RequiredWorkloadOwnerApprovals() int {
+	return max(m.WorkloadOwnerThreshold, 1)
+}
+
 // CoordinatorPolicyHashes returns policy hashes for all workloads with role Coordinator.
 func (m *Manifest) CoordinatorPolicyHashes() ([]HexString, error) {
 	var all []HexString
@@ -395,6 +497,37 @@ type PolicyEntry struct {
 	SANs             []string
 	WorkloadSecretID string `json:",omitempty"`
 	Role             Role   `json:",omitempty"`
+	// MeshCertLifetime is the validity period of mesh certificates issued to the workload, as a
+	// Go duration string like "24h". If empty, mesh certificates are valid for one year.
+	MeshCertLifetime string `json:",omitempty"`
+	// SPIFFEPath is the path of the workload's SPIFFE ID, like "/ns/default/sa/web". It's only
+	// used if the manifest has a SPIFFE trust domain.
+	SPIFFEPath string `json:",omitempty"`
+	// MeshCertProfile holds additional content of the mesh certificates issued to the workload.
+	MeshCertProfile *MeshCertProfile `json:",omitempty"`
+}
+
+const (
+	// MinMeshCertLifetime is the shortest allowed PolicyEntry.MeshCertLifetime. It leaves the
+	// initializer enough time to renew the certificate.
+	MinMeshCertLifetime = 10 * time.Minute
+	// MaxMeshCertLifetime is the longest allowed PolicyEntry.MeshCertLifetime.
+	MaxMeshCertLifetime = 365 * 24 * time.Hour
+)
+
+// CertLifetime returns the parsed MeshCertLifetime, or zero if it's not set.
+func (e PolicyEntry) CertLifetime() (time.Duration, error) {
+	if e.MeshCertLifetime == "" {
+		return 0, nil
+	}
+	lifetime, err := time.ParseDuration(e.MeshCertLifetime)
+	if err != nil {
+		return 0, err
+	}
+	if lifetime < MinMeshCertLifetime || lifetime > MaxMeshCertLifetime {
+		return 0, fmt.Errorf("lifetime %s is not between %s and %s", lifetime, MinMeshCertLifetime, MaxMeshCertLifetime)
+	}
+	return lifetime, nil
 }
 
 // Validate checks the validity of a policy entry given its policy hash.
@@ -410,6 +543,22 @@ func (e PolicyEntry) Validate(policyHash HexString) error {
 		errs = append(errs, newValidationError("Role", err))
 	}
 
+	if _, err := e.CertLifetime(); err != nil {
+		errs = append(errs, newValidationError("MeshCertLifetime", err))
+	}
+
+	if e.SPIFFEPath != "" {
+		if err := spiffe.ValidatePath(e.SPIFFEPath); err != nil {
+			errs = append(errs, newValidationError("SPIFFEPath", err))
+		}
+	}
+
+	if e.MeshCertProfile != nil {
+		if err := e.MeshCertProfile.Validate(); err != nil {
+			errs = append(errs, newValidationError("MeshCertProfile", err))
+		}
+	}
+
 	return errors.Join(errs...)
 }
 
@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/edgelesssys/contrast/internal/cryptohelpers"
 	"github.com/google/go-sev-guest/abi"
 	"github.com/google/go-sev-guest/kds"
 	"github.com/stretchr/testify/assert"
@@ -72,6 +73,60 @@ func newTestManifestTDX() *Manifest {
 	return m
 }
 
+func newTestFederatedDeployment() FederatedDeployment {
+	return FederatedDeployment{
+		CoordinatorEndpoint:   "coordinator.example.com:1313",
+		CoordinatorPolicyHash: HexString("dddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddd"),
+		RootCAFingerprint:     HexString("eeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee"),
+		ReferenceValues:       newTestManifestSNP().ReferenceValues,
+	}
+}
+
+func TestSPIFFEID(t *testing.T) {
+	testCases := map[string]struct {
+		trustDomain string
+		entry       PolicyEntry
+		want        string
+		wantErr     bool
+	}{
+		"no trust domain": {
+			entry: PolicyEntry{SANs: []string{"web"}, SPIFFEPath: "/ns/default/sa/web"},
+		},
+		"explicit path": {
+			trustDomain: "example.org",
+			entry:       PolicyEntry{SANs: []string{"web"}, SPIFFEPath: "/ns/default/sa/web"},
+			want:        "spiffe://example.org/ns/default/sa/web",
+		},
+		"derived from SANs": {
+			trustDomain: "example.org",
+			entry:       PolicyEntry{SANs: []string{"web", "*"}},
+			want:        "spiffe://example.org/policy/web",
+		},
+		"no SANs": {
+			trustDomain: "example.org",
+			wantErr:     true,
+		},
+		"invalid SAN": {
+			trustDomain: "example.org",
+			entry:       PolicyEntry{SANs: []string{"*"}},
+			wantErr:     true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			m := &Manifest{SPIFFETrustDomain: tc.trustDomain}
+			id, err := m.SPIFFEID(tc.entry)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, id)
+		})
+	}
+}
+
 func TestValidate(t *testing.T) {
 	testCases := map[string]struct {
 		m       *Manifest
@@ -107,6 +162,212 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		"valid mesh cert lifetime": {
+			m: newTestManifestSNP(),
+			mutate: func(m *Manifest) {
+				policyHash := HexString("bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb")
+				policy := m.Policies[policyHash]
+				policy.MeshCertLifetime = "24h"
+				m.Policies[policyHash] = policy
+			},
+		},
+		"invalid mesh cert lifetime": {
+			m: newTestManifestSNP(),
+			mutate: func(m *Manifest) {
+				policyHash := HexString("bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb")
+				policy := m.Policies[policyHash]
+				policy.MeshCertLifetime = "one day"
+				m.Policies[policyHash] = policy
+			},
+			wantErr: true,
+		},
+		"mesh cert lifetime too short": {
+			m: newTestManifestSNP(),
+			mutate: func(m *Manifest) {
+				policyHash := HexString("bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb")
+				policy := m.Policies[policyHash]
+				policy.MeshCertLifetime = "1m"
+				m.Policies[policyHash] = policy
+			},
+			wantErr: true,
+		},
+		"valid key algorithm": {
+			m: newTestManifestSNP(),
+			mutate: func(m *Manifest) {
+				m.KeyAlgorithm = cryptohelpers.KeyAlgorithmRSA3072
+			},
+		},
+		"invalid key algorithm": {
+			m: newTestManifestSNP(),
+			mutate: func(m *Manifest) {
+				m.KeyAlgorithm = "RSA-1024"
+			},
+			wantErr: true,
+		},
+		"valid federated deployment": {
+			m: newTestManifestSNP(),
+			mutate: func(m *Manifest) {
+				m.FederatedDeployments = map[string]FederatedDeployment{"peer": newTestFederatedDeployment()}
+			},
+		},
+		"federated deployment without port": {
+			m: newTestManifestSNP(),
+			mutate: func(m *Manifest) {
+				d := newTestFederatedDeployment()
+				d.CoordinatorEndpoint = "coordinator.example.com"
+				m.FederatedDeployments = map[string]FederatedDeployment{"peer": d}
+			},
+			wantErr: true,
+		},
+		"federated deployment with invalid policy hash": {
+			m: newTestManifestSNP(),
+			mutate: func(m *Manifest) {
+				d := newTestFederatedDeployment()
+				d.CoordinatorPolicyHash = "bbbb"
+				m.FederatedDeployments = map[string]FederatedDeployment{"peer": d}
+			},
+			wantErr: true,
+		},
+		"federated deployment without root CA fingerprint": {
+			m: newTestManifestSNP(),
+			mutate: func(m *Manifest) {
+				d := newTestFederatedDeployment()
+				d.RootCAFingerprint = ""
+				m.FederatedDeployments = map[string]FederatedDeployment{"peer": d}
+			},
+			wantErr: true,
+		},
+		"federated deployment without reference values": {
+			m: newTestManifestSNP(),
+			mutate: func(m *Manifest) {
+				d := newTestFederatedDeployment()
+				d.ReferenceValues = ReferenceValues{}
+				m.FederatedDeployments = map[string]FederatedDeployment{"peer": d}
+			},
+			wantErr: true,
+		},
+		"federated deployment with empty name": {
+			m: newTestManifestSNP(),
+			mutate: func(m *Manifest) {
+				m.FederatedDeployments = map[string]FederatedDeployment{"": newTestFederatedDeployment()}
+			},
+			wantErr: true,
+		},
+		"insecure federated deployment": {
+			m: newTestManifestSNP(),
+			mutate: func(m *Manifest) {
+				d := newTestFederatedDeployment()
+				d.ReferenceValues = ReferenceValues{SNP: []SNPReferenceValues{{Platform: "Metal-QEMU-Insecure"}}}
+				m.FederatedDeployments = map[string]FederatedDeployment{"peer": d}
+			},
+			wantErr: true,
+		},
+		"valid transit key": {
+			m: newTestManifestSNP(),
+			mutate: func(m *Manifest) {
+				m.TransitKeys = map[string]TransitKey{"shared": {Grants: []TransitKeyGrant{{
+					WorkloadSecretIDs: []string{"foo"},
+					PolicyHashes:      []HexString{"bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"},
+					Operations:        []TransitOperation{TransitOperationEncrypt, TransitOperationDecrypt},
+				}}}}
+			},
+		},
+		"transit key with empty name": {
+			m: newTestManifestSNP(),
+			mutate: func(m *Manifest) {
+				m.TransitKeys = map[string]TransitKey{"": {Grants: []TransitKeyGrant{{
+					WorkloadSecretIDs: []string{"foo"},
+					Operations:        []TransitOperation{TransitOperationEncrypt},
+				}}}}
+			},
+			wantErr: true,
+		},
+		"transit key without grants": {
+			m: newTestManifestSNP(),
+			mutate: func(m *Manifest) {
+				m.TransitKeys = map[string]TransitKey{"shared": {}}
+			},
+			wantErr: true,
+		},
+		"transit key grant without workloads": {
+			m: newTestManifestSNP(),
+			mutate: func(m *Manifest) {
+				m.TransitKeys = map[string]TransitKey{"shared": {Grants: []TransitKeyGrant{{
+					Operations: []TransitOperation{TransitOperationEncrypt},
+				}}}}
+			},
+			wantErr: true,
+		},
+		"transit key grant with unknown policy hash": {
+			m: newTestManifestSNP(),
+			mutate: func(m *Manifest) {
+				m.TransitKeys = map[string]TransitKey{"shared": {Grants: []TransitKeyGrant{{
+					PolicyHashes: []HexString{"cccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccc"},
+					Operations:   []TransitOperation{TransitOperationEncrypt},
+				}}}}
+			},
+			wantErr: true,
+		},
+		"transit key grant with unknown operation": {
+			m: newTestManifestSNP(),
+			mutate: func(m *Manifest) {
+				m.TransitKeys = map[string]TransitKey{"shared": {Grants: []TransitKeyGrant{{
+					WorkloadSecretIDs: []string{"foo"},
+					Operations:        []TransitOperation{"export"},
+				}}}}
+			},
+			wantErr: true,
+		},
+		"transit key grant without operations": {
+			m: newTestManifestSNP(),
+			mutate: func(m *Manifest) {
+				m.TransitKeys = map[string]TransitKey{"shared": {Grants: []TransitKeyGrant{{
+					WorkloadSecretIDs: []string{"foo"},
+				}}}}
+			},
+			wantErr: true,
+		},
+		"valid SPIFFE trust domain": {
+			m: newTestManifestSNP(),
+			mutate: func(m *Manifest) {
+				m.SPIFFETrustDomain = "example.org"
+			},
+		},
+		"invalid SPIFFE trust domain": {
+			m: newTestManifestSNP(),
+			mutate: func(m *Manifest) {
+				m.SPIFFETrustDomain = "Example.org"
+			},
+			wantErr: true,
+		},
+		"invalid SPIFFE path": {
+			m: newTestManifestSNP(),
+			mutate: func(m *Manifest) {
+				m.Policies[HexString("bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb")] = PolicyEntry{
+					SPIFFEPath: "ns/default",
+				}
+			},
+			wantErr: true,
+		},
+		"no SPIFFE path derivable": {
+			m: newTestManifestSNP(),
+			mutate: func(m *Manifest) {
+				m.SPIFFETrustDomain = "example.org"
+				m.Policies[HexString("bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb")] = PolicyEntry{}
+			},
+			wantErr: true,
+		},
+		"URI SAN with SPIFFE trust domain": {
+			m: newTestManifestSNP(),
+			mutate: func(m *Manifest) {
+				m.SPIFFETrustDomain = "example.org"
+				m.Policies[HexString("bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb")] = PolicyEntry{
+					SANs:       []string{"spiffe://example.org/other"},
+					SPIFFEPath: "/ns/default/sa/web",
+				}
+			},
+			wantErr: true,
+		},
 		"trusted measurement empty": {
 			m: newTestManifestSNP(),
 			mutate: func(m *Manifest) {
@@ -136,6 +397,26 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		"workload owner threshold reachable": {
+			m: newTestManifestSNP(),
+			mutate: func(m *Manifest) {
+				m.WorkloadOwnerThreshold = 1
+			},
+		},
+		"workload owner threshold unreachable": {
+			m: newTestManifestSNP(),
+			mutate: func(m *Manifest) {
+				m.WorkloadOwnerThreshold = 2
+			},
+			wantErr: true,
+		},
+		"negative workload owner threshold": {
+			m: newTestManifestSNP(),
+			mutate: func(m *Manifest) {
+				m.WorkloadOwnerThreshold = -1
+			},
+			wantErr: true,
+		},
 		"invalid seedshare owner public key": {
 			m: newTestManifestSNP(),
 			mutate: func(m *Manifest) {
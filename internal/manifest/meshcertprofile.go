@@ -0,0 +1,226 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package manifest
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MeshCertProfile holds additional content of the mesh certificates issued to a workload.
+type MeshCertProfile struct {
+	// EmailAddresses are added to the certificate as email SANs.
+	EmailAddresses []string `json:",omitempty"`
+	// IPRanges restricts the IP addresses the Coordinator adds to the certificate as IP SANs. If
+	// set, the workload's IP address is only added if it's in one of the given CIDR ranges.
+	IPRanges []string `json:",omitempty"`
+	// Subject replaces the subject of the certificate. If its common name is empty, the first SAN
+	// is used as common name.
+	Subject *CertSubject `json:",omitempty"`
+	// ExtKeyUsages restricts the extended key usages of the certificate, which default to
+	// ServerAuth and ClientAuth. Supported values are ServerAuth, ClientAuth, CodeSigning,
+	// EmailProtection and TimeStamping.
+	ExtKeyUsages []string `json:",omitempty"`
+	// Extensions are added to the certificate as static extensions.
+	Extensions []CertExtension `json:",omitempty"`
+}
+
+// CertSubject is the subject of a mesh certificate.
+type CertSubject struct {
+	CommonName         string   `json:",omitempty"`
+	Organization       []string `json:",omitempty"`
+	OrganizationalUnit []string `json:",omitempty"`
+	Country            []string `json:",omitempty"`
+	Province           []string `json:",omitempty"`
+	Locality           []string `json:",omitempty"`
+}
+
+// CertExtension is a static X.509 extension of a mesh certificate.
+type CertExtension struct {
+	// OID is the object identifier of the extension in dotted notation, like "1.2.3.4".
+	OID string
+	// Critical marks the extension as critical. Verifiers that don't know a critical extension
+	// reject the certificate.
+	Critical bool `json:",omitempty"`
+	// Value is the DER-encoded value of the extension.
+	Value HexString
+}
+
+// extKeyUsages maps the supported names of extended key usages to their values. Usages that would
+// allow the workload to act on behalf of the mesh CA, like OCSPSigning, aren't supported.
+var extKeyUsages = map[string]x509.ExtKeyUsage{
+	"ServerAuth":      x509.ExtKeyUsageServerAuth,
+	"ClientAuth":      x509.ExtKeyUsageClientAuth,
+	"CodeSigning":     x509.ExtKeyUsageCodeSigning,
+	"EmailProtection": x509.ExtKeyUsageEmailProtection,
+	"TimeStamping":    x509.ExtKeyUsageTimeStamping,
+}
+
+// reservedExtensionArcs are the OID arcs of extensions that are set by the Coordinator. Overriding
+// them could turn the certificate into a CA certificate, or forge attestation claims.
+var reservedExtensionArcs = []asn1.ObjectIdentifier{
+	{2, 5, 29},   // X.509 certificate extensions, see RFC 5280, section 4.2.
+	{1, 3, 9901}, // Attestation and workload secret extensions, see package oid.
+}
+
+// maxCommonNameLength is the upper bound of the common name, see RFC 5280, appendix A.1.
+const maxCommonNameLength = 64
+
+// Validate checks the validity of the profile.
+func (p *MeshCertProfile) Validate() error {
+	var errs []error
+	for i, email := range p.EmailAddresses {
+		if addr, err := mail.ParseAddress(email); err != nil || addr.Name != "" || addr.Address != email {
+			errs = append(errs, newValidationError(fmt.Sprintf("EmailAddresses[%d]", i), fmt.Errorf("invalid email address %q", email)))
+		}
+	}
+	for i, ipRange := range p.IPRanges {
+		if _, err := netip.ParsePrefix(ipRange); err != nil {
+			errs = append(errs, newValidationError(fmt.Sprintf("IPRanges[%d]", i), err))
+		}
+	}
+	if p.Subject != nil {
+		if err := p.Subject.validate(); err != nil {
+			errs = append(errs, newValidationError("Subject", err))
+		}
+	}
+	for i, name := range p.ExtKeyUsages {
+		if _, ok := extKeyUsages[name]; !ok {
+			errs = append(errs, newValidationError(fmt.Sprintf("ExtKeyUsages[%d]", i), fmt.Errorf("unsupported extended key usage %q", name)))
+		}
+	}
+	seen := make(map[string]bool)
+	for i, ext := range p.Extensions {
+		if err := ext.validate(); err != nil {
+			errs = append(errs, newValidationError(fmt.Sprintf("Extensions[%d]", i), err))
+		} else if seen[ext.OID] {
+			errs = append(errs, newValidationError(fmt.Sprintf("Extensions[%d]", i), fmt.Errorf("duplicate extension %s", ext.OID)))
+		}
+		seen[ext.OID] = true
+	}
+	return errors.Join(errs...)
+}
+
+// AllowsIP reports whether the given IP address may be added to the certificate.
+func (p *MeshCertProfile) AllowsIP(ip netip.Addr) bool {
+	if len(p.IPRanges) == 0 {
+		return true
+	}
+	for _, ipRange := range p.IPRanges {
+		if prefix, err := netip.ParsePrefix(ipRange); err == nil && prefix.Contains(ip.Unmap()) {
+			return true
+		}
+	}
+	return false
+}
+
+// PKIXSubject returns the subject of the certificate. It's empty if the profile has no subject.
+func (p *MeshCertProfile) PKIXSubject() pkix.Name {
+	if p.Subject == nil {
+		return pkix.Name{}
+	}
+	return pkix.Name{
+		CommonName:         p.Subject.CommonName,
+		Organization:       p.Subject.Organization,
+		OrganizationalUnit: p.Subject.OrganizationalUnit,
+		Country:            p.Subject.Country,
+		Province:           p.Subject.Province,
+		Locality:           p.Subject.Locality,
+	}
+}
+
+// X509ExtKeyUsages returns the extended key usages of the certificate. It's empty if the profile
+// doesn't restrict them.
+func (p *MeshCertProfile) X509ExtKeyUsages() ([]x509.ExtKeyUsage, error) {
+	var usages []x509.ExtKeyUsage
+	for _, name := range p.ExtKeyUsages {
+		usage, ok := extKeyUsages[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported extended key usage %q", name)
+		}
+		usages = append(usages, usage)
+	}
+	return usages, nil
+}
+
+// PKIXExtensions returns the static extensions of the certificate.
+func (p *MeshCertProfile) PKIXExtensions() ([]pkix.Extension, error) {
+	var extensions []pkix.Extension
+	for _, ext := range p.Extensions {
+		id, err := parseOID(ext.OID)
+		if err != nil {
+			return nil, err
+		}
+		value, err := ext.Value.Bytes()
+		if err != nil {
+			return nil, fmt.Errorf("decoding value of extension %s: %w", ext.OID, err)
+		}
+		extensions = append(extensions, pkix.Extension{Id: id, Critical: ext.Critical, Value: value})
+	}
+	return extensions, nil
+}
+
+func (s *CertSubject) validate() error {
+	var errs []error
+	if len(s.CommonName) > maxCommonNameLength {
+		errs = append(errs, newValidationError("CommonName", fmt.Errorf("longer than %d characters", maxCommonNameLength)))
+	}
+	for i, country := range s.Country {
+		if len(country) != 2 || strings.ToUpper(country) != country {
+			errs = append(errs, newValidationError(fmt.Sprintf("Country[%d]", i), fmt.Errorf("%q is not a two-letter country code", country)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (e CertExtension) validate() error {
+	id, err := parseOID(e.OID)
+	if err != nil {
+		return newValidationError("OID", err)
+	}
+	for _, arc := range reservedExtensionArcs {
+		if len(id) >= len(arc) && slices.Equal(id[:len(arc)], arc) {
+			return newValidationError("OID", fmt.Errorf("extension %s is reserved", e.OID))
+		}
+	}
+	value, err := e.Value.Bytes()
+	if err != nil {
+		return newValidationError("Value", err)
+	}
+	var raw asn1.RawValue
+	if rest, err := asn1.Unmarshal(value, &raw); err != nil {
+		return newValidationError("Value", fmt.Errorf("not DER-encoded: %w", err))
+	} else if len(rest) > 0 {
+		return newValidationError("Value", errors.New("trailing data after DER value"))
+	}
+	return nil
+}
+
+// parseOID parses an object identifier in dotted notation.
+func parseOID(s string) (asn1.ObjectIdentifier, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid OID %q: needs at least two arcs", s)
+	}
+	id := make(asn1.ObjectIdentifier, 0, len(parts))
+	for _, part := range parts {
+		arc, err := strconv.ParseUint(part, 10, 31)
+		if err != nil || (len(part) > 1 && part[0] == '0') {
+			return nil, fmt.Errorf("invalid OID %q: invalid arc %q", s, part)
+		}
+		id = append(id, int(arc))
+	}
+	if id[0] > 2 || (id[0] < 2 && id[1] > 39) {
+		return nil, fmt.Errorf("invalid OID %q: invalid root arcs", s)
+	}
+	return id, nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package manifest
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeshCertProfileValidate(t *testing.T) {
+	testCases := map[string]struct {
+		profile MeshCertProfile
+		wantErr bool
+	}{
+		"empty": {},
+		"valid": {
+			profile: MeshCertProfile{
+				EmailAddresses: []string{"web@example.com"},
+				IPRanges:       []string{"10.0.0.0/8", "fd00::/8"},
+				Subject:        &CertSubject{CommonName: "web", Organization: []string{"Example"}, Country: []string{"DE"}},
+				ExtKeyUsages:   []string{"ClientAuth", "EmailProtection"},
+				Extensions:     []CertExtension{{OID: "1.3.6.1.4.1.99999.1", Value: "0c03666f6f"}},
+			},
+		},
+		"invalid email": {
+			profile: MeshCertProfile{EmailAddresses: []string{"Web <web@example.com>"}},
+			wantErr: true,
+		},
+		"invalid IP range": {
+			profile: MeshCertProfile{IPRanges: []string{"10.0.0.1"}},
+			wantErr: true,
+		},
+		"common name too long": {
+			profile: MeshCertProfile{Subject: &CertSubject{CommonName: string(make([]byte, 65))}},
+			wantErr: true,
+		},
+		"invalid country": {
+			profile: MeshCertProfile{Subject: &CertSubject{Country: []string{"Germany"}}},
+			wantErr: true,
+		},
+		"unsupported ext key usage": {
+			profile: MeshCertProfile{ExtKeyUsages: []string{"OCSPSigning"}},
+			wantErr: true,
+		},
+		"invalid OID": {
+			profile: MeshCertProfile{Extensions: []CertExtension{{OID: "1.3.x", Value: "0500"}}},
+			wantErr: true,
+		},
+		"reserved X.509 extension": {
+			profile: MeshCertProfile{Extensions: []CertExtension{{OID: "2.5.29.19", Value: "30030101ff"}}},
+			wantErr: true,
+		},
+		"reserved attestation extension": {
+			profile: MeshCertProfile{Extensions: []CertExtension{{OID: "1.3.9901.3.1", Value: "0500"}}},
+			wantErr: true,
+		},
+		"value not DER": {
+			profile: MeshCertProfile{Extensions: []CertExtension{{OID: "1.2.3.4", Value: "ffff"}}},
+			wantErr: true,
+		},
+		"duplicate extension": {
+			profile: MeshCertProfile{Extensions: []CertExtension{{OID: "1.2.3.4", Value: "0500"}, {OID: "1.2.3.4", Value: "0500"}}},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.profile.Validate()
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMeshCertProfileConversion(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	profile := &MeshCertProfile{
+		IPRanges:     []string{"10.0.0.0/8"},
+		Subject:      &CertSubject{CommonName: "web", OrganizationalUnit: []string{"Payments"}},
+		ExtKeyUsages: []string{"ClientAuth"},
+		Extensions:   []CertExtension{{OID: "1.2.3.4", Critical: true, Value: "0500"}},
+	}
+
+	assert.True(profile.AllowsIP(netip.MustParseAddr("10.1.2.3")))
+	assert.True(profile.AllowsIP(netip.MustParseAddr("::ffff:10.1.2.3")))
+	assert.False(profile.AllowsIP(netip.MustParseAddr("192.0.2.1")))
+	assert.True((&MeshCertProfile{}).AllowsIP(netip.MustParseAddr("192.0.2.1")))
+
+	subject := profile.PKIXSubject()
+	assert.Equal("web", subject.CommonName)
+	assert.Equal([]string{"Payments"}, subject.OrganizationalUnit)
+
+	usages, err := profile.X509ExtKeyUsages()
+	require.NoError(err)
+	assert.Equal([]x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}, usages)
+
+	extensions, err := profile.PKIXExtensions()
+	require.NoError(err)
+	require.Len(extensions, 1)
+	assert.Equal(asn1.ObjectIdentifier{1, 2, 3, 4}, extensions[0].Id)
+	assert.True(extensions[0].Critical)
+	assert.Equal([]byte{0x05, 0x00}, extensions[0].Value)
+}
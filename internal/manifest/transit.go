@@ -0,0 +1,151 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package manifest
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// TransitOperation is an operation of the transit engine API.
+type TransitOperation string
+
+const (
+	// TransitOperationEncrypt allows encrypting data with the key.
+	TransitOperationEncrypt TransitOperation = "encrypt"
+	// TransitOperationDecrypt allows decrypting data with the key.
+	TransitOperationDecrypt TransitOperation = "decrypt"
+	// TransitOperationRewrap allows re-encrypting data with the latest version of the key.
+	TransitOperationRewrap TransitOperation = "rewrap"
+	// TransitOperationDatakey allows generating data keys wrapped with the key.
+	TransitOperationDatakey TransitOperation = "datakey"
+	// TransitOperationHMAC allows computing HMACs with the key.
+	TransitOperationHMAC TransitOperation = "hmac"
+	// TransitOperationSign allows signing data with the key.
+	TransitOperationSign TransitOperation = "sign"
+	// TransitOperationVerify allows verifying signatures and HMACs of the key.
+	TransitOperationVerify TransitOperation = "verify"
+	// TransitOperationRead allows reading the versions of the key.
+	TransitOperationRead TransitOperation = "read"
+	// TransitOperationRotate allows rotating the key.
+	TransitOperationRotate TransitOperation = "rotate"
+	// TransitOperationConfig allows changing the minimum versions of the key.
+	TransitOperationConfig TransitOperation = "config"
+)
+
+// TransitOperations returns all operations of the transit engine API.
+func TransitOperations() []TransitOperation {
+	return []TransitOperation{
+		TransitOperationEncrypt,
+		TransitOperationDecrypt,
+		TransitOperationRewrap,
+		TransitOperationDatakey,
+		TransitOperationHMAC,
+		TransitOperationSign,
+		TransitOperationVerify,
+		TransitOperationRead,
+		TransitOperationRotate,
+		TransitOperationConfig,
+	}
+}
+
+// Validate checks that the operation is supported.
+func (o TransitOperation) Validate() error {
+	if !slices.Contains(TransitOperations(), o) {
+		return fmt.Errorf("unsupported transit operation %q, supported are %v", o, TransitOperations())
+	}
+	return nil
+}
+
+// TransitKey restricts the access to a key of the transit engine API.
+type TransitKey struct {
+	// Grants allow workloads to perform operations with the key. An operation is allowed if any
+	// grant allows it.
+	Grants []TransitKeyGrant
+}
+
+// TransitKeyGrant allows the workloads identified by their WorkloadSecretID or policy hash to
+// perform operations with a key of the transit engine API.
+type TransitKeyGrant struct {
+	// WorkloadSecretIDs are the WorkloadSecretIDs of the workloads the grant applies to.
+	WorkloadSecretIDs []string `json:",omitempty"`
+	// PolicyHashes are the policy hashes of the workloads the grant applies to.
+	PolicyHashes []HexString `json:",omitempty"`
+	// Operations are the operations the grant allows.
+	Operations []TransitOperation
+}
+
+// Validate checks the validity of all grants of the key against the manifest's policies.
+func (k TransitKey) Validate(policies map[HexString]PolicyEntry) error {
+	var errs []error
+	if len(k.Grants) == 0 {
+		errs = append(errs, newValidationError("Grants", errors.New("key must have at least one grant")))
+	}
+	for i, grant := range k.Grants {
+		if err := grant.Validate(policies); err != nil {
+			errs = append(errs, newValidationError(fmt.Sprintf("Grants[%d]", i), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the validity of the grant against the manifest's policies.
+func (g TransitKeyGrant) Validate(policies map[HexString]PolicyEntry) error {
+	var errs []error
+	if len(g.WorkloadSecretIDs) == 0 && len(g.PolicyHashes) == 0 {
+		errs = append(errs, errors.New("grant must have at least one WorkloadSecretID or policy hash"))
+	}
+	for i, id := range g.WorkloadSecretIDs {
+		if id == "" {
+			errs = append(errs, newValidationError(fmt.Sprintf("WorkloadSecretIDs[%d]", i), errors.New("WorkloadSecretID must not be empty")))
+		}
+	}
+	for i, policyHash := range g.PolicyHashes {
+		if _, ok := policies[policyHash]; !ok {
+			errs = append(errs, newValidationError(fmt.Sprintf("PolicyHashes[%d]", i), fmt.Errorf("policy hash %s not found in Policies", policyHash)))
+		}
+	}
+	if len(g.Operations) == 0 {
+		errs = append(errs, newValidationError("Operations", errors.New("grant must allow at least one operation")))
+	}
+	for i, op := range g.Operations {
+		if err := op.Validate(); err != nil {
+			errs = append(errs, newValidationError(fmt.Sprintf("Operations[%d]", i), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// TransitAccess authorizes a workload to perform an operation with the named key of the transit
+// engine API. The workload is identified by its WorkloadSecretID, which may be empty, and its
+// policy hash.
+//
+// Keys without an entry in TransitKeys can only be used by workloads whose WorkloadSecretID
+// equals the key name, with all operations. Keys with an entry can only be used as allowed by its
+// grants. The returned string describes the rule that allowed the operation.
+func (m *Manifest) TransitAccess(name, workloadSecretID string, policyHash HexString, op TransitOperation) (string, error) {
+	key, ok := m.TransitKeys[name]
+	if !ok {
+		if workloadSecretID != "" && workloadSecretID == name {
+			return "WorkloadSecretID equals key name", nil
+		}
+		return "", fmt.Errorf("WorkloadSecretID %q doesn't match key name %q", workloadSecretID, name)
+	}
+	for i, grant := range key.Grants {
+		if !slices.Contains(grant.Operations, op) {
+			continue
+		}
+		if workloadSecretID != "" && slices.Contains(grant.WorkloadSecretIDs, workloadSecretID) {
+			return fmt.Sprintf("TransitKeys[%q].Grants[%d] by WorkloadSecretID", name, i), nil
+		}
+		if policyHash != "" && slices.ContainsFunc(grant.PolicyHashes, func(h HexString) bool {
+			return strings.EqualFold(h.String(), policyHash.String())
+		}) {
+			return fmt.Sprintf("TransitKeys[%q].Grants[%d] by policy hash", name, i), nil
+		}
+	}
+	return "", fmt.Errorf("no grant of key %q allows %s", name, op)
+}
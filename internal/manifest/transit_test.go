@@ -0,0 +1,108 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package manifest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransitAccess(t *testing.T) {
+	const policyHash = HexString("bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb")
+	m := &Manifest{
+		TransitKeys: map[string]TransitKey{
+			"shared": {Grants: []TransitKeyGrant{
+				{
+					WorkloadSecretIDs: []string{"producer"},
+					Operations:        []TransitOperation{TransitOperationEncrypt, TransitOperationRotate},
+				},
+				{
+					WorkloadSecretIDs: []string{"consumer"},
+					PolicyHashes:      []HexString{policyHash},
+					Operations:        []TransitOperation{TransitOperationDecrypt},
+				},
+			}},
+		},
+	}
+
+	testCases := map[string]struct {
+		key              string
+		workloadSecretID string
+		policyHash       HexString
+		op               TransitOperation
+		wantErr          bool
+	}{
+		"unrestricted key with matching ID": {
+			key:              "foo",
+			workloadSecretID: "foo",
+			op:               TransitOperationSign,
+		},
+		"unrestricted key with other ID": {
+			key:              "foo",
+			workloadSecretID: "bar",
+			op:               TransitOperationEncrypt,
+			wantErr:          true,
+		},
+		"unrestricted key without ID": {
+			key:        "foo",
+			policyHash: policyHash,
+			op:         TransitOperationEncrypt,
+			wantErr:    true,
+		},
+		"granted by ID": {
+			key:              "shared",
+			workloadSecretID: "producer",
+			op:               TransitOperationEncrypt,
+		},
+		"other grant by ID": {
+			key:              "shared",
+			workloadSecretID: "consumer",
+			op:               TransitOperationDecrypt,
+		},
+		"granted by policy hash": {
+			key:        "shared",
+			policyHash: policyHash,
+			op:         TransitOperationDecrypt,
+		},
+		"granted by upper case policy hash": {
+			key:        "shared",
+			policyHash: "BBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBB",
+			op:         TransitOperationDecrypt,
+		},
+		"operation not granted": {
+			key:              "shared",
+			workloadSecretID: "producer",
+			op:               TransitOperationDecrypt,
+			wantErr:          true,
+		},
+		"restricted key with matching ID": {
+			key:              "shared",
+			workloadSecretID: "shared",
+			op:               TransitOperationEncrypt,
+			wantErr:          true,
+		},
+		"unknown workload": {
+			key:              "shared",
+			workloadSecretID: "other",
+			policyHash:       "cccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccc",
+			op:               TransitOperationEncrypt,
+			wantErr:          true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			rule, err := m.TransitAccess(tc.key, tc.workloadSecretID, tc.policyHash, tc.op)
+			if tc.wantErr {
+				assert.Error(err)
+				return
+			}
+			assert.NoError(err)
+			assert.NotEmpty(rule)
+		})
+	}
+}
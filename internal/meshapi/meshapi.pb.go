@@ -22,7 +22,11 @@ const (
 )
 
 type NewMeshCertRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Optional PEM-encoded certificate signing request. If set, the certificate is issued for the
+	// public key of the CSR instead of the key of the aTLS handshake. The SANs of the CSR must be
+	// allowed by the manifest. If the CSR has no SANs, the SANs of the manifest are used.
+	CSR           []byte `protobuf:"bytes,2,opt,name=CSR,proto3" json:"CSR,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -57,6 +61,13 @@ func (*NewMeshCertRequest) Descriptor() ([]byte, []int) {
 	return file_meshapi_proto_rawDescGZIP(), []int{0}
 }
 
+func (x *NewMeshCertRequest) GetCSR() []byte {
+	if x != nil {
+		return x.CSR
+	}
+	return nil
+}
+
 type NewMeshCertResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// PEM-encoded certificate used by the workload as CA
@@ -67,8 +78,16 @@ type NewMeshCertResponse struct {
 	RootCACert []byte `protobuf:"bytes,3,opt,name=RootCACert,proto3" json:"RootCACert,omitempty"`
 	// Raw byte slice which can be used to derive more secrets
 	WorkloadSecret []byte `protobuf:"bytes,4,opt,name=WorkloadSecret,proto3" json:"WorkloadSecret,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	// PEM-encoded CRLs of the mesh CA and of the mesh CAs of federated deployments
+	CRL []byte `protobuf:"bytes,5,opt,name=CRL,proto3" json:"CRL,omitempty"`
+	// Concatenated PEM-encoded mesh CA certificates of this deployment and of all federated
+	// deployments
+	TrustBundle []byte `protobuf:"bytes,6,opt,name=TrustBundle,proto3" json:"TrustBundle,omitempty"`
+	// PEM-encoded mesh CA certificates of federated deployments with a SPIFFE trust domain, keyed by
+	// trust domain
+	FederatedSPIFFEBundles map[string][]byte `protobuf:"bytes,7,rep,name=FederatedSPIFFEBundles,proto3" json:"FederatedSPIFFEBundles,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
 }
 
 func (x *NewMeshCertResponse) Reset() {
@@ -129,6 +148,137 @@ func (x *NewMeshCertResponse) GetWorkloadSecret() []byte {
 	return nil
 }
 
+func (x *NewMeshCertResponse) GetCRL() []byte {
+	if x != nil {
+		return x.CRL
+	}
+	return nil
+}
+
+func (x *NewMeshCertResponse) GetTrustBundle() []byte {
+	if x != nil {
+		return x.TrustBundle
+	}
+	return nil
+}
+
+func (x *NewMeshCertResponse) GetFederatedSPIFFEBundles() map[string][]byte {
+	if x != nil {
+		return x.FederatedSPIFFEBundles
+	}
+	return nil
+}
+
+type GetTrustBundleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTrustBundleRequest) Reset() {
+	*x = GetTrustBundleRequest{}
+	mi := &file_meshapi_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTrustBundleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTrustBundleRequest) ProtoMessage() {}
+
+func (x *GetTrustBundleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_meshapi_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTrustBundleRequest.ProtoReflect.Descriptor instead.
+func (*GetTrustBundleRequest) Descriptor() ([]byte, []int) {
+	return file_meshapi_proto_rawDescGZIP(), []int{2}
+}
+
+type GetTrustBundleResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// PEM-encoded certificate used by the workload as CA
+	MeshCACert []byte `protobuf:"bytes,1,opt,name=MeshCACert,proto3" json:"MeshCACert,omitempty"`
+	// PEM-encoded CRLs of the mesh CA and of the mesh CAs of federated deployments
+	CRL []byte `protobuf:"bytes,2,opt,name=CRL,proto3" json:"CRL,omitempty"`
+	// Concatenated PEM-encoded mesh CA certificates of this deployment and of all federated
+	// deployments
+	TrustBundle []byte `protobuf:"bytes,3,opt,name=TrustBundle,proto3" json:"TrustBundle,omitempty"`
+	// PEM-encoded mesh CA certificates of federated deployments with a SPIFFE trust domain, keyed by
+	// trust domain
+	FederatedSPIFFEBundles map[string][]byte `protobuf:"bytes,4,rep,name=FederatedSPIFFEBundles,proto3" json:"FederatedSPIFFEBundles,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *GetTrustBundleResponse) Reset() {
+	*x = GetTrustBundleResponse{}
+	mi := &file_meshapi_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTrustBundleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTrustBundleResponse) ProtoMessage() {}
+
+func (x *GetTrustBundleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_meshapi_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTrustBundleResponse.ProtoReflect.Descriptor instead.
+func (*GetTrustBundleResponse) Descriptor() ([]byte, []int) {
+	return file_meshapi_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetTrustBundleResponse) GetMeshCACert() []byte {
+	if x != nil {
+		return x.MeshCACert
+	}
+	return nil
+}
+
+func (x *GetTrustBundleResponse) GetCRL() []byte {
+	if x != nil {
+		return x.CRL
+	}
+	return nil
+}
+
+func (x *GetTrustBundleResponse) GetTrustBundle() []byte {
+	if x != nil {
+		return x.TrustBundle
+	}
+	return nil
+}
+
+func (x *GetTrustBundleResponse) GetFederatedSPIFFEBundles() map[string][]byte {
+	if x != nil {
+		return x.FederatedSPIFFEBundles
+	}
+	return nil
+}
+
 type RecoverRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -137,7 +287,7 @@ type RecoverRequest struct {
 
 func (x *RecoverRequest) Reset() {
 	*x = RecoverRequest{}
-	mi := &file_meshapi_proto_msgTypes[2]
+	mi := &file_meshapi_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -149,7 +299,7 @@ func (x *RecoverRequest) String() string {
 func (*RecoverRequest) ProtoMessage() {}
 
 func (x *RecoverRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_meshapi_proto_msgTypes[2]
+	mi := &file_meshapi_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -162,7 +312,7 @@ func (x *RecoverRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RecoverRequest.ProtoReflect.Descriptor instead.
 func (*RecoverRequest) Descriptor() ([]byte, []int) {
-	return file_meshapi_proto_rawDescGZIP(), []int{2}
+	return file_meshapi_proto_rawDescGZIP(), []int{4}
 }
 
 type RecoverResponse struct {
@@ -177,7 +327,7 @@ type RecoverResponse struct {
 
 func (x *RecoverResponse) Reset() {
 	*x = RecoverResponse{}
-	mi := &file_meshapi_proto_msgTypes[3]
+	mi := &file_meshapi_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -189,7 +339,7 @@ func (x *RecoverResponse) String() string {
 func (*RecoverResponse) ProtoMessage() {}
 
 func (x *RecoverResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_meshapi_proto_msgTypes[3]
+	mi := &file_meshapi_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -202,7 +352,7 @@ func (x *RecoverResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RecoverResponse.ProtoReflect.Descriptor instead.
 func (*RecoverResponse) Descriptor() ([]byte, []int) {
-	return file_meshapi_proto_rawDescGZIP(), []int{3}
+	return file_meshapi_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *RecoverResponse) GetSeed() []byte {
@@ -237,8 +387,9 @@ var File_meshapi_proto protoreflect.FileDescriptor
 
 const file_meshapi_proto_rawDesc = "" +
 	"\n" +
-	"\rmeshapi.proto\x12\ameshapi\"-\n" +
-	"\x12NewMeshCertRequestJ\x04\b\x01\x10\x02R\x11PeerPublicKeyHash\"\x9b\x01\n" +
+	"\rmeshapi.proto\x12\ameshapi\"?\n" +
+	"\x12NewMeshCertRequest\x12\x10\n" +
+	"\x03CSR\x18\x02 \x01(\fR\x03CSRJ\x04\b\x01\x10\x02R\x11PeerPublicKeyHash\"\x8c\x03\n" +
 	"\x13NewMeshCertResponse\x12\x1e\n" +
 	"\n" +
 	"MeshCACert\x18\x01 \x01(\fR\n" +
@@ -247,16 +398,34 @@ const file_meshapi_proto_rawDesc = "" +
 	"\n" +
 	"RootCACert\x18\x03 \x01(\fR\n" +
 	"RootCACert\x12&\n" +
-	"\x0eWorkloadSecret\x18\x04 \x01(\fR\x0eWorkloadSecret\"\x10\n" +
+	"\x0eWorkloadSecret\x18\x04 \x01(\fR\x0eWorkloadSecret\x12\x10\n" +
+	"\x03CRL\x18\x05 \x01(\fR\x03CRL\x12 \n" +
+	"\vTrustBundle\x18\x06 \x01(\fR\vTrustBundle\x12p\n" +
+	"\x16FederatedSPIFFEBundles\x18\a \x03(\v28.meshapi.NewMeshCertResponse.FederatedSPIFFEBundlesEntryR\x16FederatedSPIFFEBundles\x1aI\n" +
+	"\x1bFederatedSPIFFEBundlesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value:\x028\x01\"\x17\n" +
+	"\x15GetTrustBundleRequest\"\xac\x02\n" +
+	"\x16GetTrustBundleResponse\x12\x1e\n" +
+	"\n" +
+	"MeshCACert\x18\x01 \x01(\fR\n" +
+	"MeshCACert\x12\x10\n" +
+	"\x03CRL\x18\x02 \x01(\fR\x03CRL\x12 \n" +
+	"\vTrustBundle\x18\x03 \x01(\fR\vTrustBundle\x12s\n" +
+	"\x16FederatedSPIFFEBundles\x18\x04 \x03(\v2;.meshapi.GetTrustBundleResponse.FederatedSPIFFEBundlesEntryR\x16FederatedSPIFFEBundles\x1aI\n" +
+	"\x1bFederatedSPIFFEBundlesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value:\x028\x01\"\x10\n" +
 	"\x0eRecoverRequest\"\x7f\n" +
 	"\x0fRecoverResponse\x12\x12\n" +
 	"\x04Seed\x18\x01 \x01(\fR\x04Seed\x12\x12\n" +
 	"\x04Salt\x18\x02 \x01(\fR\x04Salt\x12\x1c\n" +
 	"\tMeshCAKey\x18\x03 \x01(\fR\tMeshCAKey\x12&\n" +
-	"\x0eLatestManifest\x18\x04 \x01(\fR\x0eLatestManifest2\x91\x01\n" +
+	"\x0eLatestManifest\x18\x04 \x01(\fR\x0eLatestManifest2\xe4\x01\n" +
 	"\aMeshAPI\x12H\n" +
 	"\vNewMeshCert\x12\x1b.meshapi.NewMeshCertRequest\x1a\x1c.meshapi.NewMeshCertResponse\x12<\n" +
-	"\aRecover\x12\x17.meshapi.RecoverRequest\x1a\x18.meshapi.RecoverResponseB2Z0github.com/edgelesssys/contrast/internal/meshapib\x06proto3"
+	"\aRecover\x12\x17.meshapi.RecoverRequest\x1a\x18.meshapi.RecoverResponse\x12Q\n" +
+	"\x0eGetTrustBundle\x12\x1e.meshapi.GetTrustBundleRequest\x1a\x1f.meshapi.GetTrustBundleResponseB2Z0github.com/edgelesssys/contrast/internal/meshapib\x06proto3"
 
 var (
 	file_meshapi_proto_rawDescOnce sync.Once
@@ -270,23 +439,31 @@ func file_meshapi_proto_rawDescGZIP() []byte {
 	return file_meshapi_proto_rawDescData
 }
 
-var file_meshapi_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_meshapi_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
 var file_meshapi_proto_goTypes = []any{
-	(*NewMeshCertRequest)(nil),  // 0: meshapi.NewMeshCertRequest
-	(*NewMeshCertResponse)(nil), // 1: meshapi.NewMeshCertResponse
-	(*RecoverRequest)(nil),      // 2: meshapi.RecoverRequest
-	(*RecoverResponse)(nil),     // 3: meshapi.RecoverResponse
+	(*NewMeshCertRequest)(nil),     // 0: meshapi.NewMeshCertRequest
+	(*NewMeshCertResponse)(nil),    // 1: meshapi.NewMeshCertResponse
+	(*GetTrustBundleRequest)(nil),  // 2: meshapi.GetTrustBundleRequest
+	(*GetTrustBundleResponse)(nil), // 3: meshapi.GetTrustBundleResponse
+	(*RecoverRequest)(nil),         // 4: meshapi.RecoverRequest
+	(*RecoverResponse)(nil),        // 5: meshapi.RecoverResponse
+	nil,                            // 6: meshapi.NewMeshCertResponse.FederatedSPIFFEBundlesEntry
+	nil,                            // 7: meshapi.GetTrustBundleResponse.FederatedSPIFFEBundlesEntry
 }
 var file_meshapi_proto_depIdxs = []int32{
-	0, // 0: meshapi.MeshAPI.NewMeshCert:input_type -> meshapi.NewMeshCertRequest
-	2, // 1: meshapi.MeshAPI.Recover:input_type -> meshapi.RecoverRequest
-	1, // 2: meshapi.MeshAPI.NewMeshCert:output_type -> meshapi.NewMeshCertResponse
-	3, // 3: meshapi.MeshAPI.Recover:output_type -> meshapi.RecoverResponse
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	6, // 0: meshapi.NewMeshCertResponse.FederatedSPIFFEBundles:type_name -> meshapi.NewMeshCertResponse.FederatedSPIFFEBundlesEntry
+	7, // 1: meshapi.GetTrustBundleResponse.FederatedSPIFFEBundles:type_name -> meshapi.GetTrustBundleResponse.FederatedSPIFFEBundlesEntry
+	0, // 2: meshapi.MeshAPI.NewMeshCert:input_type -> meshapi.NewMeshCertRequest
+	4, // 3: meshapi.MeshAPI.Recover:input_type -> meshapi.RecoverRequest
+	2, // 4: meshapi.MeshAPI.GetTrustBundle:input_type -> meshapi.GetTrustBundleRequest
+	1, // 5: meshapi.MeshAPI.NewMeshCert:output_type -> meshapi.NewMeshCertResponse
+	5, // 6: meshapi.MeshAPI.Recover:output_type -> meshapi.RecoverResponse
+	3, // 7: meshapi.MeshAPI.GetTrustBundle:output_type -> meshapi.GetTrustBundleResponse
+	5, // [5:8] is the sub-list for method output_type
+	2, // [2:5] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_meshapi_proto_init() }
@@ -300,7 +477,7 @@ func file_meshapi_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_meshapi_proto_rawDesc), len(file_meshapi_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
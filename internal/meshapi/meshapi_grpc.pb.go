@@ -19,8 +19,9 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	MeshAPI_NewMeshCert_FullMethodName = "/meshapi.MeshAPI/NewMeshCert"
-	MeshAPI_Recover_FullMethodName     = "/meshapi.MeshAPI/Recover"
+	MeshAPI_NewMeshCert_FullMethodName    = "/meshapi.MeshAPI/NewMeshCert"
+	MeshAPI_Recover_FullMethodName        = "/meshapi.MeshAPI/Recover"
+	MeshAPI_GetTrustBundle_FullMethodName = "/meshapi.MeshAPI/GetTrustBundle"
 )
 
 // MeshAPIClient is the client API for MeshAPI service.
@@ -29,6 +30,7 @@ const (
 type MeshAPIClient interface {
 	NewMeshCert(ctx context.Context, in *NewMeshCertRequest, opts ...grpc.CallOption) (*NewMeshCertResponse, error)
 	Recover(ctx context.Context, in *RecoverRequest, opts ...grpc.CallOption) (*RecoverResponse, error)
+	GetTrustBundle(ctx context.Context, in *GetTrustBundleRequest, opts ...grpc.CallOption) (*GetTrustBundleResponse, error)
 }
 
 type meshAPIClient struct {
@@ -59,12 +61,23 @@ func (c *meshAPIClient) Recover(ctx context.Context, in *RecoverRequest, opts ..
 	return out, nil
 }
 
+func (c *meshAPIClient) GetTrustBundle(ctx context.Context, in *GetTrustBundleRequest, opts ...grpc.CallOption) (*GetTrustBundleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTrustBundleResponse)
+	err := c.cc.Invoke(ctx, MeshAPI_GetTrustBundle_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MeshAPIServer is the server API for MeshAPI service.
 // All implementations must embed UnimplementedMeshAPIServer
 // for forward compatibility.
 type MeshAPIServer interface {
 	NewMeshCert(context.Context, *NewMeshCertRequest) (*NewMeshCertResponse, error)
 	Recover(context.Context, *RecoverRequest) (*RecoverResponse, error)
+	GetTrustBundle(context.Context, *GetTrustBundleRequest) (*GetTrustBundleResponse, error)
 	mustEmbedUnimplementedMeshAPIServer()
 }
 
@@ -81,6 +94,9 @@ func (UnimplementedMeshAPIServer) NewMeshCert(context.Context, *NewMeshCertReque
 func (UnimplementedMeshAPIServer) Recover(context.Context, *RecoverRequest) (*RecoverResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Recover not implemented")
 }
+func (UnimplementedMeshAPIServer) GetTrustBundle(context.Context, *GetTrustBundleRequest) (*GetTrustBundleResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetTrustBundle not implemented")
+}
 func (UnimplementedMeshAPIServer) mustEmbedUnimplementedMeshAPIServer() {}
 func (UnimplementedMeshAPIServer) testEmbeddedByValue()                 {}
 
@@ -138,6 +154,24 @@ func _MeshAPI_Recover_Handler(srv interface{}, ctx context.Context, dec func(int
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MeshAPI_GetTrustBundle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTrustBundleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MeshAPIServer).GetTrustBundle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MeshAPI_GetTrustBundle_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MeshAPIServer).GetTrustBundle(ctx, req.(*GetTrustBundleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // MeshAPI_ServiceDesc is the grpc.ServiceDesc for MeshAPI service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -153,6 +187,10 @@ var MeshAPI_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Recover",
 			Handler:    _MeshAPI_Recover_Handler,
 		},
+		{
+			MethodName: "GetTrustBundle",
+			Handler:    _MeshAPI_GetTrustBundle_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "meshapi.proto",
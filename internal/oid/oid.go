@@ -21,3 +21,7 @@ var RawInsecureReport = asn1.ObjectIdentifier{1, 3, 9901, 2, 99}
 // extension, added to the mesh certificates to allow verification
 // and authorization based on the workloadSecretID.
 var WorkloadSecretOID = asn1.ObjectIdentifier{1, 3, 9901, 3, 1}
+
+// PolicyHashOID is the OID of the policy hash extension, added to the mesh
+// certificates to allow authorization based on the policy hash of the workload.
+var PolicyHashOID = asn1.ObjectIdentifier{1, 3, 9901, 3, 2}
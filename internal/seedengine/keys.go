@@ -0,0 +1,113 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package seedengine
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+const (
+	// rsaPublicExponent is the public exponent of derived RSA keys.
+	rsaPublicExponent = 65537
+	// maxRSAPrimeCandidates bounds the search for a prime. The expected number of candidates for a
+	// 2048-bit prime is below 1000, so hitting the bound indicates a broken key stream.
+	maxRSAPrimeCandidates = 100000
+	// minSecretSize is the minimum size of the secret keys are derived from.
+	minSecretSize = 32
+)
+
+// deriveRSAKey deterministically generates an RSA key with a modulus of the given size from secret.
+//
+// The randomness source of rsa.GenerateKey can't be used for deterministic derivation, so the
+// primes are searched directly: candidates are read from an AES-256-CTR key stream keyed with the
+// secret, and the first two candidates that are prime and coprime to the public exponent are used.
+func deriveRSAKey(secret []byte, bits int) (*rsa.PrivateKey, error) {
+	if len(secret) < minSecretSize {
+		return nil, fmt.Errorf("secret must be at least %d bytes long", minSecretSize)
+	}
+	if bits%16 != 0 {
+		return nil, fmt.Errorf("RSA key size %d isn't a multiple of 16", bits)
+	}
+	block, err := aes.NewCipher(secret[:32])
+	if err != nil {
+		return nil, err
+	}
+	stream := cipher.NewCTR(block, make([]byte, aes.BlockSize))
+
+	e := big.NewInt(rsaPublicExponent)
+	p, err := deriveRSAPrime(stream, bits/2, e)
+	if err != nil {
+		return nil, err
+	}
+	q, err := deriveRSAPrime(stream, bits/2, e)
+	if err != nil {
+		return nil, err
+	}
+	if p.Cmp(q) == 0 {
+		return nil, errors.New("derived identical RSA primes")
+	}
+
+	one := big.NewInt(1)
+	pMinus1 := new(big.Int).Sub(p, one)
+	qMinus1 := new(big.Int).Sub(q, one)
+	gcd := new(big.Int).GCD(nil, nil, pMinus1, qMinus1)
+	lambda := new(big.Int).Div(new(big.Int).Mul(pMinus1, qMinus1), gcd)
+	d := new(big.Int).ModInverse(e, lambda)
+	if d == nil {
+		return nil, errors.New("public exponent isn't invertible")
+	}
+
+	key := &rsa.PrivateKey{
+		PublicKey: rsa.PublicKey{N: new(big.Int).Mul(p, q), E: rsaPublicExponent},
+		D:         d,
+		Primes:    []*big.Int{p, q},
+	}
+	if key.N.BitLen() != bits {
+		return nil, fmt.Errorf("derived RSA modulus has %d bits, expected %d", key.N.BitLen(), bits)
+	}
+	key.Precompute()
+	if err := key.Validate(); err != nil {
+		return nil, fmt.Errorf("validating derived RSA key: %w", err)
+	}
+	return key, nil
+}
+
+// deriveRSAPrime reads prime candidates of the given size from stream until it finds a prime p
+// with gcd(e, p-1) = 1.
+func deriveRSAPrime(stream cipher.Stream, bits int, e *big.Int) (*big.Int, error) {
+	buf := make([]byte, bits/8)
+	one := big.NewInt(1)
+	for range maxRSAPrimeCandidates {
+		clear(buf)
+		stream.XORKeyStream(buf, buf)
+		// Setting the two most significant bits ensures that the product of two candidates has
+		// the full size. Even candidates can be skipped.
+		buf[0] |= 0xc0
+		buf[len(buf)-1] |= 1
+		p := new(big.Int).SetBytes(buf)
+
+		// e is prime, so gcd(e, p-1) = 1 iff e doesn't divide p-1.
+		if new(big.Int).Mod(new(big.Int).Sub(p, one), e).Sign() == 0 {
+			continue
+		}
+		if p.ProbablyPrime(20) {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("no prime found after %d candidates", maxRSAPrimeCandidates)
+}
+
+// deriveEd25519Key deterministically generates an Ed25519 key from secret.
+func deriveEd25519Key(secret []byte) (ed25519.PrivateKey, error) {
+	if len(secret) < minSecretSize {
+		return nil, fmt.Errorf("secret must be at least %d bytes long", minSecretSize)
+	}
+	return ed25519.NewKeyFromSeed(secret[:ed25519.SeedSize]), nil
+}
@@ -1,25 +1,30 @@
 // Copyright 2024 Edgeless Systems GmbH
 // SPDX-License-Identifier: BUSL-1.1
 
-// Package seedengine provides deterministic key derivation of ECDSA and symmetric keys
+// Package seedengine provides deterministic key derivation of asymmetric and symmetric keys
 // from a secret seed.
 package seedengine
 
 import (
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
-	"crypto/rand"
 	"crypto/sha256"
 	"errors"
 	"fmt"
 	"hash"
 	"io"
+	"sync"
 
 	"filippo.io/keygen"
+	"github.com/edgelesssys/contrast/internal/cryptohelpers"
 	"golang.org/x/crypto/hkdf"
 )
 
-// SeedEngine provides deterministic key derivation of ECDSA and symmetric keys
+// DefaultKeyAlgorithm is the algorithm of the CA keys if the manifest doesn't specify one.
+const DefaultKeyAlgorithm = cryptohelpers.KeyAlgorithmECDSAP384
+
+// SeedEngine provides deterministic key derivation of asymmetric and symmetric keys
 // from a secret seed.
 type SeedEngine struct {
 	curve   func() elliptic.Curve
@@ -33,6 +38,10 @@ type SeedEngine struct {
 
 	rootCAKey             *ecdsa.PrivateKey
 	transactionSigningKey *ecdsa.PrivateKey
+
+	// rootCAKeys caches root CA keys of non-default algorithms, because deriving RSA keys is slow.
+	rootCAKeysMu sync.Mutex
+	rootCAKeys   map[cryptohelpers.KeyAlgorithm]crypto.Signer
 }
 
 // New creates a new SeedEngine from a secret seed and a salt.
@@ -42,6 +51,8 @@ func New(secretSeed []byte, salt []byte) (*SeedEngine, error) {
 		hashFun: sha256.New,
 		seed:    secretSeed,
 		salt:    salt,
+
+		rootCAKeys: make(map[cryptohelpers.KeyAlgorithm]crypto.Signer),
 	}
 
 	// Recommended to use salt length equal to hash size, see RFC 5869, section 3.1.
@@ -102,16 +113,75 @@ func (s *SeedEngine) DeriveTransitEngineKey(keyVersion uint32, name string) ([]b
 	return s.hkdfDerive(s.transitEngineSeed, fmt.Sprintf("TRANSIT ENGINE KEY: %d %s", keyVersion, name))
 }
 
-// GenerateMeshCAKey generates a new random key for the mesh authority.
-func (s *SeedEngine) GenerateMeshCAKey() (*ecdsa.PrivateKey, error) {
-	return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+// DeriveTransitHMACKey derives an HMAC-SHA256 key for the transit engine API from a key version and
+// name. The key is independent of the encryption key of the same version and name.
+func (s *SeedEngine) DeriveTransitHMACKey(keyVersion uint32, name string) ([]byte, error) {
+	if name == "" {
+		return nil, errors.New("transit engine key name must not be empty")
+	}
+	return s.hkdfDerive(s.transitEngineSeed, fmt.Sprintf("TRANSIT ENGINE HMAC KEY: %d %s", keyVersion, name))
+}
+
+// DeriveTransitSigningKey derives a signing key of the given algorithm for the transit engine API
+// from a key version and name. Only ECDSA and Ed25519 algorithms are supported, because deriving
+// RSA keys is too slow to do per request.
+func (s *SeedEngine) DeriveTransitSigningKey(alg cryptohelpers.KeyAlgorithm, keyVersion uint32, name string) (crypto.Signer, error) {
+	if name == "" {
+		return nil, errors.New("transit engine key name must not be empty")
+	}
+	if alg.Curve() == nil && alg != cryptohelpers.KeyAlgorithmEd25519 {
+		return nil, fmt.Errorf("unsupported transit engine signing key algorithm %q", alg)
+	}
+	secret, err := s.hkdfDerive(s.transitEngineSeed, fmt.Sprintf("TRANSIT ENGINE SIGNING KEY: %s %d %s", alg, keyVersion, name))
+	if err != nil {
+		return nil, fmt.Errorf("deriving seed: %w", err)
+	}
+	return s.derivePrivateKey(alg, secret)
+}
+
+// GenerateMeshCAKey generates a new random key of the given algorithm for the mesh authority.
+// An empty algorithm selects DefaultKeyAlgorithm.
+func (s *SeedEngine) GenerateMeshCAKey(alg cryptohelpers.KeyAlgorithm) (crypto.Signer, error) {
+	if alg == "" {
+		alg = DefaultKeyAlgorithm
+	}
+	return alg.GenerateKey()
 }
 
-// RootCAKey returns the root CA key which is derived from the secret seed.
+// RootCAKey returns the root CA key of DefaultKeyAlgorithm which is derived from the secret seed.
 func (s *SeedEngine) RootCAKey() *ecdsa.PrivateKey {
 	return s.rootCAKey
 }
 
+// DeriveRootCAKey returns the root CA key of the given algorithm, which is derived from the secret
+// seed. An empty algorithm selects DefaultKeyAlgorithm.
+func (s *SeedEngine) DeriveRootCAKey(alg cryptohelpers.KeyAlgorithm) (crypto.Signer, error) {
+	if alg == "" || alg == DefaultKeyAlgorithm {
+		return s.rootCAKey, nil
+	}
+	if err := alg.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.rootCAKeysMu.Lock()
+	defer s.rootCAKeysMu.Unlock()
+	if key, ok := s.rootCAKeys[alg]; ok {
+		return key, nil
+	}
+	// The info differs from the one of the default key, so that keys of different algorithms are
+	// independent.
+	rootCASeed, err := s.hkdfDerive(s.seed, fmt.Sprintf("ROOT CA SEED: %s", alg))
+	if err != nil {
+		return nil, fmt.Errorf("deriving seed: %w", err)
+	}
+	key, err := s.derivePrivateKey(alg, rootCASeed)
+	if err != nil {
+		return nil, fmt.Errorf("generating %s key: %w", alg, err)
+	}
+	s.rootCAKeys[alg] = key
+	return key, nil
+}
+
 // TransactionSigningKey returns the transaction signing key which is derived from the secret seed.
 func (s *SeedEngine) TransactionSigningKey() *ecdsa.PrivateKey {
 	return s.transactionSigningKey
@@ -139,3 +209,17 @@ func (s *SeedEngine) hkdfDerive(secret []byte, info string) ([]byte, error) {
 func (s *SeedEngine) generateECDSAPrivateKey(secret []byte) (*ecdsa.PrivateKey, error) {
 	return keygen.ECDSA(s.curve(), secret)
 }
+
+// derivePrivateKey deterministically generates a key of the given algorithm from secret.
+func (s *SeedEngine) derivePrivateKey(alg cryptohelpers.KeyAlgorithm, secret []byte) (crypto.Signer, error) {
+	if curve := alg.Curve(); curve != nil {
+		return keygen.ECDSA(curve, secret)
+	}
+	if bits := alg.RSABits(); bits > 0 {
+		return deriveRSAKey(secret, bits)
+	}
+	if alg == cryptohelpers.KeyAlgorithmEd25519 {
+		return deriveEd25519Key(secret)
+	}
+	return nil, alg.Validate()
+}
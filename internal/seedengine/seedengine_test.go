@@ -4,10 +4,13 @@
 package seedengine
 
 import (
+	"crypto/ed25519"
+	"crypto/sha256"
 	"crypto/x509"
 	"encoding/hex"
 	"testing"
 
+	"github.com/edgelesssys/contrast/internal/cryptohelpers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -188,3 +191,173 @@ func TestSeedEngine_DeriveTransitEngineKey(t *testing.T) {
 		assert.NotEqual(hex.EncodeToString(craftedWorkloadSecret), hex.EncodeToString(transitKey))
 	})
 }
+
+func TestSeedEngine_DeriveRootCAKey(t *testing.T) {
+	testCases := map[cryptohelpers.KeyAlgorithm]struct {
+		want string // SHA-256 of the PKCS #8 DER encoding, hex encoded
+	}{
+		/*
+			Crypto-determinism regression test cases.
+
+			DO NOT CHANGE!
+		*/
+		cryptohelpers.KeyAlgorithmRSA2048:   {want: "19370d05d47a9f484f4a632fc3584c82cf4c7bfc76275856132c75c19af0943a"},
+		cryptohelpers.KeyAlgorithmRSA3072:   {want: "555a0ada89404d418258b6d92c3fe15cbab6d924939d1fd51816518afa9bd039"},
+		cryptohelpers.KeyAlgorithmRSA4096:   {want: "d6b0c03e6af0f2a3cc5aa68b676c42f34c45fbe010598e2ccc7bbaeffb04ad56"},
+		cryptohelpers.KeyAlgorithmEd25519:   {want: "41a1d901f6a45abc8ee90b6dbba9a528ba1a7a824f35bac1ebadf12d3ab28dbc"},
+		cryptohelpers.KeyAlgorithmECDSAP256: {},
+		cryptohelpers.KeyAlgorithmECDSAP384: {},
+		cryptohelpers.KeyAlgorithmECDSAP521: {},
+	}
+
+	secretSeed, err := hex.DecodeString("ccebed634ddee7535cd593e1e200b19b780f3906d8782207fa09c59e87a07cb3")
+	require.NoError(t, err)
+	salt, err := hex.DecodeString("8c1b1225c5f6cb7eef6dbd8f77a1e1e149de031d6e3718e660a8b04c8e2b0037")
+	require.NoError(t, err)
+	se, err := New(secretSeed, salt)
+	require.NoError(t, err)
+	otherSE, err := New(secretSeed, salt)
+	require.NoError(t, err)
+
+	for alg, tc := range testCases {
+		t.Run(string(alg), func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			key, err := se.DeriveRootCAKey(alg)
+			require.NoError(err)
+			otherKey, err := otherSE.DeriveRootCAKey(alg)
+			require.NoError(err)
+			assert.Equal(key.Public(), otherKey.Public())
+
+			if tc.want != "" {
+				der, err := x509.MarshalPKCS8PrivateKey(key)
+				require.NoError(err)
+				digest := sha256.Sum256(der)
+				assert.Equal(tc.want, hex.EncodeToString(digest[:]))
+			}
+		})
+	}
+
+	t.Run("default algorithm", func(t *testing.T) {
+		key, err := se.DeriveRootCAKey("")
+		require.NoError(t, err)
+		assert.Equal(t, se.RootCAKey(), key)
+	})
+
+	t.Run("unsupported algorithm", func(t *testing.T) {
+		_, err := se.DeriveRootCAKey("ML-DSA-65")
+		assert.Error(t, err)
+	})
+}
+
+func TestSeedEngine_DeriveTransitHMACKey(t *testing.T) {
+	require := require.New(t)
+
+	testCases := map[string]struct {
+		keyVersion uint32
+		name       string
+		want       string // hex encoded
+		err        bool
+	}{
+		/*
+			Crypto-determinism regression test cases.
+
+			DO NOT CHANGE!
+		*/
+		"v0 vault_unsealing": {keyVersion: 0, name: "vault_unsealing", want: "3ad08189e1e9453eb24cac9d32fa32bc848b134546384279dffeedbaf38f9d74"},
+		"v2 autounseal":      {keyVersion: 2, name: "autounseal", want: "6da249b063b5c4abf1b7b43f200940ca3e6229c30ee038a8476acf09e05e6929"},
+		"v2000 special char": {keyVersion: 2000, name: "thi$$hoU_ld+*work", want: "9c3cd4e8052a6924d41eddbd07d8528457a064f14f933f77ba7c82d690682222"},
+		"empty name errors":  {keyVersion: 0, name: "", err: true},
+	}
+
+	secretSeed, err := hex.DecodeString("9c7f285a46704602f8b6d9d4a89193579a979f144a9d8733fddd4f2bbcecd77f")
+	require.NoError(err)
+	salt, err := hex.DecodeString("6227b2cae740349beaff040af74aa1566ac330e9b54ce0e58f8d5ee47281745a")
+	require.NoError(err)
+
+	se, err := New(secretSeed, salt)
+	require.NoError(err)
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			key, err := se.DeriveTransitHMACKey(tc.keyVersion, tc.name)
+
+			if tc.err {
+				require.Error(err)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(tc.want, hex.EncodeToString(key))
+		})
+	}
+
+	t.Run("HMAC key never equals the encryption key", func(t *testing.T) {
+		hmacKey, err := se.DeriveTransitHMACKey(0, "vault_unsealing")
+		require.NoError(err)
+		encryptionKey, err := se.DeriveTransitEngineKey(0, "vault_unsealing")
+		require.NoError(err)
+		assert.NotEqual(t, hex.EncodeToString(encryptionKey), hex.EncodeToString(hmacKey))
+	})
+}
+
+func TestSeedEngine_DeriveTransitSigningKey(t *testing.T) {
+	testCases := map[string]struct {
+		alg        cryptohelpers.KeyAlgorithm
+		keyVersion uint32
+		name       string
+		want       string // public key, hex encoded
+		wantErr    bool
+	}{
+		/*
+			Crypto-determinism regression test cases.
+
+			DO NOT CHANGE!
+		*/
+		"Ed25519 v0 vault_unsealing": {alg: cryptohelpers.KeyAlgorithmEd25519, keyVersion: 0, name: "vault_unsealing", want: "a38e32770c4a062587df77f4ea0f3bd236efabfc1222c9024d6b68c9b90e868b"},
+		"Ed25519 v2 autounseal":      {alg: cryptohelpers.KeyAlgorithmEd25519, keyVersion: 2, name: "autounseal", want: "498b5b03d7a850b55601cbd743f512dfd2d8f754773a7883317cdb00a26d89c6"},
+		"ECDSA-P256":                 {alg: cryptohelpers.KeyAlgorithmECDSAP256, keyVersion: 0, name: "vault_unsealing"},
+		"ECDSA-P384":                 {alg: cryptohelpers.KeyAlgorithmECDSAP384, keyVersion: 1, name: "vault_unsealing"},
+		"ECDSA-P521":                 {alg: cryptohelpers.KeyAlgorithmECDSAP521, keyVersion: 1, name: "vault_unsealing"},
+		"RSA errors":                 {alg: cryptohelpers.KeyAlgorithmRSA2048, keyVersion: 0, name: "vault_unsealing", wantErr: true},
+		"empty name errors":          {alg: cryptohelpers.KeyAlgorithmEd25519, keyVersion: 0, name: "", wantErr: true},
+	}
+
+	secretSeed, err := hex.DecodeString("9c7f285a46704602f8b6d9d4a89193579a979f144a9d8733fddd4f2bbcecd77f")
+	require.NoError(t, err)
+	salt, err := hex.DecodeString("6227b2cae740349beaff040af74aa1566ac330e9b54ce0e58f8d5ee47281745a")
+	require.NoError(t, err)
+	se, err := New(secretSeed, salt)
+	require.NoError(t, err)
+	otherSE, err := New(secretSeed, salt)
+	require.NoError(t, err)
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			key, err := se.DeriveTransitSigningKey(tc.alg, tc.keyVersion, tc.name)
+			if tc.wantErr {
+				require.Error(err)
+				return
+			}
+			require.NoError(err)
+			otherKey, err := otherSE.DeriveTransitSigningKey(tc.alg, tc.keyVersion, tc.name)
+			require.NoError(err)
+			assert.Equal(key.Public(), otherKey.Public())
+
+			nextKey, err := se.DeriveTransitSigningKey(tc.alg, tc.keyVersion+1, tc.name)
+			require.NoError(err)
+			assert.NotEqual(key.Public(), nextKey.Public())
+
+			if tc.want != "" {
+				pub, ok := key.Public().(ed25519.PublicKey)
+				require.True(ok)
+				assert.Equal(tc.want, hex.EncodeToString(pub))
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+// Package spiffe implements the parts of the SPIFFE specifications that Contrast uses to express
+// workload identities.
+//
+// See https://github.com/spiffe/spiffe/tree/main/standards for the specifications.
+package spiffe
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	// Scheme is the URI scheme of SPIFFE IDs.
+	Scheme = "spiffe"
+
+	// maxTrustDomainLength is the maximum length of a trust domain name.
+	maxTrustDomainLength = 255
+	// maxIDLength is the maximum length of a SPIFFE ID in bytes.
+	maxIDLength = 2048
+)
+
+// ValidateTrustDomain checks that name is a valid SPIFFE trust domain name.
+func ValidateTrustDomain(name string) error {
+	if name == "" {
+		return errors.New("trust domain is empty")
+	}
+	if len(name) > maxTrustDomainLength {
+		return fmt.Errorf("trust domain is longer than %d characters", maxTrustDomainLength)
+	}
+	for _, c := range name {
+		if !isTrustDomainChar(c) {
+			return fmt.Errorf("trust domain %q contains invalid character %q", name, c)
+		}
+	}
+	return nil
+}
+
+// ValidatePath checks that path is a valid path component of a SPIFFE ID.
+func ValidatePath(path string) error {
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("path %q doesn't start with a slash", path)
+	}
+	for segment := range strings.SplitSeq(path[1:], "/") {
+		if err := ValidatePathSegment(segment); err != nil {
+			return fmt.Errorf("path %q: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// ValidatePathSegment checks that segment is a valid segment of the path component of a SPIFFE ID.
+func ValidatePathSegment(segment string) error {
+	switch segment {
+	case "":
+		return errors.New("path segment is empty")
+	case ".", "..":
+		return fmt.Errorf("path segment %q is a relative path modifier", segment)
+	}
+	for _, c := range segment {
+		if !isPathSegmentChar(c) {
+			return fmt.Errorf("path segment %q contains invalid character %q", segment, c)
+		}
+	}
+	return nil
+}
+
+// ID returns the SPIFFE ID with the given trust domain and path.
+func ID(trustDomain, path string) (string, error) {
+	if err := ValidateTrustDomain(trustDomain); err != nil {
+		return "", err
+	}
+	if err := ValidatePath(path); err != nil {
+		return "", err
+	}
+	id := Scheme + "://" + trustDomain + path
+	if len(id) > maxIDLength {
+		return "", fmt.Errorf("SPIFFE ID is longer than %d bytes", maxIDLength)
+	}
+	return id, nil
+}
+
+// KubernetesPath returns the conventional SPIFFE ID path of a Kubernetes service account.
+func KubernetesPath(namespace, serviceAccount string) string {
+	return "/ns/" + namespace + "/sa/" + serviceAccount
+}
+
+// Bundle is a SPIFFE trust bundle in JWK set format.
+type Bundle struct {
+	// Keys holds the trust anchors of the trust domain.
+	Keys []JWK `json:"keys"`
+}
+
+// JWK is a JSON Web Key with SPIFFE-specific parameters.
+type JWK struct {
+	// Use is the SPIFFE key use. Contrast only issues X.509-SVIDs, so it's always "x509-svid".
+	Use string `json:"use"`
+	// KeyType is the JWK key type.
+	KeyType string `json:"kty"`
+	// Curve is the curve of an EC or OKP key.
+	Curve string `json:"crv,omitempty"`
+	// X is the base64url-encoded x coordinate of an EC key, or the public key of an OKP key.
+	X string `json:"x,omitempty"`
+	// Y is the base64url-encoded y coordinate of an EC key.
+	Y string `json:"y,omitempty"`
+	// N is the base64url-encoded modulus of an RSA key.
+	N string `json:"n,omitempty"`
+	// E is the base64url-encoded public exponent of an RSA key.
+	E string `json:"e,omitempty"`
+	// X5C holds the base64-encoded DER certificate of the trust anchor.
+	X5C []string `json:"x5c"`
+}
+
+// NewBundle creates the JSON-encoded trust bundle of a trust domain from PEM-encoded CA
+// certificates with ECDSA, RSA or Ed25519 keys.
+func NewBundle(caCertsPEM []byte) ([]byte, error) {
+	bundle := Bundle{Keys: []JWK{}}
+	for rest := caCertsPEM; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsing CA certificate: %w", err)
+		}
+		jwk, err := newJWK(cert.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		jwk.X5C = []string{base64.StdEncoding.EncodeToString(cert.Raw)}
+		bundle.Keys = append(bundle.Keys, jwk)
+	}
+	if len(bundle.Keys) == 0 {
+		return nil, errors.New("no CA certificate found")
+	}
+	return json.Marshal(bundle)
+}
+
+// newJWK returns the JWK of a CA public key, without the certificate.
+func newJWK(pub any) (JWK, error) {
+	jwk := JWK{Use: "x509-svid"}
+	switch pub := pub.(type) {
+	case *ecdsa.PublicKey:
+		byteLen := (pub.Curve.Params().BitSize + 7) / 8
+		pubKeyBytes, err := pub.Bytes()
+		if err != nil {
+			return JWK{}, fmt.Errorf("encoding CA public key: %w", err)
+		}
+		// The uncompressed point encoding is 0x04 || x || y, with fixed-size coordinates.
+		jwk.KeyType = "EC"
+		jwk.Curve = pub.Curve.Params().Name
+		jwk.X = base64.RawURLEncoding.EncodeToString(pubKeyBytes[1 : 1+byteLen])
+		jwk.Y = base64.RawURLEncoding.EncodeToString(pubKeyBytes[1+byteLen:])
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	default:
+		return JWK{}, fmt.Errorf("unsupported CA public key type %T", pub)
+	}
+	return jwk, nil
+}
+
+func isTrustDomainChar(c rune) bool {
+	return (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '.' || c == '-' || c == '_'
+}
+
+func isPathSegmentChar(c rune) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '.' || c == '-' || c == '_'
+}
@@ -0,0 +1,223 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package spiffe
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestID(t *testing.T) {
+	testCases := map[string]struct {
+		trustDomain string
+		path        string
+		want        string
+		wantErr     bool
+	}{
+		"kubernetes service account": {
+			trustDomain: "example.org",
+			path:        KubernetesPath("default", "web"),
+			want:        "spiffe://example.org/ns/default/sa/web",
+		},
+		"single segment": {
+			trustDomain: "contrast",
+			path:        "/web_1.v2",
+			want:        "spiffe://contrast/web_1.v2",
+		},
+		"empty trust domain": {
+			path:    "/web",
+			wantErr: true,
+		},
+		"uppercase trust domain": {
+			trustDomain: "Example.org",
+			path:        "/web",
+			wantErr:     true,
+		},
+		"trust domain with port": {
+			trustDomain: "example.org:8080",
+			path:        "/web",
+			wantErr:     true,
+		},
+		"trust domain too long": {
+			trustDomain: strings.Repeat("a", 256),
+			path:        "/web",
+			wantErr:     true,
+		},
+		"empty path": {
+			trustDomain: "example.org",
+			wantErr:     true,
+		},
+		"root path": {
+			trustDomain: "example.org",
+			path:        "/",
+			wantErr:     true,
+		},
+		"trailing slash": {
+			trustDomain: "example.org",
+			path:        "/web/",
+			wantErr:     true,
+		},
+		"relative segment": {
+			trustDomain: "example.org",
+			path:        "/ns/../web",
+			wantErr:     true,
+		},
+		"invalid character": {
+			trustDomain: "example.org",
+			path:        "/web?query",
+			wantErr:     true,
+		},
+		"id too long": {
+			trustDomain: "example.org",
+			path:        "/" + strings.Repeat("a", 2048),
+			wantErr:     true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			id, err := ID(tc.trustDomain, tc.path)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, id)
+		})
+	}
+}
+
+func TestNewBundle(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(err)
+	certPEM := selfSignedCert(t, key)
+
+	bundleJSON, err := NewBundle(certPEM)
+	require.NoError(err)
+	var bundle Bundle
+	require.NoError(json.Unmarshal(bundleJSON, &bundle))
+	require.Len(bundle.Keys, 1)
+
+	jwk := bundle.Keys[0]
+	assert.Equal("x509-svid", jwk.Use)
+	assert.Equal("EC", jwk.KeyType)
+	assert.Equal("P-384", jwk.Curve)
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	require.NoError(err)
+	y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+	require.NoError(err)
+	assert.Len(x, 48)
+	assert.Len(y, 48)
+	assert.Zero(key.X.Cmp(new(big.Int).SetBytes(x)))
+	assert.Zero(key.Y.Cmp(new(big.Int).SetBytes(y)))
+
+	require.Len(jwk.X5C, 1)
+	der, err := base64.StdEncoding.DecodeString(jwk.X5C[0])
+	require.NoError(err)
+	block, _ := pem.Decode(certPEM)
+	assert.Equal(block.Bytes, der)
+}
+
+func TestNewBundle_KeyTypes(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		key     crypto.Signer
+		wantJWK JWK
+	}{
+		"rsa": {
+			key: rsaKey,
+			wantJWK: JWK{
+				Use:     "x509-svid",
+				KeyType: "RSA",
+				N:       base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+				E:       "AQAB",
+			},
+		},
+		"ed25519": {
+			key: ed25519Key,
+			wantJWK: JWK{
+				Use:     "x509-svid",
+				KeyType: "OKP",
+				Curve:   "Ed25519",
+				X:       base64.RawURLEncoding.EncodeToString(ed25519Key.Public().(ed25519.PublicKey)),
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			certPEM := selfSignedCert(t, tc.key)
+			bundleJSON, err := NewBundle(certPEM)
+			require.NoError(err)
+			var bundle Bundle
+			require.NoError(json.Unmarshal(bundleJSON, &bundle))
+			require.Len(bundle.Keys, 1)
+
+			block, _ := pem.Decode(certPEM)
+			tc.wantJWK.X5C = []string{base64.StdEncoding.EncodeToString(block.Bytes)}
+			assert.Equal(t, tc.wantJWK, bundle.Keys[0])
+		})
+	}
+}
+
+func TestNewBundle_Errors(t *testing.T) {
+	testCases := map[string]struct {
+		pem []byte
+	}{
+		"empty": {},
+		"no certificate": {
+			pem: pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte("key")}),
+		},
+		"invalid certificate": {
+			pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("cert")}),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewBundle(tc.pem)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func selfSignedCert(t *testing.T, key crypto.Signer) []byte {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
@@ -0,0 +1,214 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+// Package workloadapi implements the X.509 part of the SPIFFE Workload API.
+//
+// See https://github.com/spiffe/spiffe/blob/main/standards/SPIFFE_Workload_API.md for the
+// specification.
+package workloadapi
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative workloadapi.proto
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/edgelesssys/contrast/internal/spiffe"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// securityHeader is the gRPC metadata key that clients must set to "true". Requests without it
+// could be forged by a server-side request forgery and are rejected.
+const securityHeader = "workload.spiffe.io"
+
+// ErrNoSPIFFEID is returned if the mesh certificate doesn't contain a SPIFFE ID.
+var ErrNoSPIFFEID = errors.New("mesh certificate has no SPIFFE ID, is SPIFFETrustDomain set in the manifest?")
+
+// Server serves the X.509-SVID of the workload over the SPIFFE Workload API, and streams updates
+// to connected clients.
+type Server struct {
+	UnimplementedSpiffeWorkloadAPIServer
+
+	mu      sync.Mutex
+	svid    *X509SVIDResponse
+	bundles *X509BundlesResponse
+	// updated is closed and replaced whenever the SVID is updated.
+	updated chan struct{}
+}
+
+// NewServer creates a Server without an SVID.
+func NewServer() *Server {
+	return &Server{updated: make(chan struct{})}
+}
+
+// Update sets the SVID that's served from now on, and sends it to all connected clients.
+//
+// The certificate chain, mesh CA certificate, CRL and federated bundles are PEM-encoded, as
+// returned by the Coordinator's mesh API. The federated bundles are keyed by trust domain name.
+// The leaf certificate must contain a SPIFFE ID.
+func (s *Server) Update(certChainPEM []byte, key crypto.PrivateKey, meshCACertPEM, crlPEM []byte, federatedBundlesPEM map[string][]byte) error {
+	certChain := decodePEM(certChainPEM, "CERTIFICATE")
+	if len(certChain) == 0 {
+		return errors.New("certificate chain is empty")
+	}
+	leaf, err := x509.ParseCertificate(certChain[0])
+	if err != nil {
+		return fmt.Errorf("parsing mesh certificate: %w", err)
+	}
+	var id string
+	var trustDomain string
+	for _, uri := range leaf.URIs {
+		if uri.Scheme == spiffe.Scheme {
+			id, trustDomain = uri.String(), spiffe.Scheme+"://"+uri.Host
+			break
+		}
+	}
+	if id == "" {
+		return ErrNoSPIFFEID
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("marshaling private key: %w", err)
+	}
+	bundle := bytes.Join(decodePEM(meshCACertPEM, "CERTIFICATE"), nil)
+	if len(bundle) == 0 {
+		return errors.New("mesh CA certificate is empty")
+	}
+	crls := decodePEM(crlPEM, "X509 CRL")
+	var federatedBundles map[string][]byte
+	for name, bundlePEM := range federatedBundlesPEM {
+		federatedBundle := bytes.Join(decodePEM(bundlePEM, "CERTIFICATE"), nil)
+		if len(federatedBundle) == 0 {
+			return fmt.Errorf("federated bundle of trust domain %q is empty", name)
+		}
+		if federatedBundles == nil {
+			federatedBundles = make(map[string][]byte, len(federatedBundlesPEM))
+		}
+		federatedBundles[spiffe.Scheme+"://"+name] = federatedBundle
+	}
+	allBundles := maps.Clone(federatedBundles)
+	if allBundles == nil {
+		allBundles = make(map[string][]byte, 1)
+	}
+	allBundles[trustDomain] = bundle
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.svid = &X509SVIDResponse{
+		Svids: []*X509SVID{{
+			SpiffeId:    id,
+			X509Svid:    bytes.Join(certChain, nil),
+			X509SvidKey: keyDER,
+			Bundle:      bundle,
+		}},
+		Crl:              crls,
+		FederatedBundles: federatedBundles,
+	}
+	s.bundles = &X509BundlesResponse{
+		Crl:     crls,
+		Bundles: allBundles,
+	}
+	close(s.updated)
+	s.updated = make(chan struct{})
+	return nil
+}
+
+// FetchX509SVID streams the SVID of the workload to the client.
+func (s *Server) FetchX509SVID(_ *X509SVIDRequest, stream grpc.ServerStreamingServer[X509SVIDResponse]) error {
+	return s.stream(stream.Context(), func(svid *X509SVIDResponse, _ *X509BundlesResponse) error {
+		return stream.Send(svid)
+	})
+}
+
+// FetchX509Bundles streams the trust bundle of the workload's trust domain to the client.
+func (s *Server) FetchX509Bundles(_ *X509BundlesRequest, stream grpc.ServerStreamingServer[X509BundlesResponse]) error {
+	return s.stream(stream.Context(), func(_ *X509SVIDResponse, bundles *X509BundlesResponse) error {
+		return stream.Send(bundles)
+	})
+}
+
+// Serve serves the Workload API on the given listener until ctx is done.
+func (s *Server) Serve(ctx context.Context, lis net.Listener) error {
+	grpcServer := grpc.NewServer()
+	RegisterSpiffeWorkloadAPIServer(grpcServer, s)
+	go func() {
+		<-ctx.Done()
+		// Streams never end on their own, so there is nothing to wait for.
+		grpcServer.Stop()
+	}()
+	return grpcServer.Serve(lis)
+}
+
+// Listen creates the Unix socket the Workload API is served on, replacing a stale socket left by
+// a previous run.
+func Listen(socketPath string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(socketPath), 0o755); err != nil {
+		return nil, fmt.Errorf("creating socket directory: %w", err)
+	}
+	if err := os.Remove(socketPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("removing stale socket: %w", err)
+	}
+	lis, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("listening on %s: %w", socketPath, err)
+	}
+	// Containers of the pod may run as any user.
+	if err := os.Chmod(socketPath, 0o777); err != nil {
+		lis.Close()
+		return nil, fmt.Errorf("changing socket permissions: %w", err)
+	}
+	return lis, nil
+}
+
+// stream sends the current SVID, and every update of it, until the client disconnects.
+func (s *Server) stream(ctx context.Context, send func(*X509SVIDResponse, *X509BundlesResponse) error) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(securityHeader); len(values) != 1 || values[0] != "true" {
+		return status.Error(codes.InvalidArgument, "security header missing from request")
+	}
+
+	for {
+		s.mu.Lock()
+		svid, bundles, updated := s.svid, s.bundles, s.updated
+		s.mu.Unlock()
+
+		if svid != nil {
+			if err := send(svid, bundles); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-updated:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// decodePEM returns the DER contents of all PEM blocks of the given type.
+func decodePEM(data []byte, blockType string) [][]byte {
+	var ders [][]byte
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return ders
+		}
+		if block.Type == blockType {
+			ders = append(ders, block.Bytes)
+		}
+	}
+}
@@ -0,0 +1,413 @@
+// Copied from github.com/spiffe/go-spiffe/v2/proto/spiffe/workload/workload.proto
+// Version: 2.5.0
+// Reduced to the X.509 methods, which are the only ones served by Contrast.
+
+//
+// Copyright 2017 The SPIFFE Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v7.34.1
+// source: workloadapi.proto
+
+package workloadapi
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// The X509SVIDRequest message conveys parameters for requesting an X.509-SVID.
+// There are currently no request parameters.
+type X509SVIDRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *X509SVIDRequest) Reset() {
+	*x = X509SVIDRequest{}
+	mi := &file_workloadapi_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *X509SVIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*X509SVIDRequest) ProtoMessage() {}
+
+func (x *X509SVIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_workloadapi_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use X509SVIDRequest.ProtoReflect.Descriptor instead.
+func (*X509SVIDRequest) Descriptor() ([]byte, []int) {
+	return file_workloadapi_proto_rawDescGZIP(), []int{0}
+}
+
+// The X509SVIDResponse message carries X.509-SVIDs and related information,
+// including a set of global CRLs and a list of bundles the workload may use
+// for federating with foreign trust domains.
+type X509SVIDResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Required. A list of X509SVID messages, each of which includes a single
+	// X.509-SVID, its private key, and the bundle for the trust domain.
+	Svids []*X509SVID `protobuf:"bytes,1,rep,name=svids,proto3" json:"svids,omitempty"`
+	// Optional. ASN.1 DER encoded certificate revocation lists.
+	Crl [][]byte `protobuf:"bytes,2,rep,name=crl,proto3" json:"crl,omitempty"`
+	// Optional. CA certificate bundles belonging to foreign trust domains that
+	// the workload should trust, keyed by the SPIFFE ID of the foreign trust
+	// domain. Bundles are ASN.1 DER encoded.
+	FederatedBundles map[string][]byte `protobuf:"bytes,3,rep,name=federated_bundles,json=federatedBundles,proto3" json:"federated_bundles,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *X509SVIDResponse) Reset() {
+	*x = X509SVIDResponse{}
+	mi := &file_workloadapi_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *X509SVIDResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*X509SVIDResponse) ProtoMessage() {}
+
+func (x *X509SVIDResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_workloadapi_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use X509SVIDResponse.ProtoReflect.Descriptor instead.
+func (*X509SVIDResponse) Descriptor() ([]byte, []int) {
+	return file_workloadapi_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *X509SVIDResponse) GetSvids() []*X509SVID {
+	if x != nil {
+		return x.Svids
+	}
+	return nil
+}
+
+func (x *X509SVIDResponse) GetCrl() [][]byte {
+	if x != nil {
+		return x.Crl
+	}
+	return nil
+}
+
+func (x *X509SVIDResponse) GetFederatedBundles() map[string][]byte {
+	if x != nil {
+		return x.FederatedBundles
+	}
+	return nil
+}
+
+// The X509SVID message carries a single SVID and all associated information,
+// including the X.509 bundle for the trust domain.
+type X509SVID struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Required. The SPIFFE ID of the SVID in this entry
+	SpiffeId string `protobuf:"bytes,1,opt,name=spiffe_id,json=spiffeId,proto3" json:"spiffe_id,omitempty"`
+	// Required. ASN.1 DER encoded certificate chain. MAY include
+	// intermediates, the leaf certificate (or SVID itself) MUST come first.
+	X509Svid []byte `protobuf:"bytes,2,opt,name=x509_svid,json=x509Svid,proto3" json:"x509_svid,omitempty"`
+	// Required. ASN.1 DER encoded PKCS#8 private key. MUST be unencrypted.
+	X509SvidKey []byte `protobuf:"bytes,3,opt,name=x509_svid_key,json=x509SvidKey,proto3" json:"x509_svid_key,omitempty"`
+	// Required. ASN.1 DER encoded X.509 bundle for the trust domain.
+	Bundle []byte `protobuf:"bytes,4,opt,name=bundle,proto3" json:"bundle,omitempty"`
+	// Optional. An operator-specified string used to provide guidance on how this
+	// identity should be used by a workload when more than one SVID is returned.
+	// For example, `internal` and `external` to indicate an SVID for internal or
+	// external use, respectively.
+	Hint          string `protobuf:"bytes,5,opt,name=hint,proto3" json:"hint,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *X509SVID) Reset() {
+	*x = X509SVID{}
+	mi := &file_workloadapi_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *X509SVID) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*X509SVID) ProtoMessage() {}
+
+func (x *X509SVID) ProtoReflect() protoreflect.Message {
+	mi := &file_workloadapi_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use X509SVID.ProtoReflect.Descriptor instead.
+func (*X509SVID) Descriptor() ([]byte, []int) {
+	return file_workloadapi_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *X509SVID) GetSpiffeId() string {
+	if x != nil {
+		return x.SpiffeId
+	}
+	return ""
+}
+
+func (x *X509SVID) GetX509Svid() []byte {
+	if x != nil {
+		return x.X509Svid
+	}
+	return nil
+}
+
+func (x *X509SVID) GetX509SvidKey() []byte {
+	if x != nil {
+		return x.X509SvidKey
+	}
+	return nil
+}
+
+func (x *X509SVID) GetBundle() []byte {
+	if x != nil {
+		return x.Bundle
+	}
+	return nil
+}
+
+func (x *X509SVID) GetHint() string {
+	if x != nil {
+		return x.Hint
+	}
+	return ""
+}
+
+// The X509BundlesRequest message conveys parameters for requesting X.509
+// bundles. There are currently no such parameters.
+type X509BundlesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *X509BundlesRequest) Reset() {
+	*x = X509BundlesRequest{}
+	mi := &file_workloadapi_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *X509BundlesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*X509BundlesRequest) ProtoMessage() {}
+
+func (x *X509BundlesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_workloadapi_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use X509BundlesRequest.ProtoReflect.Descriptor instead.
+func (*X509BundlesRequest) Descriptor() ([]byte, []int) {
+	return file_workloadapi_proto_rawDescGZIP(), []int{3}
+}
+
+// The X509BundlesResponse message carries a set of global CRLs and a map of
+// trust bundles the workload should trust.
+type X509BundlesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Optional. ASN.1 DER encoded certificate revocation lists.
+	Crl [][]byte `protobuf:"bytes,1,rep,name=crl,proto3" json:"crl,omitempty"`
+	// Required. CA certificate bundles belonging to trust domains that the
+	// workload should trust, keyed by the SPIFFE ID of the trust domain.
+	// Bundles are ASN.1 DER encoded.
+	Bundles       map[string][]byte `protobuf:"bytes,2,rep,name=bundles,proto3" json:"bundles,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *X509BundlesResponse) Reset() {
+	*x = X509BundlesResponse{}
+	mi := &file_workloadapi_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *X509BundlesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*X509BundlesResponse) ProtoMessage() {}
+
+func (x *X509BundlesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_workloadapi_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use X509BundlesResponse.ProtoReflect.Descriptor instead.
+func (*X509BundlesResponse) Descriptor() ([]byte, []int) {
+	return file_workloadapi_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *X509BundlesResponse) GetCrl() [][]byte {
+	if x != nil {
+		return x.Crl
+	}
+	return nil
+}
+
+func (x *X509BundlesResponse) GetBundles() map[string][]byte {
+	if x != nil {
+		return x.Bundles
+	}
+	return nil
+}
+
+var File_workloadapi_proto protoreflect.FileDescriptor
+
+const file_workloadapi_proto_rawDesc = "" +
+	"\n" +
+	"\x11workloadapi.proto\"\x11\n" +
+	"\x0fX509SVIDRequest\"\xe0\x01\n" +
+	"\x10X509SVIDResponse\x12\x1f\n" +
+	"\x05svids\x18\x01 \x03(\v2\t.X509SVIDR\x05svids\x12\x10\n" +
+	"\x03crl\x18\x02 \x03(\fR\x03crl\x12T\n" +
+	"\x11federated_bundles\x18\x03 \x03(\v2'.X509SVIDResponse.FederatedBundlesEntryR\x10federatedBundles\x1aC\n" +
+	"\x15FederatedBundlesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value:\x028\x01\"\x94\x01\n" +
+	"\bX509SVID\x12\x1b\n" +
+	"\tspiffe_id\x18\x01 \x01(\tR\bspiffeId\x12\x1b\n" +
+	"\tx509_svid\x18\x02 \x01(\fR\bx509Svid\x12\"\n" +
+	"\rx509_svid_key\x18\x03 \x01(\fR\vx509SvidKey\x12\x16\n" +
+	"\x06bundle\x18\x04 \x01(\fR\x06bundle\x12\x12\n" +
+	"\x04hint\x18\x05 \x01(\tR\x04hint\"\x14\n" +
+	"\x12X509BundlesRequest\"\xa0\x01\n" +
+	"\x13X509BundlesResponse\x12\x10\n" +
+	"\x03crl\x18\x01 \x03(\fR\x03crl\x12;\n" +
+	"\abundles\x18\x02 \x03(\v2!.X509BundlesResponse.BundlesEntryR\abundles\x1a:\n" +
+	"\fBundlesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value:\x028\x012\x8c\x01\n" +
+	"\x11SpiffeWorkloadAPI\x126\n" +
+	"\rFetchX509SVID\x12\x10.X509SVIDRequest\x1a\x11.X509SVIDResponse0\x01\x12?\n" +
+	"\x10FetchX509Bundles\x12\x13.X509BundlesRequest\x1a\x14.X509BundlesResponse0\x01B=Z;github.com/edgelesssys/contrast/internal/spiffe/workloadapib\x06proto3"
+
+var (
+	file_workloadapi_proto_rawDescOnce sync.Once
+	file_workloadapi_proto_rawDescData []byte
+)
+
+func file_workloadapi_proto_rawDescGZIP() []byte {
+	file_workloadapi_proto_rawDescOnce.Do(func() {
+		file_workloadapi_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_workloadapi_proto_rawDesc), len(file_workloadapi_proto_rawDesc)))
+	})
+	return file_workloadapi_proto_rawDescData
+}
+
+var file_workloadapi_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_workloadapi_proto_goTypes = []any{
+	(*X509SVIDRequest)(nil),     // 0: X509SVIDRequest
+	(*X509SVIDResponse)(nil),    // 1: X509SVIDResponse
+	(*X509SVID)(nil),            // 2: X509SVID
+	(*X509BundlesRequest)(nil),  // 3: X509BundlesRequest
+	(*X509BundlesResponse)(nil), // 4: X509BundlesResponse
+	nil,                         // 5: X509SVIDResponse.FederatedBundlesEntry
+	nil,                         // 6: X509BundlesResponse.BundlesEntry
+}
+var file_workloadapi_proto_depIdxs = []int32{
+	2, // 0: X509SVIDResponse.svids:type_name -> X509SVID
+	5, // 1: X509SVIDResponse.federated_bundles:type_name -> X509SVIDResponse.FederatedBundlesEntry
+	6, // 2: X509BundlesResponse.bundles:type_name -> X509BundlesResponse.BundlesEntry
+	0, // 3: SpiffeWorkloadAPI.FetchX509SVID:input_type -> X509SVIDRequest
+	3, // 4: SpiffeWorkloadAPI.FetchX509Bundles:input_type -> X509BundlesRequest
+	1, // 5: SpiffeWorkloadAPI.FetchX509SVID:output_type -> X509SVIDResponse
+	4, // 6: SpiffeWorkloadAPI.FetchX509Bundles:output_type -> X509BundlesResponse
+	5, // [5:7] is the sub-list for method output_type
+	3, // [3:5] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_workloadapi_proto_init() }
+func file_workloadapi_proto_init() {
+	if File_workloadapi_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_workloadapi_proto_rawDesc), len(file_workloadapi_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_workloadapi_proto_goTypes,
+		DependencyIndexes: file_workloadapi_proto_depIdxs,
+		MessageInfos:      file_workloadapi_proto_msgTypes,
+	}.Build()
+	File_workloadapi_proto = out.File
+	file_workloadapi_proto_goTypes = nil
+	file_workloadapi_proto_depIdxs = nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 Edgeless Systems GmbH
+// SPDX-License-Identifier: BUSL-1.1
+
+package workloadapi
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/edgelesssys/contrast/internal/ca"
+	"github.com/edgelesssys/contrast/internal/testkeys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestServer(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	meshCA, err := ca.New(testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[0]), testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[1]))
+	require.NoError(err)
+	crl, err := meshCA.CreateCRL(nil, big.NewInt(1), time.Now().Add(time.Hour))
+	require.NoError(err)
+
+	peerCA, err := ca.New(testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[0]), testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[2]))
+	require.NoError(err)
+	federatedBundles := map[string][]byte{"peer.example.org": peerCA.GetMeshCACert()}
+
+	server := NewServer()
+	key1, certChain1 := newSVID(t, meshCA, "spiffe://example.org/ns/default/sa/web")
+	require.NoError(server.Update(certChain1, key1, meshCA.GetMeshCACert(), crl, federatedBundles))
+
+	lis, err := Listen(filepath.Join(t.TempDir(), "spiffe", "agent.sock"))
+	require.NoError(err)
+	go func() {
+		_ = server.Serve(t.Context(), lis)
+	}()
+	conn, err := grpc.NewClient("unix://"+lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(err)
+	defer conn.Close()
+	client := NewSpiffeWorkloadAPIClient(conn)
+
+	// Requests without the security header are rejected.
+	stream, err := client.FetchX509SVID(t.Context(), &X509SVIDRequest{})
+	require.NoError(err)
+	_, err = stream.Recv()
+	assert.Equal(codes.InvalidArgument, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(t.Context(), securityHeader, "true")
+	stream, err = client.FetchX509SVID(ctx, &X509SVIDRequest{})
+	require.NoError(err)
+	resp, err := stream.Recv()
+	require.NoError(err)
+	require.Len(resp.Svids, 1)
+	svid := resp.Svids[0]
+	assert.Equal("spiffe://example.org/ns/default/sa/web", svid.SpiffeId)
+	certs, err := x509.ParseCertificates(svid.X509Svid)
+	require.NoError(err)
+	require.Len(certs, 2)
+	assert.Equal(svid.SpiffeId, certs[0].URIs[0].String())
+	key, err := x509.ParsePKCS8PrivateKey(svid.X509SvidKey)
+	require.NoError(err)
+	assert.True(key1.Equal(key))
+	bundle, err := x509.ParseCertificates(svid.Bundle)
+	require.NoError(err)
+	require.Len(bundle, 1)
+	_, err = certs[0].Verify(x509.VerifyOptions{Roots: certPool(bundle[0])})
+	assert.NoError(err)
+	require.Len(resp.Crl, 1)
+	_, err = x509.ParseRevocationList(resp.Crl[0])
+	assert.NoError(err)
+	peerBlock, _ := pem.Decode(peerCA.GetMeshCACert())
+	require.NotNil(peerBlock)
+	assert.Equal(map[string][]byte{"spiffe://peer.example.org": peerBlock.Bytes}, resp.FederatedBundles)
+
+	bundleStream, err := client.FetchX509Bundles(ctx, &X509BundlesRequest{})
+	require.NoError(err)
+	bundlesResp, err := bundleStream.Recv()
+	require.NoError(err)
+	assert.Equal(map[string][]byte{
+		"spiffe://example.org":      svid.Bundle,
+		"spiffe://peer.example.org": peerBlock.Bytes,
+	}, bundlesResp.Bundles)
+
+	// Updates are streamed to connected clients.
+	key2, certChain2 := newSVID(t, meshCA, "spiffe://example.org/ns/default/sa/web")
+	require.NoError(server.Update(certChain2, key2, meshCA.GetMeshCACert(), crl, nil))
+	resp, err = stream.Recv()
+	require.NoError(err)
+	key, err = x509.ParsePKCS8PrivateKey(resp.Svids[0].X509SvidKey)
+	require.NoError(err)
+	assert.True(key2.Equal(key))
+	_, err = bundleStream.Recv()
+	require.NoError(err)
+}
+
+func TestUpdate_NoSPIFFEID(t *testing.T) {
+	meshCA, err := ca.New(testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[0]), testkeys.New[ecdsa.PrivateKey](t, testkeys.ECDSAP384Keys[1]))
+	require.NoError(t, err)
+	key, certChain := newSVID(t, meshCA, "https://example.org/web")
+
+	err = NewServer().Update(certChain, key, meshCA.GetMeshCACert(), nil, nil)
+	require.ErrorIs(t, err, ErrNoSPIFFEID)
+}
+
+func newSVID(t *testing.T, meshCA *ca.CA, uri string) (*ecdsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	certPEM, err := meshCA.NewAttestedMeshCert([]string{"web", uri}, nil, key.Public(), ca.MeshCertOptions{Lifetime: time.Hour})
+	require.NoError(t, err)
+	return key, append(certPEM, meshCA.GetIntermCACert()...)
+}
+
+func certPool(certs ...*x509.Certificate) *x509.CertPool {
+	pool := x509.NewCertPool()
+	for _, cert := range certs {
+		pool.AddCert(cert)
+	}
+	return pool
+}
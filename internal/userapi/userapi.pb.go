@@ -27,8 +27,14 @@ type SetManifestRequest struct {
 	Policies               [][]byte               `protobuf:"bytes,2,rep,name=Policies,proto3" json:"Policies,omitempty"`
 	PreviousTransitionHash []byte                 `protobuf:"bytes,3,opt,name=PreviousTransitionHash,proto3" json:"PreviousTransitionHash,omitempty"`
 	Signature              []byte                 `protobuf:"bytes,4,opt,name=Signature,proto3" json:"Signature,omitempty"`
-	unknownFields          protoimpl.UnknownFields
-	sizeCache              protoimpl.SizeCache
+	// Additional workload owner signatures over the next transition hash, for manifests that
+	// require the approval of more than one workload owner.
+	Signatures [][]byte `protobuf:"bytes,5,rep,name=Signatures,proto3" json:"Signatures,omitempty"`
+	// Time at which the update becomes active, in seconds since the Unix epoch. If unset or not in
+	// the future, the update is applied immediately.
+	ActivationTime int64 `protobuf:"varint,6,opt,name=ActivationTime,proto3" json:"ActivationTime,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *SetManifestRequest) Reset() {
@@ -89,6 +95,20 @@ func (x *SetManifestRequest) GetSignature() []byte {
 	return nil
 }
 
+func (x *SetManifestRequest) GetSignatures() [][]byte {
+	if x != nil {
+		return x.Signatures
+	}
+	return nil
+}
+
+func (x *SetManifestRequest) GetActivationTime() int64 {
+	if x != nil {
+		return x.ActivationTime
+	}
+	return 0
+}
+
 type SetManifestResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// PEM-encoded certificate
@@ -97,6 +117,9 @@ type SetManifestResponse struct {
 	MeshCA []byte `protobuf:"bytes,2,opt,name=MeshCA,proto3" json:"MeshCA,omitempty"`
 	// Secret seed (share), encrypted with each of the recovery holders' public keys.
 	SeedSharesDoc *SeedShareDocument `protobuf:"bytes,3,opt,name=SeedSharesDoc,proto3" json:"SeedSharesDoc,omitempty"`
+	// The scheduled update, if the request had an activation time in the future. The CA fields are
+	// unset in this case.
+	PendingUpdate *PendingUpdate `protobuf:"bytes,4,opt,name=PendingUpdate,proto3" json:"PendingUpdate,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -152,6 +175,13 @@ func (x *SetManifestResponse) GetSeedSharesDoc() *SeedShareDocument {
 	return nil
 }
 
+func (x *SetManifestResponse) GetPendingUpdate() *PendingUpdate {
+	if x != nil {
+		return x.PendingUpdate
+	}
+	return nil
+}
+
 type SeedShareDocument struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SeedShares    []*SeedShare           `protobuf:"bytes,1,rep,name=SeedShares,proto3" json:"SeedShares,omitempty"`
@@ -301,8 +331,21 @@ type GetManifestsResponse struct {
 	// PEM-encoded certificate
 	MeshCA           []byte            `protobuf:"bytes,4,opt,name=MeshCA,proto3" json:"MeshCA,omitempty"`
 	LatestTransition *LatestTransition `protobuf:"bytes,5,opt,name=LatestTransition,proto3" json:"LatestTransition,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+	// Detached workload owner signatures of the transitions in the history, if any.
+	TransitionSignatures []*TransitionSignature `protobuf:"bytes,6,rep,name=TransitionSignatures,proto3" json:"TransitionSignatures,omitempty"`
+	// Checkpoint of the pruned part of the history. Unset if the history is complete.
+	// If set, the oldest manifest is a successor of the checkpoint's transition.
+	Checkpoint *HistoryCheckpoint `protobuf:"bytes,7,opt,name=Checkpoint,proto3" json:"Checkpoint,omitempty"`
+	// Manifest update that's scheduled for later activation, if any.
+	PendingUpdate *PendingUpdate `protobuf:"bytes,8,opt,name=PendingUpdate,proto3" json:"PendingUpdate,omitempty"`
+	// PEM-encoded public key of the Coordinator's transaction signing key, which signs the history
+	// and webhook notifications.
+	SigningKey []byte `protobuf:"bytes,9,opt,name=SigningKey,proto3" json:"SigningKey,omitempty"`
+	// PEM-encoded CRL of the mesh CA. It's valid as long as the mesh CA certificate, so that it can
+	// be handed out to workloads of federated deployments along with their certificates.
+	CRL           []byte `protobuf:"bytes,10,opt,name=CRL,proto3" json:"CRL,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetManifestsResponse) Reset() {
@@ -370,28 +413,66 @@ func (x *GetManifestsResponse) GetLatestTransition() *LatestTransition {
 	return nil
 }
 
-type LatestTransition struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	TransitionHash []byte                 `protobuf:"bytes,1,opt,name=TransitionHash,proto3" json:"TransitionHash,omitempty"`
-	Signature      []byte                 `protobuf:"bytes,2,opt,name=Signature,proto3" json:"Signature,omitempty"`
+func (x *GetManifestsResponse) GetTransitionSignatures() []*TransitionSignature {
+	if x != nil {
+		return x.TransitionSignatures
+	}
+	return nil
+}
+
+func (x *GetManifestsResponse) GetCheckpoint() *HistoryCheckpoint {
+	if x != nil {
+		return x.Checkpoint
+	}
+	return nil
+}
+
+func (x *GetManifestsResponse) GetPendingUpdate() *PendingUpdate {
+	if x != nil {
+		return x.PendingUpdate
+	}
+	return nil
+}
+
+func (x *GetManifestsResponse) GetSigningKey() []byte {
+	if x != nil {
+		return x.SigningKey
+	}
+	return nil
+}
+
+func (x *GetManifestsResponse) GetCRL() []byte {
+	if x != nil {
+		return x.CRL
+	}
+	return nil
+}
+
+type PendingUpdate struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Hash of the transition that becomes the latest transition.
+	TransitionHash []byte `protobuf:"bytes,1,opt,name=TransitionHash,proto3" json:"TransitionHash,omitempty"`
+	// Time at which the update becomes active, in seconds since the Unix epoch.
+	ActivationTime int64  `protobuf:"varint,2,opt,name=ActivationTime,proto3" json:"ActivationTime,omitempty"`
+	Manifest       []byte `protobuf:"bytes,3,opt,name=Manifest,proto3" json:"Manifest,omitempty"`
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
 
-func (x *LatestTransition) Reset() {
-	*x = LatestTransition{}
+func (x *PendingUpdate) Reset() {
+	*x = PendingUpdate{}
 	mi := &file_userapi_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *LatestTransition) String() string {
+func (x *PendingUpdate) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LatestTransition) ProtoMessage() {}
+func (*PendingUpdate) ProtoMessage() {}
 
-func (x *LatestTransition) ProtoReflect() protoreflect.Message {
+func (x *PendingUpdate) ProtoReflect() protoreflect.Message {
 	mi := &file_userapi_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -403,48 +484,57 @@ func (x *LatestTransition) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LatestTransition.ProtoReflect.Descriptor instead.
-func (*LatestTransition) Descriptor() ([]byte, []int) {
+// Deprecated: Use PendingUpdate.ProtoReflect.Descriptor instead.
+func (*PendingUpdate) Descriptor() ([]byte, []int) {
 	return file_userapi_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *LatestTransition) GetTransitionHash() []byte {
+func (x *PendingUpdate) GetTransitionHash() []byte {
 	if x != nil {
 		return x.TransitionHash
 	}
 	return nil
 }
 
-func (x *LatestTransition) GetSignature() []byte {
+func (x *PendingUpdate) GetActivationTime() int64 {
 	if x != nil {
-		return x.Signature
+		return x.ActivationTime
+	}
+	return 0
+}
+
+func (x *PendingUpdate) GetManifest() []byte {
+	if x != nil {
+		return x.Manifest
 	}
 	return nil
 }
 
-type RecoverRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Seed          []byte                 `protobuf:"bytes,1,opt,name=Seed,proto3" json:"Seed,omitempty"`
-	Salt          []byte                 `protobuf:"bytes,2,opt,name=Salt,proto3" json:"Salt,omitempty"`
-	Force         bool                   `protobuf:"varint,3,opt,name=Force,proto3" json:"Force,omitempty"`
+type CancelPendingUpdateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Hash of the transition of the pending update.
+	TransitionHash []byte `protobuf:"bytes,1,opt,name=TransitionHash,proto3" json:"TransitionHash,omitempty"`
+	// Workload owner signatures over the cancellation of the pending update, for manifests that
+	// require the approval of more than one workload owner.
+	Signatures    [][]byte `protobuf:"bytes,2,rep,name=Signatures,proto3" json:"Signatures,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RecoverRequest) Reset() {
-	*x = RecoverRequest{}
+func (x *CancelPendingUpdateRequest) Reset() {
+	*x = CancelPendingUpdateRequest{}
 	mi := &file_userapi_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RecoverRequest) String() string {
+func (x *CancelPendingUpdateRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RecoverRequest) ProtoMessage() {}
+func (*CancelPendingUpdateRequest) ProtoMessage() {}
 
-func (x *RecoverRequest) ProtoReflect() protoreflect.Message {
+func (x *CancelPendingUpdateRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_userapi_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -456,52 +546,45 @@ func (x *RecoverRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RecoverRequest.ProtoReflect.Descriptor instead.
-func (*RecoverRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use CancelPendingUpdateRequest.ProtoReflect.Descriptor instead.
+func (*CancelPendingUpdateRequest) Descriptor() ([]byte, []int) {
 	return file_userapi_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *RecoverRequest) GetSeed() []byte {
+func (x *CancelPendingUpdateRequest) GetTransitionHash() []byte {
 	if x != nil {
-		return x.Seed
+		return x.TransitionHash
 	}
 	return nil
 }
 
-func (x *RecoverRequest) GetSalt() []byte {
+func (x *CancelPendingUpdateRequest) GetSignatures() [][]byte {
 	if x != nil {
-		return x.Salt
+		return x.Signatures
 	}
 	return nil
 }
 
-func (x *RecoverRequest) GetForce() bool {
-	if x != nil {
-		return x.Force
-	}
-	return false
-}
-
-type RecoverResponse struct {
+type CancelPendingUpdateResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RecoverResponse) Reset() {
-	*x = RecoverResponse{}
+func (x *CancelPendingUpdateResponse) Reset() {
+	*x = CancelPendingUpdateResponse{}
 	mi := &file_userapi_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RecoverResponse) String() string {
+func (x *CancelPendingUpdateResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RecoverResponse) ProtoMessage() {}
+func (*CancelPendingUpdateResponse) ProtoMessage() {}
 
-func (x *RecoverResponse) ProtoReflect() protoreflect.Message {
+func (x *CancelPendingUpdateResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_userapi_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -513,52 +596,1306 @@ func (x *RecoverResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RecoverResponse.ProtoReflect.Descriptor instead.
-func (*RecoverResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use CancelPendingUpdateResponse.ProtoReflect.Descriptor instead.
+func (*CancelPendingUpdateResponse) Descriptor() ([]byte, []int) {
 	return file_userapi_proto_rawDescGZIP(), []int{8}
 }
 
-var File_userapi_proto protoreflect.FileDescriptor
+type RollbackRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Hash of the transition whose manifest is re-activated.
+	TransitionHash []byte `protobuf:"bytes,1,opt,name=TransitionHash,proto3" json:"TransitionHash,omitempty"`
+	// If set, the rollback is only applied if this is the latest transition.
+	PreviousTransitionHash []byte `protobuf:"bytes,2,opt,name=PreviousTransitionHash,proto3" json:"PreviousTransitionHash,omitempty"`
+	// Workload owner signatures over the hash of the new transition, as for SetManifest.
+	Signatures    [][]byte `protobuf:"bytes,3,rep,name=Signatures,proto3" json:"Signatures,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_userapi_proto_rawDesc = "" +
-	"\n" +
-	"\ruserapi.proto\x12\x1cedgelesssys.contrast.userapi\"\xa2\x01\n" +
-	"\x12SetManifestRequest\x12\x1a\n" +
-	"\bManifest\x18\x01 \x01(\fR\bManifest\x12\x1a\n" +
-	"\bPolicies\x18\x02 \x03(\fR\bPolicies\x126\n" +
-	"\x16PreviousTransitionHash\x18\x03 \x01(\fR\x16PreviousTransitionHash\x12\x1c\n" +
-	"\tSignature\x18\x04 \x01(\fR\tSignature\"\x9c\x01\n" +
-	"\x13SetManifestResponse\x12\x16\n" +
-	"\x06RootCA\x18\x01 \x01(\fR\x06RootCA\x12\x16\n" +
-	"\x06MeshCA\x18\x02 \x01(\fR\x06MeshCA\x12U\n" +
-	"\rSeedSharesDoc\x18\x03 \x01(\v2/.edgelesssys.contrast.userapi.SeedShareDocumentR\rSeedSharesDoc\"p\n" +
-	"\x11SeedShareDocument\x12G\n" +
-	"\n" +
-	"SeedShares\x18\x01 \x03(\v2'.edgelesssys.contrast.userapi.SeedShareR\n" +
-	"SeedShares\x12\x12\n" +
-	"\x04salt\x18\x02 \x01(\fR\x04salt\"O\n" +
-	"\tSeedShare\x12\x1c\n" +
-	"\tPublicKey\x18\x01 \x01(\tR\tPublicKey\x12$\n" +
-	"\rEncryptedSeed\x18\x02 \x01(\fR\rEncryptedSeed\"\x15\n" +
-	"\x13GetManifestsRequest\"\xdc\x01\n" +
-	"\x14GetManifestsResponse\x12\x1c\n" +
-	"\tManifests\x18\x01 \x03(\fR\tManifests\x12\x1a\n" +
-	"\bPolicies\x18\x02 \x03(\fR\bPolicies\x12\x16\n" +
-	"\x06RootCA\x18\x03 \x01(\fR\x06RootCA\x12\x16\n" +
-	"\x06MeshCA\x18\x04 \x01(\fR\x06MeshCA\x12Z\n" +
-	"\x10LatestTransition\x18\x05 \x01(\v2..edgelesssys.contrast.userapi.LatestTransitionR\x10LatestTransition\"X\n" +
-	"\x10LatestTransition\x12&\n" +
-	"\x0eTransitionHash\x18\x01 \x01(\fR\x0eTransitionHash\x12\x1c\n" +
-	"\tSignature\x18\x02 \x01(\fR\tSignature\"N\n" +
-	"\x0eRecoverRequest\x12\x12\n" +
-	"\x04Seed\x18\x01 \x01(\fR\x04Seed\x12\x12\n" +
-	"\x04Salt\x18\x02 \x01(\fR\x04Salt\x12\x14\n" +
-	"\x05Force\x18\x03 \x01(\bR\x05Force\"\x11\n" +
-	"\x0fRecoverResponse2\xdc\x02\n" +
-	"\aUserAPI\x12r\n" +
-	"\vSetManifest\x120.edgelesssys.contrast.userapi.SetManifestRequest\x1a1.edgelesssys.contrast.userapi.SetManifestResponse\x12u\n" +
-	"\fGetManifests\x121.edgelesssys.contrast.userapi.GetManifestsRequest\x1a2.edgelesssys.contrast.userapi.GetManifestsResponse\x12f\n" +
-	"\aRecover\x12,.edgelesssys.contrast.userapi.RecoverRequest\x1a-.edgelesssys.contrast.userapi.RecoverResponseB2Z0github.com/edgelesssys/contrast/internal/userapib\x06proto3"
+func (x *RollbackRequest) Reset() {
+	*x = RollbackRequest{}
+	mi := &file_userapi_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RollbackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RollbackRequest) ProtoMessage() {}
+
+func (x *RollbackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_userapi_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RollbackRequest.ProtoReflect.Descriptor instead.
+func (*RollbackRequest) Descriptor() ([]byte, []int) {
+	return file_userapi_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RollbackRequest) GetTransitionHash() []byte {
+	if x != nil {
+		return x.TransitionHash
+	}
+	return nil
+}
+
+func (x *RollbackRequest) GetPreviousTransitionHash() []byte {
+	if x != nil {
+		return x.PreviousTransitionHash
+	}
+	return nil
+}
+
+func (x *RollbackRequest) GetSignatures() [][]byte {
+	if x != nil {
+		return x.Signatures
+	}
+	return nil
+}
+
+type RollbackResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// PEM-encoded certificate
+	RootCA []byte `protobuf:"bytes,1,opt,name=RootCA,proto3" json:"RootCA,omitempty"`
+	// PEM-encoded certificate
+	MeshCA []byte `protobuf:"bytes,2,opt,name=MeshCA,proto3" json:"MeshCA,omitempty"`
+	// Hash of the new latest transition.
+	TransitionHash []byte `protobuf:"bytes,3,opt,name=TransitionHash,proto3" json:"TransitionHash,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RollbackResponse) Reset() {
+	*x = RollbackResponse{}
+	mi := &file_userapi_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RollbackResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RollbackResponse) ProtoMessage() {}
+
+func (x *RollbackResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_userapi_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RollbackResponse.ProtoReflect.Descriptor instead.
+func (*RollbackResponse) Descriptor() ([]byte, []int) {
+	return file_userapi_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RollbackResponse) GetRootCA() []byte {
+	if x != nil {
+		return x.RootCA
+	}
+	return nil
+}
+
+func (x *RollbackResponse) GetMeshCA() []byte {
+	if x != nil {
+		return x.MeshCA
+	}
+	return nil
+}
+
+func (x *RollbackResponse) GetTransitionHash() []byte {
+	if x != nil {
+		return x.TransitionHash
+	}
+	return nil
+}
+
+type LatestTransition struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TransitionHash []byte                 `protobuf:"bytes,1,opt,name=TransitionHash,proto3" json:"TransitionHash,omitempty"`
+	Signature      []byte                 `protobuf:"bytes,2,opt,name=Signature,proto3" json:"Signature,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *LatestTransition) Reset() {
+	*x = LatestTransition{}
+	mi := &file_userapi_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LatestTransition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LatestTransition) ProtoMessage() {}
+
+func (x *LatestTransition) ProtoReflect() protoreflect.Message {
+	mi := &file_userapi_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LatestTransition.ProtoReflect.Descriptor instead.
+func (*LatestTransition) Descriptor() ([]byte, []int) {
+	return file_userapi_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *LatestTransition) GetTransitionHash() []byte {
+	if x != nil {
+		return x.TransitionHash
+	}
+	return nil
+}
+
+func (x *LatestTransition) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+type HistoryCheckpoint struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Hash of the most recent transition that was pruned from the history.
+	TransitionHash []byte `protobuf:"bytes,1,opt,name=TransitionHash,proto3" json:"TransitionHash,omitempty"`
+	// Number of transitions that were pruned from the history.
+	Generation uint64 `protobuf:"varint,2,opt,name=Generation,proto3" json:"Generation,omitempty"`
+	// Signature of the Coordinator over the checkpoint.
+	Signature     []byte `protobuf:"bytes,3,opt,name=Signature,proto3" json:"Signature,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HistoryCheckpoint) Reset() {
+	*x = HistoryCheckpoint{}
+	mi := &file_userapi_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HistoryCheckpoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistoryCheckpoint) ProtoMessage() {}
+
+func (x *HistoryCheckpoint) ProtoReflect() protoreflect.Message {
+	mi := &file_userapi_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistoryCheckpoint.ProtoReflect.Descriptor instead.
+func (*HistoryCheckpoint) Descriptor() ([]byte, []int) {
+	return file_userapi_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *HistoryCheckpoint) GetTransitionHash() []byte {
+	if x != nil {
+		return x.TransitionHash
+	}
+	return nil
+}
+
+func (x *HistoryCheckpoint) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *HistoryCheckpoint) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+type TransitionSignature struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TransitionHash []byte                 `protobuf:"bytes,1,opt,name=TransitionHash,proto3" json:"TransitionHash,omitempty"`
+	// Concatenated ASN.1 encoded ECDSA signatures over the hex-encoded transition hash, as passed to
+	// SetManifest.
+	Signature     []byte `protobuf:"bytes,2,opt,name=Signature,proto3" json:"Signature,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransitionSignature) Reset() {
+	*x = TransitionSignature{}
+	mi := &file_userapi_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransitionSignature) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransitionSignature) ProtoMessage() {}
+
+func (x *TransitionSignature) ProtoReflect() protoreflect.Message {
+	mi := &file_userapi_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransitionSignature.ProtoReflect.Descriptor instead.
+func (*TransitionSignature) Descriptor() ([]byte, []int) {
+	return file_userapi_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *TransitionSignature) GetTransitionHash() []byte {
+	if x != nil {
+		return x.TransitionHash
+	}
+	return nil
+}
+
+func (x *TransitionSignature) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+type DryRunSetManifestResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Changes of the requested manifest compared to the current manifest.
+	Diff *ManifestDiff `protobuf:"bytes,1,opt,name=Diff,proto3" json:"Diff,omitempty"`
+	// Hash of the transition that SetManifest would create.
+	NextTransitionHash []byte `protobuf:"bytes,2,opt,name=NextTransitionHash,proto3" json:"NextTransitionHash,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *DryRunSetManifestResponse) Reset() {
+	*x = DryRunSetManifestResponse{}
+	mi := &file_userapi_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DryRunSetManifestResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DryRunSetManifestResponse) ProtoMessage() {}
+
+func (x *DryRunSetManifestResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_userapi_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DryRunSetManifestResponse.ProtoReflect.Descriptor instead.
+func (*DryRunSetManifestResponse) Descriptor() ([]byte, []int) {
+	return file_userapi_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *DryRunSetManifestResponse) GetDiff() *ManifestDiff {
+	if x != nil {
+		return x.Diff
+	}
+	return nil
+}
+
+func (x *DryRunSetManifestResponse) GetNextTransitionHash() []byte {
+	if x != nil {
+		return x.NextTransitionHash
+	}
+	return nil
+}
+
+type ManifestDiff struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	AddedPolicies   []string               `protobuf:"bytes,1,rep,name=AddedPolicies,proto3" json:"AddedPolicies,omitempty"`
+	RemovedPolicies []string               `protobuf:"bytes,2,rep,name=RemovedPolicies,proto3" json:"RemovedPolicies,omitempty"`
+	ChangedPolicies []string               `protobuf:"bytes,3,rep,name=ChangedPolicies,proto3" json:"ChangedPolicies,omitempty"`
+	// JSON-encoded manifest.ReferenceValues
+	AddedReferenceValues []byte `protobuf:"bytes,4,opt,name=AddedReferenceValues,proto3" json:"AddedReferenceValues,omitempty"`
+	// JSON-encoded manifest.ReferenceValues
+	RemovedReferenceValues       []byte   `protobuf:"bytes,5,opt,name=RemovedReferenceValues,proto3" json:"RemovedReferenceValues,omitempty"`
+	AddedWorkloadOwnerPubKeys    []string `protobuf:"bytes,6,rep,name=AddedWorkloadOwnerPubKeys,proto3" json:"AddedWorkloadOwnerPubKeys,omitempty"`
+	RemovedWorkloadOwnerPubKeys  []string `protobuf:"bytes,7,rep,name=RemovedWorkloadOwnerPubKeys,proto3" json:"RemovedWorkloadOwnerPubKeys,omitempty"`
+	AddedSeedshareOwnerPubKeys   []string `protobuf:"bytes,8,rep,name=AddedSeedshareOwnerPubKeys,proto3" json:"AddedSeedshareOwnerPubKeys,omitempty"`
+	RemovedSeedshareOwnerPubKeys []string `protobuf:"bytes,9,rep,name=RemovedSeedshareOwnerPubKeys,proto3" json:"RemovedSeedshareOwnerPubKeys,omitempty"`
+	unknownFields                protoimpl.UnknownFields
+	sizeCache                    protoimpl.SizeCache
+}
+
+func (x *ManifestDiff) Reset() {
+	*x = ManifestDiff{}
+	mi := &file_userapi_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ManifestDiff) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ManifestDiff) ProtoMessage() {}
+
+func (x *ManifestDiff) ProtoReflect() protoreflect.Message {
+	mi := &file_userapi_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ManifestDiff.ProtoReflect.Descriptor instead.
+func (*ManifestDiff) Descriptor() ([]byte, []int) {
+	return file_userapi_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ManifestDiff) GetAddedPolicies() []string {
+	if x != nil {
+		return x.AddedPolicies
+	}
+	return nil
+}
+
+func (x *ManifestDiff) GetRemovedPolicies() []string {
+	if x != nil {
+		return x.RemovedPolicies
+	}
+	return nil
+}
+
+func (x *ManifestDiff) GetChangedPolicies() []string {
+	if x != nil {
+		return x.ChangedPolicies
+	}
+	return nil
+}
+
+func (x *ManifestDiff) GetAddedReferenceValues() []byte {
+	if x != nil {
+		return x.AddedReferenceValues
+	}
+	return nil
+}
+
+func (x *ManifestDiff) GetRemovedReferenceValues() []byte {
+	if x != nil {
+		return x.RemovedReferenceValues
+	}
+	return nil
+}
+
+func (x *ManifestDiff) GetAddedWorkloadOwnerPubKeys() []string {
+	if x != nil {
+		return x.AddedWorkloadOwnerPubKeys
+	}
+	return nil
+}
+
+func (x *ManifestDiff) GetRemovedWorkloadOwnerPubKeys() []string {
+	if x != nil {
+		return x.RemovedWorkloadOwnerPubKeys
+	}
+	return nil
+}
+
+func (x *ManifestDiff) GetAddedSeedshareOwnerPubKeys() []string {
+	if x != nil {
+		return x.AddedSeedshareOwnerPubKeys
+	}
+	return nil
+}
+
+func (x *ManifestDiff) GetRemovedSeedshareOwnerPubKeys() []string {
+	if x != nil {
+		return x.RemovedSeedshareOwnerPubKeys
+	}
+	return nil
+}
+
+type RecoverRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seed          []byte                 `protobuf:"bytes,1,opt,name=Seed,proto3" json:"Seed,omitempty"`
+	Salt          []byte                 `protobuf:"bytes,2,opt,name=Salt,proto3" json:"Salt,omitempty"`
+	Force         bool                   `protobuf:"varint,3,opt,name=Force,proto3" json:"Force,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecoverRequest) Reset() {
+	*x = RecoverRequest{}
+	mi := &file_userapi_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecoverRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecoverRequest) ProtoMessage() {}
+
+func (x *RecoverRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_userapi_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecoverRequest.ProtoReflect.Descriptor instead.
+func (*RecoverRequest) Descriptor() ([]byte, []int) {
+	return file_userapi_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RecoverRequest) GetSeed() []byte {
+	if x != nil {
+		return x.Seed
+	}
+	return nil
+}
+
+func (x *RecoverRequest) GetSalt() []byte {
+	if x != nil {
+		return x.Salt
+	}
+	return nil
+}
+
+func (x *RecoverRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type RecoverResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecoverResponse) Reset() {
+	*x = RecoverResponse{}
+	mi := &file_userapi_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecoverResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecoverResponse) ProtoMessage() {}
+
+func (x *RecoverResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_userapi_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecoverResponse.ProtoReflect.Descriptor instead.
+func (*RecoverResponse) Descriptor() ([]byte, []int) {
+	return file_userapi_proto_rawDescGZIP(), []int{17}
+}
+
+type GetAuditLogRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// If set, only events recorded after the event with this hash are returned.
+	After []byte `protobuf:"bytes,1,opt,name=After,proto3" json:"After,omitempty"`
+	// Sequence number of the event referenced by After. If it's not set, the Coordinator searches
+	// the audit log for the event.
+	AfterSequence *uint64 `protobuf:"varint,2,opt,name=AfterSequence,proto3,oneof" json:"AfterSequence,omitempty"`
+	// Maximum number of events to return. The Coordinator limits the number of events if it's zero
+	// or too large.
+	Limit         uint32 `protobuf:"varint,3,opt,name=Limit,proto3" json:"Limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAuditLogRequest) Reset() {
+	*x = GetAuditLogRequest{}
+	mi := &file_userapi_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAuditLogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAuditLogRequest) ProtoMessage() {}
+
+func (x *GetAuditLogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_userapi_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAuditLogRequest.ProtoReflect.Descriptor instead.
+func (*GetAuditLogRequest) Descriptor() ([]byte, []int) {
+	return file_userapi_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetAuditLogRequest) GetAfter() []byte {
+	if x != nil {
+		return x.After
+	}
+	return nil
+}
+
+func (x *GetAuditLogRequest) GetAfterSequence() uint64 {
+	if x != nil && x.AfterSequence != nil {
+		return *x.AfterSequence
+	}
+	return 0
+}
+
+func (x *GetAuditLogRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetAuditLogResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// JSON-encoded events, oldest first. The hash of an event is the SHA-256 digest of its encoding,
+	// and each event references the hash of its predecessor.
+	Events [][]byte `protobuf:"bytes,1,rep,name=Events,proto3" json:"Events,omitempty"`
+	// More is set if there are events after the returned ones. They can be requested with After set
+	// to the last returned event.
+	More          bool `protobuf:"varint,2,opt,name=More,proto3" json:"More,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAuditLogResponse) Reset() {
+	*x = GetAuditLogResponse{}
+	mi := &file_userapi_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAuditLogResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAuditLogResponse) ProtoMessage() {}
+
+func (x *GetAuditLogResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_userapi_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAuditLogResponse.ProtoReflect.Descriptor instead.
+func (*GetAuditLogResponse) Descriptor() ([]byte, []int) {
+	return file_userapi_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetAuditLogResponse) GetEvents() [][]byte {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *GetAuditLogResponse) GetMore() bool {
+	if x != nil {
+		return x.More
+	}
+	return false
+}
+
+type RevokeMeshCertsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Big-endian serial numbers of the certificates to revoke.
+	SerialNumbers [][]byte `protobuf:"bytes,1,rep,name=SerialNumbers,proto3" json:"SerialNumbers,omitempty"`
+	// If set, all certificates issued to workloads with this hex-encoded policy hash are revoked.
+	PolicyHash string `protobuf:"bytes,2,opt,name=PolicyHash,proto3" json:"PolicyHash,omitempty"`
+	// CRL reason code of the revocation, see RFC 5280, section 5.3.1.
+	ReasonCode    int32 `protobuf:"varint,3,opt,name=ReasonCode,proto3" json:"ReasonCode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeMeshCertsRequest) Reset() {
+	*x = RevokeMeshCertsRequest{}
+	mi := &file_userapi_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeMeshCertsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeMeshCertsRequest) ProtoMessage() {}
+
+func (x *RevokeMeshCertsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_userapi_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeMeshCertsRequest.ProtoReflect.Descriptor instead.
+func (*RevokeMeshCertsRequest) Descriptor() ([]byte, []int) {
+	return file_userapi_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *RevokeMeshCertsRequest) GetSerialNumbers() [][]byte {
+	if x != nil {
+		return x.SerialNumbers
+	}
+	return nil
+}
+
+func (x *RevokeMeshCertsRequest) GetPolicyHash() string {
+	if x != nil {
+		return x.PolicyHash
+	}
+	return ""
+}
+
+func (x *RevokeMeshCertsRequest) GetReasonCode() int32 {
+	if x != nil {
+		return x.ReasonCode
+	}
+	return 0
+}
+
+type RevokeMeshCertsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Big-endian serial numbers of the newly revoked certificates.
+	SerialNumbers [][]byte `protobuf:"bytes,1,rep,name=SerialNumbers,proto3" json:"SerialNumbers,omitempty"`
+	// PEM-encoded CRL of the mesh CA, including the revoked certificates.
+	CRL           []byte `protobuf:"bytes,2,opt,name=CRL,proto3" json:"CRL,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeMeshCertsResponse) Reset() {
+	*x = RevokeMeshCertsResponse{}
+	mi := &file_userapi_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeMeshCertsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeMeshCertsResponse) ProtoMessage() {}
+
+func (x *RevokeMeshCertsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_userapi_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeMeshCertsResponse.ProtoReflect.Descriptor instead.
+func (*RevokeMeshCertsResponse) Descriptor() ([]byte, []int) {
+	return file_userapi_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *RevokeMeshCertsResponse) GetSerialNumbers() [][]byte {
+	if x != nil {
+		return x.SerialNumbers
+	}
+	return nil
+}
+
+func (x *RevokeMeshCertsResponse) GetCRL() []byte {
+	if x != nil {
+		return x.CRL
+	}
+	return nil
+}
+
+type IssueSubCARequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// PEM-encoded certificate signing request for the key of the sub CA.
+	CSR []byte `protobuf:"bytes,1,opt,name=CSR,proto3" json:"CSR,omitempty"`
+	// Names the sub CA may issue certificates for. DNS names permit the name and its subdomains,
+	// names of the form "*.<domain>" only the subdomains. IP addresses and CIDR ranges permit the
+	// addresses, URIs permit all URIs with the same host, and email addresses permit the address.
+	Names []string `protobuf:"bytes,2,rep,name=Names,proto3" json:"Names,omitempty"`
+	// Lifetime of the sub CA certificate in seconds. Defaults to 24 hours.
+	LifetimeSeconds int64 `protobuf:"varint,3,opt,name=LifetimeSeconds,proto3" json:"LifetimeSeconds,omitempty"`
+	// Workload owner signatures over the sub CA request, for manifests that require the approval of
+	// more than one workload owner.
+	Signatures    [][]byte `protobuf:"bytes,4,rep,name=Signatures,proto3" json:"Signatures,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IssueSubCARequest) Reset() {
+	*x = IssueSubCARequest{}
+	mi := &file_userapi_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IssueSubCARequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IssueSubCARequest) ProtoMessage() {}
+
+func (x *IssueSubCARequest) ProtoReflect() protoreflect.Message {
+	mi := &file_userapi_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IssueSubCARequest.ProtoReflect.Descriptor instead.
+func (*IssueSubCARequest) Descriptor() ([]byte, []int) {
+	return file_userapi_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *IssueSubCARequest) GetCSR() []byte {
+	if x != nil {
+		return x.CSR
+	}
+	return nil
+}
+
+func (x *IssueSubCARequest) GetNames() []string {
+	if x != nil {
+		return x.Names
+	}
+	return nil
+}
+
+func (x *IssueSubCARequest) GetLifetimeSeconds() int64 {
+	if x != nil {
+		return x.LifetimeSeconds
+	}
+	return 0
+}
+
+func (x *IssueSubCARequest) GetSignatures() [][]byte {
+	if x != nil {
+		return x.Signatures
+	}
+	return nil
+}
+
+type IssueSubCAResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// PEM-encoded sub CA certificate, issued by the mesh CA.
+	Certificate []byte `protobuf:"bytes,1,opt,name=Certificate,proto3" json:"Certificate,omitempty"`
+	// PEM-encoded mesh CA certificate.
+	MeshCACert []byte `protobuf:"bytes,2,opt,name=MeshCACert,proto3" json:"MeshCACert,omitempty"`
+	// PEM-encoded intermediate CA certificate, which links the sub CA to the root CA.
+	IntermCACert []byte `protobuf:"bytes,3,opt,name=IntermCACert,proto3" json:"IntermCACert,omitempty"`
+	// PEM-encoded root CA certificate.
+	RootCACert    []byte `protobuf:"bytes,4,opt,name=RootCACert,proto3" json:"RootCACert,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IssueSubCAResponse) Reset() {
+	*x = IssueSubCAResponse{}
+	mi := &file_userapi_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IssueSubCAResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IssueSubCAResponse) ProtoMessage() {}
+
+func (x *IssueSubCAResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_userapi_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IssueSubCAResponse.ProtoReflect.Descriptor instead.
+func (*IssueSubCAResponse) Descriptor() ([]byte, []int) {
+	return file_userapi_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *IssueSubCAResponse) GetCertificate() []byte {
+	if x != nil {
+		return x.Certificate
+	}
+	return nil
+}
+
+func (x *IssueSubCAResponse) GetMeshCACert() []byte {
+	if x != nil {
+		return x.MeshCACert
+	}
+	return nil
+}
+
+func (x *IssueSubCAResponse) GetIntermCACert() []byte {
+	if x != nil {
+		return x.IntermCACert
+	}
+	return nil
+}
+
+func (x *IssueSubCAResponse) GetRootCACert() []byte {
+	if x != nil {
+		return x.RootCACert
+	}
+	return nil
+}
+
+type ListMeshCertsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMeshCertsRequest) Reset() {
+	*x = ListMeshCertsRequest{}
+	mi := &file_userapi_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMeshCertsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMeshCertsRequest) ProtoMessage() {}
+
+func (x *ListMeshCertsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_userapi_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMeshCertsRequest.ProtoReflect.Descriptor instead.
+func (*ListMeshCertsRequest) Descriptor() ([]byte, []int) {
+	return file_userapi_proto_rawDescGZIP(), []int{24}
+}
+
+type ListMeshCertsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Mesh certificates issued by the Coordinator, oldest first.
+	Certificates  []*MeshCert `protobuf:"bytes,1,rep,name=Certificates,proto3" json:"Certificates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMeshCertsResponse) Reset() {
+	*x = ListMeshCertsResponse{}
+	mi := &file_userapi_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMeshCertsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMeshCertsResponse) ProtoMessage() {}
+
+func (x *ListMeshCertsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_userapi_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMeshCertsResponse.ProtoReflect.Descriptor instead.
+func (*ListMeshCertsResponse) Descriptor() ([]byte, []int) {
+	return file_userapi_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ListMeshCertsResponse) GetCertificates() []*MeshCert {
+	if x != nil {
+		return x.Certificates
+	}
+	return nil
+}
+
+type MeshCert struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Big-endian serial number of the certificate.
+	SerialNumber []byte `protobuf:"bytes,1,opt,name=SerialNumber,proto3" json:"SerialNumber,omitempty"`
+	// Hex-encoded policy hash of the workload the certificate was issued to.
+	PolicyHash string `protobuf:"bytes,2,opt,name=PolicyHash,proto3" json:"PolicyHash,omitempty"`
+	// Subject alternative names of the certificate.
+	SANs []string `protobuf:"bytes,3,rep,name=SANs,proto3" json:"SANs,omitempty"`
+	// Address of the workload that requested the certificate.
+	PeerAddress string `protobuf:"bytes,4,opt,name=PeerAddress,proto3" json:"PeerAddress,omitempty"`
+	// Time the certificate was issued at, in seconds since the Unix epoch.
+	IssuedAt int64 `protobuf:"varint,5,opt,name=IssuedAt,proto3" json:"IssuedAt,omitempty"`
+	// Expiry date of the certificate, in seconds since the Unix epoch.
+	NotAfter int64 `protobuf:"varint,6,opt,name=NotAfter,proto3" json:"NotAfter,omitempty"`
+	// Generation of the manifest the certificate was issued under.
+	ManifestGeneration uint64 `protobuf:"varint,7,opt,name=ManifestGeneration,proto3" json:"ManifestGeneration,omitempty"`
+	// Whether the certificate was issued by the mesh CA of the current manifest.
+	CurrentMeshCA bool `protobuf:"varint,8,opt,name=CurrentMeshCA,proto3" json:"CurrentMeshCA,omitempty"`
+	// Time the certificate was revoked at, in seconds since the Unix epoch. Only revocations
+	// performed by the same Coordinator are reflected, the CRL is authoritative.
+	RevokedAt     int64 `protobuf:"varint,9,opt,name=RevokedAt,proto3" json:"RevokedAt,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MeshCert) Reset() {
+	*x = MeshCert{}
+	mi := &file_userapi_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MeshCert) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MeshCert) ProtoMessage() {}
+
+func (x *MeshCert) ProtoReflect() protoreflect.Message {
+	mi := &file_userapi_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MeshCert.ProtoReflect.Descriptor instead.
+func (*MeshCert) Descriptor() ([]byte, []int) {
+	return file_userapi_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *MeshCert) GetSerialNumber() []byte {
+	if x != nil {
+		return x.SerialNumber
+	}
+	return nil
+}
+
+func (x *MeshCert) GetPolicyHash() string {
+	if x != nil {
+		return x.PolicyHash
+	}
+	return ""
+}
+
+func (x *MeshCert) GetSANs() []string {
+	if x != nil {
+		return x.SANs
+	}
+	return nil
+}
+
+func (x *MeshCert) GetPeerAddress() string {
+	if x != nil {
+		return x.PeerAddress
+	}
+	return ""
+}
+
+func (x *MeshCert) GetIssuedAt() int64 {
+	if x != nil {
+		return x.IssuedAt
+	}
+	return 0
+}
+
+func (x *MeshCert) GetNotAfter() int64 {
+	if x != nil {
+		return x.NotAfter
+	}
+	return 0
+}
+
+func (x *MeshCert) GetManifestGeneration() uint64 {
+	if x != nil {
+		return x.ManifestGeneration
+	}
+	return 0
+}
+
+func (x *MeshCert) GetCurrentMeshCA() bool {
+	if x != nil {
+		return x.CurrentMeshCA
+	}
+	return false
+}
+
+func (x *MeshCert) GetRevokedAt() int64 {
+	if x != nil {
+		return x.RevokedAt
+	}
+	return 0
+}
+
+var File_userapi_proto protoreflect.FileDescriptor
+
+const file_userapi_proto_rawDesc = "" +
+	"\n" +
+	"\ruserapi.proto\x12\x1cedgelesssys.contrast.userapi\"\xea\x01\n" +
+	"\x12SetManifestRequest\x12\x1a\n" +
+	"\bManifest\x18\x01 \x01(\fR\bManifest\x12\x1a\n" +
+	"\bPolicies\x18\x02 \x03(\fR\bPolicies\x126\n" +
+	"\x16PreviousTransitionHash\x18\x03 \x01(\fR\x16PreviousTransitionHash\x12\x1c\n" +
+	"\tSignature\x18\x04 \x01(\fR\tSignature\x12\x1e\n" +
+	"\n" +
+	"Signatures\x18\x05 \x03(\fR\n" +
+	"Signatures\x12&\n" +
+	"\x0eActivationTime\x18\x06 \x01(\x03R\x0eActivationTime\"\xef\x01\n" +
+	"\x13SetManifestResponse\x12\x16\n" +
+	"\x06RootCA\x18\x01 \x01(\fR\x06RootCA\x12\x16\n" +
+	"\x06MeshCA\x18\x02 \x01(\fR\x06MeshCA\x12U\n" +
+	"\rSeedSharesDoc\x18\x03 \x01(\v2/.edgelesssys.contrast.userapi.SeedShareDocumentR\rSeedSharesDoc\x12Q\n" +
+	"\rPendingUpdate\x18\x04 \x01(\v2+.edgelesssys.contrast.userapi.PendingUpdateR\rPendingUpdate\"p\n" +
+	"\x11SeedShareDocument\x12G\n" +
+	"\n" +
+	"SeedShares\x18\x01 \x03(\v2'.edgelesssys.contrast.userapi.SeedShareR\n" +
+	"SeedShares\x12\x12\n" +
+	"\x04salt\x18\x02 \x01(\fR\x04salt\"O\n" +
+	"\tSeedShare\x12\x1c\n" +
+	"\tPublicKey\x18\x01 \x01(\tR\tPublicKey\x12$\n" +
+	"\rEncryptedSeed\x18\x02 \x01(\fR\rEncryptedSeed\"\x15\n" +
+	"\x13GetManifestsRequest\"\x99\x04\n" +
+	"\x14GetManifestsResponse\x12\x1c\n" +
+	"\tManifests\x18\x01 \x03(\fR\tManifests\x12\x1a\n" +
+	"\bPolicies\x18\x02 \x03(\fR\bPolicies\x12\x16\n" +
+	"\x06RootCA\x18\x03 \x01(\fR\x06RootCA\x12\x16\n" +
+	"\x06MeshCA\x18\x04 \x01(\fR\x06MeshCA\x12Z\n" +
+	"\x10LatestTransition\x18\x05 \x01(\v2..edgelesssys.contrast.userapi.LatestTransitionR\x10LatestTransition\x12e\n" +
+	"\x14TransitionSignatures\x18\x06 \x03(\v21.edgelesssys.contrast.userapi.TransitionSignatureR\x14TransitionSignatures\x12O\n" +
+	"\n" +
+	"Checkpoint\x18\a \x01(\v2/.edgelesssys.contrast.userapi.HistoryCheckpointR\n" +
+	"Checkpoint\x12Q\n" +
+	"\rPendingUpdate\x18\b \x01(\v2+.edgelesssys.contrast.userapi.PendingUpdateR\rPendingUpdate\x12\x1e\n" +
+	"\n" +
+	"SigningKey\x18\t \x01(\fR\n" +
+	"SigningKey\x12\x10\n" +
+	"\x03CRL\x18\n" +
+	" \x01(\fR\x03CRL\"{\n" +
+	"\rPendingUpdate\x12&\n" +
+	"\x0eTransitionHash\x18\x01 \x01(\fR\x0eTransitionHash\x12&\n" +
+	"\x0eActivationTime\x18\x02 \x01(\x03R\x0eActivationTime\x12\x1a\n" +
+	"\bManifest\x18\x03 \x01(\fR\bManifest\"d\n" +
+	"\x1aCancelPendingUpdateRequest\x12&\n" +
+	"\x0eTransitionHash\x18\x01 \x01(\fR\x0eTransitionHash\x12\x1e\n" +
+	"\n" +
+	"Signatures\x18\x02 \x03(\fR\n" +
+	"Signatures\"\x1d\n" +
+	"\x1bCancelPendingUpdateResponse\"\x91\x01\n" +
+	"\x0fRollbackRequest\x12&\n" +
+	"\x0eTransitionHash\x18\x01 \x01(\fR\x0eTransitionHash\x126\n" +
+	"\x16PreviousTransitionHash\x18\x02 \x01(\fR\x16PreviousTransitionHash\x12\x1e\n" +
+	"\n" +
+	"Signatures\x18\x03 \x03(\fR\n" +
+	"Signatures\"j\n" +
+	"\x10RollbackResponse\x12\x16\n" +
+	"\x06RootCA\x18\x01 \x01(\fR\x06RootCA\x12\x16\n" +
+	"\x06MeshCA\x18\x02 \x01(\fR\x06MeshCA\x12&\n" +
+	"\x0eTransitionHash\x18\x03 \x01(\fR\x0eTransitionHash\"X\n" +
+	"\x10LatestTransition\x12&\n" +
+	"\x0eTransitionHash\x18\x01 \x01(\fR\x0eTransitionHash\x12\x1c\n" +
+	"\tSignature\x18\x02 \x01(\fR\tSignature\"y\n" +
+	"\x11HistoryCheckpoint\x12&\n" +
+	"\x0eTransitionHash\x18\x01 \x01(\fR\x0eTransitionHash\x12\x1e\n" +
+	"\n" +
+	"Generation\x18\x02 \x01(\x04R\n" +
+	"Generation\x12\x1c\n" +
+	"\tSignature\x18\x03 \x01(\fR\tSignature\"[\n" +
+	"\x13TransitionSignature\x12&\n" +
+	"\x0eTransitionHash\x18\x01 \x01(\fR\x0eTransitionHash\x12\x1c\n" +
+	"\tSignature\x18\x02 \x01(\fR\tSignature\"\x8b\x01\n" +
+	"\x19DryRunSetManifestResponse\x12>\n" +
+	"\x04Diff\x18\x01 \x01(\v2*.edgelesssys.contrast.userapi.ManifestDiffR\x04Diff\x12.\n" +
+	"\x12NextTransitionHash\x18\x02 \x01(\fR\x12NextTransitionHash\"\xf8\x03\n" +
+	"\fManifestDiff\x12$\n" +
+	"\rAddedPolicies\x18\x01 \x03(\tR\rAddedPolicies\x12(\n" +
+	"\x0fRemovedPolicies\x18\x02 \x03(\tR\x0fRemovedPolicies\x12(\n" +
+	"\x0fChangedPolicies\x18\x03 \x03(\tR\x0fChangedPolicies\x122\n" +
+	"\x14AddedReferenceValues\x18\x04 \x01(\fR\x14AddedReferenceValues\x126\n" +
+	"\x16RemovedReferenceValues\x18\x05 \x01(\fR\x16RemovedReferenceValues\x12<\n" +
+	"\x19AddedWorkloadOwnerPubKeys\x18\x06 \x03(\tR\x19AddedWorkloadOwnerPubKeys\x12@\n" +
+	"\x1bRemovedWorkloadOwnerPubKeys\x18\a \x03(\tR\x1bRemovedWorkloadOwnerPubKeys\x12>\n" +
+	"\x1aAddedSeedshareOwnerPubKeys\x18\b \x03(\tR\x1aAddedSeedshareOwnerPubKeys\x12B\n" +
+	"\x1cRemovedSeedshareOwnerPubKeys\x18\t \x03(\tR\x1cRemovedSeedshareOwnerPubKeys\"N\n" +
+	"\x0eRecoverRequest\x12\x12\n" +
+	"\x04Seed\x18\x01 \x01(\fR\x04Seed\x12\x12\n" +
+	"\x04Salt\x18\x02 \x01(\fR\x04Salt\x12\x14\n" +
+	"\x05Force\x18\x03 \x01(\bR\x05Force\"\x11\n" +
+	"\x0fRecoverResponse\"}\n" +
+	"\x12GetAuditLogRequest\x12\x14\n" +
+	"\x05After\x18\x01 \x01(\fR\x05After\x12)\n" +
+	"\rAfterSequence\x18\x02 \x01(\x04H\x00R\rAfterSequence\x88\x01\x01\x12\x14\n" +
+	"\x05Limit\x18\x03 \x01(\rR\x05LimitB\x10\n" +
+	"\x0e_AfterSequence\"A\n" +
+	"\x13GetAuditLogResponse\x12\x16\n" +
+	"\x06Events\x18\x01 \x03(\fR\x06Events\x12\x12\n" +
+	"\x04More\x18\x02 \x01(\bR\x04More\"~\n" +
+	"\x16RevokeMeshCertsRequest\x12$\n" +
+	"\rSerialNumbers\x18\x01 \x03(\fR\rSerialNumbers\x12\x1e\n" +
+	"\n" +
+	"PolicyHash\x18\x02 \x01(\tR\n" +
+	"PolicyHash\x12\x1e\n" +
+	"\n" +
+	"ReasonCode\x18\x03 \x01(\x05R\n" +
+	"ReasonCode\"Q\n" +
+	"\x17RevokeMeshCertsResponse\x12$\n" +
+	"\rSerialNumbers\x18\x01 \x03(\fR\rSerialNumbers\x12\x10\n" +
+	"\x03CRL\x18\x02 \x01(\fR\x03CRL\"\x85\x01\n" +
+	"\x11IssueSubCARequest\x12\x10\n" +
+	"\x03CSR\x18\x01 \x01(\fR\x03CSR\x12\x14\n" +
+	"\x05Names\x18\x02 \x03(\tR\x05Names\x12(\n" +
+	"\x0fLifetimeSeconds\x18\x03 \x01(\x03R\x0fLifetimeSeconds\x12\x1e\n" +
+	"\n" +
+	"Signatures\x18\x04 \x03(\fR\n" +
+	"Signatures\"\x9a\x01\n" +
+	"\x12IssueSubCAResponse\x12 \n" +
+	"\vCertificate\x18\x01 \x01(\fR\vCertificate\x12\x1e\n" +
+	"\n" +
+	"MeshCACert\x18\x02 \x01(\fR\n" +
+	"MeshCACert\x12\"\n" +
+	"\fIntermCACert\x18\x03 \x01(\fR\fIntermCACert\x12\x1e\n" +
+	"\n" +
+	"RootCACert\x18\x04 \x01(\fR\n" +
+	"RootCACert\"\x16\n" +
+	"\x14ListMeshCertsRequest\"c\n" +
+	"\x15ListMeshCertsResponse\x12J\n" +
+	"\fCertificates\x18\x01 \x03(\v2&.edgelesssys.contrast.userapi.MeshCertR\fCertificates\"\xb0\x02\n" +
+	"\bMeshCert\x12\"\n" +
+	"\fSerialNumber\x18\x01 \x01(\fR\fSerialNumber\x12\x1e\n" +
+	"\n" +
+	"PolicyHash\x18\x02 \x01(\tR\n" +
+	"PolicyHash\x12\x12\n" +
+	"\x04SANs\x18\x03 \x03(\tR\x04SANs\x12 \n" +
+	"\vPeerAddress\x18\x04 \x01(\tR\vPeerAddress\x12\x1a\n" +
+	"\bIssuedAt\x18\x05 \x01(\x03R\bIssuedAt\x12\x1a\n" +
+	"\bNotAfter\x18\x06 \x01(\x03R\bNotAfter\x12.\n" +
+	"\x12ManifestGeneration\x18\a \x01(\x04R\x12ManifestGeneration\x12$\n" +
+	"\rCurrentMeshCA\x18\b \x01(\bR\rCurrentMeshCA\x12\x1c\n" +
+	"\tRevokedAt\x18\t \x01(\x03R\tRevokedAt2\xb3\t\n" +
+	"\aUserAPI\x12r\n" +
+	"\vSetManifest\x120.edgelesssys.contrast.userapi.SetManifestRequest\x1a1.edgelesssys.contrast.userapi.SetManifestResponse\x12u\n" +
+	"\fGetManifests\x121.edgelesssys.contrast.userapi.GetManifestsRequest\x1a2.edgelesssys.contrast.userapi.GetManifestsResponse\x12f\n" +
+	"\aRecover\x12,.edgelesssys.contrast.userapi.RecoverRequest\x1a-.edgelesssys.contrast.userapi.RecoverResponse\x12~\n" +
+	"\x11DryRunSetManifest\x120.edgelesssys.contrast.userapi.SetManifestRequest\x1a7.edgelesssys.contrast.userapi.DryRunSetManifestResponse\x12\x8a\x01\n" +
+	"\x13CancelPendingUpdate\x128.edgelesssys.contrast.userapi.CancelPendingUpdateRequest\x1a9.edgelesssys.contrast.userapi.CancelPendingUpdateResponse\x12i\n" +
+	"\bRollback\x12-.edgelesssys.contrast.userapi.RollbackRequest\x1a..edgelesssys.contrast.userapi.RollbackResponse\x12r\n" +
+	"\vGetAuditLog\x120.edgelesssys.contrast.userapi.GetAuditLogRequest\x1a1.edgelesssys.contrast.userapi.GetAuditLogResponse\x12~\n" +
+	"\x0fRevokeMeshCerts\x124.edgelesssys.contrast.userapi.RevokeMeshCertsRequest\x1a5.edgelesssys.contrast.userapi.RevokeMeshCertsResponse\x12o\n" +
+	"\n" +
+	"IssueSubCA\x12/.edgelesssys.contrast.userapi.IssueSubCARequest\x1a0.edgelesssys.contrast.userapi.IssueSubCAResponse\x12x\n" +
+	"\rListMeshCerts\x122.edgelesssys.contrast.userapi.ListMeshCertsRequest\x1a3.edgelesssys.contrast.userapi.ListMeshCertsResponseB2Z0github.com/edgelesssys/contrast/internal/userapib\x06proto3"
 
 var (
 	file_userapi_proto_rawDescOnce sync.Once
@@ -572,33 +1909,71 @@ func file_userapi_proto_rawDescGZIP() []byte {
 	return file_userapi_proto_rawDescData
 }
 
-var file_userapi_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_userapi_proto_msgTypes = make([]protoimpl.MessageInfo, 27)
 var file_userapi_proto_goTypes = []any{
-	(*SetManifestRequest)(nil),   // 0: edgelesssys.contrast.userapi.SetManifestRequest
-	(*SetManifestResponse)(nil),  // 1: edgelesssys.contrast.userapi.SetManifestResponse
-	(*SeedShareDocument)(nil),    // 2: edgelesssys.contrast.userapi.SeedShareDocument
-	(*SeedShare)(nil),            // 3: edgelesssys.contrast.userapi.SeedShare
-	(*GetManifestsRequest)(nil),  // 4: edgelesssys.contrast.userapi.GetManifestsRequest
-	(*GetManifestsResponse)(nil), // 5: edgelesssys.contrast.userapi.GetManifestsResponse
-	(*LatestTransition)(nil),     // 6: edgelesssys.contrast.userapi.LatestTransition
-	(*RecoverRequest)(nil),       // 7: edgelesssys.contrast.userapi.RecoverRequest
-	(*RecoverResponse)(nil),      // 8: edgelesssys.contrast.userapi.RecoverResponse
+	(*SetManifestRequest)(nil),          // 0: edgelesssys.contrast.userapi.SetManifestRequest
+	(*SetManifestResponse)(nil),         // 1: edgelesssys.contrast.userapi.SetManifestResponse
+	(*SeedShareDocument)(nil),           // 2: edgelesssys.contrast.userapi.SeedShareDocument
+	(*SeedShare)(nil),                   // 3: edgelesssys.contrast.userapi.SeedShare
+	(*GetManifestsRequest)(nil),         // 4: edgelesssys.contrast.userapi.GetManifestsRequest
+	(*GetManifestsResponse)(nil),        // 5: edgelesssys.contrast.userapi.GetManifestsResponse
+	(*PendingUpdate)(nil),               // 6: edgelesssys.contrast.userapi.PendingUpdate
+	(*CancelPendingUpdateRequest)(nil),  // 7: edgelesssys.contrast.userapi.CancelPendingUpdateRequest
+	(*CancelPendingUpdateResponse)(nil), // 8: edgelesssys.contrast.userapi.CancelPendingUpdateResponse
+	(*RollbackRequest)(nil),             // 9: edgelesssys.contrast.userapi.RollbackRequest
+	(*RollbackResponse)(nil),            // 10: edgelesssys.contrast.userapi.RollbackResponse
+	(*LatestTransition)(nil),            // 11: edgelesssys.contrast.userapi.LatestTransition
+	(*HistoryCheckpoint)(nil),           // 12: edgelesssys.contrast.userapi.HistoryCheckpoint
+	(*TransitionSignature)(nil),         // 13: edgelesssys.contrast.userapi.TransitionSignature
+	(*DryRunSetManifestResponse)(nil),   // 14: edgelesssys.contrast.userapi.DryRunSetManifestResponse
+	(*ManifestDiff)(nil),                // 15: edgelesssys.contrast.userapi.ManifestDiff
+	(*RecoverRequest)(nil),              // 16: edgelesssys.contrast.userapi.RecoverRequest
+	(*RecoverResponse)(nil),             // 17: edgelesssys.contrast.userapi.RecoverResponse
+	(*GetAuditLogRequest)(nil),          // 18: edgelesssys.contrast.userapi.GetAuditLogRequest
+	(*GetAuditLogResponse)(nil),         // 19: edgelesssys.contrast.userapi.GetAuditLogResponse
+	(*RevokeMeshCertsRequest)(nil),      // 20: edgelesssys.contrast.userapi.RevokeMeshCertsRequest
+	(*RevokeMeshCertsResponse)(nil),     // 21: edgelesssys.contrast.userapi.RevokeMeshCertsResponse
+	(*IssueSubCARequest)(nil),           // 22: edgelesssys.contrast.userapi.IssueSubCARequest
+	(*IssueSubCAResponse)(nil),          // 23: edgelesssys.contrast.userapi.IssueSubCAResponse
+	(*ListMeshCertsRequest)(nil),        // 24: edgelesssys.contrast.userapi.ListMeshCertsRequest
+	(*ListMeshCertsResponse)(nil),       // 25: edgelesssys.contrast.userapi.ListMeshCertsResponse
+	(*MeshCert)(nil),                    // 26: edgelesssys.contrast.userapi.MeshCert
 }
 var file_userapi_proto_depIdxs = []int32{
-	2, // 0: edgelesssys.contrast.userapi.SetManifestResponse.SeedSharesDoc:type_name -> edgelesssys.contrast.userapi.SeedShareDocument
-	3, // 1: edgelesssys.contrast.userapi.SeedShareDocument.SeedShares:type_name -> edgelesssys.contrast.userapi.SeedShare
-	6, // 2: edgelesssys.contrast.userapi.GetManifestsResponse.LatestTransition:type_name -> edgelesssys.contrast.userapi.LatestTransition
-	0, // 3: edgelesssys.contrast.userapi.UserAPI.SetManifest:input_type -> edgelesssys.contrast.userapi.SetManifestRequest
-	4, // 4: edgelesssys.contrast.userapi.UserAPI.GetManifests:input_type -> edgelesssys.contrast.userapi.GetManifestsRequest
-	7, // 5: edgelesssys.contrast.userapi.UserAPI.Recover:input_type -> edgelesssys.contrast.userapi.RecoverRequest
-	1, // 6: edgelesssys.contrast.userapi.UserAPI.SetManifest:output_type -> edgelesssys.contrast.userapi.SetManifestResponse
-	5, // 7: edgelesssys.contrast.userapi.UserAPI.GetManifests:output_type -> edgelesssys.contrast.userapi.GetManifestsResponse
-	8, // 8: edgelesssys.contrast.userapi.UserAPI.Recover:output_type -> edgelesssys.contrast.userapi.RecoverResponse
-	6, // [6:9] is the sub-list for method output_type
-	3, // [3:6] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	2,  // 0: edgelesssys.contrast.userapi.SetManifestResponse.SeedSharesDoc:type_name -> edgelesssys.contrast.userapi.SeedShareDocument
+	6,  // 1: edgelesssys.contrast.userapi.SetManifestResponse.PendingUpdate:type_name -> edgelesssys.contrast.userapi.PendingUpdate
+	3,  // 2: edgelesssys.contrast.userapi.SeedShareDocument.SeedShares:type_name -> edgelesssys.contrast.userapi.SeedShare
+	11, // 3: edgelesssys.contrast.userapi.GetManifestsResponse.LatestTransition:type_name -> edgelesssys.contrast.userapi.LatestTransition
+	13, // 4: edgelesssys.contrast.userapi.GetManifestsResponse.TransitionSignatures:type_name -> edgelesssys.contrast.userapi.TransitionSignature
+	12, // 5: edgelesssys.contrast.userapi.GetManifestsResponse.Checkpoint:type_name -> edgelesssys.contrast.userapi.HistoryCheckpoint
+	6,  // 6: edgelesssys.contrast.userapi.GetManifestsResponse.PendingUpdate:type_name -> edgelesssys.contrast.userapi.PendingUpdate
+	15, // 7: edgelesssys.contrast.userapi.DryRunSetManifestResponse.Diff:type_name -> edgelesssys.contrast.userapi.ManifestDiff
+	26, // 8: edgelesssys.contrast.userapi.ListMeshCertsResponse.Certificates:type_name -> edgelesssys.contrast.userapi.MeshCert
+	0,  // 9: edgelesssys.contrast.userapi.UserAPI.SetManifest:input_type -> edgelesssys.contrast.userapi.SetManifestRequest
+	4,  // 10: edgelesssys.contrast.userapi.UserAPI.GetManifests:input_type -> edgelesssys.contrast.userapi.GetManifestsRequest
+	16, // 11: edgelesssys.contrast.userapi.UserAPI.Recover:input_type -> edgelesssys.contrast.userapi.RecoverRequest
+	0,  // 12: edgelesssys.contrast.userapi.UserAPI.DryRunSetManifest:input_type -> edgelesssys.contrast.userapi.SetManifestRequest
+	7,  // 13: edgelesssys.contrast.userapi.UserAPI.CancelPendingUpdate:input_type -> edgelesssys.contrast.userapi.CancelPendingUpdateRequest
+	9,  // 14: edgelesssys.contrast.userapi.UserAPI.Rollback:input_type -> edgelesssys.contrast.userapi.RollbackRequest
+	18, // 15: edgelesssys.contrast.userapi.UserAPI.GetAuditLog:input_type -> edgelesssys.contrast.userapi.GetAuditLogRequest
+	20, // 16: edgelesssys.contrast.userapi.UserAPI.RevokeMeshCerts:input_type -> edgelesssys.contrast.userapi.RevokeMeshCertsRequest
+	22, // 17: edgelesssys.contrast.userapi.UserAPI.IssueSubCA:input_type -> edgelesssys.contrast.userapi.IssueSubCARequest
+	24, // 18: edgelesssys.contrast.userapi.UserAPI.ListMeshCerts:input_type -> edgelesssys.contrast.userapi.ListMeshCertsRequest
+	1,  // 19: edgelesssys.contrast.userapi.UserAPI.SetManifest:output_type -> edgelesssys.contrast.userapi.SetManifestResponse
+	5,  // 20: edgelesssys.contrast.userapi.UserAPI.GetManifests:output_type -> edgelesssys.contrast.userapi.GetManifestsResponse
+	17, // 21: edgelesssys.contrast.userapi.UserAPI.Recover:output_type -> edgelesssys.contrast.userapi.RecoverResponse
+	14, // 22: edgelesssys.contrast.userapi.UserAPI.DryRunSetManifest:output_type -> edgelesssys.contrast.userapi.DryRunSetManifestResponse
+	8,  // 23: edgelesssys.contrast.userapi.UserAPI.CancelPendingUpdate:output_type -> edgelesssys.contrast.userapi.CancelPendingUpdateResponse
+	10, // 24: edgelesssys.contrast.userapi.UserAPI.Rollback:output_type -> edgelesssys.contrast.userapi.RollbackResponse
+	19, // 25: edgelesssys.contrast.userapi.UserAPI.GetAuditLog:output_type -> edgelesssys.contrast.userapi.GetAuditLogResponse
+	21, // 26: edgelesssys.contrast.userapi.UserAPI.RevokeMeshCerts:output_type -> edgelesssys.contrast.userapi.RevokeMeshCertsResponse
+	23, // 27: edgelesssys.contrast.userapi.UserAPI.IssueSubCA:output_type -> edgelesssys.contrast.userapi.IssueSubCAResponse
+	25, // 28: edgelesssys.contrast.userapi.UserAPI.ListMeshCerts:output_type -> edgelesssys.contrast.userapi.ListMeshCertsResponse
+	19, // [19:29] is the sub-list for method output_type
+	9,  // [9:19] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_userapi_proto_init() }
@@ -606,13 +1981,14 @@ func file_userapi_proto_init() {
 	if File_userapi_proto != nil {
 		return
 	}
+	file_userapi_proto_msgTypes[18].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_userapi_proto_rawDesc), len(file_userapi_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   9,
+			NumMessages:   27,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,9 +19,16 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	UserAPI_SetManifest_FullMethodName  = "/edgelesssys.contrast.userapi.UserAPI/SetManifest"
-	UserAPI_GetManifests_FullMethodName = "/edgelesssys.contrast.userapi.UserAPI/GetManifests"
-	UserAPI_Recover_FullMethodName      = "/edgelesssys.contrast.userapi.UserAPI/Recover"
+	UserAPI_SetManifest_FullMethodName         = "/edgelesssys.contrast.userapi.UserAPI/SetManifest"
+	UserAPI_GetManifests_FullMethodName        = "/edgelesssys.contrast.userapi.UserAPI/GetManifests"
+	UserAPI_Recover_FullMethodName             = "/edgelesssys.contrast.userapi.UserAPI/Recover"
+	UserAPI_DryRunSetManifest_FullMethodName   = "/edgelesssys.contrast.userapi.UserAPI/DryRunSetManifest"
+	UserAPI_CancelPendingUpdate_FullMethodName = "/edgelesssys.contrast.userapi.UserAPI/CancelPendingUpdate"
+	UserAPI_Rollback_FullMethodName            = "/edgelesssys.contrast.userapi.UserAPI/Rollback"
+	UserAPI_GetAuditLog_FullMethodName         = "/edgelesssys.contrast.userapi.UserAPI/GetAuditLog"
+	UserAPI_RevokeMeshCerts_FullMethodName     = "/edgelesssys.contrast.userapi.UserAPI/RevokeMeshCerts"
+	UserAPI_IssueSubCA_FullMethodName          = "/edgelesssys.contrast.userapi.UserAPI/IssueSubCA"
+	UserAPI_ListMeshCerts_FullMethodName       = "/edgelesssys.contrast.userapi.UserAPI/ListMeshCerts"
 )
 
 // UserAPIClient is the client API for UserAPI service.
@@ -31,6 +38,21 @@ type UserAPIClient interface {
 	SetManifest(ctx context.Context, in *SetManifestRequest, opts ...grpc.CallOption) (*SetManifestResponse, error)
 	GetManifests(ctx context.Context, in *GetManifestsRequest, opts ...grpc.CallOption) (*GetManifestsResponse, error)
 	Recover(ctx context.Context, in *RecoverRequest, opts ...grpc.CallOption) (*RecoverResponse, error)
+	// DryRunSetManifest performs all checks of SetManifest without changing the Coordinator state.
+	DryRunSetManifest(ctx context.Context, in *SetManifestRequest, opts ...grpc.CallOption) (*DryRunSetManifestResponse, error)
+	// CancelPendingUpdate cancels a manifest update that was scheduled with SetManifest.
+	CancelPendingUpdate(ctx context.Context, in *CancelPendingUpdateRequest, opts ...grpc.CallOption) (*CancelPendingUpdateResponse, error)
+	// Rollback re-activates the manifest of an earlier transition in the history.
+	Rollback(ctx context.Context, in *RollbackRequest, opts ...grpc.CallOption) (*RollbackResponse, error)
+	// GetAuditLog returns the events recorded in the Coordinator's audit log.
+	GetAuditLog(ctx context.Context, in *GetAuditLogRequest, opts ...grpc.CallOption) (*GetAuditLogResponse, error)
+	// RevokeMeshCerts revokes mesh certificates issued under the current manifest.
+	RevokeMeshCerts(ctx context.Context, in *RevokeMeshCertsRequest, opts ...grpc.CallOption) (*RevokeMeshCertsResponse, error)
+	// IssueSubCA issues a name-constrained CA certificate from the mesh CA of the current manifest.
+	// The issuance needs to be approved by the workload owners like a manifest update.
+	IssueSubCA(ctx context.Context, in *IssueSubCARequest, opts ...grpc.CallOption) (*IssueSubCAResponse, error)
+	// ListMeshCerts returns the unexpired mesh certificates the Coordinator issued since it started.
+	ListMeshCerts(ctx context.Context, in *ListMeshCertsRequest, opts ...grpc.CallOption) (*ListMeshCertsResponse, error)
 }
 
 type userAPIClient struct {
@@ -71,6 +93,76 @@ func (c *userAPIClient) Recover(ctx context.Context, in *RecoverRequest, opts ..
 	return out, nil
 }
 
+func (c *userAPIClient) DryRunSetManifest(ctx context.Context, in *SetManifestRequest, opts ...grpc.CallOption) (*DryRunSetManifestResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DryRunSetManifestResponse)
+	err := c.cc.Invoke(ctx, UserAPI_DryRunSetManifest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userAPIClient) CancelPendingUpdate(ctx context.Context, in *CancelPendingUpdateRequest, opts ...grpc.CallOption) (*CancelPendingUpdateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelPendingUpdateResponse)
+	err := c.cc.Invoke(ctx, UserAPI_CancelPendingUpdate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userAPIClient) Rollback(ctx context.Context, in *RollbackRequest, opts ...grpc.CallOption) (*RollbackResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RollbackResponse)
+	err := c.cc.Invoke(ctx, UserAPI_Rollback_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userAPIClient) GetAuditLog(ctx context.Context, in *GetAuditLogRequest, opts ...grpc.CallOption) (*GetAuditLogResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAuditLogResponse)
+	err := c.cc.Invoke(ctx, UserAPI_GetAuditLog_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userAPIClient) RevokeMeshCerts(ctx context.Context, in *RevokeMeshCertsRequest, opts ...grpc.CallOption) (*RevokeMeshCertsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevokeMeshCertsResponse)
+	err := c.cc.Invoke(ctx, UserAPI_RevokeMeshCerts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userAPIClient) IssueSubCA(ctx context.Context, in *IssueSubCARequest, opts ...grpc.CallOption) (*IssueSubCAResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(IssueSubCAResponse)
+	err := c.cc.Invoke(ctx, UserAPI_IssueSubCA_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userAPIClient) ListMeshCerts(ctx context.Context, in *ListMeshCertsRequest, opts ...grpc.CallOption) (*ListMeshCertsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListMeshCertsResponse)
+	err := c.cc.Invoke(ctx, UserAPI_ListMeshCerts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // UserAPIServer is the server API for UserAPI service.
 // All implementations must embed UnimplementedUserAPIServer
 // for forward compatibility.
@@ -78,6 +170,21 @@ type UserAPIServer interface {
 	SetManifest(context.Context, *SetManifestRequest) (*SetManifestResponse, error)
 	GetManifests(context.Context, *GetManifestsRequest) (*GetManifestsResponse, error)
 	Recover(context.Context, *RecoverRequest) (*RecoverResponse, error)
+	// DryRunSetManifest performs all checks of SetManifest without changing the Coordinator state.
+	DryRunSetManifest(context.Context, *SetManifestRequest) (*DryRunSetManifestResponse, error)
+	// CancelPendingUpdate cancels a manifest update that was scheduled with SetManifest.
+	CancelPendingUpdate(context.Context, *CancelPendingUpdateRequest) (*CancelPendingUpdateResponse, error)
+	// Rollback re-activates the manifest of an earlier transition in the history.
+	Rollback(context.Context, *RollbackRequest) (*RollbackResponse, error)
+	// GetAuditLog returns the events recorded in the Coordinator's audit log.
+	GetAuditLog(context.Context, *GetAuditLogRequest) (*GetAuditLogResponse, error)
+	// RevokeMeshCerts revokes mesh certificates issued under the current manifest.
+	RevokeMeshCerts(context.Context, *RevokeMeshCertsRequest) (*RevokeMeshCertsResponse, error)
+	// IssueSubCA issues a name-constrained CA certificate from the mesh CA of the current manifest.
+	// The issuance needs to be approved by the workload owners like a manifest update.
+	IssueSubCA(context.Context, *IssueSubCARequest) (*IssueSubCAResponse, error)
+	// ListMeshCerts returns the unexpired mesh certificates the Coordinator issued since it started.
+	ListMeshCerts(context.Context, *ListMeshCertsRequest) (*ListMeshCertsResponse, error)
 	mustEmbedUnimplementedUserAPIServer()
 }
 
@@ -97,6 +204,27 @@ func (UnimplementedUserAPIServer) GetManifests(context.Context, *GetManifestsReq
 func (UnimplementedUserAPIServer) Recover(context.Context, *RecoverRequest) (*RecoverResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Recover not implemented")
 }
+func (UnimplementedUserAPIServer) DryRunSetManifest(context.Context, *SetManifestRequest) (*DryRunSetManifestResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DryRunSetManifest not implemented")
+}
+func (UnimplementedUserAPIServer) CancelPendingUpdate(context.Context, *CancelPendingUpdateRequest) (*CancelPendingUpdateResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CancelPendingUpdate not implemented")
+}
+func (UnimplementedUserAPIServer) Rollback(context.Context, *RollbackRequest) (*RollbackResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Rollback not implemented")
+}
+func (UnimplementedUserAPIServer) GetAuditLog(context.Context, *GetAuditLogRequest) (*GetAuditLogResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetAuditLog not implemented")
+}
+func (UnimplementedUserAPIServer) RevokeMeshCerts(context.Context, *RevokeMeshCertsRequest) (*RevokeMeshCertsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RevokeMeshCerts not implemented")
+}
+func (UnimplementedUserAPIServer) IssueSubCA(context.Context, *IssueSubCARequest) (*IssueSubCAResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method IssueSubCA not implemented")
+}
+func (UnimplementedUserAPIServer) ListMeshCerts(context.Context, *ListMeshCertsRequest) (*ListMeshCertsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListMeshCerts not implemented")
+}
 func (UnimplementedUserAPIServer) mustEmbedUnimplementedUserAPIServer() {}
 func (UnimplementedUserAPIServer) testEmbeddedByValue()                 {}
 
@@ -172,6 +300,132 @@ func _UserAPI_Recover_Handler(srv interface{}, ctx context.Context, dec func(int
 	return interceptor(ctx, in, info, handler)
 }
 
+func _UserAPI_DryRunSetManifest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetManifestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserAPIServer).DryRunSetManifest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserAPI_DryRunSetManifest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserAPIServer).DryRunSetManifest(ctx, req.(*SetManifestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserAPI_CancelPendingUpdate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelPendingUpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserAPIServer).CancelPendingUpdate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserAPI_CancelPendingUpdate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserAPIServer).CancelPendingUpdate(ctx, req.(*CancelPendingUpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserAPI_Rollback_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RollbackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserAPIServer).Rollback(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserAPI_Rollback_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserAPIServer).Rollback(ctx, req.(*RollbackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserAPI_GetAuditLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAuditLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserAPIServer).GetAuditLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserAPI_GetAuditLog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserAPIServer).GetAuditLog(ctx, req.(*GetAuditLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserAPI_RevokeMeshCerts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeMeshCertsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserAPIServer).RevokeMeshCerts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserAPI_RevokeMeshCerts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserAPIServer).RevokeMeshCerts(ctx, req.(*RevokeMeshCertsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserAPI_IssueSubCA_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IssueSubCARequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserAPIServer).IssueSubCA(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserAPI_IssueSubCA_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserAPIServer).IssueSubCA(ctx, req.(*IssueSubCARequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserAPI_ListMeshCerts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMeshCertsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserAPIServer).ListMeshCerts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserAPI_ListMeshCerts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserAPIServer).ListMeshCerts(ctx, req.(*ListMeshCertsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // UserAPI_ServiceDesc is the grpc.ServiceDesc for UserAPI service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -191,6 +445,34 @@ var UserAPI_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Recover",
 			Handler:    _UserAPI_Recover_Handler,
 		},
+		{
+			MethodName: "DryRunSetManifest",
+			Handler:    _UserAPI_DryRunSetManifest_Handler,
+		},
+		{
+			MethodName: "CancelPendingUpdate",
+			Handler:    _UserAPI_CancelPendingUpdate_Handler,
+		},
+		{
+			MethodName: "Rollback",
+			Handler:    _UserAPI_Rollback_Handler,
+		},
+		{
+			MethodName: "GetAuditLog",
+			Handler:    _UserAPI_GetAuditLog_Handler,
+		},
+		{
+			MethodName: "RevokeMeshCerts",
+			Handler:    _UserAPI_RevokeMeshCerts_Handler,
+		},
+		{
+			MethodName: "IssueSubCA",
+			Handler:    _UserAPI_IssueSubCA_Handler,
+		},
+		{
+			MethodName: "ListMeshCerts",
+			Handler:    _UserAPI_ListMeshCerts_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "userapi.proto",
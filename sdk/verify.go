@@ -13,6 +13,7 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/edgelesssys/contrast/apitypes"
 	"github.com/edgelesssys/contrast/internal/atls/validators"
@@ -181,7 +182,19 @@ func (c Client) ValidateAttestation(ctx context.Context, nonce []byte, attestati
 		return nil, fmt.Errorf("getting validators: %w", err)
 	}
 
-	transitions := history.BuildTransitionChain(resp.Manifests)
+	var prunedTransitionHash [history.HashSize]byte
+	if len(resp.PrunedTransitionHash) > 0 {
+		if len(resp.PrunedTransitionHash) != history.HashSize {
+			return nil, fmt.Errorf("pruned transition hash has invalid length %d", len(resp.PrunedTransitionHash))
+		}
+		if resp.PrunedTransitions == 0 {
+			return nil, fmt.Errorf("pruned transition hash is set, but no transitions were pruned")
+		}
+		prunedTransitionHash = [history.HashSize]byte(resp.PrunedTransitionHash)
+	} else if resp.PrunedTransitions > 0 {
+		return nil, fmt.Errorf("%d transitions were pruned, but pruned transition hash is missing", resp.PrunedTransitions)
+	}
+	transitions := history.BuildTransitionChainFrom(prunedTransitionHash, resp.Manifests)
 	transitionDigest := transitions[len(transitions)-1].Digest()
 	reportData := apitypes.ConstructReportData(nonce, transitionDigest[:], &resp.CoordinatorState)
 
@@ -189,10 +202,15 @@ func (c Client) ValidateAttestation(ctx context.Context, nonce []byte, attestati
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 	state := CoordinatorState{
-		Manifests: resp.Manifests,
-		Policies:  resp.Policies,
-		RootCA:    resp.RootCA,
-		MeshCA:    resp.MeshCA,
+		Manifests:             resp.Manifests,
+		Policies:              resp.Policies,
+		RootCA:                resp.RootCA,
+		MeshCA:                resp.MeshCA,
+		PrunedTransitionHash:  resp.PrunedTransitionHash,
+		PrunedTransitions:     resp.PrunedTransitions,
+		CheckpointSignature:   resp.CheckpointSignature,
+		PendingManifest:       resp.PendingManifest,
+		PendingActivationTime: resp.PendingActivationTime,
 	}
 	return &state, nil
 }
@@ -211,4 +229,17 @@ type CoordinatorState struct {
 	LatestTransitionHash []byte
 	// Signature of the latest transition hash by the Coordinator.
 	LatestTransitionSignature []byte
+	// Hash of the most recent transition that was pruned from the Coordinator's history, if the history was pruned.
+	// The oldest manifest in Manifests is a successor of this transition.
+	PrunedTransitionHash []byte
+	// Number of transitions that were pruned from the Coordinator's history.
+	PrunedTransitions uint64
+	// Signature of the pruned history checkpoint by the Coordinator.
+	CheckpointSignature []byte
+	// Manifest of a scheduled update that's not active yet, if any.
+	PendingManifest []byte
+	// Time at which the pending update becomes active.
+	PendingActivationTime time.Time
+	// PEM-encoded public key the Coordinator signs its history and webhook notifications with.
+	SigningKey []byte
 }
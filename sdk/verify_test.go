@@ -124,6 +124,43 @@ func TestValidateAttestation(t *testing.T) {
 				},
 			},
 		},
+		"pruned history": {
+			nonce: testNonce,
+			resp: &apitypes.AttestationResponse{
+				AttestationType:   testOID,
+				RawAttestationDoc: testNonce,
+				CoordinatorState: apitypes.CoordinatorState{
+					Manifests:            [][]byte{testManifest},
+					PrunedTransitionHash: make([]byte, 32),
+					PrunedTransitions:    2,
+					CheckpointSignature:  []byte("signature"),
+				},
+			},
+		},
+		"pruned transitions without hash": {
+			nonce: testNonce,
+			resp: &apitypes.AttestationResponse{
+				AttestationType:   testOID,
+				RawAttestationDoc: testNonce,
+				CoordinatorState: apitypes.CoordinatorState{
+					Manifests:         [][]byte{testManifest},
+					PrunedTransitions: 2,
+				},
+			},
+			wantErr: "pruned transition hash is missing",
+		},
+		"pruned hash without transitions": {
+			nonce: testNonce,
+			resp: &apitypes.AttestationResponse{
+				AttestationType:   testOID,
+				RawAttestationDoc: testNonce,
+				CoordinatorState: apitypes.CoordinatorState{
+					Manifests:            [][]byte{testManifest},
+					PrunedTransitionHash: make([]byte, 32),
+				},
+			},
+			wantErr: "no transitions were pruned",
+		},
 		"no manifests": {
 			nonce: testNonce,
 			resp: &apitypes.AttestationResponse{
@@ -174,6 +211,10 @@ func TestValidateAttestation(t *testing.T) {
 				Policies:  tc.resp.Policies,
 				RootCA:    tc.resp.RootCA,
 				MeshCA:    tc.resp.MeshCA,
+
+				PrunedTransitionHash: tc.resp.PrunedTransitionHash,
+				PrunedTransitions:    tc.resp.PrunedTransitions,
+				CheckpointSignature:  tc.resp.CheckpointSignature,
 			}
 
 			assert.Equal(expected, state)
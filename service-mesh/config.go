@@ -4,6 +4,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/netip"
@@ -34,6 +35,13 @@ var loopbackCIDR = netip.MustParsePrefix("127.0.0.1/8")
 const (
 	blackHoleClusterName = "BlackHoleCluster"
 	ingressClusterName   = "IngressCluster"
+
+	// tlsConfigDir is the directory the initializer writes the mesh certificates to.
+	tlsConfigDir = "/contrast/tls-config"
+	// meshCertSecretName is the name of the SDS secret holding the mesh certificate.
+	meshCertSecretName = "mesh-cert"
+	// meshValidationSecretName is the name of the SDS secret holding the mesh validation context.
+	meshValidationSecretName = "mesh-validation"
 )
 
 // ProxyConfig represents the configuration for the proxy.
@@ -350,31 +358,7 @@ func ingressListener(name string, listenPort uint16, requireClientCertificate bo
 
 func upstreamTLSTransportSocket() (*envoyCoreV3.TransportSocket, error) {
 	tls := &envoyTLSV3.UpstreamTlsContext{
-		CommonTlsContext: &envoyTLSV3.CommonTlsContext{
-			TlsCertificates: []*envoyTLSV3.TlsCertificate{
-				{
-					PrivateKey: &envoyCoreV3.DataSource{
-						Specifier: &envoyCoreV3.DataSource_Filename{
-							Filename: "/contrast/tls-config/key.pem",
-						},
-					},
-					CertificateChain: &envoyCoreV3.DataSource{
-						Specifier: &envoyCoreV3.DataSource_Filename{
-							Filename: "/contrast/tls-config/certChain.pem",
-						},
-					},
-				},
-			},
-			ValidationContextType: &envoyTLSV3.CommonTlsContext_ValidationContext{
-				ValidationContext: &envoyTLSV3.CertificateValidationContext{
-					TrustedCa: &envoyCoreV3.DataSource{
-						Specifier: &envoyCoreV3.DataSource_Filename{
-							Filename: "/contrast/tls-config/mesh-ca.pem",
-						},
-					},
-				},
-			},
-		},
+		CommonTlsContext: meshCommonTLSContext(),
 	}
 	tlsAny, err := anypb.New(tls)
 	if err != nil {
@@ -391,44 +375,131 @@ func upstreamTLSTransportSocket() (*envoyCoreV3.TransportSocket, error) {
 
 func downstreamTLSTransportSocket(requireClientCertificate bool) (*envoyCoreV3.TransportSocket, error) {
 	tls := &envoyTLSV3.DownstreamTlsContext{
-		CommonTlsContext: &envoyTLSV3.CommonTlsContext{
-			TlsCertificates: []*envoyTLSV3.TlsCertificate{
-				{
+		CommonTlsContext:         meshCommonTLSContext(),
+		RequireClientCertificate: &wrapperspb.BoolValue{Value: requireClientCertificate},
+	}
+	tlsAny, err := anypb.New(tls)
+	if err != nil {
+		return nil, err
+	}
+
+	return &envoyCoreV3.TransportSocket{
+		Name: "envoy.transport_sockets.tls",
+		ConfigType: &envoyCoreV3.TransportSocket_TypedConfig{
+			TypedConfig: tlsAny,
+		},
+	}, nil
+}
+
+// meshCommonTLSContext references the mesh certificate and validation context from SDS, so that
+// Envoy picks up renewed certificates and CRLs without a restart.
+func meshCommonTLSContext() *envoyTLSV3.CommonTlsContext {
+	return &envoyTLSV3.CommonTlsContext{
+		TlsCertificateSdsSecretConfigs: []*envoyTLSV3.SdsSecretConfig{
+			{
+				Name:      meshCertSecretName,
+				SdsConfig: sdsConfigSource(),
+			},
+		},
+		ValidationContextType: &envoyTLSV3.CommonTlsContext_ValidationContextSdsSecretConfig{
+			ValidationContextSdsSecretConfig: &envoyTLSV3.SdsSecretConfig{
+				Name:      meshValidationSecretName,
+				SdsConfig: sdsConfigSource(),
+			},
+		},
+	}
+}
+
+func sdsConfigSource() *envoyCoreV3.ConfigSource {
+	return &envoyCoreV3.ConfigSource{
+		ResourceApiVersion: envoyCoreV3.ApiVersion_V3,
+		ConfigSourceSpecifier: &envoyCoreV3.ConfigSource_PathConfigSource{
+			PathConfigSource: &envoyCoreV3.PathConfigSource{
+				Path: sdsConfigFile,
+			},
+		},
+	}
+}
+
+// SDSConfig returns the file-based SDS resources for the mesh certificate and the mesh
+// validation context.
+//
+// The initializer swaps the ..data symlink in the TLS config directory when it renews the
+// certificate or fetches a new CRL. Watching the directory for moves makes Envoy reload all files
+// of a secret together after the swap.
+func SDSConfig() ([]byte, error) {
+	watchedDirectory := &envoyCoreV3.WatchedDirectory{Path: tlsConfigDir}
+	secrets := []*envoyTLSV3.Secret{
+		{
+			Name: meshCertSecretName,
+			Type: &envoyTLSV3.Secret_TlsCertificate{
+				TlsCertificate: &envoyTLSV3.TlsCertificate{
 					PrivateKey: &envoyCoreV3.DataSource{
 						Specifier: &envoyCoreV3.DataSource_Filename{
-							Filename: "/contrast/tls-config/key.pem",
+							Filename: tlsConfigDir + "/key.pem",
 						},
 					},
 					CertificateChain: &envoyCoreV3.DataSource{
 						Specifier: &envoyCoreV3.DataSource_Filename{
-							Filename: "/contrast/tls-config/certChain.pem",
+							Filename: tlsConfigDir + "/certChain.pem",
 						},
 					},
+					WatchedDirectory: watchedDirectory,
 				},
 			},
-			ValidationContextType: &envoyTLSV3.CommonTlsContext_ValidationContext{
-				ValidationContext: &envoyTLSV3.CertificateValidationContext{
-					TrustedCa: &envoyCoreV3.DataSource{
-						Specifier: &envoyCoreV3.DataSource_Filename{
-							Filename: "/contrast/tls-config/mesh-ca.pem",
-						},
-					},
-				},
+		},
+		{
+			Name: meshValidationSecretName,
+			Type: &envoyTLSV3.Secret_ValidationContext{
+				ValidationContext: meshValidationContext(watchedDirectory),
 			},
 		},
-		RequireClientCertificate: &wrapperspb.BoolValue{Value: requireClientCertificate},
 	}
-	tlsAny, err := anypb.New(tls)
-	if err != nil {
-		return nil, err
+
+	// The file holds a DiscoveryResponse. It's assembled here to not depend on the discovery
+	// service package, which pulls in gRPC.
+	var resp struct {
+		Resources []json.RawMessage `json:"resources"`
 	}
+	for _, secret := range secrets {
+		if err := secret.ValidateAll(); err != nil {
+			return nil, err
+		}
+		secretAny, err := anypb.New(secret)
+		if err != nil {
+			return nil, err
+		}
+		secretJSON, err := protojson.Marshal(secretAny)
+		if err != nil {
+			return nil, err
+		}
+		resp.Resources = append(resp.Resources, secretJSON)
+	}
+	return json.Marshal(resp)
+}
 
-	return &envoyCoreV3.TransportSocket{
-		Name: "envoy.transport_sockets.tls",
-		ConfigType: &envoyCoreV3.TransportSocket_TypedConfig{
-			TypedConfig: tlsAny,
+// meshValidationContext validates peer certificates against the trust bundle, which contains the
+// mesh CA and the mesh CAs of federated deployments, and rejects certificates that are listed in
+// one of their CRLs.
+func meshValidationContext(watchedDirectory *envoyCoreV3.WatchedDirectory) *envoyTLSV3.CertificateValidationContext {
+	return &envoyTLSV3.CertificateValidationContext{
+		TrustedCa: &envoyCoreV3.DataSource{
+			Specifier: &envoyCoreV3.DataSource_Filename{
+				Filename: tlsConfigDir + "/trust-bundle.pem",
+			},
 		},
-	}, nil
+		Crl: &envoyCoreV3.DataSource{
+			Specifier: &envoyCoreV3.DataSource_Filename{
+				Filename: tlsConfigDir + "/crl.pem",
+			},
+		},
+		// The Coordinator only publishes CRLs for the mesh CAs, which issue the leaf certificates.
+		// There are no CRLs for the mesh CAs themselves or for sub CAs, so the chain can't be
+		// checked as a whole. The Coordinator doesn't revoke sub CAs for that reason, and the
+		// leaf certificates they issue are rejected because their issuer has no CRL.
+		OnlyVerifyLeafCertCrl: true,
+		WatchedDirectory:      watchedDirectory,
+	}
 }
 
 func addBlackHoleToConfig(config *envoyConfigBootstrapV3.Bootstrap, listenerPorts []int) error {
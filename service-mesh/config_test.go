@@ -15,6 +15,9 @@ import (
 //go:embed golden/defaultEnvoy.json
 var defaultEnvoyConfig []byte
 
+//go:embed golden/sds.json
+var sdsConfig []byte
+
 func TestCompareEnvoyConfigToGolden(t *testing.T) {
 	require := require.New(t)
 
@@ -39,6 +42,12 @@ func TestCompareEnvoyConfigToGolden(t *testing.T) {
 	}
 }
 
+func TestCompareSDSConfigToGolden(t *testing.T) {
+	configJSON, err := SDSConfig()
+	require.NoError(t, err)
+	assert.JSONEq(t, string(sdsConfig), string(configJSON))
+}
+
 func TestMain(m *testing.M) {
 	goleak.VerifyTestMain(m)
 }
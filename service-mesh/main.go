@@ -18,6 +18,7 @@ const (
 	ingressProxyConfigEnvVar = "CONTRAST_INGRESS_PROXY_CONFIG"
 	adminPortEnvVar          = "CONTRAST_ADMIN_PORT"
 	envoyConfigFile          = "/envoy-config.yml"
+	sdsConfigFile            = "/envoy-sds.json"
 )
 
 var version = "0.0.0-dev"
@@ -71,6 +72,14 @@ func run() (retErr error) {
 		return err
 	}
 
+	sdsConfig, err := SDSConfig()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(sdsConfigFile, sdsConfig, 0o644); err != nil {
+		return err
+	}
+
 	if !pconfig.ingressDisabled {
 		if err := IngressIPTableRules(pconfig.ingress); err != nil {
 			return fmt.Errorf("failed to set up iptables rules: %w", err)